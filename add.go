@@ -3,8 +3,10 @@ package goss
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aelsabbahy/goss/resource"
 	"github.com/aelsabbahy/goss/system"
@@ -14,7 +16,7 @@ import (
 // AddResources is a simple wrapper to add multiple resources
 func AddResources(fileName, resourceName string, keys []string, c *util.Config) error {
 	var err error
-	outStoreFormat, err = getStoreFormatFromFileName(fileName)
+	outStoreFormat, err = getAddStoreFormat(fileName)
 	if err != nil {
 		return err
 	}
@@ -29,7 +31,7 @@ func AddResources(fileName, resourceName string, keys []string, c *util.Config)
 		gossConfig = *NewGossConfig()
 	}
 
-	sys := system.New(c.PackageManager)
+	sys := systemFor(c)
 
 	for _, key := range keys {
 		if err := AddResource(fileName, gossConfig, resourceName, key, *c, sys); err != nil {
@@ -77,6 +79,34 @@ func AddResource(fileName string, gossConfig GossConfig, resourceName, key strin
 		res, err = gossConfig.Interfaces.AppendSysResource(key, sys, config)
 	case "HTTP":
 		res, err = gossConfig.HTTPs.AppendSysResource(key, sys, config)
+	case "Dir":
+		res, err = gossConfig.Dirs.AppendSysResource(key, sys, config)
+	case "ShellProfile":
+		res, err = gossConfig.ShellProfiles.AppendSysResource(key, sys, config)
+	case "CryptoPolicy":
+		res, err = gossConfig.CryptoPolicies.AppendSysResource(key, sys, config)
+	case "TrustedBoot":
+		res, err = gossConfig.TrustedBoots.AppendSysResource(key, sys, config)
+	case "Entropy":
+		res, err = gossConfig.Entropies.AppendSysResource(key, sys, config)
+	case "MAC":
+		res, err = gossConfig.MACs.AppendSysResource(key, sys, config)
+	case "Container":
+		res, err = gossConfig.Containers.AppendSysResource(key, sys, config)
+	case "K8s":
+		res, err = gossConfig.K8s.AppendSysResource(key, sys, config)
+	case "KV":
+		res, err = gossConfig.KVs.AppendSysResource(key, sys, config)
+	case "NTP":
+		res, err = gossConfig.NTPs.AppendSysResource(key, sys, config)
+	case "Sockets":
+		res, err = gossConfig.Sockets.AppendSysResource(key, sys, config)
+	case "Ping":
+		res, err = gossConfig.Pings.AppendSysResource(key, sys, config)
+	case "GRPC":
+		res, err = gossConfig.GRPCs.AppendSysResource(key, sys, config)
+	case "WebSocket":
+		res, err = gossConfig.WebSockets.AppendSysResource(key, sys, config)
 	default:
 		err = fmt.Errorf("undefined resource name: %s", resourceName)
 	}
@@ -93,7 +123,7 @@ func AddResource(fileName string, gossConfig GossConfig, resourceName, key strin
 // AutoAddResources is a simple wrapper to add multiple resources
 func AutoAddResources(fileName string, keys []string, c *util.Config) error {
 	var err error
-	outStoreFormat, err = getStoreFormatFromFileName(fileName)
+	outStoreFormat, err = getAddStoreFormat(fileName)
 	if err != nil {
 		return err
 	}
@@ -108,17 +138,140 @@ func AutoAddResources(fileName string, keys []string, c *util.Config) error {
 		gossConfig = *NewGossConfig()
 	}
 
-	sys := system.New(c.PackageManager)
+	sys := systemFor(c)
 
 	for _, key := range keys {
 		if err := AutoAddResource(fileName, gossConfig, key, c, sys); err != nil {
 			return err
 		}
 	}
+	if err := AutoAddDiscovered(fileName, gossConfig, c, sys); err != nil {
+		return err
+	}
 
 	return WriteJSON(fileName, gossConfig)
 }
 
+// discoverKinds are what autoadd can discover, in the order it adds them
+var discoverKinds = []string{"containers", "services", "ports"}
+
+// AutoAddDiscovered adds the resources of c.Discover found on the system: the
+// running docker or podman containers, the services systemd starts at boot
+// and the listening ports. Kinds that can't be discovered on the system are
+// skipped with a warning for all, and an error when they're asked for.
+func AutoAddDiscovered(fileName string, gossConfig GossConfig, c *util.Config, sys *system.System) error {
+	// kinds are the kinds to discover, true for those asked for by name
+	kinds := map[string]bool{}
+	for _, kind := range c.Discover {
+		switch kind {
+		case "all":
+			for _, k := range discoverKinds {
+				if _, ok := kinds[k]; !ok {
+					kinds[k] = false
+				}
+			}
+		case "containers", "services", "ports":
+			kinds[kind] = true
+		default:
+			return fmt.Errorf("unknown --discover %s, expected one of: %s or all", kind, strings.Join(discoverKinds, ", "))
+		}
+	}
+
+	for _, kind := range discoverKinds {
+		named, ok := kinds[kind]
+		if !ok {
+			continue
+		}
+		var resources []resource.ResourceRead
+		var err error
+		switch kind {
+		case "containers":
+			resources, err = discoverContainers(gossConfig, sys)
+		case "services":
+			resources, err = discoverServices(gossConfig, sys)
+		case "ports":
+			resources, err = discoverPorts(gossConfig, sys)
+		}
+		if err != nil && named {
+			return fmt.Errorf("discovering %s: %v", kind, err)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: not discovering %s: %v\n", kind, err)
+		}
+		for _, res := range resources {
+			resourcePrint(fileName, res, c.AnnounceToCLI)
+		}
+	}
+	return nil
+}
+
+func discoverContainers(gossConfig GossConfig, sys *system.System) ([]resource.ResourceRead, error) {
+	names, err := system.RunningContainers("", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	var added []resource.ResourceRead
+	for _, name := range names {
+		if res, _, ok, err := gossConfig.Containers.AppendSysResourceIfExists(name, sys); err != nil {
+			return added, err
+		} else if ok {
+			added = append(added, res)
+		}
+	}
+	return added, nil
+}
+
+func discoverServices(gossConfig GossConfig, sys *system.System) ([]resource.ResourceRead, error) {
+	if _, ok := sys.NewService("", sys, util.Config{}).(*system.ServiceSystemd); !ok {
+		return nil, fmt.Errorf("services are only discovered with systemd")
+	}
+	names, err := system.EnabledSystemdServices()
+	if err != nil {
+		return nil, err
+	}
+	var added []resource.ResourceRead
+	for _, name := range names {
+		if res, _, ok, err := gossConfig.Services.AppendSysResourceIfExists(name, sys); err != nil {
+			return added, err
+		} else if ok {
+			added = append(added, res)
+		}
+	}
+	return added, nil
+}
+
+func discoverPorts(gossConfig GossConfig, sys *system.System) ([]resource.ResourceRead, error) {
+	var added []resource.ResourceRead
+	for _, port := range listeningPorts() {
+		if res, _, ok, err := gossConfig.Ports.AppendSysResourceIfExists(port, sys); err != nil {
+			return added, err
+		} else if ok {
+			added = append(added, res)
+		}
+	}
+	return added, nil
+}
+
+// listeningPorts are the listening tcp ports and the udp ports of sockets
+// that aren't connected to a peer, connected ones are those of clients
+func listeningPorts() []string {
+	var ports []string
+	for port, entries := range system.GetPorts(false) {
+		if !strings.HasPrefix(port, "udp") {
+			ports = append(ports, port)
+			continue
+		}
+		for _, e := range entries {
+			if e.ForeignPort == 0 {
+				ports = append(ports, port)
+				break
+			}
+		}
+	}
+	sort.Strings(ports)
+	return ports
+}
+
 // AutoAddResource adds a single resource to fileName with automatic detection of the type of resource
 func AutoAddResource(fileName string, gossConfig GossConfig, key string, c *util.Config, sys *system.System) error {
 	// file
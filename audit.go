@@ -0,0 +1,181 @@
+package goss
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/aelsabbahy/goss/outputs"
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+)
+
+// auditLine is a line of the audit log, hash is the sha256 of entry as it's
+// written, which holds the hash of the line before it, so changing, removing
+// or reordering lines breaks the chain
+type auditLine struct {
+	Hash  string          `json:"hash"`
+	Entry json.RawMessage `json:"entry"`
+}
+
+// auditEntry is a run of validate
+type auditEntry struct {
+	Time     time.Time                    `json:"time"`
+	Hostname string                       `json:"hostname"`
+	Gossfile string                       `json:"gossfile"`
+	RunID    string                       `json:"run-id,omitempty"`
+	Summary  outputs.StructureTestSummary `json:"summary"`
+	Results  []resource.TestResult        `json:"results"`
+	PrevHash string                       `json:"prev-hash"`
+}
+
+// AuditLog appends the results of each run to an NDJSON file, whose lines
+// are chained by their hashes to make it tamper-evident
+type AuditLog struct {
+	file     string
+	gossfile string
+	err      error
+}
+
+// NewAuditLog is the audit log in file, nil when file is empty
+func NewAuditLog(file, gossfile string) *AuditLog {
+	if file == "" {
+		return nil
+	}
+	return &AuditLog{file: file, gossfile: gossfile}
+}
+
+// Results passes the results through and appends them to the log once the
+// run finished, before the output sees the end of the results. outConfig is
+// that of the run, for its ID. Err is the error appending them.
+func (a *AuditLog) Results(in <-chan []resource.TestResult, startTime time.Time, outConfig util.OutputConfig) <-chan []resource.TestResult {
+	if a == nil {
+		return in
+	}
+
+	out := make(chan []resource.TestResult)
+	go func() {
+		defer close(out)
+		entry := auditEntry{Time: startTime, Gossfile: a.gossfile, RunID: outConfig.RunID, Results: []resource.TestResult{}}
+		entry.Hostname, _ = os.Hostname()
+		for resultGroup := range in {
+			for _, r := range resultGroup {
+				switch {
+				case r.Warning():
+					entry.Summary.Warnings++
+				case r.Result == resource.FAIL:
+					entry.Summary.Failed++
+				case r.Result == resource.ERROR:
+					entry.Summary.Errored++
+				case r.Result == resource.TIMEOUT:
+					entry.Summary.TimedOut++
+				}
+				entry.Summary.TestCount++
+				// Errors are marshalled as their error code and message
+				r.Err = util.CodeError(r.Err)
+				entry.Results = append(entry.Results, r)
+			}
+			out <- resultGroup
+		}
+		entry.Summary.TotalDuration = time.Since(startTime)
+		a.err = a.append(entry)
+	}()
+
+	return out
+}
+
+// Err is the error appending the last run
+func (a *AuditLog) Err() error {
+	if a == nil || a.err == nil {
+		return nil
+	}
+	return fmt.Errorf("audit log %s: %v", a.file, a.err)
+}
+
+func (a *AuditLog) append(entry auditEntry) error {
+	f, err := os.OpenFile(a.file, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	lines, last, err := readAuditLog(f)
+	if err != nil {
+		return fmt.Errorf("line %d: %v, refusing to append", lines+1, err)
+	}
+	entry.PrevHash = last
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(auditLine{Hash: auditHash(raw), Entry: raw})
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+func auditHash(entry []byte) string {
+	sum := sha256.Sum256(entry)
+	return hex.EncodeToString(sum[:])
+}
+
+// readAuditLog checks the chain of the lines of r and returns their number
+// and the hash of the last one, the error is that of the first line breaking
+// the chain
+func readAuditLog(r io.Reader) (int, string, error) {
+	br := bufio.NewReader(r)
+	lines := 0
+	last := ""
+	for {
+		data, err := br.ReadBytes('\n')
+		if err == io.EOF && len(data) == 0 {
+			return lines, last, nil
+		} else if err == io.EOF {
+			return lines, last, fmt.Errorf("truncated line")
+		} else if err != nil {
+			return lines, last, err
+		}
+		var line auditLine
+		if err := json.Unmarshal(bytes.TrimSpace(data), &line); err != nil {
+			return lines, last, err
+		}
+		if auditHash(line.Entry) != line.Hash {
+			return lines, last, fmt.Errorf("hash doesn't match the entry, it was changed")
+		}
+		var entry struct {
+			PrevHash *string `json:"prev-hash"`
+		}
+		if err := json.Unmarshal(line.Entry, &entry); err != nil {
+			return lines, last, err
+		}
+		if entry.PrevHash == nil || *entry.PrevHash != last {
+			return lines, last, fmt.Errorf("prev-hash doesn't match the line before it, lines were removed or reordered")
+		}
+		last = line.Hash
+		lines++
+	}
+}
+
+// VerifyAuditLog checks the hash chain of the audit log in file and returns
+// the number of runs it holds
+func VerifyAuditLog(file string) (int, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	lines, _, err := readAuditLog(f)
+	if err != nil {
+		return lines, fmt.Errorf("%s: line %d: %v", file, lines+1, err)
+	}
+	return lines, nil
+}
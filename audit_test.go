@@ -0,0 +1,61 @@
+package goss
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	spec := filepath.Join("testdata", "failing.goss.yaml")
+	file := filepath.Join(t.TempDir(), "audit.ndjson")
+	for i := 0; i < 3; i++ {
+		config, err := util.NewConfig(util.WithSpecFile(spec), util.WithAuditLog(file), util.WithOutputFormat("json"), util.WithResultWriter(&bytes.Buffer{}))
+		require.NoError(t, err)
+		code, err := Validate(config, time.Now())
+		require.NoError(t, err)
+		require.Equal(t, 1, code)
+	}
+	runs, err := VerifyAuditLog(file)
+	require.NoError(t, err)
+	assert.Equal(t, 3, runs)
+
+	data, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	lines := strings.SplitAfter(strings.TrimSuffix(string(data), "\n"), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], `"prev-hash":""`)
+	assert.Contains(t, lines[0], `"failed-count":2`)
+
+	tests := map[string]struct {
+		lines []string
+		broke int
+	}{
+		"changed":   {[]string{lines[0], strings.Replace(lines[1], `"failed-count":2`, `"failed-count":0`, 1), lines[2]}, 2},
+		"removed":   {[]string{lines[0], lines[2]}, 2},
+		"reordered": {[]string{lines[1], lines[0], lines[2]}, 1},
+		"truncated": {[]string{lines[0], lines[1], lines[2][:10]}, 3},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			broken := filepath.Join(t.TempDir(), "audit.ndjson")
+			require.NoError(t, ioutil.WriteFile(broken, []byte(strings.Join(tt.lines, "")), 0600))
+			runs, err := VerifyAuditLog(broken)
+			require.Error(t, err)
+			assert.Equal(t, tt.broke-1, runs)
+
+			// Nothing is appended to a broken chain
+			config, err := util.NewConfig(util.WithSpecFile(spec), util.WithAuditLog(broken), util.WithOutputFormat("json"), util.WithResultWriter(&bytes.Buffer{}))
+			require.NoError(t, err)
+			_, err = Validate(config, time.Now())
+			assert.Error(t, err)
+		})
+	}
+}
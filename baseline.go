@@ -0,0 +1,106 @@
+package goss
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/aelsabbahy/goss/outputs"
+	"github.com/aelsabbahy/goss/resource"
+)
+
+// placeholderNumber numbers the placeholders of redacted output in the order
+// their values were first seen, which differs between runs
+var placeholderNumber = regexp.MustCompile(`<([a-z]+)-[0-9]+>`)
+
+// baselineResult holds the fields of a result in the json and structured
+// output formats that identify a test
+type baselineResult struct {
+	ResourceType string `json:"resource-type"`
+	ResourceId   string `json:"resource-id"`
+	Property     string `json:"property"`
+	Result       int    `json:"result"`
+}
+
+func baselineKey(resourceType, id, property string) string {
+	return resourceType + "\x00" + id + "\x00" + property
+}
+
+// loadBaseline reads the results of a previous run in the json or structured
+// format and returns the keys of the tests that didn't pass
+func loadBaseline(file string) (map[string]bool, error) {
+	if file == "" {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("baseline file error: %v", err)
+	}
+	var out struct {
+		Results *[]baselineResult `json:"results"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("baseline %s: must be the output of --format json or structured: %v", file, err)
+	}
+	if out.Results == nil {
+		return nil, fmt.Errorf("baseline %s: must be the output of --format json or structured: no results", file)
+	}
+	known := make(map[string]bool)
+	for _, r := range *out.Results {
+		if r.Result != resource.SUCCESS && r.Result != resource.SKIP {
+			known[baselineKey(r.ResourceType, r.ResourceId, r.Property)] = true
+		}
+	}
+	return known, nil
+}
+
+// redactedKey is the key of a test with its ID redacted by r, without the
+// numbers of the placeholders
+func redactedKey(r *outputs.Redactor, resourceType, id, property string) string {
+	id = r.Result(resource.TestResult{ResourceType: resourceType, ResourceId: id}).ResourceId
+	return baselineKey(resourceType, placeholderNumber.ReplaceAllString(id, "<$1>"), property)
+}
+
+// BaselineResults marks the tests that didn't pass and didn't pass in the
+// baseline either, so only regressions affect the exit code. When redact is
+// set the IDs of both are redacted before they're compared, so a baseline of
+// redacted output matches the results, which are redacted later.
+func BaselineResults(in <-chan []resource.TestResult, known map[string]bool, redact bool) <-chan []resource.TestResult {
+	if len(known) == 0 {
+		return in
+	}
+	var r *outputs.Redactor
+	if redact {
+		r = outputs.NewRedactor()
+		redacted := make(map[string]bool, len(known))
+		for key := range known {
+			parts := strings.SplitN(key, "\x00", 3)
+			redacted[redactedKey(r, parts[0], parts[1], parts[2])] = true
+		}
+		known = redacted
+	}
+
+	out := make(chan []resource.TestResult)
+	go func() {
+		defer close(out)
+		for resultGroup := range in {
+			for i, t := range resultGroup {
+				if t.Result == resource.SUCCESS || t.Result == resource.SKIP {
+					continue
+				}
+				key := baselineKey(t.ResourceType, t.ResourceId, t.Property)
+				if r != nil {
+					key = redactedKey(r, t.ResourceType, t.ResourceId, t.Property)
+				}
+				if known[key] {
+					resultGroup[i].Baseline = true
+				}
+			}
+			out <- resultGroup
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,82 @@
+package goss
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaselineResults(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "baseline.json")
+	require.NoError(t, ioutil.WriteFile(file, []byte(`{"results": [
+		{"resource-type": "File", "resource-id": "/etc/app.conf", "property": "exists", "result": 1},
+		{"resource-type": "File", "resource-id": "/etc/app.conf", "property": "mode", "result": 0},
+		{"resource-type": "Port", "resource-id": "tcp:80", "property": "listening", "result": 4}
+	]}`), 0644))
+	known, err := loadBaseline(file)
+	require.NoError(t, err)
+
+	in := make(chan []resource.TestResult, 1)
+	in <- []resource.TestResult{
+		{ResourceType: "File", ResourceId: "/etc/app.conf", Property: "exists", Result: resource.FAIL},
+		{ResourceType: "File", ResourceId: "/etc/app.conf", Property: "mode", Result: resource.FAIL},
+		{ResourceType: "Port", ResourceId: "tcp:80", Property: "listening", Result: resource.FAIL},
+		{ResourceType: "Port", ResourceId: "tcp:443", Property: "listening", Result: resource.FAIL},
+	}
+	close(in)
+	got := <-BaselineResults(in, known, false)
+	assert.True(t, got[0].Warning(), "failed in the baseline")
+	assert.False(t, got[1].Warning(), "passed in the baseline")
+	assert.True(t, got[2].Warning(), "errored in the baseline")
+	assert.False(t, got[3].Warning(), "not in the baseline")
+
+	// A baseline of redacted output matches the results before they're
+	// redacted, whatever the numbers of the placeholders
+	require.NoError(t, ioutil.WriteFile(file, []byte(`{"results": [
+		{"resource-type": "HTTP", "resource-id": "https://<host-3>/health", "property": "status", "result": 1},
+		{"resource-type": "File", "resource-id": "/etc/app.conf", "property": "exists", "result": 1}
+	]}`), 0644))
+	known, err = loadBaseline(file)
+	require.NoError(t, err)
+	in = make(chan []resource.TestResult, 1)
+	in <- []resource.TestResult{
+		{ResourceType: "HTTP", ResourceId: "https://api.example.internal/health", Property: "status", Result: resource.FAIL},
+		{ResourceType: "HTTP", ResourceId: "https://api.example.internal/ready", Property: "status", Result: resource.FAIL},
+		{ResourceType: "File", ResourceId: "/etc/app.conf", Property: "exists", Result: resource.FAIL},
+	}
+	close(in)
+	got = <-BaselineResults(in, known, true)
+	assert.True(t, got[0].Warning(), "failed in the redacted baseline")
+	assert.False(t, got[1].Warning(), "not in the redacted baseline")
+	assert.True(t, got[2].Warning(), "failed in the baseline, with nothing to redact")
+	assert.Equal(t, "https://api.example.internal/health", got[0].ResourceId, "redacted before the output")
+
+	require.NoError(t, ioutil.WriteFile(file, []byte("count: 1\n"), 0644))
+	_, err = loadBaseline(file)
+	assert.Error(t, err)
+}
+
+func TestValidateBaseline(t *testing.T) {
+	spec := filepath.Join("testdata", "failing.goss.yaml")
+	var previous bytes.Buffer
+	config, err := util.NewConfig(util.WithSpecFile(spec), util.WithOutputFormat("json"), util.WithResultWriter(&previous))
+	require.NoError(t, err)
+	code, err := Validate(config, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 1, code)
+
+	file := filepath.Join(t.TempDir(), "baseline.json")
+	require.NoError(t, ioutil.WriteFile(file, previous.Bytes(), 0644))
+	config, err = util.NewConfig(util.WithSpecFile(spec), util.WithBaseline(file), util.WithResultWriter(&bytes.Buffer{}))
+	require.NoError(t, err)
+	code, err = Validate(config, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, code)
+}
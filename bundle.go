@@ -0,0 +1,229 @@
+package goss
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// bundleMagic ends a bundle, after the length of its payload
+const bundleMagic = "\x00goss-bundle-v1\x00"
+
+// bundleTrailerLen is the length of the payload length and bundleMagic
+const bundleTrailerLen = 8 + len(bundleMagic)
+
+// bundlePayload is what a bundle appends to the goss binary: the gossfile,
+// the vars sources it's validated with and the files they read, by the path
+// they were read with
+type bundlePayload struct {
+	Gossfile   string   `json:"gossfile"`
+	Vars       []string `json:"vars,omitempty"`
+	VarsInline string   `json:"vars-inline,omitempty"`
+	// TemplateFuncs are the template programs, run on the host of the bundle
+	TemplateFuncs []string          `json:"template-funcs,omitempty"`
+	Files         map[string][]byte `json:"files"`
+}
+
+var (
+	// bundleFiles are the files of the running bundle, read instead of those
+	// of the host
+	bundleFiles map[string][]byte
+	// bundleRecorder records the files read while a bundle is created
+	bundleRecorder   map[string][]byte
+	bundleRecorderMu sync.Mutex
+)
+
+// readGossFile reads the gossfile or vars file path, from the running bundle
+// when it has it
+func readGossFile(path string) ([]byte, error) {
+	data, ok := bundleFiles[path]
+	if !ok {
+		var err error
+		if data, err = ioutil.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	recordBundleFile(path, data)
+	return data, nil
+}
+
+func recordBundleFile(path string, data []byte) {
+	bundleRecorderMu.Lock()
+	defer bundleRecorderMu.Unlock()
+	if bundleRecorder != nil {
+		bundleRecorder[path] = data
+	}
+}
+
+// bundleGlob are the files of the running bundle matching pattern, nil when
+// there's none
+func bundleGlob(pattern string) []string {
+	var matches []string
+	for path := range bundleFiles {
+		if ok, _ := filepath.Match(pattern, path); ok {
+			matches = append(matches, path)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// Bundle writes output, a copy of the goss binary at base, or of the running
+// one when it's empty, with the gossfile of c, its local vars files and every
+// gossfile it includes embedded. Running output validates them without
+// reading any of them from the host, remote includes are fetched once, now.
+// Templates are rendered when the bundle runs, but the includes are those the
+// gossfile has with the vars and environment of now.
+func Bundle(c *util.Config, output, base string) error {
+	if c.Spec == "-" {
+		return fmt.Errorf("a gossfile from stdin can't be bundled")
+	}
+	if len(c.TemplateFuncs) > 0 {
+		return fmt.Errorf("Go template funcs can't be bundled, only template programs")
+	}
+	if base == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("goss binary: %v", err)
+		}
+		base = exe
+	}
+	exe, err := ioutil.ReadFile(base)
+	if err != nil {
+		return fmt.Errorf("goss binary: %v", err)
+	}
+	// The payload of a bundle isn't bundled again
+	if offset, _ := bundleOffset(bytes.NewReader(exe), int64(len(exe))); offset >= 0 {
+		exe = exe[:offset]
+	}
+
+	bundleRecorderMu.Lock()
+	bundleRecorder = make(map[string][]byte)
+	bundleRecorderMu.Unlock()
+	defer func() {
+		bundleRecorderMu.Lock()
+		bundleRecorder = nil
+		bundleRecorderMu.Unlock()
+	}()
+	if _, err := getGossConfig(c); err != nil {
+		return err
+	}
+	payload := bundlePayload{Gossfile: c.Spec, Vars: varsSources(c), VarsInline: c.VarsInline, TemplateFuncs: c.TemplatePrograms, Files: bundleRecorder}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	trailer := make([]byte, 8, bundleTrailerLen)
+	binary.BigEndian.PutUint64(trailer, uint64(len(data)))
+	trailer = append(trailer, bundleMagic...)
+	fh, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	for _, b := range [][]byte{exe, data, trailer} {
+		if _, err := fh.Write(b); err != nil {
+			fh.Close()
+			return err
+		}
+	}
+	return fh.Close()
+}
+
+// bundleOffset is where the payload of the bundle r, of size, starts, -1 when
+// r isn't a bundle
+func bundleOffset(r io.ReaderAt, size int64) (int64, error) {
+	if size < int64(bundleTrailerLen) {
+		return -1, nil
+	}
+	trailer := make([]byte, bundleTrailerLen)
+	if _, err := r.ReadAt(trailer, size-int64(bundleTrailerLen)); err != nil {
+		return -1, err
+	}
+	if string(trailer[8:]) != bundleMagic {
+		return -1, nil
+	}
+	length := int64(binary.BigEndian.Uint64(trailer[:8]))
+	if length > size-int64(bundleTrailerLen) {
+		return -1, fmt.Errorf("bundle payload of %d bytes is larger than the binary", length)
+	}
+	return size - int64(bundleTrailerLen) - length, nil
+}
+
+// readBundle reads the payload of the bundle at path, nil when it isn't one
+func readBundle(path string) (*bundlePayload, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		// Such as a binary that's only executable
+		return nil, nil
+	}
+	defer fh.Close()
+	info, err := fh.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset, err := bundleOffset(fh, info.Size())
+	if err != nil || offset < 0 {
+		return nil, err
+	}
+	data := make([]byte, info.Size()-int64(bundleTrailerLen)-offset)
+	if _, err := fh.ReadAt(data, offset); err != nil {
+		return nil, err
+	}
+	var payload bundlePayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("bundle %s: %v", path, err)
+	}
+	return &payload, nil
+}
+
+// BundleArgs are the command line arguments args of a goss bundle made with
+// Bundle: its gossfile and vars are given as the global flags, and validate is
+// the command when there's none, so the arguments of a bundle are those of
+// validate. The files of the bundle are used for the run. They're args when
+// the running binary isn't a bundle.
+func BundleArgs(args []string) ([]string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return args, nil
+	}
+	payload, err := readBundle(exe)
+	if err != nil || payload == nil {
+		return args, err
+	}
+	bundleFiles = payload.Files
+
+	bundled := []string{args[0], "--gossfile", payload.Gossfile}
+	for _, v := range payload.Vars {
+		bundled = append(bundled, "--vars", v)
+	}
+	if payload.VarsInline != "" {
+		bundled = append(bundled, "--vars-inline", payload.VarsInline)
+	}
+	for _, f := range payload.TemplateFuncs {
+		bundled = append(bundled, "--template-func", f)
+	}
+	rest := args[1:]
+	if len(rest) == 0 || (strings.HasPrefix(rest[0], "-") && !isHelpFlag(rest[0])) {
+		bundled = append(bundled, "validate")
+	}
+	return append(bundled, rest...), nil
+}
+
+func isHelpFlag(arg string) bool {
+	switch arg {
+	case "-h", "--help", "-v", "--version":
+		return true
+	}
+	return false
+}
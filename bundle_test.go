@@ -0,0 +1,77 @@
+package goss
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aelsabbahy/goss/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundle(t *testing.T) {
+	dir := t.TempDir()
+	gossfile := filepath.Join(dir, "goss.yaml")
+	require.NoError(t, ioutil.WriteFile(gossfile, []byte(`gossfile:
+  web/*.yaml: {}
+file:
+  {{.Vars.path}}:
+    exists: true
+`), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "web"), 0755))
+	web := filepath.Join(dir, "web", "port.yaml")
+	require.NoError(t, ioutil.WriteFile(web, []byte("port:\n  tcp:80:\n    listening: true\n"), 0644))
+	vars := filepath.Join(dir, "vars.yaml")
+	require.NoError(t, ioutil.WriteFile(vars, []byte("path: /etc/passwd\n"), 0644))
+	base := filepath.Join(dir, "goss")
+	require.NoError(t, ioutil.WriteFile(base, []byte("binary"), 0755))
+
+	c, err := util.NewConfig(util.WithSpecFile(gossfile), util.WithVarsFile(vars))
+	require.NoError(t, err)
+	output := filepath.Join(dir, "bundle")
+	require.NoError(t, Bundle(c, output, base))
+
+	payload, err := readBundle(output)
+	require.NoError(t, err)
+	require.NotNil(t, payload)
+	assert.Equal(t, gossfile, payload.Gossfile)
+	assert.Equal(t, []string{vars}, payload.Vars)
+	assert.Len(t, payload.Files, 3)
+	assert.Contains(t, string(payload.Files[web]), "tcp:80")
+
+	// The binary of a bundle is that it was made from
+	data, err := ioutil.ReadFile(output)
+	require.NoError(t, err)
+	offset, err := bundleOffset(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	assert.Equal(t, "binary", string(data[:offset]))
+
+	// A bundle of a bundle embeds its files once
+	again := filepath.Join(dir, "again")
+	require.NoError(t, Bundle(c, again, output))
+	data, err = ioutil.ReadFile(again)
+	require.NoError(t, err)
+	offset, err = bundleOffset(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	assert.Equal(t, "binary", string(data[:offset]))
+
+	// The files are read from the bundle
+	bundleFiles = payload.Files
+	defer func() { bundleFiles = nil }()
+	require.NoError(t, os.RemoveAll(dir))
+	currentTemplateFilter = nil
+	c, err = util.NewConfig(util.WithSpecFile(payload.Gossfile), util.WithVarsSources(payload.Vars...))
+	require.NoError(t, err)
+	gossConfig, err := getGossConfig(c)
+	require.NoError(t, err)
+	assert.Len(t, gossConfig.Files, 1)
+	assert.Contains(t, gossConfig.Files, "/etc/passwd")
+	assert.Len(t, gossConfig.Ports, 1)
+
+	payload, err = readBundle(os.Args[0])
+	assert.NoError(t, err)
+	assert.Nil(t, payload)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aelsabbahy/goss/util"
+	"github.com/urfave/cli"
+)
+
+// useDefaults sets the flags of the commands of app that aren't set on the
+// command line or by their environment variable to the values of the config
+// files before running them
+func useDefaults(app *cli.App) {
+	names := flagNames(app)
+	for i := range app.Commands {
+		withDefaults(&app.Commands[i], app.Commands[i].Name, names, app.Flags)
+	}
+}
+
+// withDefaults applies the defaults of the command named top, which cmd is
+// or is a subcommand of, before running the action of cmd
+func withDefaults(cmd *cli.Command, top string, names map[string][]string, global []cli.Flag) {
+	for i := range cmd.Subcommands {
+		withDefaults(&cmd.Subcommands[i], top, names, global)
+	}
+	action, ok := cmd.Action.(func(*cli.Context) error)
+	if !ok {
+		return
+	}
+	flags := cmd.Flags
+	cmd.Action = func(c *cli.Context) error {
+		files, untrusted := util.DefaultsFiles(), util.WorkingDirDefaults
+		if file := c.GlobalString("config"); file != "" {
+			if _, err := os.Stat(file); err != nil {
+				return fmt.Errorf("config file error: %v", err)
+			}
+			files, untrusted = []string{file}, ""
+		}
+		defaults, err := util.LoadDefaults(files, names, untrusted)
+		if err != nil {
+			return err
+		}
+		for _, v := range defaults.Values(top) {
+			isSet, set := c.IsSet, c.Set
+			if !hasFlag(flags, v.Name) {
+				if !hasFlag(global, v.Name) {
+					continue
+				}
+				isSet, set = c.GlobalIsSet, c.GlobalSet
+			}
+			if isSet(v.Name) {
+				continue
+			}
+			for _, value := range v.Values {
+				if err := set(v.Name, value); err != nil {
+					return fmt.Errorf("config file: %s: %v", v.Name, err)
+				}
+			}
+		}
+		// Once the defaults are set, so config files can set them too
+		if err := util.SetupLogging(os.Stderr, c.GlobalString("log-format"), c.GlobalString("log-level")); err != nil {
+			return err
+		}
+		return action(c)
+	}
+}
+
+// flagNames are the names of the flags of the commands of app, with those of
+// their subcommands, and "" for the global ones
+func flagNames(app *cli.App) map[string][]string {
+	names := map[string][]string{"": namesOf(app.Flags)}
+	var add func(top string, cmds []cli.Command)
+	add = func(top string, cmds []cli.Command) {
+		for _, cmd := range cmds {
+			names[top] = append(names[top], namesOf(cmd.Flags)...)
+			add(top, cmd.Subcommands)
+		}
+	}
+	for _, cmd := range app.Commands {
+		add(cmd.Name, []cli.Command{cmd})
+	}
+	return names
+}
+
+func namesOf(flags []cli.Flag) []string {
+	var names []string
+	for _, f := range flags {
+		for _, name := range strings.Split(f.GetName(), ",") {
+			names = append(names, strings.TrimSpace(name))
+		}
+	}
+	return names
+}
+
+func hasFlag(flags []cli.Flag, name string) bool {
+	for _, n := range namesOf(flags) {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -21,30 +21,87 @@ var version string
 
 // converts a cli context into a goss Config
 func newRuntimeConfigFromCLI(c *cli.Context) *util.Config {
+	// The first vars source is the vars file, the others are merged over it
+	var varsFile string
+	vars := splitList(c.GlobalStringSlice("vars"))
+	if len(vars) > 0 {
+		varsFile, vars = vars[0], vars[1:]
+	}
 	cfg := &util.Config{
 		AllowInsecure:     c.Bool("insecure"),
 		AnnounceToCLI:     true,
+		AuditLog:          c.String("audit-log"),
+		Baseline:          c.String("baseline"),
+		BasicAuth:         c.String("basic-auth"),
+		BearerToken:       c.String("bearer-token"),
+		CAFile:            c.String("ca-file"),
 		Cache:             c.Duration("cache"),
+		ClientCert:        c.String("client-cert"),
+		ClientKey:         c.String("client-key"),
+		CommandPolicy:     c.String("command-policy"),
+		DNSServer:         c.GlobalString("dns-server"),
 		Debug:             c.Bool("debug"),
+		Discover:          splitList(c.StringSlice("discover")),
 		Endpoint:          c.String("endpoint"),
-		FormatOptions:     c.StringSlice("format-options"),
+		Fixtures:          c.String("fixtures"),
+		GRPCListenAddress: c.String("grpc-listen-addr"),
+		HostsFile:         c.GlobalString("hosts-file"),
+		IPVersion:         c.GlobalString("ip-version"),
 		IgnoreList:        c.GlobalStringSlice("exclude-attr"),
+		Lang:              c.String("lang"),
 		ListenAddress:     c.String("listen-addr"),
+		MaintenanceFile:   c.String("maintenance-file"),
 		MaxConcurrent:     c.Int("max-concurrent"),
+		MaxConcurrentType: c.StringSlice("max-concurrent-type"),
+		MaxOutputBytes:    c.Int("max-output-bytes"),
+		MaxRunDuration:    c.Duration("max-run-duration"),
+		MetricsEndpoint:   c.String("metrics-endpoint"),
+		MetricsTextfile:   c.String("prometheus-textfile"),
+		Netns:             c.GlobalString("netns"),
 		NoFollowRedirects: c.Bool("no-follow-redirects"),
+		NotifyPreset:      c.String("notify-preset"),
+		NotifyTemplate:    c.String("notify-template"),
+		NotifyURL:         c.String("notify-url"),
+		OutputDetailsFile: c.String("output-details-file"),
 		OutputFormat:      c.String("format"),
 		PackageManager:    c.GlobalString("package"),
 		Password:          c.String("password"),
+		PostRunExec:       c.String("post-run-exec"),
+		Preflight:         c.Bool("preflight"),
+		Procfs:            c.GlobalBool("procfs"),
+		Pushgateway:       c.String("pushgateway"),
+		Record:            c.String("record"),
+		Redact:            c.Bool("redact"),
+		Replay:            c.String("replay"),
 		RetryTimeout:      c.Duration("retry-timeout"),
+		ScoreThreshold:    c.Float64("score-threshold"),
+		ServeConfig:       c.String("serve-config"),
+		ServeEndpoints:    c.StringSlice("serve-endpoint"),
 		Server:            c.String("server"),
+		SeverityExitCode:  c.StringSlice("severity-exit-code"),
+		SkipTags:          splitList(c.StringSlice("skip-tags")),
 		Sleep:             c.Duration("sleep"),
+		SortResults:       c.Bool("sort"),
 		Spec:              c.GlobalString("gossfile"),
+		TLSCert:           c.String("tls-cert"),
+		TLSClientCA:       c.String("tls-client-ca"),
+		TLSKey:            c.String("tls-key"),
+		Tags:              splitList(c.StringSlice("tags")),
+		TemplatePrograms:  c.GlobalStringSlice("template-func"),
 		Timeout:           c.Duration("timeout"),
+		UnprivilegedUser:  c.String("unprivileged-user"),
 		Username:          c.String("username"),
-		Vars:              c.GlobalString("vars"),
+		Vars:              varsFile,
 		VarsInline:        c.GlobalString("vars-inline"),
+		VarsSources:       vars,
+		Version:           version,
+		WatchInterval:     c.Duration("interval"),
+		WatchSchedule:     c.String("schedule-file"),
+		WatchStatusFile:   c.String("status-file"),
 	}
 
+	util.WithFormatOptions(c.StringSlice("format-options")...)(cfg)
+
 	if c.Bool("no-color") {
 		util.WithNoColor()(cfg)
 	}
@@ -56,15 +113,45 @@ func newRuntimeConfigFromCLI(c *cli.Context) *util.Config {
 	return cfg
 }
 
+// splitList splits the comma separated values of a flag that may also be
+// specified multiple times
+func splitList(values []string) []string {
+	var list []string
+	for _, v := range values {
+		for _, e := range strings.Split(v, ",") {
+			if e = strings.TrimSpace(e); e != "" {
+				list = append(list, e)
+			}
+		}
+	}
+	return list
+}
+
 func timeoutFlag(value time.Duration) cli.DurationFlag {
 	return cli.DurationFlag{
-		Name:  "timeout",
-		Value: value,
+		Name:   "timeout",
+		Value:  value,
+		EnvVar: "GOSS_TIMEOUT",
 	}
 }
 
 func main() {
-	startTime := time.Now()
+	app := newApp(time.Now())
+	addAlphaFlagIfNeeded(app)
+	warnAlphaIfNeeded()
+	args, err := goss.BundleArgs(os.Args)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = app.Run(args)
+	if err != nil {
+		log.Fatal(err)
+	}
+	warnAlphaIfNeeded()
+}
+
+// newApp is the goss command line, startTime is when goss started
+func newApp(startTime time.Time) *cli.App {
 	app := cli.NewApp()
 	app.EnableBashCompletion = true
 	app.Version = version
@@ -77,9 +164,9 @@ func main() {
 			Usage:  "Goss file to read from / write to",
 			EnvVar: "GOSS_FILE",
 		},
-		cli.StringFlag{
+		cli.StringSliceFlag{
 			Name:   "vars",
-			Usage:  "json/yaml file containing variables for template",
+			Usage:  "json/yaml file containing variables for template, or env:PREFIX, vault:path or ssm:/path, can be repeated with later ones winning",
 			EnvVar: "GOSS_VARS",
 		},
 		cli.StringFlag{
@@ -87,9 +174,57 @@ func main() {
 			Usage:  "json/yaml string containing variables for template (overwrites vars)",
 			EnvVar: "GOSS_VARS_INLINE",
 		},
+		cli.StringSliceFlag{
+			Name:   "template-func",
+			Usage:  "Template function running a program, as name=program [args...], its output is the result, can be repeated",
+			EnvVar: "GOSS_TEMPLATE_FUNC",
+		},
+		cli.StringFlag{
+			Name:   "package",
+			Usage:  fmt.Sprintf("Package type to use [%s]", strings.Join(system.SupportedPackageManagers(), ", ")),
+			EnvVar: "GOSS_PACKAGE",
+		},
+		cli.BoolFlag{
+			Name:   "procfs",
+			Usage:  "Read services from /proc and /etc instead of running systemctl or service",
+			EnvVar: "GOSS_PROCFS",
+		},
+		cli.StringFlag{
+			Name:   "dns-server",
+			Usage:  "DNS server the hosts of the addr, dns and http checks are resolved through, as the server of dns",
+			EnvVar: "GOSS_DNS_SERVER",
+		},
+		cli.StringFlag{
+			Name:   "hosts-file",
+			Usage:  "/etc/hosts formatted file the hosts of the addr, dns and http checks are looked up in first",
+			EnvVar: "GOSS_HOSTS_FILE",
+		},
+		cli.StringFlag{
+			Name:   "ip-version",
+			Usage:  "Address family of the addr, dns, http and port checks that don't set ip-version: 4, 6 or any",
+			EnvVar: "GOSS_IP_VERSION",
+		},
+		cli.StringFlag{
+			Name:   "netns",
+			Usage:  "Network namespace, by ip netns name or path, the addr, dns and http checks that don't set netns run in",
+			EnvVar: "GOSS_NETNS",
+		},
+		cli.StringFlag{
+			Name:   "config",
+			Usage:  "Config file with the default values of flags, instead of .goss.yaml and the config.yaml of the user",
+			EnvVar: "GOSS_CONFIG",
+		},
 		cli.StringFlag{
-			Name:  "package",
-			Usage: fmt.Sprintf("Package type to use [%s]", strings.Join(system.SupportedPackageManagers(), ", ")),
+			Name:   "log-format",
+			Value:  "text",
+			Usage:  fmt.Sprintf("Format of the logs written to stderr, separate from the results [%s]", strings.Join(util.LogFormats, ", ")),
+			EnvVar: "GOSS_LOG_FORMAT",
+		},
+		cli.StringFlag{
+			Name:   "log-level",
+			Value:  "info",
+			Usage:  fmt.Sprintf("Level of the logs, debug traces the lifecycle of each check [%s]", strings.Join(util.LogLevels, ", ")),
+			EnvVar: "GOSS_LOG_LEVEL",
 		},
 	}
 	app.Commands = []cli.Command{
@@ -109,6 +244,17 @@ func main() {
 					Usage:  fmt.Sprintf("Extra options passed to the formatter, valid options: %s", outputs.FormatOptions()),
 					EnvVar: "GOSS_FMT_OPTIONS",
 				},
+				cli.Float64Flag{
+					Name:   "score-threshold",
+					Usage:  "Lowest score in percent the score format passes with",
+					Value:  100,
+					EnvVar: "GOSS_SCORE_THRESHOLD",
+				},
+				cli.StringFlag{
+					Name:   "lang",
+					Usage:  fmt.Sprintf("Language for human readable output, defaults to the system locale, valid options: %s", outputs.Languages()),
+					EnvVar: "GOSS_LANG",
+				},
 				cli.BoolFlag{
 					Name:   "color",
 					Usage:  "Force color on",
@@ -137,10 +283,155 @@ func main() {
 					Value:  50,
 					EnvVar: "GOSS_MAX_CONCURRENT",
 				},
+				cli.StringSliceFlag{
+					Name:   "max-concurrent-type",
+					Usage:  "Validate a resource type with a pool of workers of its own, type=limit such as http=5, may be specified multiple times",
+					EnvVar: "GOSS_MAX_CONCURRENT_TYPE",
+				},
+				cli.DurationFlag{
+					Name:   "max-run-duration",
+					Usage:  "Report the tests that haven't finished after this long as timed out, 0 is unlimited",
+					EnvVar: "GOSS_MAX_RUN_DURATION",
+				},
+				cli.StringFlag{
+					Name:   "maintenance-file",
+					Usage:  "YAML/JSON file of maintenance windows, failures of matching tests are reported as warnings",
+					EnvVar: "GOSS_MAINTENANCE_FILE",
+				},
+				cli.StringFlag{
+					Name:   "baseline",
+					Usage:  "Results of a previous run in the json or structured format, only tests that passed there fail the run",
+					EnvVar: "GOSS_BASELINE",
+				},
+				cli.StringSliceFlag{
+					Name:   "severity-exit-code",
+					Usage:  "Exit code of runs whose most severe tests that didn't pass are of a severity, severity=code such as warn=3, may be specified multiple times (default: fail=1, warn=0, info=0)",
+					EnvVar: "GOSS_SEVERITY_EXIT_CODE",
+				},
+				cli.StringFlag{
+					Name:   "audit-log",
+					Usage:  "Append the results of each run to this NDJSON file, chained by their hashes, check it with goss audit",
+					EnvVar: "GOSS_AUDIT_LOG",
+				},
+				cli.StringFlag{
+					Name:   "prometheus-textfile",
+					Usage:  "Write the results of each run as Prometheus metrics to this file, for the textfile collector of node_exporter",
+					EnvVar: "GOSS_PROMETHEUS_TEXTFILE",
+				},
+				cli.StringFlag{
+					Name:   "pushgateway",
+					Usage:  "Push the results of each run as Prometheus metrics to the Pushgateway at this URL",
+					EnvVar: "GOSS_PUSHGATEWAY",
+				},
+				cli.StringFlag{
+					Name:   "notify-url",
+					Usage:  "Post a summary of the runs that fail to this webhook",
+					EnvVar: "GOSS_NOTIFY_URL",
+				},
+				cli.StringFlag{
+					Name:   "notify-preset",
+					Value:  "json",
+					Usage:  "Payload of --notify-url: json, slack or teams",
+					EnvVar: "GOSS_NOTIFY_PRESET",
+				},
+				cli.StringFlag{
+					Name:   "notify-template",
+					Usage:  "Go template of the payload of --notify-url, instead of the preset",
+					EnvVar: "GOSS_NOTIFY_TEMPLATE",
+				},
+				cli.StringFlag{
+					Name:   "post-run-exec",
+					Usage:  "Run this program after each run with the results as json on its stdin",
+					EnvVar: "GOSS_POST_RUN_EXEC",
+				},
+				cli.IntFlag{
+					Name:   "max-output-bytes",
+					Usage:  "Truncate the output of each result to this many bytes, 0 is unlimited",
+					EnvVar: "GOSS_MAX_OUTPUT_BYTES",
+				},
+				cli.BoolFlag{
+					Name:   "redact",
+					Usage:  "Replace hostnames, IP addresses and home directory paths in the output with placeholders",
+					EnvVar: "GOSS_REDACT",
+				},
+				cli.BoolFlag{
+					Name:   "sort",
+					Usage:  "Sort the results by resource type, id and property instead of reporting them in gossfile order",
+					EnvVar: "GOSS_SORT",
+				},
+				cli.StringFlag{
+					Name:   "record",
+					Usage:  "Write the values the tests read from the system to this file",
+					EnvVar: "GOSS_RECORD",
+				},
+				cli.StringFlag{
+					Name:   "replay",
+					Usage:  "Evaluate the tests against the values recorded in this file instead of the system",
+					EnvVar: "GOSS_REPLAY",
+				},
+				cli.StringSliceFlag{
+					Name:   "tags",
+					Usage:  "Only validate the resources with one of these tags, comma separated or specified multiple times",
+					EnvVar: "GOSS_TAGS",
+				},
+				cli.StringSliceFlag{
+					Name:   "skip-tags",
+					Usage:  "Don't validate the resources with one of these tags, comma separated or specified multiple times",
+					EnvVar: "GOSS_SKIP_TAGS",
+				},
+				cli.StringFlag{
+					Name:   "command-policy",
+					Usage:  "Policy file restricting the executables command resources may run",
+					EnvVar: "GOSS_COMMAND_POLICY",
+				},
+				cli.StringFlag{
+					Name:   "unprivileged-user",
+					Usage:  "Run http, dns and unprivileged command checks as this user, usually nobody",
+					EnvVar: "GOSS_UNPRIVILEGED_USER",
+				},
+				cli.StringFlag{
+					Name:   "output-details-file",
+					Usage:  "Write the full details of truncated results to this file, only active when --max-output-bytes is set",
+					EnvVar: "GOSS_OUTPUT_DETAILS_FILE",
+				},
+				cli.BoolFlag{
+					Name:   "preflight",
+					Usage:  "Report checks that will be unreliable with the privileges goss runs with before validating",
+					EnvVar: "GOSS_PREFLIGHT",
+				},
+				cli.BoolFlag{
+					Name:   "watch",
+					Usage:  "Keep validating every --interval and when the gossfile changes, printing only the tests whose result changed",
+					EnvVar: "GOSS_WATCH",
+				},
+				cli.DurationFlag{
+					Name:   "interval",
+					Usage:  "Time between the runs of --watch",
+					Value:  30 * time.Second,
+					EnvVar: "GOSS_INTERVAL",
+				},
+				cli.StringFlag{
+					Name:   "schedule-file",
+					Usage:  "Validate the resources with the tags of a schedule of this YAML file on its cron schedule with --watch, the others every --interval",
+					EnvVar: "GOSS_SCHEDULE_FILE",
+				},
+				cli.StringFlag{
+					Name:   "status-file",
+					Usage:  "Write the exit code and counts of the latest run of --watch to this JSON file",
+					EnvVar: "GOSS_STATUS_FILE",
+				},
 			},
 			Action: func(c *cli.Context) error {
 				fatalAlphaIfNeeded(c)
-				code, err := goss.Validate(newRuntimeConfigFromCLI(c), startTime)
+				var code int
+				var err error
+				if c.Bool("watch") {
+					code, err = goss.Watch(newRuntimeConfigFromCLI(c))
+				} else if c.String("schedule-file") != "" {
+					code, err = 1, fmt.Errorf("--schedule-file can only be used with --watch")
+				} else {
+					code, err = goss.Validate(newRuntimeConfigFromCLI(c), startTime)
+				}
 				if err != nil {
 					color.Red(fmt.Sprintf("Error: %v\n", err))
 				}
@@ -165,6 +456,17 @@ func main() {
 					Usage:  fmt.Sprintf("Extra options passed to the formatter, valid options: %s", outputs.FormatOptions()),
 					EnvVar: "GOSS_FMT_OPTIONS",
 				},
+				cli.Float64Flag{
+					Name:   "score-threshold",
+					Usage:  "Lowest score in percent the score format passes with",
+					Value:  100,
+					EnvVar: "GOSS_SCORE_THRESHOLD",
+				},
+				cli.StringFlag{
+					Name:   "lang",
+					Usage:  fmt.Sprintf("Language for human readable output, defaults to the system locale, valid options: %s", outputs.Languages()),
+					EnvVar: "GOSS_LANG",
+				},
 				cli.DurationFlag{
 					Name:   "cache,c",
 					Usage:  "Time to cache the results",
@@ -177,32 +479,454 @@ func main() {
 					Usage:  "Address to listen on [ip]:port",
 					EnvVar: "GOSS_LISTEN",
 				},
+				cli.StringFlag{
+					Name:   "grpc-listen-addr",
+					Usage:  "Also serve the grpc.health.v1.Health service on this [ip]:port",
+					EnvVar: "GOSS_GRPC_LISTEN",
+				},
 				cli.StringFlag{
 					Name:   "endpoint,e",
 					Value:  "/healthz",
 					Usage:  "Endpoint to expose",
 					EnvVar: "GOSS_ENDPOINT",
 				},
+				cli.StringFlag{
+					Name:   "metrics-endpoint",
+					Value:  "/metrics",
+					Usage:  "Endpoint to expose the results as Prometheus metrics on, empty to disable",
+					EnvVar: "GOSS_METRICS_ENDPOINT",
+				},
+				cli.StringSliceFlag{
+					Name:   "serve-endpoint",
+					Usage:  "Also expose path=gossfile[@cache], such as /readyz=deep.yaml@60s, may be specified multiple times",
+					EnvVar: "GOSS_SERVE_ENDPOINTS",
+				},
+				cli.StringFlag{
+					Name:   "serve-config",
+					Usage:  "YAML/JSON file of more endpoints to expose, each with its own gossfile and cache",
+					EnvVar: "GOSS_SERVE_CONFIG",
+				},
+				cli.StringFlag{
+					Name:   "tls-cert",
+					Usage:  "PEM certificate file to serve the endpoints over TLS with, with --tls-key",
+					EnvVar: "GOSS_TLS_CERT",
+				},
+				cli.StringFlag{
+					Name:   "tls-key",
+					Usage:  "PEM private key file of --tls-cert",
+					EnvVar: "GOSS_TLS_KEY",
+				},
+				cli.StringFlag{
+					Name:   "tls-client-ca",
+					Usage:  "Require client certificates signed by a CA of this PEM file",
+					EnvVar: "GOSS_TLS_CLIENT_CA",
+				},
+				cli.StringFlag{
+					Name:   "basic-auth",
+					Usage:  "Require basic authentication with this user:password",
+					EnvVar: "GOSS_BASIC_AUTH",
+				},
+				cli.StringFlag{
+					Name:   "bearer-token",
+					Usage:  "Require an Authorization: Bearer header with this token",
+					EnvVar: "GOSS_BEARER_TOKEN",
+				},
 				cli.IntFlag{
 					Name:   "max-concurrent",
 					Usage:  "Max number of tests to run concurrently",
 					Value:  50,
 					EnvVar: "GOSS_MAX_CONCURRENT",
 				},
+				cli.StringSliceFlag{
+					Name:   "max-concurrent-type",
+					Usage:  "Validate a resource type with a pool of workers of its own, type=limit such as http=5, may be specified multiple times",
+					EnvVar: "GOSS_MAX_CONCURRENT_TYPE",
+				},
+				cli.DurationFlag{
+					Name:   "max-run-duration",
+					Usage:  "Report the tests that haven't finished after this long as timed out, 0 is unlimited",
+					EnvVar: "GOSS_MAX_RUN_DURATION",
+				},
+				cli.StringFlag{
+					Name:   "maintenance-file",
+					Usage:  "YAML/JSON file of maintenance windows, failures of matching tests are reported as warnings",
+					EnvVar: "GOSS_MAINTENANCE_FILE",
+				},
+				cli.StringFlag{
+					Name:   "post-run-exec",
+					Usage:  "Run this program after each refresh of the results with them as json on its stdin",
+					EnvVar: "GOSS_POST_RUN_EXEC",
+				},
+				cli.IntFlag{
+					Name:   "max-output-bytes",
+					Usage:  "Truncate the output of each result to this many bytes, 0 is unlimited",
+					EnvVar: "GOSS_MAX_OUTPUT_BYTES",
+				},
+				cli.BoolFlag{
+					Name:   "redact",
+					Usage:  "Replace hostnames, IP addresses and home directory paths in the output with placeholders",
+					EnvVar: "GOSS_REDACT",
+				},
+				cli.BoolFlag{
+					Name:   "sort",
+					Usage:  "Sort the results by resource type, id and property instead of reporting them in gossfile order",
+					EnvVar: "GOSS_SORT",
+				},
+				cli.StringSliceFlag{
+					Name:   "tags",
+					Usage:  "Only validate the resources with one of these tags, comma separated or specified multiple times",
+					EnvVar: "GOSS_TAGS",
+				},
+				cli.StringSliceFlag{
+					Name:   "skip-tags",
+					Usage:  "Don't validate the resources with one of these tags, comma separated or specified multiple times",
+					EnvVar: "GOSS_SKIP_TAGS",
+				},
+				cli.StringFlag{
+					Name:   "command-policy",
+					Usage:  "Policy file restricting the executables command resources may run",
+					EnvVar: "GOSS_COMMAND_POLICY",
+				},
+				cli.StringFlag{
+					Name:   "unprivileged-user",
+					Usage:  "Run http, dns and unprivileged command checks as this user, usually nobody",
+					EnvVar: "GOSS_UNPRIVILEGED_USER",
+				},
 			},
 			Action: func(c *cli.Context) error {
 				fatalAlphaIfNeeded(c)
 				return goss.Serve(newRuntimeConfigFromCLI(c))
 			},
 		},
+		{
+			Name:   "unprivileged-worker",
+			Usage:  "validate a json gossfile from stdin for --unprivileged-user",
+			Hidden: true,
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "max-concurrent",
+					Value: 50,
+				},
+				cli.StringSliceFlag{
+					Name: "max-concurrent-type",
+				},
+				cli.DurationFlag{
+					Name: "max-run-duration",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				resolver, err := system.NewResolver(c.GlobalString("dns-server"), c.GlobalString("hosts-file"))
+				if err != nil {
+					return err
+				}
+				sys := system.New("")
+				sys.Resolver, sys.IPVersion = resolver, c.GlobalString("ip-version")
+				return goss.UnprivilegedWorker(os.Stdin, os.Stdout, c.Int("max-concurrent"), c.StringSlice("max-concurrent-type"), c.Duration("max-run-duration"), sys)
+			},
+		},
+		{
+			Name:            "sandbox-exec",
+			Usage:           "run a command in the sandbox of command resources",
+			Hidden:          true,
+			SkipFlagParsing: true,
+			Action: func(c *cli.Context) error {
+				argv := []string(c.Args())
+				if len(argv) > 0 && argv[0] == "--" {
+					argv = argv[1:]
+				}
+				return system.SandboxExec(argv)
+			},
+		},
+		{
+			Name:    "test",
+			Aliases: []string{"t"},
+			Usage:   "Test the gossfile against the fake commands, files and http responses of fixtures",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:   "fixtures",
+					Value:  "goss_fixtures.yaml",
+					Usage:  "Fixtures file with the test cases",
+					EnvVar: "GOSS_FIXTURES",
+				},
+				cli.IntFlag{
+					Name:   "max-concurrent",
+					Usage:  "Max number of tests to run concurrently",
+					Value:  50,
+					EnvVar: "GOSS_MAX_CONCURRENT",
+				},
+				cli.StringSliceFlag{
+					Name:   "max-concurrent-type",
+					Usage:  "Validate a resource type with a pool of workers of its own, type=limit such as http=5, may be specified multiple times",
+					EnvVar: "GOSS_MAX_CONCURRENT_TYPE",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				fatalAlphaIfNeeded(c)
+				code, err := goss.RunFixtures(newRuntimeConfigFromCLI(c))
+				if err != nil {
+					color.Red(fmt.Sprintf("Error: %v\n", err))
+				}
+				os.Exit(code)
+
+				return nil
+			},
+		},
+		{
+			Name:    "inspect",
+			Aliases: []string{"i"},
+			Usage:   "print everything goss can read about a resource, as inspect <resource-type> <id>, without testing it",
+			Flags: []cli.Flag{
+				timeoutFlag(10 * time.Second),
+			},
+			Action: func(c *cli.Context) error {
+				fatalAlphaIfNeeded(c)
+				if len(c.Args()) != 2 {
+					return fmt.Errorf("usage: goss inspect <resource-type> <id>, such as goss inspect file /etc/hosts")
+				}
+
+				return goss.Inspect(c.Args()[0], c.Args()[1], newRuntimeConfigFromCLI(c))
+			},
+		},
+		{
+			Name:  "lint",
+			Usage: "Check the gossfile and the gossfiles it includes against the gossfile JSON Schema, and their matchers, without validating",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:   "format, f",
+					Value:  "text",
+					Usage:  "Output format, text or json",
+					EnvVar: "GOSS_LINT_FORMAT",
+				},
+				cli.BoolFlag{
+					Name:   "schema",
+					Usage:  "Print the gossfile JSON Schema instead",
+					EnvVar: "GOSS_LINT_SCHEMA",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				fatalAlphaIfNeeded(c)
+				if c.Bool("schema") {
+					b, err := goss.GossfileSchemaJSON()
+					if err != nil {
+						return err
+					}
+					fmt.Print(string(b))
+					return nil
+				}
+				code, err := goss.Lint(newRuntimeConfigFromCLI(c))
+				if err != nil {
+					color.Red(fmt.Sprintf("Error: %v\n", err))
+				}
+				os.Exit(code)
+
+				return nil
+			},
+		},
+		{
+			Name:    "match",
+			Aliases: []string{"m"},
+			Usage:   "match a value against a matcher without running the tests, or try matchers one after the other without --matcher",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:   "matcher",
+					Usage:  "Matcher, as it's written in a gossfile, such as '{have-prefix: foo}'",
+					EnvVar: "GOSS_MATCHER",
+				},
+				cli.StringFlag{
+					Name:   "value",
+					Usage:  "Value to match, as yaml, such as '[80, 443]' or '\"8080\"' for a string",
+					EnvVar: "GOSS_MATCH_VALUE",
+				},
+				cli.StringFlag{
+					Name:   "value-file",
+					Usage:  "File with the value to match, its contents are a string",
+					EnvVar: "GOSS_MATCH_VALUE_FILE",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				fatalAlphaIfNeeded(c)
+				config := newRuntimeConfigFromCLI(c)
+				value, err := goss.ReadMatchValue(c.String("value"), c.String("value-file"))
+				if err != nil {
+					return err
+				}
+				if c.String("matcher") == "" {
+					return goss.MatchREPL(os.Stdin, value, config)
+				}
+				ok, err := goss.Match(c.String("matcher"), value, config)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					os.Exit(1)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "audit",
+			Usage: "verify the hash chain of an audit log written by validate --audit-log, as audit <file>",
+			Action: func(c *cli.Context) error {
+				if len(c.Args()) != 1 {
+					return fmt.Errorf("usage: goss audit <file>")
+				}
+				runs, err := goss.VerifyAuditLog(c.Args()[0])
+				if err != nil {
+					return err
+				}
+				fmt.Printf("%d runs, the hash chain is intact\n", runs)
+				return nil
+			},
+		},
+		{
+			Name:  "snapshot",
+			Usage: "record the values every test of the gossfile reads from the system, as snapshot [file], to compare them with diff",
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:   "max-concurrent",
+					Usage:  "Max number of tests to run concurrently",
+					Value:  50,
+					EnvVar: "GOSS_MAX_CONCURRENT",
+				},
+				cli.DurationFlag{
+					Name:   "max-run-duration",
+					Usage:  "Report the tests that haven't finished after this long as timed out, 0 is unlimited",
+					EnvVar: "GOSS_MAX_RUN_DURATION",
+				},
+				cli.StringSliceFlag{
+					Name:   "tags",
+					Usage:  "Only snapshot the resources with one of these tags, comma separated or specified multiple times",
+					EnvVar: "GOSS_TAGS",
+				},
+				cli.StringSliceFlag{
+					Name:   "skip-tags",
+					Usage:  "Don't snapshot the resources with one of these tags, comma separated or specified multiple times",
+					EnvVar: "GOSS_SKIP_TAGS",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				fatalAlphaIfNeeded(c)
+				if len(c.Args()) > 1 {
+					return fmt.Errorf("usage: goss snapshot [file]")
+				}
+				if len(c.Args()) == 0 {
+					return goss.Snapshot(newRuntimeConfigFromCLI(c), os.Stdout)
+				}
+				fh, err := os.Create(c.Args()[0])
+				if err != nil {
+					return fmt.Errorf("snapshot file error: %v", err)
+				}
+				if err := goss.Snapshot(newRuntimeConfigFromCLI(c), fh); err != nil {
+					fh.Close()
+					return err
+				}
+				return fh.Close()
+			},
+		},
+		{
+			Name:  "bundle",
+			Usage: "write a goss binary with the gossfile, its vars and includes embedded, that validates them when run",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:   "output, o",
+					Usage:  "Path of the bundle to write",
+					EnvVar: "GOSS_BUNDLE_OUTPUT",
+				},
+				cli.StringFlag{
+					Name:   "goss-binary",
+					Usage:  "Goss binary to bundle, such as one for another platform, default is the running one",
+					EnvVar: "GOSS_BUNDLE_BINARY",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				fatalAlphaIfNeeded(c)
+				if c.String("output") == "" {
+					return fmt.Errorf("usage: goss bundle --output <file>")
+				}
+				return goss.Bundle(newRuntimeConfigFromCLI(c), c.String("output"), c.String("goss-binary"))
+			},
+		},
+		{
+			Name:  "diff",
+			Usage: "report the drift between two snapshots, or a snapshot and the system, as diff <before> [after]",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:   "format, f",
+					Value:  "text",
+					Usage:  "Output format, text or json",
+					EnvVar: "GOSS_DIFF_FORMAT",
+				},
+				cli.IntFlag{
+					Name:   "max-concurrent",
+					Usage:  "Max number of tests to run concurrently",
+					Value:  50,
+					EnvVar: "GOSS_MAX_CONCURRENT",
+				},
+				cli.DurationFlag{
+					Name:   "max-run-duration",
+					Usage:  "Report the tests that haven't finished after this long as timed out, 0 is unlimited",
+					EnvVar: "GOSS_MAX_RUN_DURATION",
+				},
+				cli.StringSliceFlag{
+					Name:   "tags",
+					Usage:  "Only snapshot the resources with one of these tags without after, comma separated or specified multiple times",
+					EnvVar: "GOSS_TAGS",
+				},
+				cli.StringSliceFlag{
+					Name:   "skip-tags",
+					Usage:  "Don't snapshot the resources with one of these tags without after, comma separated or specified multiple times",
+					EnvVar: "GOSS_SKIP_TAGS",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				fatalAlphaIfNeeded(c)
+				if len(c.Args()) < 1 || len(c.Args()) > 2 {
+					return fmt.Errorf("usage: goss diff <before> [after]")
+				}
+				code, err := goss.Diff(newRuntimeConfigFromCLI(c), c.Args()[0], c.Args().Get(1))
+				if err != nil {
+					color.Red(fmt.Sprintf("Error: %v\n", err))
+				}
+				os.Exit(code)
+
+				return nil
+			},
+		},
+		{
+			Name:  "prune",
+			Usage: "list the resources of the gossfile whose files, packages and other targets no longer exist, and remove them with --remove",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:   "remove",
+					Usage:  "Remove the resources from the gossfile, once confirmed",
+					EnvVar: "GOSS_PRUNE_REMOVE",
+				},
+				cli.BoolFlag{
+					Name:   "yes, y",
+					Usage:  "Remove the resources without asking for confirmation",
+					EnvVar: "GOSS_PRUNE_YES",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				fatalAlphaIfNeeded(c)
+				code, err := goss.Prune(newRuntimeConfigFromCLI(c), c.Bool("remove"), c.Bool("yes"), os.Stdin)
+				if err != nil {
+					color.Red(fmt.Sprintf("Error: %v\n", err))
+				}
+				os.Exit(code)
+
+				return nil
+			},
+		},
 		{
 			Name:    "render",
 			Aliases: []string{"r"},
 			Usage:   "render gossfile after imports",
 			Flags: []cli.Flag{
 				cli.BoolFlag{
-					Name:  "debug, d",
-					Usage: fmt.Sprintf("Print debugging info when rendering"),
+					Name:   "debug, d",
+					Usage:  fmt.Sprintf("Print debugging info when rendering"),
+					EnvVar: "GOSS_DEBUG",
 				},
 			},
 			Action: func(c *cli.Context) error {
@@ -221,6 +945,13 @@ func main() {
 			Name:    "autoadd",
 			Aliases: []string{"aa"},
 			Usage:   "automatically add all matching resource to the test suite",
+			Flags: []cli.Flag{
+				cli.StringSliceFlag{
+					Name:   "discover",
+					Usage:  "Also add the resources found on the system: containers (running), services (enabled in systemd), ports (listening) or all",
+					EnvVar: "GOSS_DISCOVER",
+				},
+			},
 			Action: func(c *cli.Context) error {
 				fatalAlphaIfNeeded(c)
 				return goss.AutoAddResources(c.GlobalString("gossfile"), c.Args(), newRuntimeConfigFromCLI(c))
@@ -232,8 +963,9 @@ func main() {
 			Usage:   "add a resource to the test suite",
 			Flags: []cli.Flag{
 				cli.StringSliceFlag{
-					Name:  "exclude-attr",
-					Usage: "Exclude the following attributes when adding a new resource",
+					Name:   "exclude-attr",
+					Usage:  "Exclude the following attributes when adding a new resource",
+					EnvVar: "GOSS_EXCLUDE_ATTR",
 				},
 			},
 			Subcommands: []cli.Command{
@@ -313,8 +1045,9 @@ func main() {
 					Flags: []cli.Flag{
 						timeoutFlag(500 * time.Millisecond),
 						cli.StringFlag{
-							Name:  "server",
-							Usage: "The IP address of a DNS server to query",
+							Name:   "server",
+							Usage:  "The IP address of a DNS server to query",
+							EnvVar: "GOSS_ADD_DNS_SERVER",
 						},
 					},
 					Action: func(c *cli.Context) error {
@@ -335,19 +1068,38 @@ func main() {
 					Usage: "add new http",
 					Flags: []cli.Flag{
 						cli.BoolFlag{
-							Name: "insecure, k",
+							Name:   "insecure, k",
+							EnvVar: "GOSS_INSECURE",
 						},
 						cli.BoolFlag{
-							Name: "no-follow-redirects, r",
+							Name:   "no-follow-redirects, r",
+							EnvVar: "GOSS_NO_FOLLOW_REDIRECTS",
 						},
 						timeoutFlag(5 * time.Second),
 						cli.StringFlag{
-							Name:  "username, u",
-							Usage: "Username for basic auth",
+							Name:   "username, u",
+							Usage:  "Username for basic auth",
+							EnvVar: "GOSS_USERNAME",
 						},
 						cli.StringFlag{
-							Name:  "password, p",
-							Usage: "Password for basic auth",
+							Name:   "password, p",
+							Usage:  "Password for basic auth",
+							EnvVar: "GOSS_PASSWORD",
+						},
+						cli.StringFlag{
+							Name:   "ca-file",
+							Usage:  "CA bundle used to verify the server certificate",
+							EnvVar: "GOSS_CA_FILE",
+						},
+						cli.StringFlag{
+							Name:   "client-cert",
+							Usage:  "Client certificate for mutual TLS",
+							EnvVar: "GOSS_CLIENT_CERT",
+						},
+						cli.StringFlag{
+							Name:   "client-key",
+							Usage:  "Client private key for mutual TLS",
+							EnvVar: "GOSS_CLIENT_KEY",
 						},
 					},
 					Action: func(c *cli.Context) error {
@@ -388,21 +1140,146 @@ func main() {
 						return goss.AddResources(c.GlobalString("gossfile"), "Interface", c.Args(), newRuntimeConfigFromCLI(c))
 					},
 				},
+				{
+					Name:  "dir",
+					Usage: "add new directory listing",
+					Action: func(c *cli.Context) error {
+						fatalAlphaIfNeeded(c)
+						return goss.AddResources(c.GlobalString("gossfile"), "Dir", c.Args(), newRuntimeConfigFromCLI(c))
+					},
+				},
+				{
+					Name:  "shell-profile",
+					Usage: "add new login shell profile, default or a user name",
+					Action: func(c *cli.Context) error {
+						fatalAlphaIfNeeded(c)
+						return goss.AddResources(c.GlobalString("gossfile"), "ShellProfile", c.Args(), newRuntimeConfigFromCLI(c))
+					},
+				},
+				{
+					Name:  "crypto-policy",
+					Usage: "add new system crypto policy, the only name is system",
+					Action: func(c *cli.Context) error {
+						fatalAlphaIfNeeded(c)
+						return goss.AddResources(c.GlobalString("gossfile"), "CryptoPolicy", c.Args(), newRuntimeConfigFromCLI(c))
+					},
+				},
+				{
+					Name:  "trusted-boot",
+					Usage: "add new Secure Boot and TPM state, the only name is system",
+					Action: func(c *cli.Context) error {
+						fatalAlphaIfNeeded(c)
+						return goss.AddResources(c.GlobalString("gossfile"), "TrustedBoot", c.Args(), newRuntimeConfigFromCLI(c))
+					},
+				},
+				{
+					Name:  "entropy",
+					Usage: "add new entropy and random number generator state, the only name is system",
+					Action: func(c *cli.Context) error {
+						fatalAlphaIfNeeded(c)
+						return goss.AddResources(c.GlobalString("gossfile"), "Entropy", c.Args(), newRuntimeConfigFromCLI(c))
+					},
+				},
+				{
+					Name:  "mac",
+					Usage: "add new SELinux and AppArmor state, the only name is system",
+					Action: func(c *cli.Context) error {
+						fatalAlphaIfNeeded(c)
+						return goss.AddResources(c.GlobalString("gossfile"), "MAC", c.Args(), newRuntimeConfigFromCLI(c))
+					},
+				},
+				{
+					Name:  "container",
+					Usage: "add new docker or podman container",
+					Action: func(c *cli.Context) error {
+						fatalAlphaIfNeeded(c)
+						return goss.AddResources(c.GlobalString("gossfile"), "Container", c.Args(), newRuntimeConfigFromCLI(c))
+					},
+				},
+				{
+					Name:  "k8s",
+					Usage: "add new kubernetes pod, deployment, daemonset or statefulset, as namespace/kind/name",
+					Action: func(c *cli.Context) error {
+						fatalAlphaIfNeeded(c)
+						return goss.AddResources(c.GlobalString("gossfile"), "K8s", c.Args(), newRuntimeConfigFromCLI(c))
+					},
+				},
+				{
+					Name:  "kv",
+					Usage: "add new redis or memcached server, as redis://host:port or memcached://host:port",
+					Action: func(c *cli.Context) error {
+						fatalAlphaIfNeeded(c)
+						return goss.AddResources(c.GlobalString("gossfile"), "KV", c.Args(), newRuntimeConfigFromCLI(c))
+					},
+				},
+				{
+					Name:  "ntp",
+					Usage: "add new time synchronization state, the only name is system",
+					Action: func(c *cli.Context) error {
+						fatalAlphaIfNeeded(c)
+						return goss.AddResources(c.GlobalString("gossfile"), "NTP", c.Args(), newRuntimeConfigFromCLI(c))
+					},
+				},
+				{
+					Name:  "sockets",
+					Usage: "add new TCP socket counts of all, a port, remote:<port> or process:<name>",
+					Action: func(c *cli.Context) error {
+						fatalAlphaIfNeeded(c)
+						return goss.AddResources(c.GlobalString("gossfile"), "Sockets", c.Args(), newRuntimeConfigFromCLI(c))
+					},
+				},
+				{
+					Name:  "ping",
+					Usage: "add new ICMP echo of a host - ex: 10.0.0.1 or gateway.example.com",
+					Flags: []cli.Flag{
+						timeoutFlag(time.Second),
+					},
+					Action: func(c *cli.Context) error {
+						fatalAlphaIfNeeded(c)
+						return goss.AddResources(c.GlobalString("gossfile"), "Ping", c.Args(), newRuntimeConfigFromCLI(c))
+					},
+				},
+				{
+					Name:  "grpc",
+					Usage: "add new gRPC health check of a server - ex: localhost:50051",
+					Flags: []cli.Flag{
+						timeoutFlag(5 * time.Second),
+					},
+					Action: func(c *cli.Context) error {
+						fatalAlphaIfNeeded(c)
+						return goss.AddResources(c.GlobalString("gossfile"), "GRPC", c.Args(), newRuntimeConfigFromCLI(c))
+					},
+				},
+				{
+					Name:  "websocket",
+					Usage: "add new WebSocket upgrade check of a URL - ex: wss://example.com/ws",
+					Flags: []cli.Flag{
+						timeoutFlag(5 * time.Second),
+					},
+					Action: func(c *cli.Context) error {
+						fatalAlphaIfNeeded(c)
+						return goss.AddResources(c.GlobalString("gossfile"), "WebSocket", c.Args(), newRuntimeConfigFromCLI(c))
+					},
+				},
 			},
 		},
 	}
 
-	addAlphaFlagIfNeeded(app)
-	warnAlphaIfNeeded()
-	err := app.Run(os.Args)
-	if err != nil {
-		log.Fatal(err)
+	useDefaults(app)
+	return app
+}
+
+// isAlphaPlatform reports whether goss is alpha-quality on this platform
+func isAlphaPlatform() bool {
+	switch runtime.GOOS {
+	case "darwin", "windows", "freebsd", "openbsd", "solaris", "illumos":
+		return true
 	}
-	warnAlphaIfNeeded()
+	return false
 }
 
 func addAlphaFlagIfNeeded(app *cli.App) {
-	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+	if isAlphaPlatform() {
 		app.Flags = append(app.Flags, cli.StringFlag{
 			Name:   "use-alpha",
 			Usage:  fmt.Sprintf("goss is alpha-quality. Set to 1 to use anyway."),
@@ -421,16 +1298,20 @@ Please file issues via https://github.com/aelsabbahy/goss/issues/new/choose
 Pull requests and bug reports very welcome.`
 
 func warnAlphaIfNeeded() {
-	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+	if isAlphaPlatform() {
 		log.Printf(msgFormat, strings.Title(runtime.GOOS))
 	}
 }
 
 func fatalAlphaIfNeeded(c *cli.Context) {
-	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+	if isAlphaPlatform() {
 		if c.GlobalString("use-alpha") != "1" {
 			howto := map[string]string{
 				"darwin":  "export GOSS_USE_ALPHA=1",
+				"freebsd": "export GOSS_USE_ALPHA=1",
+				"openbsd": "export GOSS_USE_ALPHA=1",
+				"solaris": "export GOSS_USE_ALPHA=1",
+				"illumos": "export GOSS_USE_ALPHA=1",
 				"windows": "In cmd:        set GOSS_USE_ALPHA=1\nIn powershell: $env:GOSS_USE_ALPHA=1\nIn bash:       export GOSS_USE_ALPHA=1",
 			}
 			log.Printf(`Terminating.
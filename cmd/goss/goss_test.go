@@ -0,0 +1,41 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/urfave/cli"
+)
+
+func TestFlagEnvVars(t *testing.T) {
+	app := newApp(time.Now())
+	// The same variable sets the same flag in every command
+	flags := map[string]string{}
+	check := func(command string, fs []cli.Flag) {
+		for _, f := range fs {
+			env := reflect.ValueOf(f).FieldByName("EnvVar").String()
+			if env == "" {
+				t.Errorf("%s: flag %s has no environment variable", command, f.GetName())
+				continue
+			}
+			if name, ok := flags[env]; ok && name != f.GetName() {
+				t.Errorf("%s: %s sets both %s and %s", command, env, name, f.GetName())
+			}
+			flags[env] = f.GetName()
+		}
+	}
+	check("goss", app.Flags)
+	var walk func(prefix string, cmds []cli.Command)
+	walk = func(prefix string, cmds []cli.Command) {
+		for _, cmd := range cmds {
+			// Hidden commands are run by goss itself, with their flags
+			if cmd.Hidden {
+				continue
+			}
+			check(prefix+cmd.Name, cmd.Flags)
+			walk(prefix+cmd.Name+" ", cmd.Subcommands)
+		}
+	}
+	walk("goss ", app.Commands)
+}
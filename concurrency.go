@@ -0,0 +1,89 @@
+package goss
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+)
+
+// Concurrency bounds how many resources are validated at once. The resource
+// types of Types, by gossfile key, have a pool of workers of their own so
+// slow network checks or heavy commands don't hold up the other types, which
+// share a pool of Max workers.
+type Concurrency struct {
+	Max   int
+	Types map[string]int
+}
+
+func newConcurrency(c *util.Config) (Concurrency, error) {
+	return parseConcurrency(c.MaxConcurrent, c.MaxConcurrentType)
+}
+
+// parseConcurrency parses the type=limit specs of --max-concurrent-type, such
+// as http=5
+func parseConcurrency(max int, specs []string) (Concurrency, error) {
+	concurrency := Concurrency{Max: max}
+	if max < 1 {
+		return concurrency, fmt.Errorf("max-concurrent must be at least 1, got %d", max)
+	}
+	names := resourceTypeNames()
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, "=", 2)
+		if len(parts) != 2 {
+			return concurrency, fmt.Errorf("max-concurrent-type must be <type>=<limit>, got: %s", spec)
+		}
+		key := strings.TrimSpace(parts[0])
+		if _, ok := names[key]; !ok {
+			return concurrency, fmt.Errorf("max-concurrent-type %s: unknown resource type: %s", spec, key)
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || limit < 1 {
+			return concurrency, fmt.Errorf("max-concurrent-type %s: the limit must be a number of at least 1", spec)
+		}
+		if concurrency.Types == nil {
+			concurrency.Types = make(map[string]int)
+		}
+		concurrency.Types[key] = limit
+	}
+	return concurrency, nil
+}
+
+// specs are the --max-concurrent-type specs of the limits, sorted
+func (c Concurrency) specs() []string {
+	var specs []string
+	for key, limit := range c.Types {
+		specs = append(specs, fmt.Sprintf("%s=%d", key, limit))
+	}
+	sort.Strings(specs)
+	return specs
+}
+
+// pools are the indexes of resources validated by each pool of workers, by
+// the gossfile key of their type and "" for the shared pool, and the number of
+// workers of each pool
+func (c Concurrency) pools(resources []resource.Resource, shared int) (map[string][]int, map[string]int) {
+	keys := make(map[string]string, len(c.Types))
+	for key, name := range resourceTypeNames() {
+		if _, ok := c.Types[key]; ok {
+			keys[name] = key
+		}
+	}
+	indexes := make(map[string][]int)
+	for i, r := range resources {
+		key := keys[reflect.TypeOf(r).Elem().Name()]
+		indexes[key] = append(indexes[key], i)
+	}
+	workers := make(map[string]int, len(indexes))
+	for key := range indexes {
+		workers[key] = c.Types[key]
+		if key == "" {
+			workers[key] = shared
+		}
+	}
+	return indexes, workers
+}
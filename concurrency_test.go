@@ -0,0 +1,69 @@
+package goss
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/system"
+)
+
+func TestParseConcurrency(t *testing.T) {
+	c, err := parseConcurrency(50, []string{"http=5", " command = 2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"http": 5, "command": 2}; !reflect.DeepEqual(c.Types, want) {
+		t.Errorf("got %v, want %v", c.Types, want)
+	}
+	if want := []string{"command=2", "http=5"}; !reflect.DeepEqual(c.specs(), want) {
+		t.Errorf("specs: got %v, want %v", c.specs(), want)
+	}
+	for _, specs := range [][]string{{"http"}, {"htp=5"}, {"http=0"}, {"http=five"}} {
+		if _, err := parseConcurrency(50, specs); err == nil {
+			t.Errorf("%v: expected an error", specs)
+		}
+	}
+	if _, err := parseConcurrency(0, nil); err == nil {
+		t.Error("max-concurrent 0: expected an error")
+	}
+}
+
+func TestValidateConcurrencyTypes(t *testing.T) {
+	g, err := ReadJSONData([]byte(`{
+		"command": {
+			"a": {"exec": "sleep 0.3", "exit-status": 0},
+			"b": {"exec": "sleep 0.3", "exit-status": 0},
+			"c": {"exec": "sleep 0.3", "exit-status": 0}
+		},
+		"file": {"/": {"exists": true}}
+	}`), true)
+	checkErr(t, err, "reading gossfile failed")
+	concurrency, err := parseConcurrency(10, []string{"command=1"})
+	checkErr(t, err, "parsing the concurrency failed")
+
+	start := time.Now()
+	var file time.Duration
+	count := 0
+	for rg := range validate(system.New(""), g, concurrency, time.Time{}) {
+		for _, r := range rg {
+			count++
+			if r.Result != resource.SUCCESS {
+				t.Errorf("%s: %s: got result %d", r.ResourceType, r.ResourceId, r.Result)
+			}
+			if r.ResourceType == "File" {
+				file = r.Duration
+			}
+		}
+	}
+	if count != 4 {
+		t.Errorf("expected 4 results, got %d", count)
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Errorf("commands limited to 1 ran concurrently, took %s", elapsed)
+	}
+	if file > 200*time.Millisecond {
+		t.Errorf("the file check waited for the commands, took %s", file)
+	}
+}
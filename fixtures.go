@@ -0,0 +1,417 @@
+package goss
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+// Fixtures is the file goss test reads, each case runs the gossfile against
+// its own fake commands, files and http responses
+type Fixtures struct {
+	Cases map[string]FixtureCase `json:"cases" yaml:"cases"`
+}
+
+type FixtureCase struct {
+	Commands map[string]CommandFixture `json:"commands,omitempty" yaml:"commands,omitempty"`
+	Files    map[string]FileFixture    `json:"files,omitempty" yaml:"files,omitempty"`
+	HTTP     map[string]HTTPFixture    `json:"http,omitempty" yaml:"http,omitempty"`
+	// Fail lists the tests expected to fail as "type: id" or
+	// "type: id: property", every other test has to pass
+	Fail []string `json:"fail,omitempty" yaml:"fail,omitempty"`
+}
+
+type CommandFixture struct {
+	ExitStatus int    `json:"exit-status" yaml:"exit-status"`
+	Stdout     string `json:"stdout,omitempty" yaml:"stdout,omitempty"`
+	Stderr     string `json:"stderr,omitempty" yaml:"stderr,omitempty"`
+}
+
+type FileFixture struct {
+	Contents string `json:"contents,omitempty" yaml:"contents,omitempty"`
+	Mode     string `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Owner    string `json:"owner,omitempty" yaml:"owner,omitempty"`
+	Group    string `json:"group,omitempty" yaml:"group,omitempty"`
+}
+
+type HTTPFixture struct {
+	Status  int      `json:"status,omitempty" yaml:"status,omitempty"`
+	Headers []string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body    string   `json:"body,omitempty" yaml:"body,omitempty"`
+	// CertFingerprints are the SPKI fingerprints of the certificates served
+	CertFingerprints []string `json:"cert-fingerprints,omitempty" yaml:"cert-fingerprints,omitempty"`
+}
+
+func loadFixtures(file string) (*Fixtures, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("fixtures file error: %v", err)
+	}
+	var f Fixtures
+	if err := unmarshalYAML(data, &f); err != nil {
+		return nil, err
+	}
+	if len(f.Cases) == 0 {
+		return nil, fmt.Errorf("found 0 cases, source: %v", file)
+	}
+	for name, c := range f.Cases {
+		for _, h := range c.HTTP {
+			for _, header := range h.Headers {
+				if !strings.Contains(header, ": ") {
+					return nil, fmt.Errorf("case %s: header %q must be \"Name: value\"", name, header)
+				}
+			}
+		}
+	}
+	return &f, nil
+}
+
+// RunFixtures runs the gossfile against every case of the fixtures file, a
+// case passes when exactly the tests in its fail list didn't pass. Only
+// command, file, http and matching resources can be faked, others are left
+// out of the run.
+func RunFixtures(c *util.Config) (int, error) {
+	gossConfig, err := loadGossConfig(c)
+	if err != nil {
+		return 1, err
+	}
+	fixtures, err := loadFixtures(c.Fixtures)
+	if err != nil {
+		return 1, err
+	}
+	concurrency, err := newConcurrency(c)
+	if err != nil {
+		return 1, err
+	}
+
+	var w io.Writer = os.Stdout
+	if c.OutputWriter != nil {
+		w = c.OutputWriter
+	}
+
+	faked, unsupported := fixtureResources(*gossConfig)
+	if len(unsupported) > 0 {
+		fmt.Fprintln(w, color.YellowString("Left out, fixtures can't fake these resource types: %s", strings.Join(unsupported, ", ")))
+	}
+
+	var names []string
+	for name := range fixtures.Cases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var failed int
+	for _, name := range names {
+		problems, err := runFixtureCase(faked, fixtures.Cases[name], concurrency)
+		if err != nil {
+			return 1, fmt.Errorf("case %s: %v", name, err)
+		}
+		if len(problems) == 0 {
+			fmt.Fprintln(w, color.GreenString("--- PASS: %s", name))
+			continue
+		}
+		failed++
+		fmt.Fprintln(w, color.RedString("--- FAIL: %s", name))
+		for _, p := range problems {
+			fmt.Fprintf(w, "    %s\n", p)
+		}
+	}
+
+	fmt.Fprintf(w, "\n%d cases, %d failed\n", len(names), failed)
+	if failed > 0 {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// fixtureResources is the part of gossConfig fixtures can fake, and the
+// types of the resources left out
+func fixtureResources(gossConfig GossConfig) (GossConfig, []string) {
+	faked := GossConfig{
+		Commands:  gossConfig.Commands,
+		Files:     gossConfig.Files,
+		HTTPs:     gossConfig.HTTPs,
+		Matchings: gossConfig.Matchings,
+		order:     gossConfig.order,
+	}
+	seen := make(map[string]bool)
+	var unsupported []string
+	for _, r := range gossConfig.Resources() {
+		switch r.(type) {
+		case *resource.Command, *resource.File, *resource.HTTP, *resource.Matching:
+			continue
+		}
+		t := reflect.TypeOf(r).Elem().Name()
+		if !seen[t] {
+			seen[t] = true
+			unsupported = append(unsupported, t)
+		}
+	}
+	sort.Strings(unsupported)
+	return faked, unsupported
+}
+
+// runFixtureCase validates gossConfig with the fakes of fc, the problems are
+// the tests that didn't pass unexpectedly and the expected failures that
+// didn't happen
+func runFixtureCase(gossConfig GossConfig, fc FixtureCase, concurrency Concurrency) ([]string, error) {
+	dir, err := ioutil.TempDir("", "goss-fixtures")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	sys, err := newFixtureSystem(fc, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := make(map[string]bool)
+	for _, f := range fc.Fail {
+		expected[f] = false
+	}
+	var problems []string
+	for results := range validate(sys, gossConfig, concurrency, runDeadline(0)) {
+		for _, r := range results {
+			if r.Result == resource.SUCCESS || r.Result == resource.SKIP {
+				continue
+			}
+			id := r.ResourceType + ": " + r.ResourceId
+			test := id + ": " + r.Property
+			if _, ok := expected[test]; ok {
+				expected[test] = true
+				continue
+			}
+			if _, ok := expected[id]; ok {
+				expected[id] = true
+				continue
+			}
+			msg := r.Human
+			switch {
+			case r.Err != nil:
+				msg = r.Err.Error()
+			case msg == "":
+				msg = fmt.Sprintf("expected %v, found %v", r.Expected, r.Found)
+			}
+			problems = append(problems, fmt.Sprintf("unexpected failure: %s: %s", test, strings.TrimSpace(msg)))
+		}
+	}
+	sort.Strings(problems)
+	for _, f := range fc.Fail {
+		if !expected[f] {
+			problems = append(problems, fmt.Sprintf("expected to fail but passed: %s", f))
+		}
+	}
+	return problems, nil
+}
+
+// newFixtureSystem is a System whose commands, files and http requests come
+// from fc, the files are written under dir
+func newFixtureSystem(fc FixtureCase, dir string) (*system.System, error) {
+	files := make(map[string]FileFixture)
+	for p, f := range fc.Files {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(fixturePath(dir, abs)), 0755); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(fixturePath(dir, abs), []byte(f.Contents), 0644); err != nil {
+			return nil, err
+		}
+		files[abs] = f
+	}
+
+	return &system.System{
+		NewCommand: func(command string, _ *system.System, _ util.Config) system.Command {
+			f, ok := fc.Commands[command]
+			return &fixtureCommand{command: command, fixture: f, ok: ok}
+		},
+		NewFile: func(path string, sys *system.System, config util.Config) system.File {
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				abs = path
+			}
+			return &fixtureFile{
+				File:    system.NewDefFile(fixturePath(dir, abs), sys, config),
+				path:    path,
+				fixture: files[abs],
+			}
+		},
+		NewHTTP: func(url string, _ *system.System, _ util.Config) system.HTTP {
+			f, ok := fc.HTTP[url]
+			return &fixtureHTTP{url: url, fixture: f, ok: ok}
+		},
+	}, nil
+}
+
+// fixturePath is where the file at the absolute path p is written under dir
+func fixturePath(dir, p string) string {
+	return filepath.Join(dir, strings.TrimPrefix(p, filepath.VolumeName(p)))
+}
+
+type fixtureCommand struct {
+	command string
+	fixture CommandFixture
+	ok      bool
+}
+
+func (c *fixtureCommand) Command() string { return c.command }
+
+func (c *fixtureCommand) err() error {
+	if !c.ok {
+		return fmt.Errorf("no fixture for command %q", c.command)
+	}
+	return nil
+}
+
+func (c *fixtureCommand) Exists() (bool, error) { return c.ok, nil }
+
+func (c *fixtureCommand) ExitStatus() (int, error) {
+	return c.fixture.ExitStatus, c.err()
+}
+
+func (c *fixtureCommand) Stdout() (io.Reader, error) {
+	return strings.NewReader(c.fixture.Stdout), c.err()
+}
+
+func (c *fixtureCommand) Stderr() (io.Reader, error) {
+	return strings.NewReader(c.fixture.Stderr), c.err()
+}
+
+func (c *fixtureCommand) Output() (io.Reader, error) {
+	return strings.NewReader(c.fixture.Stdout + c.fixture.Stderr), c.err()
+}
+
+// fixtureFile is the file written for a fixture, with the path of the
+// gossfile and the mode, owner and group of the fixture. Files without a
+// fixture don't exist.
+type fixtureFile struct {
+	system.File
+	path    string
+	fixture FileFixture
+}
+
+func (f *fixtureFile) Path() string { return f.path }
+
+func (f *fixtureFile) Mode() (string, error) {
+	if f.fixture.Mode != "" {
+		if _, err := f.File.Exists(); err != nil {
+			return "", err
+		}
+		return f.fixture.Mode, nil
+	}
+	return f.File.Mode()
+}
+
+func (f *fixtureFile) Owner() (string, error) {
+	return f.fixtureOr(f.fixture.Owner, f.File.Owner)
+}
+
+func (f *fixtureFile) Group() (string, error) {
+	return f.fixtureOr(f.fixture.Group, f.File.Group)
+}
+
+// fixtureOr is v when the fixture sets it, else what the written file has,
+// root by default as on most target hosts
+func (f *fixtureFile) fixtureOr(v string, actual func() (string, error)) (string, error) {
+	if exists, _ := f.File.Exists(); !exists {
+		return actual()
+	}
+	if v == "" {
+		v = "root"
+	}
+	return v, nil
+}
+
+type fixtureHTTP struct {
+	url     string
+	fixture HTTPFixture
+	ok      bool
+}
+
+func (h *fixtureHTTP) HTTP() string { return h.url }
+
+func (h *fixtureHTTP) err() error {
+	if !h.ok {
+		return fmt.Errorf("no fixture for http %q", h.url)
+	}
+	return nil
+}
+
+func (h *fixtureHTTP) Exists() (bool, error) { return h.ok, nil }
+
+func (h *fixtureHTTP) Status() (int, error) {
+	if h.fixture.Status == 0 {
+		return 200, h.err()
+	}
+	return h.fixture.Status, h.err()
+}
+
+func (h *fixtureHTTP) Headers() (io.Reader, error) {
+	return strings.NewReader(strings.Join(h.fixture.Headers, "\n")), h.err()
+}
+
+func (h *fixtureHTTP) Body() (io.Reader, error) {
+	return bytes.NewReader([]byte(h.fixture.Body)), h.err()
+}
+
+func (h *fixtureHTTP) Latency() (int, error) { return 0, h.err() }
+
+// ContentEncoding is the Content-Encoding of the headers, the body of the
+// fixture is never compressed
+func (h *fixtureHTTP) ContentEncoding() (string, error) {
+	v, _ := headerLine(h.fixture.Headers, "Content-Encoding")
+	return v, h.err()
+}
+
+// EchoedHeader is the header name of the fixture's headers, or of a line of
+// its body
+func (h *fixtureHTTP) EchoedHeader(name string) (string, error) {
+	if err := h.err(); err != nil {
+		return "", err
+	}
+	if v, ok := headerLine(h.fixture.Headers, name); ok {
+		return v, nil
+	}
+	if v, ok := headerLine(strings.Split(h.fixture.Body, "\n"), name); ok {
+		return v, nil
+	}
+	return "", fmt.Errorf("the response doesn't echo %s", name)
+}
+
+// headerLine is the value of the first "name: value" line of lines
+func headerLine(lines []string, name string) (string, bool) {
+	for _, line := range lines {
+		kv := strings.SplitN(line, ":", 2)
+		if len(kv) == 2 && strings.EqualFold(strings.TrimSpace(kv[0]), name) {
+			return strings.TrimSpace(kv[1]), true
+		}
+	}
+	return "", false
+}
+
+func (h *fixtureHTTP) ContentLength() (int, error) { return len(h.fixture.Body), h.err() }
+
+func (h *fixtureHTTP) CertFingerprints() ([]string, error) {
+	if h.fixture.CertFingerprints == nil {
+		return []string{}, h.err()
+	}
+	return h.fixture.CertFingerprints, h.err()
+}
+
+func (h *fixtureHTTP) SetAllowInsecure(bool)     {}
+func (h *fixtureHTTP) SetNoFollowRedirects(bool) {}
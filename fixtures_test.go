@@ -0,0 +1,95 @@
+package goss
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/aelsabbahy/goss/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunFixtures(t *testing.T) {
+	dir := t.TempDir()
+	gossfile := filepath.Join(dir, "goss.yaml")
+	require.NoError(t, ioutil.WriteFile(gossfile, []byte(`
+command:
+  systemctl is-active nginx:
+    exit-status: 0
+    stdout: [active]
+file:
+  /etc/nginx/nginx.conf:
+    exists: true
+    mode: "0644"
+    owner: root
+    contains: [worker_processes 4]
+http:
+  http://localhost/health:
+    status: 200
+    body: ['"ok": true']
+service:
+  nginx:
+    running: true
+`), 0644))
+	fixtures := filepath.Join(dir, "fixtures.yaml")
+	require.NoError(t, ioutil.WriteFile(fixtures, []byte(`
+cases:
+  healthy:
+    commands:
+      systemctl is-active nginx:
+        exit-status: 0
+        stdout: "active\n"
+    files:
+      /etc/nginx/nginx.conf:
+        contents: "worker_processes 4;\n"
+        mode: "0644"
+    http:
+      http://localhost/health:
+        body: '{"ok": true}'
+  down:
+    commands:
+      systemctl is-active nginx:
+        exit-status: 3
+        stdout: "inactive\n"
+    http:
+      http://localhost/health:
+        status: 502
+    fail:
+      - "Command: systemctl is-active nginx"
+      - "File: /etc/nginx/nginx.conf"
+      - "HTTP: http://localhost/health: status"
+      - "HTTP: http://localhost/health: Body"
+  wrong:
+    commands:
+      systemctl is-active nginx:
+        exit-status: 0
+        stdout: "active\n"
+    files:
+      /etc/nginx/nginx.conf:
+        contents: "worker_processes 4;\n"
+        owner: nginx
+    fail:
+      - "Command: systemctl is-active nginx: exit-status"
+`), 0644))
+
+	var out bytes.Buffer
+	c, err := util.NewConfig(util.WithFixtures(fixtures))
+	require.NoError(t, err)
+	c.Spec = gossfile
+	c.OutputWriter = &out
+	code, err := RunFixtures(c)
+	require.NoError(t, err)
+	assert.Equal(t, 1, code)
+
+	got := out.String()
+	assert.Contains(t, got, "fixtures can't fake these resource types: Service")
+	assert.Contains(t, got, "--- PASS: down")
+	assert.Contains(t, got, "--- PASS: healthy")
+	assert.Contains(t, got, "--- FAIL: wrong")
+	assert.Contains(t, got, "unexpected failure: File: /etc/nginx/nginx.conf: owner")
+	assert.Contains(t, got, `unexpected failure: HTTP: http://localhost/health: status: no fixture for http "http://localhost/health"`)
+	assert.Contains(t, got, "expected to fail but passed: Command: systemctl is-active nginx: exit-status")
+	assert.Contains(t, got, "3 cases, 1 failed")
+}
@@ -11,7 +11,8 @@ require (
 	github.com/cheekybits/genny v1.0.0
 	github.com/docker/docker v1.13.1
 	github.com/fatih/color v1.9.0
-	github.com/google/uuid v1.1.1 // indirect
+	github.com/fsnotify/fsnotify v1.4.7
+	github.com/google/uuid v1.1.1
 	github.com/huandu/xstrings v1.3.0 // indirect
 	github.com/imdario/mergo v0.3.8 // indirect
 	github.com/miekg/dns v1.1.27
@@ -22,6 +23,7 @@ require (
 	github.com/patrickmn/go-cache v2.1.0+incompatible
 	github.com/stretchr/testify v1.4.0
 	github.com/urfave/cli v0.0.0-20161102131801-d86a009f5e13
+	golang.org/x/net v0.0.0-20190923162816-aa69164e4478
 	gopkg.in/yaml.v2 v2.2.8
 )
 
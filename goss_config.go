@@ -2,53 +2,117 @@ package goss
 
 import (
 	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
 
 	"github.com/aelsabbahy/goss/resource"
 )
 
 type GossConfig struct {
-	Files        resource.FileMap        `json:"file,omitempty" yaml:"file,omitempty"`
-	Packages     resource.PackageMap     `json:"package,omitempty" yaml:"package,omitempty"`
-	Addrs        resource.AddrMap        `json:"addr,omitempty" yaml:"addr,omitempty"`
-	Ports        resource.PortMap        `json:"port,omitempty" yaml:"port,omitempty"`
-	Services     resource.ServiceMap     `json:"service,omitempty" yaml:"service,omitempty"`
-	Users        resource.UserMap        `json:"user,omitempty" yaml:"user,omitempty"`
-	Groups       resource.GroupMap       `json:"group,omitempty" yaml:"group,omitempty"`
-	Commands     resource.CommandMap     `json:"command,omitempty" yaml:"command,omitempty"`
-	DNS          resource.DNSMap         `json:"dns,omitempty" yaml:"dns,omitempty"`
-	Processes    resource.ProcessMap     `json:"process,omitempty" yaml:"process,omitempty"`
-	Gossfiles    resource.GossfileMap    `json:"gossfile,omitempty" yaml:"gossfile,omitempty"`
-	KernelParams resource.KernelParamMap `json:"kernel-param,omitempty" yaml:"kernel-param,omitempty"`
-	Mounts       resource.MountMap       `json:"mount,omitempty" yaml:"mount,omitempty"`
-	Interfaces   resource.InterfaceMap   `json:"interface,omitempty" yaml:"interface,omitempty"`
-	HTTPs        resource.HTTPMap        `json:"http,omitempty" yaml:"http,omitempty"`
-	Matchings    resource.MatchingMap    `json:"matching,omitempty" yaml:"matching,omitempty"`
+	Files          resource.FileMap         `json:"file,omitempty" yaml:"file,omitempty"`
+	Packages       resource.PackageMap      `json:"package,omitempty" yaml:"package,omitempty"`
+	Addrs          resource.AddrMap         `json:"addr,omitempty" yaml:"addr,omitempty"`
+	Ports          resource.PortMap         `json:"port,omitempty" yaml:"port,omitempty"`
+	Services       resource.ServiceMap      `json:"service,omitempty" yaml:"service,omitempty"`
+	Users          resource.UserMap         `json:"user,omitempty" yaml:"user,omitempty"`
+	Groups         resource.GroupMap        `json:"group,omitempty" yaml:"group,omitempty"`
+	Commands       resource.CommandMap      `json:"command,omitempty" yaml:"command,omitempty"`
+	DNS            resource.DNSMap          `json:"dns,omitempty" yaml:"dns,omitempty"`
+	Processes      resource.ProcessMap      `json:"process,omitempty" yaml:"process,omitempty"`
+	Gossfiles      resource.GossfileMap     `json:"gossfile,omitempty" yaml:"gossfile,omitempty"`
+	KernelParams   resource.KernelParamMap  `json:"kernel-param,omitempty" yaml:"kernel-param,omitempty"`
+	Mounts         resource.MountMap        `json:"mount,omitempty" yaml:"mount,omitempty"`
+	Interfaces     resource.InterfaceMap    `json:"interface,omitempty" yaml:"interface,omitempty"`
+	HTTPs          resource.HTTPMap         `json:"http,omitempty" yaml:"http,omitempty"`
+	Dirs           resource.DirMap          `json:"dir,omitempty" yaml:"dir,omitempty"`
+	ShellProfiles  resource.ShellProfileMap `json:"shell-profile,omitempty" yaml:"shell-profile,omitempty"`
+	CryptoPolicies resource.CryptoPolicyMap `json:"crypto-policy,omitempty" yaml:"crypto-policy,omitempty"`
+	TrustedBoots   resource.TrustedBootMap  `json:"trusted-boot,omitempty" yaml:"trusted-boot,omitempty"`
+	Entropies      resource.EntropyMap      `json:"entropy,omitempty" yaml:"entropy,omitempty"`
+	MACs           resource.MACMap          `json:"mac,omitempty" yaml:"mac,omitempty"`
+	Containers     resource.ContainerMap    `json:"container,omitempty" yaml:"container,omitempty"`
+	K8s            resource.K8sMap          `json:"k8s,omitempty" yaml:"k8s,omitempty"`
+	SQLs           resource.SQLMap          `json:"sql,omitempty" yaml:"sql,omitempty"`
+	KVs            resource.KVMap           `json:"kv,omitempty" yaml:"kv,omitempty"`
+	Firewalls      resource.FirewallMap     `json:"firewall,omitempty" yaml:"firewall,omitempty"`
+	NTPs           resource.NTPMap          `json:"ntp,omitempty" yaml:"ntp,omitempty"`
+	Sockets        resource.SocketsMap      `json:"sockets,omitempty" yaml:"sockets,omitempty"`
+	Bandwidths     resource.BandwidthMap    `json:"bandwidth,omitempty" yaml:"bandwidth,omitempty"`
+	Pings          resource.PingMap         `json:"ping,omitempty" yaml:"ping,omitempty"`
+	GRPCs          resource.GRPCMap         `json:"grpc,omitempty" yaml:"grpc,omitempty"`
+	WebSockets     resource.WebSocketMap    `json:"websocket,omitempty" yaml:"websocket,omitempty"`
+	Matchings      resource.MatchingMap     `json:"matching,omitempty" yaml:"matching,omitempty"`
+
+	// order is the orderKey of every resource in the order the gossfiles
+	// list them, resources listed more than once keep the first position
+	order []string
+	// rendering is how long loading the vars and rendering the templates of
+	// the gossfiles took
+	rendering time.Duration
+}
+
+// UnmarshalYAML reads the order of the resources from the same parse of the
+// gossfile as the resources themselves
+func (c *GossConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain GossConfig
+	if err := unmarshal((*plain)(c)); err != nil {
+		return err
+	}
+	var spec yaml.MapSlice
+	if err := unmarshal(&spec); err != nil {
+		return err
+	}
+	c.order = yamlSpecOrder(spec)
+	return nil
 }
 
 func NewGossConfig() *GossConfig {
 	return &GossConfig{
-		Files:        make(resource.FileMap),
-		Packages:     make(resource.PackageMap),
-		Addrs:        make(resource.AddrMap),
-		Ports:        make(resource.PortMap),
-		Services:     make(resource.ServiceMap),
-		Users:        make(resource.UserMap),
-		Groups:       make(resource.GroupMap),
-		Commands:     make(resource.CommandMap),
-		DNS:          make(resource.DNSMap),
-		Processes:    make(resource.ProcessMap),
-		Gossfiles:    make(resource.GossfileMap),
-		KernelParams: make(resource.KernelParamMap),
-		Mounts:       make(resource.MountMap),
-		Interfaces:   make(resource.InterfaceMap),
-		HTTPs:        make(resource.HTTPMap),
-		Matchings:    make(resource.MatchingMap),
+		Files:          make(resource.FileMap),
+		Packages:       make(resource.PackageMap),
+		Addrs:          make(resource.AddrMap),
+		Ports:          make(resource.PortMap),
+		Services:       make(resource.ServiceMap),
+		Users:          make(resource.UserMap),
+		Groups:         make(resource.GroupMap),
+		Commands:       make(resource.CommandMap),
+		DNS:            make(resource.DNSMap),
+		Processes:      make(resource.ProcessMap),
+		Gossfiles:      make(resource.GossfileMap),
+		KernelParams:   make(resource.KernelParamMap),
+		Mounts:         make(resource.MountMap),
+		Interfaces:     make(resource.InterfaceMap),
+		HTTPs:          make(resource.HTTPMap),
+		Dirs:           make(resource.DirMap),
+		ShellProfiles:  make(resource.ShellProfileMap),
+		CryptoPolicies: make(resource.CryptoPolicyMap),
+		TrustedBoots:   make(resource.TrustedBootMap),
+		Entropies:      make(resource.EntropyMap),
+		MACs:           make(resource.MACMap),
+		Containers:     make(resource.ContainerMap),
+		K8s:            make(resource.K8sMap),
+		SQLs:           make(resource.SQLMap),
+		KVs:            make(resource.KVMap),
+		Firewalls:      make(resource.FirewallMap),
+		NTPs:           make(resource.NTPMap),
+		Sockets:        make(resource.SocketsMap),
+		Bandwidths:     make(resource.BandwidthMap),
+		Pings:          make(resource.PingMap),
+		GRPCs:          make(resource.GRPCMap),
+		WebSockets:     make(resource.WebSocketMap),
+		Matchings:      make(resource.MatchingMap),
 	}
 }
 
 // Merge consumes all the resources in g2 into c, duplicate resources
 // will be overwritten with the ones in g2
 func (c *GossConfig) Merge(g2 GossConfig) {
+	// Resources keep the position they're first listed in, see Resources
+	c.order = append(c.order, g2.order...)
+
 	for k, v := range g2.Files {
 		c.Files[k] = v
 	}
@@ -105,13 +169,84 @@ func (c *GossConfig) Merge(g2 GossConfig) {
 		c.HTTPs[k] = v
 	}
 
+	for k, v := range g2.Dirs {
+		c.Dirs[k] = v
+	}
+
+	for k, v := range g2.ShellProfiles {
+		c.ShellProfiles[k] = v
+	}
+
+	for k, v := range g2.CryptoPolicies {
+		c.CryptoPolicies[k] = v
+	}
+
+	for k, v := range g2.TrustedBoots {
+		c.TrustedBoots[k] = v
+	}
+
+	for k, v := range g2.Entropies {
+		c.Entropies[k] = v
+	}
+
+	for k, v := range g2.MACs {
+		c.MACs[k] = v
+	}
+
+	for k, v := range g2.Containers {
+		c.Containers[k] = v
+	}
+
+	for k, v := range g2.K8s {
+		c.K8s[k] = v
+	}
+
+	for k, v := range g2.SQLs {
+		c.SQLs[k] = v
+	}
+
+	for k, v := range g2.KVs {
+		c.KVs[k] = v
+	}
+
+	for k, v := range g2.Firewalls {
+		c.Firewalls[k] = v
+	}
+
+	for k, v := range g2.NTPs {
+		c.NTPs[k] = v
+	}
+
+	for k, v := range g2.Sockets {
+		c.Sockets[k] = v
+	}
+
+	for k, v := range g2.Bandwidths {
+		c.Bandwidths[k] = v
+	}
+
+	for k, v := range g2.Pings {
+		c.Pings[k] = v
+	}
+
+	for k, v := range g2.GRPCs {
+		c.GRPCs[k] = v
+	}
+
+	for k, v := range g2.WebSockets {
+		c.WebSockets[k] = v
+	}
+
 	for k, v := range g2.Matchings {
 		c.Matchings[k] = v
 	}
 }
 
+// Resources are the resources in the order the gossfiles list them, the ones
+// that aren't in a gossfile follow, by type and then sorted by ID
 func (c *GossConfig) Resources() []resource.Resource {
 	var tests []resource.Resource
+	var keys []string
 
 	gm := genericConcatMaps(c.Commands,
 		c.HTTPs,
@@ -127,19 +262,77 @@ func (c *GossConfig) Resources() []resource.Resource {
 		c.KernelParams,
 		c.Mounts,
 		c.Interfaces,
+		c.Dirs,
+		c.ShellProfiles,
+		c.CryptoPolicies,
+		c.TrustedBoots,
+		c.Entropies,
+		c.MACs,
+		c.Containers,
+		c.K8s,
+		c.SQLs,
+		c.KVs,
+		c.Firewalls,
+		c.NTPs,
+		c.Sockets,
+		c.Bandwidths,
+		c.Pings,
+		c.GRPCs,
+		c.WebSockets,
 		c.Matchings,
 	)
 
 	for _, m := range gm {
-		for _, t := range m {
+		ids := make([]string, 0, len(m))
+		for id := range m {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		typeName := ""
+		for _, id := range ids {
 			// FIXME: Can this be moved to a safer compile-time check?
-			tests = append(tests, t.(resource.Resource))
+			t := m[id].(resource.Resource)
+			if typeName == "" {
+				typeName = strings.Split(reflect.TypeOf(t).String(), ".")[1]
+			}
+			tests = append(tests, t)
+			keys = append(keys, orderKey(typeName, id))
 		}
 	}
 
+	if len(c.order) == 0 {
+		return tests
+	}
+	position := make(map[string]int, len(c.order))
+	for i, k := range c.order {
+		if _, ok := position[k]; !ok {
+			position[k] = i
+		}
+	}
+	positions := make([]int, len(tests))
+	for i, k := range keys {
+		p, ok := position[k]
+		if !ok {
+			p = len(c.order)
+		}
+		positions[i] = p
+	}
+	sort.Stable(byPosition{tests, positions})
 	return tests
 }
 
+type byPosition struct {
+	resources []resource.Resource
+	positions []int
+}
+
+func (b byPosition) Len() int           { return len(b.resources) }
+func (b byPosition) Less(i, j int) bool { return b.positions[i] < b.positions[j] }
+func (b byPosition) Swap(i, j int) {
+	b.resources[i], b.resources[j] = b.resources[j], b.resources[i]
+	b.positions[i], b.positions[j] = b.positions[j], b.positions[i]
+}
+
 func genericConcatMaps(maps ...interface{}) (ret []map[string]interface{}) {
 	for _, slice := range maps {
 		im := interfaceMap(slice)
@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/aelsabbahy/goss/outputs"
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/system"
 	"github.com/aelsabbahy/goss/util"
 )
 
@@ -120,3 +123,145 @@ func TestUseAsPackage(t *testing.T) {
 		t.Fatalf("expected %d passed but got %d", passed, okcount)
 	}
 }
+
+func TestValidateDeadline(t *testing.T) {
+	g, err := ReadJSONData([]byte(`{"command": {"slow": {"exec": "sleep 5", "exit-status": 0}, "fast": {"exec": "true", "exit-status": 0}}}`), true)
+	checkErr(t, err, "reading gossfile failed")
+
+	start := time.Now()
+	results := map[string]int{}
+	for rg := range validate(system.New(""), g, Concurrency{Max: 10}, time.Now().Add(500*time.Millisecond)) {
+		for _, r := range rg {
+			results[r.ResourceId] = r.Result
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("validate didn't stop at the deadline, took %s", elapsed)
+	}
+	if results["slow"] != resource.TIMEOUT {
+		t.Errorf("slow command should have timed out, got result %d", results["slow"])
+	}
+	if results["fast"] != resource.SUCCESS {
+		t.Errorf("fast command should have passed, got result %d", results["fast"])
+	}
+}
+
+func TestValidateRetries(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "ready")
+	g, err := ReadJSONData([]byte(`{"command": {
+		"eventually": {"exec": "test -f `+marker+` || { touch `+marker+`; false; }", "exit-status": 0, "meta": {"retries": 2, "retry-interval": 10}},
+		"flaky": {"exec": "false", "exit-status": 0, "meta": {"retries": 1, "retry-interval": 10, "flaky": true}},
+		"failing": {"exec": "false", "exit-status": 0},
+		"invalid": {"exec": "true", "exit-status": 0, "meta": {"retries": "three"}}
+	}}`), true)
+	checkErr(t, err, "reading gossfile failed")
+
+	results := map[string]resource.TestResult{}
+	for rg := range validate(system.New(""), g, Concurrency{Max: 10}, time.Time{}) {
+		for _, r := range rg {
+			if r.Property == "exit-status" || r.Property == "retries" {
+				results[r.ResourceId] = r
+			}
+		}
+	}
+	if r := results["eventually"]; r.Result != resource.SUCCESS || r.Attempts != 2 {
+		t.Errorf("eventually: got result %d after %d attempts, want passed after 2", r.Result, r.Attempts)
+	}
+	if r := results["flaky"]; r.Result != resource.FAIL || !r.Warning() || r.Attempts != 2 {
+		t.Errorf("flaky: got result %d, warning %v after %d attempts, want a failure warning after 2", r.Result, r.Warning(), r.Attempts)
+	}
+	if r := results["failing"]; r.Result != resource.FAIL || r.Warning() || r.Attempts != 0 {
+		t.Errorf("failing: got result %d, warning %v after %d attempts", r.Result, r.Warning(), r.Attempts)
+	}
+	if r := results["invalid"]; r.Result != resource.ERROR {
+		t.Errorf("invalid retries: got result %d, want an error", r.Result)
+	}
+}
+
+func TestValidateSecrets(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "token")
+	if err := ioutil.WriteFile(secretFile, []byte("t0ken\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("GOSS_TEST_SECRET", "s3cret")
+	defer os.Unsetenv("GOSS_TEST_SECRET")
+	filter, err := NewTemplateFilter("", "")
+	checkErr(t, err, "creating template filter failed")
+	currentTemplateFilter = filter
+	defer func() { currentTemplateFilter = nil }()
+
+	gossfile := `command:
+  env:
+    exec: 'test {{secretRef "env:GOSS_TEST_SECRET"}} = "s3""cret"'
+    exit-status: 0
+  file:
+    exec: 'test "{{secretRef "file:` + secretFile + `"}}" = "t0""ken"'
+    exit-status: 0
+  missing:
+    exec: 'echo {{secretRef "env:GOSS_TEST_MISSING"}}'
+    exit-status: 0
+`
+	g, err := ReadJSONData([]byte(gossfile), true)
+	checkErr(t, err, "reading gossfile failed")
+	results := map[string]resource.TestResult{}
+	for rg := range validate(system.New(""), g, Concurrency{Max: 10}, time.Time{}) {
+		for _, r := range rg {
+			results[r.ResourceId] = r
+		}
+	}
+	for _, id := range []string{"env", "file"} {
+		if r := results[id]; r.Result != resource.SUCCESS {
+			t.Errorf("%s: got result %d, %v, want passed", id, r.Result, r.Err)
+		}
+	}
+	if r := results["missing"]; r.Result != resource.ERROR || r.Property != "secret" {
+		t.Errorf("missing secret: got result %d of %s, want an error", r.Result, r.Property)
+	}
+
+	outStoreFormat = YAML
+	rendered, err := marshal(g)
+	checkErr(t, err, "rendering gossfile failed")
+	if bytes.Contains(rendered, []byte("s3cret")) || bytes.Contains(rendered, []byte("t0ken")) {
+		t.Errorf("rendered gossfile holds a secret:\n%s", rendered)
+	}
+	again, err := ReadJSONData(rendered, true)
+	checkErr(t, err, "reading rendered gossfile failed")
+	if again.Commands["env"].Exec != g.Commands["env"].Exec {
+		t.Errorf("rendering again: got %q, want %q", again.Commands["env"].Exec, g.Commands["env"].Exec)
+	}
+}
+
+type panickingFile struct {
+	*resource.File
+}
+
+func (p panickingFile) Validate(sys *system.System) []resource.TestResult {
+	var m map[string]int
+	m["boom"]++
+	return nil
+}
+
+func TestValidatePanic(t *testing.T) {
+	g, err := ReadJSONData([]byte(`{"command": {"fast": {"exec": "true", "exit-status": 0}}}`), true)
+	checkErr(t, err, "reading gossfile failed")
+	resources := append(g.Resources(), panickingFile{&resource.File{Path: "/broken"}})
+
+	for _, r := range resources {
+		results := validateResource(system.New(""), r)
+		if len(results) != 1 {
+			t.Fatalf("got %d results, want 1", len(results))
+		}
+		switch results[0].ResourceId {
+		case "fast":
+			if results[0].Result != resource.SUCCESS {
+				t.Errorf("fast command should have passed, got result %d", results[0].Result)
+			}
+		case "/broken":
+			if results[0].Result != resource.ERROR || util.ErrorCode(results[0].Err) != util.ErrCodePanic {
+				t.Errorf("panic should be an error, got result %d: %v", results[0].Result, results[0].Err)
+			}
+		default:
+			t.Errorf("unexpected result %v", results[0])
+		}
+	}
+}
@@ -0,0 +1,748 @@
+package goss
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A gossfile in the native syntax of HCL has a block for each resource, its
+// type and ID are the labels:
+//
+//	file "/etc/passwd" {
+//	  exists = true
+//	  mode   = "0644"
+//	}
+//
+// Blocks and attributes are read as the JSON object of the gossfile, so the
+// parser supports the subset of HCL that has a JSON value:
+//
+//   - blocks labeled with strings or identifiers, and name = value attributes
+//   - strings, with their escapes and $${ and %%{ for a literal ${ and %{
+//   - <<EOF and <<-EOF heredocs
+//   - numbers, true, false and null
+//   - lists, and objects of key = value or key: value items
+//   - #, // and /* */ comments
+//
+// Anything that needs evaluating, such as interpolations, references,
+// function calls, operators, conditionals, for expressions, indexes and
+// splats, is an error naming it. Templates are rendered before the file is
+// parsed, so they stand in for them.
+
+// hclObject is an object of an HCL file, its keys in the order of the file
+type hclObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newHCLObject() *hclObject {
+	return &hclObject{values: make(map[string]interface{})}
+}
+
+// set sets key to v, it's false when the object already has key
+func (o *hclObject) set(key string, v interface{}) bool {
+	if _, ok := o.values[key]; ok {
+		return false
+	}
+	o.keys = append(o.keys, key)
+	o.values[key] = v
+	return true
+}
+
+// hclToJSON converts the HCL gossfile data to JSON, keeping the order of its
+// blocks and attributes
+func hclToJSON(data []byte) ([]byte, error) {
+	p := &hclParser{src: []rune(string(data)), line: 1}
+	body, err := p.body(true)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	writeJSONValue(&buf, body)
+	return buf.Bytes(), nil
+}
+
+// writeJSONValue writes v, a value of an HCL file, as JSON
+func writeJSONValue(buf *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case *hclObject:
+		buf.WriteByte('{')
+		for i, k := range v.keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(k)
+			buf.Write(key)
+			buf.WriteByte(':')
+			writeJSONValue(buf, v.values[k])
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, e := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSONValue(buf, e)
+		}
+		buf.WriteByte(']')
+	case json.Number:
+		buf.WriteString(string(v))
+	default:
+		b, _ := json.Marshal(v)
+		buf.Write(b)
+	}
+}
+
+type hclParser struct {
+	src  []rune
+	pos  int
+	line int
+}
+
+func (p *hclParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("hcl: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *hclParser) eof() bool { return p.pos >= len(p.src) }
+
+// at is the rune i runes ahead, 0 at the end of the file
+func (p *hclParser) at(i int) rune {
+	if p.pos+i >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos+i]
+}
+
+func (p *hclParser) next() rune {
+	r := p.at(0)
+	if !p.eof() {
+		p.pos++
+	}
+	if r == '\n' {
+		p.line++
+	}
+	return r
+}
+
+// skip skips spaces and comments, and newlines when newlines is true
+func (p *hclParser) skip(newlines bool) {
+	for !p.eof() {
+		switch r := p.at(0); {
+		case r == ' ' || r == '\t' || r == '\r' || (newlines && r == '\n'):
+			p.next()
+		case r == '#' || (r == '/' && p.at(1) == '/'):
+			for !p.eof() && p.at(0) != '\n' {
+				p.next()
+			}
+		case r == '/' && p.at(1) == '*':
+			p.next()
+			p.next()
+			for !p.eof() && !(p.at(0) == '*' && p.at(1) == '/') {
+				p.next()
+			}
+			p.next()
+			p.next()
+		default:
+			return
+		}
+	}
+}
+
+// body parses the attributes and blocks up to the closing brace, or the end
+// of the file at the top level. The labels of a block nest its body in the
+// object of its name, so every file block is in the object of file.
+func (p *hclParser) body(top bool) (*hclObject, error) {
+	obj := newHCLObject()
+	for {
+		p.skip(true)
+		switch {
+		case p.eof() && top:
+			return obj, nil
+		case p.eof():
+			return nil, p.errorf("unclosed block")
+		case p.at(0) == '}' && !top:
+			p.next()
+			return obj, nil
+		}
+
+		line := p.line
+		name, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+		p.skip(false)
+		if p.at(0) == '=' {
+			p.next()
+			p.skip(false)
+			v, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			if !obj.set(name, v) {
+				return nil, fmt.Errorf("hcl: line %d: duplicate attribute %q", line, name)
+			}
+		} else {
+			path := []string{name}
+			for p.at(0) != '{' {
+				label, err := p.label()
+				if err != nil {
+					return nil, err
+				}
+				path = append(path, label)
+				p.skip(false)
+			}
+			p.next()
+			block, err := p.body(false)
+			if err != nil {
+				return nil, err
+			}
+			if err := setBlock(obj, path, block); err != nil {
+				return nil, fmt.Errorf("hcl: line %d: %v", line, err)
+			}
+		}
+		if err := p.endOfItem(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// setBlock sets the body of the block with the name and labels of path in obj
+func setBlock(obj *hclObject, path []string, block *hclObject) error {
+	for _, key := range path[:len(path)-1] {
+		v, ok := obj.values[key]
+		if !ok {
+			v = newHCLObject()
+			obj.set(key, v)
+		}
+		nested, ok := v.(*hclObject)
+		if !ok {
+			return fmt.Errorf("block %s conflicts with the attribute %q", strings.Join(path, " "), key)
+		}
+		obj = nested
+	}
+	if !obj.set(path[len(path)-1], block) {
+		return fmt.Errorf("duplicate block %s", strings.Join(path, " "))
+	}
+	return nil
+}
+
+// endOfItem checks an attribute or block ends its line
+func (p *hclParser) endOfItem() error {
+	p.skip(false)
+	switch p.at(0) {
+	case '\n':
+		p.next()
+		return nil
+	case '}', 0:
+		return nil
+	}
+	return p.errorf("expected a newline, got %q", p.at(0))
+}
+
+func isIdentifierStart(r rune) bool { return r == '_' || unicode.IsLetter(r) }
+
+func isIdentifier(r rune) bool {
+	return isIdentifierStart(r) || r == '-' || unicode.IsDigit(r)
+}
+
+func (p *hclParser) identifier() (string, error) {
+	if !isIdentifierStart(p.at(0)) {
+		return "", p.errorf("expected an attribute or block name, got %q", p.at(0))
+	}
+	start := p.pos
+	for isIdentifier(p.at(0)) {
+		p.next()
+	}
+	return string(p.src[start:p.pos]), nil
+}
+
+// label is a block label, a string or an identifier
+func (p *hclParser) label() (string, error) {
+	if p.at(0) == '"' {
+		return p.quoted(false)
+	}
+	if isIdentifierStart(p.at(0)) {
+		return p.identifier()
+	}
+	return "", p.errorf("expected a block label or {, got %q", p.at(0))
+}
+
+// expression is a literal value, the operators, conditionals, indexes and
+// splats that could follow it are rejected rather than read as the end of
+// the item
+func (p *hclParser) expression() (interface{}, error) {
+	v, err := p.literal()
+	if err != nil {
+		return nil, err
+	}
+	p.skip(false)
+	switch r := p.at(0); r {
+	case '+', '-', '*', '/', '%', '=', '!', '<', '>', '&', '|', '?':
+		return nil, p.errorf("operator %q isn't supported, only literal values are", r)
+	case '.', '[':
+		return nil, p.errorf("indexes and splats aren't supported, only literal values are")
+	}
+	return v, nil
+}
+
+func (p *hclParser) literal() (interface{}, error) {
+	switch r := p.at(0); {
+	case r == '"':
+		return p.quoted(true)
+	case r == '<' && p.at(1) == '<':
+		return p.heredoc()
+	case r == '[':
+		return p.tuple()
+	case r == '{':
+		return p.object()
+	case r == '-' || unicode.IsDigit(r):
+		return p.number()
+	case isIdentifierStart(r):
+		start := p.line
+		name, _ := p.identifier()
+		switch name {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		case "for":
+			return nil, fmt.Errorf("hcl: line %d: for expressions aren't supported, only literal values are", start)
+		}
+		p.skip(false)
+		if p.at(0) == '(' {
+			return nil, fmt.Errorf("hcl: line %d: function calls such as %s() aren't supported, only literal values are", start, name)
+		}
+		for p.at(0) == '.' && isIdentifierStart(p.at(1)) {
+			p.next()
+			attr, _ := p.identifier()
+			name += "." + attr
+		}
+		return nil, fmt.Errorf("hcl: line %d: references such as %s aren't supported, only literal values are", start, name)
+	case r == '!':
+		return nil, p.errorf("operator %q isn't supported, only literal values are", r)
+	case r == '(':
+		return nil, p.errorf("parenthesized expressions aren't supported, only literal values are")
+	case r == 0:
+		return nil, p.errorf("expected a value")
+	}
+	return nil, p.errorf("unexpected %q", p.at(0))
+}
+
+// quoted is a quoted string. The ones of values are templates, whose
+// interpolations aren't supported since there's nothing to evaluate them
+// with, goss templates are. Labels and keys are literal.
+func (p *hclParser) quoted(template bool) (string, error) {
+	p.next()
+	var sb strings.Builder
+	for {
+		r := p.next()
+		switch {
+		case r == '"':
+			return sb.String(), nil
+		case r == 0 || r == '\n':
+			return "", p.errorf("unterminated string")
+		case r == '\\':
+			e := p.next()
+			switch e {
+			case 'n':
+				sb.WriteRune('\n')
+			case 'r':
+				sb.WriteRune('\r')
+			case 't':
+				sb.WriteRune('\t')
+			case '"', '\\':
+				sb.WriteRune(e)
+			case 'u', 'U':
+				n := 4
+				if e == 'U' {
+					n = 8
+				}
+				if p.pos+n > len(p.src) {
+					return "", p.errorf("invalid escape \\%c", e)
+				}
+				code, err := strconv.ParseUint(string(p.src[p.pos:p.pos+n]), 16, 32)
+				if err != nil {
+					return "", p.errorf("invalid escape \\%c%s", e, string(p.src[p.pos:p.pos+n]))
+				}
+				p.pos += n
+				sb.WriteRune(rune(code))
+			default:
+				return "", p.errorf("invalid escape \\%c", e)
+			}
+		case template && (r == '$' || r == '%') && p.at(0) == r && p.at(1) == '{':
+			// $${ and %%{ are a literal ${ and %{
+			p.next()
+			sb.WriteRune(r)
+		case template && (r == '$' || r == '%') && p.at(0) == '{':
+			return "", p.errorf("interpolation isn't supported, use templates")
+		default:
+			sb.WriteRune(r)
+		}
+	}
+}
+
+// heredoc is a <<EOF string or a <<-EOF one whose lines are unindented
+func (p *hclParser) heredoc() (string, error) {
+	p.next()
+	p.next()
+	indented := p.at(0) == '-'
+	if indented {
+		p.next()
+	}
+	marker, err := p.identifier()
+	if err != nil {
+		return "", err
+	}
+	p.skip(false)
+	if p.next() != '\n' {
+		return "", p.errorf("expected a newline after <<%s", marker)
+	}
+	var lines []string
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated heredoc, expected %s", marker)
+		}
+		start := p.pos
+		for !p.eof() && p.at(0) != '\n' {
+			p.pos++
+		}
+		line := strings.TrimSuffix(string(p.src[start:p.pos]), "\r")
+		if strings.TrimSpace(line) == marker {
+			break
+		}
+		lines = append(lines, line)
+		p.next()
+	}
+	if indented {
+		lines = unindent(lines)
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return p.template(strings.Join(lines, "\n") + "\n")
+}
+
+// template unescapes the $${ and %%{ of the heredoc s, which is a template
+// like quoted strings
+func (p *hclParser) template(s string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c == '$' || c == '%') && i+2 < len(s) && s[i+1] == c && s[i+2] == '{' {
+			sb.WriteByte(c)
+			i++
+			continue
+		}
+		if (c == '$' || c == '%') && i+1 < len(s) && s[i+1] == '{' {
+			return "", p.errorf("interpolation isn't supported, use templates")
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String(), nil
+}
+
+// unindent removes the indentation the lines that aren't blank share
+func unindent(lines []string) []string {
+	indent := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		n := len(l) - len(strings.TrimLeft(l, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		if len(l) >= indent && indent > 0 {
+			l = l[indent:]
+		}
+		out[i] = l
+	}
+	return out
+}
+
+func (p *hclParser) number() (json.Number, error) {
+	start := p.pos
+	if p.at(0) == '-' {
+		p.next()
+	}
+	for unicode.IsDigit(p.at(0)) || p.at(0) == '.' || p.at(0) == 'e' || p.at(0) == 'E' ||
+		((p.at(0) == '+' || p.at(0) == '-') && (p.at(-1) == 'e' || p.at(-1) == 'E')) {
+		p.next()
+	}
+	n := string(p.src[start:p.pos])
+	if _, err := strconv.ParseFloat(n, 64); err != nil {
+		return "", p.errorf("invalid number %s", n)
+	}
+	return json.Number(n), nil
+}
+
+// tuple is a list of values, separated by commas and possibly newlines
+func (p *hclParser) tuple() ([]interface{}, error) {
+	p.next()
+	list := []interface{}{}
+	for {
+		p.skip(true)
+		if p.at(0) == ']' {
+			p.next()
+			return list, nil
+		}
+		v, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+		p.skip(true)
+		switch p.at(0) {
+		case ',':
+			p.next()
+		case ']':
+		default:
+			return nil, p.errorf("expected , or ], got %q", p.at(0))
+		}
+	}
+}
+
+// object is an object of key = value or key: value items, separated by
+// commas or newlines
+func (p *hclParser) object() (*hclObject, error) {
+	p.next()
+	obj := newHCLObject()
+	for {
+		p.skip(true)
+		if p.at(0) == '}' {
+			p.next()
+			return obj, nil
+		}
+		line := p.line
+		var key string
+		var err error
+		if p.at(0) == '"' {
+			key, err = p.quoted(false)
+		} else {
+			key, err = p.identifier()
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.skip(false)
+		if key == "for" && p.at(0) != '=' && p.at(0) != ':' {
+			return nil, fmt.Errorf("hcl: line %d: for expressions aren't supported, only literal values are", line)
+		}
+		if r := p.next(); r != '=' && r != ':' {
+			return nil, fmt.Errorf("hcl: line %d: expected = after %q", line, key)
+		}
+		p.skip(false)
+		v, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		if !obj.set(key, v) {
+			return nil, fmt.Errorf("hcl: line %d: duplicate key %q", line, key)
+		}
+		p.skip(false)
+		switch p.at(0) {
+		case ',', '\n':
+			p.next()
+		case '}':
+		default:
+			return nil, p.errorf("expected , or a newline, got %q", p.at(0))
+		}
+	}
+}
+
+// marshalHCL writes v, such as a GossConfig, as HCL: a block for each
+// resource, labeled with its type and ID, of the attributes of its JSON
+func marshalHCL(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	doc, err := decodeOrdered(dec)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := doc.(*hclObject)
+	if !ok {
+		return nil, fmt.Errorf("expected an object, got %s", data)
+	}
+
+	var buf bytes.Buffer
+	for _, typ := range obj.keys {
+		resources, ok := obj.values[typ].(*hclObject)
+		if !ok || !isHCLName(typ) || !allObjects(resources) {
+			// Such as the resource of resourcePrint
+			writeHCLAttribute(&buf, typ, obj.values[typ], "")
+			continue
+		}
+		for _, id := range resources.keys {
+			attrs := resources.values[id].(*hclObject)
+			if buf.Len() > 0 {
+				buf.WriteByte('\n')
+			}
+			fmt.Fprintf(&buf, "%s %s {\n", typ, hclString(id, false))
+			for _, name := range attrs.keys {
+				writeHCLAttribute(&buf, name, attrs.values[name], "  ")
+			}
+			buf.WriteString("}\n")
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// allObjects is whether every value of o is an object
+func allObjects(o *hclObject) bool {
+	for _, v := range o.values {
+		if _, ok := v.(*hclObject); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeOrdered decodes the next JSON value of dec, with its objects as
+// hclObjects so their keys keep their order
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case json.Delim('{'):
+		obj := newHCLObject()
+		for dec.More() {
+			k, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj.set(k.(string), v)
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		list := []interface{}{}
+		for dec.More() {
+			v, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		_, err := dec.Token()
+		return list, err
+	}
+	return t, nil
+}
+
+func writeHCLAttribute(buf *bytes.Buffer, name string, v interface{}, indent string) {
+	if !isHCLName(name) {
+		name = hclString(name, false)
+	}
+	fmt.Fprintf(buf, "%s%s = ", indent, name)
+	writeHCLValue(buf, v, indent)
+	buf.WriteByte('\n')
+}
+
+// writeHCLValue writes v, lists of scalars on one line and objects with a
+// line for each key
+func writeHCLValue(buf *bytes.Buffer, v interface{}, indent string) {
+	switch v := v.(type) {
+	case *hclObject:
+		if len(v.keys) == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteString("{\n")
+		for _, k := range v.keys {
+			writeHCLAttribute(buf, k, v.values[k], indent+"  ")
+		}
+		buf.WriteString(indent + "}")
+	case []interface{}:
+		scalars := true
+		for _, e := range v {
+			switch e.(type) {
+			case *hclObject, []interface{}:
+				scalars = false
+			}
+		}
+		if scalars {
+			buf.WriteByte('[')
+			for i, e := range v {
+				if i > 0 {
+					buf.WriteString(", ")
+				}
+				writeHCLValue(buf, e, indent)
+			}
+			buf.WriteByte(']')
+			return
+		}
+		buf.WriteString("[\n")
+		for _, e := range v {
+			buf.WriteString(indent + "  ")
+			writeHCLValue(buf, e, indent+"  ")
+			buf.WriteString(",\n")
+		}
+		buf.WriteString(indent + "]")
+	case string:
+		buf.WriteString(hclString(v, true))
+	case json.Number:
+		buf.WriteString(string(v))
+	case nil:
+		buf.WriteString("null")
+	default:
+		fmt.Fprintf(buf, "%v", v)
+	}
+}
+
+// hclString is s quoted, with its ${ and %{ escaped when it's the template of
+// a value rather than a label or key
+func hclString(s string, template bool) string {
+	if template {
+		s = strings.NewReplacer("${", "$${", "%{", "%%{").Replace(s)
+	}
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r < 0x20:
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// isHCLName is whether s is an identifier, the name of an attribute or block
+func isHCLName(s string) bool {
+	for i, r := range s {
+		if (i == 0 && !isIdentifierStart(r)) || !isIdentifier(r) {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,119 @@
+package goss
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHCLToJSON(t *testing.T) {
+	data, err := hclToJSON([]byte(`# comment
+file "/etc/passwd" {
+  exists = true // comment
+  mode   = "0644"
+  contains = [
+    "root",
+    "/^daemon/", /* trailing comma */
+  ]
+  meta = { owner = "team", "on call": -1.5e3 }
+}
+
+command "echo ${HOME}" {
+  exit-status = 0
+  title = <<-EOT
+    literal $${HOME}
+      indented
+  EOT
+  stdout = ["a\tbé"]
+}
+`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"file":{"/etc/passwd":{"exists":true,"mode":"0644","contains":["root","/^daemon/"],"meta":{"owner":"team","on call":-1.5e3}}},`+
+		`"command":{"echo ${HOME}":{"exit-status":0,"title":"literal ${HOME}\n  indented\n","stdout":["a\tbé"]}}}`, string(data))
+
+	for hcl, expected := range map[string]string{
+		"file \"/a\" {\n  mode = \"${x}\"\n}\n":               "hcl: line 2: interpolation isn't supported, use templates",
+		"file \"/a\" {}\nfile \"/a\" {}\n":                    "hcl: line 2: duplicate block file /a",
+		"file \"/a\" {\n  exists = true\n  exists = false\n}": `hcl: line 3: duplicate attribute "exists"`,
+		"file \"/a\" {\n  exists = upper(x)\n}":               "hcl: line 2: function calls such as upper() aren't supported, only literal values are",
+		"file \"/a\" {\n  mode = var.mode\n}":                 "hcl: line 2: references such as var.mode aren't supported, only literal values are",
+		"file \"/a\" {\n  size = 1 + 2\n}":                    "hcl: line 2: operator '+' isn't supported, only literal values are",
+		"file \"/a\" {\n  exists = true ? true : false\n}":    "hcl: line 2: operator '?' isn't supported, only literal values are",
+		"file \"/a\" {\n  exists = !false\n}":                 "hcl: line 2: operator '!' isn't supported, only literal values are",
+		"file \"/a\" {\n  size = (1)\n}":                      "hcl: line 2: parenthesized expressions aren't supported, only literal values are",
+		"file \"/a\" {\n  mode = [\"a\"][0]\n}":               "hcl: line 2: indexes and splats aren't supported, only literal values are",
+		"file \"/a\" {\n  contains = [for s in x: s]\n}":      "hcl: line 2: for expressions aren't supported, only literal values are",
+		"file \"/a\" {\n  meta = {for k, v in x: k => v}\n}":  "hcl: line 2: for expressions aren't supported, only literal values are",
+		"file \"/a\" {\n  exists = true":                      "hcl: line 2: unclosed block",
+		"file \"/a\" { exists = true } x = 1":                 `hcl: line 1: expected a newline, got 'x'`,
+	} {
+		_, err := hclToJSON([]byte(hcl))
+		assert.EqualError(t, err, expected, hcl)
+	}
+}
+
+func TestHCLGossfile(t *testing.T) {
+	dir := t.TempDir()
+	gossfile := filepath.Join(dir, "goss.hcl")
+	require.NoError(t, ioutil.WriteFile(gossfile, []byte(`service "sshd" {
+  enabled = true
+  running = true
+}
+
+file "/etc/passwd" {
+  exists = true
+}
+
+command "echo ${HOME}" {
+  stdout = ["$${HOME}"]
+}
+
+gossfile "web.yaml" {}
+`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "web.yaml"), []byte("port:\n  tcp:80:\n    listening: true\n"), 0644))
+
+	outStoreFormat = HCL
+	currentTemplateFilter = nil
+	j, err := ReadJSON(gossfile)
+	require.NoError(t, err)
+	gossConfig, err := mergeJSONData(j, 0, dir)
+	require.NoError(t, err)
+	var ids []string
+	for _, r := range gossConfig.Resources() {
+		ids = append(ids, r.(resource.ResourceRead).ID())
+	}
+	assert.Equal(t, []string{"sshd", "/etc/passwd", "echo ${HOME}", "tcp:80"}, ids)
+
+	// Rendered HCL reads back the same
+	data, err := marshalHCL(gossConfig)
+	require.NoError(t, err)
+	again, err := readGossData(data, HCL, false)
+	require.NoError(t, err)
+	rendered, err := marshalHCL(again)
+	require.NoError(t, err)
+	assert.Equal(t, string(data), string(rendered))
+	assert.Contains(t, string(data), "service \"sshd\" {\n  enabled = true\n  running = true\n}\n")
+	assert.Equal(t, []string{"${HOME}"}, again.Commands["echo ${HOME}"].Stdout)
+}
+
+func TestCUEGossfile(t *testing.T) {
+	bin := t.TempDir()
+	// cue export prints the JSON of the file, a fake one that of any file
+	require.NoError(t, ioutil.WriteFile(filepath.Join(bin, "cue"), []byte("#!/bin/sh\n"+
+		"test \"$1 $2 $3\" = \"export --out json\" || exit 2\n"+
+		"grep -q invalid \"$4\" && { echo 'file: conflicting values' >&2; exit 1; }\n"+
+		"echo '{\"file\": {\"/etc/passwd\": {\"exists\": true}}}'\n"), 0755))
+	setEnv(t, map[string]string{"PATH": bin + string(os.PathListSeparator) + os.Getenv("PATH")})
+
+	currentTemplateFilter = nil
+	gossConfig, err := readGossData([]byte("file: \"/etc/passwd\": exists: true\n"), CUE, false)
+	require.NoError(t, err)
+	assert.Len(t, gossConfig.Files, 1)
+	_, err = readGossData([]byte("invalid\n"), CUE, false)
+	assert.EqualError(t, err, "cue export: file: conflicting values")
+}
@@ -0,0 +1,212 @@
+package goss
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// includeClient is the client remote gossfiles are fetched with
+var includeClient = &http.Client{Timeout: 30 * time.Second}
+
+// s3Endpoint is the URL of the S3 bucket of region
+var s3Endpoint = func(region, bucket string) string {
+	return "https://" + bucket + ".s3." + region + ".amazonaws.com"
+}
+
+// includeCacheDir is where remote gossfiles are cached, "" when there's no
+// cache directory
+var includeCacheDir = func() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "goss", "includes")
+}
+
+// isRemoteInclude is whether path is a gossfile fetched over https or from S3
+func isRemoteInclude(path string) bool {
+	return strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "s3://")
+}
+
+// includeDir is the directory the includes of the gossfile at path are
+// relative to, a URL for a remote gossfile
+func includeDir(path string) string {
+	if isRemoteInclude(path) {
+		return path[:strings.LastIndex(path, "/")]
+	}
+	return filepath.Dir(path)
+}
+
+// includePaths are the gossfiles id includes from a gossfile of dir: a URL,
+// relative to dir when it's one, a preset, or the files matching its glob
+func includePaths(dir, id string) ([]string, error) {
+	switch {
+	case isRemoteInclude(id), isPreset(id):
+		return []string{id}, nil
+	case isRemoteInclude(dir):
+		if strings.HasPrefix(id, "/") {
+			return nil, fmt.Errorf("remote gossfile %s can't include the local file %q", dir, id)
+		}
+		return []string{dir + "/" + strings.TrimPrefix(id, "./")}, nil
+	}
+	fpath := id
+	if !strings.HasPrefix(id, "/") {
+		fpath = filepath.Join(dir, id)
+	}
+	if matches := bundleGlob(fpath); matches != nil {
+		return matches, nil
+	}
+	matches, err := filepath.Glob(fpath)
+	if err != nil {
+		return nil, fmt.Errorf("error in expanding glob pattern: %q", err)
+	}
+	if matches == nil {
+		return nil, fmt.Errorf("no matched files were found: %q", fpath)
+	}
+	return matches, nil
+}
+
+// readInclude reads the gossfile at path, fetching it when it's remote, and
+// checks its sha256 is pin unless pin is empty. A pinned remote gossfile is
+// read from the cache once it was fetched, an unpinned one is fetched every
+// time and only read from the cache when fetching it fails.
+func readInclude(path, pin string) ([]byte, error) {
+	pin = strings.ToLower(strings.TrimPrefix(pin, "sha256:"))
+	if !isRemoteInclude(path) {
+		data, err := readGossFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("file error: %v", err)
+		}
+		if err := checkPin(data, pin); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+
+	if data, ok := bundleFiles[path]; ok {
+		recordBundleFile(path, data)
+		return data, checkPin(data, pin)
+	}
+	cache := includeCacheDir()
+	cached := ""
+	if cache != "" {
+		key := pin
+		if key == "" {
+			hash := sha256.Sum256([]byte(path))
+			key = "url-" + hex.EncodeToString(hash[:])
+		}
+		cached = filepath.Join(cache, key)
+	}
+	if pin != "" && cached != "" {
+		if data, err := ioutil.ReadFile(cached); err == nil && checkPin(data, pin) == nil {
+			return data, nil
+		}
+	}
+
+	data, err := fetchInclude(path)
+	if err != nil {
+		if pin == "" && cached != "" {
+			if data, cacheErr := ioutil.ReadFile(cached); cacheErr == nil {
+				color.New(color.FgYellow).Fprintf(os.Stderr, "WARNING: using the cached copy of %s: %v\n", redactURL(path), err)
+				return data, nil
+			}
+		}
+		return nil, fmt.Errorf("fetching %s: %v", redactURL(path), err)
+	}
+	if err := checkPin(data, pin); err != nil {
+		return nil, err
+	}
+	recordBundleFile(path, data)
+	if cached != "" {
+		if err := os.MkdirAll(cache, 0755); err == nil {
+			writeTextfile(cached, data)
+		}
+	}
+	return data, nil
+}
+
+// checkPin errors when pin isn't empty and isn't the sha256 of data
+func checkPin(data []byte, pin string) error {
+	if pin == "" {
+		return nil
+	}
+	hash := sha256.Sum256(data)
+	if sum := hex.EncodeToString(hash[:]); sum != pin {
+		return fmt.Errorf("sha256 mismatch: expected %s, got %s", pin, sum)
+	}
+	return nil
+}
+
+// fetchInclude gets the gossfile at the https URL or s3://bucket/key with the
+// AWS credentials of the environment
+func fetchInclude(path string) ([]byte, error) {
+	var req *http.Request
+	var err error
+	if strings.HasPrefix(path, "s3://") {
+		req, err = s3Request(strings.TrimPrefix(path, "s3://"))
+	} else {
+		req, err = http.NewRequest("GET", path, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp, err := includeClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+	return body, nil
+}
+
+// s3Request is the signed request getting the object key of bucket, as
+// bucket/key
+func s3Request(bucketKey string) (*http.Request, error) {
+	i := strings.Index(bucketKey, "/")
+	if i <= 0 || i == len(bucketKey)-1 {
+		return nil, fmt.Errorf("expected s3://bucket/key")
+	}
+	region, accessKey, secretKey, err := awsCredentials()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("GET", s3Endpoint(region, bucketKey[:i])+"/"+bucketKey[i+1:], nil)
+	if err != nil {
+		return nil, err
+	}
+	empty := sha256.Sum256(nil)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(empty[:]))
+	if token := os.Getenv("AWS_SESSION_TOKEN"); token != "" {
+		req.Header.Set("X-Amz-Security-Token", token)
+	}
+	signAWS(req, nil, "s3", region, accessKey, secretKey, time.Now())
+	return req, nil
+}
+
+// redactURL is u without its password, which errors shouldn't print
+func redactURL(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.User == nil {
+		return u
+	}
+	if _, ok := parsed.User.Password(); ok {
+		parsed.User = url.UserPassword(parsed.User.Username(), "xxxxx")
+	}
+	return parsed.String()
+}
@@ -0,0 +1,185 @@
+package goss
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// inspectMaxBytes is how much of contents, such as the ones of a file or the
+// output of a command, inspect prints
+const inspectMaxBytes = 64 * 1024
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Inspect prints everything goss can read about the resource id of the
+// gossfile type resourceType, such as file or kernel-param, without testing
+// it. The values are printed under the names of the gossfile attributes where
+// there is one, to help write tests.
+func Inspect(resourceType, id string, c *util.Config) error {
+	sys, err := newSystem(c)
+	if err != nil {
+		return err
+	}
+	typeName, err := inspectTypeName(resourceType)
+	if err != nil {
+		return err
+	}
+	newFn := reflect.ValueOf(sys).Elem().FieldByName("New" + typeName)
+	if !newFn.IsValid() || newFn.IsNil() {
+		return fmt.Errorf("%s resources can't be inspected", resourceType)
+	}
+	sysRes := newFn.Call([]reflect.Value{reflect.ValueOf(id), reflect.ValueOf(sys), reflect.ValueOf(util.Config{Timeout: c.Timeout})})[0]
+
+	var w io.Writer = os.Stdout
+	if c.OutputWriter != nil {
+		w = c.OutputWriter
+	}
+	key, _ := yaml.Marshal(id)
+	fmt.Fprintf(w, "%s:\n  %s:\n", resourceType, strings.TrimSuffix(string(key), "\n"))
+
+	attrs := inspectAttributes(typeName)
+	t := sysRes.Type()
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		if m.Type.NumIn() != 0 || m.Type.NumOut() != 2 || m.Type.Out(1) != errorType {
+			continue
+		}
+		name, ok := attrs[m.Name]
+		if !ok {
+			name = kebabCase(m.Name)
+		}
+		out := sysRes.Method(i).Call(nil)
+		if err, _ := out[1].Interface().(error); err != nil {
+			fmt.Fprintf(w, "    %s: # error: %v\n", name, err)
+			continue
+		}
+		printInspected(w, name, out[0].Interface())
+	}
+	return nil
+}
+
+// inspectTypeName is the Go type of the gossfile key resourceType
+func inspectTypeName(resourceType string) (string, error) {
+	t := reflect.TypeOf(GossConfig{})
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		key := strings.Split(f.Tag.Get("yaml"), ",")[0]
+		if key == resourceType {
+			return f.Type.Elem().Elem().Name(), nil
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return "", fmt.Errorf("unknown resource type %q, must be one of %s", resourceType, strings.Join(keys, ", "))
+}
+
+// inspectAttributes maps the fields of the resource typeName to their
+// gossfile attributes, zero-argument methods of the system resource are
+// named after the field they're tested with
+func inspectAttributes(typeName string) map[string]string {
+	attrs := make(map[string]string)
+	t, ok := resourceTypes()[typeName]
+	if !ok {
+		return attrs
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if name := strings.Split(f.Tag.Get("yaml"), ",")[0]; name != "" && name != "-" {
+			attrs[f.Name] = name
+		}
+	}
+	return attrs
+}
+
+// resourceTypes maps the resource type names to their struct types
+func resourceTypes() map[string]reflect.Type {
+	types := make(map[string]reflect.Type)
+	t := reflect.TypeOf(GossConfig{})
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath != "" {
+			continue
+		}
+		rt := t.Field(i).Type.Elem().Elem()
+		types[rt.Name()] = rt
+	}
+	return types
+}
+
+func printInspected(w io.Writer, name string, v interface{}) {
+	if r, ok := v.(io.Reader); ok {
+		if rc, ok := r.(io.ReadCloser); ok {
+			defer rc.Close()
+		}
+		b, err := ioutil.ReadAll(io.LimitReader(r, inspectMaxBytes+1))
+		if err != nil {
+			fmt.Fprintf(w, "    %s: # error: %v\n", name, err)
+			return
+		}
+		truncated := len(b) > inspectMaxBytes
+		if truncated {
+			b = b[:inspectMaxBytes]
+		}
+		printInspected(w, name, string(b))
+		if truncated {
+			fmt.Fprintf(w, "    # %s: only the first %d bytes are shown\n", name, inspectMaxBytes)
+		}
+		return
+	}
+	if s, ok := v.(string); ok && strings.Contains(s, "\n") {
+		indicator := "|-"
+		if strings.HasSuffix(s, "\n") {
+			indicator = "|"
+		}
+		fmt.Fprintf(w, "    %s: %s\n", name, indicator)
+		for _, line := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
+			fmt.Fprintf(w, "      %s\n", line)
+		}
+		return
+	}
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		fmt.Fprintf(w, "    %s: # error: %v\n", name, err)
+		return
+	}
+	out := strings.TrimSuffix(string(b), "\n")
+	if !strings.Contains(out, "\n") && !strings.HasPrefix(out, "- ") {
+		fmt.Fprintf(w, "    %s: %s\n", name, out)
+		return
+	}
+	fmt.Fprintf(w, "    %s:\n", name)
+	for _, line := range strings.Split(out, "\n") {
+		fmt.Fprintf(w, "      %s\n", line)
+	}
+}
+
+// kebabCase is the attribute name of a method without a field, ExitStatus
+// becomes exit-status and TPMVersion tpm-version
+func kebabCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				b.WriteRune('-')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
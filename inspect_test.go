@@ -0,0 +1,46 @@
+package goss
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/aelsabbahy/goss/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.conf")
+	require.NoError(t, ioutil.WriteFile(file, []byte("port: 8080\nworkers: 4\n"), 0640))
+
+	var out bytes.Buffer
+	c, err := util.NewConfig()
+	require.NoError(t, err)
+	c.OutputWriter = &out
+	require.NoError(t, Inspect("file", file, c))
+
+	got := out.String()
+	assert.Contains(t, got, "file:\n  "+file+":\n")
+	assert.Contains(t, got, "    exists: true\n")
+	assert.Contains(t, got, "    mode: \"0640\"\n")
+	assert.Contains(t, got, "    contains: |\n      port: 8080\n      workers: 4\n")
+	assert.Contains(t, got, "    linked-to: # error: ")
+
+	assert.Error(t, Inspect("matching", "x", c))
+	assert.Error(t, Inspect("nope", "x", c))
+}
+
+func TestKebabCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"Exists":        "exists",
+		"ExitStatus":    "exit-status",
+		"TPMVersion":    "tpm-version",
+		"PCRBanks":      "pcr-banks",
+		"Sha256":        "sha256",
+		"SELinuxPolicy": "se-linux-policy",
+	} {
+		assert.Equal(t, want, kebabCase(in), in)
+	}
+}
@@ -0,0 +1,484 @@
+package goss
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+)
+
+// schemaRef is the prefix of the references to the definitions of the schema
+const schemaRef = "#/definitions/"
+
+// GossfileSchema is the JSON Schema of gossfiles, as published in
+// docs/schema.json. A resource type is a definition named after its gossfile
+// key, matchers and have-patterns are the matcher and patterns definitions.
+func GossfileSchema() map[string]interface{} {
+	definitions := map[string]interface{}{
+		"matcher": map[string]interface{}{
+			"description": "A value, or a matcher such as {have-prefix: foo}, one of: " + strings.Join(resource.Matchers, ", "),
+		},
+		"patterns": map[string]interface{}{
+			"description": "Patterns the output has to contain: strings, /regexes/, and either inverted with a leading !",
+			"type":        "array",
+			"items":       map[string]interface{}{"type": "string"},
+		},
+	}
+	properties := map[string]interface{}{}
+	names := resourceTypeNames()
+	t := reflect.TypeOf(GossConfig{})
+	for i := 0; i < t.NumField(); i++ {
+		key := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if _, ok := names[key]; !ok {
+			continue
+		}
+		definitions[key] = resourceSchema(t.Field(i).Type.Elem().Elem())
+		properties[key] = map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": map[string]interface{}{"$ref": schemaRef + key},
+		}
+	}
+	return map[string]interface{}{
+		"$schema":              "http://json-schema.org/draft-07/schema#",
+		"title":                "goss gossfile",
+		"type":                 "object",
+		"additionalProperties": false,
+		"properties":           properties,
+		"definitions":          definitions,
+	}
+}
+
+// GossfileSchemaJSON is GossfileSchema as docs/schema.json holds it
+func GossfileSchemaJSON() ([]byte, error) {
+	b, err := json.MarshalIndent(GossfileSchema(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+func resourceSchema(t reflect.Type) map[string]interface{} {
+	patterns := map[string]bool{}
+	for _, attr := range resource.PatternAttributes[t.Name()] {
+		patterns[attr] = true
+	}
+	properties := map[string]interface{}{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if f.PkgPath != "" || name == "" || name == "-" {
+			continue
+		}
+		if patterns[name] {
+			properties[name] = map[string]interface{}{"$ref": schemaRef + "patterns"}
+		} else if name == "severity" {
+			properties[name] = map[string]interface{}{"type": "string", "enum": resource.Severities}
+		} else {
+			properties[name] = typeSchema(f.Type)
+		}
+	}
+	return map[string]interface{}{
+		"type":                 []string{"object", "null"},
+		"additionalProperties": false,
+		"properties":           properties,
+	}
+}
+
+func typeSchema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Interface:
+		if t.Name() == "matcher" {
+			return map[string]interface{}{"$ref": schemaRef + "matcher"}
+		}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		if t.Name() == "meta" {
+			return map[string]interface{}{"type": "object"}
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	}
+	return map[string]interface{}{}
+}
+
+// LintProblem is a problem of a gossfile, Path is the keys of the value it
+// is about, such as the type, id and attribute of a resource
+type LintProblem struct {
+	File    string   `json:"file"`
+	Path    []string `json:"path,omitempty"`
+	Message string   `json:"message"`
+}
+
+func (p LintProblem) String() string {
+	return strings.Join(append([]string{p.File}, append(p.Path, p.Message)...), ": ")
+}
+
+// LintReport is the outcome of Lint, the json format
+type LintReport struct {
+	Files    []string      `json:"files"`
+	Problems []LintProblem `json:"problems"`
+}
+
+// Lint checks the gossfile of c and the gossfiles it includes, rendered with
+// the vars of c, against GossfileSchema before they're deployed: unknown
+// resource types and attributes, values of the wrong type and matchers and
+// patterns that would error. The exit code is 1 when it finds problems.
+func Lint(c *util.Config) (int, error) {
+	var w io.Writer = os.Stdout
+	if c.OutputWriter != nil {
+		w = c.OutputWriter
+	}
+	filter, err := configTemplateFilter(c)
+	if err != nil {
+		return 1, err
+	}
+	l := &linter{schema: GossfileSchema(), filter: filter, seen: map[string]bool{}}
+	l.definitions = l.schema["definitions"].(map[string]interface{})
+	if err := l.lintFile(c.Spec, "", nil, 0); err != nil {
+		return 1, err
+	}
+	report := LintReport{Files: l.files, Problems: l.problems}
+	if report.Problems == nil {
+		report.Problems = []LintProblem{}
+	}
+
+	switch c.OutputFormat {
+	case "json":
+		b, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			return 1, err
+		}
+		fmt.Fprintln(w, string(b))
+	case "", "text":
+		for _, p := range report.Problems {
+			fmt.Fprintln(w, p)
+		}
+		fmt.Fprintf(w, "Gossfiles: %d, Problems: %d\n", len(report.Files), len(report.Problems))
+	default:
+		return 1, fmt.Errorf("unknown lint format %q, expected text or json", c.OutputFormat)
+	}
+	if len(report.Problems) > 0 {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+type linter struct {
+	schema      map[string]interface{}
+	definitions map[string]interface{}
+	filter      TemplateFilter
+	seen        map[string]bool
+	files       []string
+	problems    []LintProblem
+	// file and loose are those of the gossfile being linted, YAML decodes
+	// any scalar as a string
+	file  string
+	loose bool
+}
+
+func (l *linter) report(path []string, format string, a ...interface{}) {
+	l.problems = append(l.problems, LintProblem{File: l.file, Path: append([]string{}, path...), Message: fmt.Sprintf(format, a...)})
+}
+
+// lintFile lints the gossfile at spec, pinned to the sha256 pin, and then the
+// gossfiles it includes. A preset is rendered with vars. Only reading them is
+// an error, what's wrong with them is a problem.
+func (l *linter) lintFile(spec, pin string, vars map[string]interface{}, depth int) error {
+	if depth >= 50 {
+		return fmt.Errorf("max depth of 50 reached, possibly due to dependency loop in goss file")
+	}
+	if l.seen[spec] {
+		return nil
+	}
+	l.seen[spec] = true
+	l.files = append(l.files, spec)
+
+	var data []byte
+	var err error
+	if spec == "-" {
+		if data, err = ioutil.ReadAll(os.Stdin); err != nil {
+			return fmt.Errorf("file error: %v", err)
+		}
+	} else if isPreset(spec) {
+		if data, err = readPreset(spec, vars); err != nil {
+			l.file = spec
+			l.report(nil, "%v", err)
+			return nil
+		}
+	} else if data, err = readInclude(spec, pin); err != nil {
+		return fmt.Errorf("%s: %v", redactURL(spec), err)
+	}
+	l.file = spec
+	// Presets are rendered with their own vars
+	if !isPreset(spec) {
+		if data, err = l.filter(data); err != nil {
+			l.report(nil, "%v", err)
+			return nil
+		}
+	}
+	format, err := getStoreFormatFromFileName(spec)
+	if spec == "-" || isRemoteInclude(spec) {
+		format, err = getStoreFormatFromData(data)
+	} else if isPreset(spec) {
+		format, err = YAML, nil
+	}
+	if err == nil {
+		data, err = convertToJSON(data, format)
+	}
+	if err != nil {
+		l.report(nil, "%v", err)
+		return nil
+	}
+	// Not unmarshalYAML, its errors quote the whole gossfile
+	var doc interface{}
+	if format == YAML {
+		err = yaml.Unmarshal(data, &doc)
+	} else {
+		err = unmarshalJSON(data, &doc)
+	}
+	if err != nil {
+		l.report(nil, "%v", err)
+		return nil
+	}
+	l.loose = format == YAML
+	doc = lintValue(doc)
+	l.check(l.schema, doc, nil)
+
+	// Includes are relative to the gossfile, stdin's to the working directory
+	m, _ := doc.(map[string]interface{})
+	includes, _ := m["gossfile"].(map[string]interface{})
+	var paths []string
+	for p := range includes {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		matches, err := includePaths(includeDir(spec), p)
+		if err != nil {
+			l.file = spec
+			l.report([]string{"gossfile", p}, "%v", err)
+			continue
+		}
+		attrs, _ := includes[p].(map[string]interface{})
+		pin, _ := attrs["sha256"].(string)
+		vars, _ := attrs["vars"].(map[string]interface{})
+		if len(vars) > 0 && !isPreset(p) {
+			l.file = spec
+			l.report([]string{"gossfile", p, "vars"}, "vars are only those of presets, other gossfiles are rendered with --vars")
+		}
+		for _, match := range matches {
+			if err := l.lintFile(match, pin, vars, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// lintValue is v with the maps YAML decodes as map[string]interface{}, like
+// JSON
+func lintValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for k, e := range x {
+			m[fmt.Sprint(k)] = lintValue(e)
+		}
+		return m
+	case map[string]interface{}:
+		for k, e := range x {
+			x[k] = lintValue(e)
+		}
+	case []interface{}:
+		for i, e := range x {
+			x[i] = lintValue(e)
+		}
+	}
+	return v
+}
+
+// check reports where value doesn't match schema, the subset of JSON Schema
+// GossfileSchema uses
+func (l *linter) check(schema map[string]interface{}, value interface{}, path []string) {
+	ref, _ := schema["$ref"].(string)
+	if ref != "" {
+		schema = l.definitions[strings.TrimPrefix(ref, schemaRef)].(map[string]interface{})
+	}
+	if value == nil {
+		return
+	}
+	if !l.hasType(schema["type"], value) {
+		l.report(path, "expected %s, found %s", schemaTypes(schema["type"]), describeLintValue(value))
+		return
+	}
+	if enum, ok := schema["enum"].([]string); ok && !util.IsValueInList(fmt.Sprint(value), enum) {
+		l.report(path, "expected one of %s, found %s", strings.Join(enum, ", "), describeLintValue(value))
+		return
+	}
+	switch x := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if p, ok := properties[k].(map[string]interface{}); ok {
+				l.check(p, x[k], append(path, k))
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case map[string]interface{}:
+				l.check(additional, x[k], append(path, k))
+			case bool:
+				if !additional {
+					l.reportUnknown(path, k, properties)
+				}
+			}
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, e := range x {
+				l.check(items, e, append(path, strconv.Itoa(i)))
+			}
+		}
+	}
+
+	switch ref {
+	case schemaRef + "matcher":
+		if err := resource.LintMatcher(value); err != nil {
+			l.report(path, "invalid matcher: %v", err)
+		}
+	case schemaRef + "patterns":
+		list, _ := value.([]interface{})
+		patterns := make([]string, 0, len(list))
+		for _, e := range list {
+			patterns = append(patterns, fmt.Sprint(e))
+		}
+		if err := resource.LintPatterns(patterns); err != nil {
+			l.report(path, "invalid pattern: %v", err)
+		}
+	}
+}
+
+func (l *linter) reportUnknown(path []string, key string, properties map[string]interface{}) {
+	what := "attribute"
+	if len(path) == 0 {
+		what = "resource type"
+	}
+	best, distance := "", 3
+	for p := range properties {
+		if d := editDistance(key, p); d < distance || d == distance && p < best {
+			best, distance = p, d
+		}
+	}
+	if best != "" {
+		l.report(path, "unknown %s %s, did you mean %s?", what, key, best)
+	} else {
+		l.report(path, "unknown %s %s", what, key)
+	}
+}
+
+func (l *linter) hasType(types interface{}, value interface{}) bool {
+	switch t := types.(type) {
+	case nil:
+		return true
+	case []string:
+		for _, typ := range t {
+			if l.hasType(typ, value) {
+				return true
+			}
+		}
+		return false
+	case string:
+		switch v := value.(type) {
+		case string:
+			return t == "string"
+		case bool:
+			return t == "boolean" || t == "string" && l.loose
+		case int:
+			return t == "integer" || t == "number" || t == "string" && l.loose
+		case float64:
+			return t == "number" || t == "integer" && v == math.Trunc(v) || t == "string" && l.loose
+		case map[string]interface{}:
+			return t == "object"
+		case []interface{}:
+			return t == "array"
+		}
+	}
+	return false
+}
+
+func schemaTypes(types interface{}) string {
+	if list, ok := types.([]string); ok {
+		return strings.Join(list, " or ")
+	}
+	return fmt.Sprint(types)
+}
+
+func describeLintValue(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return "string " + strconv.Quote(x)
+	case bool:
+		return fmt.Sprintf("boolean %v", x)
+	case int, float64:
+		return fmt.Sprintf("number %v", x)
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// editDistance is the Levenshtein distance of a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,81 @@
+package goss
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/aelsabbahy/goss/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("goss.yaml", `gossfile:
+  "*.json": {}
+file:
+  /etc/passwd:
+    exsits: true
+    mode: 0644
+    contains: ["/[a-/"]
+command:
+  "{{.Vars.cmd}}":
+    exit-status: {lt: 1}
+    timeout: 5s
+    stdout-json:
+      $.a: {and: [{have-prefix: a}, {match-regexp: "(x"}]}
+servcie:
+  nginx: {running: true}
+`)
+	write("port.json", `{"port": {"tcp:22": {"listening": true, "severity": "critical", "skip": "no"}}}`)
+	write("vars.yaml", "cmd: true\n")
+
+	var out bytes.Buffer
+	c := &util.Config{Spec: filepath.Join(dir, "goss.yaml"), Vars: filepath.Join(dir, "vars.yaml"), OutputFormat: "json", OutputWriter: &out}
+	code, err := Lint(c)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, code)
+	var report LintReport
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &report))
+
+	gossfile, port := filepath.Join(dir, "goss.yaml"), filepath.Join(dir, "port.json")
+	assert.Equal(t, []string{gossfile, port}, report.Files)
+	assert.Equal(t, []LintProblem{
+		{File: gossfile, Path: []string{"command", "true", "stdout-json", "$.a"}, Message: "invalid matcher: and: match-regexp: error parsing regexp: missing closing ): `(x`"},
+		{File: gossfile, Path: []string{"command", "true", "timeout"}, Message: `expected integer, found string "5s"`},
+		{File: gossfile, Path: []string{"file", "/etc/passwd", "contains"}, Message: "invalid pattern: error parsing regexp: missing closing ]: `[a-`"},
+		{File: gossfile, Path: []string{"file", "/etc/passwd"}, Message: "unknown attribute exsits, did you mean exists?"},
+		{File: gossfile, Message: "unknown resource type servcie, did you mean service?"},
+		{File: port, Path: []string{"port", "tcp:22", "severity"}, Message: `expected one of fail, warn, info, found string "critical"`},
+		{File: port, Path: []string{"port", "tcp:22", "skip"}, Message: `expected boolean, found string "no"`},
+	}, report.Problems)
+
+	out.Reset()
+	write("goss.yaml", "file:\n  /etc/passwd:\n    exists: true\n")
+	c.OutputFormat = "text"
+	code, err = Lint(c)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "Gossfiles: 1, Problems: 0\n", out.String())
+}
+
+func TestGossfileSchemaPublished(t *testing.T) {
+	published, err := ioutil.ReadFile("docs/schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := GossfileSchemaJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(published, schema) {
+		t.Error("docs/schema.json is out of date, regenerate it with: goss lint --schema > docs/schema.json")
+	}
+}
@@ -0,0 +1,113 @@
+package goss
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aelsabbahy/goss/resource"
+)
+
+// MaintenanceWindow is an entry of --maintenance-file, between From and Until
+// the failures of the tests it matches are reported as warnings
+type MaintenanceWindow struct {
+	// Resource is the gossfile key of the resource type, all types when empty
+	Resource string `json:"resource,omitempty" yaml:"resource,omitempty"`
+	// ID is a glob of the resource IDs, all IDs when empty
+	ID     string `json:"id,omitempty" yaml:"id,omitempty"`
+	From   string `json:"from,omitempty" yaml:"from,omitempty"`
+	Until  string `json:"until" yaml:"until"`
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+
+	from, until time.Time
+}
+
+// loadMaintenance reads the maintenance windows of file, it's read on every
+// run so windows can be added without restarting goss serve
+func loadMaintenance(file string) ([]MaintenanceWindow, error) {
+	if file == "" {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("maintenance file error: %v", err)
+	}
+	var windows []MaintenanceWindow
+	if err := unmarshalYAML(data, &windows); err != nil {
+		return nil, err
+	}
+	for i := range windows {
+		w := &windows[i]
+		if w.Resource == "" && w.ID == "" {
+			return nil, fmt.Errorf("maintenance window %d: resource or id is required", i+1)
+		}
+		if _, err := path.Match(w.ID, ""); err != nil {
+			return nil, fmt.Errorf("maintenance window %d: invalid id %q: %v", i+1, w.ID, err)
+		}
+		if w.until, err = time.Parse(time.RFC3339, w.Until); err != nil {
+			return nil, fmt.Errorf("maintenance window %d: until: %v", i+1, err)
+		}
+		if w.From != "" {
+			if w.from, err = time.Parse(time.RFC3339, w.From); err != nil {
+				return nil, fmt.Errorf("maintenance window %d: from: %v", i+1, err)
+			}
+		}
+		if w.Reason == "" {
+			w.Reason = "until " + w.Until
+		}
+	}
+	return windows, nil
+}
+
+func (w MaintenanceWindow) active(now time.Time) bool {
+	return !now.Before(w.from) && now.Before(w.until)
+}
+
+func (w MaintenanceWindow) matches(t resource.TestResult) bool {
+	// ResourceType is the Go type name, KernelParam for kernel-param
+	if w.Resource != "" && strings.ToLower(t.ResourceType) != strings.ToLower(strings.Replace(w.Resource, "-", "", -1)) {
+		return false
+	}
+	if w.ID == "" {
+		return true
+	}
+	ok, _ := path.Match(w.ID, t.ResourceId)
+	return ok
+}
+
+// MaintenanceResults sets the Maintenance reason of the tests that didn't pass
+// and match a window active at now, so they're reported as warnings
+func MaintenanceResults(in <-chan []resource.TestResult, windows []MaintenanceWindow, now time.Time) <-chan []resource.TestResult {
+	var active []MaintenanceWindow
+	for _, w := range windows {
+		if w.active(now) {
+			active = append(active, w)
+		}
+	}
+	if len(active) == 0 {
+		return in
+	}
+
+	out := make(chan []resource.TestResult)
+	go func() {
+		defer close(out)
+		for resultGroup := range in {
+			for i, testResult := range resultGroup {
+				if testResult.Result == resource.SUCCESS || testResult.Result == resource.SKIP {
+					continue
+				}
+				for _, w := range active {
+					if w.matches(testResult) {
+						resultGroup[i].Maintenance = w.Reason
+						break
+					}
+				}
+			}
+			out <- resultGroup
+		}
+	}()
+
+	return out
+}
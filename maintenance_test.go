@@ -0,0 +1,79 @@
+package goss
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceResults(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "maintenance.yaml")
+	require.NoError(t, ioutil.WriteFile(file, []byte(`
+- resource: kernel-param
+  id: net.ipv4.*
+  until: 2026-01-02T00:00:00Z
+  reason: kernel upgrade
+- id: /var/lib/*
+  from: 2026-01-01T12:00:00Z
+  until: 2026-01-01T13:00:00Z
+`), 0644))
+	windows, err := loadMaintenance(file)
+	require.NoError(t, err)
+
+	run := func(now time.Time, results ...resource.TestResult) []resource.TestResult {
+		in := make(chan []resource.TestResult, 1)
+		in <- results
+		close(in)
+		return <-MaintenanceResults(in, windows, now)
+	}
+	got := run(time.Date(2026, 1, 1, 12, 30, 0, 0, time.UTC),
+		resource.TestResult{ResourceType: "KernelParam", ResourceId: "net.ipv4.ip_forward", Result: resource.FAIL},
+		resource.TestResult{ResourceType: "KernelParam", ResourceId: "net.ipv4.ip_forward", Result: resource.SUCCESS},
+		resource.TestResult{ResourceType: "KernelParam", ResourceId: "kernel.panic", Result: resource.FAIL},
+		resource.TestResult{ResourceType: "File", ResourceId: "/var/lib/app", Result: resource.ERROR},
+	)
+	assert.Equal(t, "kernel upgrade", got[0].Maintenance)
+	assert.True(t, got[0].Warning())
+	assert.Empty(t, got[1].Maintenance)
+	assert.Empty(t, got[2].Maintenance)
+	assert.Equal(t, "until 2026-01-01T13:00:00Z", got[3].Maintenance)
+
+	got = run(time.Date(2026, 1, 1, 13, 0, 0, 0, time.UTC),
+		resource.TestResult{ResourceType: "File", ResourceId: "/var/lib/app", Result: resource.FAIL},
+	)
+	assert.Empty(t, got[0].Maintenance, "window should have ended")
+
+	for _, bad := range []string{"- id: a\n", "- until: 2026-01-02T00:00:00Z\n", "- id: a\n  until: tomorrow\n", "- id: '['\n  until: 2026-01-02T00:00:00Z\n"} {
+		require.NoError(t, ioutil.WriteFile(file, []byte(bad), 0644))
+		_, err := loadMaintenance(file)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestServeMaintenance(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "maintenance.yaml")
+	require.NoError(t, ioutil.WriteFile(file, []byte("- resource: command\n  until: 2999-01-01T00:00:00Z\n"), 0644))
+	config, err := util.NewConfig(
+		util.WithSpecFile(filepath.Join("testdata", "failing.goss.yaml")),
+		util.WithMaintenanceFile(file),
+	)
+	require.NoError(t, err)
+	hh, err := newHealthHandler(config)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", config.Endpoint, nil)
+	require.NoError(t, err)
+	hh.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Warnings:")
+}
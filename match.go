@@ -0,0 +1,126 @@
+package goss
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+)
+
+const matchHelp = `Enter a matcher to match the value against, such as {have-prefix: foo}
+  :value <yaml>  sets the value, such as :value [80, 443]
+  :file <path>   sets the value to the contents of a file
+  :show          prints the value
+  :quit          exits
+`
+
+// Match prints whether value matches the yaml matcher, as it's written in a
+// gossfile, such as {and: [{gt: 1}, {lt: 10}]}
+func Match(matcher string, value interface{}, c *util.Config) (bool, error) {
+	return match(matcherOutput(c), matcher, value)
+}
+
+// ReadMatchValue is the value to match, the contents of valueFile as a string
+// when it's set, otherwise the yaml value
+func ReadMatchValue(value, valueFile string) (interface{}, error) {
+	if valueFile == "" {
+		return parseMatchValue(value)
+	}
+	if value != "" {
+		return nil, fmt.Errorf("only one of a value and a value file can be matched")
+	}
+	b, err := ioutil.ReadFile(valueFile)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// MatchREPL reads matchers from r and prints whether the value, which can be
+// changed with :value and :file, matches them until r ends or :quit
+func MatchREPL(r io.Reader, value interface{}, c *util.Config) error {
+	w := matcherOutput(c)
+	v := value
+	fmt.Fprint(w, matchHelp)
+	scanner := bufio.NewScanner(r)
+	for fmt.Fprint(w, "> "); scanner.Scan(); fmt.Fprint(w, "> ") {
+		line := strings.TrimSpace(scanner.Text())
+		cmd, arg := line, ""
+		if i := strings.Index(line, " "); i > 0 {
+			cmd, arg = line[:i], strings.TrimSpace(line[i+1:])
+		}
+		switch {
+		case line == "":
+		case cmd == ":quit" || cmd == ":q":
+			return nil
+		case cmd == ":help":
+			fmt.Fprint(w, matchHelp)
+		case cmd == ":show":
+			fmt.Fprintf(w, "%#v\n", v)
+		case cmd == ":value":
+			if nv, err := parseMatchValue(arg); err != nil {
+				fmt.Fprintf(w, "Error: %v\n", err)
+			} else {
+				v = nv
+			}
+		case cmd == ":file":
+			if nv, err := ReadMatchValue("", arg); err != nil {
+				fmt.Fprintf(w, "Error: %v\n", err)
+			} else {
+				v = nv
+			}
+		case strings.HasPrefix(cmd, ":"):
+			fmt.Fprintf(w, "Error: unknown command %s, try :help\n", cmd)
+		default:
+			if _, err := match(w, line, v); err != nil {
+				fmt.Fprintf(w, "Error: %v\n", err)
+			}
+		}
+	}
+	fmt.Fprintln(w)
+	return scanner.Err()
+}
+
+func matcherOutput(c *util.Config) io.Writer {
+	if c.OutputWriter != nil {
+		return c.OutputWriter
+	}
+	return os.Stdout
+}
+
+// parseMatchValue is the yaml value, quoted numbers and booleans stay
+// strings as the output of a command would be, and nothing is ""
+func parseMatchValue(value string) (interface{}, error) {
+	if strings.TrimSpace(value) == "" {
+		return "", nil
+	}
+	var v interface{}
+	if err := yaml.Unmarshal([]byte(value), &v); err != nil {
+		return nil, fmt.Errorf("invalid value: %v", err)
+	}
+	return v, nil
+}
+
+func match(w io.Writer, matcher string, value interface{}) (bool, error) {
+	var m interface{}
+	if err := yaml.Unmarshal([]byte(matcher), &m); err != nil {
+		return false, fmt.Errorf("invalid matcher: %v", err)
+	}
+	success, message, err := resource.EvaluateMatcher(m, value)
+	if err != nil {
+		return false, err
+	}
+	if success {
+		fmt.Fprintln(w, "PASS")
+	} else {
+		fmt.Fprintf(w, "FAIL\n%s\n", message)
+	}
+	return success, nil
+}
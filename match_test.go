@@ -0,0 +1,53 @@
+package goss
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aelsabbahy/goss/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	var out bytes.Buffer
+	c := &util.Config{OutputWriter: &out}
+
+	for _, tt := range []struct {
+		matcher, value string
+		want           bool
+	}{
+		{"{and: [{gt: 1}, {lt: 10}]}", "5", true},
+		{"{have-prefix: foo}", "foobar", true},
+		{"[80, 443]", "[22, 80]", false},
+		{`"8080"`, `"8080"`, true},
+		{"8080", `"8080"`, false},
+	} {
+		v, err := ReadMatchValue(tt.value, "")
+		require.NoError(t, err)
+		got, err := Match(tt.matcher, v, c)
+		assert.NoError(t, err, tt.matcher)
+		assert.Equal(t, tt.want, got, "%s against %s", tt.matcher, tt.value)
+	}
+
+	_, err := Match("{have-prefix: foo}", 5, c)
+	assert.Error(t, err)
+	_, err = Match("{bogus: 1}", 5, c)
+	assert.Error(t, err)
+	_, err = ReadMatchValue("5", "value.txt")
+	assert.Error(t, err)
+}
+
+func TestMatchREPL(t *testing.T) {
+	var out bytes.Buffer
+	c := &util.Config{OutputWriter: &out}
+	in := strings.NewReader("{gt: 3}\n:value 2\n{gt: 3}\n{bogus: 1}\n:quit\n{gt: 1}\n")
+	require.NoError(t, MatchREPL(in, 5, c))
+
+	got := out.String()
+	assert.Contains(t, got, "> PASS\n")
+	assert.Contains(t, got, "> FAIL\n")
+	assert.Contains(t, got, "Error: Unknown matcher: bogus\n")
+	assert.Equal(t, 1, strings.Count(got, "PASS"))
+}
@@ -0,0 +1,89 @@
+package matchers
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/onsi/gomega/format"
+	"github.com/onsi/gomega/types"
+)
+
+// ChecksumAlgorithms are the algorithms of the checksum matcher
+var ChecksumAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// HaveChecksum succeeds when the digest of actual, a string, bytes or a
+// stream read to its end, is checksum, an algorithm and hex digest such as
+// "sha256:9f86d0...". The failures report the digest found rather than
+// actual, so large outputs can be pinned without showing them.
+func HaveChecksum(checksum interface{}) types.GomegaMatcher {
+	return &HaveChecksumMatcher{
+		Checksum: checksum,
+	}
+}
+
+type HaveChecksumMatcher struct {
+	Checksum interface{}
+	found    string
+}
+
+func (matcher *HaveChecksumMatcher) Match(actual interface{}) (success bool, err error) {
+	algorithm, digest, err := ParseChecksum(matcher.Checksum)
+	if err != nil {
+		return false, err
+	}
+	h := ChecksumAlgorithms[algorithm]()
+	switch v := actual.(type) {
+	case string:
+		io.WriteString(h, v)
+	case []byte:
+		h.Write(v)
+	case io.Reader:
+		if _, err := io.Copy(h, v); err != nil {
+			return false, err
+		}
+	default:
+		return false, fmt.Errorf("HaveChecksum matcher expects a string, bytes or a stream.  Got:\n%s", format.Object(actual, 1))
+	}
+	found := hex.EncodeToString(h.Sum(nil))
+	matcher.found = algorithm + ":" + found
+	return found == digest, nil
+}
+
+func (matcher *HaveChecksumMatcher) FailureMessage(actual interface{}) (message string) {
+	return format.Message(matcher.found, "to be the checksum", matcher.Checksum)
+}
+
+func (matcher *HaveChecksumMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	return format.Message(matcher.found, "not to be the checksum", matcher.Checksum)
+}
+
+// ParseChecksum splits checksum, such as "sha256:9f86d0...", into its
+// algorithm and lower case hex digest
+func ParseChecksum(checksum interface{}) (algorithm, digest string, err error) {
+	s, ok := checksum.(string)
+	parts := strings.SplitN(s, ":", 2)
+	if !ok || len(parts) != 2 {
+		return "", "", fmt.Errorf("Expected a checksum such as \"sha256:<hex digest>\".  Got:\n%s", format.Object(checksum, 1))
+	}
+	algorithm, digest = strings.ToLower(parts[0]), strings.ToLower(parts[1])
+	newHash, ok := ChecksumAlgorithms[algorithm]
+	if !ok {
+		return "", "", fmt.Errorf("Unknown checksum algorithm %q, expected one of: md5, sha1, sha256, sha512", parts[0])
+	}
+	if b, err := hex.DecodeString(digest); err != nil || len(b) != newHash().Size() {
+		return "", "", fmt.Errorf("Expected a %s digest of %d hex characters.  Got: %s", algorithm, 2*newHash().Size(), parts[1])
+	}
+	return algorithm, digest, nil
+}
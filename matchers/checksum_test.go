@@ -0,0 +1,54 @@
+package matchers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSha256 = "sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+func TestHaveChecksumMatcher_Match(t *testing.T) {
+	tests := []struct {
+		name     string
+		checksum interface{}
+		actual   interface{}
+		want     bool
+		wantErr  bool
+	}{
+		{name: "string", checksum: testSha256, actual: "test", want: true},
+		{name: "bytes", checksum: testSha256, actual: []byte("test"), want: true},
+		{name: "stream", checksum: testSha256, actual: strings.NewReader("test"), want: true},
+		{name: "upper_case", checksum: strings.ToUpper(testSha256), actual: "test", want: true},
+		{name: "md5", checksum: "md5:098f6bcd4621d373cade4e832627b4f6", actual: "test", want: true},
+		{name: "different", checksum: testSha256, actual: "test\n", want: false},
+		{name: "no_algorithm", checksum: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", actual: "test", wantErr: true},
+		{name: "unknown_algorithm", checksum: "crc32:d87f7e0c", actual: "test", wantErr: true},
+		{name: "short_digest", checksum: "sha256:9f86d081", actual: "test", wantErr: true},
+		{name: "not_hex", checksum: "md5:098f6bcd4621d373cade4e832627b4fz", actual: "test", wantErr: true},
+		{name: "number", checksum: testSha256, actual: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := HaveChecksum(tt.checksum).Match(tt.actual)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHaveChecksumMatcher_FailureMessage(t *testing.T) {
+	m := HaveChecksum("md5:00000000000000000000000000000000")
+	actual := strings.Repeat("large output ", 1000)
+	ok, err := m.Match(actual)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	msg := m.FailureMessage(actual)
+	assert.Contains(t, msg, "md5:")
+	assert.NotContains(t, msg, "large output")
+}
@@ -0,0 +1,53 @@
+package matchers
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/onsi/gomega/format"
+	"github.com/onsi/gomega/types"
+)
+
+// BeInCIDR succeeds when actual is an address within cidr, addresses with a
+// prefix length such as 10.0.0.5/24, as interfaces report them, are accepted
+func BeInCIDR(cidr interface{}) types.GomegaMatcher {
+	return &BeInCIDRMatcher{
+		CIDR: cidr,
+	}
+}
+
+type BeInCIDRMatcher struct {
+	CIDR interface{}
+}
+
+func (matcher *BeInCIDRMatcher) Match(actual interface{}) (success bool, err error) {
+	s, ok := matcher.CIDR.(string)
+	if !ok {
+		return false, fmt.Errorf("Expected a CIDR such as 10.0.0.0/8.  Got:\n%s", format.Object(matcher.CIDR, 1))
+	}
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return false, fmt.Errorf("Expected a CIDR such as 10.0.0.0/8.  Got:\n%s", format.Object(matcher.CIDR, 1))
+	}
+	addr, ok := actual.(string)
+	if !ok {
+		return false, fmt.Errorf("Expected an address.  Got:\n%s", format.Object(actual, 1))
+	}
+	if i := strings.Index(addr, "/"); i >= 0 {
+		addr = addr[:i]
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false, fmt.Errorf("Expected an address.  Got:\n%s", format.Object(actual, 1))
+	}
+	return network.Contains(ip), nil
+}
+
+func (matcher *BeInCIDRMatcher) FailureMessage(actual interface{}) (message string) {
+	return format.Message(actual, "to be in", matcher.CIDR)
+}
+
+func (matcher *BeInCIDRMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	return format.Message(actual, "not to be in", matcher.CIDR)
+}
@@ -0,0 +1,37 @@
+package matchers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBeInCIDRMatcher_Match(t *testing.T) {
+	tests := []struct {
+		name    string
+		cidr    interface{}
+		actual  interface{}
+		want    bool
+		wantErr bool
+	}{
+		{name: "ipv4", cidr: "10.0.0.0/8", actual: "10.1.2.3", want: true},
+		{name: "ipv4_prefix", cidr: "10.0.0.0/8", actual: "10.1.2.3/24", want: true},
+		{name: "ipv4_outside", cidr: "10.0.0.0/8", actual: "172.17.0.2/16", want: false},
+		{name: "ipv6", cidr: "2001:db8::/32", actual: "2001:db8:1::5/64", want: true},
+		{name: "ipv6_link_local", cidr: "fe80::/10", actual: "fe80::42:acff:fe11:2/64", want: true},
+		{name: "family", cidr: "2001:db8::/32", actual: "10.1.2.3", want: false},
+		{name: "invalid_cidr", cidr: "10.0.0.0", actual: "10.1.2.3", wantErr: true},
+		{name: "invalid_addr", cidr: "10.0.0.0/8", actual: "eth0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BeInCIDR(tt.cidr).Match(tt.actual)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
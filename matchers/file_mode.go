@@ -0,0 +1,55 @@
+package matchers
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/onsi/gomega/format"
+	"github.com/onsi/gomega/types"
+)
+
+// BeModeAtMost succeeds when actual, an octal file mode such as 0640, has no
+// permission bits that max doesn't have, as security baselines require
+func BeModeAtMost(max interface{}) types.GomegaMatcher {
+	return &BeModeAtMostMatcher{
+		Max: max,
+	}
+}
+
+type BeModeAtMostMatcher struct {
+	Max interface{}
+}
+
+func (matcher *BeModeAtMostMatcher) Match(actual interface{}) (success bool, err error) {
+	max, ok := ParseFileMode(matcher.Max)
+	if !ok {
+		return false, fmt.Errorf("Expected an octal mode such as \"0644\".  Got:\n%s", format.Object(matcher.Max, 1))
+	}
+	mode, ok := ParseFileMode(actual)
+	if !ok {
+		return false, fmt.Errorf("Expected an octal mode.  Got:\n%s", format.Object(actual, 1))
+	}
+	return mode&^max == 0, nil
+}
+
+func (matcher *BeModeAtMostMatcher) FailureMessage(actual interface{}) (message string) {
+	return format.Message(actual, "to have no permissions beyond", matcher.Max)
+}
+
+func (matcher *BeModeAtMostMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	return format.Message(actual, "to have permissions beyond", matcher.Max)
+}
+
+// ParseFileMode reads mode, an octal string of the permission and special
+// bits such as "0644" or "4755". Numbers aren't modes, YAML reads 0644 as 420.
+func ParseFileMode(mode interface{}) (uint32, bool) {
+	s, ok := mode.(string)
+	if !ok || s == "" || len(s) > 5 {
+		return 0, false
+	}
+	m, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || m > 07777 {
+		return 0, false
+	}
+	return uint32(m), true
+}
@@ -0,0 +1,40 @@
+package matchers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBeModeAtMostMatcher_Match(t *testing.T) {
+	tests := []struct {
+		name    string
+		max     interface{}
+		actual  interface{}
+		want    bool
+		wantErr bool
+	}{
+		{name: "equal", max: "0644", actual: "0644", want: true},
+		{name: "fewer_bits", max: "0644", actual: "0600", want: true},
+		{name: "group_write", max: "0644", actual: "0664", want: false},
+		{name: "execute", max: "0644", actual: "0755", want: false},
+		{name: "not_greater", max: "0640", actual: "0604", want: false},
+		{name: "setuid", max: "0755", actual: "4755", want: false},
+		{name: "sticky_allowed", max: "1777", actual: "1777", want: true},
+		{name: "short", max: "644", actual: "0400", want: true},
+		{name: "number", max: 420, actual: "0644", wantErr: true},
+		{name: "not_octal", max: "0648", actual: "0644", wantErr: true},
+		{name: "invalid_actual", max: "0644", actual: "rw-r--r--", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BeModeAtMost(tt.max).Match(tt.actual)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package matchers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/format"
+	"github.com/onsi/gomega/types"
+)
+
+// BeNumerically is gomega.BeNumerically that also accepts numbers in strings,
+// such as the values of kernel parameters
+func BeNumerically(comparator string, compareTo interface{}) types.GomegaMatcher {
+	return &BeNumericallyMatcher{
+		Comparator: comparator,
+		CompareTo:  compareTo,
+	}
+}
+
+type BeNumericallyMatcher struct {
+	Comparator string
+	CompareTo  interface{}
+}
+
+func (matcher *BeNumericallyMatcher) Match(actual interface{}) (success bool, err error) {
+	n, err := toNumber(actual)
+	if err != nil {
+		return false, err
+	}
+	return gomega.BeNumerically(matcher.Comparator, matcher.CompareTo).Match(n)
+}
+
+func (matcher *BeNumericallyMatcher) FailureMessage(actual interface{}) (message string) {
+	return format.Message(actual, fmt.Sprintf("to be %s", matcher.Comparator), matcher.CompareTo)
+}
+
+func (matcher *BeNumericallyMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	return format.Message(actual, fmt.Sprintf("not to be %s", matcher.Comparator), matcher.CompareTo)
+}
+
+// BeInRange succeeds when actual is between min and max, inclusive
+func BeInRange(min, max interface{}) types.GomegaMatcher {
+	return &BeInRangeMatcher{
+		Min: min,
+		Max: max,
+	}
+}
+
+type BeInRangeMatcher struct {
+	Min interface{}
+	Max interface{}
+}
+
+func (matcher *BeInRangeMatcher) Match(actual interface{}) (success bool, err error) {
+	n, err := toNumber(actual)
+	if err != nil {
+		return false, err
+	}
+	if success, err = gomega.BeNumerically(">=", matcher.Min).Match(n); !success || err != nil {
+		return false, err
+	}
+	return gomega.BeNumerically("<=", matcher.Max).Match(n)
+}
+
+func (matcher *BeInRangeMatcher) FailureMessage(actual interface{}) (message string) {
+	return format.Message(actual, fmt.Sprintf("to be within [%v, %v]", matcher.Min, matcher.Max))
+}
+
+func (matcher *BeInRangeMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	return format.Message(actual, fmt.Sprintf("not to be within [%v, %v]", matcher.Min, matcher.Max))
+}
+
+// toNumber returns numbers as they are and parses strings holding one number
+func toNumber(in interface{}) (interface{}, error) {
+	str, ok := in.(string)
+	if !ok {
+		return in, nil
+	}
+	s := strings.TrimSpace(str)
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, nil
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return u, nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("Expected a number.  Got:\n%s", format.Object(in, 1))
+}
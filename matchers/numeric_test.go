@@ -0,0 +1,71 @@
+package matchers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBeNumericallyMatcher_Match(t *testing.T) {
+	tests := []struct {
+		name       string
+		comparator string
+		compareTo  interface{}
+		actual     interface{}
+		want       bool
+		wantErr    bool
+	}{
+		{name: "int", comparator: ">=", compareTo: 262144, actual: 262144, want: true},
+		{name: "string", comparator: ">=", compareTo: 262144, actual: "262144", want: true},
+		{name: "string_less", comparator: ">=", compareTo: 262144, actual: "65530", want: false},
+		{name: "string_space", comparator: "<", compareTo: 1, actual: " 0\n", want: true},
+		{name: "string_float", comparator: ">", compareTo: 0.5, actual: "0.75", want: true},
+		{name: "string_uint64", comparator: ">", compareTo: 0, actual: "18446744073709551615", want: true},
+		{name: "string_negative", comparator: "<", compareTo: 0, actual: "-1", want: true},
+		{name: "not_a_number", comparator: ">", compareTo: 0, actual: "4096 87380 6291456", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BeNumerically(tt.comparator, tt.compareTo).Match(tt.actual)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBeInRangeMatcher_Match(t *testing.T) {
+	tests := []struct {
+		name    string
+		min     interface{}
+		max     interface{}
+		actual  interface{}
+		want    bool
+		wantErr bool
+	}{
+		{name: "min", min: 1, max: 10, actual: 1, want: true},
+		{name: "max", min: 1, max: 10, actual: "10", want: true},
+		{name: "below", min: 1, max: 10, actual: "0", want: false},
+		{name: "above", min: 1, max: 10, actual: 11, want: false},
+		{name: "float", min: 0.5, max: 1.5, actual: "1.25", want: true},
+		{name: "not_a_number", min: 1, max: 10, actual: "on", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BeInRange(tt.min, tt.max).Match(tt.actual)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBeInRangeMatcher_FailureMessage(t *testing.T) {
+	assert.Equal(t, "Expected\n    <string>: 11\nto be within [1, 10]", BeInRange(1, 10).FailureMessage("11"))
+}
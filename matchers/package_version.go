@@ -0,0 +1,488 @@
+package matchers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/onsi/gomega/format"
+	"github.com/onsi/gomega/types"
+)
+
+// VersionCompare returns a negative number when a is older than b, 0 when
+// they're the same version and a positive number when a is newer
+type VersionCompare func(a, b string) int
+
+// PackageVersionCompare is how the versions of packageManager are ordered,
+// dpkg, apk, pip, gem and npm have their own orders, the others, such as
+// pacman or snap, are ordered as rpm
+func PackageVersionCompare(packageManager string) VersionCompare {
+	switch packageManager {
+	case "dpkg":
+		return CompareDebVersions
+	case "apk":
+		return CompareApkVersions
+	case "pip", "gem":
+		return CompareLibraryVersions
+	case "npm":
+		return CompareSemVerVersions
+	default:
+		// pacman orders versions as rpm does, snap and flatpak versions are
+		// free-form
+		return CompareRPMVersions
+	}
+}
+
+// BePackageVersion succeeds when every version satisfies all of the
+// constraints, such as ">= 2.4.0" and "!= 2.4.3", a version without an
+// operator is the same as "="
+func BePackageVersion(constraints []string, compare VersionCompare) types.GomegaMatcher {
+	return &BePackageVersionMatcher{
+		Constraints: constraints,
+		compare:     compare,
+	}
+}
+
+type BePackageVersionMatcher struct {
+	Constraints []string
+	compare     VersionCompare
+}
+
+type versionConstraint struct {
+	op, version string
+}
+
+var versionOperators = []string{">=", "<=", "!=", "==", ">", "<", "="}
+
+func parseVersionConstraint(s string) (versionConstraint, error) {
+	s = strings.TrimSpace(s)
+	for _, op := range versionOperators {
+		if strings.HasPrefix(s, op) {
+			v := strings.TrimSpace(strings.TrimPrefix(s, op))
+			if v == "" {
+				return versionConstraint{}, fmt.Errorf("version constraint %q has no version", s)
+			}
+			return versionConstraint{op: op, version: v}, nil
+		}
+	}
+	if s == "" {
+		return versionConstraint{}, fmt.Errorf("empty version constraint")
+	}
+	return versionConstraint{op: "=", version: s}, nil
+}
+
+func (c versionConstraint) satisfied(version string, compare VersionCompare) bool {
+	n := compare(version, c.version)
+	switch c.op {
+	case ">=":
+		return n >= 0
+	case "<=":
+		return n <= 0
+	case ">":
+		return n > 0
+	case "<":
+		return n < 0
+	case "!=":
+		return n != 0
+	default:
+		return n == 0
+	}
+}
+
+func (matcher *BePackageVersionMatcher) Match(actual interface{}) (success bool, err error) {
+	var constraints []versionConstraint
+	for _, s := range matcher.Constraints {
+		c, err := parseVersionConstraint(s)
+		if err != nil {
+			return false, err
+		}
+		constraints = append(constraints, c)
+	}
+	versions, ok := actual.([]string)
+	if !ok {
+		return false, fmt.Errorf("Expected a list of versions.  Got:\n%s", format.Object(actual, 1))
+	}
+	if len(versions) == 0 {
+		return false, nil
+	}
+	for _, v := range versions {
+		for _, c := range constraints {
+			if !c.satisfied(v, matcher.compare) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+func (matcher *BePackageVersionMatcher) FailureMessage(actual interface{}) (message string) {
+	return format.Message(actual, fmt.Sprintf("to be %s", strings.Join(matcher.Constraints, ", ")))
+}
+
+func (matcher *BePackageVersionMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	return format.Message(actual, fmt.Sprintf("not to be %s", strings.Join(matcher.Constraints, ", ")))
+}
+
+// MarshalJSON is the constraints, as they're shown as the expected value
+func (matcher *BePackageVersionMatcher) MarshalJSON() ([]byte, error) {
+	return json.Marshal(matcher.Constraints)
+}
+
+// splitVersion splits [epoch:]version[-revision], the epoch is 0 when it's
+// missing or isn't a number
+func splitVersion(s string) (epoch int, version, revision string) {
+	if i := strings.Index(s, ":"); i > 0 {
+		if e, err := strconv.Atoi(s[:i]); err == nil {
+			epoch, s = e, s[i+1:]
+		}
+	}
+	if i := strings.LastIndex(s, "-"); i >= 0 {
+		return epoch, s[:i], s[i+1:]
+	}
+	return epoch, s, ""
+}
+
+// CompareDebVersions compares versions as dpkg does, a missing revision is
+// older than any revision
+func CompareDebVersions(a, b string) int {
+	ea, va, ra := splitVersion(a)
+	eb, vb, rb := splitVersion(b)
+	if ea != eb {
+		return ea - eb
+	}
+	if n := debVerRevCmp(va, vb); n != 0 {
+		return n
+	}
+	return debVerRevCmp(ra, rb)
+}
+
+// debOrder is the weight of a character outside of digits, '~' sorts before
+// everything, even the end of the version, and letters before other characters
+func debOrder(s string, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+	c := s[i]
+	switch {
+	case c >= '0' && c <= '9':
+		return 0
+	case isAlpha(c):
+		return int(c)
+	case c == '~':
+		return -1
+	default:
+		return int(c) + 256
+	}
+}
+
+func isDigit(s string, i int) bool {
+	return i < len(s) && s[i] >= '0' && s[i] <= '9'
+}
+
+func debVerRevCmp(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for (i < len(a) && !isDigit(a, i)) || (j < len(b) && !isDigit(b, j)) {
+			if ac, bc := debOrder(a, i), debOrder(b, j); ac != bc {
+				return ac - bc
+			}
+			i++
+			j++
+		}
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+		firstDiff := 0
+		for isDigit(a, i) && isDigit(b, j) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+		if isDigit(a, i) {
+			return 1
+		}
+		if isDigit(b, j) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return firstDiff
+		}
+	}
+	return 0
+}
+
+// CompareRPMVersions compares versions as rpm does, the release is only
+// compared when both versions have one so "2.4.0" matches any release of it
+func CompareRPMVersions(a, b string) int {
+	ea, va, ra := splitVersion(a)
+	eb, vb, rb := splitVersion(b)
+	if ea != eb {
+		return ea - eb
+	}
+	if n := rpmVerCmp(va, vb); n != 0 || ra == "" || rb == "" {
+		return n
+	}
+	return rpmVerCmp(ra, rb)
+}
+
+func isAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isAlnum(c byte) bool {
+	return (c >= '0' && c <= '9') || isAlpha(c)
+}
+
+// rpmVerCmp is rpmvercmp, comparing the numeric and alphabetic segments of
+// the versions in turn, '~' sorts before the end of a version and '^' after
+func rpmVerCmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for i < len(a) && !isAlnum(a[i]) && a[i] != '~' && a[i] != '^' {
+			i++
+		}
+		for j < len(b) && !isAlnum(b[j]) && b[j] != '~' && b[j] != '^' {
+			j++
+		}
+		at, bt := i < len(a), j < len(b)
+		if (at && a[i] == '~') || (bt && b[j] == '~') {
+			if !at || a[i] != '~' {
+				return 1
+			}
+			if !bt || b[j] != '~' {
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+		if (at && a[i] == '^') || (bt && b[j] == '^') {
+			if !at {
+				return -1
+			}
+			if !bt {
+				return 1
+			}
+			if a[i] != '^' {
+				return 1
+			}
+			if b[j] != '^' {
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+		if !at || !bt {
+			break
+		}
+
+		si, sj := i, j
+		numeric := isDigit(a, i)
+		if numeric {
+			for isDigit(a, i) {
+				i++
+			}
+			for isDigit(b, j) {
+				j++
+			}
+		} else {
+			for i < len(a) && isAlpha(a[i]) {
+				i++
+			}
+			for j < len(b) && isAlpha(b[j]) {
+				j++
+			}
+		}
+		if sj == j {
+			// A numeric segment is newer than an alphabetic one
+			if numeric {
+				return 1
+			}
+			return -1
+		}
+		sa, sb := a[si:i], b[sj:j]
+		if numeric {
+			sa, sb = strings.TrimLeft(sa, "0"), strings.TrimLeft(sb, "0")
+			if len(sa) != len(sb) {
+				return len(sa) - len(sb)
+			}
+		}
+		if n := strings.Compare(sa, sb); n != 0 {
+			return n
+		}
+	}
+	if i >= len(a) && j >= len(b) {
+		return 0
+	}
+	if i < len(a) {
+		return 1
+	}
+	return -1
+}
+
+// apkPreReleases are the suffixes of apk versions older than the release
+var apkPreReleases = strings.NewReplacer("_alpha", "~alpha", "_beta", "~beta", "_pre", "~pre", "_rc", "~rc")
+
+// CompareApkVersions compares apk versions such as 1.2.3_rc1-r0 as dpkg does,
+// with the alpha, beta, pre and rc suffixes older than the release
+func CompareApkVersions(a, b string) int {
+	return CompareDebVersions(apkPreReleases.Replace(a), apkPreReleases.Replace(b))
+}
+
+// Ranks of the parts of library versions, at the same position a pre-release
+// is older than the end of a version, which is older than a post-release or a
+// number
+const (
+	rankDev = iota - 1
+	rankPreRelease
+	rankEnd
+	rankPostRelease
+	rankNumber
+	// rankIdentifier is an alphanumeric semver pre-release identifier, newer
+	// than a numeric one
+	rankIdentifier
+)
+
+type versionToken struct {
+	rank  int
+	value string
+}
+
+// libraryWords normalizes the spellings of pre-releases, so alpha, beta and
+// rc are in order
+var libraryWords = map[string]string{"alpha": "a", "beta": "b", "c": "rc", "pre": "rc", "preview": "rc"}
+
+func libraryWordRank(w string) int {
+	switch w {
+	case "dev":
+		return rankDev
+	case "post", "p", "pl", "patch", "r", "rev":
+		return rankPostRelease
+	}
+	return rankPreRelease
+}
+
+// libraryVersionTokens splits a version into its numbers and words, the
+// separators between them don't matter
+func libraryVersionTokens(s string, wordRank func(string) int) []versionToken {
+	var tokens []versionToken
+	for i := 0; i < len(s); {
+		j := i
+		switch {
+		case isDigit(s, i):
+			for isDigit(s, j) {
+				j++
+			}
+			tokens = append(tokens, versionToken{rankNumber, strings.TrimLeft(s[i:j], "0")})
+		case isAlpha(s[i]):
+			for j < len(s) && isAlpha(s[j]) {
+				j++
+			}
+			w := strings.ToLower(s[i:j])
+			if n, ok := libraryWords[w]; ok {
+				w = n
+			}
+			tokens = append(tokens, versionToken{wordRank(w), w})
+		default:
+			j++
+		}
+		i = j
+	}
+	return tokens
+}
+
+// trimZeros drops the zeros at the end of a release, or before the words
+// following it, 1.0 is the same version as 1.0.0
+func trimZeros(tokens []versionToken) []versionToken {
+	var trimmed []versionToken
+	for i, t := range tokens {
+		if t.rank == rankNumber && t.value == "" && i > 0 {
+			j := i
+			for j < len(tokens) && tokens[j].rank == rankNumber && tokens[j].value == "" {
+				j++
+			}
+			if j == len(tokens) || tokens[j].rank != rankNumber {
+				continue
+			}
+		}
+		trimmed = append(trimmed, t)
+	}
+	return trimmed
+}
+
+func compareVersionTokens(a, b []versionToken) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		ta, tb := versionToken{rank: rankEnd}, versionToken{rank: rankEnd}
+		if i < len(a) {
+			ta = a[i]
+		}
+		if i < len(b) {
+			tb = b[i]
+		}
+		if ta.rank != tb.rank {
+			return ta.rank - tb.rank
+		}
+		if ta.rank == rankNumber && len(ta.value) != len(tb.value) {
+			return len(ta.value) - len(tb.value)
+		}
+		if n := strings.Compare(ta.value, tb.value); n != 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+// splitLibraryVersion splits [epoch!]version[+local], a leading v is dropped
+func splitLibraryVersion(s string) (epoch int, version string) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.Index(s, "!"); i > 0 {
+		if e, err := strconv.Atoi(s[:i]); err == nil {
+			epoch, s = e, s[i+1:]
+		}
+	}
+	if i := strings.Index(s, "+"); i >= 0 {
+		s = s[:i]
+	}
+	return epoch, s
+}
+
+// CompareLibraryVersions compares pip and gem versions, dev, alpha, beta and
+// rc releases are older than the release and post-releases newer, so
+// 1.0.dev1 < 1.0a1 < 1.0rc1 < 1.0 = 1.0.0 < 1.0.post1 < 1.0.1
+func CompareLibraryVersions(a, b string) int {
+	ea, va := splitLibraryVersion(a)
+	eb, vb := splitLibraryVersion(b)
+	if ea != eb {
+		return ea - eb
+	}
+	return compareVersionTokens(trimZeros(libraryVersionTokens(va, libraryWordRank)), trimZeros(libraryVersionTokens(vb, libraryWordRank)))
+}
+
+// semVerTokens are the tokens of a semver version, the pre-release after the
+// first '-' is older than the release
+func semVerTokens(s string) []versionToken {
+	_, s = splitLibraryVersion(s)
+	i := strings.Index(s, "-")
+	if i < 0 {
+		return libraryVersionTokens(s, libraryWordRank)
+	}
+	tokens := libraryVersionTokens(s[:i], libraryWordRank)
+	tokens = append(tokens, versionToken{rank: rankPreRelease})
+	return append(tokens, libraryVersionTokens(s[i+1:], func(string) int { return rankIdentifier })...)
+}
+
+// CompareSemVerVersions compares npm versions as semver does, 1.0.0-rc.1 is
+// older than 1.0.0 and build metadata is ignored
+func CompareSemVerVersions(a, b string) int {
+	return compareVersionTokens(semVerTokens(a), semVerTokens(b))
+}
@@ -0,0 +1,98 @@
+package matchers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		compare VersionCompare
+		a, b    string
+		want    int
+	}{
+		{CompareDebVersions, "2.4.10", "2.4.9", 1},
+		{CompareDebVersions, "1.0~rc1", "1.0", -1},
+		{CompareDebVersions, "1:1.0", "2.0", 1},
+		{CompareDebVersions, "2.4.0-1ubuntu1", "2.4.0-1", 1},
+		{CompareDebVersions, "2.4.0", "2.4.0-1", -1},
+		{CompareDebVersions, "1.0a", "1.0+", -1},
+		{CompareDebVersions, "1.002", "1.2", 0},
+		{CompareRPMVersions, "2.4.10", "2.4.9", 1},
+		{CompareRPMVersions, "1.0~rc1", "1.0", -1},
+		{CompareRPMVersions, "1.0^git1", "1.0", 1},
+		{CompareRPMVersions, "1.0a", "1.0", 1},
+		{CompareRPMVersions, "1.0", "1.0.1", -1},
+		{CompareRPMVersions, "1.a", "1.1", -1},
+		{CompareRPMVersions, "2.4.0-3.el8", "2.4.0", 0},
+		{CompareRPMVersions, "2.4.0-3.el8", "2.4.0-10.el8", -1},
+		{CompareRPMVersions, "1:1.0", "2.0", 1},
+		{CompareApkVersions, "1.2.3_rc1-r0", "1.2.3-r0", -1},
+		{CompareApkVersions, "1.2.3-r1", "1.2.3-r0", 1},
+		{CompareApkVersions, "1.2.3_p1-r0", "1.2.3-r0", 1},
+		{CompareLibraryVersions, "2.10.0", "2.9.1", 1},
+		{CompareLibraryVersions, "1.0.dev1", "1.0a1", -1},
+		{CompareLibraryVersions, "1.0a1", "1.0b1", -1},
+		{CompareLibraryVersions, "1.0beta2", "1.0rc1", -1},
+		{CompareLibraryVersions, "1.0rc1", "1.0", -1},
+		{CompareLibraryVersions, "1.0", "1.0.post1", -1},
+		{CompareLibraryVersions, "1.0.post1", "1.0.1", -1},
+		{CompareLibraryVersions, "1.0", "1.0.0", 0},
+		{CompareLibraryVersions, "1.0.0rc1", "1.0rc1", 0},
+		{CompareLibraryVersions, "1.0.0.1", "1.0", 1},
+		{CompareLibraryVersions, "1!1.0", "2.0", 1},
+		{CompareLibraryVersions, "1.0+local", "1.0", 0},
+		{CompareLibraryVersions, "5.0.0.pre", "5.0.0", -1},
+		{CompareSemVerVersions, "1.10.0", "1.9.0", 1},
+		{CompareSemVerVersions, "1.0.0-rc.1", "1.0.0", -1},
+		{CompareSemVerVersions, "1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{CompareSemVerVersions, "1.0.0-1", "1.0.0-alpha", -1},
+		{CompareSemVerVersions, "1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{CompareSemVerVersions, "v1.0.0+build.5", "1.0.0", 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, sign(tt.compare(tt.a, tt.b)), "%s vs %s", tt.a, tt.b)
+		assert.Equal(t, -tt.want, sign(tt.compare(tt.b, tt.a)), "%s vs %s", tt.b, tt.a)
+	}
+}
+
+func TestBePackageVersionMatcher_Match(t *testing.T) {
+	tests := []struct {
+		name        string
+		constraints []string
+		actual      interface{}
+		want        bool
+		wantErr     bool
+	}{
+		{name: "in_range", constraints: []string{">= 2.4.0", "!= 2.4.3"}, actual: []string{"2.4.10"}, want: true},
+		{name: "excluded", constraints: []string{">= 2.4.0", "!= 2.4.3"}, actual: []string{"2.4.3"}, want: false},
+		{name: "too_old", constraints: []string{">=2.4.0"}, actual: []string{"2.3.9"}, want: false},
+		{name: "bare_version", constraints: []string{"2.4.0"}, actual: []string{"2.4.0"}, want: true},
+		{name: "every_version", constraints: []string{"< 6"}, actual: []string{"5.4.0", "6.1.0"}, want: false},
+		{name: "not_installed", constraints: []string{">= 1"}, actual: []string{}, want: false},
+		{name: "no_version", constraints: []string{">="}, actual: []string{"1"}, wantErr: true},
+		{name: "not_a_list", constraints: []string{">= 1"}, actual: "1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BePackageVersion(tt.constraints, CompareDebVersions).Match(tt.actual)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
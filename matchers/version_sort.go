@@ -0,0 +1,97 @@
+package matchers
+
+import (
+	"fmt"
+
+	"github.com/onsi/gomega/format"
+	"github.com/onsi/gomega/types"
+)
+
+// CompareNaturalVersions compares versions that aren't semver, such as those
+// of kernels and OpenSSL, by their numeric and alphabetic segments in turn as
+// rpm does, so 1.10 is newer than 1.9 and 1.1.1k newer than 1.1.1j. A leading
+// v, as in v1.2, is ignored.
+func CompareNaturalVersions(a, b string) int {
+	return rpmVerCmp(trimVersionPrefix(a), trimVersionPrefix(b))
+}
+
+func trimVersionPrefix(s string) string {
+	if len(s) > 1 && (s[0] == 'v' || s[0] == 'V') && isDigit(s, 1) {
+		return s[1:]
+	}
+	return s
+}
+
+// BeVersion succeeds when actual, a version or a list of versions, is newer
+// than version with the ">" comparator or older with "<", compared with
+// CompareNaturalVersions
+func BeVersion(comparator string, version interface{}) types.GomegaMatcher {
+	return &BeVersionMatcher{
+		Comparator: comparator,
+		Version:    version,
+	}
+}
+
+type BeVersionMatcher struct {
+	Comparator string
+	Version    interface{}
+}
+
+func (matcher *BeVersionMatcher) Match(actual interface{}) (success bool, err error) {
+	version, ok := matcher.Version.(string)
+	if !ok || version == "" {
+		return false, fmt.Errorf("Expected a version string such as \"1.10\".  Got:\n%s", format.Object(matcher.Version, 1))
+	}
+	if matcher.Comparator != ">" && matcher.Comparator != "<" {
+		return false, fmt.Errorf("Unknown comparator: %s", matcher.Comparator)
+	}
+	versions, ok := toVersionStrings(actual)
+	if !ok {
+		return false, fmt.Errorf("Expected a single or list of version(s).  Got:\n%s", format.Object(actual, 1))
+	}
+	if len(versions) == 0 {
+		return false, nil
+	}
+	for _, v := range versions {
+		n := CompareNaturalVersions(v, version)
+		if matcher.Comparator == ">" && n <= 0 || matcher.Comparator == "<" && n >= 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (matcher *BeVersionMatcher) FailureMessage(actual interface{}) (message string) {
+	return format.Message(actual, fmt.Sprintf("to be a version %s", matcher.relation()), matcher.Version)
+}
+
+func (matcher *BeVersionMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	return format.Message(actual, fmt.Sprintf("not to be a version %s", matcher.relation()), matcher.Version)
+}
+
+func (matcher *BeVersionMatcher) relation() string {
+	if matcher.Comparator == "<" {
+		return "older than"
+	}
+	return "newer than"
+}
+
+func toVersionStrings(in interface{}) ([]string, bool) {
+	switch v := in.(type) {
+	case string:
+		return []string{v}, true
+	case []string:
+		return v, true
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, s)
+		}
+		return out, true
+	}
+	return nil, false
+}
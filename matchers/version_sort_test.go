@@ -0,0 +1,60 @@
+package matchers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareNaturalVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.10", "1.9", 1},
+		{"1.9", "1.10", -1},
+		{"1.1.1k", "1.1.1j", 1},
+		{"1.0.2", "1.0.2a", -1},
+		{"3.0.2", "1.1.1w", 1},
+		{"5.15.0-91-generic", "5.4.0-150-generic", 1},
+		{"5.15.0-91-generic", "5.15.0-101-generic", -1},
+		{"v1.10", "1.9", 1},
+		{"v2", "1.0", 1},
+		{"1.02", "1.2", 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, sign(CompareNaturalVersions(tt.a, tt.b)), "%s vs %s", tt.a, tt.b)
+	}
+}
+
+func TestBeVersionMatcher_Match(t *testing.T) {
+	tests := []struct {
+		name       string
+		comparator string
+		version    interface{}
+		actual     interface{}
+		want       bool
+		wantErr    bool
+	}{
+		{name: "gt", comparator: ">", version: "1.9", actual: "1.10", want: true},
+		{name: "gt_equal", comparator: ">", version: "1.10", actual: "1.10", want: false},
+		{name: "lt", comparator: "<", version: "1.10", actual: "1.9", want: true},
+		{name: "lt_older", comparator: "<", version: "1.9", actual: "1.10", want: false},
+		{name: "list", comparator: ">", version: "1.1.1j", actual: []string{"1.1.1k", "3.0.2"}, want: true},
+		{name: "list_one_older", comparator: ">", version: "1.1.1j", actual: []interface{}{"1.1.1k", "1.1.1i"}, want: false},
+		{name: "empty_list", comparator: ">", version: "1.0", actual: []string{}, want: false},
+		{name: "number", comparator: ">", version: 1.1, actual: "1.10", wantErr: true},
+		{name: "invalid_actual", comparator: ">", version: "1.0", actual: 2, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BeVersion(tt.comparator, tt.version).Match(tt.actual)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
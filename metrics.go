@@ -0,0 +1,145 @@
+package goss
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aelsabbahy/goss/outputs"
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+)
+
+// MetricsSink writes the results of each run as Prometheus metrics to the
+// file of the textfile collector and pushes them to a Pushgateway, whatever
+// the output format
+type MetricsSink struct {
+	textfile    string
+	pushgateway string
+	err         error
+}
+
+// NewMetricsSink is the sink of the metrics, nil when there's nowhere to
+// write them
+func NewMetricsSink(textfile, pushgateway string) *MetricsSink {
+	if textfile == "" && pushgateway == "" {
+		return nil
+	}
+	return &MetricsSink{textfile: textfile, pushgateway: pushgateway}
+}
+
+// Results passes the results through and writes their metrics once the run
+// finished, before the output sees the end of the results. outConfig is that
+// of the run, for its timings and ID. Err is the error writing them.
+func (m *MetricsSink) Results(in <-chan []resource.TestResult, startTime time.Time, outConfig util.OutputConfig) <-chan []resource.TestResult {
+	if m == nil {
+		return in
+	}
+
+	out := make(chan []resource.TestResult)
+	go func() {
+		defer close(out)
+		var results [][]resource.TestResult
+		for resultGroup := range in {
+			results = append(results, resultGroup)
+			out <- resultGroup
+		}
+		var metrics bytes.Buffer
+		outputs.Prometheus{}.Output(&metrics, resultsChan(results), startTime, runOutputConfig(outConfig))
+		m.err = m.write(metrics.Bytes())
+	}()
+
+	return out
+}
+
+// Err is the error writing the metrics of the last run
+func (m *MetricsSink) Err() error {
+	if m == nil {
+		return nil
+	}
+	return m.err
+}
+
+// runOutputConfig is the part of outConfig about the run itself, the timings
+// and the ID, for the sinks that write their own format
+func runOutputConfig(outConfig util.OutputConfig) util.OutputConfig {
+	return util.OutputConfig{Timing: outConfig.Timing, RunID: outConfig.RunID}
+}
+
+// resultsChan is a closed channel of results
+func resultsChan(results [][]resource.TestResult) <-chan []resource.TestResult {
+	out := make(chan []resource.TestResult, len(results))
+	for _, resultGroup := range results {
+		out <- resultGroup
+	}
+	close(out)
+	return out
+}
+
+func (m *MetricsSink) write(metrics []byte) error {
+	if m.textfile != "" {
+		if err := writeTextfile(m.textfile, metrics); err != nil {
+			return fmt.Errorf("prometheus textfile %s: %v", m.textfile, err)
+		}
+	}
+	if m.pushgateway != "" {
+		if err := push(m.pushgateway, metrics); err != nil {
+			return fmt.Errorf("pushgateway %s: %v", m.pushgateway, err)
+		}
+	}
+	return nil
+}
+
+// writeTextfile replaces file by renaming a temporary file over it, so the
+// textfile collector never reads a partial file
+func writeTextfile(file string, metrics []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(file), "."+filepath.Base(file)+".")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(metrics); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// push replaces the metrics of the goss job of this host on the Pushgateway,
+// so those of tests removed from the gossfile don't linger
+func push(gateway string, metrics []byte) error {
+	u := strings.TrimSuffix(gateway, "/") + "/metrics/job/goss"
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		u += "/instance/" + url.PathEscape(hostname)
+	}
+	req, err := http.NewRequest("PUT", u, bytes.NewReader(metrics))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(&io.LimitedReader{R: resp.Body, N: 512})
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package goss
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateMetrics(t *testing.T) {
+	var method, path string
+	var pushed []byte
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		pushed, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer gateway.Close()
+
+	spec := filepath.Join("testdata", "failing.goss.yaml")
+	textfile := filepath.Join(t.TempDir(), "goss.prom")
+	config, err := util.NewConfig(util.WithSpecFile(spec), util.WithOutputFormat("json"), util.WithResultWriter(&bytes.Buffer{}),
+		util.WithMetricsTextfile(textfile), util.WithPushgateway(gateway.URL+"/"))
+	require.NoError(t, err)
+	code, err := Validate(config, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 1, code)
+
+	metrics, err := ioutil.ReadFile(textfile)
+	require.NoError(t, err)
+	assert.Contains(t, string(metrics), `goss_tests{status="failed"} 2`)
+	assert.Contains(t, string(metrics), "goss_run_exit_code 1\n")
+	files, _ := filepath.Glob(filepath.Join(filepath.Dir(textfile), "*"))
+	assert.Len(t, files, 1, "temporary files are removed")
+
+	assert.Equal(t, "PUT", method)
+	assert.True(t, strings.HasPrefix(path, "/metrics/job/goss/instance/"), path)
+	assert.Equal(t, string(metrics), string(pushed))
+
+	gateway.Config.Handler = http.NotFoundHandler()
+	_, err = Validate(config, time.Now())
+	assert.Error(t, err)
+}
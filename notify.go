@@ -0,0 +1,223 @@
+package goss
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aelsabbahy/goss/outputs"
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+)
+
+// notifyRetryInterval is the time before the first retry of a notification,
+// it doubles after every retry
+var notifyRetryInterval = time.Second
+
+const notifyAttempts = 3
+
+// Notification is what a notifier posts about a failed run, the data of the
+// payload templates
+type Notification struct {
+	Hostname    string                       `json:"hostname"`
+	Gossfile    string                       `json:"gossfile"`
+	RunID       string                       `json:"run-id,omitempty"`
+	Summary     outputs.StructureTestSummary `json:"summary"`
+	SummaryLine string                       `json:"summary-line"`
+	Failures    []NotificationFailure        `json:"failures"`
+}
+
+// NotificationFailure is a test that failed, errored or timed out
+type NotificationFailure struct {
+	ResourceType string `json:"resource-type"`
+	ResourceId   string `json:"resource-id"`
+	Title        string `json:"title,omitempty"`
+	Property     string `json:"property"`
+	Result       string `json:"result"`
+	Message      string `json:"message"`
+}
+
+// notifyPresets are the payloads of the webhooks of chat services, json is
+// the Notification itself
+var notifyPresets = map[string]string{
+	"json":  `{{json .}}`,
+	"slack": `{"text": {{json (printf "*goss failed on %s*\n%s%s" .Hostname .SummaryLine (failures .))}}}`,
+	"teams": `{"@type": "MessageCard", "@context": "https://schema.org/extensions", "themeColor": "d7000b",` +
+		` "summary": {{json (printf "goss failed on %s" .Hostname)}}, "title": {{json (printf "goss failed on %s" .Hostname)}},` +
+		` "text": {{json (printf "%s%s" .SummaryLine (failures .))}}}`,
+}
+
+var notifyFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	// failures is a line of each failure, as a markdown list
+	"failures": func(n Notification) string {
+		var s string
+		for _, f := range n.Failures {
+			s += fmt.Sprintf("\n- %s: %s: %s: %s", f.ResourceType, f.ResourceId, f.Property, strings.Replace(f.Message, "\n", " ", -1))
+		}
+		return s
+	},
+}
+
+// Notifier posts a summary of the runs that fail to a webhook
+type Notifier struct {
+	url      string
+	gossfile string
+	payload  *template.Template
+	err      error
+}
+
+// NewNotifier is the notifier posting to webhook, nil when it's empty. The
+// payload is the template of templateFile, or otherwise of the preset,
+// json when it's empty.
+func NewNotifier(webhook, preset, templateFile, gossfile string) (*Notifier, error) {
+	if webhook == "" {
+		return nil, nil
+	}
+	text, ok := notifyPresets[preset]
+	if preset == "" {
+		text, ok = notifyPresets["json"], true
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown notify preset %q, must be json, slack or teams", preset)
+	}
+	if templateFile != "" {
+		data, err := ioutil.ReadFile(templateFile)
+		if err != nil {
+			return nil, fmt.Errorf("notify template: %v", err)
+		}
+		text = string(data)
+	}
+	payload, err := template.New("payload").Funcs(notifyFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("notify template: %v", err)
+	}
+	return &Notifier{url: webhook, gossfile: gossfile, payload: payload}, nil
+}
+
+// Results passes the results through and posts the notification once the
+// run finished when it failed, before the output sees the end of the results.
+// outConfig is that of the run, for its ID. Err is the error posting it.
+func (n *Notifier) Results(in <-chan []resource.TestResult, startTime time.Time, outConfig util.OutputConfig) <-chan []resource.TestResult {
+	if n == nil {
+		return in
+	}
+
+	out := make(chan []resource.TestResult)
+	go func() {
+		defer close(out)
+		notification := Notification{Gossfile: n.gossfile, RunID: outConfig.RunID, Failures: []NotificationFailure{}}
+		notification.Hostname, _ = os.Hostname()
+		for resultGroup := range in {
+			for _, r := range resultGroup {
+				notification.add(r)
+			}
+			out <- resultGroup
+		}
+		s := &notification.Summary
+		s.TotalDuration = time.Since(startTime)
+		notification.SummaryLine = s.String()
+		if s.Failed+s.Errored+s.TimedOut > 0 {
+			n.err = n.post(notification)
+		}
+	}()
+
+	return out
+}
+
+func (n *Notification) add(r resource.TestResult) {
+	n.Summary.TestCount++
+	var result string
+	switch {
+	case r.Warning():
+		n.Summary.Warnings++
+		return
+	case r.Result == resource.FAIL:
+		n.Summary.Failed++
+		result = "failed"
+	case r.Result == resource.ERROR:
+		n.Summary.Errored++
+		result = "error"
+	case r.Result == resource.TIMEOUT:
+		n.Summary.TimedOut++
+		result = "timed out"
+	default:
+		return
+	}
+	n.Failures = append(n.Failures, NotificationFailure{
+		ResourceType: r.ResourceType,
+		ResourceId:   r.ResourceId,
+		Title:        r.Title,
+		Property:     r.Property,
+		Result:       result,
+		Message:      failureMessage(r),
+	})
+}
+
+// failureMessage is the uncolored reason the test didn't pass
+func failureMessage(r resource.TestResult) string {
+	switch {
+	case r.Err != nil:
+		return "Error: " + r.Err.Error()
+	case r.Result == resource.TIMEOUT:
+		return "timed out"
+	case r.Human != "":
+		return r.Human
+	}
+	return fmt.Sprintf("doesn't match, expect: %s found: %s", r.Expected, r.Found)
+}
+
+// Err is the error posting the notification of the last run
+func (n *Notifier) Err() error {
+	if n == nil || n.err == nil {
+		return nil
+	}
+	return fmt.Errorf("notify: %v", n.err)
+}
+
+// post retries the server errors and connection errors of the webhook
+func (n *Notifier) post(notification Notification) error {
+	var payload bytes.Buffer
+	if err := n.payload.Execute(&payload, notification); err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	interval := notifyRetryInterval
+	var err error
+	for i := 0; i < notifyAttempts; i++ {
+		if i > 0 {
+			time.Sleep(interval)
+			interval *= 2
+		}
+		var resp *http.Response
+		resp, err = client.Post(n.url, "application/json", bytes.NewReader(payload.Bytes()))
+		if uerr, ok := err.(*url.Error); ok {
+			// Webhook URLs are secrets
+			err = uerr.Err
+		}
+		if err != nil {
+			continue
+		}
+		body, _ := ioutil.ReadAll(&io.LimitedReader{R: resp.Body, N: 512})
+		resp.Body.Close()
+		if resp.StatusCode/100 == 2 {
+			return nil
+		}
+		err = fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+package goss
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateNotify(t *testing.T) {
+	defer func(d time.Duration) { notifyRetryInterval = d }(notifyRetryInterval)
+	notifyRetryInterval = time.Millisecond
+
+	var posts int
+	var payload []byte
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts++
+		payload, _ = ioutil.ReadAll(r.Body)
+		if posts == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer webhook.Close()
+
+	validate := func(spec, preset, template string) (int, error) {
+		config, err := util.NewConfig(util.WithSpecFile(filepath.Join("testdata", spec)), util.WithOutputFormat("json"),
+			util.WithResultWriter(&bytes.Buffer{}), util.WithNotifyURL(webhook.URL, preset, template))
+		require.NoError(t, err)
+		return Validate(config, time.Now())
+	}
+
+	_, err := validate("passing.goss.yaml", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, 0, posts, "passing runs aren't notified")
+
+	code, err := validate("failing.goss.yaml", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, code)
+	assert.Equal(t, 2, posts, "server errors are retried")
+	var notification Notification
+	require.NoError(t, json.Unmarshal(payload, &notification))
+	assert.Equal(t, 2, notification.Summary.Failed)
+	require.Len(t, notification.Failures, 2)
+	assert.Equal(t, "exit-status", notification.Failures[0].Property)
+
+	_, err = validate("failing.goss.yaml", "slack", "")
+	require.NoError(t, err)
+	var slack struct{ Text string }
+	require.NoError(t, json.Unmarshal(payload, &slack))
+	assert.True(t, strings.HasPrefix(slack.Text, "*goss failed on "), slack.Text)
+	assert.Contains(t, slack.Text, "\n- Command: hello world: exit-status: ")
+
+	template := filepath.Join(t.TempDir(), "payload.tmpl")
+	require.NoError(t, ioutil.WriteFile(template, []byte(`{"failed": {{.Summary.Failed}}}`), 0644))
+	_, err = validate("failing.goss.yaml", "", template)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"failed": 2}`, string(payload))
+
+	_, err = validate("failing.goss.yaml", "discord", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,137 @@
+package goss
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/aelsabbahy/goss/resource"
+)
+
+// resourceTypeNames maps the gossfile keys of resource types, such as
+// kernel-param, to their type names, such as KernelParam
+func resourceTypeNames() map[string]string {
+	names := make(map[string]string)
+	t := reflect.TypeOf(GossConfig{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if key := strings.Split(f.Tag.Get("yaml"), ",")[0]; key != "" && key != "-" {
+			names[key] = f.Type.Elem().Elem().Name()
+		}
+	}
+	return names
+}
+
+func orderKey(typeName, id string) string {
+	return typeName + ": " + id
+}
+
+// yamlSpecOrder is the order of the resources of a decoded YAML gossfile
+func yamlSpecOrder(spec yaml.MapSlice) []string {
+	names := resourceTypeNames()
+	var order []string
+	for _, typ := range spec {
+		key, _ := typ.Key.(string)
+		name, ok := names[key]
+		if !ok {
+			continue
+		}
+		resources, _ := typ.Value.(yaml.MapSlice)
+		for _, r := range resources {
+			if id, ok := r.Key.(string); ok {
+				order = append(order, orderKey(name, id))
+			}
+		}
+	}
+	return order
+}
+
+// jsonSpecOrder is the order of the resources of a JSON gossfile, read token
+// by token so the resources aren't decoded again
+func jsonSpecOrder(data []byte) []string {
+	names := resourceTypeNames()
+	var order []string
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return order
+		}
+		key, _ := t.(string)
+		if t, err = dec.Token(); err != nil || t != json.Delim('{') {
+			return order
+		}
+		for dec.More() {
+			t, err := dec.Token()
+			if err != nil {
+				return order
+			}
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return order
+			}
+			if name, ok := names[key]; ok {
+				order = append(order, orderKey(name, t.(string)))
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return order
+		}
+	}
+	return order
+}
+
+// resultKey is the orderKey of the resource of a group of results
+func resultKey(group []resource.TestResult) string {
+	if len(group) == 0 {
+		return ""
+	}
+	return orderKey(group[0].ResourceType, group[0].ResourceId)
+}
+
+// orderResults sends the groups of results of in in the order of resources,
+// as soon as the ones before them were sent. Groups of other resources are
+// sent as they come.
+func orderResults(in <-chan []resource.TestResult, resources []resource.Resource) <-chan []resource.TestResult {
+	index := make(map[string]int)
+	for i, r := range resources {
+		typeName := strings.Split(reflect.TypeOf(r).String(), ".")[1]
+		index[orderKey(typeName, r.(resource.ResourceRead).ID())] = i
+	}
+	out := make(chan []resource.TestResult)
+	go func() {
+		defer close(out)
+		pending := make(map[int][]resource.TestResult)
+		next := 0
+		for group := range in {
+			i, ok := index[resultKey(group)]
+			if !ok {
+				out <- group
+				continue
+			}
+			pending[i] = group
+			for {
+				g, ok := pending[next]
+				if !ok {
+					break
+				}
+				out <- g
+				delete(pending, next)
+				next++
+			}
+		}
+		// Resources that sent nothing don't hold up the others
+		for ; next < len(resources); next++ {
+			if group, ok := pending[next]; ok {
+				out <- group
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,88 @@
+package goss
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aelsabbahy/goss/resource"
+)
+
+func TestSpecOrder(t *testing.T) {
+	want := []string{"Service: sshd", "File: /etc/passwd", "File: /etc/group", "KernelParam: net.ipv4.ip_forward"}
+
+	yamlSpec := `service:
+  sshd:
+    running: true
+file:
+  /etc/passwd:
+    exists: true
+  /etc/group:
+    exists: true
+kernel-param:
+  net.ipv4.ip_forward:
+    value: "1"
+`
+	gossConfig, err := ReadJSONData([]byte(yamlSpec), true)
+	checkErr(t, err, "reading yaml spec")
+	if got := gossConfig.order; !reflect.DeepEqual(got, want) {
+		t.Errorf("yaml: got %v, want %v", got, want)
+	}
+
+	jsonSpec := `{"service": {"sshd": {"running": true}},
+  "file": {"/etc/passwd": {"exists": true}, "/etc/group": {"exists": true, "contains": ["root"]}},
+  "kernel-param": {"net.ipv4.ip_forward": {"value": "1"}}}`
+	gossConfig, err = ReadJSONData([]byte(jsonSpec), true)
+	checkErr(t, err, "reading json spec")
+	if got := gossConfig.order; !reflect.DeepEqual(got, want) {
+		t.Errorf("json: got %v, want %v", got, want)
+	}
+}
+
+func TestResourcesOrder(t *testing.T) {
+	spec := `file:
+  /tmp/b:
+    exists: true
+  /tmp/a:
+    exists: true
+service:
+  sshd:
+    running: true
+`
+	gossConfig, err := ReadJSONData([]byte(spec), true)
+	checkErr(t, err, "reading spec")
+
+	var got []string
+	for _, r := range gossConfig.Resources() {
+		got = append(got, r.(resource.ResourceRead).ID())
+	}
+	want := []string{"/tmp/b", "/tmp/a", "sshd"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestOrderResults(t *testing.T) {
+	resources := []resource.Resource{
+		&resource.File{Path: "/tmp/b"},
+		&resource.File{Path: "/tmp/a"},
+		&resource.Service{Service: "sshd"},
+	}
+	group := func(typ, id string) []resource.TestResult {
+		return []resource.TestResult{{ResourceType: typ, ResourceId: id}}
+	}
+
+	in := make(chan []resource.TestResult, 3)
+	in <- group("Service", "sshd")
+	in <- group("File", "/tmp/a")
+	in <- group("File", "/tmp/b")
+	close(in)
+
+	var got []string
+	for g := range orderResults(in, resources) {
+		got = append(got, resultKey(g))
+	}
+	want := []string{"File: /tmp/b", "File: /tmp/a", "Service: sshd"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
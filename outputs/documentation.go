@@ -16,28 +16,44 @@ func (r Documentation) Output(w io.Writer, results <-chan []resource.TestResult,
 
 	testCount := 0
 	var failedOrSkipped [][]resource.TestResult
-	var skipped, failed int
+	var skipped, failed, errored, timedOut, warnings int
+	groups := newSummaryGroups()
 	for resultGroup := range results {
 		failedOrSkippedGroup := []resource.TestResult{}
 		first := resultGroup[0]
 		header := header(first)
-		if header != "" {
+		if header != "" && listed(first, outConfig) {
 			fmt.Fprint(w, header)
 		}
 		for _, testResult := range resultGroup {
+			testCount++
+			groups.add(testResult)
+			if !listed(testResult, outConfig) {
+				if testResult.Result == resource.SKIP {
+					skipped++
+				}
+				continue
+			}
+			fmt.Fprintln(w, humanizeResult(testResult))
+			if testResult.Warning() {
+				failedOrSkippedGroup = append(failedOrSkippedGroup, testResult)
+				warnings++
+				continue
+			}
 			switch testResult.Result {
-			case resource.SUCCESS:
-				fmt.Fprintln(w, humanizeResult(testResult))
 			case resource.SKIP:
-				fmt.Fprintln(w, humanizeResult(testResult))
 				failedOrSkippedGroup = append(failedOrSkippedGroup, testResult)
 				skipped++
 			case resource.FAIL:
-				fmt.Fprintln(w, humanizeResult(testResult))
 				failedOrSkippedGroup = append(failedOrSkippedGroup, testResult)
 				failed++
+			case resource.ERROR:
+				failedOrSkippedGroup = append(failedOrSkippedGroup, testResult)
+				errored++
+			case resource.TIMEOUT:
+				failedOrSkippedGroup = append(failedOrSkippedGroup, testResult)
+				timedOut++
 			}
-			testCount++
 		}
 		if len(failedOrSkippedGroup) > 0 {
 			failedOrSkipped = append(failedOrSkipped, failedOrSkippedGroup)
@@ -46,14 +62,12 @@ func (r Documentation) Output(w io.Writer, results <-chan []resource.TestResult,
 
 	fmt.Fprint(w, "\n\n")
 	fmt.Fprint(w, failedOrSkippedSummary(failedOrSkipped))
+	fmt.Fprint(w, groups)
 
-	fmt.Fprint(w, summary(startTime, testCount, failed, skipped))
-	if failed > 0 {
-		return 1
-	}
-	return 0
+	fmt.Fprint(w, summary(startTime, outConfig, testCount, failed, skipped, errored, timedOut, warnings))
+	return resultExitCode(failed, errored, timedOut)
 }
 
 func init() {
-	RegisterOutputer("documentation", &Documentation{}, []string{})
+	RegisterOutputer("documentation", &Documentation{}, listFormatOptions)
 }
@@ -0,0 +1,165 @@
+package outputs
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+	"github.com/fatih/color"
+)
+
+// GitHub writes the workflow commands of GitHub Actions, failures are error
+// annotations and warnings warning annotations on the gossfile, and appends a
+// table of the results to the job summary when GITHUB_STEP_SUMMARY is set
+type GitHub struct{}
+
+type githubCounts struct {
+	passed, failed, errored, timedOut, warnings, skipped int
+}
+
+func (r GitHub) Output(w io.Writer, results <-chan []resource.TestResult,
+	startTime time.Time, outConfig util.OutputConfig) (exitCode int) {
+
+	color.NoColor = true
+	var gossfile []string
+	if outConfig.Gossfile != "" && outConfig.Gossfile != "-" {
+		if data, err := ioutil.ReadFile(outConfig.Gossfile); err == nil {
+			gossfile = strings.Split(string(data), "\n")
+		}
+	}
+
+	var total githubCounts
+	var types []string
+	counts := make(map[string]*githubCounts)
+	var failures []resource.TestResult
+	testCount := 0
+	for resultGroup := range results {
+		for _, testResult := range resultGroup {
+			c, ok := counts[testResult.ResourceType]
+			if !ok {
+				c = &githubCounts{}
+				counts[testResult.ResourceType] = c
+				types = append(types, testResult.ResourceType)
+			}
+			level := "error"
+			switch {
+			case testResult.Warning():
+				c.warnings++
+				total.warnings++
+				level = "warning"
+			case testResult.Result == resource.FAIL:
+				c.failed++
+				total.failed++
+			case testResult.Result == resource.ERROR:
+				c.errored++
+				total.errored++
+			case testResult.Result == resource.TIMEOUT:
+				c.timedOut++
+				total.timedOut++
+			case testResult.Result == resource.SKIP:
+				c.skipped++
+				total.skipped++
+				level = ""
+			default:
+				c.passed++
+				total.passed++
+				level = ""
+			}
+			testCount++
+			if level == "" {
+				continue
+			}
+			failures = append(failures, testResult)
+
+			props := []string{}
+			if len(gossfile) > 0 {
+				props = append(props, "file="+githubEscapeProperty(outConfig.Gossfile))
+				if line := gossfileLine(gossfile, testResult.ResourceId); line > 0 {
+					props = append(props, fmt.Sprintf("line=%d", line))
+				}
+			}
+			title := fmt.Sprintf("%s: %s: %s", testResult.ResourceType, testResult.ResourceId, testResult.Property)
+			props = append(props, "title="+githubEscapeProperty(title))
+			fmt.Fprintf(w, "::%s %s::%s\n", level, strings.Join(props, ","), githubEscape(humanizeResult(testResult)))
+		}
+	}
+
+	duration := time.Since(startTime)
+	summary := summaryLine(testCount, total.failed, total.errored, total.timedOut, total.warnings, duration)
+	if phases := phasesLine(outConfig.Timing, duration); phases != "" {
+		summary += ", Phases: " + phases
+	}
+	if outConfig.RunID != "" {
+		summary += ", Run ID: " + outConfig.RunID
+	}
+	fmt.Fprintln(w, summary)
+
+	if file := os.Getenv("GITHUB_STEP_SUMMARY"); file != "" {
+		if f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644); err == nil {
+			writeJobSummary(f, summary, types, counts, failures)
+			f.Close()
+		}
+	}
+
+	return resultExitCode(total.failed, total.errored, total.timedOut)
+}
+
+func init() {
+	RegisterOutputer("github", &GitHub{}, []string{})
+}
+
+// writeJobSummary writes the markdown of the job summary, the counts of each
+// resource type and the tests that didn't pass
+func writeJobSummary(w io.Writer, summary string, types []string, counts map[string]*githubCounts, failures []resource.TestResult) {
+	fmt.Fprintf(w, "### goss\n\n%s\n\n", summary)
+	fmt.Fprintln(w, "| Resource type | Passed | Failed | Errors | Timed out | Warnings | Skipped |")
+	fmt.Fprintln(w, "| --- | --- | --- | --- | --- | --- | --- |")
+	for _, t := range types {
+		c := counts[t]
+		fmt.Fprintf(w, "| %s | %d | %d | %d | %d | %d | %d |\n", t, c.passed, c.failed, c.errored, c.timedOut, c.warnings, c.skipped)
+	}
+	if len(failures) == 0 {
+		fmt.Fprintln(w)
+		return
+	}
+	fmt.Fprintln(w, "\n| Resource | Property | Result |")
+	fmt.Fprintln(w, "| --- | --- | --- |")
+	for _, r := range failures {
+		fmt.Fprintf(w, "| %s: %s | %s | %s |\n", r.ResourceType, markdownCell(r.ResourceId), r.Property, markdownCell(humanizeResult(r)))
+	}
+	fmt.Fprintln(w)
+}
+
+// gossfileLine is the line the resource id is a key on, 0 when it isn't in
+// the gossfile, as when it comes from a gossfile it includes
+func gossfileLine(lines []string, id string) int {
+	for i, l := range lines {
+		l = strings.TrimSpace(l)
+		for _, key := range []string{id, `"` + id + `"`, "'" + id + "'"} {
+			if strings.HasPrefix(l, key+":") {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+// githubEscape escapes the message of a workflow command
+func githubEscape(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// githubEscapeProperty escapes the properties of a workflow command, which
+// are separated by commas
+func githubEscapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", "\\|", "\r", "", "\n", "<br>").Replace(s)
+}
@@ -0,0 +1,123 @@
+package outputs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aelsabbahy/goss/resource"
+)
+
+// summaryGroupKinds are what the tests of a run are summarized by: the tags
+// and severity of their resource, and the team that owns it, its meta owner
+var summaryGroupKinds = []string{"tag", "severity", "owner"}
+
+// summaryGroupLines are the formats of the line of each kind of groups
+var summaryGroupLines = map[string]string{
+	"tag":      "By tag: %s\n",
+	"severity": "By severity: %s\n",
+	"owner":    "By owner: %s\n",
+}
+
+// groupCount is the summary of the tests of a group
+type groupCount struct {
+	Tests    int `json:"test-count"`
+	Failed   int `json:"failed-count"`
+	Warnings int `json:"warning-count"`
+}
+
+// summaryGroups counts the tests of a run by the groups of summaryGroupKinds,
+// so the report of a large suite shows whose tests are failing. Severities
+// are only counted once a resource doesn't fail the run.
+type summaryGroups struct {
+	counts     map[string]map[string]*groupCount
+	severities bool
+}
+
+func newSummaryGroups() *summaryGroups {
+	return &summaryGroups{counts: make(map[string]map[string]*groupCount)}
+}
+
+func (g *summaryGroups) add(r resource.TestResult) {
+	owner := ""
+	if o, ok := r.Meta["owner"]; ok && o != nil {
+		owner = fmt.Sprint(o)
+	}
+	if r.Severity != "" && r.Severity != resource.SeverityFail {
+		g.severities = true
+	}
+	for _, tag := range r.Tags {
+		g.count("tag", tag, r)
+	}
+	g.count("severity", r.Severity, r)
+	g.count("owner", owner, r)
+}
+
+func (g *summaryGroups) count(kind, name string, r resource.TestResult) {
+	if name == "" {
+		return
+	}
+	if g.counts[kind] == nil {
+		g.counts[kind] = make(map[string]*groupCount)
+	}
+	c := g.counts[kind][name]
+	if c == nil {
+		c = &groupCount{}
+		g.counts[kind][name] = c
+	}
+	c.Tests++
+	switch {
+	case r.Warning():
+		c.Warnings++
+	case r.Result == resource.FAIL, r.Result == resource.ERROR, r.Result == resource.TIMEOUT:
+		c.Failed++
+	}
+}
+
+// groups are the counts of every kind the run had groups of, by kind and
+// name, nil when it had none
+func (g *summaryGroups) groups() map[string]map[string]groupCount {
+	var out map[string]map[string]groupCount
+	for _, kind := range summaryGroupKinds {
+		if len(g.counts[kind]) == 0 || kind == "severity" && !g.severities {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]map[string]groupCount)
+		}
+		out[kind] = make(map[string]groupCount, len(g.counts[kind]))
+		for name, c := range g.counts[kind] {
+			out[kind][name] = *c
+		}
+	}
+	return out
+}
+
+// String is a line of each kind of groups, such as
+// "By owner: dba 2/5 failed; web 0/7 failed"
+func (g *summaryGroups) String() string {
+	groups := g.groups()
+	var s string
+	for _, kind := range summaryGroupKinds {
+		counts, ok := groups[kind]
+		if !ok {
+			continue
+		}
+		names := make([]string, 0, len(counts))
+		for name := range counts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		entries := make([]string, 0, len(names))
+		for _, name := range names {
+			c := counts[name]
+			entry := fmt.Sprintf(tr("%s %d/%d failed"), name, c.Failed, c.Tests)
+			if c.Warnings > 0 {
+				entry += fmt.Sprintf(tr(", %d warned"), c.Warnings)
+			}
+			entries = append(entries, entry)
+		}
+		s += fmt.Sprintf(tr(summaryGroupLines[kind]), strings.Join(entries, "; "))
+	}
+	return s
+}
@@ -0,0 +1,198 @@
+package outputs
+
+import (
+	"html/template"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+	"github.com/fatih/color"
+)
+
+// HTML is a single file report, its styles are inline so the file can be
+// attached to CI artifacts and opened anywhere
+type HTML struct{}
+
+// htmlReport leaves out the hostname, the results are those of --redact
+type htmlReport struct {
+	Timestamp string
+	Summary   StructureTestSummary
+	Phases    []util.Phase
+	RunID     string
+	Passed    int
+	Skipped   int
+	Types     []*htmlType
+}
+
+type htmlType struct {
+	Name      string
+	Failed    int
+	Resources []*htmlResource
+}
+
+type htmlResource struct {
+	ID       string
+	Title    string
+	Status   string
+	Duration time.Duration
+	Tests    []htmlTest
+}
+
+type htmlTest struct {
+	Property string
+	Status   string
+	Duration time.Duration
+	Details  string
+}
+
+// htmlRank orders the statuses of the tests of a resource, the resource
+// takes that of its worst test
+var htmlRank = map[string]int{"passed": 0, "skipped": 1, "warning": 2, "timed out": 3, "error": 4, "failed": 5}
+
+func htmlStatus(r resource.TestResult) string {
+	switch {
+	case r.Warning():
+		return "warning"
+	case r.Result == resource.FAIL:
+		return "failed"
+	case r.Result == resource.ERROR:
+		return "error"
+	case r.Result == resource.TIMEOUT:
+		return "timed out"
+	case r.Result == resource.SKIP:
+		return "skipped"
+	}
+	return "passed"
+}
+
+func (r HTML) Output(w io.Writer, results <-chan []resource.TestResult,
+	startTime time.Time, outConfig util.OutputConfig) (exitCode int) {
+
+	color.NoColor = true
+	report := htmlReport{Timestamp: time.Now().Format(time.RFC3339)}
+	types := make(map[string]*htmlType)
+	resources := make(map[string]*htmlResource)
+
+	for resultGroup := range results {
+		for _, testResult := range resultGroup {
+			status := htmlStatus(testResult)
+			switch status {
+			case "warning":
+				report.Summary.Warnings++
+			case "failed":
+				report.Summary.Failed++
+			case "error":
+				report.Summary.Errored++
+			case "timed out":
+				report.Summary.TimedOut++
+			case "skipped":
+				report.Skipped++
+			default:
+				report.Passed++
+			}
+			report.Summary.TestCount++
+			if !listed(testResult, outConfig) {
+				continue
+			}
+
+			t, ok := types[testResult.ResourceType]
+			if !ok {
+				t = &htmlType{Name: testResult.ResourceType}
+				types[t.Name] = t
+				report.Types = append(report.Types, t)
+			}
+			key := testResult.ResourceType + "\x00" + testResult.ResourceId
+			res, ok := resources[key]
+			if !ok {
+				res = &htmlResource{ID: testResult.ResourceId, Title: testResult.Title, Status: "passed"}
+				resources[key] = res
+				t.Resources = append(t.Resources, res)
+			}
+			if htmlRank[status] > htmlRank[res.Status] {
+				res.Status = status
+			}
+			res.Duration += testResult.Duration
+
+			test := htmlTest{Property: testResult.Property, Status: status, Duration: testResult.Duration}
+			if status != "passed" && status != "skipped" {
+				test.Details = humanizeResult(testResult)
+			}
+			res.Tests = append(res.Tests, test)
+		}
+	}
+	report.Summary.TotalDuration = time.Since(startTime)
+	report.Phases = outConfig.Timing.Phases(report.Summary.TotalDuration)
+	report.RunID = outConfig.RunID
+	for _, t := range report.Types {
+		for _, res := range t.Resources {
+			if htmlRank[res.Status] >= htmlRank["timed out"] {
+				t.Failed++
+			}
+		}
+	}
+	sort.Slice(report.Types, func(i, j int) bool { return report.Types[i].Name < report.Types[j].Name })
+
+	htmlTemplate.Execute(w, report)
+
+	return resultExitCode(report.Summary.Failed, report.Summary.Errored, report.Summary.TimedOut)
+}
+
+func init() {
+	RegisterOutputer("html", &HTML{}, listFormatOptions)
+}
+
+var htmlTemplate = template.Must(template.New("html").Funcs(template.FuncMap{
+	"duration":  func(d time.Duration) string { return d.Round(time.Millisecond).String() },
+	"precisely": func(d time.Duration) string { return d.Round(time.Microsecond).String() },
+	"class": func(status string) string {
+		if status == "timed out" {
+			return "timedout"
+		}
+		return status
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>goss report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.5em; margin-bottom: 0; }
+.meta { color: #666; margin-top: .3em; }
+.counts span { display: inline-block; padding: .4em .8em; margin: .5em .5em .5em 0; border-radius: 4px; background: #eee; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1.5em; }
+th, td { text-align: left; padding: .3em .6em; border-bottom: 1px solid #ddd; vertical-align: top; }
+details summary { cursor: pointer; }
+pre { white-space: pre-wrap; margin: .3em 0; }
+.passed { color: #1a7f37; } .failed, .error, .timedout { color: #cf222e; } .warning, .skipped { color: #9a6700; }
+.counts .failed, .counts .error, .counts .timedout { background: #ffebe9; } .counts .passed { background: #dafbe1; } .counts .warning, .counts .skipped { background: #fff8c5; }
+</style>
+</head>
+<body>
+<h1>goss report</h1>
+<p class="meta">{{.Timestamp}}, {{precisely .Summary.TotalDuration}}{{if .Phases}} ({{range $i, $p := .Phases}}{{if $i}}, {{end}}{{$p.Name}} {{precisely $p.Duration}}{{end}}){{end}}{{if .RunID}}, run {{.RunID}}{{end}}</p>
+<p class="counts"><span>Count: {{.Summary.TestCount}}</span><span class="passed">Passed: {{.Passed}}</span><span class="failed">Failed: {{.Summary.Failed}}</span>
+{{- if .Summary.Errored}}<span class="error">Errors: {{.Summary.Errored}}</span>{{end}}
+{{- if .Summary.TimedOut}}<span class="timedout">Timed out: {{.Summary.TimedOut}}</span>{{end}}
+{{- if .Summary.Warnings}}<span class="warning">Warnings: {{.Summary.Warnings}}</span>{{end}}
+{{- if .Skipped}}<span class="skipped">Skipped: {{.Skipped}}</span>{{end}}</p>
+{{range .Types}}
+<h2>{{.Name}} ({{len .Resources}}{{if .Failed}}, {{.Failed}} failing{{end}})</h2>
+<table>
+<tr><th>Resource</th><th>Status</th><th>Duration</th></tr>
+{{range .Resources}}<tr>
+<td><details{{if ne .Status "passed"}}{{if ne .Status "skipped"}} open{{end}}{{end}}><summary>{{.ID}}{{if .Title}} - {{.Title}}{{end}}</summary>
+<table>
+{{range .Tests}}<tr><td>{{.Property}}</td><td class="{{class .Status}}">{{.Status}}</td><td>{{duration .Duration}}</td></tr>
+{{if .Details}}<tr><td colspan="3"><pre>{{.Details}}</pre></td></tr>
+{{end}}{{end}}</table>
+</details></td>
+<td class="{{class .Status}}">{{.Status}}</td>
+<td>{{duration .Duration}}</td>
+</tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,201 @@
+package outputs
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// defaultLanguage is the language the message formats in this package are written in
+const defaultLanguage = "en"
+
+// catalogs holds the translated message formats for every supported language,
+// keyed by the English format string used at the call site
+var catalogs = map[string]map[string]string{
+	"de": {
+		"%s: %s: Error: %s":                               "%s: %s: Fehler: %s",
+		"%s: %s: %s: matches expectation: %s":             "%s: %s: %s: entspricht der Erwartung: %s",
+		"%s: %s: %s: skipped":                             "%s: %s: %s: übersprungen",
+		"%s: %s: %s: skipped, %s":                         "%s: %s: %s: übersprungen, %s",
+		"%s: %s: timed out":                               "%s: %s: Zeitüberschreitung",
+		"%s: %s: %s: all expectations found: [%s]":        "%s: %s: %s: alle Erwartungen gefunden: [%s]",
+		"%s: %s: %s: doesn't match, expect: %s found: %s": "%s: %s: %s: stimmt nicht überein, erwartet: %s gefunden: %s",
+		"%s: %s: %s: expectations not found [%s]":         "%s: %s: %s: Erwartungen nicht gefunden [%s]",
+		"%s: %s: %s: patterns not found: [%s]":            "%s: %s: %s: Muster nicht gefunden: [%s]",
+		"Total Duration: %.6fs\n":                         "Gesamtdauer: %.6fs\n",
+		"Phases: %s\n":                                    "Phasen: %s\n",
+		"Run ID: %s\n":                                    "Lauf-ID: %s\n",
+		"Count: %d, Failed: %d, Skipped: %d":              "Anzahl: %d, Fehlgeschlagen: %d, Übersprungen: %d",
+		", Errors: %d":                                    ", Fehler: %d",
+		", Timed out: %d":                                 ", Zeitüberschreitung: %d",
+		", Warnings: %d":                                  ", Warnungen: %d",
+		"[quarantined] ":                                  "[Quarantäne] ",
+		"[flaky] ":                                        "[instabil] ",
+		"[maintenance: %s] ":                              "[Wartung: %s] ",
+		"[baseline] ":                                     "[Bestandsfehler] ",
+		"[warn] ":                                         "[Warnung] ",
+		"[info] ":                                         "[Info] ",
+		"Failures/Skipped:\n\n":                           "Fehlgeschlagen/Übersprungen:\n\n",
+		"Failed resources:\n\n":                           "Fehlgeschlagene Ressourcen:\n\n",
+		"%s: %s (weight %g)\n":                            "%s: %s (Gewicht %g)\n",
+		"Score: %.2f%% (%g of %g), threshold: %g%%: PASS": "Punktzahl: %.2f%% (%g von %g), Schwelle: %g%%: BESTANDEN",
+		"Score: %.2f%% (%g of %g), threshold: %g%%: FAIL": "Punktzahl: %.2f%% (%g von %g), Schwelle: %g%%: NICHT BESTANDEN",
+		"Title: %s\n":                                     "Titel: %s\n",
+		"Meta:\n":                                         "Meta:\n",
+		"By tag: %s\n":                                    "Nach Tag: %s\n",
+		"By severity: %s\n":                               "Nach Schweregrad: %s\n",
+		"By owner: %s\n":                                  "Nach Verantwortlichem: %s\n",
+		"%s %d/%d failed":                                 "%s %d/%d fehlgeschlagen",
+		", %d warned":                                     ", %d gewarnt",
+	},
+	"es": {
+		"%s: %s: Error: %s":                               "%s: %s: Error: %s",
+		"%s: %s: %s: matches expectation: %s":             "%s: %s: %s: coincide con lo esperado: %s",
+		"%s: %s: %s: skipped":                             "%s: %s: %s: omitido",
+		"%s: %s: %s: skipped, %s":                         "%s: %s: %s: omitido, %s",
+		"%s: %s: timed out":                               "%s: %s: tiempo agotado",
+		"%s: %s: %s: all expectations found: [%s]":        "%s: %s: %s: todas las expectativas encontradas: [%s]",
+		"%s: %s: %s: doesn't match, expect: %s found: %s": "%s: %s: %s: no coincide, se esperaba: %s se encontró: %s",
+		"%s: %s: %s: expectations not found [%s]":         "%s: %s: %s: expectativas no encontradas [%s]",
+		"%s: %s: %s: patterns not found: [%s]":            "%s: %s: %s: patrones no encontrados: [%s]",
+		"Total Duration: %.6fs\n":                         "Duración total: %.6fs\n",
+		"Phases: %s\n":                                    "Fases: %s\n",
+		"Run ID: %s\n":                                    "ID de ejecución: %s\n",
+		"Count: %d, Failed: %d, Skipped: %d":              "Total: %d, Fallidos: %d, Omitidos: %d",
+		", Errors: %d":                                    ", Errores: %d",
+		", Timed out: %d":                                 ", Tiempo agotado: %d",
+		", Warnings: %d":                                  ", Advertencias: %d",
+		"[quarantined] ":                                  "[en cuarentena] ",
+		"[flaky] ":                                        "[inestable] ",
+		"[maintenance: %s] ":                              "[mantenimiento: %s] ",
+		"[baseline] ":                                     "[ya fallaba] ",
+		"[warn] ":                                         "[advertencia] ",
+		"[info] ":                                         "[informativo] ",
+		"Failures/Skipped:\n\n":                           "Fallidos/Omitidos:\n\n",
+		"Failed resources:\n\n":                           "Recursos fallidos:\n\n",
+		"%s: %s (weight %g)\n":                            "%s: %s (peso %g)\n",
+		"Score: %.2f%% (%g of %g), threshold: %g%%: PASS": "Puntuación: %.2f%% (%g de %g), umbral: %g%%: APROBADO",
+		"Score: %.2f%% (%g of %g), threshold: %g%%: FAIL": "Puntuación: %.2f%% (%g de %g), umbral: %g%%: SUSPENDIDO",
+		"Title: %s\n":                                     "Título: %s\n",
+		"Meta:\n":                                         "Meta:\n",
+		"By tag: %s\n":                                    "Por etiqueta: %s\n",
+		"By severity: %s\n":                               "Por severidad: %s\n",
+		"By owner: %s\n":                                  "Por responsable: %s\n",
+		"%s %d/%d failed":                                 "%s %d/%d fallidas",
+		", %d warned":                                     ", %d con advertencia",
+	},
+	"fr": {
+		"%s: %s: Error: %s":                               "%s: %s: Erreur: %s",
+		"%s: %s: %s: matches expectation: %s":             "%s: %s: %s: correspond à l'attendu: %s",
+		"%s: %s: %s: skipped":                             "%s: %s: %s: ignoré",
+		"%s: %s: %s: skipped, %s":                         "%s: %s: %s: ignoré, %s",
+		"%s: %s: timed out":                               "%s: %s: délai dépassé",
+		"%s: %s: %s: all expectations found: [%s]":        "%s: %s: %s: toutes les attentes trouvées: [%s]",
+		"%s: %s: %s: doesn't match, expect: %s found: %s": "%s: %s: %s: ne correspond pas, attendu: %s trouvé: %s",
+		"%s: %s: %s: expectations not found [%s]":         "%s: %s: %s: attentes non trouvées [%s]",
+		"%s: %s: %s: patterns not found: [%s]":            "%s: %s: %s: motifs non trouvés: [%s]",
+		"Total Duration: %.6fs\n":                         "Durée totale: %.6fs\n",
+		"Phases: %s\n":                                    "Phases : %s\n",
+		"Run ID: %s\n":                                    "ID d'exécution : %s\n",
+		"Count: %d, Failed: %d, Skipped: %d":              "Total: %d, Échecs: %d, Ignorés: %d",
+		", Errors: %d":                                    ", Erreurs: %d",
+		", Timed out: %d":                                 ", Délai dépassé: %d",
+		", Warnings: %d":                                  ", Avertissements: %d",
+		"[quarantined] ":                                  "[en quarantaine] ",
+		"[flaky] ":                                        "[instable] ",
+		"[maintenance: %s] ":                              "[maintenance : %s] ",
+		"[baseline] ":                                     "[déjà en échec] ",
+		"[warn] ":                                         "[avertissement] ",
+		"[info] ":                                         "[information] ",
+		"Failures/Skipped:\n\n":                           "Échecs/Ignorés:\n\n",
+		"Failed resources:\n\n":                           "Ressources en échec:\n\n",
+		"%s: %s (weight %g)\n":                            "%s: %s (poids %g)\n",
+		"Score: %.2f%% (%g of %g), threshold: %g%%: PASS": "Score: %.2f%% (%g sur %g), seuil: %g%%: RÉUSSI",
+		"Score: %.2f%% (%g of %g), threshold: %g%%: FAIL": "Score: %.2f%% (%g sur %g), seuil: %g%%: ÉCHEC",
+		"Title: %s\n":                                     "Titre: %s\n",
+		"Meta:\n":                                         "Méta:\n",
+		"By tag: %s\n":                                    "Par étiquette : %s\n",
+		"By severity: %s\n":                               "Par sévérité : %s\n",
+		"By owner: %s\n":                                  "Par responsable : %s\n",
+		"%s %d/%d failed":                                 "%s %d/%d en échec",
+		", %d warned":                                     ", %d en avertissement",
+	},
+}
+
+var (
+	catalogMu      sync.RWMutex
+	currentCatalog map[string]string
+)
+
+// Languages returns a sorted list of the languages human readable output can be written in
+func Languages() []string {
+	list := []string{defaultLanguage}
+	for lang := range catalogs {
+		list = append(list, lang)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// SetLanguage selects the message catalog used for human readable output. An
+// empty lang falls back to the system locale, then to English
+func SetLanguage(lang string) error {
+	if lang == "" {
+		lang = DetectLanguage()
+	}
+	lang = normalizeLanguage(lang)
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	if lang == defaultLanguage {
+		currentCatalog = nil
+		return nil
+	}
+	catalog, ok := catalogs[lang]
+	if !ok {
+		return fmt.Errorf("unsupported language: %s, valid options: %s", lang, strings.Join(Languages(), ", "))
+	}
+	currentCatalog = catalog
+	return nil
+}
+
+// DetectLanguage determines the language from the system locale environment
+// variables, unsupported or unset locales result in English
+func DetectLanguage() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		v := os.Getenv(env)
+		if v == "" {
+			continue
+		}
+		lang := normalizeLanguage(v)
+		if _, ok := catalogs[lang]; ok {
+			return lang
+		}
+		return defaultLanguage
+	}
+	return defaultLanguage
+}
+
+// normalizeLanguage turns a locale such as de_DE.UTF-8 into a language code
+func normalizeLanguage(locale string) string {
+	lang := strings.ToLower(locale)
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	if lang == "c" || lang == "posix" {
+		return defaultLanguage
+	}
+	return lang
+}
+
+// tr returns the translation of the English message format in the current language
+func tr(format string) string {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	if t, ok := currentCatalog[format]; ok {
+		return t
+	}
+	return format
+}
@@ -22,16 +22,32 @@ func (r Json) Output(w io.Writer, results <-chan []resource.TestResult,
 	color.NoColor = true
 	testCount := 0
 	failed := 0
+	errored := 0
+	timedOut := 0
+	warnings := 0
 	var resultsOut []map[string]interface{}
+	groups := newSummaryGroups()
 	for resultGroup := range results {
 		for _, testResult := range resultGroup {
-			if !testResult.Successful {
+			groups.add(testResult)
+			switch {
+			case testResult.Warning():
+				warnings++
+			case testResult.Result == resource.FAIL:
 				failed++
+			case testResult.Result == resource.ERROR:
+				errored++
+			case testResult.Result == resource.TIMEOUT:
+				timedOut++
 			}
+			// Errors are marshalled as their error code and message
+			testResult.Err = util.CodeError(testResult.Err)
 			m := struct2map(testResult)
 			m["summary-line"] = humanizeResult(testResult)
 			m["duration"] = int64(m["duration"].(float64))
-			resultsOut = append(resultsOut, m)
+			if listed(testResult, outConfig) {
+				resultsOut = append(resultsOut, m)
+			}
 			testCount++
 		}
 	}
@@ -41,7 +57,19 @@ func (r Json) Output(w io.Writer, results <-chan []resource.TestResult,
 	summary["test-count"] = testCount
 	summary["failed-count"] = failed
 	summary["total-duration"] = duration
-	summary["summary-line"] = fmt.Sprintf("Count: %d, Failed: %d, Duration: %.3fs", testCount, failed, duration.Seconds())
+	if phases := phaseDurations(outConfig.Timing, duration); phases != nil {
+		summary["phase-durations"] = phases
+	}
+	if outConfig.RunID != "" {
+		summary["run-id"] = outConfig.RunID
+	}
+	summary["error-count"] = errored
+	summary["timed-out-count"] = timedOut
+	summary["warning-count"] = warnings
+	if g := groups.groups(); g != nil {
+		summary["groups"] = g
+	}
+	summary["summary-line"] = summaryLine(testCount, failed, errored, timedOut, warnings, duration)
 
 	out := make(map[string]interface{})
 	out["results"] = resultsOut
@@ -56,15 +84,11 @@ func (r Json) Output(w io.Writer, results <-chan []resource.TestResult,
 
 	fmt.Fprintln(w, string(j))
 
-	if failed > 0 {
-		return 1
-	}
-
-	return 0
+	return resultExitCode(failed, errored, timedOut)
 }
 
 func init() {
-	RegisterOutputer("json", &Json{}, []string{"pretty"})
+	RegisterOutputer("json", &Json{}, append([]string{"pretty"}, listFormatOptions...))
 }
 
 func struct2map(i interface{}) map[string]interface{} {
@@ -19,16 +19,30 @@ func (r JsonOneline) Output(w io.Writer, results <-chan []resource.TestResult,
 	color.NoColor = true
 	testCount := 0
 	failed := 0
+	errored := 0
+	timedOut := 0
+	warnings := 0
 	var resultsOut []map[string]interface{}
 	for resultGroup := range results {
 		for _, testResult := range resultGroup {
-			if !testResult.Successful {
+			switch {
+			case testResult.Warning():
+				warnings++
+			case testResult.Result == resource.FAIL:
 				failed++
+			case testResult.Result == resource.ERROR:
+				errored++
+			case testResult.Result == resource.TIMEOUT:
+				timedOut++
 			}
+			// Errors are marshalled as their error code and message
+			testResult.Err = util.CodeError(testResult.Err)
 			m := struct2map(testResult)
 			m["summary-line"] = humanizeResult(testResult)
 			m["duration"] = int64(m["duration"].(float64))
-			resultsOut = append(resultsOut, m)
+			if listed(testResult, outConfig) {
+				resultsOut = append(resultsOut, m)
+			}
 			testCount++
 		}
 	}
@@ -38,7 +52,16 @@ func (r JsonOneline) Output(w io.Writer, results <-chan []resource.TestResult,
 	summary["test-count"] = testCount
 	summary["failed-count"] = failed
 	summary["total-duration"] = duration
-	summary["summary-line"] = fmt.Sprintf("Count: %d, Failed: %d, Duration: %.3fs", testCount, failed, duration.Seconds())
+	if phases := phaseDurations(outConfig.Timing, duration); phases != nil {
+		summary["phase-durations"] = phases
+	}
+	if outConfig.RunID != "" {
+		summary["run-id"] = outConfig.RunID
+	}
+	summary["error-count"] = errored
+	summary["timed-out-count"] = timedOut
+	summary["warning-count"] = warnings
+	summary["summary-line"] = summaryLine(testCount, failed, errored, timedOut, warnings, duration)
 
 	out := make(map[string]interface{})
 	out["results"] = resultsOut
@@ -47,13 +70,9 @@ func (r JsonOneline) Output(w io.Writer, results <-chan []resource.TestResult,
 	j, _ := json.Marshal(out)
 	fmt.Fprintln(w, string(j))
 
-	if failed > 0 {
-		return 1
-	}
-
-	return 0
+	return resultExitCode(failed, errored, timedOut)
 }
 
 func init() {
-	RegisterOutputer("json_oneline", &JsonOneline{}, []string{})
+	RegisterOutputer("json_oneline", &JsonOneline{}, listFormatOptions)
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"os"
 	"strconv"
 	"time"
 
@@ -19,7 +20,7 @@ func (r JUnit) Output(w io.Writer, results <-chan []resource.TestResult,
 	startTime time.Time, outConfig util.OutputConfig) (exitCode int) {
 
 	color.NoColor = true
-	var testCount, failed, skipped int
+	var testCount, failed, skipped, errored, timedOut int
 
 	// ISO8601 timeformat
 	timestamp := time.Now().Format(time.RFC3339)
@@ -29,14 +30,29 @@ func (r JUnit) Output(w io.Writer, results <-chan []resource.TestResult,
 
 	for resultGroup := range results {
 		for _, testResult := range resultGroup {
+			if !listed(testResult, outConfig) {
+				if testResult.Result == resource.SKIP {
+					skipped++
+				}
+				testCount++
+				continue
+			}
 			m := struct2map(testResult)
 			duration := strconv.FormatFloat(m["duration"].(float64)/1000/1000/1000, 'f', 3, 64)
 			summary[testCount] = "<testcase name=\"" +
 				testResult.ResourceType + " " +
 				escapeString(testResult.ResourceId) + " " +
 				testResult.Property + "\" " +
+				"classname=\"" + testResult.ResourceType + "\" " +
 				"time=\"" + duration + "\">\n"
-			if testResult.Result == resource.FAIL {
+			if testResult.Warning() {
+				// JUnit has no warnings, skipped keeps it from failing the build
+				summary[testCount] += "<skipped message=\"" + warningLabel(testResult) + "\"/>" +
+					"<system-err>" +
+					escapeString(humanizeResult2(testResult)) +
+					"</system-err>\n</testcase>\n"
+				skipped++
+			} else if testResult.Result == resource.FAIL {
 				summary[testCount] += "<system-err>" +
 					escapeString(humanizeResult2(testResult)) +
 					"</system-err>\n"
@@ -45,6 +61,16 @@ func (r JUnit) Output(w io.Writer, results <-chan []resource.TestResult,
 					"</failure>\n</testcase>\n"
 
 				failed++
+			} else if testResult.Result == resource.ERROR {
+				summary[testCount] += "<error message=\"" + escapeString(testResult.Err.Error()) + "\">" +
+					escapeString(humanizeResult2(testResult)) +
+					"</error>\n</testcase>\n"
+				errored++
+			} else if testResult.Result == resource.TIMEOUT {
+				summary[testCount] += "<error message=\"timed out\">" +
+					escapeString(humanizeResult2(testResult)) +
+					"</error>\n</testcase>\n"
+				timedOut++
 			} else {
 				if testResult.Result == resource.SKIP {
 					summary[testCount] += "<skipped/>"
@@ -60,9 +86,10 @@ func (r JUnit) Output(w io.Writer, results <-chan []resource.TestResult,
 
 	duration := time.Since(startTime)
 	fmt.Fprintln(w, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>")
-	fmt.Fprintf(w, "<testsuite name=\"goss\" errors=\"0\" tests=\"%d\" "+
-		"failures=\"%d\" skipped=\"%d\" time=\"%.3f\" timestamp=\"%s\">\n",
-		testCount, failed, skipped, duration.Seconds(), timestamp)
+	fmt.Fprintf(w, "<testsuite name=\"goss\" errors=\"%d\" tests=\"%d\" "+
+		"failures=\"%d\" skipped=\"%d\" time=\"%.6f\" timestamp=\"%s\">\n",
+		errored+timedOut, testCount, failed, skipped, duration.Seconds(), timestamp)
+	fmt.Fprint(w, junitProperties(outConfig, duration))
 
 	for i := 0; i < testCount; i++ {
 		fmt.Fprintf(w, "%s", summary[i])
@@ -70,15 +97,42 @@ func (r JUnit) Output(w io.Writer, results <-chan []resource.TestResult,
 
 	fmt.Fprintln(w, "</testsuite>")
 
-	if failed > 0 {
-		return 1
-	}
-
-	return 0
+	return resultExitCode(failed, errored, timedOut)
 }
 
 func init() {
-	RegisterOutputer("junit", &JUnit{}, []string{})
+	RegisterOutputer("junit", &JUnit{}, listFormatOptions)
+}
+
+// junitProperties are the properties of the suite, where and how it ran
+func junitProperties(outConfig util.OutputConfig, duration time.Duration) string {
+	var properties [][2]string
+	if outConfig.Gossfile != "" {
+		properties = append(properties, [2]string{"gossfile", outConfig.Gossfile})
+	}
+	if outConfig.Vars != "" {
+		properties = append(properties, [2]string{"vars", outConfig.Vars})
+	}
+	if outConfig.Version != "" {
+		properties = append(properties, [2]string{"goss-version", outConfig.Version})
+	}
+	if outConfig.RunID != "" {
+		properties = append(properties, [2]string{"run-id", outConfig.RunID})
+	}
+	if hostname, err := os.Hostname(); err == nil && !outConfig.Redact {
+		properties = append(properties, [2]string{"hostname", hostname})
+	}
+	for _, p := range outConfig.Timing.Phases(duration) {
+		properties = append(properties, [2]string{p.Name + "-duration", fmt.Sprintf("%.6f", p.Duration.Seconds())})
+	}
+	if len(properties) == 0 {
+		return ""
+	}
+	s := "<properties>\n"
+	for _, p := range properties {
+		s += "<property name=\"" + p[0] + "\" value=\"" + escapeString(p[1]) + "\"/>\n"
+	}
+	return s + "</properties>\n"
 }
 
 func escapeString(str string) string {
@@ -15,50 +15,79 @@ type Nagios struct{}
 func (r Nagios) Output(w io.Writer, results <-chan []resource.TestResult,
 	startTime time.Time, outConfig util.OutputConfig) (exitCode int) {
 
-	var testCount, failed, skipped int
+	var testCount, failed, skipped, errored, timedOut, warnings int
 
 	var perfdata, verbose bool
 	perfdata = util.IsValueInList("perfdata", outConfig.FormatOptions)
 	verbose = util.IsValueInList("verbose", outConfig.FormatOptions)
 
-	var summary map[int]string
-	summary = make(map[int]string)
+	var summary []string
 
 	for resultGroup := range results {
 		for _, testResult := range resultGroup {
-			switch testResult.Result {
-			case resource.FAIL:
-				if util.IsValueInList("verbose", outConfig.FormatOptions) {
-					summary[failed] = "Fail " + strconv.Itoa(failed+1) + " - " + humanizeResult2(testResult) + "\n"
+			switch {
+			case testResult.Warning():
+				warnings++
+			case testResult.Result == resource.FAIL:
+				if verbose {
+					summary = append(summary, "Fail "+strconv.Itoa(failed+1)+" - "+humanizeResult2(testResult)+"\n")
 				}
 				failed++
-			case resource.SKIP:
+			case testResult.Result == resource.ERROR:
+				if verbose {
+					summary = append(summary, "Error "+strconv.Itoa(errored+1)+" - "+humanizeResult2(testResult)+"\n")
+				}
+				errored++
+			case testResult.Result == resource.SKIP:
 				skipped++
+			case testResult.Result == resource.TIMEOUT:
+				timedOut++
 			}
 			testCount++
 		}
 	}
 
-	duration := time.Since(startTime)
+	// Tests that errored or timed out leave the state unknown unless others failed
+	state, code := "OK", 0
 	if failed > 0 {
-		fmt.Fprintf(w, "GOSS CRITICAL - Count: %d, Failed: %d, Skipped: %d, Duration: %.3fs", testCount, failed, skipped, duration.Seconds())
-		if perfdata {
-			fmt.Fprintf(w, "|total=%d failed=%d skipped=%d duration=%.3fs", testCount, failed, skipped, duration.Seconds())
-		}
-		fmt.Fprint(w, "\n")
-		if verbose {
-			for i := 0; i < failed; i++ {
-				fmt.Fprintf(w, "%s", summary[i])
-			}
-		}
-		return 2
+		state, code = "CRITICAL", 2
+	} else if errored > 0 || timedOut > 0 {
+		state, code = "UNKNOWN", 3
+	}
+
+	duration := time.Since(startTime)
+	fmt.Fprintf(w, "GOSS %s - Count: %d, Failed: %d, Skipped: %d", state, testCount, failed, skipped)
+	if errored > 0 {
+		fmt.Fprintf(w, ", Errors: %d", errored)
+	}
+	if timedOut > 0 {
+		fmt.Fprintf(w, ", Timed out: %d", timedOut)
+	}
+	if warnings > 0 {
+		fmt.Fprintf(w, ", Warnings: %d", warnings)
+	}
+	fmt.Fprintf(w, ", Duration: %.6fs", duration.Seconds())
+	if outConfig.RunID != "" {
+		fmt.Fprintf(w, ", Run ID: %s", outConfig.RunID)
 	}
-	fmt.Fprintf(w, "GOSS OK - Count: %d, Failed: %d, Skipped: %d, Duration: %.3fs", testCount, failed, skipped, duration.Seconds())
 	if perfdata {
-		fmt.Fprintf(w, "|total=%d failed=%d skipped=%d duration=%.3fs", testCount, failed, skipped, duration.Seconds())
+		fmt.Fprintf(w, "|total=%d failed=%d skipped=%d", testCount, failed, skipped)
+		if errored > 0 || timedOut > 0 {
+			fmt.Fprintf(w, " errors=%d timed_out=%d", errored, timedOut)
+		}
+		if warnings > 0 {
+			fmt.Fprintf(w, " warnings=%d", warnings)
+		}
+		fmt.Fprintf(w, " duration=%.6fs", duration.Seconds())
+		for _, p := range outConfig.Timing.Phases(duration) {
+			fmt.Fprintf(w, " %s_duration=%.6fs", p.Name, p.Duration.Seconds())
+		}
 	}
 	fmt.Fprint(w, "\n")
-	return 0
+	for _, s := range summary {
+		fmt.Fprint(w, s)
+	}
+	return code
 }
 
 func init() {
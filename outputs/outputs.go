@@ -22,60 +22,128 @@ var red = color.New(color.FgRed).SprintfFunc()
 var yellow = color.New(color.FgYellow).SprintfFunc()
 
 func humanizeResult(r resource.TestResult) string {
+	return warningPrefix(r) + humanizeOutcome(r)
+}
+
+func humanizeOutcome(r resource.TestResult) string {
 	if r.Err != nil {
-		return red("%s: %s: Error: %s", r.ResourceId, r.Property, r.Err)
+		return red(tr("%s: %s: Error: %s"), r.ResourceId, r.Property, r.Err)
 	}
 
 	switch r.Result {
 	case resource.SUCCESS:
-		return green("%s: %s: %s: matches expectation: %s", r.ResourceType, r.ResourceId, r.Property, r.Expected)
+		return green(tr("%s: %s: %s: matches expectation: %s"), r.ResourceType, r.ResourceId, r.Property, r.Expected)
 	case resource.SKIP:
-		return yellow("%s: %s: %s: skipped", r.ResourceType, r.ResourceId, r.Property)
+		if r.SkipReason != "" {
+			return yellow(tr("%s: %s: %s: skipped, %s"), r.ResourceType, r.ResourceId, r.Property, r.SkipReason)
+		}
+		return yellow(tr("%s: %s: %s: skipped"), r.ResourceType, r.ResourceId, r.Property)
+	case resource.TIMEOUT:
+		return yellow(tr("%s: %s: timed out"), r.ResourceType, r.ResourceId)
 	case resource.FAIL:
 		if r.Human != "" {
 			return red("%s: %s: %s:\n%s", r.ResourceType, r.ResourceId, r.Property, r.Human)
 		}
-		return humanizeResult2(r)
+		return humanizeOutcome2(r)
 	default:
 		panic(fmt.Sprintf("Unexpected Result Code: %v\n", r.Result))
 	}
 }
 
 func humanizeResult2(r resource.TestResult) string {
+	return warningPrefix(r) + humanizeOutcome2(r)
+}
+
+func humanizeOutcome2(r resource.TestResult) string {
 	if r.Err != nil {
-		return red("%s: %s: Error: %s", r.ResourceId, r.Property, r.Err)
+		return red(tr("%s: %s: Error: %s"), r.ResourceId, r.Property, r.Err)
 	}
 
 	switch r.Result {
 	case resource.SUCCESS:
 		switch r.TestType {
 		case resource.Value:
-			return green("%s: %s: %s: matches expectation: %s", r.ResourceType, r.ResourceId, r.Property, r.Expected)
+			return green(tr("%s: %s: %s: matches expectation: %s"), r.ResourceType, r.ResourceId, r.Property, r.Expected)
 		case resource.Values:
-			return green("%s: %s: %s: all expectations found: [%s]", r.ResourceType, r.ResourceId, r.Property, strings.Join(r.Expected, ", "))
+			return green(tr("%s: %s: %s: all expectations found: [%s]"), r.ResourceType, r.ResourceId, r.Property, strings.Join(r.Expected, ", "))
 		case resource.Contains:
-			return green("%s: %s: %s: all expectations found: [%s]", r.ResourceType, r.ResourceId, r.Property, strings.Join(r.Expected, ", "))
+			return green(tr("%s: %s: %s: all expectations found: [%s]"), r.ResourceType, r.ResourceId, r.Property, strings.Join(r.Expected, ", "))
 		default:
 			return red("Unexpected type %d", r.TestType)
 		}
 	case resource.FAIL:
 		switch r.TestType {
 		case resource.Value:
-			return red("%s: %s: %s: doesn't match, expect: %s found: %s", r.ResourceType, r.ResourceId, r.Property, r.Expected, r.Found)
+			return red(tr("%s: %s: %s: doesn't match, expect: %s found: %s"), r.ResourceType, r.ResourceId, r.Property, r.Expected, r.Found)
 		case resource.Values:
-			return red("%s: %s: %s: expectations not found [%s]", r.ResourceType, r.ResourceId, r.Property, strings.Join(subtractSlice(r.Expected, r.Found), ", "))
+			return red(tr("%s: %s: %s: expectations not found [%s]"), r.ResourceType, r.ResourceId, r.Property, strings.Join(subtractSlice(r.Expected, r.Found), ", "))
 		case resource.Contains:
-			return red("%s: %s: %s: patterns not found: [%s]", r.ResourceType, r.ResourceId, r.Property, strings.Join(subtractSlice(r.Expected, r.Found), ", "))
+			return red(tr("%s: %s: %s: patterns not found: [%s]"), r.ResourceType, r.ResourceId, r.Property, strings.Join(subtractSlice(r.Expected, r.Found), ", "))
 		default:
 			return red("Unexpected type %d", r.TestType)
 		}
 	case resource.SKIP:
-		return yellow("%s: %s: %s: skipped", r.ResourceType, r.ResourceId, r.Property)
+		if r.SkipReason != "" {
+			return yellow(tr("%s: %s: %s: skipped, %s"), r.ResourceType, r.ResourceId, r.Property, r.SkipReason)
+		}
+		return yellow(tr("%s: %s: %s: skipped"), r.ResourceType, r.ResourceId, r.Property)
+	case resource.TIMEOUT:
+		return yellow(tr("%s: %s: timed out"), r.ResourceType, r.ResourceId)
 	default:
 		panic(fmt.Sprintf("Unexpected Result Code: %v\n", r.Result))
 	}
 }
 
+// warningPrefix marks the results of quarantined and flaky tests, tests in a
+// maintenance window, tests of warn and info resources and tests that already
+// failed in the baseline, which don't affect the exit code
+func warningPrefix(r resource.TestResult) string {
+	switch {
+	case r.Quarantined():
+		return yellow(tr("[quarantined] "))
+	case r.Flaky():
+		return yellow(tr("[flaky] "))
+	case r.Maintenance != "" && r.Warning():
+		return yellow(tr("[maintenance: %s] "), r.Maintenance)
+	case r.Informational() && r.Severity == resource.SeverityWarn:
+		return yellow(tr("[warn] "))
+	case r.Informational():
+		return yellow(tr("[info] "))
+	case r.Warning():
+		return yellow(tr("[baseline] "))
+	}
+	return ""
+}
+
+// listFormatOptions are the format options of the outputers that list every
+// test, failures-only leaves out the tests that passed or were skipped while
+// still counting them, the others order the results, see OrderResults
+var listFormatOptions = []string{"failures-only", "group-by-type", "sort-by-duration"}
+
+// listed reports whether the test is listed by the format options
+func listed(r resource.TestResult, outConfig util.OutputConfig) bool {
+	if !util.IsValueInList("failures-only", outConfig.FormatOptions) || r.Warning() {
+		return true
+	}
+	return r.Result != resource.SUCCESS && r.Result != resource.SKIP
+}
+
+// warningLabel is the untranslated reason a test only warns, for formats
+// read by other tools
+func warningLabel(r resource.TestResult) string {
+	switch {
+	case r.Quarantined():
+		return "quarantined"
+	case r.Flaky():
+		return "flaky"
+	case r.Maintenance != "":
+		return "maintenance"
+	case r.Informational():
+		return r.Severity
+	}
+	return "baseline"
+}
+
 // Copied from database/sql
 var (
 	outputersMu           sync.Mutex
@@ -175,7 +243,7 @@ func subtractSlice(x, y []string) []string {
 func header(t resource.TestResult) string {
 	var out string
 	if t.Title != "" {
-		out += fmt.Sprintf("Title: %s\n", t.Title)
+		out += fmt.Sprintf(tr("Title: %s\n"), t.Title)
 	}
 	if t.Meta != nil {
 		var keys []string
@@ -184,7 +252,7 @@ func header(t resource.TestResult) string {
 		}
 		sort.Strings(keys)
 
-		out += "Meta:\n"
+		out += tr("Meta:\n")
 		for _, k := range keys {
 			out += fmt.Sprintf("    %v: %v\n", k, t.Meta[k])
 		}
@@ -192,20 +260,90 @@ func header(t resource.TestResult) string {
 	return out
 }
 
-func summary(startTime time.Time, count, failed, skipped int) string {
+func summary(startTime time.Time, outConfig util.OutputConfig, count, failed, skipped, errored, timedOut, warnings int) string {
 	var s string
-	s += fmt.Sprintf("Total Duration: %.3fs\n", time.Since(startTime).Seconds())
+	total := time.Since(startTime)
+	s += fmt.Sprintf(tr("Total Duration: %.6fs\n"), total.Seconds())
+	if phases := phasesLine(outConfig.Timing, total); phases != "" {
+		s += fmt.Sprintf(tr("Phases: %s\n"), phases)
+	}
+	if outConfig.RunID != "" {
+		s += fmt.Sprintf(tr("Run ID: %s\n"), outConfig.RunID)
+	}
 	f := green
-	if failed > 0 {
+	if failed > 0 || errored > 0 {
 		f = red
+	} else if timedOut > 0 {
+		f = yellow
+	}
+	line := fmt.Sprintf(tr("Count: %d, Failed: %d, Skipped: %d"), count, failed, skipped)
+	if errored > 0 {
+		line += fmt.Sprintf(tr(", Errors: %d"), errored)
 	}
-	s += f("Count: %d, Failed: %d, Skipped: %d\n", count, failed, skipped)
+	if timedOut > 0 {
+		line += fmt.Sprintf(tr(", Timed out: %d"), timedOut)
+	}
+	if warnings > 0 {
+		line += fmt.Sprintf(tr(", Warnings: %d"), warnings)
+	}
+	s += f("%s\n", line)
 	return s
 }
+
+// summaryLine is the untranslated summary of the machine readable formats
+func summaryLine(count, failed, errored, timedOut, warnings int, duration time.Duration) string {
+	s := fmt.Sprintf("Count: %d, Failed: %d", count, failed)
+	if errored > 0 {
+		s += fmt.Sprintf(", Errors: %d", errored)
+	}
+	if timedOut > 0 {
+		s += fmt.Sprintf(", Timed out: %d", timedOut)
+	}
+	if warnings > 0 {
+		s += fmt.Sprintf(", Warnings: %d", warnings)
+	}
+	return s + fmt.Sprintf(", Duration: %.6fs", duration.Seconds())
+}
+
+// phasesLine is how long each phase of a run that took total took, empty
+// without a timing
+func phasesLine(timing *util.Timing, total time.Duration) string {
+	var phases []string
+	for _, p := range timing.Phases(total) {
+		phases = append(phases, fmt.Sprintf("%s %.6fs", p.Name, p.Duration.Seconds()))
+	}
+	return strings.Join(phases, ", ")
+}
+
+// phaseDurations are the durations of the phases of a run that took total by
+// name, nil without a timing
+func phaseDurations(timing *util.Timing, total time.Duration) map[string]time.Duration {
+	phases := timing.Phases(total)
+	if phases == nil {
+		return nil
+	}
+	durations := make(map[string]time.Duration, len(phases))
+	for _, p := range phases {
+		durations[p.Name] = p.Duration
+	}
+	return durations
+}
+
+// resultExitCode is 1 when tests failed and 2 when none failed but some couldn't
+// be checked, because they errored or timed out
+func resultExitCode(failed, errored, timedOut int) int {
+	if failed > 0 {
+		return 1
+	}
+	if errored > 0 || timedOut > 0 {
+		return 2
+	}
+	return 0
+}
 func failedOrSkippedSummary(failedOrSkipped [][]resource.TestResult) string {
 	var s string
 	if len(failedOrSkipped) > 0 {
-		s += fmt.Sprint("Failures/Skipped:\n\n")
+		s += fmt.Sprint(tr("Failures/Skipped:\n\n"))
 		for _, failedGroup := range failedOrSkipped {
 			first := failedGroup[0]
 			header := header(first)
@@ -1,7 +1,19 @@
 package outputs
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
 )
 
 func TestIsValidFormat(t *testing.T) {
@@ -23,3 +35,540 @@ func TestIsValidFormatOption(t *testing.T) {
 		t.Fatal("'verbose' should be a valid output format option")
 	}
 }
+
+func TestSetLanguage(t *testing.T) {
+	defer SetLanguage("en")
+
+	if err := SetLanguage("xx"); err == nil {
+		t.Fatal("'xx' should not be a valid language")
+	}
+
+	if err := SetLanguage("de_DE.UTF-8"); err != nil {
+		t.Fatal(err)
+	}
+	if got := tr("Meta:\n"); got != "Meta:\n" {
+		t.Fatalf("expected 'Meta:' got %q", got)
+	}
+	if got := tr("Title: %s\n"); got != "Titel: %s\n" {
+		t.Fatalf("expected 'Titel: %%s' got %q", got)
+	}
+
+	if err := SetLanguage("C"); err != nil {
+		t.Fatal(err)
+	}
+	if got := tr("Title: %s\n"); got != "Title: %s\n" {
+		t.Fatalf("expected 'Title: %%s' got %q", got)
+	}
+}
+
+func TestTruncateString(t *testing.T) {
+	got, ok := truncateString("hello world", 5)
+	if !ok || got != "hello... [6 bytes truncated]" {
+		t.Fatalf("unexpected truncation: %q", got)
+	}
+
+	got, ok = truncateString("hello", 5)
+	if ok || got != "hello" {
+		t.Fatalf("did not expect truncation: %q", got)
+	}
+
+	got, _ = truncateString("aé", 2)
+	if got != "a... [2 bytes truncated]" {
+		t.Fatalf("expected rune safe truncation, got %q", got)
+	}
+}
+
+func TestTruncateResult(t *testing.T) {
+	var found []string
+	for i := 0; i < 1000; i++ {
+		found = append(found, fmt.Sprintf("/etc/file-%03d", i))
+	}
+	r := resource.TestResult{Human: "Expected to contain everything", Expected: []string{"/etc/file-000"}, Found: found}
+	got, ok := truncateResult(r, 100)
+	if !ok {
+		t.Fatal("expected the result to be truncated")
+	}
+	size := len(got.Human)
+	for _, s := range append(append([]string{}, got.Expected...), got.Found...) {
+		size += len(s)
+	}
+	// The budget, one cut entry and the marker of the dropped ones
+	if size > 100+2*len("... [1000 entries, 10000 bytes truncated]") {
+		t.Errorf("truncated result has %d bytes: %q %q %q", size, got.Human, got.Expected, got.Found)
+	}
+	if last := got.Found[len(got.Found)-1]; !strings.HasSuffix(last, "bytes truncated]") || !strings.Contains(last, " entries, ") {
+		t.Errorf("expected the dropped entries to be counted, got %q", last)
+	}
+	if len(r.Found) != 1000 || r.Found[999] != "/etc/file-999" {
+		t.Error("the found of the result was changed")
+	}
+
+	// The expectations not found are the same after truncating found
+	for _, testType := range []int{resource.Values, resource.Contains} {
+		r = resource.TestResult{Result: resource.FAIL, TestType: testType, Expected: []string{"/etc/file-999", "/etc/missing"}, Found: found}
+		got, ok = truncateResult(r, 100)
+		if !ok {
+			t.Fatal("expected the result to be truncated")
+		}
+		if missing := subtractSlice(got.Expected, got.Found); !reflect.DeepEqual(missing, []string{"/etc/missing"}) {
+			t.Errorf("type %d: expectations not found after truncating: %q", testType, missing)
+		}
+		if !strings.Contains(humanizeResult2(got), "[/etc/missing]") {
+			t.Errorf("type %d: got %q", testType, humanizeResult2(got))
+		}
+	}
+
+	if _, ok := truncateResult(resource.TestResult{Human: "short", Found: []string{"a", "b"}}, 100); ok {
+		t.Error("didn't expect a result within the budget to be truncated")
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	r := NewRedactor()
+
+	got := r.String(`https://internal.example.com:8443/health: Get "https://internal.example.com:8443/health": dial tcp 10.1.2.3:8443: connection refused`)
+	host, ip := r.placeholders["internal.example.com"], r.placeholders["10.1.2.3"]
+	want := `https://` + host + `:8443/health: Get "https://` + host + `:8443/health": dial tcp ` + ip + `:8443: connection refused`
+	if host == "" || ip == "" || got != want {
+		t.Fatalf("expected hostname and ip to be redacted, got %q", got)
+	}
+
+	got = r.String(`["10.1.2.3","::1"] /home/alice/.ssh/config 1.0.0`)
+	want = `["` + ip + `","` + r.placeholders["::1"] + `"] ` + r.placeholders["/home/alice"] + `/.ssh/config 1.0.0`
+	if got != want {
+		t.Fatalf("expected stable placeholders, got %q want %q", got, want)
+	}
+}
+
+func TestRedactorNetworkResources(t *testing.T) {
+	tests := []struct {
+		resourceType, id, host string
+	}{
+		{"DNS", "A:db.internal", "db.internal"},
+		{"Ping", "gateway.internal", "gateway.internal"},
+		{"Addr", "tcp://cache.internal:6379", "cache.internal"},
+		{"Addr", "queue.internal:5672", "queue.internal"},
+		{"HTTP", "https://user@api.internal:8443/health?x=1", "api.internal"},
+		{"GRPC", "orders.internal:50051", "orders.internal"},
+	}
+	for _, tc := range tests {
+		r := NewRedactor()
+		got := r.Result(resource.TestResult{ResourceType: tc.resourceType, ResourceId: tc.id, Title: "checks " + tc.id, Err: fmt.Errorf("%s doesn't implement server reflection", tc.id)})
+		if tc.host != "" && r.placeholders[tc.host] == "" {
+			t.Errorf("%s %s: host %s wasn't registered", tc.resourceType, tc.id, tc.host)
+		}
+		if tc.host != "" && strings.Contains(got.ResourceId+got.Title+got.Err.Error(), tc.host) {
+			t.Errorf("%s %s: host leaked: %q %q %v", tc.resourceType, tc.id, got.ResourceId, got.Title, got.Err)
+		}
+	}
+}
+
+func TestRedactorSkipReasonMeta(t *testing.T) {
+	r := NewRedactor()
+	meta := map[string]interface{}{
+		"owner":   "/home/alice",
+		"weight":  3,
+		"runbook": []interface{}{"https://wiki.internal/db"},
+		"server":  map[interface{}]interface{}{"ip": "10.1.2.3"},
+	}
+	got := r.Result(resource.TestResult{ResourceType: "File", ResourceId: "/etc/hosts", Meta: meta, SkipReason: "requires Addr tcp://db.internal:5432 failed"})
+	if want := "requires Addr tcp://" + r.placeholders["db.internal"] + ":5432 failed"; got.SkipReason != want {
+		t.Errorf("SkipReason = %q, want: %q", got.SkipReason, want)
+	}
+	want := map[string]interface{}{
+		"owner":   r.placeholders["/home/alice"],
+		"weight":  3,
+		"runbook": []interface{}{"https://" + r.placeholders["wiki.internal"] + "/db"},
+		"server":  map[interface{}]interface{}{"ip": r.placeholders["10.1.2.3"]},
+	}
+	if !reflect.DeepEqual(map[string]interface{}(got.Meta), want) {
+		t.Errorf("Meta = %v, want: %v", got.Meta, want)
+	}
+	if meta["owner"] != "/home/alice" {
+		t.Errorf("the meta of the resource was changed: %v", meta)
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	results := func(meta map[string]interface{}, codes ...int) <-chan []resource.TestResult {
+		c := make(chan []resource.TestResult, 1)
+		var group []resource.TestResult
+		for _, r := range codes {
+			tr := resource.TestResult{ResourceType: "File", ResourceId: "/etc/passwd", Property: "exists", Result: r, Successful: r == resource.SUCCESS, Meta: meta}
+			if r == resource.ERROR {
+				tr.Err = fmt.Errorf("permission denied")
+			}
+			group = append(group, tr)
+		}
+		c <- group
+		close(c)
+		return c
+	}
+	tests := []struct {
+		meta           map[string]interface{}
+		results        []int
+		want, wantNag  int
+		nagiosContains string
+	}{
+		{nil, []int{resource.SUCCESS, resource.SKIP}, 0, 0, "GOSS OK"},
+		{nil, []int{resource.SUCCESS, resource.FAIL, resource.ERROR}, 1, 2, "GOSS CRITICAL"},
+		{nil, []int{resource.SUCCESS, resource.ERROR}, 2, 3, "Errors: 1"},
+		{nil, []int{resource.TIMEOUT}, 2, 3, "GOSS UNKNOWN"},
+		{map[string]interface{}{"quarantined": true}, []int{resource.SUCCESS, resource.FAIL, resource.ERROR}, 0, 0, "Warnings: 2"},
+		{map[string]interface{}{"flaky": true}, []int{resource.SUCCESS, resource.FAIL}, 0, 0, "Warnings: 1"},
+	}
+	for _, tc := range tests {
+		for _, name := range []string{"documentation", "html", "json", "junit", "prometheus", "rspecish", "silent", "tap"} {
+			var b bytes.Buffer
+			if got := outputers[name].Output(&b, results(tc.meta, tc.results...), time.Now(), util.OutputConfig{}); got != tc.want {
+				t.Errorf("%s exit code for %v: got %d, want %d", name, tc.results, got, tc.want)
+			}
+		}
+		var b bytes.Buffer
+		if got := outputers["nagios"].Output(&b, results(tc.meta, tc.results...), time.Now(), util.OutputConfig{}); got != tc.wantNag {
+			t.Errorf("nagios exit code for %v: got %d, want %d", tc.results, got, tc.wantNag)
+		}
+		if !strings.Contains(b.String(), tc.nagiosContains) {
+			t.Errorf("nagios output for %v doesn't contain %q: %s", tc.results, tc.nagiosContains, b.String())
+		}
+	}
+}
+
+func TestHTML(t *testing.T) {
+	c := make(chan []resource.TestResult, 2)
+	c <- []resource.TestResult{
+		{ResourceType: "Service", ResourceId: "sshd", Property: "running", Result: resource.SUCCESS, Successful: true},
+		{ResourceType: "Service", ResourceId: "sshd", Property: "enabled", Result: resource.FAIL, TestType: resource.Value, Expected: []string{"true"}, Found: []string{"false"}},
+	}
+	c <- []resource.TestResult{{ResourceType: "File", ResourceId: "<script>", Property: "exists", Result: resource.SUCCESS, Successful: true, Duration: 1500 * time.Microsecond}}
+	close(c)
+	var b bytes.Buffer
+	if got := outputers["html"].Output(&b, c, time.Now(), util.OutputConfig{}); got != 1 {
+		t.Errorf("html exit code: got %d, want 1", got)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"<span class=\"passed\">Passed: 2</span><span class=\"failed\">Failed: 1</span>",
+		"<h2>Service (1, 1 failing)</h2>",
+		"<summary>&lt;script&gt;</summary>",
+		"Service: sshd: enabled: doesn&#39;t match, expect: [true] found: [false]",
+		"<td>2ms</td>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("html output doesn't contain %q: %s", want, out)
+		}
+	}
+	if strings.Index(out, "<h2>File") > strings.Index(out, "<h2>Service") {
+		t.Errorf("html output isn't grouped by type in order: %s", out)
+	}
+}
+
+func TestGitHub(t *testing.T) {
+	dir := t.TempDir()
+	gossfile := filepath.Join(dir, "goss.yaml")
+	if err := ioutil.WriteFile(gossfile, []byte("service:\n  sshd:\n    enabled: true\n    running: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	summary := filepath.Join(dir, "summary.md")
+	defer os.Setenv("GITHUB_STEP_SUMMARY", os.Getenv("GITHUB_STEP_SUMMARY"))
+	os.Setenv("GITHUB_STEP_SUMMARY", summary)
+
+	c := make(chan []resource.TestResult, 2)
+	c <- []resource.TestResult{
+		{ResourceType: "Service", ResourceId: "sshd", Property: "running", Result: resource.SUCCESS, Successful: true},
+		{ResourceType: "Service", ResourceId: "sshd", Property: "enabled", Result: resource.FAIL, TestType: resource.Value, Expected: []string{"true"}, Found: []string{"false"}},
+	}
+	c <- []resource.TestResult{{ResourceType: "Port", ResourceId: "tcp:22", Property: "listening", Result: resource.TIMEOUT, Meta: map[string]interface{}{"quarantined": true}}}
+	close(c)
+	var b bytes.Buffer
+	if got := outputers["github"].Output(&b, c, time.Now(), util.OutputConfig{Gossfile: gossfile}); got != 1 {
+		t.Errorf("github exit code: got %d, want 1", got)
+	}
+	for _, want := range []string{
+		"::error file=" + githubEscapeProperty(gossfile) + ",line=2,title=Service%3A sshd%3A enabled::Service: sshd: enabled: doesn't match, expect: [true] found: [false]\n",
+		"::warning file=" + githubEscapeProperty(gossfile) + ",title=Port%3A tcp%3A22%3A listening::[quarantined] Port: tcp:22: timed out\n",
+		"Count: 3, Failed: 1, Warnings: 1",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("github output doesn't contain %q: %s", want, b.String())
+		}
+	}
+	md, err := ioutil.ReadFile(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"| Service | 1 | 1 | 0 | 0 | 0 | 0 |",
+		"| Port | 0 | 0 | 0 | 0 | 1 | 0 |",
+		"| Service: sshd | enabled | Service: sshd: enabled: doesn't match, expect: [true] found: [false] |",
+	} {
+		if !strings.Contains(string(md), want) {
+			t.Errorf("job summary doesn't contain %q: %s", want, md)
+		}
+	}
+}
+
+func TestPrometheus(t *testing.T) {
+	c := make(chan []resource.TestResult, 1)
+	c <- []resource.TestResult{
+		{ResourceType: "File", ResourceId: `C:\"x"`, Property: "exists", Result: resource.SUCCESS, Successful: true, Duration: 1500 * time.Millisecond},
+		{ResourceType: "Service", ResourceId: "sshd", Property: "running", Result: resource.FAIL},
+	}
+	close(c)
+	var b bytes.Buffer
+	if got := outputers["prometheus"].Output(&b, c, time.Now(), util.OutputConfig{}); got != 1 {
+		t.Errorf("prometheus exit code: got %d, want 1", got)
+	}
+	for _, want := range []string{
+		`goss_test_status{resource_type="File",resource_id="C:\\\"x\"",property="exists",status="passed"} 1`,
+		`goss_test_duration_seconds{resource_type="File",resource_id="C:\\\"x\"",property="exists"} 1.5`,
+		`goss_test_status{resource_type="Service",resource_id="sshd",property="running",status="failed"} 1`,
+		`goss_tests{status="failed"} 1`,
+		`goss_tests{status="timed_out"} 0`,
+		"goss_run_exit_code 1\n# EOF\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("prometheus output doesn't contain %q: %s", want, b.String())
+		}
+	}
+}
+
+func TestJUnit(t *testing.T) {
+	c := make(chan []resource.TestResult, 1)
+	c <- []resource.TestResult{
+		{ResourceType: "File", ResourceId: "/etc/hosts", Property: "exists", Result: resource.SUCCESS, Successful: true, Duration: 1500 * time.Millisecond},
+		{ResourceType: "Port", ResourceId: "tcp:22", Property: "listening", Result: resource.SKIP},
+	}
+	close(c)
+	var b bytes.Buffer
+	outputers["junit"].Output(&b, c, time.Now(), util.OutputConfig{Gossfile: "goss.yaml", Vars: "vars & more.yaml", Version: "v1.2.3", Redact: true})
+	for _, want := range []string{
+		`<testcase name="File /etc/hosts exists" classname="File" time="1.500">`,
+		`<testcase name="Port tcp:22 listening" classname="Port" time="0.000">` + "\n<skipped/>",
+		"<properties>\n<property name=\"gossfile\" value=\"goss.yaml\"/>\n<property name=\"vars\" value=\"vars &amp; more.yaml\"/>\n<property name=\"goss-version\" value=\"v1.2.3\"/>\n</properties>",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("junit output doesn't contain %q: %s", want, b.String())
+		}
+	}
+	if strings.Contains(b.String(), "hostname") {
+		t.Errorf("junit output has the hostname with redact: %s", b.String())
+	}
+}
+
+func TestScore(t *testing.T) {
+	results := func() <-chan []resource.TestResult {
+		c := make(chan []resource.TestResult, 4)
+		c <- []resource.TestResult{
+			{ResourceType: "File", ResourceId: "/etc/shadow", Property: "mode", Result: resource.FAIL, Meta: map[string]interface{}{"weight": 3}},
+			{ResourceType: "File", ResourceId: "/etc/shadow", Property: "owner", Result: resource.SUCCESS, Meta: map[string]interface{}{"weight": 3}},
+		}
+		c <- []resource.TestResult{{ResourceType: "Service", ResourceId: "sshd", Property: "running", Result: resource.SUCCESS, Meta: map[string]interface{}{"weight": 4.5}}}
+		c <- []resource.TestResult{{ResourceType: "Package", ResourceId: "telnet", Property: "installed", Result: resource.SUCCESS}}
+		c <- []resource.TestResult{{ResourceType: "Port", ResourceId: "tcp:23", Property: "listening", Result: resource.SKIP, Meta: map[string]interface{}{"weight": 10}}}
+		close(c)
+		return c
+	}
+	var b bytes.Buffer
+	if got := outputers["score"].Output(&b, results(), time.Now(), util.OutputConfig{ScoreThreshold: 60}); got != 0 {
+		t.Errorf("score exit code above the threshold: got %d, want 0: %s", got, b.String())
+	}
+	if want := "Score: 64.71% (5.5 of 8.5), threshold: 60%: PASS"; !strings.Contains(b.String(), want) {
+		t.Errorf("score output doesn't contain %q: %s", want, b.String())
+	}
+	if want := "File: /etc/shadow (weight 3)"; !strings.Contains(b.String(), want) {
+		t.Errorf("score output doesn't contain %q: %s", want, b.String())
+	}
+	b.Reset()
+	if got := outputers["score"].Output(&b, results(), time.Now(), util.OutputConfig{ScoreThreshold: 90}); got != 1 {
+		t.Errorf("score exit code below the threshold: got %d, want 1: %s", got, b.String())
+	}
+}
+
+func TestJSONErrorCodes(t *testing.T) {
+	for _, name := range []string{"json", "json_oneline", "structured"} {
+		c := make(chan []resource.TestResult, 1)
+		c <- []resource.TestResult{{ResourceType: "Package", ResourceId: "nginx", Property: "installed", Result: resource.ERROR,
+			Err: util.NewCodedError(util.ErrCodePkgBackendNotFound, fmt.Errorf("rpm isn't installed"))}}
+		close(c)
+		var b bytes.Buffer
+		outputers[name].Output(&b, c, time.Now(), util.OutputConfig{})
+		if want := `"err":{"code":"GOSS-E-PKG-BACKEND-NOT-FOUND","message":"rpm isn't installed"}`; !strings.Contains(b.String(), want) {
+			t.Errorf("%s output doesn't contain %s: %s", name, want, b.String())
+		}
+	}
+}
+
+func TestSortResults(t *testing.T) {
+	in := make(chan []resource.TestResult, 2)
+	in <- []resource.TestResult{
+		{ResourceType: "Service", ResourceId: "sshd", Property: "running"},
+		{ResourceType: "Service", ResourceId: "sshd", Property: "enabled"},
+	}
+	in <- []resource.TestResult{{ResourceType: "File", ResourceId: "/etc/passwd", Property: "exists"}}
+	close(in)
+
+	var got []string
+	for group := range SortResults(in) {
+		for _, r := range group {
+			got = append(got, r.ResourceType+" "+r.ResourceId+" "+r.Property)
+		}
+	}
+	want := []string{"File /etc/passwd exists", "Service sshd enabled", "Service sshd running"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestOrderResults(t *testing.T) {
+	in := make(chan []resource.TestResult, 2)
+	in <- []resource.TestResult{
+		{ResourceType: "Service", ResourceId: "sshd", Property: "running", Duration: time.Millisecond},
+		{ResourceType: "File", ResourceId: "/etc/passwd", Property: "exists", Duration: time.Millisecond},
+	}
+	in <- []resource.TestResult{{ResourceType: "Service", ResourceId: "nginx", Property: "running", Duration: time.Second}}
+	close(in)
+
+	var got []string
+	for group := range OrderResults(in, []string{"group-by-type", "sort-by-duration"}) {
+		for _, r := range group {
+			got = append(got, r.ResourceType+" "+r.ResourceId)
+		}
+	}
+	want := []string{"Service nginx", "Service sshd", "File /etc/passwd"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestFailuresOnly(t *testing.T) {
+	outConfig := util.OutputConfig{FormatOptions: []string{"failures-only"}}
+	for _, name := range []string{"documentation", "html", "json", "json_oneline", "junit", "rspecish", "structured"} {
+		c := make(chan []resource.TestResult, 1)
+		c <- []resource.TestResult{
+			{ResourceType: "File", ResourceId: "/etc/passwd", Property: "exists", Result: resource.SUCCESS, Successful: true, Expected: []string{"true"}},
+			{ResourceType: "File", ResourceId: "/etc/shadow", Property: "exists", Result: resource.FAIL, Expected: []string{"true"}, Found: []string{"false"}},
+		}
+		close(c)
+		var b bytes.Buffer
+		if code := outputers[name].Output(&b, c, time.Now(), outConfig); name != "structured" && code != 1 {
+			t.Errorf("%s exit code: got %d, want 1", name, code)
+		}
+		if strings.Contains(b.String(), "/etc/passwd") || !strings.Contains(b.String(), "/etc/shadow") {
+			t.Errorf("%s lists the tests that passed or not the failures:\n%s", name, b.String())
+		}
+		if name == "rspecish" && !strings.Contains(b.String(), "Count: 2, Failed: 1") {
+			t.Errorf("%s doesn't count the tests that passed:\n%s", name, b.String())
+		}
+	}
+}
+
+func TestPhases(t *testing.T) {
+	timing := &util.Timing{Load: 2 * time.Millisecond, Render: 500 * time.Microsecond, Validate: time.Second}
+	start := time.Now().Add(-1100 * time.Millisecond)
+	want := map[string]string{
+		"rspecish":   "Phases: load 0.002000s, render 0.000500s, validate 1.000000s, output 0.09",
+		"tap":        "# Phases: load 0.002000s, render 0.000500s, validate 1.000000s, output 0.09",
+		"json":       `"phase-durations":{"load":2000000,"output":9`,
+		"structured": `"phase-durations":{"load":2000000,"output":9`,
+		"prometheus": `goss_run_phase_duration_seconds{phase="render"} 0.0005`,
+	}
+	for format, line := range want {
+		c := make(chan []resource.TestResult, 1)
+		c <- []resource.TestResult{{ResourceType: "File", ResourceId: "/etc/hosts", Property: "exists", Result: resource.SUCCESS, Successful: true}}
+		close(c)
+		var b bytes.Buffer
+		outputers[format].Output(&b, c, start, util.OutputConfig{Timing: timing})
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("%s output doesn't contain %q: %s", format, line, b.String())
+		}
+	}
+
+	c := make(chan []resource.TestResult)
+	close(c)
+	var b bytes.Buffer
+	outputers["rspecish"].Output(&b, c, start, util.OutputConfig{})
+	if strings.Contains(b.String(), "Phases") {
+		t.Errorf("rspecish output without a timing has phases: %s", b.String())
+	}
+}
+
+func TestRunID(t *testing.T) {
+	id := "0b6f5d8e-3c1a-4f2b-9d7e-1a2b3c4d5e6f"
+	want := map[string]string{
+		"rspecish":   "Run ID: " + id + "\n",
+		"tap":        "# Run ID: " + id + "\n",
+		"nagios":     ", Run ID: " + id,
+		"json":       `"run-id":"` + id + `"`,
+		"structured": `"run-id":"` + id + `"`,
+		"junit":      `<property name="run-id" value="` + id + `"/>`,
+		"prometheus": `goss_run_info{run_id="` + id + `"} 1`,
+		"html":       "run " + id,
+	}
+	for format, line := range want {
+		c := make(chan []resource.TestResult, 1)
+		c <- []resource.TestResult{{ResourceType: "File", ResourceId: "/etc/hosts", Property: "exists", Result: resource.SUCCESS, Successful: true}}
+		close(c)
+		var b bytes.Buffer
+		outputers[format].Output(&b, c, time.Now(), util.OutputConfig{RunID: id})
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("%s output doesn't contain %q: %s", format, line, b.String())
+		}
+	}
+}
+
+func TestSummaryGroups(t *testing.T) {
+	results := func() <-chan []resource.TestResult {
+		c := make(chan []resource.TestResult, 1)
+		c <- []resource.TestResult{
+			{ResourceType: "Service", ResourceId: "postgresql", Property: "running", Result: resource.FAIL, Severity: "fail",
+				Tags: []string{"db"}, Meta: map[string]interface{}{"owner": "dba"}},
+			{ResourceType: "Service", ResourceId: "postgresql", Property: "enabled", Result: resource.SUCCESS, Successful: true, Severity: "fail",
+				Tags: []string{"db"}, Meta: map[string]interface{}{"owner": "dba"}},
+			{ResourceType: "Port", ResourceId: "tcp:80", Property: "listening", Result: resource.FAIL, Severity: "warn",
+				Tags: []string{"web", "edge"}, Meta: map[string]interface{}{"owner": "web"}},
+			{ResourceType: "File", ResourceId: "/etc/hosts", Property: "exists", Result: resource.SUCCESS, Successful: true, Severity: "fail"},
+		}
+		close(c)
+		return c
+	}
+
+	var b bytes.Buffer
+	outputers["documentation"].Output(&b, results(), time.Now(), util.OutputConfig{})
+	for _, line := range []string{
+		"By tag: db 1/2 failed; edge 0/1 failed, 1 warned; web 0/1 failed, 1 warned\n",
+		"By severity: fail 1/3 failed; warn 0/1 failed, 1 warned\n",
+		"By owner: dba 1/2 failed; web 0/1 failed, 1 warned\n",
+	} {
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("documentation output doesn't contain %q: %s", line, b.String())
+		}
+	}
+
+	b.Reset()
+	outputers["json"].Output(&b, results(), time.Now(), util.OutputConfig{})
+	var out struct {
+		Summary struct {
+			Groups map[string]map[string]groupCount `json:"groups"`
+		} `json:"summary"`
+	}
+	if err := json.Unmarshal(b.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.Summary.Groups["owner"]["dba"], (groupCount{Tests: 2, Failed: 1}); got != want {
+		t.Errorf("json owner dba: got %+v, want %+v", got, want)
+	}
+	if got, want := out.Summary.Groups["severity"]["warn"], (groupCount{Tests: 1, Warnings: 1}); got != want {
+		t.Errorf("json severity warn: got %+v, want %+v", got, want)
+	}
+
+	// Suites that don't use tags, owners or severities aren't grouped
+	c := make(chan []resource.TestResult, 1)
+	c <- []resource.TestResult{{ResourceType: "File", ResourceId: "/etc/hosts", Property: "exists", Result: resource.FAIL, Severity: "fail"}}
+	close(c)
+	b.Reset()
+	outputers["json"].Output(&b, c, time.Now(), util.OutputConfig{})
+	if strings.Contains(b.String(), `"groups"`) {
+		t.Errorf("json output has groups: %s", b.String())
+	}
+}
@@ -0,0 +1,98 @@
+package outputs
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+)
+
+// Prometheus writes the results as metrics in the OpenMetrics text format,
+// which the textfile collector of node_exporter and the Pushgateway read
+type Prometheus struct{}
+
+// prometheusStatuses are the values of the status label, every one of them
+// has a goss_tests series so rates don't break when a count is 0
+var prometheusStatuses = []string{"passed", "failed", "error", "timed_out", "warning", "skipped"}
+
+func prometheusStatus(r resource.TestResult) string {
+	switch {
+	case r.Warning():
+		return "warning"
+	case r.Result == resource.FAIL:
+		return "failed"
+	case r.Result == resource.ERROR:
+		return "error"
+	case r.Result == resource.TIMEOUT:
+		return "timed_out"
+	case r.Result == resource.SKIP:
+		return "skipped"
+	}
+	return "passed"
+}
+
+func (r Prometheus) Output(w io.Writer, results <-chan []resource.TestResult,
+	startTime time.Time, outConfig util.OutputConfig) (exitCode int) {
+
+	var status, duration strings.Builder
+	counts := make(map[string]int)
+	for resultGroup := range results {
+		for _, testResult := range resultGroup {
+			s := prometheusStatus(testResult)
+			counts[s]++
+			labels := fmt.Sprintf(`resource_type="%s",resource_id="%s",property="%s"`,
+				escapeLabel(testResult.ResourceType), escapeLabel(testResult.ResourceId), escapeLabel(testResult.Property))
+			fmt.Fprintf(&status, "goss_test_status{%s,status=\"%s\"} 1\n", labels, s)
+			fmt.Fprintf(&duration, "goss_test_duration_seconds{%s} %g\n", labels, testResult.Duration.Seconds())
+		}
+	}
+	exitCode = resultExitCode(counts["failed"], counts["error"], counts["timed_out"])
+
+	fmt.Fprintln(w, "# HELP goss_test_status Status of the test, 1 for its status")
+	fmt.Fprintln(w, "# TYPE goss_test_status gauge")
+	fmt.Fprint(w, status.String())
+	fmt.Fprintln(w, "# HELP goss_test_duration_seconds Duration of the test")
+	fmt.Fprintln(w, "# TYPE goss_test_duration_seconds gauge")
+	fmt.Fprint(w, duration.String())
+	fmt.Fprintln(w, "# HELP goss_tests Number of tests by status")
+	fmt.Fprintln(w, "# TYPE goss_tests gauge")
+	for _, s := range prometheusStatuses {
+		fmt.Fprintf(w, "goss_tests{status=\"%s\"} %d\n", s, counts[s])
+	}
+	fmt.Fprintln(w, "# HELP goss_run_duration_seconds Duration of the run")
+	fmt.Fprintln(w, "# TYPE goss_run_duration_seconds gauge")
+	total := time.Since(startTime)
+	fmt.Fprintf(w, "goss_run_duration_seconds %g\n", total.Seconds())
+	if phases := outConfig.Timing.Phases(total); phases != nil {
+		fmt.Fprintln(w, "# HELP goss_run_phase_duration_seconds Duration of the load, render, validate and output phases of the run")
+		fmt.Fprintln(w, "# TYPE goss_run_phase_duration_seconds gauge")
+		for _, p := range phases {
+			fmt.Fprintf(w, "goss_run_phase_duration_seconds{phase=\"%s\"} %g\n", p.Name, p.Duration.Seconds())
+		}
+	}
+	if outConfig.RunID != "" {
+		fmt.Fprintln(w, "# HELP goss_run_info Identity of the run, always 1")
+		fmt.Fprintln(w, "# TYPE goss_run_info gauge")
+		fmt.Fprintf(w, "goss_run_info{run_id=\"%s\"} 1\n", outConfig.RunID)
+	}
+	fmt.Fprintln(w, "# HELP goss_run_timestamp_seconds Time the run finished")
+	fmt.Fprintln(w, "# TYPE goss_run_timestamp_seconds gauge")
+	fmt.Fprintf(w, "goss_run_timestamp_seconds %d\n", time.Now().Unix())
+	fmt.Fprintln(w, "# HELP goss_run_exit_code Exit code of the run, 0 when it passed")
+	fmt.Fprintln(w, "# TYPE goss_run_exit_code gauge")
+	fmt.Fprintf(w, "goss_run_exit_code %d\n", exitCode)
+	fmt.Fprintln(w, "# EOF")
+
+	return exitCode
+}
+
+func init() {
+	RegisterOutputer("prometheus", &Prometheus{}, []string{})
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,231 @@
+package outputs
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+)
+
+var (
+	ipCandidate   = regexp.MustCompile(`[0-9a-fA-F:.]*[:.][0-9a-fA-F:.]+`)
+	urlHost       = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://(?:[^@/\s]*@)?(\[[^\]]+\]|[^/:\s"'\]]+)`)
+	homeDir       = regexp.MustCompile(`(?:/home|/Users)/[^/\s"':]+`)
+	dnsTypePrefix = regexp.MustCompile(`^[A-Z]+:`)
+)
+
+// Redactor replaces IP addresses, hostnames and home directory paths with
+// stable placeholders, the same value is always replaced by the same
+// placeholder for the lifetime of the Redactor
+type Redactor struct {
+	mu           sync.Mutex
+	placeholders map[string]string
+	counts       map[string]int
+	hosts        map[string]bool
+}
+
+// NewRedactor creates a Redactor that knows about the local hostname and home directory
+func NewRedactor() *Redactor {
+	r := &Redactor{
+		placeholders: make(map[string]string),
+		counts:       make(map[string]int),
+		hosts:        make(map[string]bool),
+	}
+	if h, err := os.Hostname(); err == nil && h != "" {
+		r.addHost(h)
+		r.addHost(strings.SplitN(h, ".", 2)[0])
+	}
+	if home, err := os.UserHomeDir(); err == nil && home != "" && home != "/" {
+		r.placeholder("home", home)
+	}
+	return r
+}
+
+// RedactResults redacts every result passing through the channel when enabled
+func RedactResults(in <-chan []resource.TestResult, enabled bool) <-chan []resource.TestResult {
+	if !enabled {
+		return in
+	}
+
+	r := NewRedactor()
+	out := make(chan []resource.TestResult)
+	go func() {
+		defer close(out)
+		for resultGroup := range in {
+			for i, testResult := range resultGroup {
+				resultGroup[i] = r.Result(testResult)
+			}
+			out <- resultGroup
+		}
+	}()
+
+	return out
+}
+
+// Result returns a redacted copy of t
+func (r *Redactor) Result(t resource.TestResult) resource.TestResult {
+	// Hostnames that are the subject of a network check are always sensitive
+	r.addHost(networkHost(t.ResourceType, t.ResourceId))
+	t.ResourceId = r.String(t.ResourceId)
+	t.Title = r.String(t.Title)
+	t.Human = r.String(t.Human)
+	t.SkipReason = r.String(t.SkipReason)
+	if t.Meta != nil {
+		// The meta is shared by the results of a resource, it's copied
+		meta := make(map[string]interface{}, len(t.Meta))
+		for k, v := range t.Meta {
+			meta[k] = r.value(v)
+		}
+		t.Meta = meta
+	}
+	t.Expected = r.slice(t.Expected)
+	t.Found = r.slice(t.Found)
+	if t.Err != nil {
+		t.Err = util.ReplaceErrorMessage(t.Err, r.String(t.Err.Error()))
+	}
+	return t
+}
+
+// String redacts s
+func (r *Redactor) String(s string) string {
+	if s == "" {
+		return s
+	}
+	for _, m := range urlHost.FindAllStringSubmatch(s, -1) {
+		r.addHost(strings.Trim(m[1], "[]"))
+	}
+
+	s = homeDir.ReplaceAllStringFunc(s, func(m string) string {
+		return r.placeholder("home", m)
+	})
+	s = ipCandidate.ReplaceAllStringFunc(s, func(m string) string {
+		// The candidate may include a port or trailing punctuation, e.g. end of a sentence
+		candidates := []string{m, strings.TrimRight(m, ".:"), strings.Trim(m, ".:")}
+		for _, c := range candidates[:2] {
+			if host, _, err := net.SplitHostPort(c); err == nil {
+				candidates = append(candidates, host)
+			}
+		}
+		for _, c := range candidates {
+			if ip := net.ParseIP(c); ip != nil {
+				return strings.Replace(m, c, r.placeholder("ip", c), 1)
+			}
+		}
+		return m
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var known []string
+	for k := range r.placeholders {
+		known = append(known, k)
+	}
+	// Longest first, so a FQDN isn't partially replaced by its short name
+	sort.Slice(known, func(i, j int) bool { return len(known[i]) > len(known[j]) })
+	for _, k := range known {
+		s = strings.Replace(s, k, r.placeholders[k], -1)
+	}
+	return s
+}
+
+// networkHost is the host the resource of a network check connects to, or
+// "" for other resources
+func networkHost(resourceType, id string) string {
+	switch resourceType {
+	case "DNS":
+		return dnsTypePrefix.ReplaceAllString(id, "")
+	case "Ping":
+		return id
+	case "Addr", "GRPC", "HTTP":
+		return hostOf(id)
+	}
+	return ""
+}
+
+// hostOf is the host of a URL or an address such as tcp://host:port or
+// host:port
+func hostOf(address string) string {
+	if i := strings.Index(address, "://"); i >= 0 {
+		address = address[i+3:]
+	}
+	if i := strings.IndexAny(address, "/?#"); i >= 0 {
+		address = address[:i]
+	}
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		address = address[i+1:]
+	}
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
+	}
+	return strings.Trim(address, "[]")
+}
+
+func (r *Redactor) slice(in []string) []string {
+	if in == nil {
+		return nil
+	}
+	out := make([]string, len(in))
+	for i, s := range in {
+		out[i] = r.String(s)
+	}
+	return out
+}
+
+// value redacts the strings of a value of the meta, in lists and maps too
+func (r *Redactor) value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return r.String(v)
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			out[i] = r.value(e)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			out[k] = r.value(e)
+		}
+		return out
+	case map[interface{}]interface{}:
+		out := make(map[interface{}]interface{}, len(v))
+		for k, e := range v {
+			out[k] = r.value(e)
+		}
+		return out
+	}
+	return v
+}
+
+func (r *Redactor) addHost(h string) {
+	if h == "" || h == "localhost" || net.ParseIP(h) != nil {
+		return
+	}
+	r.mu.Lock()
+	newHost := !r.hosts[h]
+	r.hosts[h] = true
+	r.mu.Unlock()
+	if newHost {
+		r.placeholder("host", h)
+	}
+}
+
+// placeholder returns the placeholder for value, creating one of kind if it doesn't exist yet
+func (r *Redactor) placeholder(kind, value string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p, ok := r.placeholders[value]; ok {
+		return p
+	}
+	r.counts[kind]++
+	p := fmt.Sprintf("<%s-%d>", kind, r.counts[kind])
+	r.placeholders[value] = p
+	return p
+}
@@ -16,10 +16,24 @@ func (r Rspecish) Output(w io.Writer, results <-chan []resource.TestResult,
 
 	testCount := 0
 	var failedOrSkipped [][]resource.TestResult
-	var skipped, failed int
+	var skipped, failed, errored, timedOut, warnings int
 	for resultGroup := range results {
 		failedOrSkippedGroup := []resource.TestResult{}
 		for _, testResult := range resultGroup {
+			if !listed(testResult, outConfig) {
+				if testResult.Result == resource.SKIP {
+					skipped++
+				}
+				testCount++
+				continue
+			}
+			if testResult.Warning() {
+				fmt.Fprintf(w, yellow("W"))
+				failedOrSkippedGroup = append(failedOrSkippedGroup, testResult)
+				warnings++
+				testCount++
+				continue
+			}
 			switch testResult.Result {
 			case resource.SUCCESS:
 				fmt.Fprintf(w, green("."))
@@ -31,6 +45,14 @@ func (r Rspecish) Output(w io.Writer, results <-chan []resource.TestResult,
 				fmt.Fprintf(w, red("F"))
 				failedOrSkippedGroup = append(failedOrSkippedGroup, testResult)
 				failed++
+			case resource.ERROR:
+				fmt.Fprintf(w, red("E"))
+				failedOrSkippedGroup = append(failedOrSkippedGroup, testResult)
+				errored++
+			case resource.TIMEOUT:
+				fmt.Fprintf(w, yellow("T"))
+				failedOrSkippedGroup = append(failedOrSkippedGroup, testResult)
+				timedOut++
 			}
 			testCount++
 		}
@@ -42,13 +64,10 @@ func (r Rspecish) Output(w io.Writer, results <-chan []resource.TestResult,
 	fmt.Fprint(w, "\n\n")
 	fmt.Fprint(w, failedOrSkippedSummary(failedOrSkipped))
 
-	fmt.Fprint(w, summary(startTime, testCount, failed, skipped))
-	if failed > 0 {
-		return 1
-	}
-	return 0
+	fmt.Fprint(w, summary(startTime, outConfig, testCount, failed, skipped, errored, timedOut, warnings))
+	return resultExitCode(failed, errored, timedOut)
 }
 
 func init() {
-	RegisterOutputer("rspecish", &Rspecish{}, []string{})
+	RegisterOutputer("rspecish", &Rspecish{}, listFormatOptions)
 }
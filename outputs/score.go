@@ -0,0 +1,129 @@
+package outputs
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+)
+
+// Score grades the run by the weights of the resources that passed, a
+// resource passes when none of its tests failed, errored or timed out
+type Score struct{}
+
+type scoredResource struct {
+	resourceType, id string
+	weight           float64
+	passed, scored   bool
+	failures         []resource.TestResult
+}
+
+func (r Score) Output(w io.Writer, results <-chan []resource.TestResult,
+	startTime time.Time, outConfig util.OutputConfig) (exitCode int) {
+
+	var scored []*scoredResource
+	byID := make(map[string]*scoredResource)
+	var testCount, failed, skipped, errored, timedOut, warnings int
+	for resultGroup := range results {
+		for _, testResult := range resultGroup {
+			testCount++
+			key := testResult.ResourceType + "\x00" + testResult.ResourceId
+			s, ok := byID[key]
+			if !ok {
+				weight, err := resourceWeight(testResult.Meta)
+				if err != nil {
+					fmt.Fprintln(w, yellow("%s: %s: %s, using 1", testResult.ResourceType, testResult.ResourceId, err))
+				}
+				s = &scoredResource{resourceType: testResult.ResourceType, id: testResult.ResourceId, weight: weight, passed: true}
+				byID[key] = s
+				scored = append(scored, s)
+			}
+			switch {
+			case testResult.Warning():
+				// Quarantined and known failures are left out of the score
+				warnings++
+				continue
+			case testResult.Result == resource.SKIP:
+				skipped++
+				continue
+			case testResult.Result == resource.FAIL:
+				failed++
+			case testResult.Result == resource.ERROR:
+				errored++
+			case testResult.Result == resource.TIMEOUT:
+				timedOut++
+			}
+			s.scored = true
+			if testResult.Result != resource.SUCCESS {
+				s.passed = false
+				s.failures = append(s.failures, testResult)
+			}
+		}
+	}
+
+	var total, passed float64
+	var failedResources []*scoredResource
+	for _, s := range scored {
+		if !s.scored {
+			continue
+		}
+		total += s.weight
+		if s.passed {
+			passed += s.weight
+		} else {
+			failedResources = append(failedResources, s)
+		}
+	}
+	// Nothing to grade is a full score, as with a run where every test passed
+	score := 100.0
+	if total > 0 {
+		score = passed / total * 100
+	}
+
+	if len(failedResources) > 0 {
+		fmt.Fprint(w, tr("Failed resources:\n\n"))
+		for _, s := range failedResources {
+			fmt.Fprintf(w, tr("%s: %s (weight %g)\n"), s.resourceType, s.id, s.weight)
+			for _, f := range s.failures {
+				fmt.Fprintf(w, "    %s\n", humanizeResult2(f))
+			}
+		}
+		fmt.Fprint(w, "\n")
+	}
+	fmt.Fprint(w, summary(startTime, outConfig, testCount, failed, skipped, errored, timedOut, warnings))
+
+	f, line := green, tr("Score: %.2f%% (%g of %g), threshold: %g%%: PASS")
+	if score < outConfig.ScoreThreshold {
+		f, line, exitCode = red, tr("Score: %.2f%% (%g of %g), threshold: %g%%: FAIL"), 1
+	}
+	fmt.Fprintln(w, f(line, score, passed, total, outConfig.ScoreThreshold))
+	return exitCode
+}
+
+// resourceWeight is meta.weight, 1 when it isn't set or isn't a non negative
+// number
+func resourceWeight(m map[string]interface{}) (float64, error) {
+	v, ok := m["weight"]
+	if !ok {
+		return 1, nil
+	}
+	var weight float64
+	switch n := v.(type) {
+	case int:
+		weight = float64(n)
+	case float64:
+		weight = n
+	default:
+		return 1, fmt.Errorf("meta.weight %v is not a number", v)
+	}
+	if weight < 0 {
+		return 1, fmt.Errorf("meta.weight %v is negative", v)
+	}
+	return weight, nil
+}
+
+func init() {
+	RegisterOutputer("score", &Score{}, []string{})
+}
@@ -13,20 +13,23 @@ type Silent struct{}
 func (r Silent) Output(w io.Writer, results <-chan []resource.TestResult,
 	startTime time.Time, outConfig util.OutputConfig) (exitCode int) {
 
-	var failed int
+	var failed, errored, timedOut int
 	for resultGroup := range results {
 		for _, testResult := range resultGroup {
-			switch testResult.Result {
-			case resource.FAIL:
+			switch {
+			case testResult.Warning():
+				// Quarantined or in maintenance, doesn't affect the exit code
+			case testResult.Result == resource.FAIL:
 				failed++
+			case testResult.Result == resource.ERROR:
+				errored++
+			case testResult.Result == resource.TIMEOUT:
+				timedOut++
 			}
 		}
 	}
 
-	if failed > 0 {
-		return 1
-	}
-	return 0
+	return resultExitCode(failed, errored, timedOut)
 }
 
 func init() {
@@ -0,0 +1,73 @@
+package outputs
+
+import (
+	"sort"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+)
+
+// SortResults reports all of the results as one group sorted by resource
+// type, id and property, so reports don't depend on the order of the gossfile
+func SortResults(in <-chan []resource.TestResult) <-chan []resource.TestResult {
+	out := make(chan []resource.TestResult)
+	go func() {
+		defer close(out)
+		var results []resource.TestResult
+		for resultGroup := range in {
+			results = append(results, resultGroup...)
+		}
+		sort.SliceStable(results, func(i, j int) bool {
+			a, b := results[i], results[j]
+			if a.ResourceType != b.ResourceType {
+				return a.ResourceType < b.ResourceType
+			}
+			if a.ResourceId != b.ResourceId {
+				return a.ResourceId < b.ResourceId
+			}
+			return a.Property < b.Property
+		})
+		if len(results) > 0 {
+			out <- results
+		}
+	}()
+	return out
+}
+
+// OrderResults reports all of the results as one group ordered by the format
+// options: group-by-type keeps the tests of a resource type together, in the
+// order the types first appear, and sort-by-duration puts the slowest tests
+// first, within their type when both are set. in is returned as is otherwise.
+func OrderResults(in <-chan []resource.TestResult, formatOptions []string) <-chan []resource.TestResult {
+	byType := util.IsValueInList("group-by-type", formatOptions)
+	byDuration := util.IsValueInList("sort-by-duration", formatOptions)
+	if !byType && !byDuration {
+		return in
+	}
+
+	out := make(chan []resource.TestResult)
+	go func() {
+		defer close(out)
+		var results []resource.TestResult
+		typeOrder := make(map[string]int)
+		for resultGroup := range in {
+			for _, r := range resultGroup {
+				if _, ok := typeOrder[r.ResourceType]; !ok {
+					typeOrder[r.ResourceType] = len(typeOrder)
+				}
+			}
+			results = append(results, resultGroup...)
+		}
+		sort.SliceStable(results, func(i, j int) bool {
+			a, b := results[i], results[j]
+			if byType && a.ResourceType != b.ResourceType {
+				return typeOrder[a.ResourceType] < typeOrder[b.ResourceType]
+			}
+			return byDuration && a.Duration > b.Duration
+		})
+		if len(results) > 0 {
+			out <- results
+		}
+	}()
+	return out
+}
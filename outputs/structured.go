@@ -23,7 +23,15 @@ type StructuredTestResult struct {
 type StructureTestSummary struct {
 	TestCount     int           `json:"test-count"`
 	Failed        int           `json:"failed-count"`
+	Errored       int           `json:"error-count"`
+	TimedOut      int           `json:"timed-out-count"`
+	Warnings      int           `json:"warning-count"`
 	TotalDuration time.Duration `json:"total-duration"`
+	// PhaseDurations are the durations of the load, render, validate and
+	// output phases of the run
+	PhaseDurations map[string]time.Duration `json:"phase-durations,omitempty"`
+	// RunID identifies the run
+	RunID string `json:"run-id,omitempty"`
 }
 
 // StructuredOutput is the full output structure for the structured output format
@@ -35,7 +43,7 @@ type StructuredOutput struct {
 
 // String represents human friendly representation of the test summary
 func (s *StructureTestSummary) String() string {
-	return fmt.Sprintf("Count: %d, Failed: %d, Duration: %.3fs", s.TestCount, s.Failed, s.TotalDuration.Seconds())
+	return summaryLine(s.TestCount, s.Failed, s.Errored, s.TimedOut, s.Warnings, s.TotalDuration)
 }
 
 // Output processes output from tests into StructuredOutput written to w as a string
@@ -47,22 +55,35 @@ func (r Structured) Output(w io.Writer, results <-chan []resource.TestResult, st
 
 	for resultGroup := range results {
 		for _, testResult := range resultGroup {
+			// Errors are marshalled as their error code and message
+			testResult.Err = util.CodeError(testResult.Err)
 			r := StructuredTestResult{
 				TestResult:  testResult,
 				SummaryLine: humanizeResult(testResult),
 			}
 
-			if !testResult.Successful {
+			switch {
+			case testResult.Warning():
+				result.Summary.Warnings++
+			case testResult.Result == resource.FAIL:
 				result.Summary.Failed++
+			case testResult.Result == resource.ERROR:
+				result.Summary.Errored++
+			case testResult.Result == resource.TIMEOUT:
+				result.Summary.TimedOut++
 			}
 
 			result.Summary.TestCount++
 
-			result.Results = append(result.Results, r)
+			if listed(testResult, outConfig) {
+				result.Results = append(result.Results, r)
+			}
 		}
 	}
 
 	result.Summary.TotalDuration = time.Since(startTime)
+	result.Summary.PhaseDurations = phaseDurations(outConfig.Timing, result.Summary.TotalDuration)
+	result.Summary.RunID = outConfig.RunID
 	result.SummaryLine = result.Summary.String()
 
 	var j []byte
@@ -79,5 +100,5 @@ func (r Structured) Output(w io.Writer, results <-chan []resource.TestResult, st
 }
 
 func init() {
-	RegisterOutputer("structured", &Structured{}, []string{})
+	RegisterOutputer("structured", &Structured{}, listFormatOptions)
 }
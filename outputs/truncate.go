@@ -0,0 +1,144 @@
+package outputs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+)
+
+// TruncateResults limits the size of the human readable parts of every result
+// to maxBytes so outputers don't produce enormous reports. When details is not
+// nil the untruncated version of every truncated result is written to it as a
+// json line. A maxBytes of 0 or less disables truncation.
+func TruncateResults(in <-chan []resource.TestResult, maxBytes int, details io.Writer) <-chan []resource.TestResult {
+	if maxBytes <= 0 {
+		return in
+	}
+
+	out := make(chan []resource.TestResult)
+	go func() {
+		defer close(out)
+		for resultGroup := range in {
+			for i, testResult := range resultGroup {
+				truncated, ok := truncateResult(testResult, maxBytes)
+				if !ok {
+					continue
+				}
+				if details != nil {
+					writeDetails(details, testResult)
+				}
+				resultGroup[i] = truncated
+			}
+			out <- resultGroup
+		}
+	}()
+
+	return out
+}
+
+// truncateResult returns a copy of r with its large fields truncated to
+// maxBytes in total, ok is false when nothing needed truncating. The error
+// comes first as it explains the failure, then human, expected and found.
+//
+// Outputs report the expectations of a failed values or contains test that
+// aren't in found, which truncating either could change, so those are cut
+// down to the expectations not found and the entries found that weren't
+// expected first.
+func truncateResult(r resource.TestResult, maxBytes int) (resource.TestResult, bool) {
+	t, ok := truncateFields(r, maxBytes)
+	if !ok || r.Result != resource.FAIL || (r.TestType != resource.Values && r.TestType != resource.Contains) {
+		return t, ok
+	}
+	r.Expected, r.Found = difference(r.Expected, r.Found), difference(r.Found, r.Expected)
+	t, _ = truncateFields(r, maxBytes)
+	return t, true
+}
+
+// difference is the entries of x that aren't in y
+func difference(x, y []string) []string {
+	if d := subtractSlice(x, y); d != nil {
+		return d
+	}
+	return []string{}
+}
+
+func truncateFields(r resource.TestResult, maxBytes int) (resource.TestResult, bool) {
+	b := &budget{left: maxBytes}
+	if r.Err != nil {
+		if msg := b.string(r.Err.Error()); b.truncated {
+			r.Err = util.ReplaceErrorMessage(r.Err, msg)
+		}
+	}
+	r.Human = b.string(r.Human)
+	r.Expected = b.slice(r.Expected)
+	r.Found = b.slice(r.Found)
+	return r, b.truncated
+}
+
+// budget is what's left of the bytes of a result, truncated is whether any
+// field was truncated
+type budget struct {
+	left      int
+	truncated bool
+}
+
+func (b *budget) string(s string) string {
+	ts, t := truncateString(s, b.left)
+	if !t {
+		b.left -= len(s)
+		return s
+	}
+	b.left = 0
+	b.truncated = true
+	return ts
+}
+
+// slice truncates the entries of in, those after the budget ran out are
+// replaced by one marker
+func (b *budget) slice(in []string) []string {
+	out := make([]string, 0, len(in))
+	changed := false
+	for i, s := range in {
+		if b.left == 0 && s != "" {
+			bytes := 0
+			for _, rest := range in[i:] {
+				bytes += len(rest)
+			}
+			b.truncated = true
+			return append(out, fmt.Sprintf("... [%d entries, %d bytes truncated]", len(in)-i, bytes))
+		}
+		ts := b.string(s)
+		changed = changed || ts != s
+		out = append(out, ts)
+	}
+	if !changed {
+		return in
+	}
+	return out
+}
+
+func truncateString(s string, maxBytes int) (string, bool) {
+	if len(s) <= maxBytes {
+		return s, false
+	}
+	// Don't cut a multi-byte character in half
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s... [%d bytes truncated]", s[:cut], len(s)-cut), true
+}
+
+func writeDetails(w io.Writer, r resource.TestResult) {
+	m := struct2map(r)
+	if r.Err != nil {
+		m["err"] = r.Err.Error()
+		m["err-code"] = util.ErrorCode(r.Err)
+	}
+	j, _ := json.Marshal(m)
+	fmt.Fprintln(w, string(j))
+}
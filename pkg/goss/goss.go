@@ -0,0 +1,205 @@
+// Package goss is the stable API to embed goss in Go programs, such as
+// operators and provisioners, without running the goss binary and parsing its
+// json output.
+//
+//	suite, err := goss.Load("goss.yaml", goss.WithVarsFile("vars.yaml"))
+//	if err != nil {
+//		return err
+//	}
+//	report, err := suite.Run(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	if !report.Passed() {
+//		for _, r := range report.Failures() {
+//			log.Printf("%s: %s: %s: %s", r.ResourceType, r.ResourceID, r.Property, r.Message())
+//		}
+//	}
+//
+// The types of this package are kept compatible between releases, unlike
+// those of the packages goss is built from.
+package goss
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	core "github.com/aelsabbahy/goss"
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+)
+
+// Option configures how a gossfile is loaded and validated
+type Option func(c *util.Config) error
+
+// WithVarsFile renders the gossfile with the vars of file, a yaml or json
+// file, or of sources such as ssm://, as --vars does
+func WithVarsFile(file string) Option { return Option(util.WithVarsSources(file)) }
+
+// WithVars renders the gossfile with vars, such as a map, as --vars-inline
+// does with its json
+func WithVars(vars interface{}) Option { return Option(util.WithVarsData(vars)) }
+
+// WithTemplateFunc makes fn available to the templates of the gossfile as
+// name, such as a lookup in an inventory
+func WithTemplateFunc(name string, fn interface{}) Option {
+	return Option(util.WithTemplateFunc(name, fn))
+}
+
+// WithTags only validates the resources with one of tags
+func WithTags(tags ...string) Option { return Option(util.WithTags(tags...)) }
+
+// WithSkipTags leaves out the resources with one of tags
+func WithSkipTags(tags ...string) Option { return Option(util.WithSkipTags(tags...)) }
+
+// WithPackageManager sets the package manager packages are validated with
+// instead of the detected one
+func WithPackageManager(p string) Option { return Option(util.WithPackageManager(p)) }
+
+// WithMaxConcurrency is how many resources are validated at the same time
+func WithMaxConcurrency(n int) Option { return Option(util.WithMaxConcurrency(n)) }
+
+// WithRunTimeout reports the resources that haven't finished after d as timed
+// out, a deadline of the context of a run is used when it's sooner
+func WithRunTimeout(d time.Duration) Option {
+	return func(c *util.Config) error {
+		c.MaxRunDuration = d
+		return nil
+	}
+}
+
+// WithProcfs reads services from /proc and /etc instead of the service
+// manager, as --procfs does
+func WithProcfs() Option { return Option(util.WithProcfs()) }
+
+// loading serializes loading gossfiles, their rendering isn't safe to run
+// concurrently
+var loading sync.Mutex
+
+// Suite is a loaded gossfile, it's validated every time it's run. Runs of a
+// Suite started from several goroutines take turns.
+type Suite struct {
+	config     util.Config
+	gossConfig core.GossConfig
+	// running is held until the validation of a run finished, resources
+	// aren't safe to validate concurrently
+	running sync.Mutex
+}
+
+// Load loads the gossfile at path, rendered with its vars and with its
+// includes, as goss validate does
+func Load(path string, opts ...Option) (*Suite, error) {
+	configOpts := []util.ConfigOption{util.WithSpecFile(path)}
+	for _, opt := range opts {
+		configOpts = append(configOpts, util.ConfigOption(opt))
+	}
+	c, err := util.NewConfig(configOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	loading.Lock()
+	defer loading.Unlock()
+	gossConfig, err := core.LoadGossConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	return &Suite{config: *c, gossConfig: *gossConfig}, nil
+}
+
+// Event is sent as each resource finishes validating, with the results of
+// its tests
+type Event struct {
+	RunID   string
+	Results []Result
+}
+
+// Events validates the suite and sends an event for each resource as it
+// finishes, the channel is closed when the run is over. The resources that
+// haven't finished by the deadline of ctx are reported as timed out. Once ctx
+// is canceled no more events are sent, resources still validating finish in
+// the background.
+func (s *Suite) Events(ctx context.Context) (<-chan Event, error) {
+	s.running.Lock()
+	c := s.config
+	if deadline, ok := ctx.Deadline(); ok {
+		if d := time.Until(deadline); c.MaxRunDuration <= 0 || d < c.MaxRunDuration {
+			c.MaxRunDuration = d
+		}
+	}
+	results, err := core.ValidateGossConfig(&c, s.gossConfig)
+	if err != nil {
+		s.running.Unlock()
+		return nil, err
+	}
+
+	runID := uuid.New().String()
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		// Drained so a canceled run doesn't block the validation
+		defer func() {
+			go func() {
+				defer s.running.Unlock()
+				for range results {
+				}
+			}()
+		}()
+		done := ctx.Done()
+		for {
+			var resultGroup []resource.TestResult
+			var ok bool
+			select {
+			case <-done:
+				if ctx.Err() != context.Canceled {
+					// The run has the same deadline and reports the
+					// rest as timed out
+					done = nil
+					continue
+				}
+				return
+			case resultGroup, ok = <-results:
+				if !ok {
+					return
+				}
+			}
+			event := Event{RunID: runID}
+			for _, r := range resultGroup {
+				event.Results = append(event.Results, newResult(r))
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				if ctx.Err() == context.Canceled {
+					return
+				}
+				events <- event
+			}
+		}
+	}()
+	return events, nil
+}
+
+// Run validates the suite and reports the results once every resource
+// finished. The error is that of ctx when it's canceled before the end of the
+// run.
+func (s *Suite) Run(ctx context.Context) (*Report, error) {
+	start := time.Now()
+	events, err := s.Events(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{}
+	for event := range events {
+		report.RunID = event.RunID
+		report.Results = append(report.Results, event.Results...)
+	}
+	if ctx.Err() == context.Canceled {
+		return nil, ctx.Err()
+	}
+	report.Duration = time.Since(start)
+	return report, nil
+}
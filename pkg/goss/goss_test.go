@@ -0,0 +1,66 @@
+package goss
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuite(t *testing.T) {
+	dir := t.TempDir()
+	gossfile := filepath.Join(dir, "goss.yaml")
+	require.NoError(t, ioutil.WriteFile(gossfile, []byte(`file:
+  {{.Vars.dir | parentDir}}:
+    exists: true
+    filetype: directory
+command:
+  exit 1:
+    exit-status: 0
+    tags: [slow]
+`), 0644))
+
+	suite, err := Load(gossfile, WithVars(map[string]interface{}{"dir": filepath.Join(dir, "x")}),
+		WithTemplateFunc("parentDir", filepath.Dir))
+	require.NoError(t, err)
+	report, err := suite.Run(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, report.RunID, 36)
+	assert.Len(t, report.Results, 3)
+	assert.False(t, report.Passed())
+	failures := report.Failures()
+	require.Len(t, failures, 1)
+	assert.Equal(t, "Command", failures[0].ResourceType)
+	assert.Equal(t, "exit 1", failures[0].ResourceID)
+	assert.Equal(t, "exit-status", failures[0].Property)
+	assert.Equal(t, StatusFail, failures[0].Status)
+	assert.Equal(t, []string{"1"}, failures[0].Found)
+	assert.NotEmpty(t, failures[0].Message())
+
+	suite, err = Load(gossfile, WithVars(map[string]interface{}{"dir": filepath.Join(dir, "x")}),
+		WithTemplateFunc("parentDir", filepath.Dir), WithSkipTags("slow"))
+	require.NoError(t, err)
+	events, err := suite.Events(context.Background())
+	require.NoError(t, err)
+	var results []Result
+	for event := range events {
+		assert.Len(t, event.RunID, 36)
+		results = append(results, event.Results...)
+	}
+	require.Len(t, results, 2)
+	for _, r := range results {
+		assert.Equal(t, StatusPass, r.Status)
+		assert.Equal(t, dir, r.ResourceID)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = suite.Run(ctx)
+	assert.Equal(t, context.Canceled, err)
+
+	_, err = Load(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,121 @@
+package goss
+
+import (
+	"time"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+)
+
+// Status is the outcome of a test
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip"
+	// StatusTimeout is the status of the tests of resources that hadn't
+	// finished by the deadline of the run
+	StatusTimeout Status = "timeout"
+	// StatusError is the status of tests that couldn't be checked, such as
+	// when the backend is unavailable, as opposed to StatusFail where the
+	// system doesn't match the expectation
+	StatusError Status = "error"
+)
+
+// Result is the result of a test of a resource: a property such as installed
+// of a package
+type Result struct {
+	ResourceType string
+	ResourceID   string
+	Title        string
+	Meta         map[string]interface{}
+	Property     string
+	Status       Status
+	// Warning is whether the test didn't pass but only warns, because it's
+	// quarantined, in a maintenance window, of a warn or info resource or
+	// already failed in the baseline
+	Warning bool
+	// Severity is the severity of the resource: fail, warn or info
+	Severity string
+	Expected []string
+	Found    []string
+	// Human is the explanation of a failure
+	Human string
+	// Err is why the test couldn't be checked, ErrCode its GOSS-E- code
+	Err        error
+	ErrCode    string
+	SkipReason string
+	Duration   time.Duration
+}
+
+func newResult(r resource.TestResult) Result {
+	status := StatusPass
+	switch r.Result {
+	case resource.FAIL:
+		status = StatusFail
+	case resource.SKIP:
+		status = StatusSkip
+	case resource.TIMEOUT:
+		status = StatusTimeout
+	case resource.ERROR:
+		status = StatusError
+	}
+	return Result{
+		ResourceType: r.ResourceType,
+		ResourceID:   r.ResourceId,
+		Title:        r.Title,
+		Meta:         r.Meta,
+		Property:     r.Property,
+		Status:       status,
+		Warning:      r.Warning(),
+		Severity:     r.Severity,
+		Expected:     r.Expected,
+		Found:        r.Found,
+		Human:        r.Human,
+		Err:          r.Err,
+		ErrCode:      util.ErrorCode(r.Err),
+		SkipReason:   r.SkipReason,
+		Duration:     r.Duration,
+	}
+}
+
+// Failed is whether the test didn't pass and isn't only a warning
+func (r Result) Failed() bool {
+	return r.Status != StatusPass && r.Status != StatusSkip && !r.Warning
+}
+
+// Message is what went wrong: the error, the explanation of the failure or
+// why the test was skipped
+func (r Result) Message() string {
+	switch {
+	case r.Err != nil:
+		return r.Err.Error()
+	case r.Status == StatusSkip:
+		return r.SkipReason
+	}
+	return r.Human
+}
+
+// Report is the results of a run
+type Report struct {
+	RunID    string
+	Results  []Result
+	Duration time.Duration
+}
+
+// Passed is whether no test failed, warnings aside
+func (r *Report) Passed() bool {
+	return len(r.Failures()) == 0
+}
+
+// Failures are the results of the tests that failed
+func (r *Report) Failures() []Result {
+	var failures []Result
+	for _, result := range r.Results {
+		if result.Failed() {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
@@ -0,0 +1,76 @@
+package goss
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/aelsabbahy/goss/outputs"
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+)
+
+// PostRunExec runs a program after each run with the results, as the document
+// of the json format, on its stdin
+type PostRunExec struct {
+	program string
+	err     error
+}
+
+// NewPostRunExec is the hook running program, nil when there's no program
+func NewPostRunExec(program string) *PostRunExec {
+	if program == "" {
+		return nil
+	}
+	return &PostRunExec{program: program}
+}
+
+// Results passes the results through and runs the program once the run
+// finished, before the output sees the end of the results. outConfig is that
+// of the run, for its timings and ID. Err is the error running the program.
+func (p *PostRunExec) Results(in <-chan []resource.TestResult, startTime time.Time, outConfig util.OutputConfig) <-chan []resource.TestResult {
+	if p == nil {
+		return in
+	}
+
+	out := make(chan []resource.TestResult)
+	go func() {
+		defer close(out)
+		var results [][]resource.TestResult
+		for resultGroup := range in {
+			results = append(results, resultGroup)
+			out <- resultGroup
+		}
+		var doc bytes.Buffer
+		exitCode := outputs.Json{}.Output(&doc, resultsChan(results), startTime, runOutputConfig(outConfig))
+		p.err = p.run(doc.Bytes(), exitCode, outConfig.RunID)
+	}()
+
+	return out
+}
+
+// Err is the error running the program after the last run
+func (p *PostRunExec) Err() error {
+	if p == nil {
+		return nil
+	}
+	return p.err
+}
+
+// run runs the program with doc on its stdin, the exit code of the run as
+// GOSS_EXIT_CODE and its ID as GOSS_RUN_ID. Its output goes to stderr so it
+// doesn't mix with the results.
+func (p *PostRunExec) run(doc []byte, exitCode int, runID string) error {
+	cmd := exec.Command(p.program)
+	cmd.Stdin = bytes.NewReader(doc)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), "GOSS_EXIT_CODE="+strconv.Itoa(exitCode), "GOSS_RUN_ID="+runID)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("post-run-exec %s: %v", p.program, err)
+	}
+	return nil
+}
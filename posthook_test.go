@@ -0,0 +1,48 @@
+package goss
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePostRunExec(t *testing.T) {
+	dir := t.TempDir()
+	program := filepath.Join(dir, "hook.sh")
+	script := "#!/bin/sh\ncat > " + filepath.Join(dir, "results.json") + "\necho $GOSS_EXIT_CODE > " + filepath.Join(dir, "exit-code") + "\n"
+	require.NoError(t, ioutil.WriteFile(program, []byte(script), 0755))
+
+	spec := filepath.Join("testdata", "failing.goss.yaml")
+	config, err := util.NewConfig(util.WithSpecFile(spec), util.WithOutputFormat("rspecish"), util.WithResultWriter(&bytes.Buffer{}),
+		util.WithPostRunExec(program))
+	require.NoError(t, err)
+	code, err := Validate(config, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 1, code)
+
+	doc, err := ioutil.ReadFile(filepath.Join(dir, "results.json"))
+	require.NoError(t, err)
+	var results struct {
+		Results []json.RawMessage      `json:"results"`
+		Summary map[string]interface{} `json:"summary"`
+	}
+	require.NoError(t, json.Unmarshal(doc, &results))
+	assert.Len(t, results.Results, 2)
+	assert.Equal(t, float64(2), results.Summary["failed-count"])
+	assert.Contains(t, results.Summary, "phase-durations")
+	assert.Len(t, results.Summary["run-id"], 36)
+	exitCode, err := ioutil.ReadFile(filepath.Join(dir, "exit-code"))
+	require.NoError(t, err)
+	assert.Equal(t, "1\n", string(exitCode))
+
+	require.NoError(t, ioutil.WriteFile(program, []byte("#!/bin/sh\nexit 3\n"), 0755))
+	_, err = Validate(config, time.Now())
+	assert.EqualError(t, err, "post-run-exec "+program+": exit status 3")
+}
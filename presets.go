@@ -0,0 +1,189 @@
+package goss
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// presetScheme prefixes the gossfiles of presets, such as
+// preset://ssh-hardening
+const presetScheme = "preset://"
+
+// preset is a gossfile shipped with goss, rendered with its vars
+type preset struct {
+	// vars are the defaults of the vars the gossfile is rendered with
+	vars     map[string]interface{}
+	gossfile string
+}
+
+// presets are the suites gossfiles include with preset://<name>
+var presets = map[string]preset{
+	"ssh-hardening": {
+		vars: map[string]interface{}{
+			"service":                 "sshd",
+			"port":                    22,
+			"config":                  "/etc/ssh/sshd_config",
+			"permit_root_login":       "no",
+			"password_authentication": "no",
+			"max_auth_tries":          4,
+		},
+		gossfile: `file:
+  {{ .Vars.config }}:
+    exists: true
+    owner: root
+    group: root
+    mode: {mode-at-most: "0600"}
+    contains:
+    - "/^\\s*PermitRootLogin\\s+{{ .Vars.permit_root_login }}\\s*$/"
+    - "/^\\s*PasswordAuthentication\\s+{{ .Vars.password_authentication }}\\s*$/"
+    - "/^\\s*PermitEmptyPasswords\\s+no\\s*$/"
+    - "/^\\s*MaxAuthTries\\s+{{ .Vars.max_auth_tries }}\\s*$/"
+    - "!/^\\s*Protocol\\s+1/"
+port:
+  tcp:{{ .Vars.port }}:
+    listening: true
+service:
+  {{ .Vars.service }}:
+    enabled: true
+    running: true
+`,
+	},
+	"docker-host": {
+		vars: map[string]interface{}{
+			"service":      "docker",
+			"socket":       "/var/run/docker.sock",
+			"socket_group": "docker",
+		},
+		gossfile: `service:
+  {{ .Vars.service }}:
+    enabled: true
+    running: true
+file:
+  {{ .Vars.socket }}:
+    exists: true
+    filetype: socket
+    owner: root
+    group: {{ .Vars.socket_group }}
+    mode: {mode-at-most: "0660"}
+group:
+  {{ .Vars.socket_group }}:
+    exists: true
+kernel-param:
+  net.ipv4.ip_forward:
+    value: "1"
+command:
+  docker info:
+    exit-status: 0
+`,
+	},
+	"k8s-node": {
+		vars: map[string]interface{}{
+			"container_runtime": "containerd",
+			"kubelet_port":      10250,
+			"healthz_port":      10248,
+		},
+		gossfile: `service:
+  kubelet:
+    enabled: true
+    running: true
+  {{ .Vars.container_runtime }}:
+    enabled: true
+    running: true
+port:
+  tcp:{{ .Vars.kubelet_port }}:
+    listening: true
+http:
+  http://127.0.0.1:{{ .Vars.healthz_port }}/healthz:
+    status: 200
+    body: [ok]
+    timeout: 5000
+file:
+  /etc/kubernetes/kubelet.conf:
+    exists: true
+    mode: {mode-at-most: "0600"}
+  /var/lib/kubelet/config.yaml:
+    exists: true
+kernel-param:
+  net.ipv4.ip_forward:
+    value: "1"
+  net.bridge.bridge-nf-call-iptables:
+    value: "1"
+command:
+  swapon --noheadings --show:
+    exit-status: 0
+    stdout-value: ""
+`,
+	},
+	"web-server": {
+		vars: map[string]interface{}{
+			"service": "nginx",
+			"port":    80,
+			"url":     "http://localhost/",
+			"status":  200,
+		},
+		gossfile: `service:
+  {{ .Vars.service }}:
+    enabled: true
+    running: true
+process:
+  {{ .Vars.service }}:
+    running: true
+port:
+  tcp:{{ .Vars.port }}:
+    listening: true
+http:
+  {{ .Vars.url }}:
+    status: {{ .Vars.status }}
+    timeout: 5000
+`,
+	},
+}
+
+// isPreset is whether path is the gossfile of a preset
+func isPreset(path string) bool {
+	return strings.HasPrefix(path, presetScheme)
+}
+
+// readPreset is the gossfile of the preset of path rendered with its vars,
+// those of vars replacing the defaults
+func readPreset(path string, vars map[string]interface{}) ([]byte, error) {
+	name := strings.TrimPrefix(path, presetScheme)
+	p, ok := presets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown preset %q, the presets are: %s", name, strings.Join(presetNames(), ", "))
+	}
+	merged := make(map[string]interface{}, len(p.vars))
+	for k, v := range p.vars {
+		merged[k] = v
+	}
+	for k, v := range vars {
+		if _, ok := p.vars[k]; !ok {
+			return nil, fmt.Errorf("unknown var %q of preset %s, its vars are: %s", k, name, strings.Join(presetVars(p), ", "))
+		}
+		merged[k] = v
+	}
+	data, err := varsTemplateFilter(merged, nil)([]byte(p.gossfile))
+	if err != nil {
+		return nil, fmt.Errorf("preset %s: %v", name, err)
+	}
+	return data, nil
+}
+
+func presetNames() []string {
+	var names []string
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func presetVars(p preset) []string {
+	var vars []string
+	for k := range p.vars {
+		vars = append(vars, k)
+	}
+	sort.Strings(vars)
+	return vars
+}
@@ -0,0 +1,40 @@
+package goss
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresets(t *testing.T) {
+	outStoreFormat = YAML
+	currentTemplateFilter = nil
+	merge := func(gossfile string) (GossConfig, error) {
+		j, err := ReadJSONData([]byte(gossfile), false)
+		require.NoError(t, err)
+		return mergeJSONData(j, 0, t.TempDir())
+	}
+
+	for _, name := range presetNames() {
+		g, err := merge("gossfile:\n  preset://" + name + ": {}\n")
+		require.NoError(t, err, name)
+		assert.NotEmpty(t, g.Resources(), name)
+	}
+
+	g, err := merge("gossfile:\n  preset://web-server:\n    vars: {service: httpd, port: 8080}\n" +
+		"service:\n  httpd:\n    enabled: false\n    running: true\n")
+	require.NoError(t, err)
+	assert.Contains(t, g.Ports, "tcp:8080")
+	assert.Contains(t, g.Processes, "httpd")
+	assert.Contains(t, g.HTTPs, "http://localhost/")
+	require.Contains(t, g.Services, "httpd")
+	assert.Equal(t, false, g.Services["httpd"].Enabled, "the gossfile replaces the resources of its presets")
+
+	_, err = merge("gossfile:\n  preset://web-server:\n    vars: {srvice: httpd}\n")
+	assert.EqualError(t, err, `unknown var "srvice" of preset web-server, its vars are: port, service, status, url`)
+	_, err = merge("gossfile:\n  preset://mail-server: {}\n")
+	assert.Error(t, err)
+	_, err = merge("gossfile:\n  other.yaml:\n    vars: {service: httpd}\n")
+	assert.Error(t, err, "vars are only those of presets")
+}
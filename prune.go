@@ -0,0 +1,105 @@
+package goss
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+)
+
+// Prune lists the resources of the gossfile of c whose targets, such as files
+// and packages, no longer exist on the system, see resource.TargetMissing.
+// The gossfiles it includes aren't, they're pruned on their own. With remove
+// the resources are deleted from the gossfile once the answer read from in
+// confirms it, or right away with yes. The exit code is 1 when dead resources
+// are left in the gossfile.
+func Prune(c *util.Config, remove, yes bool, in io.Reader) (int, error) {
+	var w io.Writer = os.Stdout
+	if c.OutputWriter != nil {
+		w = c.OutputWriter
+	}
+	var err error
+	currentTemplateFilter, err = configTemplateFilter(c)
+	if err != nil {
+		return 1, err
+	}
+	outStoreFormat, err = getStoreFormatFromFileName(c.Spec)
+	if err != nil {
+		return 1, err
+	}
+	gossConfig, err := ReadJSON(c.Spec)
+	if err != nil {
+		return 1, err
+	}
+	sys, err := newSystem(c)
+	if err != nil {
+		return 1, err
+	}
+
+	keys := resourceTypeKeys()
+	dead := make(map[resource.Resource]bool)
+	for _, r := range gossConfig.Resources() {
+		missing, err := resource.TargetMissing(r, sys)
+		if err != nil {
+			fmt.Fprintf(w, "%s: can't tell whether it exists: %v\n", requiresRef(keys, r), err)
+			continue
+		}
+		if missing {
+			fmt.Fprintf(w, "%s: doesn't exist\n", requiresRef(keys, r))
+			dead[r] = true
+		}
+	}
+	if len(dead) == 0 {
+		fmt.Fprintln(w, "No resources to prune")
+		return 0, nil
+	}
+	if !remove {
+		return 1, nil
+	}
+
+	// Writing the gossfile back would render its templates
+	raw, err := ioutil.ReadFile(c.Spec)
+	if err != nil {
+		return 1, err
+	}
+	if bytes.Contains(raw, []byte("{{")) {
+		return 1, fmt.Errorf("%s is a template, remove the resources from it by hand", c.Spec)
+	}
+	if !yes {
+		fmt.Fprintf(w, "Remove %d resources from %s? [y/N] ", len(dead), c.Spec)
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			fmt.Fprintln(w, "Nothing removed")
+			return 1, nil
+		}
+	}
+	removeResources(&gossConfig, dead)
+	if err := WriteJSON(c.Spec, gossConfig); err != nil {
+		return 1, err
+	}
+	fmt.Fprintf(w, "Removed %d resources from %s\n", len(dead), c.Spec)
+	return 0, nil
+}
+
+// removeResources deletes the resources of remove from gossConfig
+func removeResources(gossConfig *GossConfig, remove map[resource.Resource]bool) {
+	v := reflect.ValueOf(gossConfig).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if f.Kind() != reflect.Map || !f.CanSet() {
+			continue
+		}
+		for _, k := range f.MapKeys() {
+			if r, ok := f.MapIndex(k).Interface().(resource.Resource); ok && remove[r] {
+				f.SetMapIndex(k, reflect.Value{})
+			}
+		}
+	}
+}
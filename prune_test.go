@@ -0,0 +1,64 @@
+package goss
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aelsabbahy/goss/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	kept := filepath.Join(dir, "kept.conf")
+	require.NoError(t, ioutil.WriteFile(kept, []byte("port: 8080\n"), 0644))
+	deleted := filepath.Join(dir, "deleted.conf")
+	absent := filepath.Join(dir, "absent.conf")
+	spec := filepath.Join(dir, "goss.yaml")
+	gossfile := "file:\n" +
+		"  " + kept + ":\n    exists: true\n" +
+		"  " + deleted + ":\n    exists: true\n    mode: \"0644\"\n" +
+		"  " + absent + ":\n    exists: false\n" +
+		"command:\n  echo hi:\n    exit-status: 0\n"
+	require.NoError(t, ioutil.WriteFile(spec, []byte(gossfile), 0644))
+	c, err := util.NewConfig(util.WithSpecFile(spec))
+	require.NoError(t, err)
+	var out bytes.Buffer
+	c.OutputWriter = &out
+
+	code, err := Prune(c, false, false, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "file:"+deleted+": doesn't exist\n", out.String())
+
+	out.Reset()
+	code, err = Prune(c, true, false, strings.NewReader("n\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, out.String(), "Nothing removed")
+
+	out.Reset()
+	code, err = Prune(c, true, false, strings.NewReader("y\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 0, code)
+	g, err := ReadJSON(spec)
+	require.NoError(t, err)
+	assert.Contains(t, g.Files, kept)
+	assert.Contains(t, g.Files, absent)
+	assert.NotContains(t, g.Files, deleted)
+	assert.Contains(t, g.Commands, "echo hi")
+
+	out.Reset()
+	code, err = Prune(c, false, false, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "No resources to prune\n", out.String())
+
+	require.NoError(t, ioutil.WriteFile(spec, []byte("file:\n  {{ .Env.HOME }}/gone:\n    exists: true\n"), 0644))
+	_, err = Prune(c, true, true, nil)
+	assert.Error(t, err, "templates aren't rewritten")
+}
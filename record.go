@@ -0,0 +1,52 @@
+package goss
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aelsabbahy/goss/resource"
+)
+
+// useFacts starts recording the values the tests read with --record, or
+// replaying the ones of --replay. The recording to write is nil unless
+// recording.
+func useFacts(record, replay, unprivilegedUser string) (*resource.Facts, error) {
+	switch {
+	case record == "" && replay == "":
+		return nil, nil
+	case record != "" && replay != "":
+		return nil, fmt.Errorf("--record and --replay can't be used together")
+	case unprivilegedUser != "":
+		// The values read by the unprivileged worker stay in its process
+		return nil, fmt.Errorf("--record and --replay can't be used with --unprivileged-user")
+	}
+
+	if record != "" {
+		facts := resource.NewFacts()
+		resource.UseFacts(facts)
+		return facts, nil
+	}
+	fh, err := os.Open(replay)
+	if err != nil {
+		return nil, fmt.Errorf("replay file error: %v", err)
+	}
+	defer fh.Close()
+	facts, err := resource.LoadFacts(fh)
+	if err != nil {
+		return nil, err
+	}
+	resource.UseFacts(facts)
+	return nil, nil
+}
+
+func writeFacts(file string, facts *resource.Facts) error {
+	fh, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("record file error: %v", err)
+	}
+	if err := facts.Write(fh); err != nil {
+		fh.Close()
+		return fmt.Errorf("record file error: %v", err)
+	}
+	return fh.Close()
+}
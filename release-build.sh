@@ -7,6 +7,12 @@ version_stamp="${TRAVIS_TAG:-"0.0.0"}"
 # Split platform_spec into platform/arch segments
 IFS='- ' read -r -a segments <<< "${platform_spec}"
 
+# A -slim suffix leaves the optional subsystems out, see the README
+tags=""
+if [[ "${platform_spec}" == *-slim ]]; then
+  tags="slim"
+fi
+
 os="${segments[0]}"
 arch="${segments[1]}"
 if [[ "${segments[0]}" == "alpha" ]]; then
@@ -22,6 +28,7 @@ fi
 output="${output_dir}/${output_fname}"
 
 GOOS="${os}" GOARCH="${arch}" CGO_ENABLED=0 go build \
+  -tags "${tags}" \
   -ldflags "-X main.version=${version_stamp} -s -w" \
   -o "${output}" \
   github.com/aelsabbahy/goss/cmd/goss
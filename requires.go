@@ -0,0 +1,117 @@
+package goss
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aelsabbahy/goss/resource"
+)
+
+// resourceTypeKeys maps the type names of resources to their gossfile keys
+func resourceTypeKeys() map[string]string {
+	keys := make(map[string]string)
+	for key, name := range resourceTypeNames() {
+		keys[name] = key
+	}
+	return keys
+}
+
+// requiresRef is how the requires of other resources name r, such as
+// service:nginx
+func requiresRef(keys map[string]string, r resource.Resource) string {
+	return keys[reflect.TypeOf(r).Elem().Name()] + ":" + r.(resource.ResourceRead).ID()
+}
+
+// resolveRequires is the indexes of the resources each of resources requires,
+// and the order to validate resources in: theirs, with every resource after
+// its requirements. A requirement is <gossfile key>:<id>, or the id of a
+// single resource. One that isn't among resources is an error unless
+// missingOK, as when the run doesn't include it, and a cycle always is.
+func resolveRequires(resources []resource.Resource, missingOK bool) ([][]int, []int, error) {
+	keys := resourceTypeKeys()
+	refs := make([]string, len(resources))
+	byRef := make(map[string]int, len(resources))
+	byID := make(map[string][]int, len(resources))
+	for i, r := range resources {
+		refs[i] = requiresRef(keys, r)
+		byRef[refs[i]] = i
+		id := r.(resource.ResourceRead).ID()
+		byID[id] = append(byID[id], i)
+	}
+
+	reqs := make([][]int, len(resources))
+	for i, r := range resources {
+		for _, ref := range r.(resource.ResourceRead).GetRequires() {
+			j, ok := byRef[ref]
+			if !ok {
+				switch ids := byID[ref]; {
+				case len(ids) == 1:
+					j, ok = ids[0], true
+				case len(ids) > 1:
+					return nil, nil, fmt.Errorf("%s requires %s, which is the id of several resources, use <type>:<id>", refs[i], ref)
+				}
+			}
+			if !ok {
+				if missingOK {
+					continue
+				}
+				return nil, nil, fmt.Errorf("%s requires %s, which isn't a resource of the gossfile", refs[i], ref)
+			}
+			reqs[i] = append(reqs[i], j)
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(resources))
+	order := make([]int, 0, len(resources))
+	var path []int
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			var cycle []string
+			for k := len(path) - 1; k >= 0; k-- {
+				cycle = append([]string{refs[path[k]]}, cycle...)
+				if path[k] == i {
+					break
+				}
+			}
+			return fmt.Errorf("requires cycle: %s -> %s", strings.Join(cycle, " -> "), refs[i])
+		}
+		state[i] = visiting
+		path = append(path, i)
+		for _, j := range reqs[i] {
+			if err := visit(j); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		order = append(order, i)
+		return nil
+	}
+	for i := range resources {
+		if err := visit(i); err != nil {
+			return nil, nil, err
+		}
+	}
+	return reqs, order, nil
+}
+
+// requiredRefs are the requires of the resources of gossConfig, as written
+func requiredRefs(gossConfig GossConfig) map[string]bool {
+	refs := make(map[string]bool)
+	for _, r := range gossConfig.Resources() {
+		for _, ref := range r.(resource.ResourceRead).GetRequires() {
+			refs[ref] = true
+		}
+	}
+	return refs
+}
@@ -0,0 +1,87 @@
+package goss
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/system"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveRequires(t *testing.T) {
+	resolve := func(gossfile string, missingOK bool) ([]string, error) {
+		g, err := ReadJSONData([]byte(gossfile), true)
+		checkErr(t, err, "reading gossfile failed")
+		resources := g.Resources()
+		_, order, err := resolveRequires(resources, missingOK)
+		var refs []string
+		for _, i := range order {
+			refs = append(refs, requiresRef(resourceTypeKeys(), resources[i]))
+		}
+		return refs, err
+	}
+
+	order, err := resolve(`{
+		"http": {"http://localhost": {"status": 200, "requires": ["service:nginx", "80"]}},
+		"port": {"80": {"listening": true, "requires": ["nginx"]}},
+		"service": {"nginx": {"running": true}}
+	}`, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"service:nginx", "port:80", "http:http://localhost"}, order)
+
+	_, err = resolve(`{"port": {"80": {"listening": true, "requires": ["service:nginx"]}}}`, false)
+	assert.EqualError(t, err, "port:80 requires service:nginx, which isn't a resource of the gossfile")
+	_, err = resolve(`{"port": {"80": {"listening": true, "requires": ["service:nginx"]}}}`, true)
+	assert.NoError(t, err)
+
+	_, err = resolve(`{
+		"port": {"80": {"listening": true, "requires": ["nginx"]}},
+		"service": {"nginx": {"running": true}},
+		"process": {"nginx": {"running": true}}
+	}`, true)
+	assert.EqualError(t, err, "port:80 requires nginx, which is the id of several resources, use <type>:<id>")
+
+	_, err = resolve(`{
+		"port": {"80": {"listening": true, "requires": ["service:nginx"]}},
+		"service": {"nginx": {"running": true, "requires": ["port:80"]}}
+	}`, true)
+	assert.Error(t, err)
+	assert.Regexp(t, "^requires cycle: (port:80 -> service:nginx -> port:80|service:nginx -> port:80 -> service:nginx)$", err.Error())
+
+	_, err = resolve(`{"service": {"nginx": {"running": true, "requires": ["nginx"]}}}`, true)
+	assert.EqualError(t, err, "requires cycle: service:nginx -> service:nginx")
+}
+
+func TestValidateRequires(t *testing.T) {
+	g, err := ReadJSONData([]byte(`{"command": {
+		"broken": {"exec": "false", "exit-status": 0},
+		"working": {"exec": "true", "exit-status": 0},
+		"needs-broken": {"exec": "true", "exit-status": 0, "requires": ["broken"]},
+		"needs-skipped": {"exec": "true", "exit-status": 0, "requires": ["command:needs-broken"]},
+		"needs-working": {"exec": "true", "exit-status": 0, "requires": ["working", "file:/not/in/the/run"]}
+	}}`), true)
+	checkErr(t, err, "reading gossfile failed")
+
+	for _, concurrency := range []Concurrency{{Max: 1}, {Max: 10, Types: map[string]int{"command": 2}}} {
+		results := map[string]resource.TestResult{}
+		for rg := range validate(system.New(""), g, concurrency, time.Now().Add(10*time.Second)) {
+			for _, r := range rg {
+				results[r.ResourceId] = r
+			}
+		}
+		for id, want := range map[string]int{"broken": resource.FAIL, "working": resource.SUCCESS, "needs-working": resource.SUCCESS} {
+			if r := results[id]; r.Result != want {
+				t.Errorf("%s: got result %d, want %d", id, r.Result, want)
+			}
+		}
+		for id, reason := range map[string]string{
+			"needs-broken":  "requires command:broken, which didn't pass",
+			"needs-skipped": "requires command:needs-broken, which didn't pass",
+		} {
+			if r := results[id]; r.Result != resource.SKIP || r.Property != "requires" || r.SkipReason != reason {
+				t.Errorf("%s: got result %d, %q, want skipped: %s", id, r.Result, r.SkipReason, reason)
+			}
+		}
+	}
+}
@@ -8,20 +8,28 @@ import (
 )
 
 type Addr struct {
-	Title        string  `json:"title,omitempty" yaml:"title,omitempty"`
-	Meta         meta    `json:"meta,omitempty" yaml:"meta,omitempty"`
-	Address      string  `json:"-" yaml:"-"`
-	LocalAddress string  `json:"local-address,omitempty" yaml:"local-address,omitempty"`
-	Reachable    matcher `json:"reachable" yaml:"reachable"`
-	Timeout      int     `json:"timeout" yaml:"timeout"`
+	Title        string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta         meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags         []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires     []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity     string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Address      string   `json:"-" yaml:"-"`
+	LocalAddress string   `json:"local-address,omitempty" yaml:"local-address,omitempty"`
+	Reachable    matcher  `json:"reachable" yaml:"reachable"`
+	Timeout      int      `json:"timeout" yaml:"timeout"`
+	IPVersion    matcher  `json:"ip-version,omitempty" yaml:"ip-version,omitempty"`
+	Netns        string   `json:"netns,omitempty" yaml:"netns,omitempty"`
 }
 
 func (a *Addr) ID() string      { return a.Address }
 func (a *Addr) SetID(id string) { a.Address = id }
 
 // FIXME: Can this be refactored?
-func (r *Addr) GetTitle() string { return r.Title }
-func (r *Addr) GetMeta() meta    { return r.Meta }
+func (r *Addr) GetTitle() string      { return r.Title }
+func (r *Addr) GetMeta() meta         { return r.Meta }
+func (r *Addr) GetTags() []string     { return r.Tags }
+func (r *Addr) GetRequires() []string { return r.Requires }
+func (r *Addr) GetSeverity() string   { return r.Severity }
 
 func (a *Addr) Validate(sys *system.System) []TestResult {
 	skip := false
@@ -29,10 +37,15 @@ func (a *Addr) Validate(sys *system.System) []TestResult {
 		a.Timeout = 500
 	}
 
-	sysAddr := sys.NewAddr(a.Address, sys, util.Config{Timeout: time.Duration(a.Timeout) * time.Millisecond, LocalAddress: a.LocalAddress})
+	version, err := ipVersion(a.IPVersion)
+	sysAddr := sys.NewAddr(a.Address, sys, util.Config{Timeout: time.Duration(a.Timeout) * time.Millisecond, LocalAddress: a.LocalAddress, IPVersion: version, Netns: a.Netns})
+	reachable := sysAddr.Reachable
+	if err != nil {
+		reachable = func() (bool, error) { return false, err }
+	}
 
 	var results []TestResult
-	results = append(results, ValidateValue(a, "reachable", a.Reachable, sysAddr.Reachable, skip))
+	results = append(results, ValidateValue(a, "reachable", a.Reachable, reachable, skip))
 	return results
 }
 
@@ -0,0 +1,78 @@
+package resource
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// backendMissing reports whether err is that of a backend, such as the
+// service manager, the container engine or a package manager, that doesn't
+// exist on the host
+func backendMissing(err error) bool {
+	code := util.ErrorCode(err)
+	return code == util.ErrCodeBackendNotFound || code == util.ErrCodePkgBackendNotFound
+}
+
+// checkIfBackendMissing errors when action isn't a valid if-backend-missing
+func checkIfBackendMissing(action string) error {
+	switch action {
+	case "", "error", "skip", "fail":
+		return nil
+	}
+	return fmt.Errorf("unknown if-backend-missing %q, must be skip, error or fail", action)
+}
+
+// ifBackendMissing applies action, the if-backend-missing of res, to its
+// results when its backend doesn't exist: they're skipped with skip, failed
+// with fail and left as errors with error, the default, which is how a
+// gossfile shared by minimal containers and full hosts declares what the
+// containers lack
+func ifBackendMissing(res ResourceRead, action string, results []TestResult, startTime time.Time) []TestResult {
+	if err := checkIfBackendMissing(action); err != nil {
+		return []TestResult{ConfigErrorResult(res, "if-backend-missing", err, startTime)}
+	}
+	if action == "" || action == "error" {
+		return results
+	}
+	var missing error
+	for _, r := range results {
+		if backendMissing(r.Err) {
+			missing = r.Err
+			break
+		}
+	}
+	if missing == nil {
+		return results
+	}
+	for i := range results {
+		r := &results[i]
+		switch {
+		case action == "skip":
+			r.Successful, r.Result, r.Err, r.SkipReason = true, SKIP, nil, missing.Error()
+		case backendMissing(r.Err):
+			r.Successful, r.Result, r.Err, r.Human = false, FAIL, nil, missing.Error()
+		}
+	}
+	return results
+}
+
+// missingBackend is the service or package of a backend that doesn't exist,
+// every value is err
+type missingBackend struct {
+	name string
+	err  error
+}
+
+func (b *missingBackend) Service() string                      { return b.name }
+func (b *missingBackend) Name() string                         { return b.name }
+func (b *missingBackend) Exists() (bool, error)                { return false, b.err }
+func (b *missingBackend) Enabled() (bool, error)               { return false, b.err }
+func (b *missingBackend) Running() (bool, error)               { return false, b.err }
+func (b *missingBackend) Masked() (bool, error)                { return false, b.err }
+func (b *missingBackend) Failed() (bool, error)                { return false, b.err }
+func (b *missingBackend) Restarts() (int, error)               { return 0, b.err }
+func (b *missingBackend) Property(name string) (string, error) { return "", b.err }
+func (b *missingBackend) Installed() (bool, error)             { return false, b.err }
+func (b *missingBackend) Versions() ([]string, error)          { return nil, b.err }
@@ -0,0 +1,40 @@
+package resource
+
+import (
+	"testing"
+
+	"github.com/aelsabbahy/goss/system"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIfBackendMissing(t *testing.T) {
+	sys := &system.System{NewPackage: system.NewNullPackage}
+	validate := func(action string) []TestResult {
+		p := &Package{Name: "nginx", Installed: true, Versions: []interface{}{"1.0"}, IfBackendMissing: action}
+		return p.Validate(sys)
+	}
+
+	results := validate("")
+	assert.Equal(t, ERROR, results[0].Result)
+	assert.Equal(t, SKIP, results[1].Result)
+
+	results = validate("skip")
+	for _, r := range results {
+		assert.Equal(t, SKIP, r.Result)
+		assert.True(t, r.Successful)
+		assert.Nil(t, r.Err)
+		assert.Equal(t, system.ErrNullPackage.Error(), r.SkipReason)
+	}
+
+	results = validate("fail")
+	assert.Equal(t, FAIL, results[0].Result)
+	assert.False(t, results[0].Successful)
+	assert.Nil(t, results[0].Err)
+	assert.Equal(t, system.ErrNullPackage.Error(), results[0].Human)
+	assert.Equal(t, SKIP, results[1].Result)
+
+	results = validate("ignore")
+	assert.Len(t, results, 1)
+	assert.Equal(t, "if-backend-missing", results[0].Property)
+	assert.EqualError(t, results[0].Err, `unknown if-backend-missing "ignore", must be skip, error or fail`)
+}
@@ -0,0 +1,68 @@
+package resource
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+type Bandwidth struct {
+	Title      string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta       meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags       []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires   []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity   string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Target     string   `json:"-" yaml:"-"`
+	Throughput matcher  `json:"throughput,omitempty" yaml:"throughput,omitempty"`
+	Loss       matcher  `json:"loss,omitempty" yaml:"loss,omitempty"`
+	Duration   int      `json:"duration,omitempty" yaml:"duration,omitempty"`
+	MaxSize    string   `json:"max-size,omitempty" yaml:"max-size,omitempty"`
+	UDP        bool     `json:"udp,omitempty" yaml:"udp,omitempty"`
+	Bitrate    string   `json:"bitrate,omitempty" yaml:"bitrate,omitempty"`
+	Reverse    bool     `json:"reverse,omitempty" yaml:"reverse,omitempty"`
+	Timeout    int      `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Skip       bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+func (b *Bandwidth) ID() string      { return b.Target }
+func (b *Bandwidth) SetID(id string) { b.Target = id }
+
+func (b *Bandwidth) GetTitle() string      { return b.Title }
+func (b *Bandwidth) GetMeta() meta         { return b.Meta }
+func (b *Bandwidth) GetTags() []string     { return b.Tags }
+func (b *Bandwidth) GetRequires() []string { return b.Requires }
+func (b *Bandwidth) GetSeverity() string   { return b.Severity }
+
+func (b *Bandwidth) Validate(sys *system.System) []TestResult {
+	skip := b.Skip
+	sysBandwidth := sys.NewBandwidth(b.Target, sys, util.Config{Timeout: time.Duration(b.Timeout) * time.Millisecond})
+	transfer := system.BandwidthTransfer{Duration: b.Duration, UDP: b.UDP, Bitrate: b.Bitrate, Reverse: b.Reverse}
+	throughput, loss := sysBandwidth.Throughput, sysBandwidth.Loss
+	if b.MaxSize != "" {
+		maxBytes, ok := parseSize(b.MaxSize)
+		if !ok {
+			err := util.NewCodedError(util.ErrCodeConfigInvalid, fmt.Errorf("max-size must be a size such as 10MB or 64MiB, got: %s", b.MaxSize))
+			throughput = func() (float64, error) { return 0, err }
+			loss = throughput
+		}
+		transfer.MaxBytes = int64(maxBytes)
+	}
+	sysBandwidth.SetTransfer(transfer)
+
+	var results []TestResult
+	if b.Throughput != nil {
+		results = append(results, ValidateValue(b, "throughput", b.Throughput, throughput, skip))
+	}
+	if b.Loss != nil {
+		results = append(results, ValidateValue(b, "loss", b.Loss, loss, skip))
+	}
+	return results
+}
+
+// NewBandwidth can't add a transfer from the command line, its throughput
+// is the capacity the link was provisioned with rather than a measure of it
+func NewBandwidth(sysBandwidth system.Bandwidth, config util.Config) (*Bandwidth, error) {
+	return nil, fmt.Errorf("bandwidth %s: bandwidth checks can't be added, write the target and the expected throughput in the gossfile", sysBandwidth.Target())
+}
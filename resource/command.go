@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 	"time"
 
@@ -12,22 +13,38 @@ import (
 )
 
 type Command struct {
-	Title      string   `json:"title,omitempty" yaml:"title,omitempty"`
-	Meta       meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
-	Command    string   `json:"-" yaml:"-"`
-	Exec       string   `json:"exec,omitempty" yaml:"exec,omitempty"`
-	ExitStatus matcher  `json:"exit-status" yaml:"exit-status"`
-	Stdout     []string `json:"stdout" yaml:"stdout"`
-	Stderr     []string `json:"stderr" yaml:"stderr"`
-	Timeout    int      `json:"timeout" yaml:"timeout"`
-	Skip       bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
+	Title        string             `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta         meta               `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags         []string           `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires     []string           `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity     string             `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Command      string             `json:"-" yaml:"-"`
+	Exec         string             `json:"exec,omitempty" yaml:"exec,omitempty"`
+	Shell        string             `json:"shell,omitempty" yaml:"shell,omitempty"`
+	Stdin        string             `json:"stdin,omitempty" yaml:"stdin,omitempty"`
+	Env          map[string]string  `json:"env,omitempty" yaml:"env,omitempty"`
+	Dir          string             `json:"dir,omitempty" yaml:"dir,omitempty"`
+	Unprivileged bool               `json:"unprivileged,omitempty" yaml:"unprivileged,omitempty"`
+	Sandbox      bool               `json:"sandbox,omitempty" yaml:"sandbox,omitempty"`
+	ExitStatus   matcher            `json:"exit-status" yaml:"exit-status"`
+	Stdout       []string           `json:"stdout" yaml:"stdout"`
+	StdoutValue  matcher            `json:"stdout-value,omitempty" yaml:"stdout-value,omitempty"`
+	StdoutJSON   map[string]matcher `json:"stdout-json,omitempty" yaml:"stdout-json,omitempty"`
+	StdoutKV     map[string]matcher `json:"stdout-kv,omitempty" yaml:"stdout-kv,omitempty"`
+	Stderr       []string           `json:"stderr" yaml:"stderr"`
+	Output       []string           `json:"output,omitempty" yaml:"output,omitempty"`
+	Timeout      int                `json:"timeout" yaml:"timeout"`
+	Skip         bool               `json:"skip,omitempty" yaml:"skip,omitempty"`
 }
 
 func (c *Command) ID() string      { return c.Command }
 func (c *Command) SetID(id string) { c.Command = id }
 
-func (c *Command) GetTitle() string { return c.Title }
-func (c *Command) GetMeta() meta    { return c.Meta }
+func (c *Command) GetTitle() string      { return c.Title }
+func (c *Command) GetMeta() meta         { return c.Meta }
+func (c *Command) GetTags() []string     { return c.Tags }
+func (c *Command) GetRequires() []string { return c.Requires }
+func (c *Command) GetSeverity() string   { return c.Severity }
 func (c *Command) GetExec() string {
 	if c.Exec != "" {
 		return c.Exec
@@ -45,19 +62,56 @@ func (c *Command) Validate(sys *system.System) []TestResult {
 	}
 
 	var results []TestResult
-	sysCommand := sys.NewCommand(c.GetExec(), sys, util.Config{Timeout: time.Duration(c.Timeout) * time.Millisecond})
+	sysCommand := sys.NewCommand(c.GetExec(), sys, util.Config{
+		Timeout:        time.Duration(c.Timeout) * time.Millisecond,
+		Shell:          c.Shell,
+		Stdin:          c.Stdin,
+		Env:            c.env(),
+		Dir:            c.Dir,
+		DropPrivileges: c.Unprivileged,
+		Sandbox:        c.Sandbox,
+	})
 
 	cExitStatus := deprecateAtoI(c.ExitStatus, fmt.Sprintf("%s: command.exit-status", c.Command))
 	results = append(results, ValidateValue(c, "exit-status", cExitStatus, sysCommand.ExitStatus, skip))
 	if len(c.Stdout) > 0 {
 		results = append(results, ValidateContains(c, "stdout", c.Stdout, sysCommand.Stdout, skip))
 	}
+	if c.StdoutValue != nil {
+		stdout := &wholeOutput{read: sysCommand.Stdout}
+		results = append(results, ValidateValue(c, "stdout-value", c.StdoutValue, stdout.String, skip))
+	}
+	if len(c.StdoutJSON) > 0 {
+		doc := &structuredOutput{read: sysCommand.Stdout, parse: parseJSON}
+		for _, path := range sortedMatcherKeys(c.StdoutJSON) {
+			results = append(results, ValidateValue(c, "stdout-json["+path+"]", c.StdoutJSON[path], doc.field(path, jsonPath), skip))
+		}
+	}
+	if len(c.StdoutKV) > 0 {
+		doc := &structuredOutput{read: sysCommand.Stdout, parse: parseKeyValues}
+		for _, key := range sortedMatcherKeys(c.StdoutKV) {
+			results = append(results, ValidateValue(c, "stdout-kv["+key+"]", c.StdoutKV[key], doc.field(key, keyValue), skip))
+		}
+	}
 	if len(c.Stderr) > 0 {
 		results = append(results, ValidateContains(c, "stderr", c.Stderr, sysCommand.Stderr, skip))
 	}
+	if len(c.Output) > 0 {
+		results = append(results, ValidateContains(c, "output", c.Output, sysCommand.Output, skip))
+	}
 	return results
 }
 
+// env is Env as KEY=value pairs sorted by key
+func (c *Command) env() []string {
+	var env []string
+	for k, v := range c.Env {
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
+	return env
+}
+
 func NewCommand(sysCommand system.Command, config util.Config) (*Command, error) {
 	command := sysCommand.Command()
 	exitStatus, err := sysCommand.ExitStatus()
@@ -0,0 +1,114 @@
+package resource
+
+import (
+	"time"
+
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+type Container struct {
+	Title        string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta         meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags         []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires     []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity     string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Name         string   `json:"-" yaml:"-"`
+	Exists       matcher  `json:"exists" yaml:"exists"`
+	Running      matcher  `json:"running,omitempty" yaml:"running,omitempty"`
+	Health       matcher  `json:"health,omitempty" yaml:"health,omitempty"`
+	Image        matcher  `json:"image,omitempty" yaml:"image,omitempty"`
+	RestartCount matcher  `json:"restart-count,omitempty" yaml:"restart-count,omitempty"`
+	Ports        matcher  `json:"ports,omitempty" yaml:"ports,omitempty"`
+	Mounts       matcher  `json:"mounts,omitempty" yaml:"mounts,omitempty"`
+	Socket       string   `json:"socket,omitempty" yaml:"socket,omitempty"`
+	Timeout      int      `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// IfBackendMissing is how the container is reported on hosts without
+	// docker or podman: skip, error or fail
+	IfBackendMissing string `json:"if-backend-missing,omitempty" yaml:"if-backend-missing,omitempty"`
+	Skip             bool   `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+func (c *Container) ID() string      { return c.Name }
+func (c *Container) SetID(id string) { c.Name = id }
+
+func (c *Container) GetTitle() string      { return c.Title }
+func (c *Container) GetMeta() meta         { return c.Meta }
+func (c *Container) GetTags() []string     { return c.Tags }
+func (c *Container) GetRequires() []string { return c.Requires }
+func (c *Container) GetSeverity() string   { return c.Severity }
+
+func (c *Container) Validate(sys *system.System) []TestResult {
+	skip := c.Skip
+	startTime := time.Now()
+	if c.Timeout == 0 {
+		c.Timeout = 10000
+	}
+	sysContainer := sys.NewContainer(c.Name, sys, util.Config{Timeout: time.Duration(c.Timeout) * time.Millisecond})
+	sysContainer.SetSocket(c.Socket)
+
+	var results []TestResult
+	results = append(results, ValidateValue(c, "exists", c.Exists, sysContainer.Exists, skip))
+	if shouldSkip(results) {
+		skip = true
+	}
+	if c.Running != nil {
+		results = append(results, ValidateValue(c, "running", c.Running, sysContainer.Running, skip))
+	}
+	if c.Health != nil {
+		results = append(results, ValidateValue(c, "health", c.Health, sysContainer.Health, skip))
+	}
+	if c.Image != nil {
+		results = append(results, ValidateValue(c, "image", c.Image, sysContainer.Image, skip))
+	}
+	if c.RestartCount != nil {
+		results = append(results, ValidateValue(c, "restart-count", c.RestartCount, sysContainer.RestartCount, skip))
+	}
+	if c.Ports != nil {
+		results = append(results, ValidateValue(c, "ports", c.Ports, sysContainer.Ports, skip))
+	}
+	if c.Mounts != nil {
+		results = append(results, ValidateValue(c, "mounts", c.Mounts, sysContainer.Mounts, skip))
+	}
+	return ifBackendMissing(c, c.IfBackendMissing, results, startTime)
+}
+
+func NewContainer(sysContainer system.Container, config util.Config) (*Container, error) {
+	exists, err := sysContainer.Exists()
+	if err != nil {
+		return nil, err
+	}
+	c := &Container{
+		Name:   sysContainer.Name(),
+		Exists: exists,
+	}
+	if !exists {
+		return c, nil
+	}
+	if !contains(config.IgnoreList, "running") {
+		if running, err := sysContainer.Running(); err == nil {
+			c.Running = running
+		}
+	}
+	if !contains(config.IgnoreList, "health") {
+		if health, err := sysContainer.Health(); err == nil && health != "none" {
+			c.Health = health
+		}
+	}
+	if !contains(config.IgnoreList, "image") {
+		if image, err := sysContainer.Image(); err == nil {
+			c.Image = image
+		}
+	}
+	if !contains(config.IgnoreList, "ports") {
+		if ports, err := sysContainer.Ports(); err == nil && len(ports) > 0 {
+			c.Ports = ports
+		}
+	}
+	if !contains(config.IgnoreList, "mounts") {
+		if mounts, err := sysContainer.Mounts(); err == nil && len(mounts) > 0 {
+			c.Mounts = mounts
+		}
+	}
+	return c, nil
+}
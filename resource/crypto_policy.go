@@ -0,0 +1,71 @@
+package resource
+
+import (
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+type CryptoPolicy struct {
+	Title               string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta                meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags                []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires            []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity            string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Name                string   `json:"-" yaml:"-"`
+	Policy              matcher  `json:"policy,omitempty" yaml:"policy,omitempty"`
+	FIPS                matcher  `json:"fips,omitempty" yaml:"fips,omitempty"`
+	OpenSSLMinProtocol  matcher  `json:"openssl-min-protocol,omitempty" yaml:"openssl-min-protocol,omitempty"`
+	OpenSSLCipherString matcher  `json:"openssl-cipher-string,omitempty" yaml:"openssl-cipher-string,omitempty"`
+	Skip                bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+func (c *CryptoPolicy) ID() string      { return c.Name }
+func (c *CryptoPolicy) SetID(id string) { c.Name = id }
+
+func (c *CryptoPolicy) GetTitle() string      { return c.Title }
+func (c *CryptoPolicy) GetMeta() meta         { return c.Meta }
+func (c *CryptoPolicy) GetTags() []string     { return c.Tags }
+func (c *CryptoPolicy) GetRequires() []string { return c.Requires }
+func (c *CryptoPolicy) GetSeverity() string   { return c.Severity }
+
+func (c *CryptoPolicy) Validate(sys *system.System) []TestResult {
+	skip := c.Skip
+	sysPolicy := sys.NewCryptoPolicy(c.Name, sys, util.Config{})
+
+	var results []TestResult
+	if c.Policy != nil {
+		results = append(results, ValidateValue(c, "policy", c.Policy, sysPolicy.Policy, skip))
+	}
+	if c.FIPS != nil {
+		results = append(results, ValidateValue(c, "fips", c.FIPS, sysPolicy.FIPS, skip))
+	}
+	if c.OpenSSLMinProtocol != nil {
+		results = append(results, ValidateValue(c, "openssl-min-protocol", c.OpenSSLMinProtocol, sysPolicy.OpenSSLMinProtocol, skip))
+	}
+	if c.OpenSSLCipherString != nil {
+		results = append(results, ValidateValue(c, "openssl-cipher-string", c.OpenSSLCipherString, sysPolicy.OpenSSLCipherString, skip))
+	}
+	return results
+}
+
+func NewCryptoPolicy(sysPolicy system.CryptoPolicy, config util.Config) (*CryptoPolicy, error) {
+	fips, err := sysPolicy.FIPS()
+	if err != nil {
+		return nil, err
+	}
+	c := &CryptoPolicy{
+		Name: sysPolicy.Name(),
+		FIPS: fips,
+	}
+	if !contains(config.IgnoreList, "policy") {
+		if policy, err := sysPolicy.Policy(); err == nil {
+			c.Policy = policy
+		}
+	}
+	if !contains(config.IgnoreList, "openssl-min-protocol") {
+		if v, err := sysPolicy.OpenSSLMinProtocol(); err == nil && v != "" {
+			c.OpenSSLMinProtocol = v
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,249 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around the changes of a diff
+const diffContext = 3
+
+// maxDiffCells bounds the work of a diff, the lines of expected times the
+// lines found, larger values are left to the message of the matcher
+const maxDiffCells = 1 << 20
+
+// failureDiff is a unified diff of what expected doesn't match in found, for
+// multi-line strings, those of expected-from-file included, consist-of lists
+// and have-key-with-value maps of plain values. It's empty when the message of
+// the matcher is as readable.
+func failureDiff(expected, found interface{}) string {
+	a, b, ok := diffLines(expected, found)
+	if !ok {
+		return ""
+	}
+	diff := unifiedDiff(a, b)
+	if diff == "" {
+		return ""
+	}
+	return "doesn't match, diff of expected (-) and found (+):\n" + diff
+}
+
+// diffLines renders expected and found as the lines the diff compares
+func diffLines(expected, found interface{}) (a, b []string, ok bool) {
+	switch e := expected.(type) {
+	case string:
+		f, isString := found.(string)
+		if !isString || !strings.Contains(e+f, "\n") {
+			return nil, nil, false
+		}
+		return strings.Split(strings.TrimSuffix(e, "\n"), "\n"), strings.Split(strings.TrimSuffix(f, "\n"), "\n"), true
+	case map[string]interface{}:
+		if len(e) != 1 {
+			return nil, nil, false
+		}
+		if elements, isList := e["consist-of"].([]interface{}); isList {
+			foundElements, isFoundList := toSlice(found)
+			if !isFoundList || !plainValues(elements) {
+				return nil, nil, false
+			}
+			return sortedLines(elements), sortedLines(foundElements), true
+		}
+		if values, isMap := e["have-key-with-value"].(map[string]interface{}); isMap {
+			foundMap, isFoundMap := toMap(found)
+			plain := make([]interface{}, 0, len(values))
+			for _, v := range values {
+				plain = append(plain, v)
+			}
+			if !isFoundMap || !plainValues(plain) {
+				return nil, nil, false
+			}
+			// Found with the expected values is what would have matched, so
+			// only the keys that don't match differ
+			want := make(map[string]interface{}, len(foundMap))
+			for k, v := range foundMap {
+				want[k] = v
+			}
+			for k, v := range values {
+				want[k] = v
+			}
+			return mapLines(want), mapLines(foundMap), true
+		}
+		if _, isFile := e[expectedFromFile]; isFile {
+			expected, err := readExpectedFile(e[expectedFromFile])
+			if err != nil {
+				return nil, nil, false
+			}
+			return diffLines(expected, found)
+		}
+	}
+	return nil, nil, false
+}
+
+func plainValues(values []interface{}) bool {
+	for _, v := range values {
+		switch v.(type) {
+		case string, int, bool, float64:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func toSlice(v interface{}) ([]interface{}, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	s := make([]interface{}, rv.Len())
+	for i := range s {
+		s[i] = rv.Index(i).Interface()
+	}
+	return s, true
+}
+
+func toMap(v interface{}) (map[string]interface{}, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map {
+		return nil, false
+	}
+	m := make(map[string]interface{}, rv.Len())
+	for _, k := range rv.MapKeys() {
+		m[fmt.Sprint(k.Interface())] = rv.MapIndex(k).Interface()
+	}
+	return m, true
+}
+
+func diffValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+func sortedLines(values []interface{}) []string {
+	lines := make([]string, len(values))
+	for i, v := range values {
+		lines[i] = diffValue(v)
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func mapLines(m map[string]interface{}) []string {
+	lines := make([]string, 0, len(m))
+	for k, v := range m {
+		lines = append(lines, fmt.Sprintf("%s: %s", k, diffValue(v)))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+type diffLine struct {
+	op   byte
+	text string
+	// a and b are the number of lines of each side before this one
+	a, b int
+}
+
+// unifiedDiff is the hunks of the changes turning a into b, empty when
+// they're the same
+func unifiedDiff(a, b []string) string {
+	if len(a)*len(b) > maxDiffCells {
+		return ""
+	}
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []diffLine
+	changed := false
+	for i, j := 0, 0; i < len(a) || j < len(b); {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i], i, j})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{'-', a[i], i, j})
+			changed = true
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j], i, j})
+			changed = true
+			j++
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var out strings.Builder
+	for start := 0; start < len(lines); {
+		for start < len(lines) && lines[start].op == ' ' {
+			start++
+		}
+		if start == len(lines) {
+			break
+		}
+		// Changes closer than twice the context share a hunk
+		end := start
+		for k := start; k < len(lines) && k-end <= 2*diffContext; k++ {
+			if lines[k].op != ' ' {
+				end = k
+			}
+		}
+		from := start - diffContext
+		if from < 0 {
+			from = 0
+		}
+		to := end + diffContext + 1
+		if to > len(lines) {
+			to = len(lines)
+		}
+		hunk := lines[from:to]
+		var aLen, bLen int
+		for _, l := range hunk {
+			if l.op != '+' {
+				aLen++
+			}
+			if l.op != '-' {
+				bLen++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(hunk[0].a, aLen), hunkRange(hunk[0].b, bLen))
+		for _, l := range hunk {
+			fmt.Fprintf(&out, "%c%s\n", l.op, l.text)
+		}
+		start = to
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// hunkRange is the start,length of a hunk, which starts at the line before
+// it when it's empty
+func hunkRange(before, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	return fmt.Sprintf("%d,%d", before+1, length)
+}
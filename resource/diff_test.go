@@ -0,0 +1,58 @@
+package resource
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFailureDiff(t *testing.T) {
+	lines := func(n int) []string {
+		var l []string
+		for i := 1; i <= n; i++ {
+			l = append(l, strings.Repeat("x", i))
+		}
+		return l
+	}
+	expected := lines(20)
+	found := lines(20)
+	found[1] = "changed"
+	found = append(found[:15], found[16:]...)
+
+	tests := []struct {
+		name     string
+		expected interface{}
+		found    interface{}
+		want     string
+	}{
+		{
+			"hunks of multi-line strings",
+			strings.Join(expected, "\n"), strings.Join(found, "\n"),
+			"@@ -1,5 +1,5 @@\n x\n-xx\n+changed\n xxx\n xxxx\n xxxxx\n" +
+				"@@ -13,7 +13,6 @@\n " + strings.Join(expected[12:15], "\n ") + "\n-" + expected[15] + "\n " + strings.Join(expected[16:19], "\n "),
+		},
+		{
+			"consist-of",
+			map[string]interface{}{"consist-of": []interface{}{"a", "b", "x"}}, []string{"b", "a", "c"},
+			"@@ -1,3 +1,3 @@\n \"a\"\n \"b\"\n-\"x\"\n+\"c\"",
+		},
+		{
+			"have-key-with-value",
+			map[string]interface{}{"have-key-with-value": map[string]interface{}{"b": 3}}, map[interface{}]interface{}{"a": 1, "b": 2},
+			"@@ -1,2 +1,2 @@\n a: 1\n-b: 3\n+b: 2",
+		},
+		{"single line strings", "foo", "bar", ""},
+		{"same lines", "a\nb\n", "a\nb", ""},
+		{"matchers", map[string]interface{}{"consist-of": []interface{}{map[string]interface{}{"have-prefix": "a"}}}, []string{"b"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := failureDiff(tt.expected, tt.found)
+			if tt.want != "" {
+				tt.want = "doesn't match, diff of expected (-) and found (+):\n" + tt.want
+			}
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package resource
+
+import (
+	"strings"
+
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+type Dir struct {
+	Title    string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta     meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags     []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Path     string   `json:"-" yaml:"-"`
+	Exists   matcher  `json:"exists" yaml:"exists"`
+	Entries  matcher  `json:"entries,omitempty" yaml:"entries,omitempty"`
+	Skip     bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+func (d *Dir) ID() string      { return d.Path }
+func (d *Dir) SetID(id string) { d.Path = id }
+
+func (d *Dir) GetTitle() string      { return d.Title }
+func (d *Dir) GetMeta() meta         { return d.Meta }
+func (d *Dir) GetTags() []string     { return d.Tags }
+func (d *Dir) GetRequires() []string { return d.Requires }
+func (d *Dir) GetSeverity() string   { return d.Severity }
+
+func (d *Dir) Validate(sys *system.System) []TestResult {
+	skip := false
+	sysDir := sys.NewDir(d.Path, sys, util.Config{})
+
+	if d.Skip {
+		skip = true
+	}
+
+	var results []TestResult
+	results = append(results, ValidateValue(d, "exists", d.Exists, sysDir.Exists, skip))
+	if shouldSkip(results) {
+		skip = true
+	}
+	if d.Entries != nil {
+		results = append(results, ValidateValue(d, "entries", d.Entries, sysDir.Entries, skip))
+	}
+	return results
+}
+
+func NewDir(sysDir system.Dir, config util.Config) (*Dir, error) {
+	path := sysDir.Path()
+	exists, err := sysDir.Exists()
+	if err != nil {
+		return nil, err
+	}
+	d := &Dir{
+		Path:   path,
+		Exists: exists,
+	}
+	if !contains(config.IgnoreList, "entries") {
+		if entries, err := sysDir.Entries(); err == nil {
+			d.Entries = map[string]interface{}{"consist-of": entries}
+		}
+	}
+	return d, nil
+}
+
+func (d *Dir) preflight(sys *system.System) []string {
+	if d.Skip || d.Entries == nil || strings.HasPrefix(d.Path, "~") {
+		return nil
+	}
+	if problem := unreadable(d.Path); problem != "" {
+		return []string{"entries: " + problem}
+	}
+	return nil
+}
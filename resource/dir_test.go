@@ -0,0 +1,62 @@
+package resource
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+func TestDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.conf", ".hidden"} {
+		writeFile(t, filepath.Join(dir, name), "")
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(dir, link); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		dir     *Dir
+		success map[string]bool
+	}{
+		{"entries", &Dir{Path: dir, Exists: true, Entries: map[string]interface{}{"consist-of": []interface{}{".hidden", "a.conf", "sub"}}}, map[string]bool{"exists": true, "entries": true}},
+		{"missing entry", &Dir{Path: dir, Exists: true, Entries: map[string]interface{}{"contain-element": "b.conf"}}, map[string]bool{"exists": true, "entries": false}},
+		{"symlink", &Dir{Path: link, Exists: true, Entries: map[string]interface{}{"contain-element": "a.conf"}}, map[string]bool{"exists": true, "entries": true}},
+		{"file", &Dir{Path: filepath.Join(dir, "a.conf"), Exists: false}, map[string]bool{"exists": true}},
+		// The entries of a directory that doesn't exist are skipped
+		{"missing", &Dir{Path: filepath.Join(dir, "missing"), Exists: true, Entries: []interface{}{}}, map[string]bool{"exists": false, "entries": true}},
+	}
+	for _, tt := range tests {
+		results := tt.dir.Validate(system.New(""))
+		if len(results) != len(tt.success) {
+			t.Errorf("%s: got %d results, want %d: %+v", tt.name, len(results), len(tt.success), results)
+			continue
+		}
+		for _, r := range results {
+			if tt.name == "missing" && r.Property == "entries" && r.Result != SKIP {
+				t.Errorf("%s: entries: got result %d, want skipped", tt.name, r.Result)
+			}
+			if r.Successful != tt.success[r.Property] {
+				t.Errorf("%s: %s: got successful %v, want %v: %+v", tt.name, r.Property, r.Successful, tt.success[r.Property], r)
+			}
+		}
+	}
+
+	d, err := NewDir(system.NewDefDir(dir, nil, util.Config{}), util.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"consist-of": []string{".hidden", "a.conf", "sub"}}
+	if d.Exists != true || !reflect.DeepEqual(d.Entries, want) {
+		t.Errorf("NewDir: got exists %v, entries %v, want entries %v", d.Exists, d.Entries, want)
+	}
+}
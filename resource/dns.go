@@ -9,22 +9,32 @@ import (
 )
 
 type DNS struct {
-	Title       string  `json:"title,omitempty" yaml:"title,omitempty"`
-	Meta        meta    `json:"meta,omitempty" yaml:"meta,omitempty"`
-	Host        string  `json:"-" yaml:"-"`
-	Resolveable matcher `json:"resolveable,omitempty" yaml:"resolveable,omitempty"`
-	Resolvable  matcher `json:"resolvable" yaml:"resolvable"`
-	Addrs       matcher `json:"addrs,omitempty" yaml:"addrs,omitempty"`
-	Timeout     int     `json:"timeout" yaml:"timeout"`
-	Server      string  `json:"server,omitempty" yaml:"server,omitempty"`
-	Skip        bool    `json:"skip,omitempty" yaml:"skip,omitempty"`
+	Title       string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta        meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags        []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires    []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity    string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Host        string   `json:"-" yaml:"-"`
+	Resolveable matcher  `json:"resolveable,omitempty" yaml:"resolveable,omitempty"`
+	Resolvable  matcher  `json:"resolvable" yaml:"resolvable"`
+	Addrs       matcher  `json:"addrs,omitempty" yaml:"addrs,omitempty"`
+	Records     matcher  `json:"records,omitempty" yaml:"records,omitempty"`
+	DNSSEC      matcher  `json:"dnssec,omitempty" yaml:"dnssec,omitempty"`
+	Timeout     int      `json:"timeout" yaml:"timeout"`
+	Server      string   `json:"server,omitempty" yaml:"server,omitempty"`
+	IPVersion   matcher  `json:"ip-version,omitempty" yaml:"ip-version,omitempty"`
+	Netns       string   `json:"netns,omitempty" yaml:"netns,omitempty"`
+	Skip        bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
 }
 
 func (d *DNS) ID() string      { return d.Host }
 func (d *DNS) SetID(id string) { d.Host = id }
 
-func (d *DNS) GetTitle() string { return d.Title }
-func (d *DNS) GetMeta() meta    { return d.Meta }
+func (d *DNS) GetTitle() string      { return d.Title }
+func (d *DNS) GetMeta() meta         { return d.Meta }
+func (d *DNS) GetTags() []string     { return d.Tags }
+func (d *DNS) GetRequires() []string { return d.Requires }
+func (d *DNS) GetSeverity() string   { return d.Severity }
 
 func (d *DNS) Validate(sys *system.System) []TestResult {
 	skip := false
@@ -35,20 +45,31 @@ func (d *DNS) Validate(sys *system.System) []TestResult {
 		skip = true
 	}
 
-	sysDNS := sys.NewDNS(d.Host, sys, util.Config{Timeout: time.Duration(d.Timeout) * time.Millisecond, Server: d.Server})
+	version, err := ipVersion(d.IPVersion)
+	sysDNS := sys.NewDNS(d.Host, sys, util.Config{Timeout: time.Duration(d.Timeout) * time.Millisecond, Server: d.Server, IPVersion: version, Netns: d.Netns})
+	resolvable := sysDNS.Resolvable
+	if err != nil {
+		resolvable = func() (bool, error) { return false, err }
+	}
 
 	var results []TestResult
 	// Backwards copatibility hack for now
 	if d.Resolvable == nil {
 		d.Resolvable = d.Resolveable
 	}
-	results = append(results, ValidateValue(d, "resolvable", d.Resolvable, sysDNS.Resolvable, skip))
+	results = append(results, ValidateValue(d, "resolvable", d.Resolvable, resolvable, skip))
 	if shouldSkip(results) {
 		skip = true
 	}
 	if d.Addrs != nil {
 		results = append(results, ValidateValue(d, "addrs", d.Addrs, sysDNS.Addrs, skip))
 	}
+	if d.Records != nil {
+		results = append(results, ValidateValue(d, "records", d.Records, sysDNS.Records, skip))
+	}
+	if d.DNSSEC != nil {
+		results = append(results, ValidateValue(d, "dnssec", d.DNSSEC, sysDNS.DNSSEC, skip))
+	}
 	return results
 }
 
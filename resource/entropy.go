@@ -0,0 +1,94 @@
+package resource
+
+import (
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+type Entropy struct {
+	Title         string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta          meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags          []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires      []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity      string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Name          string   `json:"-" yaml:"-"`
+	Available     matcher  `json:"available,omitempty" yaml:"available,omitempty"`
+	PoolSize      matcher  `json:"pool-size,omitempty" yaml:"pool-size,omitempty"`
+	HWRNG         matcher  `json:"hwrng,omitempty" yaml:"hwrng,omitempty"`
+	HWRNGSource   matcher  `json:"hwrng-source,omitempty" yaml:"hwrng-source,omitempty"`
+	Jitterentropy matcher  `json:"jitterentropy,omitempty" yaml:"jitterentropy,omitempty"`
+	Daemons       matcher  `json:"daemons,omitempty" yaml:"daemons,omitempty"`
+	Skip          bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+func (e *Entropy) ID() string      { return e.Name }
+func (e *Entropy) SetID(id string) { e.Name = id }
+
+func (e *Entropy) GetTitle() string      { return e.Title }
+func (e *Entropy) GetMeta() meta         { return e.Meta }
+func (e *Entropy) GetTags() []string     { return e.Tags }
+func (e *Entropy) GetRequires() []string { return e.Requires }
+func (e *Entropy) GetSeverity() string   { return e.Severity }
+
+func (e *Entropy) Validate(sys *system.System) []TestResult {
+	skip := e.Skip
+	sysEntropy := sys.NewEntropy(e.Name, sys, util.Config{})
+
+	var results []TestResult
+	if e.Available != nil {
+		results = append(results, ValidateValue(e, "available", e.Available, sysEntropy.Available, skip))
+	}
+	if e.PoolSize != nil {
+		results = append(results, ValidateValue(e, "pool-size", e.PoolSize, sysEntropy.PoolSize, skip))
+	}
+	if e.HWRNG != nil {
+		results = append(results, ValidateValue(e, "hwrng", e.HWRNG, sysEntropy.HWRNG, skip))
+	}
+	if e.HWRNGSource != nil {
+		results = append(results, ValidateValue(e, "hwrng-source", e.HWRNGSource, sysEntropy.HWRNGSource, skip))
+	}
+	if e.Jitterentropy != nil {
+		results = append(results, ValidateValue(e, "jitterentropy", e.Jitterentropy, sysEntropy.Jitterentropy, skip))
+	}
+	if e.Daemons != nil {
+		results = append(results, ValidateValue(e, "daemons", e.Daemons, sysEntropy.Daemons, skip))
+	}
+	return results
+}
+
+func NewEntropy(sysEntropy system.Entropy, config util.Config) (*Entropy, error) {
+	hwrng, err := sysEntropy.HWRNG()
+	if err != nil {
+		return nil, err
+	}
+	e := &Entropy{
+		Name:  sysEntropy.Name(),
+		HWRNG: hwrng,
+	}
+	// The available entropy changes all the time, a lower bound is what matters
+	// and 256 bits are enough to seed anything
+	if !contains(config.IgnoreList, "available") {
+		if avail, err := sysEntropy.Available(); err == nil {
+			if avail > 256 {
+				avail = 256
+			}
+			e.Available = map[string]interface{}{"ge": avail}
+		}
+	}
+	if !contains(config.IgnoreList, "hwrng-source") && hwrng {
+		if source, err := sysEntropy.HWRNGSource(); err == nil {
+			e.HWRNGSource = source
+		}
+	}
+	if !contains(config.IgnoreList, "jitterentropy") {
+		if jent, err := sysEntropy.Jitterentropy(); err == nil {
+			e.Jitterentropy = jent
+		}
+	}
+	if !contains(config.IgnoreList, "daemons") {
+		if daemons, err := sysEntropy.Daemons(); err == nil && len(daemons) > 0 {
+			e.Daemons = daemons
+		}
+	}
+	return e, nil
+}
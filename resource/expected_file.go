@@ -0,0 +1,109 @@
+package resource
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// expectedFromFile is the matcher whose expected value is the content of a
+// file, so long content such as keys and banners isn't inlined in the
+// gossfile
+const expectedFromFile = "expected-from-file"
+
+var matcherType = reflect.TypeOf((*matcher)(nil)).Elem()
+
+// ResolveExpectedFiles makes the relative paths of the expected-from-file
+// matchers of res relative to dir, the directory of its gossfile
+func ResolveExpectedFiles(res Resource, dir string) error {
+	v := reflect.ValueOf(res)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		var matchers []interface{}
+		switch {
+		case f.Type() == matcherType:
+			matchers = append(matchers, f.Interface())
+		case f.Kind() == reflect.Map && f.Type().Elem() == matcherType:
+			for _, k := range f.MapKeys() {
+				matchers = append(matchers, f.MapIndex(k).Interface())
+			}
+		}
+		for _, m := range matchers {
+			if err := resolveExpectedFiles(reflect.ValueOf(m), dir); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolveExpectedFiles rewrites the paths of the matcher v in place, those of
+// the matchers of and, or and the other matchers of matchers included
+func resolveExpectedFiles(v reflect.Value, dir string) error {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			e := v.MapIndex(key)
+			k := key
+			if k.Kind() == reflect.Interface {
+				k = k.Elem()
+			}
+			if k.Kind() != reflect.String || k.String() != expectedFromFile {
+				if err := resolveExpectedFiles(e, dir); err != nil {
+					return err
+				}
+				continue
+			}
+			path, err := resolveExpectedFile(e.Interface(), dir)
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(key, reflect.ValueOf(path))
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := resolveExpectedFiles(v.Index(i), dir); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func resolveExpectedFile(path interface{}, dir string) (string, error) {
+	p, ok := path.(string)
+	if !ok || p == "" {
+		return "", fmt.Errorf("%s: expected a path, found: %v", expectedFromFile, path)
+	}
+	if filepath.IsAbs(p) {
+		return p, nil
+	}
+	if strings.Contains(dir, "://") {
+		return "", fmt.Errorf("%s: remote gossfile %s can't read the local file %q", expectedFromFile, dir, p)
+	}
+	return filepath.Join(dir, p), nil
+}
+
+// readExpectedFile is the expected value of an expected-from-file matcher
+func readExpectedFile(path interface{}) (string, error) {
+	p, ok := path.(string)
+	if !ok || p == "" {
+		return "", fmt.Errorf("Matcher %s expected a path, got: %v", expectedFromFile, path)
+	}
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		return "", fmt.Errorf("Matcher %s: %v", expectedFromFile, err)
+	}
+	return string(data), nil
+}
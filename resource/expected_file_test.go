@@ -0,0 +1,66 @@
+package resource
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveExpectedFiles(t *testing.T) {
+	c := &Command{
+		Command:    "cat /etc/issue",
+		ExitStatus: map[interface{}]interface{}{"expected-from-file": "/abs/status"},
+		Stdout:     []string{"expected-from-file"},
+		StdoutValue: map[string]interface{}{"or": []interface{}{
+			map[string]interface{}{"expected-from-file": "issue"},
+			map[string]interface{}{"not": map[string]interface{}{"expected-from-file": "../old/issue"}},
+		}},
+		StdoutKV: map[string]matcher{"ID": map[string]interface{}{"expected-from-file": "id"}},
+	}
+	if err := ResolveExpectedFiles(c, "tests"); err != nil {
+		t.Fatal(err)
+	}
+	or := c.StdoutValue.(map[string]interface{})["or"].([]interface{})
+	got := []interface{}{
+		c.ExitStatus.(map[interface{}]interface{})["expected-from-file"],
+		or[0].(map[string]interface{})["expected-from-file"],
+		or[1].(map[string]interface{})["not"].(map[string]interface{})["expected-from-file"],
+		c.StdoutKV["ID"].(map[string]interface{})["expected-from-file"],
+	}
+	want := []interface{}{"/abs/status", filepath.Join("tests", "issue"), "old/issue", filepath.Join("tests", "id")}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("path %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	c = &Command{ExitStatus: map[string]interface{}{"expected-from-file": "issue"}}
+	if err := ResolveExpectedFiles(c, "https://example.com/suites"); err == nil {
+		t.Error("remote gossfile: got no error")
+	}
+}
+
+func TestExpectedFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "banner")
+	if err := ioutil.WriteFile(path, []byte("Authorized use only\nAll activity is logged\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{"expected-from-file": path}
+
+	found := func(s string) func() (string, error) {
+		return func() (string, error) { return s, nil }
+	}
+	got := ValidateValue(&FakeResource{""}, "", expected, found("Authorized use only\nAll activity is logged\n"), false)
+	if !got.Successful {
+		t.Errorf("same content: %+v", got)
+	}
+	got = ValidateValue(&FakeResource{""}, "", expected, found("Authorized use only\n"), false)
+	if got.Successful || !strings.Contains(got.Human, "-All activity is logged") {
+		t.Errorf("different content: %+v", got)
+	}
+	got = ValidateValue(&FakeResource{""}, "", map[string]interface{}{"expected-from-file": path + ".missing"}, found(""), false)
+	if got.Result != ERROR {
+		t.Errorf("missing file: %+v", got)
+	}
+}
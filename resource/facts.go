@@ -0,0 +1,271 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// Facts are the values tests read from the system in a run, keyed by
+// "type: id: property". Recorded facts can be replayed to evaluate the
+// matchers of a gossfile against them instead of the system, to debug the
+// failures of another host offline.
+type Facts struct {
+	mu     sync.Mutex
+	replay bool
+	facts  map[string]fact
+}
+
+type fact struct {
+	Value   json.RawMessage `json:"value,omitempty"`
+	Err     string          `json:"err,omitempty"`
+	ErrCode string          `json:"err-code,omitempty"`
+}
+
+// error is the recorded error, with its error code
+func (v fact) error() error {
+	if v.Err == "" {
+		return nil
+	}
+	if v.ErrCode == "" {
+		return errors.New(v.Err)
+	}
+	return util.NewCodedError(v.ErrCode, errors.New(v.Err))
+}
+
+type factsFile struct {
+	Facts map[string]fact `json:"facts"`
+}
+
+// currentFacts records or replays the facts of ValidateValue and
+// ValidateContains, nil to only read the system
+var currentFacts *Facts
+
+// UseFacts records the facts of the run in f, or replays them when f was
+// loaded with LoadFacts. nil stops recording and replaying.
+func UseFacts(f *Facts) {
+	currentFacts = f
+}
+
+// NewFacts is an empty recording
+func NewFacts() *Facts {
+	return &Facts{facts: make(map[string]fact)}
+}
+
+// LoadFacts reads a recording written by Facts.Write to replay it
+func LoadFacts(r io.Reader) (*Facts, error) {
+	var file factsFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("could not read recorded facts: %v", err)
+	}
+	if file.Facts == nil {
+		file.Facts = make(map[string]fact)
+	}
+	return &Facts{replay: true, facts: file.Facts}, nil
+}
+
+// Write writes the recorded facts as JSON
+func (f *Facts) Write(w io.Writer) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, err := json.MarshalIndent(factsFile{Facts: f.facts}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+func factKey(typeS, id, property string) string {
+	return typeS + ": " + id + ": " + property
+}
+
+func (f *Facts) get(key string) (fact, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.facts[key]
+	if !ok {
+		return fact{}, util.NewCodedError(util.ErrCodeFactNotRecorded, fmt.Errorf("no recorded fact for %s", key))
+	}
+	return v, nil
+}
+
+func (f *Facts) set(key string, value interface{}, err error) {
+	var v fact
+	if err != nil {
+		v.Err = err.Error()
+		v.ErrCode = util.ErrorCode(err)
+	} else if b, merr := json.Marshal(value); merr == nil {
+		v.Value = b
+	} else {
+		v.Err = fmt.Sprintf("could not record value: %v", merr)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.facts[key] = v
+}
+
+// readValue calls actual, or replays what it returned when it was recorded
+func readValue(key string, actual interface{}) (interface{}, error) {
+	facts := currentFacts
+	if facts != nil && facts.replay {
+		return replayValue(facts, key, actual)
+	}
+
+	var foundValue interface{}
+	var err error
+	switch f := actual.(type) {
+	case func() (bool, error):
+		foundValue, err = f()
+	case func() (string, error):
+		foundValue, err = f()
+	case func() (int, error):
+		foundValue, err = f()
+	case func() (float64, error):
+		foundValue, err = f()
+	case func() ([]string, error):
+		foundValue, err = f()
+	case func() ([]interface{}, error):
+		foundValue, err = f()
+	case func() (interface{}, error):
+		foundValue, err = f()
+	default:
+		return nil, fmt.Errorf("Unknown method signature: %t", f)
+	}
+	if facts != nil {
+		facts.set(key, foundValue, err)
+	}
+	return foundValue, err
+}
+
+// replayValue decodes the recorded value as the type actual returns, so
+// matchers see an int rather than the float64 of JSON
+func replayValue(facts *Facts, key string, actual interface{}) (interface{}, error) {
+	v, err := facts.get(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.error(); err != nil {
+		return nil, err
+	}
+	var target interface{}
+	switch actual.(type) {
+	case func() (bool, error):
+		target = new(bool)
+	case func() (string, error):
+		target = new(string)
+	case func() (int, error):
+		target = new(int)
+	case func() (float64, error):
+		target = new(float64)
+	case func() ([]string, error):
+		target = new([]string)
+	case func() ([]interface{}, error):
+		target = new([]interface{})
+	case func() (interface{}, error):
+		target = new(interface{})
+	default:
+		return nil, fmt.Errorf("Unknown method signature: %t", actual)
+	}
+	if err := json.Unmarshal(v.Value, target); err != nil {
+		return nil, fmt.Errorf("recorded fact for %s: %v", key, err)
+	}
+	return reflect.ValueOf(target).Elem().Interface(), nil
+}
+
+// readContents calls method, or replays the contents it returned when it was
+// recorded. Recording reads all of it, as matching may stop early.
+func readContents(key string, method func() (io.Reader, error)) (io.Reader, error) {
+	facts := currentFacts
+	if facts == nil {
+		return method()
+	}
+	if facts.replay {
+		v, err := facts.get(key)
+		if err != nil {
+			return nil, err
+		}
+		if err := v.error(); err != nil {
+			return nil, err
+		}
+		var contents string
+		if err := json.Unmarshal(v.Value, &contents); err != nil {
+			return nil, fmt.Errorf("recorded fact for %s: %v", key, err)
+		}
+		return strings.NewReader(contents), nil
+	}
+
+	fh, err := method()
+	if err != nil {
+		facts.set(key, nil, err)
+		return nil, err
+	}
+	if rc, ok := fh.(io.ReadCloser); ok {
+		defer rc.Close()
+	}
+	b, err := ioutil.ReadAll(fh)
+	facts.set(key, string(b), err)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(b), nil
+}
+
+// FactChange is a fact that differs between two recordings, as the JSON of
+// its value or "error: " and its error. Before is empty for a fact only the
+// second recording has, After for one only the first has.
+type FactChange struct {
+	Key    string `json:"key"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// String is how the fact is shown in a change, its value compacted as
+// recordings are written indented
+func (v fact) String() string {
+	if v.Err != "" {
+		return "error: " + v.Err
+	}
+	var b bytes.Buffer
+	if err := json.Compact(&b, v.Value); err != nil {
+		return string(v.Value)
+	}
+	return b.String()
+}
+
+// DiffFacts are the facts that differ between before and after, sorted by
+// key
+func DiffFacts(before, after *Facts) []FactChange {
+	before.mu.Lock()
+	defer before.mu.Unlock()
+	after.mu.Lock()
+	defer after.mu.Unlock()
+
+	var changes []FactChange
+	for key, b := range before.facts {
+		a, ok := after.facts[key]
+		if !ok {
+			changes = append(changes, FactChange{Key: key, Before: b.String()})
+			continue
+		}
+		if b.String() != a.String() {
+			changes = append(changes, FactChange{Key: key, Before: b.String(), After: a.String()})
+		}
+	}
+	for key, a := range after.facts {
+		if _, ok := before.facts[key]; !ok {
+			changes = append(changes, FactChange{Key: key, After: a.String()})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
@@ -0,0 +1,88 @@
+package resource
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFactsRecordReplay(t *testing.T) {
+	res := &FakeResource{"foo"}
+	exitStatus := func() (int, error) { return 3, nil }
+	stdout := func() (io.Reader, error) { return strings.NewReader("one\ntwo\n"), nil }
+	denied := func() (string, error) { return "", fmt.Errorf("permission denied") }
+
+	recording := NewFacts()
+	UseFacts(recording)
+	defer UseFacts(nil)
+	ValidateValue(res, "exit-status", 3, exitStatus, false)
+	ValidateContains(res, "stdout", []string{"one"}, stdout, false)
+	ValidateValue(res, "owner", "root", denied, false)
+
+	var buf bytes.Buffer
+	if err := recording.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	replay, err := LoadFacts(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	UseFacts(replay)
+
+	unreachable := func() (int, error) {
+		t.Error("replay read the system")
+		return 0, nil
+	}
+	if got := ValidateValue(res, "exit-status", 3, unreachable, false); got.Result != SUCCESS {
+		t.Errorf("exit-status: got %v, want SUCCESS", got.Result)
+	}
+	if got := ValidateValue(res, "exit-status", 0, unreachable, false); got.Result != FAIL {
+		t.Errorf("changed matcher: got %v, want FAIL", got.Result)
+	}
+	if got := ValidateContains(res, "stdout", []string{"two"}, nil, false); got.Result != SUCCESS {
+		t.Errorf("stdout: got %v, want SUCCESS", got.Result)
+	}
+	got := ValidateValue(res, "owner", "root", denied, false)
+	if got.Result != ERROR || got.Err.Error() != "permission denied" {
+		t.Errorf("owner: got %v %v, want the recorded error", got.Result, got.Err)
+	}
+	got = ValidateValue(res, "size", 1, unreachable, false)
+	if got.Result != ERROR || !strings.Contains(got.Err.Error(), "no recorded fact for FakeResource: foo: size") {
+		t.Errorf("size: got %v %v, want a missing fact error", got.Result, got.Err)
+	}
+}
+
+func TestFactsRecordReplayFloat(t *testing.T) {
+	res := &NTP{Name: "ntp"}
+	offset := func() (float64, error) { return 0.25, nil }
+
+	recording := NewFacts()
+	UseFacts(recording)
+	defer UseFacts(nil)
+	if got := ValidateValue(res, "offset", map[string]interface{}{"lt": 0.5}, offset, false); got.Result != SUCCESS {
+		t.Fatalf("offset: got %v %v, want SUCCESS", got.Result, got.Err)
+	}
+
+	var buf bytes.Buffer
+	if err := recording.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	replay, err := LoadFacts(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	UseFacts(replay)
+
+	unreachable := func() (float64, error) {
+		t.Error("replay read the system")
+		return 0, nil
+	}
+	if got := ValidateValue(res, "offset", map[string]interface{}{"lt": 0.5}, unreachable, false); got.Result != SUCCESS {
+		t.Errorf("offset: got %v %v, want SUCCESS", got.Result, got.Err)
+	}
+	if got := ValidateValue(res, "offset", map[string]interface{}{"lt": 0.2}, unreachable, false); got.Result != FAIL {
+		t.Errorf("offset: got %v %v, want FAIL", got.Result, got.Err)
+	}
+}
@@ -1,36 +1,60 @@
 package resource
 
 import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
 	"github.com/aelsabbahy/goss/system"
 	"github.com/aelsabbahy/goss/util"
 )
 
+// sourceTimeout limits how long fetching a matches-source URL may take
+const sourceTimeout = 30 * time.Second
+
 type File struct {
-	Title    string   `json:"title,omitempty" yaml:"title,omitempty"`
-	Meta     meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
-	Path     string   `json:"-" yaml:"-"`
-	Exists   matcher  `json:"exists" yaml:"exists"`
-	Mode     matcher  `json:"mode,omitempty" yaml:"mode,omitempty"`
-	Size     matcher  `json:"size,omitempty" yaml:"size,omitempty"`
-	Owner    matcher  `json:"owner,omitempty" yaml:"owner,omitempty"`
-	Group    matcher  `json:"group,omitempty" yaml:"group,omitempty"`
-	LinkedTo matcher  `json:"linked-to,omitempty" yaml:"linked-to,omitempty"`
-	Filetype matcher  `json:"filetype,omitempty" yaml:"filetype,omitempty"`
-	Contains []string `json:"contains" yaml:"contains"`
-	Md5      matcher  `json:"md5,omitempty" yaml:"md5,omitempty"`
-	Sha256   matcher  `json:"sha256,omitempty" yaml:"sha256,omitempty"`
-	Skip     bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
+	Title         string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta          meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags          []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires      []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity      string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Path          string   `json:"-" yaml:"-"`
+	Exists        matcher  `json:"exists" yaml:"exists"`
+	Mode          matcher  `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Size          matcher  `json:"size,omitempty" yaml:"size,omitempty"`
+	Blocks        matcher  `json:"blocks,omitempty" yaml:"blocks,omitempty"`
+	Allocated     matcher  `json:"allocated,omitempty" yaml:"allocated,omitempty"`
+	Owner         matcher  `json:"owner,omitempty" yaml:"owner,omitempty"`
+	Group         matcher  `json:"group,omitempty" yaml:"group,omitempty"`
+	Recursive     bool     `json:"recursive,omitempty" yaml:"recursive,omitempty"`
+	Exclude       []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+	LinkedTo      matcher  `json:"linked-to,omitempty" yaml:"linked-to,omitempty"`
+	Follow        bool     `json:"follow,omitempty" yaml:"follow,omitempty"`
+	Filetype      matcher  `json:"filetype,omitempty" yaml:"filetype,omitempty"`
+	Attributes    matcher  `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+	Contains      []string `json:"contains" yaml:"contains"`
+	Encoding      string   `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+	Md5           matcher  `json:"md5,omitempty" yaml:"md5,omitempty"`
+	Sha256        matcher  `json:"sha256,omitempty" yaml:"sha256,omitempty"`
+	Sha512        matcher  `json:"sha512,omitempty" yaml:"sha512,omitempty"`
+	MatchesSource string   `json:"matches-source,omitempty" yaml:"matches-source,omitempty"`
+	Skip          bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
 }
 
 func (f *File) ID() string      { return f.Path }
 func (f *File) SetID(id string) { f.Path = id }
 
-func (f *File) GetTitle() string { return f.Title }
-func (f *File) GetMeta() meta    { return f.Meta }
+func (f *File) GetTitle() string      { return f.Title }
+func (f *File) GetMeta() meta         { return f.Meta }
+func (f *File) GetTags() []string     { return f.Tags }
+func (f *File) GetRequires() []string { return f.Requires }
+func (f *File) GetSeverity() string   { return f.Severity }
 
 func (f *File) Validate(sys *system.System) []TestResult {
 	skip := false
-	sysFile := sys.NewFile(f.Path, sys, util.Config{})
+	sysFile := sys.NewFile(f.Path, sys, util.Config{FollowSymlinks: f.Follow})
 
 	if f.Skip {
 		skip = true
@@ -41,14 +65,18 @@ func (f *File) Validate(sys *system.System) []TestResult {
 	if shouldSkip(results) {
 		skip = true
 	}
-	if f.Mode != nil {
-		results = append(results, ValidateValue(f, "mode", f.Mode, sysFile.Mode, skip))
-	}
-	if f.Owner != nil {
-		results = append(results, ValidateValue(f, "owner", f.Owner, sysFile.Owner, skip))
-	}
-	if f.Group != nil {
-		results = append(results, ValidateValue(f, "group", f.Group, sysFile.Group, skip))
+	if f.Recursive {
+		results = append(results, f.validateTree(sysFile, skip)...)
+	} else {
+		if f.Mode != nil {
+			results = append(results, ValidateValue(f, "mode", f.Mode, sysFile.Mode, skip))
+		}
+		if f.Owner != nil {
+			results = append(results, ValidateValue(f, "owner", f.Owner, sysFile.Owner, skip))
+		}
+		if f.Group != nil {
+			results = append(results, ValidateValue(f, "group", f.Group, sysFile.Group, skip))
+		}
 	}
 	if f.LinkedTo != nil {
 		results = append(results, ValidateValue(f, "linkedto", f.LinkedTo, sysFile.LinkedTo, skip))
@@ -56,11 +84,20 @@ func (f *File) Validate(sys *system.System) []TestResult {
 	if f.Filetype != nil {
 		results = append(results, ValidateValue(f, "filetype", f.Filetype, sysFile.Filetype, skip))
 	}
+	if f.Attributes != nil {
+		results = append(results, ValidateValue(f, "attributes", f.Attributes, sysFile.Attributes, skip))
+	}
 	if len(f.Contains) > 0 {
-		results = append(results, ValidateContains(f, "contains", f.Contains, sysFile.Contains, skip))
+		results = append(results, ValidateContains(f, "contains", f.Contains, f.decodedContains(sysFile), skip))
 	}
 	if f.Size != nil {
-		results = append(results, ValidateValue(f, "size", f.Size, sysFile.Size, skip))
+		results = append(results, ValidateValue(f, "size", sizeMatcher(f.Size), sysFile.Size, skip))
+	}
+	if f.Blocks != nil {
+		results = append(results, ValidateValue(f, "blocks", f.Blocks, sysFile.Blocks, skip))
+	}
+	if f.Allocated != nil {
+		results = append(results, ValidateValue(f, "allocated", sizeMatcher(f.Allocated), sysFile.Allocated, skip))
 	}
 	if f.Md5 != nil {
 		results = append(results, ValidateValue(f, "md5", f.Md5, sysFile.Md5, skip))
@@ -68,9 +105,131 @@ func (f *File) Validate(sys *system.System) []TestResult {
 	if f.Sha256 != nil {
 		results = append(results, ValidateValue(f, "sha256", f.Sha256, sysFile.Sha256, skip))
 	}
+	if f.Sha512 != nil {
+		results = append(results, ValidateValue(f, "sha512", f.Sha512, sysFile.Sha512, skip))
+	}
+	if f.MatchesSource != "" {
+		results = append(results, f.validateSource(sysFile, skip))
+	}
 	return results
 }
 
+// validateTree validates mode, owner and group of the file and every entry under
+// it, symlinks are left out of mode since their permissions aren't used
+func (f *File) validateTree(sysFile system.File, skip bool) []TestResult {
+	var entries []system.File
+	var err error
+	if !skip && (f.Mode != nil || f.Owner != nil || f.Group != nil) {
+		entries, err = sysFile.Walk(f.Exclude)
+	}
+
+	var results []TestResult
+	if f.Mode != nil {
+		var files []system.File
+		for _, e := range entries {
+			if ft, _ := e.Filetype(); ft != "symlink" {
+				files = append(files, e)
+			}
+		}
+		results = append(results, f.validateEach("mode", f.Mode, files, err, system.File.Mode, skip))
+	}
+	if f.Owner != nil {
+		results = append(results, f.validateEach("owner", f.Owner, entries, err, system.File.Owner, skip))
+	}
+	if f.Group != nil {
+		results = append(results, f.validateEach("group", f.Group, entries, err, system.File.Group, skip))
+	}
+	return results
+}
+
+// maxTreeFailures limits the number of entries listed when a recursive check fails
+const maxTreeFailures = 20
+
+// validateEach validates property of each of files, the result of a failure is
+// that of the first file that didn't match listing every file that didn't
+func (f *File) validateEach(property string, expected matcher, files []system.File, walkErr error, found func(system.File) (string, error), skip bool) TestResult {
+	startTime := time.Now()
+	if skip {
+		return ValidateValue(f, property, expected, nil, skip)
+	}
+	if walkErr == nil && len(files) == 0 {
+		walkErr = fmt.Errorf("no entries to check, all of them are excluded")
+	}
+	if walkErr != nil {
+		return ValidateValue(f, property, expected, func() (string, error) { return "", walkErr }, skip)
+	}
+
+	var first *TestResult
+	var failed []string
+	for _, file := range files {
+		file := file
+		r := ValidateValue(f, property, expected, func() (string, error) {
+			v, err := found(file)
+			if err != nil {
+				err = fmt.Errorf("%s: %v", file.Path(), err)
+			}
+			return v, err
+		}, skip)
+		if r.Successful {
+			if first == nil {
+				first = &r
+			}
+			continue
+		}
+		if len(failed) == 0 {
+			first = &r
+		}
+		if r.Err != nil {
+			failed = append(failed, r.Err.Error())
+		} else {
+			failed = append(failed, file.Path()+": "+strings.Join(r.Found, ", "))
+		}
+	}
+
+	res := *first
+	res.Duration = time.Now().Sub(startTime)
+	if len(failed) > 0 && res.Err == nil {
+		listed := failed
+		if len(listed) > maxTreeFailures {
+			listed = listed[:maxTreeFailures]
+		}
+		res.Found = failed
+		res.Human = fmt.Sprintf("%d of %d entries don't match:\n    %s", len(failed), len(files), strings.Join(listed, "\n    "))
+		if len(failed) > len(listed) {
+			res.Human += fmt.Sprintf("\n    ... and %d more", len(failed)-len(listed))
+		}
+		res.Human += "\n\n" + first.Human
+	}
+	return res
+}
+
+// validateSource compares the checksum of the file with the checksum of MatchesSource
+func (f *File) validateSource(sysFile system.File, skip bool) TestResult {
+	var want string
+	found := sysFile.Sha256
+	if !skip {
+		var err error
+		if want, err = system.SourceSha256(f.MatchesSource, sourceTimeout); err != nil {
+			found = func() (string, error) { return "", err }
+		}
+	}
+	return ValidateValue(f, "matches-source", want, found, skip)
+}
+
+// decodedContains converts the content of sysFile from the file's encoding to UTF-8
+func (f *File) decodedContains(sysFile system.File) func() (io.Reader, error) {
+	if f.Encoding == "" {
+		return sysFile.Contains
+	}
+	return func() (io.Reader, error) {
+		r, err := sysFile.Contains()
+		if err != nil {
+			return r, err
+		}
+		return util.NewDecodingReader(r, f.Encoding)
+	}
+}
+
 func NewFile(sysFile system.File, config util.Config) (*File, error) {
 	path := sysFile.Path()
 	exists, err := sysFile.Exists()
@@ -114,3 +273,26 @@ func NewFile(sysFile system.File, config util.Config) (*File, error) {
 	}
 	return f, nil
 }
+
+func (f *File) preflight(sys *system.System) []string {
+	if f.Skip || strings.HasPrefix(f.Path, "~") {
+		return nil
+	}
+	var problems []string
+	if _, err := os.Lstat(f.Path); os.IsPermission(err) {
+		return []string{fmt.Sprintf("permission denied looking up %s", f.Path)}
+	}
+	if len(f.Contains) > 0 || f.Md5 != nil || f.Sha256 != nil || f.Sha512 != nil || f.MatchesSource != "" {
+		if fi, err := os.Stat(f.Path); err == nil && fi.Mode().IsRegular() {
+			if problem := unreadable(f.Path); problem != "" {
+				problems = append(problems, "contents and checksums: "+problem)
+			}
+		}
+	}
+	if f.Recursive {
+		if problem := unreadable(f.Path); problem != "" {
+			problems = append(problems, "recursive: "+problem)
+		}
+	}
+	return problems
+}
@@ -0,0 +1,129 @@
+package resource
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aelsabbahy/goss/system"
+)
+
+// fileResults validates f and returns its results by property
+func fileResults(t *testing.T, f *File) map[string]TestResult {
+	results := map[string]TestResult{}
+	for _, r := range f.Validate(system.New("")) {
+		results[r.Property] = r
+	}
+	return results
+}
+
+func writeFile(t *testing.T, path, content string) string {
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFileMatchesSource(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, filepath.Join(dir, "app.conf"), "listen 80\n")
+	same := writeFile(t, filepath.Join(dir, "same.conf"), "listen 80\n")
+	other := writeFile(t, filepath.Join(dir, "other.conf"), "listen 8080\n")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/app.conf" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("listen 80\n"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		source  string
+		success bool
+		err     bool
+	}{
+		{same, true, false},
+		{other, false, false},
+		{server.URL + "/app.conf", true, false},
+		{server.URL + "/missing.conf", false, true},
+		{filepath.Join(dir, "missing.conf"), false, true},
+	}
+	for _, tt := range tests {
+		r := fileResults(t, &File{Path: path, Exists: true, MatchesSource: tt.source})["matches-source"]
+		if r.Successful != tt.success || (r.Err != nil) != tt.err {
+			t.Errorf("%s: got successful %v, error %v, want %v, error %v", tt.source, r.Successful, r.Err, tt.success, tt.err)
+		}
+	}
+
+	// The source isn't fetched when the file doesn't exist
+	r := fileResults(t, &File{Path: filepath.Join(dir, "absent"), Exists: true, MatchesSource: same})["matches-source"]
+	if r.Result != SKIP {
+		t.Errorf("missing file: got result %d, want skipped", r.Result)
+	}
+}
+
+func TestFileChecksums(t *testing.T) {
+	path := writeFile(t, filepath.Join(t.TempDir(), "hello"), "hello\n")
+	f := &File{
+		Path:   path,
+		Exists: true,
+		Md5:    "b1946ac92492d2347c6235b4d2611184",
+		Sha256: "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+		Sha512: "e7c22b994c59d9cf2b48e549b1e24666636045930d3da7c1acb299d1c3b7f931f94aae41edda2c2b207a36e10f8bcb8d45223e54878f5b316e7ce3b6bc019629",
+	}
+	for property, r := range fileResults(t, f) {
+		if !r.Successful {
+			t.Errorf("%s: %+v", property, r)
+		}
+	}
+
+	f.Sha512 = "e7c22b994c59d9cf2b48e549b1e24666636045930d3da7c1acb299d1c3b7f931f94aae41edda2c2b207a36e10f8bcb8d45223e54878f5b316e7ce3b6bc019620"
+	if r := fileResults(t, f)["sha512"]; r.Successful {
+		t.Errorf("sha512 of another file: %+v", r)
+	}
+}
+
+func TestFileFollow(t *testing.T) {
+	dir := t.TempDir()
+	target := writeFile(t, filepath.Join(dir, "target"), "hello")
+	if err := os.Chmod(target, 0640); err != nil {
+		t.Fatal(err)
+	}
+	// link -> middle -> target, and a dangling link
+	middle, link, dangling := filepath.Join(dir, "middle"), filepath.Join(dir, "link"), filepath.Join(dir, "dangling")
+	for _, l := range [][2]string{{target, middle}, {middle, link}, {filepath.Join(dir, "missing"), dangling}} {
+		if err := os.Symlink(l[0], l[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f := &File{Path: link, Exists: true, Filetype: "symlink", LinkedTo: middle}
+	for property, r := range fileResults(t, f) {
+		if !r.Successful {
+			t.Errorf("without follow: %s: %+v", property, r)
+		}
+	}
+
+	f = &File{Path: link, Follow: true, Exists: true, Filetype: "file", Mode: "0640", Size: 5, LinkedTo: target}
+	for property, r := range fileResults(t, f) {
+		if !r.Successful {
+			t.Errorf("with follow: %s: %+v", property, r)
+		}
+	}
+
+	// Following a symlink to nothing is a file that doesn't exist
+	if r := fileResults(t, &File{Path: dangling, Exists: false, Follow: true})["exists"]; !r.Successful {
+		t.Errorf("dangling with follow: %+v", r)
+	}
+	if r := fileResults(t, &File{Path: dangling, Exists: true})["exists"]; !r.Successful {
+		t.Errorf("dangling without follow: %+v", r)
+	}
+	// linked-to fails with follow on a file that isn't a symlink
+	if r := fileResults(t, &File{Path: target, Exists: true, Follow: true, LinkedTo: target})["linkedto"]; r.Err == nil {
+		t.Errorf("linked-to of a file with follow: %+v", r)
+	}
+}
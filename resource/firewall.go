@@ -0,0 +1,66 @@
+package resource
+
+import (
+	"fmt"
+
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+type Firewall struct {
+	Title    string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta     meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags     []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Name     string   `json:"-" yaml:"-"`
+	Exists   matcher  `json:"exists" yaml:"exists"`
+	Backend  string   `json:"backend,omitempty" yaml:"backend,omitempty"`
+	Family   string   `json:"family,omitempty" yaml:"family,omitempty"`
+	Table    string   `json:"table,omitempty" yaml:"table,omitempty"`
+	Chain    string   `json:"chain,omitempty" yaml:"chain,omitempty"`
+	Protocol string   `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+	DPort    int      `json:"dport,omitempty" yaml:"dport,omitempty"`
+	Action   string   `json:"action,omitempty" yaml:"action,omitempty"`
+	Skip     bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+func (f *Firewall) ID() string      { return f.Name }
+func (f *Firewall) SetID(id string) { f.Name = id }
+
+func (f *Firewall) GetTitle() string      { return f.Title }
+func (f *Firewall) GetMeta() meta         { return f.Meta }
+func (f *Firewall) GetTags() []string     { return f.Tags }
+func (f *Firewall) GetRequires() []string { return f.Requires }
+func (f *Firewall) GetSeverity() string   { return f.Severity }
+
+func (f *Firewall) Validate(sys *system.System) []TestResult {
+	skip := f.Skip
+	sysFirewall := sys.NewFirewall(f.Name, sys, util.Config{})
+	sysFirewall.SetRule(system.FirewallRule{
+		Backend:  f.Backend,
+		Family:   f.Family,
+		Table:    f.Table,
+		Chain:    f.Chain,
+		Protocol: f.Protocol,
+		DPort:    f.DPort,
+		Action:   f.Action,
+	})
+
+	var results []TestResult
+	results = append(results, ValidateValue(f, "exists", f.Exists, sysFirewall.Exists, skip))
+	return results
+}
+
+// NewFirewall can't add a rule from the command line, it needs the criteria
+// of the rule
+func NewFirewall(sysFirewall system.Firewall, config util.Config) (*Firewall, error) {
+	return nil, fmt.Errorf("firewall %s: firewall rules can't be added, write their criteria in the gossfile", sysFirewall.Name())
+}
+
+func (f *Firewall) preflight(sys *system.System) []string {
+	if f.Skip || !unprivileged() {
+		return nil
+	}
+	return []string{"exists: the firewall rules are only readable by root"}
+}
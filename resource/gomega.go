@@ -2,6 +2,7 @@ package resource
 
 import (
 	"fmt"
+	"math"
 	"sort"
 
 	"github.com/aelsabbahy/goss/matchers"
@@ -107,10 +108,32 @@ func matcherToGomegaMatcher(matcher interface{}) (types.GomegaMatcher, error) {
 			"lt": "<",
 			"le": "<=",
 		}[matchType]
-		return gomega.BeNumerically(comparator, value), nil
+		return matchers.BeNumerically(comparator, value), nil
+	case "range":
+		bounds, ok := value.([]interface{})
+		if !ok || len(bounds) != 2 {
+			return nil, fmt.Errorf("Matcher range expected [min, max], got: %v", value)
+		}
+		return matchers.BeInRange(bounds[0], bounds[1]), nil
 
 	case "semver-constraint":
 		return matchers.BeSemverConstraint(value.(string)), nil
+	case "in-cidr":
+		return matchers.BeInCIDR(value), nil
+	case "mode-at-most":
+		return matchers.BeModeAtMost(value), nil
+	case "version-gt":
+		return matchers.BeVersion(">", value), nil
+	case "version-lt":
+		return matchers.BeVersion("<", value), nil
+	case "have-checksum":
+		return matchers.HaveChecksum(value), nil
+	case expectedFromFile:
+		expected, err := readExpectedFile(value)
+		if err != nil {
+			return nil, err
+		}
+		return gomega.Equal(expected), nil
 	default:
 		return nil, fmt.Errorf("Unknown matcher: %s", matchType)
 
@@ -162,7 +185,7 @@ func sliceToGomega(value interface{}) ([]types.GomegaMatcher, error) {
 
 // Normalize expectedValue so json and yaml are the same
 func sanitizeExpectedValue(i interface{}) interface{} {
-	if e, ok := i.(float64); ok {
+	if e, ok := i.(float64); ok && e == math.Trunc(e) {
 		return int(e)
 	}
 	if e, ok := i.(map[interface{}]interface{}); ok {
@@ -178,3 +201,26 @@ func sanitizeExpectedValue(i interface{}) interface{} {
 	}
 	return i
 }
+
+// EvaluateMatcher matches value against matcher, a matcher as it's written in
+// a gossfile, the message is why it doesn't match
+func EvaluateMatcher(matcher, value interface{}) (success bool, message string, err error) {
+	defer func() {
+		// Matchers of the wrong type panic, such as a have-prefix of a number
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid matcher: %v", r)
+		}
+	}()
+	gomegaMatcher, err := matcherToGomegaMatcher(sanitizeExpectedValue(matcher))
+	if err != nil {
+		return false, "", err
+	}
+	success, err = gomegaMatcher.Match(value)
+	if err != nil || success {
+		return success, "", err
+	}
+	if diff := failureDiff(sanitizeExpectedValue(matcher), value); diff != "" {
+		return false, diff, nil
+	}
+	return false, gomegaMatcher.FailureMessage(value), nil
+}
@@ -42,19 +42,23 @@ var gomegaTests = []struct {
 	// Golang json escapes '>', '<' symbols, so we use 'gt', 'le' instead
 	{
 		in:   `{"gt": 1}`,
-		want: gomega.BeNumerically(">", float64(1)),
+		want: matchers.BeNumerically(">", float64(1)),
 	},
 	{
 		in:   `{"ge": 1}`,
-		want: gomega.BeNumerically(">=", float64(1)),
+		want: matchers.BeNumerically(">=", float64(1)),
 	},
 	{
 		in:   `{"lt": 1}`,
-		want: gomega.BeNumerically("<", float64(1)),
+		want: matchers.BeNumerically("<", float64(1)),
 	},
 	{
 		in:   `{"le": 1}`,
-		want: gomega.BeNumerically("<=", float64(1)),
+		want: matchers.BeNumerically("<=", float64(1)),
+	},
+	{
+		in:   `{"range": [1, 10]}`,
+		want: matchers.BeInRange(float64(1), float64(10)),
 	},
 
 	// String
@@ -134,6 +138,34 @@ var gomegaTests = []struct {
 		in:   `{"semver-constraint": "> 1.0.0"}`,
 		want: matchers.BeSemverConstraint("> 1.0.0"),
 	},
+
+	// CIDR
+	{
+		in:   `{"in-cidr": "10.0.0.0/8"}`,
+		want: matchers.BeInCIDR("10.0.0.0/8"),
+	},
+
+	// File mode
+	{
+		in:   `{"mode-at-most": "0644"}`,
+		want: matchers.BeModeAtMost("0644"),
+	},
+
+	// Version sort
+	{
+		in:   `{"version-gt": "1.9"}`,
+		want: matchers.BeVersion(">", "1.9"),
+	},
+	{
+		in:   `{"version-lt": "1.1.1k"}`,
+		want: matchers.BeVersion("<", "1.1.1k"),
+	},
+
+	// Checksum
+	{
+		in:   `{"have-checksum": "md5:098f6bcd4621d373cade4e832627b4f6"}`,
+		want: matchers.HaveChecksum("md5:098f6bcd4621d373cade4e832627b4f6"),
+	},
 }
 
 func TestMatcherToGomegaMatcher(t *testing.T) {
@@ -179,6 +211,6 @@ func gomegaEqual(g, w interface{}, negateTester bool) bool {
 }
 
 func sanitizeMatcherText(s string) string {
-	r := regexp.MustCompile("[0-9]x[a-z0-9]{10}")
+	r := regexp.MustCompile("0x[a-f0-9]+")
 	return r.ReplaceAllString(s, "")
 }
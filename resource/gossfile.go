@@ -6,16 +6,28 @@ import (
 )
 
 type Gossfile struct {
-	Title string `json:"title,omitempty" yaml:"title,omitempty"`
-	Meta  meta   `json:"meta,omitempty" yaml:"meta,omitempty"`
-	Path  string `json:"-" yaml:"-"`
+	Title string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta  meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags  []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Sha256 pins the contents of the gossfile, before it's rendered
+	Sha256 string `json:"sha256,omitempty" yaml:"sha256,omitempty"`
+	// Vars replace the defaults of the vars of a preset
+	Vars map[string]interface{} `json:"vars,omitempty" yaml:"vars,omitempty"`
+	Path string                 `json:"-" yaml:"-"`
 }
 
 func (g *Gossfile) ID() string      { return g.Path }
 func (g *Gossfile) SetID(id string) { g.Path = id }
 
-func (g *Gossfile) GetTitle() string { return g.Title }
-func (g *Gossfile) GetMeta() meta    { return g.Meta }
+func (g *Gossfile) GetTitle() string  { return g.Title }
+func (g *Gossfile) GetMeta() meta     { return g.Meta }
+func (g *Gossfile) GetTags() []string { return g.Tags }
+
+// GetRequires is empty, an included gossfile is validated as its resources
+func (g *Gossfile) GetRequires() []string { return nil }
+
+// GetSeverity is empty, the resources of an included gossfile have their own
+func (g *Gossfile) GetSeverity() string { return "" }
 
 func NewGossfile(sysGossfile system.Gossfile, config util.Config) (*Gossfile, error) {
 	path := sysGossfile.Path()
@@ -8,19 +8,25 @@ import (
 )
 
 type Group struct {
-	Title     string  `json:"title,omitempty" yaml:"title,omitempty"`
-	Meta      meta    `json:"meta,omitempty" yaml:"meta,omitempty"`
-	Groupname string  `json:"-" yaml:"-"`
-	Exists    matcher `json:"exists" yaml:"exists"`
-	GID       matcher `json:"gid,omitempty" yaml:"gid,omitempty"`
-	Skip      bool    `json:"skip,omitempty" yaml:"skip,omitempty"`
+	Title     string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta      meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags      []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires  []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity  string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Groupname string   `json:"-" yaml:"-"`
+	Exists    matcher  `json:"exists" yaml:"exists"`
+	GID       matcher  `json:"gid,omitempty" yaml:"gid,omitempty"`
+	Skip      bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
 }
 
 func (g *Group) ID() string      { return g.Groupname }
 func (g *Group) SetID(id string) { g.Groupname = id }
 
-func (g *Group) GetTitle() string { return g.Title }
-func (g *Group) GetMeta() meta    { return g.Meta }
+func (g *Group) GetTitle() string      { return g.Title }
+func (g *Group) GetMeta() meta         { return g.Meta }
+func (g *Group) GetTags() []string     { return g.Tags }
+func (g *Group) GetRequires() []string { return g.Requires }
+func (g *Group) GetSeverity() string   { return g.Severity }
 
 func (g *Group) Validate(sys *system.System) []TestResult {
 	skip := false
@@ -0,0 +1,92 @@
+package resource
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+type GRPC struct {
+	Title         string             `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta          meta               `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags          []string           `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires      []string           `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity      string             `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Target        string             `json:"-" yaml:"-"`
+	Status        matcher            `json:"status" yaml:"status"`
+	Services      map[string]matcher `json:"services,omitempty" yaml:"services,omitempty"`
+	Reflection    matcher            `json:"reflection,omitempty" yaml:"reflection,omitempty"`
+	Latency       matcher            `json:"latency,omitempty" yaml:"latency,omitempty"`
+	TLS           bool               `json:"tls,omitempty" yaml:"tls,omitempty"`
+	ServerName    string             `json:"server-name,omitempty" yaml:"server-name,omitempty"`
+	AllowInsecure bool               `json:"allow-insecure,omitempty" yaml:"allow-insecure,omitempty"`
+	CAFile        string             `json:"ca-file,omitempty" yaml:"ca-file,omitempty"`
+	ClientCert    string             `json:"client-cert,omitempty" yaml:"client-cert,omitempty"`
+	ClientKey     string             `json:"client-key,omitempty" yaml:"client-key,omitempty"`
+	Timeout       int                `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	IPVersion     matcher            `json:"ip-version,omitempty" yaml:"ip-version,omitempty"`
+	Netns         string             `json:"netns,omitempty" yaml:"netns,omitempty"`
+	Skip          bool               `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+func (g *GRPC) ID() string      { return g.Target }
+func (g *GRPC) SetID(id string) { g.Target = id }
+
+func (g *GRPC) GetTitle() string      { return g.Title }
+func (g *GRPC) GetMeta() meta         { return g.Meta }
+func (g *GRPC) GetTags() []string     { return g.Tags }
+func (g *GRPC) GetRequires() []string { return g.Requires }
+func (g *GRPC) GetSeverity() string   { return g.Severity }
+
+func (g *GRPC) Validate(sys *system.System) []TestResult {
+	skip := g.Skip
+	if g.Timeout == 0 {
+		g.Timeout = 5000
+	}
+
+	version, err := ipVersion(g.IPVersion)
+	sysGRPC := sys.NewGRPC(g.Target, sys, util.Config{
+		AllowInsecure: g.AllowInsecure, CAFile: g.CAFile, ClientCert: g.ClientCert, ClientKey: g.ClientKey,
+		Timeout: time.Duration(g.Timeout) * time.Millisecond, IPVersion: version, Netns: g.Netns})
+	sysGRPC.SetTLS(g.TLS, g.ServerName)
+	status, services, latency := sysGRPC.Status, sysGRPC.Services, sysGRPC.Latency
+	if err != nil {
+		status = func(string) (string, error) { return "", err }
+		services = func() ([]string, error) { return nil, err }
+		latency = func() (int, error) { return 0, err }
+	}
+
+	var results []TestResult
+	results = append(results, ValidateValue(g, "status", g.Status, func() (string, error) { return status("") }, skip))
+	if shouldSkip(results) {
+		skip = true
+	}
+	names := make([]string, 0, len(g.Services))
+	for name := range g.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		name := name
+		results = append(results, ValidateValue(g, "services["+name+"]", g.Services[name], func() (string, error) { return status(name) }, skip))
+	}
+	if g.Reflection != nil {
+		results = append(results, ValidateValue(g, "reflection", g.Reflection, services, skip))
+	}
+	if g.Latency != nil {
+		results = append(results, ValidateValue(g, "latency", g.Latency, latency, skip))
+	}
+	return results
+}
+
+func NewGRPC(sysGRPC system.GRPC, config util.Config) (*GRPC, error) {
+	status, err := sysGRPC.Status("")
+	g := &GRPC{
+		Target:  sysGRPC.Target(),
+		Status:  status,
+		Timeout: config.TimeOutMilliSeconds(),
+	}
+	return g, err
+}
@@ -1,6 +1,10 @@
 package resource
 
 import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aelsabbahy/goss/system"
@@ -10,16 +14,42 @@ import (
 type HTTP struct {
 	Title             string   `json:"title,omitempty" yaml:"title,omitempty"`
 	Meta              meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags              []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires          []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity          string   `json:"severity,omitempty" yaml:"severity,omitempty"`
 	HTTP              string   `json:"-" yaml:"-"`
 	Status            matcher  `json:"status" yaml:"status"`
 	AllowInsecure     bool     `json:"allow-insecure" yaml:"allow-insecure"`
 	NoFollowRedirects bool     `json:"no-follow-redirects" yaml:"no-follow-redirects"`
 	Timeout           int      `json:"timeout" yaml:"timeout"`
 	RequestHeader     []string `json:"request-headers,omitempty" yaml:"request-headers,omitempty"`
+	AcceptEncoding    string   `json:"accept-encoding,omitempty" yaml:"accept-encoding,omitempty"`
 	Headers           []string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	ContentEncoding   matcher  `json:"content-encoding,omitempty" yaml:"content-encoding,omitempty"`
+	ContentLength     matcher  `json:"content-length,omitempty" yaml:"content-length,omitempty"`
 	Body              []string `json:"body" yaml:"body"`
+	BodyValue         matcher  `json:"body-value,omitempty" yaml:"body-value,omitempty"`
+	MaxBodyBytes      int      `json:"max-body-bytes,omitempty" yaml:"max-body-bytes,omitempty"`
+	DownloadTime      matcher  `json:"download-time,omitempty" yaml:"download-time,omitempty"`
+	DownloadRate      matcher  `json:"download-rate,omitempty" yaml:"download-rate,omitempty"`
+	Latency           matcher  `json:"latency,omitempty" yaml:"latency,omitempty"`
 	Username          string   `json:"username,omitempty" yaml:"username,omitempty"`
 	Password          string   `json:"password,omitempty" yaml:"password,omitempty"`
+	CAFile            string   `json:"ca-file,omitempty" yaml:"ca-file,omitempty"`
+	ClientCert        string   `json:"client-cert,omitempty" yaml:"client-cert,omitempty"`
+	ClientKey         string   `json:"client-key,omitempty" yaml:"client-key,omitempty"`
+	CertFingerprints  []string `json:"expected-cert-fingerprints,omitempty" yaml:"expected-cert-fingerprints,omitempty"`
+	Resolve           []string `json:"resolve,omitempty" yaml:"resolve,omitempty"`
+	ProxyProtocol     string   `json:"proxy-protocol,omitempty" yaml:"proxy-protocol,omitempty"`
+	ProxySource       string   `json:"proxy-source,omitempty" yaml:"proxy-source,omitempty"`
+	XForwardedFor     matcher  `json:"x-forwarded-for,omitempty" yaml:"x-forwarded-for,omitempty"`
+	XRealIP           matcher  `json:"x-real-ip,omitempty" yaml:"x-real-ip,omitempty"`
+	Retries           int      `json:"retries,omitempty" yaml:"retries,omitempty"`
+	RetryInterval     int      `json:"retry-interval,omitempty" yaml:"retry-interval,omitempty"`
+	RetryBackoff      float64  `json:"retry-backoff,omitempty" yaml:"retry-backoff,omitempty"`
+	RetryOn           []string `json:"retry-on,omitempty" yaml:"retry-on,omitempty"`
+	IPVersion         matcher  `json:"ip-version,omitempty" yaml:"ip-version,omitempty"`
+	Netns             string   `json:"netns,omitempty" yaml:"netns,omitempty"`
 	Skip              bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
 }
 
@@ -27,40 +57,292 @@ func (u *HTTP) ID() string      { return u.HTTP }
 func (u *HTTP) SetID(id string) { u.HTTP = id }
 
 // FIXME: Can this be refactored?
-func (r *HTTP) GetTitle() string { return r.Title }
-func (r *HTTP) GetMeta() meta    { return r.Meta }
+func (r *HTTP) GetTitle() string      { return r.Title }
+func (r *HTTP) GetMeta() meta         { return r.Meta }
+func (r *HTTP) GetTags() []string     { return r.Tags }
+func (r *HTTP) GetRequires() []string { return r.Requires }
+func (r *HTTP) GetSeverity() string   { return r.Severity }
 
 func (u *HTTP) Validate(sys *system.System) []TestResult {
 	skip := false
 	if u.Timeout == 0 {
 		u.Timeout = 5000
 	}
-	sysHTTP := sys.NewHTTP(u.HTTP, sys, util.Config{
-		AllowInsecure: u.AllowInsecure, NoFollowRedirects: u.NoFollowRedirects,
-		Timeout: time.Duration(u.Timeout) * time.Millisecond, Username: u.Username, Password: u.Password,
-		RequestHeader: u.RequestHeader})
-	sysHTTP.SetAllowInsecure(u.AllowInsecure)
-	sysHTTP.SetNoFollowRedirects(u.NoFollowRedirects)
-
 	if u.Skip {
 		skip = true
 	}
 
+	var sysHTTP system.HTTP
+	if skip {
+		sysHTTP = u.newSysHTTP(sys)
+	} else {
+		sysHTTP = u.waitForStatus(sys)
+	}
+
 	var results []TestResult
-	results = append(results, ValidateValue(u, "status", u.Status, sysHTTP.Status, skip))
-	if shouldSkip(results) {
+	if len(u.CertFingerprints) > 0 {
+		// The connection is refused when the certificate doesn't match, so
+		// the rest is skipped
+		results = append(results, ValidateValue(u, "expected-cert-fingerprints", certPinMatcher(u.CertFingerprints), certFingerprints(sysHTTP), skip))
+		if !results[0].Successful {
+			skip = true
+		}
+	}
+	status := sysHTTP.Status
+	if err := validRetryOn(u.RetryOn); err != nil {
+		status = func() (int, error) { return 0, err }
+	}
+	if _, err := ipVersion(u.IPVersion); err != nil {
+		status = func() (int, error) { return 0, err }
+	}
+	results = append(results, ValidateValue(u, "status", u.Status, status, skip))
+	if shouldSkip(results[len(results)-1:]) {
 		skip = true
 	}
 	if len(u.Headers) > 0 {
 		results = append(results, ValidateContains(u, "Headers", u.Headers, sysHTTP.Headers, skip))
 	}
+	if u.ContentEncoding != nil {
+		results = append(results, ValidateValue(u, "content-encoding", u.ContentEncoding, httpContentEncoding(sysHTTP), skip))
+	}
+	if u.ContentLength != nil {
+		results = append(results, ValidateValue(u, "content-length", u.ContentLength, httpContentLength(sysHTTP), skip))
+	}
+	// Before the body, which they may read the echoed headers from
+	if u.XForwardedFor != nil {
+		results = append(results, ValidateValue(u, "x-forwarded-for", u.XForwardedFor, httpEchoedHeader(sysHTTP, "X-Forwarded-For"), skip))
+	}
+	if u.XRealIP != nil {
+		results = append(results, ValidateValue(u, "x-real-ip", u.XRealIP, httpEchoedHeader(sysHTTP, "X-Real-IP"), skip))
+	}
+	body := sysHTTP.Body
+	if u.BodyValue != nil || u.MaxBodyBytes > 0 || u.DownloadTime != nil || u.DownloadRate != nil {
+		// The response body can only be read once
+		whole := &wholeOutput{read: sysHTTP.Body, limit: u.MaxBodyBytes}
+		body = whole.Reader
+		if u.MaxBodyBytes > 0 {
+			results = append(results, ValidateValue(u, "max-body-bytes", map[string]interface{}{"le": u.MaxBodyBytes}, bodyBytes(whole), skip))
+		}
+		if u.BodyValue != nil {
+			results = append(results, ValidateValue(u, "body-value", u.BodyValue, whole.String, skip))
+		}
+		if u.DownloadTime != nil {
+			results = append(results, ValidateValue(u, "download-time", u.DownloadTime, downloadTime(whole), skip))
+		}
+		if u.DownloadRate != nil {
+			results = append(results, ValidateValue(u, "download-rate", u.DownloadRate, downloadRate(whole), skip))
+		}
+	}
 	if len(u.Body) > 0 {
-		results = append(results, ValidateContains(u, "Body", u.Body, sysHTTP.Body, skip))
+		results = append(results, ValidateContains(u, "Body", u.Body, body, skip))
+	}
+	if u.Latency != nil {
+		results = append(results, ValidateValue(u, "latency", u.Latency, sysHTTP.Latency, skip))
 	}
 
 	return results
 }
 
+func (u *HTTP) newSysHTTP(sys *system.System) system.HTTP {
+	version, _ := ipVersion(u.IPVersion)
+	sysHTTP := sys.NewHTTP(u.HTTP, sys, util.Config{
+		AllowInsecure: u.AllowInsecure, NoFollowRedirects: u.NoFollowRedirects,
+		Timeout: time.Duration(u.Timeout) * time.Millisecond, Username: u.Username, Password: u.Password,
+		RequestHeader: u.RequestHeader, CAFile: u.CAFile, ClientCert: u.ClientCert, ClientKey: u.ClientKey,
+		CertPins: u.CertFingerprints, Resolve: u.Resolve, AcceptEncoding: u.AcceptEncoding, IPVersion: version,
+		Netns: u.Netns, ProxyProtocol: u.ProxyProtocol, ProxySource: u.ProxySource})
+	sysHTTP.SetAllowInsecure(u.AllowInsecure)
+	sysHTTP.SetNoFollowRedirects(u.NoFollowRedirects)
+	return sysHTTP
+}
+
+// waitForStatus requests the endpoint up to Retries additional times until
+// the status matches the expectation, sleeping RetryInterval milliseconds in
+// between, multiplied by RetryBackoff after every attempt. With RetryOn it
+// only retries the failures of its conditions. The last response is returned
+// for validation.
+func (u *HTTP) waitForStatus(sys *system.System) system.HTTP {
+	sysHTTP := u.newSysHTTP(sys)
+	if _, err := ipVersion(u.IPVersion); u.Retries <= 0 || validRetryOn(u.RetryOn) != nil || err != nil {
+		return sysHTTP
+	}
+
+	gomegaMatcher, err := matcherToGomegaMatcher(sanitizeExpectedValue(u.Status))
+	if err != nil {
+		return sysHTTP
+	}
+	interval := time.Duration(u.RetryInterval) * time.Millisecond
+	if interval == 0 {
+		interval = time.Second
+	}
+	backoff := u.RetryBackoff
+	if backoff < 1 {
+		backoff = 1
+	}
+
+	for i := 0; i < u.Retries; i++ {
+		status, err := sysHTTP.Status()
+		if err == nil {
+			if ok, _ := gomegaMatcher.Match(status); ok {
+				return sysHTTP
+			}
+		}
+		if !retryOn(u.RetryOn, status, err) {
+			return sysHTTP
+		}
+		// Discard the response we're not going to validate
+		if body, err := sysHTTP.Body(); err == nil {
+			if rc, ok := body.(io.ReadCloser); ok {
+				rc.Close()
+			}
+		}
+		time.Sleep(interval)
+		interval = time.Duration(float64(interval) * backoff)
+		sysHTTP = u.newSysHTTP(sys)
+	}
+	return sysHTTP
+}
+
+// validRetryOn checks the conditions of retry-on, a class of statuses such
+// as 5xx, a status, connection-error or timeout
+func validRetryOn(conditions []string) error {
+	for _, c := range conditions {
+		switch {
+		case c == "connection-error", c == "timeout":
+		case len(c) == 3 && c[0] >= '1' && c[0] <= '5' && (c[1:] == "xx" || isDigits(c[1:])):
+		default:
+			return util.NewCodedError(util.ErrCodeConfigInvalid,
+				fmt.Errorf("retry-on must be a status such as 503, a class of statuses such as 5xx, connection-error or timeout, got: %s", c))
+		}
+	}
+	return nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// retryOn reports whether the response matches one of the conditions, which
+// always is the case without conditions
+func retryOn(conditions []string, status int, err error) bool {
+	if len(conditions) == 0 {
+		return true
+	}
+	for _, c := range conditions {
+		switch {
+		case err != nil && c == "timeout":
+			if util.ErrorCode(err) == util.ErrCodeTimeout {
+				return true
+			}
+		case err != nil && c == "connection-error":
+			if util.ErrorCode(err) != util.ErrCodeTimeout {
+				return true
+			}
+		case err != nil:
+		case strings.HasSuffix(c, "xx"):
+			if strconv.Itoa(status)[0] == c[0] {
+				return true
+			}
+		case c == strconv.Itoa(status):
+			return true
+		}
+	}
+	return false
+}
+
+// certPinMatcher matches the fingerprints served when one of them is pinned
+func certPinMatcher(pins []string) matcher {
+	if len(pins) == 1 {
+		return map[string]interface{}{"contain-element": pins[0]}
+	}
+	var or []interface{}
+	for _, pin := range pins {
+		or = append(or, pin)
+	}
+	return map[string]interface{}{"contain-element": map[string]interface{}{"or": or}}
+}
+
+func certFingerprints(sysHTTP system.HTTP) func() ([]string, error) {
+	return func() ([]string, error) {
+		h, ok := sysHTTP.(system.HTTPCertFingerprints)
+		if !ok {
+			return nil, fmt.Errorf("expected-cert-fingerprints isn't supported by this http backend")
+		}
+		return h.CertFingerprints()
+	}
+}
+
+func httpContentEncoding(sysHTTP system.HTTP) func() (string, error) {
+	return func() (string, error) {
+		h, ok := sysHTTP.(system.HTTPEncoding)
+		if !ok {
+			return "", fmt.Errorf("content-encoding isn't supported by this http backend")
+		}
+		return h.ContentEncoding()
+	}
+}
+
+func httpContentLength(sysHTTP system.HTTP) func() (int, error) {
+	return func() (int, error) {
+		h, ok := sysHTTP.(system.HTTPEncoding)
+		if !ok {
+			return 0, fmt.Errorf("content-length isn't supported by this http backend")
+		}
+		return h.ContentLength()
+	}
+}
+
+// bodyBytes is the size of the body, as it's decompressed, up to a byte past
+// max-body-bytes
+func bodyBytes(whole *wholeOutput) func() (int, error) {
+	return func() (int, error) {
+		data, err := whole.String()
+		return len(data), err
+	}
+}
+
+// downloadTime is the time in milliseconds it took to read the body after
+// the response headers were received
+func downloadTime(whole *wholeOutput) func() (int, error) {
+	return func() (int, error) {
+		if _, err := whole.String(); err != nil {
+			return 0, err
+		}
+		return int(whole.duration / time.Millisecond), nil
+	}
+}
+
+// downloadRate is the bytes per second the body was read at
+func downloadRate(whole *wholeOutput) func() (int, error) {
+	return func() (int, error) {
+		data, err := whole.String()
+		if err != nil {
+			return 0, err
+		}
+		d := whole.duration
+		if d < time.Microsecond {
+			d = time.Microsecond
+		}
+		return int(float64(len(data)) / d.Seconds()), nil
+	}
+}
+
+// httpEchoedHeader is the request header name as the backend echoed it
+func httpEchoedHeader(sysHTTP system.HTTP, name string) func() (string, error) {
+	return func() (string, error) {
+		h, ok := sysHTTP.(system.HTTPEcho)
+		if !ok {
+			return "", fmt.Errorf("%s isn't supported by this http backend", strings.ToLower(name))
+		}
+		return h.EchoedHeader(name)
+	}
+}
+
 func NewHTTP(sysHTTP system.HTTP, config util.Config) (*HTTP, error) {
 	http := sysHTTP.HTTP()
 	status, err := sysHTTP.Status()
@@ -75,6 +357,9 @@ func NewHTTP(sysHTTP system.HTTP, config util.Config) (*HTTP, error) {
 		Timeout:           config.TimeOutMilliSeconds(),
 		Username:          config.Username,
 		Password:          config.Password,
+		CAFile:            config.CAFile,
+		ClientCert:        config.ClientCert,
+		ClientKey:         config.ClientKey,
 	}
 	return u, err
 }
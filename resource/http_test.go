@@ -0,0 +1,207 @@
+package resource
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/system"
+)
+
+func TestHTTPRetryOn(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		retryOn  []string
+		requests int
+		success  bool
+	}{
+		{"5xx is retried", []int{503, 502, 200}, []string{"5xx"}, 3, true},
+		{"4xx fails at once", []int{404, 200}, []string{"5xx", "connection-error"}, 1, false},
+		{"status", []int{429, 200}, []string{"429"}, 2, true},
+		{"everything without retry-on", []int{404, 200}, nil, 2, true},
+		{"invalid", []int{200}, []string{"5x"}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statuses[requests])
+				requests++
+			}))
+			defer server.Close()
+
+			h := &HTTP{HTTP: server.URL, Status: 200, Retries: 5, RetryInterval: 1, RetryOn: tt.retryOn}
+			results := h.Validate(system.New(""))
+			if results[0].Successful != tt.success {
+				t.Errorf("status successful: got %v, want %v: %+v", results[0].Successful, tt.success, results[0])
+			}
+			if requests != tt.requests {
+				t.Errorf("requests: got %d, want %d", requests, tt.requests)
+			}
+		})
+	}
+
+	if !retryOn([]string{"timeout"}, 0, timeoutError{}) || retryOn([]string{"connection-error"}, 0, timeoutError{}) {
+		t.Error("timeouts are only retried with timeout")
+	}
+}
+
+func TestHTTPRetries(t *testing.T) {
+	tests := []struct {
+		name string
+		// statuses of the requests, 0 closes the connection without a response
+		statuses []int
+		retries  int
+		requests int
+		success  bool
+		found    string
+	}{
+		{"errors then success", []int{0, 0, 200}, 5, 3, true, "200"},
+		{"failures then success", []int{500, 503, 200}, 5, 3, true, "200"},
+		{"never succeeds", []int{500, 501, 502, 503}, 3, 4, false, "503"},
+		{"never answers", []int{0, 0, 0}, 2, 3, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[requests]
+				requests++
+				if status == 0 {
+					conn, _, _ := w.(http.Hijacker).Hijack()
+					conn.Close()
+					return
+				}
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			h := &HTTP{HTTP: server.URL, Status: 200, Retries: tt.retries, RetryInterval: 1, Timeout: 5000}
+			result := h.Validate(system.New(""))[0]
+			if result.Successful != tt.success {
+				t.Errorf("status successful: got %v, want %v: %+v", result.Successful, tt.success, result)
+			}
+			if requests != tt.requests {
+				t.Errorf("requests: got %d, want %d", requests, tt.requests)
+			}
+			// The last response is the one validated
+			if tt.found != "" && (len(result.Found) != 1 || result.Found[0] != tt.found) {
+				t.Errorf("found: got %v, want [%s]", result.Found, tt.found)
+			}
+			if tt.found == "" && result.Err == nil {
+				t.Errorf("expected the error of the last request: %+v", result)
+			}
+		})
+	}
+}
+
+func TestHTTPRetryBackoff(t *testing.T) {
+	var times []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	h := &HTTP{HTTP: server.URL, Status: 200, Retries: 3, RetryInterval: 20, RetryBackoff: 2, Timeout: 5000}
+	h.Validate(system.New(""))
+	if len(times) != 4 {
+		t.Fatalf("requests: got %d, want 4", len(times))
+	}
+	want := 20 * time.Millisecond
+	for i := 1; i < len(times); i++ {
+		if gap := times[i].Sub(times[i-1]); gap < want {
+			t.Errorf("retry %d after %v, want at least %v", i, gap, want)
+		}
+		want *= 2
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestHTTPBodyValue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("test"))
+	}))
+	defer server.Close()
+
+	h := &HTTP{
+		HTTP:      server.URL,
+		Status:    200,
+		BodyValue: map[string]interface{}{"have-checksum": "sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"},
+		Body:      []string{"test"},
+	}
+	results := h.Validate(system.New(""))
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3: %+v", len(results), results)
+	}
+	for _, r := range results {
+		if !r.Successful {
+			t.Errorf("%s: %+v", r.Property, r)
+		}
+	}
+}
+
+func TestHTTPLatency(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		latency matcher
+		success bool
+	}{
+		{map[string]interface{}{"ge": 50, "lt": 5000}, true},
+		{map[string]interface{}{"lt": 50}, false},
+	}
+	for _, tt := range tests {
+		h := &HTTP{HTTP: server.URL, Status: 200, Latency: tt.latency, Timeout: 5000}
+		results := h.Validate(system.New(""))
+		r := results[len(results)-1]
+		if r.Property != "latency" || r.Successful != tt.success {
+			t.Errorf("latency %v: got %s successful %v, want %v: %+v", tt.latency, r.Property, r.Successful, tt.success, r)
+		}
+	}
+}
+
+func TestHTTPMaxBodyBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("error page ", 1000)))
+	}))
+	defer server.Close()
+
+	h := &HTTP{
+		HTTP:         server.URL,
+		Status:       200,
+		MaxBodyBytes: 2048,
+		DownloadTime: map[string]interface{}{"lt": 5000},
+		DownloadRate: map[string]interface{}{"gt": 0},
+		Body:         []string{"error page"},
+	}
+	results := h.Validate(system.New(""))
+	want := map[string]bool{"status": true, "max-body-bytes": false, "download-time": true, "download-rate": true, "Body": true}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d: %+v", len(results), len(want), results)
+	}
+	for _, r := range results {
+		if r.Successful != want[r.Property] {
+			t.Errorf("%s: successful: got %v, want %v: %+v", r.Property, r.Successful, want[r.Property], r)
+		}
+	}
+	if found := results[1].Found; len(found) != 1 || found[0] != "2049" {
+		t.Errorf("max-body-bytes: found %v, want the body read up to a byte past it", found)
+	}
+
+	h.MaxBodyBytes = 20000
+	if r := h.Validate(system.New(""))[1]; !r.Successful {
+		t.Errorf("max-body-bytes: %+v", r)
+	}
+}
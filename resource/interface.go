@@ -6,21 +6,31 @@ import (
 )
 
 type Interface struct {
-	Title  string  `json:"title,omitempty" yaml:"title,omitempty"`
-	Meta   meta    `json:"meta,omitempty" yaml:"meta,omitempty"`
-	Name   string  `json:"-" yaml:"-"`
-	Exists matcher `json:"exists" yaml:"exists"`
-	Addrs  matcher `json:"addrs,omitempty" yaml:"addrs,omitempty"`
-	MTU    matcher `json:"mtu,omitempty" yaml:"mtu,omitempty"`
-	Skip   bool    `json:"skip,omitempty" yaml:"skip,omitempty"`
+	Title     string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta      meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags      []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires  []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity  string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Name      string   `json:"-" yaml:"-"`
+	Exists    matcher  `json:"exists" yaml:"exists"`
+	Addrs     matcher  `json:"addrs,omitempty" yaml:"addrs,omitempty"`
+	IPv6Addrs matcher  `json:"ipv6-addrs,omitempty" yaml:"ipv6-addrs,omitempty"`
+	MTU       matcher  `json:"mtu,omitempty" yaml:"mtu,omitempty"`
+	State     matcher  `json:"state,omitempty" yaml:"state,omitempty"`
+	Speed     matcher  `json:"speed,omitempty" yaml:"speed,omitempty"`
+	Duplex    matcher  `json:"duplex,omitempty" yaml:"duplex,omitempty"`
+	Skip      bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
 }
 
 func (i *Interface) ID() string      { return i.Name }
 func (i *Interface) SetID(id string) { i.Name = id }
 
 // FIXME: Can this be refactored?
-func (i *Interface) GetTitle() string { return i.Title }
-func (i *Interface) GetMeta() meta    { return i.Meta }
+func (i *Interface) GetTitle() string      { return i.Title }
+func (i *Interface) GetMeta() meta         { return i.Meta }
+func (i *Interface) GetTags() []string     { return i.Tags }
+func (i *Interface) GetRequires() []string { return i.Requires }
+func (i *Interface) GetSeverity() string   { return i.Severity }
 
 func (i *Interface) Validate(sys *system.System) []TestResult {
 	skip := false
@@ -38,9 +48,21 @@ func (i *Interface) Validate(sys *system.System) []TestResult {
 	if i.Addrs != nil {
 		results = append(results, ValidateValue(i, "addrs", i.Addrs, sysInterface.Addrs, skip))
 	}
+	if i.IPv6Addrs != nil {
+		results = append(results, ValidateValue(i, "ipv6-addrs", i.IPv6Addrs, sysInterface.IPv6Addrs, skip))
+	}
 	if i.MTU != nil {
 		results = append(results, ValidateValue(i, "mtu", i.MTU, sysInterface.MTU, skip))
 	}
+	if i.State != nil {
+		results = append(results, ValidateValue(i, "state", i.State, sysInterface.State, skip))
+	}
+	if i.Speed != nil {
+		results = append(results, ValidateValue(i, "speed", i.Speed, sysInterface.Speed, skip))
+	}
+	if i.Duplex != nil {
+		results = append(results, ValidateValue(i, "duplex", i.Duplex, sysInterface.Duplex, skip))
+	}
 	return results
 }
 
@@ -61,5 +83,10 @@ func NewInterface(sysInterface system.Interface, config util.Config) (*Interface
 			i.MTU = mtu
 		}
 	}
+	if !contains(config.IgnoreList, "state") {
+		if state, err := sysInterface.State(); err == nil {
+			i.State = state
+		}
+	}
 	return i, nil
 }
@@ -0,0 +1,28 @@
+package resource
+
+import (
+	"fmt"
+
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+// ipVersion is the ip-version of a network resource, 4, 6 or any, whether
+// it's written as a number or a string
+func ipVersion(v matcher) (string, error) {
+	var version string
+	switch x := v.(type) {
+	case nil:
+		return "", nil
+	case int, float64:
+		version = fmt.Sprint(x)
+	case string:
+		version = x
+	default:
+		version = fmt.Sprintf("%v", x)
+	}
+	if err := system.ValidateIPVersion(version); err != nil {
+		return "", util.NewCodedError(util.ErrCodeConfigInvalid, err)
+	}
+	return version, nil
+}
@@ -0,0 +1,86 @@
+package resource
+
+import (
+	"time"
+
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+type K8s struct {
+	Title         string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta          meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags          []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires      []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity      string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Name          string   `json:"-" yaml:"-"`
+	Exists        matcher  `json:"exists" yaml:"exists"`
+	Ready         matcher  `json:"ready,omitempty" yaml:"ready,omitempty"`
+	Replicas      matcher  `json:"replicas,omitempty" yaml:"replicas,omitempty"`
+	ReadyReplicas matcher  `json:"ready-replicas,omitempty" yaml:"ready-replicas,omitempty"`
+	Kubeconfig    string   `json:"kubeconfig,omitempty" yaml:"kubeconfig,omitempty"`
+	Context       string   `json:"context,omitempty" yaml:"context,omitempty"`
+	Timeout       int      `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Skip          bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+func (k *K8s) ID() string      { return k.Name }
+func (k *K8s) SetID(id string) { k.Name = id }
+
+func (k *K8s) GetTitle() string      { return k.Title }
+func (k *K8s) GetMeta() meta         { return k.Meta }
+func (k *K8s) GetTags() []string     { return k.Tags }
+func (k *K8s) GetRequires() []string { return k.Requires }
+func (k *K8s) GetSeverity() string   { return k.Severity }
+
+func (k *K8s) Validate(sys *system.System) []TestResult {
+	skip := k.Skip
+	if k.Timeout == 0 {
+		k.Timeout = 10000
+	}
+	sysK8s := sys.NewK8s(k.Name, sys, util.Config{Timeout: time.Duration(k.Timeout) * time.Millisecond})
+	sysK8s.SetKubeconfig(k.Kubeconfig)
+	sysK8s.SetContext(k.Context)
+
+	var results []TestResult
+	results = append(results, ValidateValue(k, "exists", k.Exists, sysK8s.Exists, skip))
+	if shouldSkip(results) {
+		skip = true
+	}
+	if k.Ready != nil {
+		results = append(results, ValidateValue(k, "ready", k.Ready, sysK8s.Ready, skip))
+	}
+	if k.Replicas != nil {
+		results = append(results, ValidateValue(k, "replicas", k.Replicas, sysK8s.Replicas, skip))
+	}
+	if k.ReadyReplicas != nil {
+		results = append(results, ValidateValue(k, "ready-replicas", k.ReadyReplicas, sysK8s.ReadyReplicas, skip))
+	}
+	return results
+}
+
+func NewK8s(sysK8s system.K8s, config util.Config) (*K8s, error) {
+	exists, err := sysK8s.Exists()
+	if err != nil {
+		return nil, err
+	}
+	k := &K8s{
+		Name:   sysK8s.Name(),
+		Exists: exists,
+	}
+	if !exists {
+		return k, nil
+	}
+	if !contains(config.IgnoreList, "ready") {
+		if ready, err := sysK8s.Ready(); err == nil {
+			k.Ready = ready
+		}
+	}
+	// Pods have no replicas
+	if !contains(config.IgnoreList, "replicas") {
+		if replicas, err := sysK8s.Replicas(); err == nil {
+			k.Replicas = replicas
+		}
+	}
+	return k, nil
+}
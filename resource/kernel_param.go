@@ -1,27 +1,36 @@
 package resource
 
 import (
+	"strings"
+
 	"github.com/aelsabbahy/goss/system"
 	"github.com/aelsabbahy/goss/util"
 )
 
 type KernelParam struct {
-	Title string  `json:"title,omitempty" yaml:"title,omitempty"`
-	Meta  meta    `json:"meta,omitempty" yaml:"meta,omitempty"`
-	Key   string  `json:"-" yaml:"-"`
-	Value matcher `json:"value" yaml:"value"`
+	Title    string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta     meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags     []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Key      string   `json:"-" yaml:"-"`
+	Value    matcher  `json:"value" yaml:"value"`
 }
 
 func (a *KernelParam) ID() string      { return a.Key }
 func (a *KernelParam) SetID(id string) { a.Key = id }
 
 // FIXME: Can this be refactored?
-func (r *KernelParam) GetTitle() string { return r.Title }
-func (r *KernelParam) GetMeta() meta    { return r.Meta }
+func (r *KernelParam) GetTitle() string      { return r.Title }
+func (r *KernelParam) GetMeta() meta         { return r.Meta }
+func (r *KernelParam) GetTags() []string     { return r.Tags }
+func (r *KernelParam) GetRequires() []string { return r.Requires }
+func (r *KernelParam) GetSeverity() string   { return r.Severity }
 
 func (a *KernelParam) Validate(sys *system.System) []TestResult {
-	skip := false
 	sysKernelParam := sys.NewKernelParam(a.Key, sys, util.Config{})
+	// Containers can't change the settings of the host they run on
+	skip := sys.Container != "" && sysKernelParam.HostOnly()
 
 	var results []TestResult
 	results = append(results, ValidateValue(a, "value", a.Value, sysKernelParam.Value, skip))
@@ -37,3 +46,10 @@ func NewKernelParam(sysKernelParam system.KernelParam, config util.Config) (*Ker
 	}
 	return a, err
 }
+
+func (a *KernelParam) preflight(sys *system.System) []string {
+	if problem := unreadable("/proc/sys/" + strings.Replace(a.Key, ".", "/", -1)); problem != "" {
+		return []string{"value: " + problem}
+	}
+	return nil
+}
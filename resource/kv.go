@@ -0,0 +1,95 @@
+package resource
+
+import (
+	"time"
+
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+type KV struct {
+	Title         string             `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta          meta               `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags          []string           `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires      []string           `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity      string             `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Address       string             `json:"-" yaml:"-"`
+	Reachable     matcher            `json:"reachable" yaml:"reachable"`
+	Command       string             `json:"command,omitempty" yaml:"command,omitempty"`
+	Key           string             `json:"key,omitempty" yaml:"key,omitempty"`
+	Response      matcher            `json:"response,omitempty" yaml:"response,omitempty"`
+	Info          map[string]matcher `json:"info,omitempty" yaml:"info,omitempty"`
+	Latency       matcher            `json:"latency,omitempty" yaml:"latency,omitempty"`
+	Username      string             `json:"username,omitempty" yaml:"username,omitempty"`
+	Password      string             `json:"password,omitempty" yaml:"password,omitempty"`
+	TLS           bool               `json:"tls,omitempty" yaml:"tls,omitempty"`
+	AllowInsecure bool               `json:"allow-insecure,omitempty" yaml:"allow-insecure,omitempty"`
+	CAFile        string             `json:"ca-file,omitempty" yaml:"ca-file,omitempty"`
+	ClientCert    string             `json:"client-cert,omitempty" yaml:"client-cert,omitempty"`
+	ClientKey     string             `json:"client-key,omitempty" yaml:"client-key,omitempty"`
+	Timeout       int                `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Skip          bool               `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+func (k *KV) ID() string      { return k.Address }
+func (k *KV) SetID(id string) { k.Address = id }
+
+func (k *KV) GetTitle() string      { return k.Title }
+func (k *KV) GetMeta() meta         { return k.Meta }
+func (k *KV) GetTags() []string     { return k.Tags }
+func (k *KV) GetRequires() []string { return k.Requires }
+func (k *KV) GetSeverity() string   { return k.Severity }
+
+func (k *KV) Validate(sys *system.System) []TestResult {
+	skip := k.Skip
+	if k.Timeout == 0 {
+		k.Timeout = 5000
+	}
+	sysKV := sys.NewKV(k.Address, sys, util.Config{
+		AllowInsecure: k.AllowInsecure, CAFile: k.CAFile, ClientCert: k.ClientCert, ClientKey: k.ClientKey,
+		Username: k.Username, Password: k.Password, Timeout: time.Duration(k.Timeout) * time.Millisecond})
+	sysKV.SetCommand(k.Command, k.Key)
+	sysKV.SetTLS(k.TLS)
+
+	var results []TestResult
+	results = append(results, ValidateValue(k, "reachable", k.Reachable, sysKV.Reachable, skip))
+	if shouldSkip(results) {
+		skip = true
+	}
+	if k.Response != nil {
+		results = append(results, ValidateValue(k, "response", k.Response, sysKV.Response, skip))
+	}
+	for _, field := range sortedMatcherKeys(k.Info) {
+		results = append(results, ValidateValue(k, "info["+field+"]", k.Info[field], kvInfo(sysKV, field), skip))
+	}
+	if k.Latency != nil {
+		results = append(results, ValidateValue(k, "latency", k.Latency, sysKV.Latency, skip))
+	}
+	return results
+}
+
+func kvInfo(sysKV system.KV, field string) func() (string, error) {
+	return func() (string, error) {
+		return sysKV.Info(field)
+	}
+}
+
+func NewKV(sysKV system.KV, config util.Config) (*KV, error) {
+	reachable, err := sysKV.Reachable()
+	if err != nil {
+		return nil, err
+	}
+	k := &KV{
+		Address:   sysKV.Address(),
+		Reachable: reachable,
+	}
+	if !reachable {
+		return k, nil
+	}
+	if !contains(config.IgnoreList, "response") {
+		if response, err := sysKV.Response(); err == nil {
+			k.Response = response
+		}
+	}
+	return k, nil
+}
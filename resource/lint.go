@@ -0,0 +1,164 @@
+package resource
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aelsabbahy/goss/matchers"
+	"github.com/blang/semver"
+)
+
+// PatternAttributes are the attributes of each resource type, by type name,
+// whose values are have-patterns: strings, /regexes/ and their ! inverse
+var PatternAttributes = map[string][]string{
+	"Command":      {"stdout", "stderr", "output"},
+	"File":         {"contains"},
+	"HTTP":         {"headers", "body"},
+	"Process":      {"args"},
+	"ShellProfile": {"contains"},
+}
+
+// Matchers are the names of the matchers a gossfile can use
+var Matchers = []string{
+	"and", "consist-of", "contain-element", "expected-from-file", "ge", "gt", "have-checksum", "have-key", "have-key-with-value", "have-len",
+	"have-prefix", "have-suffix", "in-cidr", "le", "lt", "match-regexp", "mode-at-most", "not", "or", "range",
+	"semver-constraint", "version-gt", "version-lt",
+}
+
+// LintPatterns reports the first invalid regex of patterns
+func LintPatterns(patterns []string) error {
+	_, err := sliceToPatterns(patterns)
+	return err
+}
+
+// LintMatcher reports what's wrong with matcher, as it's written in a
+// gossfile, that would only error once it's matched against a value: an
+// unknown matcher, an argument of the wrong type or an invalid regex,
+// semver constraint or CIDR
+func LintMatcher(matcher interface{}) error {
+	m, ok := sanitizeExpectedValue(matcher).(map[string]interface{})
+	if !ok {
+		// Values, and lists of values that must all be found, are compared
+		return nil
+	}
+	if len(m) != 1 {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return fmt.Errorf("a matcher has a single key, found: %s", strings.Join(keys, ", "))
+	}
+	for name, value := range m {
+		if err := lintMatcher(name, sanitizeExpectedValue(value)); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func lintMatcher(name string, value interface{}) error {
+	switch name {
+	case "have-prefix", "have-suffix":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("expected a string, found: %v", value)
+		}
+	case "match-regexp":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected a string, found: %v", value)
+		}
+		if _, err := regexp.Compile(s); err != nil {
+			return err
+		}
+	case "have-len":
+		if _, ok := value.(int); !ok {
+			return fmt.Errorf("expected an integer, found: %v", value)
+		}
+	case "have-key-with-value":
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected a map, found: %v", value)
+		}
+		for _, v := range values {
+			if err := LintMatcher(v); err != nil {
+				return err
+			}
+		}
+	case "have-key", "contain-element", "not":
+		return LintMatcher(value)
+	case "consist-of", "and", "or":
+		values, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected a list, found: %v", value)
+		}
+		for _, v := range values {
+			if err := LintMatcher(v); err != nil {
+				return err
+			}
+		}
+	case "gt", "ge", "lt", "le":
+		return lintNumber(value)
+	case "range":
+		bounds, ok := value.([]interface{})
+		if !ok || len(bounds) != 2 {
+			return fmt.Errorf("expected [min, max], found: %v", value)
+		}
+		for _, b := range bounds {
+			if err := lintNumber(sanitizeExpectedValue(b)); err != nil {
+				return err
+			}
+		}
+	case "semver-constraint":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected a string, found: %v", value)
+		}
+		if _, err := semver.ParseRange(s); err != nil {
+			return err
+		}
+	case "in-cidr":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected a CIDR such as 10.0.0.0/8, found: %v", value)
+		}
+		if _, _, err := net.ParseCIDR(s); err != nil {
+			return fmt.Errorf("expected a CIDR such as 10.0.0.0/8, found: %s", s)
+		}
+	case "mode-at-most":
+		if _, ok := matchers.ParseFileMode(value); !ok {
+			return fmt.Errorf("expected an octal mode such as \"0644\", found: %v", value)
+		}
+	case "version-gt", "version-lt":
+		if s, ok := value.(string); !ok || s == "" {
+			return fmt.Errorf("expected a version string such as \"1.10\", found: %v", value)
+		}
+	case "expected-from-file":
+		if s, ok := value.(string); !ok || s == "" {
+			return fmt.Errorf("expected a path, found: %v", value)
+		}
+	case "have-checksum":
+		if _, _, err := matchers.ParseChecksum(value); err != nil {
+			return fmt.Errorf("expected a checksum such as \"sha256:<hex>\", found: %v", value)
+		}
+	default:
+		return fmt.Errorf("unknown matcher, expected one of: %s", strings.Join(Matchers, ", "))
+	}
+	return nil
+}
+
+func lintNumber(value interface{}) error {
+	switch v := value.(type) {
+	case int, float64:
+		return nil
+	case string:
+		if _, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected a number, found: %v", value)
+}
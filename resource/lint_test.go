@@ -0,0 +1,51 @@
+package resource
+
+import "testing"
+
+func TestLintMatcher(t *testing.T) {
+	valid := []interface{}{
+		"foo", 3, true, []interface{}{"a", "b"},
+		map[string]interface{}{"have-prefix": "foo"},
+		map[interface{}]interface{}{"not": map[interface{}]interface{}{"match-regexp": "^a+$"}},
+		map[string]interface{}{"have-len": float64(3)},
+		map[string]interface{}{"range": []interface{}{1, "2.5"}},
+		map[string]interface{}{"have-key-with-value": map[string]interface{}{"a": map[string]interface{}{"gt": 1}}},
+		map[string]interface{}{"semver-constraint": ">=1.2.0 <2.0.0"},
+		map[string]interface{}{"in-cidr": "10.0.0.0/8"},
+		map[string]interface{}{"mode-at-most": "0640"},
+		map[string]interface{}{"version-gt": "1.1.1k"},
+		map[string]interface{}{"have-checksum": "md5:098f6bcd4621d373cade4e832627b4f6"},
+		map[string]interface{}{"expected-from-file": "banner.txt"},
+	}
+	for _, m := range valid {
+		if err := LintMatcher(m); err != nil {
+			t.Errorf("%v: %v", m, err)
+		}
+	}
+
+	invalid := map[string]interface{}{
+		"match-regexp: error parsing regexp: missing closing ): `(a`":            map[string]interface{}{"match-regexp": "(a"},
+		"or: have-len: expected an integer, found: x":                            map[string]interface{}{"or": []interface{}{map[string]interface{}{"have-len": "x"}}},
+		"a matcher has a single key, found: gt, lt":                              map[string]interface{}{"gt": 1, "lt": 5},
+		"range: expected [min, max], found: [1]":                                 map[string]interface{}{"range": []interface{}{1}},
+		"in-cidr: expected a CIDR such as 10.0.0.0/8, found: 10.0.0.1":           map[string]interface{}{"in-cidr": "10.0.0.1"},
+		`mode-at-most: expected an octal mode such as "0644", found: 420`:        map[interface{}]interface{}{"mode-at-most": 420},
+		`version-lt: expected a version string such as "1.10", found: 1.1`:       map[interface{}]interface{}{"version-lt": 1.1},
+		`have-checksum: expected a checksum such as "sha256:<hex>", found: 9f86`: map[string]interface{}{"have-checksum": "9f86"},
+	}
+	for want, m := range invalid {
+		if err := LintMatcher(m); err == nil || err.Error() != want {
+			t.Errorf("%v: got %v, want %s", m, err, want)
+		}
+	}
+	if err := LintMatcher(map[string]interface{}{"contain-substring": "a"}); err == nil {
+		t.Error("unknown matcher: got no error")
+	}
+
+	if err := LintPatterns([]string{"plain [", "/^ok$/", "!/fail(ed)?/"}); err != nil {
+		t.Errorf("valid patterns: %v", err)
+	}
+	if err := LintPatterns([]string{"/(/"}); err == nil {
+		t.Error("invalid regex pattern: got no error")
+	}
+}
@@ -0,0 +1,94 @@
+package resource
+
+import (
+	"fmt"
+
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+type MAC struct {
+	Title            string             `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta             meta               `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags             []string           `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires         []string           `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity         string             `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Name             string             `json:"-" yaml:"-"`
+	SELinux          matcher            `json:"selinux,omitempty" yaml:"selinux,omitempty"`
+	SELinuxPolicy    matcher            `json:"selinux-policy,omitempty" yaml:"selinux-policy,omitempty"`
+	AppArmor         matcher            `json:"apparmor,omitempty" yaml:"apparmor,omitempty"`
+	AppArmorProfiles map[string]matcher `json:"apparmor-profiles,omitempty" yaml:"apparmor-profiles,omitempty"`
+	Skip             bool               `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+func (m *MAC) ID() string      { return m.Name }
+func (m *MAC) SetID(id string) { m.Name = id }
+
+func (m *MAC) GetTitle() string      { return m.Title }
+func (m *MAC) GetMeta() meta         { return m.Meta }
+func (m *MAC) GetTags() []string     { return m.Tags }
+func (m *MAC) GetRequires() []string { return m.Requires }
+func (m *MAC) GetSeverity() string   { return m.Severity }
+
+func (m *MAC) Validate(sys *system.System) []TestResult {
+	skip := m.Skip
+	sysMAC := sys.NewMAC(m.Name, sys, util.Config{})
+
+	var results []TestResult
+	if m.SELinux != nil {
+		results = append(results, ValidateValue(m, "selinux", m.SELinux, sysMAC.SELinux, skip))
+	}
+	if m.SELinuxPolicy != nil {
+		results = append(results, ValidateValue(m, "selinux-policy", m.SELinuxPolicy, sysMAC.SELinuxPolicy, skip))
+	}
+	if m.AppArmor != nil {
+		results = append(results, ValidateValue(m, "apparmor", m.AppArmor, sysMAC.AppArmor, skip))
+	}
+	for _, name := range sortedMatcherKeys(m.AppArmorProfiles) {
+		results = append(results, ValidateValue(m, "apparmor-profiles["+name+"]", m.AppArmorProfiles[name], apparmorProfile(sysMAC, name), skip))
+	}
+	return results
+}
+
+func NewMAC(sysMAC system.MAC, config util.Config) (*MAC, error) {
+	selinux, err := sysMAC.SELinux()
+	if err != nil {
+		return nil, err
+	}
+	apparmor, err := sysMAC.AppArmor()
+	if err != nil {
+		return nil, err
+	}
+	m := &MAC{
+		Name:     sysMAC.Name(),
+		SELinux:  selinux,
+		AppArmor: apparmor,
+	}
+	if selinux != "disabled" && !contains(config.IgnoreList, "selinux-policy") {
+		if policy, err := sysMAC.SELinuxPolicy(); err == nil && policy != "" {
+			m.SELinuxPolicy = policy
+		}
+	}
+	return m, nil
+}
+
+func apparmorProfile(sysMAC system.MAC, name string) func() (string, error) {
+	return func() (string, error) {
+		profiles, err := sysMAC.AppArmorProfiles()
+		if err != nil {
+			return "", err
+		}
+		mode, ok := profiles[name]
+		if !ok {
+			return "", fmt.Errorf("apparmor profile %q is not loaded", name)
+		}
+		return mode, nil
+	}
+}
+
+func (m *MAC) preflight(sys *system.System) []string {
+	if m.Skip || len(m.AppArmorProfiles) == 0 || !unprivileged() {
+		return nil
+	}
+	return []string{"apparmor-profiles: the loaded profiles are only visible to root"}
+}
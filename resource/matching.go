@@ -11,11 +11,14 @@ import (
 )
 
 type Matching struct {
-	Title   string      `json:"title,omitempty" yaml:"title,omitempty"`
-	Meta    meta        `json:"meta,omitempty" yaml:"meta,omitempty"`
-	Content interface{} `json:"content,omitempty" yaml:"content,omitempty"`
-	Id      string      `json:"-" yaml:"-"`
-	Matches matcher     `json:"matches" yaml:"matches"`
+	Title    string      `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta     meta        `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags     []string    `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires []string    `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity string      `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Content  interface{} `json:"content,omitempty" yaml:"content,omitempty"`
+	Id       string      `json:"-" yaml:"-"`
+	Matches  matcher     `json:"matches" yaml:"matches"`
 }
 
 type MatchingMap map[string]*Matching
@@ -24,8 +27,11 @@ func (a *Matching) ID() string      { return a.Id }
 func (a *Matching) SetID(id string) { a.Id = id }
 
 // FIXME: Can this be refactored?
-func (r *Matching) GetTitle() string { return r.Title }
-func (r *Matching) GetMeta() meta    { return r.Meta }
+func (r *Matching) GetTitle() string      { return r.Title }
+func (r *Matching) GetMeta() meta         { return r.Meta }
+func (r *Matching) GetTags() []string     { return r.Tags }
+func (r *Matching) GetRequires() []string { return r.Requires }
+func (r *Matching) GetSeverity() string   { return r.Severity }
 
 func (a *Matching) Validate(sys *system.System) []TestResult {
 	skip := false
@@ -6,23 +6,30 @@ import (
 )
 
 type Mount struct {
-	Title      string  `json:"title,omitempty" yaml:"title,omitempty"`
-	Meta       meta    `json:"meta,omitempty" yaml:"meta,omitempty"`
-	MountPoint string  `json:"-" yaml:"-"`
-	Exists     matcher `json:"exists" yaml:"exists"`
-	Opts       matcher `json:"opts,omitempty" yaml:"opts,omitempty"`
-	Source     matcher `json:"source,omitempty" yaml:"source,omitempty"`
-	Filesystem matcher `json:"filesystem,omitempty" yaml:"filesystem,omitempty"`
-	Skip       bool    `json:"skip,omitempty" yaml:"skip,omitempty"`
-	Usage      matcher `json:"usage,omitempty" yaml:"usage,omitempty"`
+	Title      string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta       meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags       []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires   []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity   string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	MountPoint string   `json:"-" yaml:"-"`
+	Exists     matcher  `json:"exists" yaml:"exists"`
+	Opts       matcher  `json:"opts,omitempty" yaml:"opts,omitempty"`
+	Source     matcher  `json:"source,omitempty" yaml:"source,omitempty"`
+	Filesystem matcher  `json:"filesystem,omitempty" yaml:"filesystem,omitempty"`
+	Skip       bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
+	Usage      matcher  `json:"usage,omitempty" yaml:"usage,omitempty"`
+	InodeUsage matcher  `json:"inode-usage,omitempty" yaml:"inode-usage,omitempty"`
 }
 
 func (m *Mount) ID() string      { return m.MountPoint }
 func (m *Mount) SetID(id string) { m.MountPoint = id }
 
 // FIXME: Can this be refactored?
-func (m *Mount) GetTitle() string { return m.Title }
-func (m *Mount) GetMeta() meta    { return m.Meta }
+func (m *Mount) GetTitle() string      { return m.Title }
+func (m *Mount) GetMeta() meta         { return m.Meta }
+func (m *Mount) GetTags() []string     { return m.Tags }
+func (m *Mount) GetRequires() []string { return m.Requires }
+func (m *Mount) GetSeverity() string   { return m.Severity }
 
 func (m *Mount) Validate(sys *system.System) []TestResult {
 	skip := false
@@ -49,6 +56,9 @@ func (m *Mount) Validate(sys *system.System) []TestResult {
 	if m.Usage != nil {
 		results = append(results, ValidateValue(m, "usage", m.Usage, sysMount.Usage, skip))
 	}
+	if m.InodeUsage != nil {
+		results = append(results, ValidateValue(m, "inode-usage", m.InodeUsage, sysMount.InodeUsage, skip))
+	}
 	return results
 }
 
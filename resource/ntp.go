@@ -0,0 +1,69 @@
+package resource
+
+import (
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+type NTP struct {
+	Title            string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta             meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags             []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires         []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity         string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Name             string   `json:"-" yaml:"-"`
+	Daemon           string   `json:"daemon,omitempty" yaml:"daemon,omitempty"`
+	Synchronized     matcher  `json:"synchronized,omitempty" yaml:"synchronized,omitempty"`
+	Offset           matcher  `json:"offset,omitempty" yaml:"offset,omitempty"`
+	ReachableSources matcher  `json:"reachable-sources,omitempty" yaml:"reachable-sources,omitempty"`
+	Skip             bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+func (n *NTP) ID() string      { return n.Name }
+func (n *NTP) SetID(id string) { n.Name = id }
+
+func (n *NTP) GetTitle() string      { return n.Title }
+func (n *NTP) GetMeta() meta         { return n.Meta }
+func (n *NTP) GetTags() []string     { return n.Tags }
+func (n *NTP) GetRequires() []string { return n.Requires }
+func (n *NTP) GetSeverity() string   { return n.Severity }
+
+func (n *NTP) Validate(sys *system.System) []TestResult {
+	skip := n.Skip
+	sysNTP := sys.NewNTP(n.Name, sys, util.Config{})
+	sysNTP.SetDaemon(n.Daemon)
+
+	var results []TestResult
+	if n.Synchronized != nil {
+		results = append(results, ValidateValue(n, "synchronized", n.Synchronized, sysNTP.Synchronized, skip))
+	}
+	if n.Offset != nil {
+		results = append(results, ValidateValue(n, "offset", n.Offset, sysNTP.Offset, skip))
+	}
+	if n.ReachableSources != nil {
+		results = append(results, ValidateValue(n, "reachable-sources", n.ReachableSources, sysNTP.ReachableSources, skip))
+	}
+	return results
+}
+
+func NewNTP(sysNTP system.NTP, config util.Config) (*NTP, error) {
+	daemon, err := sysNTP.Daemon()
+	if err != nil {
+		return nil, err
+	}
+	synchronized, err := sysNTP.Synchronized()
+	if err != nil {
+		return nil, err
+	}
+	n := &NTP{
+		Name:         sysNTP.Name(),
+		Daemon:       daemon,
+		Synchronized: synchronized,
+	}
+	if !contains(config.IgnoreList, "reachable-sources") {
+		if sources, err := sysNTP.ReachableSources(); err == nil && sources > 0 {
+			n.ReachableSources = map[string]interface{}{"ge": 1}
+		}
+	}
+	return n, nil
+}
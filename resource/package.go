@@ -1,28 +1,57 @@
 package resource
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/aelsabbahy/goss/matchers"
 	"github.com/aelsabbahy/goss/system"
 	"github.com/aelsabbahy/goss/util"
 )
 
 type Package struct {
-	Title     string  `json:"title,omitempty" yaml:"title,omitempty"`
-	Meta      meta    `json:"meta,omitempty" yaml:"meta,omitempty"`
-	Name      string  `json:"-" yaml:"-"`
-	Installed matcher `json:"installed" yaml:"installed"`
-	Versions  matcher `json:"versions,omitempty" yaml:"versions,omitempty"`
-	Skip      bool    `json:"skip,omitempty" yaml:"skip,omitempty"`
+	Title          string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta           meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags           []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires       []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity       string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Name           string   `json:"-" yaml:"-"`
+	PackageManager string   `json:"package-manager,omitempty" yaml:"package-manager,omitempty"`
+	Installed      matcher  `json:"installed" yaml:"installed"`
+	Versions       matcher  `json:"versions,omitempty" yaml:"versions,omitempty"`
+	Version        []string `json:"version,omitempty" yaml:"version,omitempty"`
+	Channel        matcher  `json:"channel,omitempty" yaml:"channel,omitempty"`
+	Confinement    matcher  `json:"confinement,omitempty" yaml:"confinement,omitempty"`
+	// IfBackendMissing is how the package is reported on hosts without its
+	// package manager: skip, error or fail
+	IfBackendMissing string `json:"if-backend-missing,omitempty" yaml:"if-backend-missing,omitempty"`
+	Skip             bool   `json:"skip,omitempty" yaml:"skip,omitempty"`
 }
 
 func (p *Package) ID() string      { return p.Name }
 func (p *Package) SetID(id string) { p.Name = id }
 
-func (p *Package) GetTitle() string { return p.Title }
-func (p *Package) GetMeta() meta    { return p.Meta }
+func (p *Package) GetTitle() string      { return p.Title }
+func (p *Package) GetMeta() meta         { return p.Meta }
+func (p *Package) GetTags() []string     { return p.Tags }
+func (p *Package) GetRequires() []string { return p.Requires }
+func (p *Package) GetSeverity() string   { return p.Severity }
 
 func (p *Package) Validate(sys *system.System) []TestResult {
 	skip := false
-	sysPkg := sys.NewPackage(p.Name, sys, util.Config{})
+	startTime := time.Now()
+	packageManager := sys.PackageManager
+	var sysPkg system.Package
+	if p.PackageManager != "" {
+		packageManager = p.PackageManager
+		sysPkg = system.NewPackageProvider(p.PackageManager, p.Name, sys, util.Config{})
+	} else if err := system.PackageManagerMissing(packageManager); err != nil && p.IfBackendMissing != "" {
+		// Without its command the package manager reports every package as
+		// not installed
+		sysPkg = &missingBackend{name: p.Name, err: err}
+	} else {
+		sysPkg = sys.NewPackage(p.Name, sys, util.Config{})
+	}
 
 	if p.Skip {
 		skip = true
@@ -36,7 +65,32 @@ func (p *Package) Validate(sys *system.System) []TestResult {
 	if p.Versions != nil {
 		results = append(results, ValidateValue(p, "version", p.Versions, sysPkg.Versions, skip))
 	}
-	return results
+	if len(p.Version) > 0 {
+		// Versions are compared the way the package manager orders them
+		constraint := matchers.BePackageVersion(p.Version, matchers.PackageVersionCompare(packageManager))
+		results = append(results, ValidateValue(p, "version-constraint", []interface{}{constraint}, sysPkg.Versions, skip))
+	}
+	if p.Channel != nil {
+		channel := unsupportedPackageAttribute("channel", packageManager)
+		if c, ok := sysPkg.(system.ChannelPackage); ok {
+			channel = c.Channel
+		}
+		results = append(results, ValidateValue(p, "channel", p.Channel, channel, skip))
+	}
+	if p.Confinement != nil {
+		confinement := unsupportedPackageAttribute("confinement", packageManager)
+		if c, ok := sysPkg.(system.ConfinedPackage); ok {
+			confinement = c.Confinement
+		}
+		results = append(results, ValidateValue(p, "confinement", p.Confinement, confinement, skip))
+	}
+	return ifBackendMissing(p, p.IfBackendMissing, results, startTime)
+}
+
+func unsupportedPackageAttribute(attribute, packageManager string) func() (string, error) {
+	return func() (string, error) {
+		return "", util.NewCodedError(util.ErrCodeConfigInvalid, fmt.Errorf("%s isn't supported by %s packages", attribute, packageManager))
+	}
 }
 
 func NewPackage(sysPackage system.Package, config util.Config) (*Package, error) {
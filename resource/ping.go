@@ -0,0 +1,77 @@
+package resource
+
+import (
+	"time"
+
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+type Ping struct {
+	Title     string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta      meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags      []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires  []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity  string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Host      string   `json:"-" yaml:"-"`
+	Reachable matcher  `json:"reachable" yaml:"reachable"`
+	RTT       matcher  `json:"rtt,omitempty" yaml:"rtt,omitempty"`
+	Loss      matcher  `json:"loss,omitempty" yaml:"loss,omitempty"`
+	MTU       matcher  `json:"mtu,omitempty" yaml:"mtu,omitempty"`
+	Count     int      `json:"count,omitempty" yaml:"count,omitempty"`
+	Interval  int      `json:"interval,omitempty" yaml:"interval,omitempty"`
+	Timeout   int      `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	IPVersion matcher  `json:"ip-version,omitempty" yaml:"ip-version,omitempty"`
+	Netns     string   `json:"netns,omitempty" yaml:"netns,omitempty"`
+	Skip      bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+func (p *Ping) ID() string      { return p.Host }
+func (p *Ping) SetID(id string) { p.Host = id }
+
+func (p *Ping) GetTitle() string      { return p.Title }
+func (p *Ping) GetMeta() meta         { return p.Meta }
+func (p *Ping) GetTags() []string     { return p.Tags }
+func (p *Ping) GetRequires() []string { return p.Requires }
+func (p *Ping) GetSeverity() string   { return p.Severity }
+
+func (p *Ping) Validate(sys *system.System) []TestResult {
+	skip := p.Skip
+	if p.Timeout == 0 {
+		p.Timeout = 1000
+	}
+
+	version, err := ipVersion(p.IPVersion)
+	sysPing := sys.NewPing(p.Host, sys, util.Config{Timeout: time.Duration(p.Timeout) * time.Millisecond, IPVersion: version, Netns: p.Netns})
+	sysPing.SetProbes(p.Count, p.Interval)
+	reachable, rtt, loss, mtu := sysPing.Reachable, sysPing.RTT, sysPing.Loss, sysPing.MTU
+	if err != nil {
+		reachable = func() (bool, error) { return false, err }
+		rtt = func() (float64, error) { return 0, err }
+		loss = rtt
+		mtu = func() (int, error) { return 0, err }
+	}
+
+	var results []TestResult
+	results = append(results, ValidateValue(p, "reachable", p.Reachable, reachable, skip))
+	if p.RTT != nil {
+		results = append(results, ValidateValue(p, "rtt", p.RTT, rtt, skip))
+	}
+	if p.Loss != nil {
+		results = append(results, ValidateValue(p, "loss", p.Loss, loss, skip))
+	}
+	if p.MTU != nil {
+		results = append(results, ValidateValue(p, "mtu", p.MTU, mtu, skip))
+	}
+	return results
+}
+
+func NewPing(sysPing system.Ping, config util.Config) (*Ping, error) {
+	reachable, err := sysPing.Reachable()
+	p := &Ping{
+		Host:      sysPing.Host(),
+		Reachable: reachable,
+		Timeout:   config.TimeOutMilliSeconds(),
+	}
+	return p, err
+}
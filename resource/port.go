@@ -6,39 +6,68 @@ import (
 )
 
 type Port struct {
-	Title     string  `json:"title,omitempty" yaml:"title,omitempty"`
-	Meta      meta    `json:"meta,omitempty" yaml:"meta,omitempty"`
-	Port      string  `json:"-" yaml:"-"`
-	Listening matcher `json:"listening" yaml:"listening"`
-	IP        matcher `json:"ip,omitempty" yaml:"ip,omitempty"`
-	Skip      bool    `json:"skip,omitempty" yaml:"skip,omitempty"`
+	Title     string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta      meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags      []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires  []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity  string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Port      string   `json:"-" yaml:"-"`
+	Listening matcher  `json:"listening" yaml:"listening"`
+	IP        matcher  `json:"ip,omitempty" yaml:"ip,omitempty"`
+	Process   matcher  `json:"process,omitempty" yaml:"process,omitempty"`
+	User      matcher  `json:"user,omitempty" yaml:"user,omitempty"`
+	IPVersion matcher  `json:"ip-version,omitempty" yaml:"ip-version,omitempty"`
+	Skip      bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
 }
 
 func (p *Port) ID() string      { return p.Port }
 func (p *Port) SetID(id string) { p.Port = id }
 
-func (p *Port) GetTitle() string { return p.Title }
-func (p *Port) GetMeta() meta    { return p.Meta }
+func (p *Port) GetTitle() string      { return p.Title }
+func (p *Port) GetMeta() meta         { return p.Meta }
+func (p *Port) GetTags() []string     { return p.Tags }
+func (p *Port) GetRequires() []string { return p.Requires }
+func (p *Port) GetSeverity() string   { return p.Severity }
 
 func (p *Port) Validate(sys *system.System) []TestResult {
 	skip := false
-	sysPort := sys.NewPort(p.Port, sys, util.Config{})
+	version, err := ipVersion(p.IPVersion)
+	sysPort := sys.NewPort(p.Port, sys, util.Config{IPVersion: version})
+	listening := sysPort.Listening
+	if err != nil {
+		listening = func() (bool, error) { return false, err }
+	}
 
 	if p.Skip {
 		skip = true
 	}
 
 	var results []TestResult
-	results = append(results, ValidateValue(p, "listening", p.Listening, sysPort.Listening, skip))
+	results = append(results, ValidateValue(p, "listening", p.Listening, listening, skip))
 	if shouldSkip(results) {
 		skip = true
 	}
 	if p.IP != nil {
 		results = append(results, ValidateValue(p, "ip", p.IP, sysPort.IP, skip))
 	}
+	if p.Process != nil {
+		results = append(results, ValidateValue(p, "process", ownerMatcher(p.Process), sysPort.Process, skip))
+	}
+	if p.User != nil {
+		results = append(results, ValidateValue(p, "user", ownerMatcher(p.User), sysPort.User, skip))
+	}
 	return results
 }
 
+// ownerMatcher makes a single expected owner match when it is one of several
+// found, e.g. the users of an nginx master and its workers
+func ownerMatcher(m matcher) matcher {
+	if s, ok := m.(string); ok {
+		return []interface{}{s}
+	}
+	return m
+}
+
 func NewPort(sysPort system.Port, config util.Config) (*Port, error) {
 	port := sysPort.Port()
 	listening, _ := sysPort.Listening()
@@ -51,5 +80,22 @@ func NewPort(sysPort system.Port, config util.Config) (*Port, error) {
 			p.IP = ip
 		}
 	}
+	if !contains(config.IgnoreList, "process") {
+		if process, err := sysPort.Process(); err == nil && len(process) > 0 {
+			p.Process = process
+		}
+	}
+	if !contains(config.IgnoreList, "user") {
+		if user, err := sysPort.User(); err == nil && len(user) > 0 {
+			p.User = user
+		}
+	}
 	return p, nil
 }
+
+func (p *Port) preflight(sys *system.System) []string {
+	if p.Skip || (p.Process == nil && p.User == nil) || !unprivileged() {
+		return nil
+	}
+	return []string{"process and user: only root can see the processes of other users' sockets"}
+}
@@ -0,0 +1,53 @@
+package resource
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/aelsabbahy/goss/system"
+)
+
+// preflighter is implemented by resources with checks that need access goss
+// may not have, preflight returns the checks that will be unreliable and why
+type preflighter interface {
+	ResourceRead
+	preflight(sys *system.System) []string
+}
+
+// Preflight reports the checks of resources that will be unreliable with the
+// privileges goss runs with, instead of failing with permission errors
+func Preflight(sys *system.System, resources []Resource) []string {
+	var problems []string
+	for _, r := range resources {
+		p, ok := r.(preflighter)
+		if !ok {
+			continue
+		}
+		typeS := strings.Split(reflect.TypeOf(r).String(), ".")[1]
+		for _, problem := range p.preflight(sys) {
+			problems = append(problems, fmt.Sprintf("%s: %s: %s", typeS, p.ID(), problem))
+		}
+	}
+	return problems
+}
+
+// unreadable describes why path can't be read, it's empty when it can or the
+// path doesn't exist as that's up to the checks
+func unreadable(path string) string {
+	f, err := os.Open(path)
+	if err == nil {
+		f.Close()
+		return ""
+	}
+	if os.IsPermission(err) {
+		return fmt.Sprintf("permission denied reading %s", path)
+	}
+	return ""
+}
+
+// unprivileged reports whether goss runs without root on systems with users
+func unprivileged() bool {
+	return os.Geteuid() > 0
+}
@@ -0,0 +1,44 @@
+package resource
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/aelsabbahy/goss/system"
+)
+
+func TestPreflight(t *testing.T) {
+	// Write only sysctls can't be read, not even by root
+	writeOnly := "/proc/sys/vm/compact_memory"
+	if _, err := os.Stat(writeOnly); err != nil {
+		t.Skip(err)
+	}
+	dir := t.TempDir()
+	secret := writeFile(t, filepath.Join(dir, "secret"), "")
+	if err := os.Chmod(secret, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	resources := []Resource{
+		&KernelParam{Key: "vm.compact_memory", Value: "1"},
+		&KernelParam{Key: "kernel.ostype", Value: "Linux"},
+		&File{Path: secret, Exists: true, Contains: []string{"token"}},
+		&File{Path: filepath.Join(dir, "missing"), Exists: false, Contains: []string{"token"}},
+		&Port{Port: "tcp:22", Listening: true, Process: "sshd"},
+		&Port{Port: "tcp:22", Listening: true, Process: "sshd", Skip: true},
+		&Service{Service: "sshd", Running: true},
+	}
+	want := []string{"KernelParam: vm.compact_memory: value: permission denied reading " + writeOnly}
+	// Root reads any file and sees the processes of every socket
+	if os.Geteuid() > 0 {
+		want = append(want,
+			"File: "+secret+": contents and checksums: permission denied reading "+secret,
+			"Port: tcp:22: process and user: only root can see the processes of other users' sockets",
+		)
+	}
+	if got := Preflight(system.New(""), resources); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -6,18 +6,31 @@ import (
 )
 
 type Process struct {
-	Title      string  `json:"title,omitempty" yaml:"title,omitempty"`
-	Meta       meta    `json:"meta,omitempty" yaml:"meta,omitempty"`
-	Executable string  `json:"-" yaml:"-"`
-	Running    matcher `json:"running" yaml:"running"`
-	Skip       bool    `json:"skip,omitempty" yaml:"skip,omitempty"`
+	Title      string             `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta       meta               `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags       []string           `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires   []string           `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity   string             `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Executable string             `json:"-" yaml:"-"`
+	Running    matcher            `json:"running" yaml:"running"`
+	Count      matcher            `json:"count,omitempty" yaml:"count,omitempty"`
+	User       matcher            `json:"user,omitempty" yaml:"user,omitempty"`
+	Args       []string           `json:"args,omitempty" yaml:"args,omitempty"`
+	RSS        matcher            `json:"rss,omitempty" yaml:"rss,omitempty"`
+	VSZ        matcher            `json:"vsz,omitempty" yaml:"vsz,omitempty"`
+	Env        map[string]matcher `json:"env,omitempty" yaml:"env,omitempty"`
+	Cwd        matcher            `json:"cwd,omitempty" yaml:"cwd,omitempty"`
+	Skip       bool               `json:"skip,omitempty" yaml:"skip,omitempty"`
 }
 
 func (p *Process) ID() string      { return p.Executable }
 func (p *Process) SetID(id string) { p.Executable = id }
 
-func (p *Process) GetTitle() string { return p.Title }
-func (p *Process) GetMeta() meta    { return p.Meta }
+func (p *Process) GetTitle() string      { return p.Title }
+func (p *Process) GetMeta() meta         { return p.Meta }
+func (p *Process) GetTags() []string     { return p.Tags }
+func (p *Process) GetRequires() []string { return p.Requires }
+func (p *Process) GetSeverity() string   { return p.Severity }
 
 func (p *Process) Validate(sys *system.System) []TestResult {
 	skip := false
@@ -29,9 +42,39 @@ func (p *Process) Validate(sys *system.System) []TestResult {
 
 	var results []TestResult
 	results = append(results, ValidateValue(p, "running", p.Running, sysProcess.Running, skip))
+	if shouldSkip(results) {
+		skip = true
+	}
+	if p.Count != nil {
+		results = append(results, ValidateValue(p, "count", p.Count, sysProcess.Count, skip))
+	}
+	if p.User != nil {
+		results = append(results, ValidateValue(p, "user", ownerMatcher(p.User), sysProcess.User, skip))
+	}
+	if len(p.Args) > 0 {
+		results = append(results, ValidateContains(p, "args", p.Args, sysProcess.Args, skip))
+	}
+	if p.RSS != nil {
+		results = append(results, ValidateValue(p, "rss", p.RSS, sysProcess.RSS, skip))
+	}
+	if p.VSZ != nil {
+		results = append(results, ValidateValue(p, "vsz", p.VSZ, sysProcess.VSZ, skip))
+	}
+	for _, name := range sortedMatcherKeys(p.Env) {
+		results = append(results, ValidateValue(p, "env["+name+"]", ownerMatcher(p.Env[name]), processEnv(sysProcess, name), skip))
+	}
+	if p.Cwd != nil {
+		results = append(results, ValidateValue(p, "cwd", ownerMatcher(p.Cwd), sysProcess.Cwd, skip))
+	}
 	return results
 }
 
+func processEnv(sysProcess system.Process, name string) func() ([]string, error) {
+	return func() ([]string, error) {
+		return sysProcess.Env(name)
+	}
+}
+
 func NewProcess(sysProcess system.Process, config util.Config) (*Process, error) {
 	executable := sysProcess.Executable()
 	running, err := sysProcess.Running()
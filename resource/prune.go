@@ -0,0 +1,81 @@
+package resource
+
+import (
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+// TargetMissing reports whether the target of res, a file, directory,
+// package, service, user, group, mount, interface or kernel parameter, no
+// longer exists on the system although res doesn't expect it to be absent,
+// so its checks are dead. The targets of other resources, such as commands
+// and endpoints, aren't things of the system and never are missing, nor are
+// those goss can't tell, as without a package or service manager.
+func TargetMissing(res Resource, sys *system.System) (bool, error) {
+	switch r := res.(type) {
+	case *File:
+		if expectsAbsent(r.Exists) {
+			return false, nil
+		}
+		return missing(sys.NewFile(r.Path, sys, util.Config{FollowSymlinks: r.Follow}).Exists)
+	case *Dir:
+		if expectsAbsent(r.Exists) {
+			return false, nil
+		}
+		return missing(sys.NewDir(r.Path, sys, util.Config{}).Exists)
+	case *Package:
+		if expectsAbsent(r.Installed) {
+			return false, nil
+		}
+		if r.PackageManager != "" {
+			return missing(system.NewPackageProvider(r.PackageManager, r.Name, sys, util.Config{}).Installed)
+		}
+		if system.PackageManagerMissing(sys.PackageManager) != nil {
+			return false, nil
+		}
+		return missing(sys.NewPackage(r.Name, sys, util.Config{}).Installed)
+	case *Service:
+		if expectsAbsent(r.Enabled) && expectsAbsent(r.Running) || sys.ServiceManagerMissing() != nil {
+			return false, nil
+		}
+		return missing(sys.NewService(r.Service, sys, util.Config{}).Exists)
+	case *User:
+		if expectsAbsent(r.Exists) {
+			return false, nil
+		}
+		return missing(sys.NewUser(r.Username, sys, util.Config{}).Exists)
+	case *Group:
+		if expectsAbsent(r.Exists) {
+			return false, nil
+		}
+		return missing(sys.NewGroup(r.Groupname, sys, util.Config{}).Exists)
+	case *Mount:
+		if expectsAbsent(r.Exists) {
+			return false, nil
+		}
+		return missing(sys.NewMount(r.MountPoint, sys, util.Config{}).Exists)
+	case *Interface:
+		if expectsAbsent(r.Exists) {
+			return false, nil
+		}
+		return missing(sys.NewInterface(r.Name, sys, util.Config{}).Exists)
+	case *KernelParam:
+		return missing(sys.NewKernelParam(r.Key, sys, util.Config{}).Exists)
+	}
+	return false, nil
+}
+
+// expectsAbsent is whether m, the exists or installed of a resource, expects
+// its target not to exist
+func expectsAbsent(m matcher) bool {
+	b, ok := m.(bool)
+	return ok && !b
+}
+
+func missing(exists func() (bool, error)) (bool, error) {
+	ok, err := exists()
+	if err != nil {
+		return false, err
+	}
+	return !ok, nil
+}
@@ -19,6 +19,9 @@ type ResourceRead interface {
 	ID() string
 	GetTitle() string
 	GetMeta() meta
+	GetTags() []string
+	GetRequires() []string
+	GetSeverity() string
 }
 
 type matcher interface{}
@@ -1,3 +1,4 @@
+//go:build genny
 // +build genny
 
 package resource
@@ -13,7 +14,7 @@ import (
 	"github.com/cheekybits/genny/generic"
 )
 
-//go:generate genny -in=$GOFILE -out=resource_list.go gen "ResourceType=Addr,Command,DNS,File,Gossfile,Group,Package,Port,Process,Service,User,KernelParam,Mount,Interface,HTTP"
+//go:generate genny -in=$GOFILE -out=resource_list.go gen "ResourceType=Addr,Command,DNS,File,Gossfile,Group,Package,Port,Process,Service,User,KernelParam,Mount,Interface,HTTP,Dir,ShellProfile,CryptoPolicy,TrustedBoot,Entropy,MAC,Container,K8s,SQL,KV,Firewall,NTP,Sockets,Bandwidth,Ping,GRPC,WebSocket"
 //go:generate sed -i -e "/^\\/\\/ +build genny/d" resource_list.go
 //go:generate goimports -w resource_list.go resource_list.go
 
@@ -0,0 +1,79 @@
+package resource
+
+import (
+	"fmt"
+	"time"
+)
+
+// RetryPolicy is how often a resource whose tests didn't pass is validated
+// again, set by the retries, retry-interval and retry-backoff of its meta
+type RetryPolicy struct {
+	Retries  int
+	Interval time.Duration
+	Backoff  float64
+}
+
+// Default interval between the retries of a resource
+const defaultRetryInterval = time.Second
+
+// Retries is the retry policy of the meta of res, retry-interval is in
+// milliseconds and multiplied by retry-backoff after every retry
+func Retries(res ResourceRead) (RetryPolicy, error) {
+	m := res.GetMeta()
+	policy := RetryPolicy{Interval: defaultRetryInterval, Backoff: 1}
+	retries, ok, err := metaNumber(m, "retries")
+	if err != nil || !ok {
+		return policy, err
+	}
+	if retries < 0 || retries != float64(int(retries)) {
+		return policy, fmt.Errorf("meta retries must be a whole number of at least 0, got: %v", m["retries"])
+	}
+	policy.Retries = int(retries)
+	if interval, ok, err := metaNumber(m, "retry-interval"); err != nil {
+		return policy, err
+	} else if ok {
+		if interval < 0 {
+			return policy, fmt.Errorf("meta retry-interval must be at least 0, got: %v", m["retry-interval"])
+		}
+		policy.Interval = time.Duration(interval * float64(time.Millisecond))
+	}
+	if backoff, ok, err := metaNumber(m, "retry-backoff"); err != nil {
+		return policy, err
+	} else if ok {
+		if backoff < 1 {
+			return policy, fmt.Errorf("meta retry-backoff must be at least 1, got: %v", m["retry-backoff"])
+		}
+		policy.Backoff = backoff
+	}
+	return policy, nil
+}
+
+// metaNumber is the number of key in m, YAML decodes them as ints and JSON as
+// float64
+func metaNumber(m meta, key string) (float64, bool, error) {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return 0, false, nil
+	}
+	switch n := v.(type) {
+	case int:
+		return float64(n), true, nil
+	case int64:
+		return float64(n), true, nil
+	case uint64:
+		return float64(n), true, nil
+	case float64:
+		return n, true, nil
+	}
+	return 0, false, fmt.Errorf("meta %s must be a number, got: %v", key, v)
+}
+
+// Passed reports whether every test of results passed or was skipped
+func Passed(results []TestResult) bool {
+	for _, r := range results {
+		if r.Result != SUCCESS && r.Result != SKIP {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+package resource
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// secretRefPattern matches the references SecretRef renders, they're the
+// template action itself so a rendered gossfile renders the same again
+var secretRefPattern = regexp.MustCompile("\\{\\{\\s*secretRef\\s+`([^`]*)`\\s*\\}\\}")
+
+// SecretRef is the reference to the secret ref, env:NAME or file:/path, that
+// the attributes of a resource hold until it's validated. Gossfiles rendered
+// with it never hold the secret itself.
+func SecretRef(ref string) (string, error) {
+	if err := validSecretRef(ref); err != nil {
+		return "", err
+	}
+	return "{{secretRef `" + ref + "`}}", nil
+}
+
+func validSecretRef(ref string) error {
+	if strings.Contains(ref, "`") {
+		return fmt.Errorf("secret reference can't contain a backtick: %s", ref)
+	}
+	if (strings.HasPrefix(ref, "env:") || strings.HasPrefix(ref, "file:")) && !strings.HasSuffix(ref, ":") {
+		return nil
+	}
+	return fmt.Errorf("secret reference must be env:NAME or file:/path, got: %s", ref)
+}
+
+// resolveSecret reads the secret of ref, a file without its trailing newline
+// as docker and kubernetes secrets are mounted
+func resolveSecret(ref string) (string, error) {
+	if err := validSecretRef(ref); err != nil {
+		return "", err
+	}
+	if name := strings.TrimPrefix(ref, "env:"); name != ref {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("secret %s: environment variable isn't set", ref)
+		}
+		return v, nil
+	}
+	b, err := ioutil.ReadFile(strings.TrimPrefix(ref, "file:"))
+	if err != nil {
+		return "", fmt.Errorf("secret %s: %v", ref, err)
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+func resolveSecrets(s string) (string, error) {
+	var err error
+	resolved := secretRefPattern.ReplaceAllStringFunc(s, func(m string) string {
+		v, e := resolveSecret(secretRefPattern.FindStringSubmatch(m)[1])
+		if e != nil && err == nil {
+			err = e
+		}
+		return v
+	})
+	return resolved, err
+}
+
+// ResolveSecrets is a copy of r with the secret references of its string
+// attributes replaced by the secrets, r when it has none. The ID and title
+// aren't resolved, they're reported, and neither are matchers.
+func ResolveSecrets(r Resource) (Resource, error) {
+	v := reflect.ValueOf(r)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return r, nil
+	}
+	orig := v.Elem()
+	copied := reflect.New(orig.Type()).Elem()
+	copied.Set(orig)
+	found := false
+	for i := 0; i < orig.NumField(); i++ {
+		tag := strings.Split(orig.Type().Field(i).Tag.Get("json"), ",")[0]
+		f := copied.Field(i)
+		if tag == "-" || tag == "title" || !f.CanSet() {
+			continue
+		}
+		switch f.Kind() {
+		case reflect.String:
+			if !secretRefPattern.MatchString(f.String()) {
+				continue
+			}
+			s, err := resolveSecrets(f.String())
+			if err != nil {
+				return r, err
+			}
+			f.SetString(s)
+			found = true
+		case reflect.Slice:
+			list, ok := f.Interface().([]string)
+			if !ok {
+				continue
+			}
+			resolved := make([]string, len(list))
+			for j, e := range list {
+				s, err := resolveSecrets(e)
+				if err != nil {
+					return r, err
+				}
+				resolved[j] = s
+				found = found || s != e
+			}
+			f.Set(reflect.ValueOf(resolved))
+		case reflect.Map:
+			m, ok := f.Interface().(map[string]string)
+			if !ok {
+				continue
+			}
+			resolved := make(map[string]string, len(m))
+			for k, e := range m {
+				s, err := resolveSecrets(e)
+				if err != nil {
+					return r, err
+				}
+				resolved[k] = s
+				found = found || s != e
+			}
+			f.Set(reflect.ValueOf(resolved))
+		}
+	}
+	if !found {
+		return r, nil
+	}
+	return copied.Addr().Interface().(Resource), nil
+}
@@ -0,0 +1,51 @@
+package resource
+
+import (
+	"os"
+	"testing"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	os.Setenv("GOSS_TEST_TOKEN", "t0ken")
+	defer os.Unsetenv("GOSS_TEST_TOKEN")
+	ref, err := SecretRef("env:GOSS_TEST_TOKEN")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := &HTTP{
+		HTTP:          "https://" + ref + "@example.com",
+		Title:         ref,
+		Password:      ref,
+		RequestHeader: []string{"Authorization: Bearer " + ref, "Accept: */*"},
+	}
+	resolved, err := ResolveSecrets(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := resolved.(*HTTP)
+	if r.Password != "t0ken" || r.RequestHeader[0] != "Authorization: Bearer t0ken" || r.RequestHeader[1] != "Accept: */*" {
+		t.Errorf("resolved: got password %q and headers %q", r.Password, r.RequestHeader)
+	}
+	if r.HTTP != h.HTTP || r.Title != ref {
+		t.Errorf("the ID and title were resolved: %q, %q", r.HTTP, r.Title)
+	}
+	if h.Password != ref || h.RequestHeader[0] != "Authorization: Bearer "+ref {
+		t.Errorf("the original was resolved: %q, %q", h.Password, h.RequestHeader)
+	}
+
+	plain := &HTTP{HTTP: "https://example.com", Password: "plain"}
+	if same, err := ResolveSecrets(plain); err != nil || same != Resource(plain) {
+		t.Errorf("without references: got a copy, %v", err)
+	}
+
+	for _, bad := range []string{"vault:x", "env:", "env:a`b"} {
+		if _, err := SecretRef(bad); err == nil {
+			t.Errorf("SecretRef(%q): got no error", bad)
+		}
+	}
+	missing, _ := SecretRef("file:/nonexistent/secret")
+	if _, err := ResolveSecrets(&Command{Command: "check", Exec: "check " + missing}); err == nil {
+		t.Error("missing secret file: got no error")
+	}
+}
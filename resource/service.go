@@ -1,37 +1,84 @@
 package resource
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/aelsabbahy/goss/system"
 	"github.com/aelsabbahy/goss/util"
 )
 
 type Service struct {
-	Title   string  `json:"title,omitempty" yaml:"title,omitempty"`
-	Meta    meta    `json:"meta,omitempty" yaml:"meta,omitempty"`
-	Service string  `json:"-" yaml:"-"`
-	Enabled matcher `json:"enabled" yaml:"enabled"`
-	Running matcher `json:"running" yaml:"running"`
-	Skip    bool    `json:"skip,omitempty" yaml:"skip,omitempty"`
+	Title      string             `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta       meta               `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags       []string           `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires   []string           `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity   string             `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Service    string             `json:"-" yaml:"-"`
+	Enabled    matcher            `json:"enabled" yaml:"enabled"`
+	Running    matcher            `json:"running" yaml:"running"`
+	Masked     matcher            `json:"masked,omitempty" yaml:"masked,omitempty"`
+	Failed     matcher            `json:"failed,omitempty" yaml:"failed,omitempty"`
+	NRestarts  matcher            `json:"n-restarts,omitempty" yaml:"n-restarts,omitempty"`
+	Properties map[string]matcher `json:"properties,omitempty" yaml:"properties,omitempty"`
+	// Dependencies are the units of each kind of dependency, such as wants
+	// and after, not the goss resources of Requires
+	Dependencies map[string]matcher `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	Verified     matcher            `json:"verified,omitempty" yaml:"verified,omitempty"`
+	// IfBackendMissing is how the service is reported on hosts without a
+	// service manager: skip, error or fail
+	IfBackendMissing string `json:"if-backend-missing,omitempty" yaml:"if-backend-missing,omitempty"`
+	Skip             bool   `json:"skip,omitempty" yaml:"skip,omitempty"`
 }
 
 func (s *Service) ID() string      { return s.Service }
 func (s *Service) SetID(id string) { s.Service = id }
 
-func (s *Service) GetTitle() string { return s.Title }
-func (s *Service) GetMeta() meta    { return s.Meta }
+func (s *Service) GetTitle() string      { return s.Title }
+func (s *Service) GetMeta() meta         { return s.Meta }
+func (s *Service) GetTags() []string     { return s.Tags }
+func (s *Service) GetRequires() []string { return s.Requires }
+func (s *Service) GetSeverity() string   { return s.Severity }
 
 func (s *Service) Validate(sys *system.System) []TestResult {
 	skip := false
-	sysservice := sys.NewService(s.Service, sys, util.Config{})
+	startTime := time.Now()
+	var sysservice system.Service
+	if err := sys.ServiceManagerMissing(); err != nil && s.IfBackendMissing != "" {
+		sysservice = &missingBackend{name: s.Service, err: err}
+	} else {
+		sysservice = sys.NewService(s.Service, sys, util.Config{})
+	}
 
 	if s.Skip {
 		skip = true
 	}
 
+	// Nothing is started at boot in a container without a service manager
+	_, noBoot := sysservice.(*system.ServiceProc)
+
 	var results []TestResult
-	results = append(results, ValidateValue(s, "enabled", s.Enabled, sysservice.Enabled, skip))
+	results = append(results, ValidateValue(s, "enabled", s.Enabled, sysservice.Enabled, skip || noBoot))
 	results = append(results, ValidateValue(s, "running", s.Running, sysservice.Running, skip))
-	return results
+	if s.Masked != nil {
+		results = append(results, ValidateValue(s, "masked", s.Masked, serviceFailure(sysservice, "masked", system.ServiceFailures.Masked), skip))
+	}
+	if s.Failed != nil {
+		results = append(results, ValidateValue(s, "failed", s.Failed, serviceFailure(sysservice, "failed", system.ServiceFailures.Failed), skip))
+	}
+	if s.NRestarts != nil {
+		results = append(results, ValidateValue(s, "n-restarts", s.NRestarts, serviceRestarts(sysservice), skip))
+	}
+	for _, name := range sortedMatcherKeys(s.Properties) {
+		results = append(results, ValidateValue(s, "properties["+name+"]", s.Properties[name], serviceProperty(sysservice, name), skip))
+	}
+	for _, kind := range sortedMatcherKeys(s.Dependencies) {
+		results = append(results, ValidateValue(s, "dependencies["+kind+"]", ownerMatcher(s.Dependencies[kind]), serviceDependencies(sysservice, kind), skip))
+	}
+	if s.Verified != nil {
+		results = append(results, ValidateValue(s, "verified", s.Verified, serviceVerified(sysservice), skip))
+	}
+	return ifBackendMissing(s, s.IfBackendMissing, results, startTime)
 }
 
 func NewService(sysService system.Service, config util.Config) (*Service, error) {
@@ -50,3 +97,53 @@ func NewService(sysService system.Service, config util.Config) (*Service, error)
 		Running: running,
 	}, nil
 }
+
+func serviceProperty(sysservice system.Service, name string) func() (string, error) {
+	return func() (string, error) {
+		p, ok := sysservice.(system.ServiceProperties)
+		if !ok {
+			return "", fmt.Errorf("service properties are only supported with systemd and SMF")
+		}
+		return p.Property(name)
+	}
+}
+
+func serviceDependencies(sysservice system.Service, kind string) func() ([]string, error) {
+	return func() ([]string, error) {
+		d, ok := sysservice.(system.ServiceDependencies)
+		if !ok {
+			return nil, fmt.Errorf("dependencies are only supported with systemd")
+		}
+		return d.Dependencies(kind)
+	}
+}
+
+func serviceVerified(sysservice system.Service) func() (bool, error) {
+	return func() (bool, error) {
+		d, ok := sysservice.(system.ServiceDependencies)
+		if !ok {
+			return false, fmt.Errorf("verified is only supported with systemd")
+		}
+		return d.Verified()
+	}
+}
+
+func serviceFailure(sysservice system.Service, name string, state func(system.ServiceFailures) (bool, error)) func() (bool, error) {
+	return func() (bool, error) {
+		f, ok := sysservice.(system.ServiceFailures)
+		if !ok {
+			return false, fmt.Errorf("%s is only supported with systemd, init and SMF", name)
+		}
+		return state(f)
+	}
+}
+
+func serviceRestarts(sysservice system.Service) func() (int, error) {
+	return func() (int, error) {
+		f, ok := sysservice.(system.ServiceFailures)
+		if !ok {
+			return 0, fmt.Errorf("n-restarts is only supported with systemd")
+		}
+		return f.Restarts()
+	}
+}
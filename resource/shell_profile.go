@@ -0,0 +1,62 @@
+package resource
+
+import (
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+type ShellProfile struct {
+	Title    string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta     meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags     []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	User     string   `json:"-" yaml:"-"`
+	Umask    matcher  `json:"umask,omitempty" yaml:"umask,omitempty"`
+	Files    matcher  `json:"files,omitempty" yaml:"files,omitempty"`
+	Contains []string `json:"contains,omitempty" yaml:"contains,omitempty"`
+	Skip     bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+func (p *ShellProfile) ID() string      { return p.User }
+func (p *ShellProfile) SetID(id string) { p.User = id }
+
+func (p *ShellProfile) GetTitle() string      { return p.Title }
+func (p *ShellProfile) GetMeta() meta         { return p.Meta }
+func (p *ShellProfile) GetTags() []string     { return p.Tags }
+func (p *ShellProfile) GetRequires() []string { return p.Requires }
+func (p *ShellProfile) GetSeverity() string   { return p.Severity }
+
+func (p *ShellProfile) Validate(sys *system.System) []TestResult {
+	skip := p.Skip
+	sysProfile := sys.NewShellProfile(p.User, sys, util.Config{})
+
+	var results []TestResult
+	if p.Umask != nil {
+		results = append(results, ValidateValue(p, "umask", p.Umask, sysProfile.Umask, skip))
+	}
+	if p.Files != nil {
+		results = append(results, ValidateValue(p, "files", p.Files, sysProfile.Files, skip))
+	}
+	if len(p.Contains) > 0 {
+		results = append(results, ValidateContains(p, "contains", p.Contains, sysProfile.Contains, skip))
+	}
+	return results
+}
+
+func NewShellProfile(sysProfile system.ShellProfile, config util.Config) (*ShellProfile, error) {
+	umask, err := sysProfile.Umask()
+	if err != nil {
+		return nil, err
+	}
+	p := &ShellProfile{
+		User:  sysProfile.User(),
+		Umask: umask,
+	}
+	if !contains(config.IgnoreList, "files") {
+		if files, err := sysProfile.Files(); err == nil {
+			p.Files = files
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,66 @@
+package resource
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var sizeWithUnit = regexp.MustCompile(`^\s*([0-9]+(?:\.[0-9]+)?)\s*([kKMGTP]i?B|B)\s*$`)
+
+var sizeUnits = map[string]float64{
+	"B":   1,
+	"KB":  1e3,
+	"MB":  1e6,
+	"GB":  1e9,
+	"TB":  1e12,
+	"PB":  1e15,
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+	"TIB": 1 << 40,
+	"PIB": 1 << 50,
+}
+
+// sizeMatcher converts sizes with units, e.g. 1GB or 512MiB, anywhere in m to
+// a number of bytes
+func sizeMatcher(m matcher) matcher {
+	switch x := m.(type) {
+	case string:
+		if b, ok := parseSize(x); ok {
+			return b
+		}
+	case []interface{}:
+		out := make([]interface{}, len(x))
+		for i, v := range x {
+			out[i] = sizeMatcher(v)
+		}
+		return out
+	case map[interface{}]interface{}:
+		out := make(map[interface{}]interface{}, len(x))
+		for k, v := range x {
+			out[k] = sizeMatcher(v)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(x))
+		for k, v := range x {
+			out[k] = sizeMatcher(v)
+		}
+		return out
+	}
+	return m
+}
+
+// parseSize parses a size with an SI (KB, MB, ..) or IEC (KiB, MiB, ..) unit into bytes
+func parseSize(s string) (int, bool) {
+	m := sizeWithUnit.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	return int(n * sizeUnits[strings.ToUpper(m[2])]), true
+}
@@ -0,0 +1,28 @@
+package resource
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSizeMatcher(t *testing.T) {
+	tables := []struct {
+		in   matcher
+		want matcher
+	}{
+		{"1GB", 1000000000},
+		{"1.5 KiB", 1536},
+		{"512MiB", 536870912},
+		{2118, 2118},
+		{"not a size", "not a size"},
+		{map[interface{}]interface{}{"lt": "1GB"}, map[interface{}]interface{}{"lt": 1000000000}},
+		{map[string]interface{}{"and": []interface{}{map[string]interface{}{"gt": "1kB"}}},
+			map[string]interface{}{"and": []interface{}{map[string]interface{}{"gt": 1000}}}},
+	}
+
+	for _, table := range tables {
+		if got := sizeMatcher(table.in); !reflect.DeepEqual(got, table.want) {
+			t.Errorf("sizeMatcher (%v) was incorrect, got: %v, want: %v.", table.in, got, table.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package resource
+
+import (
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+type Sockets struct {
+	Title       string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta        meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags        []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires    []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity    string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Name        string   `json:"-" yaml:"-"`
+	Established matcher  `json:"established,omitempty" yaml:"established,omitempty"`
+	SynSent     matcher  `json:"syn-sent,omitempty" yaml:"syn-sent,omitempty"`
+	SynRecv     matcher  `json:"syn-recv,omitempty" yaml:"syn-recv,omitempty"`
+	FinWait     matcher  `json:"fin-wait,omitempty" yaml:"fin-wait,omitempty"`
+	TimeWait    matcher  `json:"time-wait,omitempty" yaml:"time-wait,omitempty"`
+	CloseWait   matcher  `json:"close-wait,omitempty" yaml:"close-wait,omitempty"`
+	LastAck     matcher  `json:"last-ack,omitempty" yaml:"last-ack,omitempty"`
+	Listen      matcher  `json:"listen,omitempty" yaml:"listen,omitempty"`
+	Total       matcher  `json:"total,omitempty" yaml:"total,omitempty"`
+	Skip        bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+func (s *Sockets) ID() string      { return s.Name }
+func (s *Sockets) SetID(id string) { s.Name = id }
+
+func (s *Sockets) GetTitle() string      { return s.Title }
+func (s *Sockets) GetMeta() meta         { return s.Meta }
+func (s *Sockets) GetTags() []string     { return s.Tags }
+func (s *Sockets) GetRequires() []string { return s.Requires }
+func (s *Sockets) GetSeverity() string   { return s.Severity }
+
+func (s *Sockets) Validate(sys *system.System) []TestResult {
+	skip := s.Skip
+	sysSockets := sys.NewSockets(s.Name, sys, util.Config{})
+
+	var results []TestResult
+	counts := []struct {
+		property string
+		expected matcher
+		actual   func() (int, error)
+	}{
+		{"established", s.Established, sysSockets.Established},
+		{"syn-sent", s.SynSent, sysSockets.SynSent},
+		{"syn-recv", s.SynRecv, sysSockets.SynRecv},
+		{"fin-wait", s.FinWait, sysSockets.FinWait},
+		{"time-wait", s.TimeWait, sysSockets.TimeWait},
+		{"close-wait", s.CloseWait, sysSockets.CloseWait},
+		{"last-ack", s.LastAck, sysSockets.LastAck},
+		{"listen", s.Listen, sysSockets.Listen},
+		{"total", s.Total, sysSockets.Total},
+	}
+	for _, c := range counts {
+		if c.expected != nil {
+			results = append(results, ValidateValue(s, c.property, c.expected, c.actual, skip))
+		}
+	}
+	return results
+}
+
+// NewSockets records the states the sockets are in with twice their current
+// count as the limit, so the test passes now and catches sockets piling up
+func NewSockets(sysSockets system.Sockets, config util.Config) (*Sockets, error) {
+	s := &Sockets{Name: sysSockets.Name()}
+	total, err := sysSockets.Total()
+	if err != nil {
+		return nil, err
+	}
+	s.Total = map[string]interface{}{"le": 2 * total}
+	for _, c := range []struct {
+		property string
+		expected *matcher
+		actual   func() (int, error)
+	}{
+		{"established", &s.Established, sysSockets.Established},
+		{"time-wait", &s.TimeWait, sysSockets.TimeWait},
+		{"close-wait", &s.CloseWait, sysSockets.CloseWait},
+	} {
+		if contains(config.IgnoreList, c.property) {
+			continue
+		}
+		if n, err := c.actual(); err == nil && n > 0 {
+			*c.expected = map[string]interface{}{"le": 2 * n}
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,68 @@
+package resource
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+type SQL struct {
+	Title    string             `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta     meta               `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags     []string           `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires []string           `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity string             `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Name     string             `json:"-" yaml:"-"`
+	Driver   string             `json:"driver" yaml:"driver"`
+	DSN      string             `json:"dsn" yaml:"dsn"`
+	Query    string             `json:"query" yaml:"query"`
+	Rows     matcher            `json:"rows,omitempty" yaml:"rows,omitempty"`
+	Value    matcher            `json:"value,omitempty" yaml:"value,omitempty"`
+	Columns  map[string]matcher `json:"columns,omitempty" yaml:"columns,omitempty"`
+	Timeout  int                `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Skip     bool               `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+func (s *SQL) ID() string      { return s.Name }
+func (s *SQL) SetID(id string) { s.Name = id }
+
+func (s *SQL) GetTitle() string      { return s.Title }
+func (s *SQL) GetMeta() meta         { return s.Meta }
+func (s *SQL) GetTags() []string     { return s.Tags }
+func (s *SQL) GetRequires() []string { return s.Requires }
+func (s *SQL) GetSeverity() string   { return s.Severity }
+
+func (s *SQL) Validate(sys *system.System) []TestResult {
+	skip := s.Skip
+	if s.Timeout == 0 {
+		s.Timeout = 10000
+	}
+	sysSQL := sys.NewSQL(s.Name, sys, util.Config{Timeout: time.Duration(s.Timeout) * time.Millisecond})
+	sysSQL.SetQuery(s.Driver, s.DSN, s.Query)
+
+	var results []TestResult
+	if s.Rows != nil {
+		results = append(results, ValidateValue(s, "rows", s.Rows, sysSQL.Rows, skip))
+	}
+	if s.Value != nil {
+		results = append(results, ValidateValue(s, "value", s.Value, sysSQL.Value, skip))
+	}
+	for _, name := range sortedMatcherKeys(s.Columns) {
+		results = append(results, ValidateValue(s, "columns["+name+"]", s.Columns[name], sqlColumn(sysSQL, name), skip))
+	}
+	return results
+}
+
+func sqlColumn(sysSQL system.SQL, name string) func() ([]string, error) {
+	return func() ([]string, error) {
+		return sysSQL.Column(name)
+	}
+}
+
+// NewSQL can't add a query from the command line, it needs the driver, dsn and
+// query
+func NewSQL(sysSQL system.SQL, config util.Config) (*SQL, error) {
+	return nil, fmt.Errorf("sql %s: sql checks can't be added, write the driver, dsn and query in the gossfile", sysSQL.Name())
+}
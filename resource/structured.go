@@ -0,0 +1,257 @@
+package resource
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// structuredOutput parses command output on first use so the command only
+// runs if an assertion isn't skipped, and is parsed once for all of them
+type structuredOutput struct {
+	read   func() (io.Reader, error)
+	parse  func(io.Reader) (interface{}, error)
+	loaded bool
+	doc    interface{}
+	err    error
+}
+
+func (s *structuredOutput) load() (interface{}, error) {
+	if s.loaded {
+		return s.doc, s.err
+	}
+	s.loaded = true
+	r, err := s.read()
+	if err != nil {
+		s.err = err
+		return nil, err
+	}
+	s.doc, s.err = s.parse(r)
+	return s.doc, s.err
+}
+
+// wholeOutput reads output on first use for the attributes that match it
+// as a single string, and the patterns of the same output, which can't be
+// read twice from every reader. With a limit no more than a byte past it is
+// read, and duration is how long reading took.
+type wholeOutput struct {
+	read     func() (io.Reader, error)
+	limit    int
+	loaded   bool
+	data     string
+	duration time.Duration
+	err      error
+}
+
+func (w *wholeOutput) String() (string, error) {
+	if w.loaded {
+		return w.data, w.err
+	}
+	w.loaded = true
+	r, err := w.read()
+	if err != nil {
+		w.err = err
+		return "", err
+	}
+	start := time.Now()
+	if w.limit > 0 {
+		if c, ok := r.(io.Closer); ok {
+			// So the rest isn't downloaded
+			defer c.Close()
+		}
+		r = io.LimitReader(r, int64(w.limit)+1)
+	}
+	data, err := ioutil.ReadAll(r)
+	w.data, w.duration, w.err = string(data), time.Since(start), err
+	return w.data, w.err
+}
+
+func (w *wholeOutput) Reader() (io.Reader, error) {
+	data, err := w.String()
+	return strings.NewReader(data), err
+}
+
+// field returns the found function for the value at path
+func (s *structuredOutput) field(path string, lookup func(interface{}, string) (interface{}, error)) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		doc, err := s.load()
+		if err != nil {
+			return nil, err
+		}
+		return lookup(doc, path)
+	}
+}
+
+func parseJSON(r io.Reader) (interface{}, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("could not parse output as JSON: %v", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("could not parse output as JSON: unexpected data after the top-level value")
+	}
+	return jsonNumbers(doc), nil
+}
+
+// jsonNumbers converts json.Number values to int when they are whole numbers,
+// the same as expected values, and float64 otherwise
+func jsonNumbers(v interface{}) interface{} {
+	switch x := v.(type) {
+	case json.Number:
+		if i, err := strconv.Atoi(x.String()); err == nil {
+			return i
+		}
+		f, _ := x.Float64()
+		if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			return int(f)
+		}
+		return f
+	case []interface{}:
+		for i := range x {
+			x[i] = jsonNumbers(x[i])
+		}
+	case map[string]interface{}:
+		for k := range x {
+			x[k] = jsonNumbers(x[k])
+		}
+	}
+	return v
+}
+
+// jsonPath returns the value at path, a subset of JSONPath supporting the root
+// $, .key, ["key"] and [index] where negative indexes count from the end
+func jsonPath(doc interface{}, path string) (interface{}, error) {
+	p := strings.TrimPrefix(path, "$")
+	if p != "" && p[0] != '.' && p[0] != '[' {
+		p = "." + p
+	}
+	cur := doc
+	for p != "" {
+		var key string
+		index, isIndex := 0, false
+		switch {
+		case p[0] == '.':
+			end := strings.IndexAny(p[1:], ".[")
+			if end == -1 {
+				end = len(p) - 1
+			}
+			key, p = p[1:end+1], p[end+1:]
+			if key == "" {
+				return nil, fmt.Errorf("invalid path %q: empty key", path)
+			}
+		case strings.HasPrefix(p, `["`) || strings.HasPrefix(p, `['`):
+			end := strings.Index(p[2:], string(p[1])+"]")
+			if end == -1 {
+				return nil, fmt.Errorf("invalid path %q: unterminated key", path)
+			}
+			key, p = p[2:end+2], p[end+4:]
+		case p[0] == '[':
+			end := strings.IndexByte(p, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("invalid path %q: unterminated index", path)
+			}
+			i, err := strconv.Atoi(p[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %q: bad index %q", path, p[1:end])
+			}
+			index, isIndex, p = i, true, p[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid path %q", path)
+		}
+
+		if isIndex {
+			list, ok := cur.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s: can't index %s", path, jsonType(cur))
+			}
+			if index < 0 {
+				index += len(list)
+			}
+			if index < 0 || index >= len(list) {
+				return nil, fmt.Errorf("%s: index out of range, length is %d", path, len(list))
+			}
+			cur = list[index]
+			continue
+		}
+		obj, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: can't look up key %q in %s", path, key, jsonType(cur))
+		}
+		if cur, ok = obj[key]; !ok {
+			return nil, fmt.Errorf("%s: key %q not found", path, key)
+		}
+	}
+	return cur, nil
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	}
+	return "a number"
+}
+
+// parseKeyValues parses key=value lines, ignoring blank lines, comments and
+// lines without an =. Whitespace around keys and values and matching quotes
+// around values are removed, later keys override earlier ones
+func parseKeyValues(r io.Reader) (interface{}, error) {
+	kv := make(map[string]interface{})
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxScanTokenSize)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.IndexByte(line, '=')
+		if i <= 0 {
+			continue
+		}
+		key, value := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		kv[key] = value
+	}
+	return kv, scanner.Err()
+}
+
+func keyValue(doc interface{}, key string) (interface{}, error) {
+	v, ok := doc.(map[string]interface{})[key]
+	if !ok {
+		return nil, fmt.Errorf("key %q not found", key)
+	}
+	return v, nil
+}
+
+func sortedMatcherKeys(m map[string]matcher) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
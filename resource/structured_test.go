@@ -0,0 +1,68 @@
+package resource
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONPath(t *testing.T) {
+	doc, err := parseJSON(strings.NewReader(`{"healthy": true, "version": "1.2", "checks": [{"name": "db", "latency": 1.5}, {"name": "cache", "count": 3}], "a.b": {"c": null}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables := []struct {
+		path string
+		want interface{}
+		err  bool
+	}{
+		{"$.healthy", true, false},
+		{"healthy", true, false},
+		{"$.version", "1.2", false},
+		{"$.checks[0].name", "db", false},
+		{"$.checks[0].latency", 1.5, false},
+		{"$.checks[-1].count", 3, false},
+		{`$["a.b"].c`, nil, false},
+		{`$['a.b']['c']`, nil, false},
+		{"$.checks[2]", nil, true},
+		{"$.missing", nil, true},
+		{"$.healthy.x", nil, true},
+		{"$.checks[x]", nil, true},
+		{"$..healthy", nil, true},
+	}
+
+	for _, table := range tables {
+		got, err := jsonPath(doc, table.path)
+		if (err != nil) != table.err {
+			t.Errorf("jsonPath (%s) error: %v, want error: %v.", table.path, err, table.err)
+			continue
+		}
+		if !reflect.DeepEqual(got, table.want) {
+			t.Errorf("jsonPath (%s) was incorrect, got: %#v, want: %#v.", table.path, got, table.want)
+		}
+	}
+
+	if _, err := parseJSON(strings.NewReader(`{"a": 1} trailing`)); err == nil {
+		t.Errorf("parseJSON accepted trailing data")
+	}
+}
+
+func TestParseKeyValues(t *testing.T) {
+	in := `# os-release
+NAME="Ubuntu"
+VERSION_ID='22.04'
+ status = ok
+not a pair
+=novalue
+EMPTY=
+status=degraded
+`
+	got, err := parseKeyValues(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"NAME": "Ubuntu", "VERSION_ID": "22.04", "status": "degraded", "EMPTY": ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseKeyValues was incorrect, got: %v, want: %v.", got, want)
+	}
+}
@@ -0,0 +1,108 @@
+package resource
+
+import (
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+type TrustedBoot struct {
+	Title        string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta         meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags         []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires     []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity     string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Name         string   `json:"-" yaml:"-"`
+	UEFI         matcher  `json:"uefi,omitempty" yaml:"uefi,omitempty"`
+	SecureBoot   matcher  `json:"secure-boot,omitempty" yaml:"secure-boot,omitempty"`
+	SetupMode    matcher  `json:"setup-mode,omitempty" yaml:"setup-mode,omitempty"`
+	TPM          matcher  `json:"tpm,omitempty" yaml:"tpm,omitempty"`
+	TPMVersion   matcher  `json:"tpm-version,omitempty" yaml:"tpm-version,omitempty"`
+	PCRBanks     matcher  `json:"pcr-banks,omitempty" yaml:"pcr-banks,omitempty"`
+	MeasuredBoot matcher  `json:"measured-boot,omitempty" yaml:"measured-boot,omitempty"`
+	Skip         bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+func (t *TrustedBoot) ID() string      { return t.Name }
+func (t *TrustedBoot) SetID(id string) { t.Name = id }
+
+func (t *TrustedBoot) GetTitle() string      { return t.Title }
+func (t *TrustedBoot) GetMeta() meta         { return t.Meta }
+func (t *TrustedBoot) GetTags() []string     { return t.Tags }
+func (t *TrustedBoot) GetRequires() []string { return t.Requires }
+func (t *TrustedBoot) GetSeverity() string   { return t.Severity }
+
+func (t *TrustedBoot) Validate(sys *system.System) []TestResult {
+	skip := t.Skip
+	sysBoot := sys.NewTrustedBoot(t.Name, sys, util.Config{})
+
+	var results []TestResult
+	if t.UEFI != nil {
+		results = append(results, ValidateValue(t, "uefi", t.UEFI, sysBoot.UEFI, skip))
+	}
+	if t.SecureBoot != nil {
+		results = append(results, ValidateValue(t, "secure-boot", t.SecureBoot, sysBoot.SecureBoot, skip))
+	}
+	if t.SetupMode != nil {
+		results = append(results, ValidateValue(t, "setup-mode", t.SetupMode, sysBoot.SetupMode, skip))
+	}
+	if t.TPM != nil {
+		results = append(results, ValidateValue(t, "tpm", t.TPM, sysBoot.TPM, skip))
+	}
+	if t.TPMVersion != nil {
+		results = append(results, ValidateValue(t, "tpm-version", t.TPMVersion, sysBoot.TPMVersion, skip))
+	}
+	if t.PCRBanks != nil {
+		results = append(results, ValidateValue(t, "pcr-banks", t.PCRBanks, sysBoot.PCRBanks, skip))
+	}
+	if t.MeasuredBoot != nil {
+		results = append(results, ValidateValue(t, "measured-boot", t.MeasuredBoot, sysBoot.MeasuredBoot, skip))
+	}
+	return results
+}
+
+func NewTrustedBoot(sysBoot system.TrustedBoot, config util.Config) (*TrustedBoot, error) {
+	uefi, err := sysBoot.UEFI()
+	if err != nil {
+		return nil, err
+	}
+	secureBoot, err := sysBoot.SecureBoot()
+	if err != nil {
+		return nil, err
+	}
+	tpm, err := sysBoot.TPM()
+	if err != nil {
+		return nil, err
+	}
+	t := &TrustedBoot{
+		Name:       sysBoot.Name(),
+		UEFI:       uefi,
+		SecureBoot: secureBoot,
+		TPM:        tpm,
+	}
+	if !tpm {
+		return t, nil
+	}
+	if !contains(config.IgnoreList, "tpm-version") {
+		if v, err := sysBoot.TPMVersion(); err == nil {
+			t.TPMVersion = v
+		}
+	}
+	if !contains(config.IgnoreList, "pcr-banks") {
+		if banks, err := sysBoot.PCRBanks(); err == nil && len(banks) > 0 {
+			t.PCRBanks = banks
+		}
+	}
+	if !contains(config.IgnoreList, "measured-boot") {
+		if m, err := sysBoot.MeasuredBoot(); err == nil {
+			t.MeasuredBoot = m
+		}
+	}
+	return t, nil
+}
+
+func (t *TrustedBoot) preflight(sys *system.System) []string {
+	if t.Skip || t.MeasuredBoot == nil || !unprivileged() {
+		return nil
+	}
+	return []string{"measured-boot: the event log is only visible to root"}
+}
@@ -8,23 +8,37 @@ import (
 )
 
 type User struct {
-	Title    string  `json:"title,omitempty" yaml:"title,omitempty"`
-	Meta     meta    `json:"meta,omitempty" yaml:"meta,omitempty"`
-	Username string  `json:"-" yaml:"-"`
-	Exists   matcher `json:"exists" yaml:"exists"`
-	UID      matcher `json:"uid,omitempty" yaml:"uid,omitempty"`
-	GID      matcher `json:"gid,omitempty" yaml:"gid,omitempty"`
-	Groups   matcher `json:"groups,omitempty" yaml:"groups,omitempty"`
-	Home     matcher `json:"home,omitempty" yaml:"home,omitempty"`
-	Shell    matcher `json:"shell,omitempty" yaml:"shell,omitempty"`
-	Skip     bool    `json:"skip,omitempty" yaml:"skip,omitempty"`
+	Title           string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta            meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags            []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires        []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity        string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Username        string   `json:"-" yaml:"-"`
+	Exists          matcher  `json:"exists" yaml:"exists"`
+	UID             matcher  `json:"uid,omitempty" yaml:"uid,omitempty"`
+	GID             matcher  `json:"gid,omitempty" yaml:"gid,omitempty"`
+	Groups          matcher  `json:"groups,omitempty" yaml:"groups,omitempty"`
+	Home            matcher  `json:"home,omitempty" yaml:"home,omitempty"`
+	Shell           matcher  `json:"shell,omitempty" yaml:"shell,omitempty"`
+	PasswordLocked  matcher  `json:"password-locked,omitempty" yaml:"password-locked,omitempty"`
+	PasswordExpired matcher  `json:"password-expired,omitempty" yaml:"password-expired,omitempty"`
+	PasswordEmpty   matcher  `json:"password-empty,omitempty" yaml:"password-empty,omitempty"`
+	PasswordAge     matcher  `json:"password-age,omitempty" yaml:"password-age,omitempty"`
+	MinDays         matcher  `json:"min-days,omitempty" yaml:"min-days,omitempty"`
+	MaxDays         matcher  `json:"max-days,omitempty" yaml:"max-days,omitempty"`
+	WarnDays        matcher  `json:"warn-days,omitempty" yaml:"warn-days,omitempty"`
+	InactiveDays    matcher  `json:"inactive-days,omitempty" yaml:"inactive-days,omitempty"`
+	Skip            bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
 }
 
 func (u *User) ID() string      { return u.Username }
 func (u *User) SetID(id string) { u.Username = id }
 
-func (u *User) GetTitle() string { return u.Title }
-func (u *User) GetMeta() meta    { return u.Meta }
+func (u *User) GetTitle() string      { return u.Title }
+func (u *User) GetMeta() meta         { return u.Meta }
+func (u *User) GetTags() []string     { return u.Tags }
+func (u *User) GetRequires() []string { return u.Requires }
+func (u *User) GetSeverity() string   { return u.Severity }
 
 func (u *User) Validate(sys *system.System) []TestResult {
 	skip := false
@@ -56,9 +70,49 @@ func (u *User) Validate(sys *system.System) []TestResult {
 	if u.Shell != nil {
 		results = append(results, ValidateValue(u, "shell", u.Shell, sysuser.Shell, skip))
 	}
+	if u.PasswordLocked != nil {
+		results = append(results, ValidateValue(u, "password-locked", u.PasswordLocked, sysuser.PasswordLocked, skip))
+	}
+	if u.PasswordExpired != nil {
+		results = append(results, ValidateValue(u, "password-expired", u.PasswordExpired, sysuser.PasswordExpired, skip))
+	}
+	if u.PasswordEmpty != nil {
+		results = append(results, ValidateValue(u, "password-empty", u.PasswordEmpty, sysuser.PasswordEmpty, skip))
+	}
+	if u.PasswordAge != nil {
+		results = append(results, ValidateValue(u, "password-age", u.PasswordAge, sysuser.PasswordAge, skip))
+	}
+	if u.MinDays != nil {
+		results = append(results, ValidateValue(u, "min-days", u.MinDays, sysuser.MinDays, skip))
+	}
+	if u.MaxDays != nil {
+		results = append(results, ValidateValue(u, "max-days", u.MaxDays, sysuser.MaxDays, skip))
+	}
+	if u.WarnDays != nil {
+		results = append(results, ValidateValue(u, "warn-days", u.WarnDays, sysuser.WarnDays, skip))
+	}
+	if u.InactiveDays != nil {
+		results = append(results, ValidateValue(u, "inactive-days", u.InactiveDays, sysuser.InactiveDays, skip))
+	}
 	return results
 }
 
+// shadowAttributes reports whether u tests fields of the shadow file
+func (u *User) shadowAttributes() bool {
+	return u.PasswordLocked != nil || u.PasswordExpired != nil || u.PasswordEmpty != nil || u.PasswordAge != nil ||
+		u.MinDays != nil || u.MaxDays != nil || u.WarnDays != nil || u.InactiveDays != nil
+}
+
+func (u *User) preflight(sys *system.System) []string {
+	if u.Skip || !u.shadowAttributes() || !unprivileged() {
+		return nil
+	}
+	if problem := unreadable("/etc/shadow"); problem != "" {
+		return []string{"password attributes: " + problem}
+	}
+	return nil
+}
+
 func NewUser(sysUser system.User, config util.Config) (*User, error) {
 	username := sysUser.Username()
 	exists, _ := sysUser.Exists()
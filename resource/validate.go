@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"github.com/onsi/gomega/types"
+
+	"github.com/aelsabbahy/goss/util"
 )
 
 const (
@@ -23,8 +25,59 @@ const (
 	SUCCESS = iota
 	FAIL
 	SKIP
+	// TIMEOUT is the result of resources that didn't finish before the run
+	// deadline, neither passed nor failed
+	TIMEOUT
+	// ERROR is the result of tests that couldn't be checked, such as when the
+	// backend is unavailable or reading the system was denied, as opposed to
+	// FAIL where the system doesn't match the expectation
+	ERROR
+)
+
+// The severities of resources: the tests of fail resources, the default, that
+// don't pass fail the run, those of warn and info resources are reported as
+// warnings
+const (
+	SeverityFail = "fail"
+	SeverityWarn = "warn"
+	SeverityInfo = "info"
 )
 
+// Severities are the severities a resource can have, most severe first
+var Severities = []string{SeverityFail, SeverityWarn, SeverityInfo}
+
+// Severity is the severity of res, fail when it isn't set
+func Severity(res ResourceRead) (string, error) {
+	s := res.GetSeverity()
+	if s == "" {
+		return SeverityFail, nil
+	}
+	if !util.IsValueInList(s, Severities) {
+		return "", fmt.Errorf("unknown severity %q, expected one of: %s", s, strings.Join(Severities, ", "))
+	}
+	return s, nil
+}
+
+// SetSeverity sets the Severity of results, the results of res
+func SetSeverity(results []TestResult, res ResourceRead) {
+	severity, _ := Severity(res)
+	for i := range results {
+		results[i].Severity = severity
+	}
+}
+
+// SetTags records the tags of res on its results, so outputs can summarize
+// the results by tag
+func SetTags(results []TestResult, res ResourceRead) {
+	tags := res.GetTags()
+	if len(tags) == 0 {
+		return
+	}
+	for i := range results {
+		results[i].Tags = tags
+	}
+}
+
 const (
 	maxScanTokenSize = 10 * 1024 * 1024
 )
@@ -43,6 +96,58 @@ type TestResult struct {
 	Found        []string      `json:"found" yaml:"found"`
 	Human        string        `json:"human" yaml:"human"`
 	Duration     time.Duration `json:"duration" yaml:"duration"`
+	Maintenance  string        `json:"maintenance,omitempty" yaml:"maintenance,omitempty"`
+	Baseline     bool          `json:"baseline,omitempty" yaml:"baseline,omitempty"`
+	// Attempts is how many times the resource was validated when it was
+	// retried, the result is that of the last attempt
+	Attempts int `json:"attempts,omitempty" yaml:"attempts,omitempty"`
+	// SkipReason is why the resource was skipped when it wasn't validated,
+	// such as a requirement that didn't pass
+	SkipReason string `json:"skip-reason,omitempty" yaml:"skip-reason,omitempty"`
+	// Severity is the severity of the resource, see Severities
+	Severity string `json:"severity,omitempty" yaml:"severity,omitempty"`
+	// Tags are the tags of the resource
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// Warning reports whether the test didn't pass but only warns, because it's
+// quarantined, flaky or of a resource whose Severity is warn or info,
+// Maintenance holds the reason of the maintenance window it's in or it already
+// didn't pass in the Baseline, warnings don't affect the exit code
+func (r TestResult) Warning() bool {
+	if r.Result == SUCCESS || r.Result == SKIP {
+		return false
+	}
+	return r.Quarantined() || r.Flaky() || r.Maintenance != "" || r.Baseline || r.Informational()
+}
+
+// Informational reports whether the test didn't pass and belongs to a
+// resource whose severity is warn or info
+func (r TestResult) Informational() bool {
+	if r.Result == SUCCESS || r.Result == SKIP {
+		return false
+	}
+	return r.Severity == SeverityWarn || r.Severity == SeverityInfo
+}
+
+// Flaky reports whether the test didn't pass, after its retries, and belongs
+// to a resource marked as eventually consistent with meta.flaky
+func (r TestResult) Flaky() bool {
+	if r.Result == SUCCESS || r.Result == SKIP {
+		return false
+	}
+	f, _ := r.Meta["flaky"].(bool)
+	return f
+}
+
+// Quarantined reports whether the test didn't pass and belongs to a resource
+// marked as flaky with meta.quarantined
+func (r TestResult) Quarantined() bool {
+	if r.Result == SUCCESS || r.Result == SKIP {
+		return false
+	}
+	q, _ := r.Meta["quarantined"].(bool)
+	return q
 }
 
 func skipResult(typeS string, testType int, id string, title string, meta meta, property string, startTime time.Time) TestResult {
@@ -59,6 +164,72 @@ func skipResult(typeS string, testType int, id string, title string, meta meta,
 	}
 }
 
+// TimedOutResult is the result of res when the run deadline expired before
+// it was validated, startTime is when the run started
+func TimedOutResult(res ResourceRead, startTime time.Time) TestResult {
+	return TestResult{
+		Successful:   false,
+		Result:       TIMEOUT,
+		ResourceType: strings.Split(reflect.TypeOf(res).String(), ".")[1],
+		TestType:     Value,
+		ResourceId:   res.ID(),
+		Title:        res.GetTitle(),
+		Meta:         res.GetMeta(),
+		Duration:     time.Since(startTime),
+	}
+}
+
+// PanicResult is the result of res when validating it panicked with
+// recovered, the panic is reported as an error of the resource so the rest of
+// the run completes
+func PanicResult(res ResourceRead, recovered interface{}, startTime time.Time) TestResult {
+	return TestResult{
+		Successful:   false,
+		Result:       ERROR,
+		ResourceType: strings.Split(reflect.TypeOf(res).String(), ".")[1],
+		TestType:     Value,
+		ResourceId:   res.ID(),
+		Title:        res.GetTitle(),
+		Meta:         res.GetMeta(),
+		Err:          util.NewCodedError(util.ErrCodePanic, fmt.Errorf("panic: %v", recovered)),
+		Duration:     time.Since(startTime),
+	}
+}
+
+// ConfigErrorResult is the result of res when property, such as its retry
+// policy or a secret reference, is invalid and it isn't validated
+func ConfigErrorResult(res ResourceRead, property string, err error, startTime time.Time) TestResult {
+	return TestResult{
+		Successful:   false,
+		Result:       ERROR,
+		ResourceType: strings.Split(reflect.TypeOf(res).String(), ".")[1],
+		TestType:     Value,
+		ResourceId:   res.ID(),
+		Title:        res.GetTitle(),
+		Meta:         res.GetMeta(),
+		Property:     property,
+		Err:          util.NewCodedError(util.ErrCodeConfigInvalid, err),
+		Duration:     time.Since(startTime),
+	}
+}
+
+// SkippedResult is the result of res when it isn't validated because of
+// reason, property is what caused it
+func SkippedResult(res ResourceRead, property, reason string, startTime time.Time) TestResult {
+	return TestResult{
+		Successful:   true,
+		Result:       SKIP,
+		ResourceType: strings.Split(reflect.TypeOf(res).String(), ".")[1],
+		TestType:     Value,
+		ResourceId:   res.ID(),
+		Title:        res.GetTitle(),
+		Meta:         res.GetMeta(),
+		Property:     property,
+		SkipReason:   reason,
+		Duration:     time.Since(startTime),
+	}
+}
+
 func ValidateValue(res ResourceRead, property string, expectedValue interface{}, actual interface{}, skip bool) TestResult {
 	id := res.ID()
 	title := res.GetTitle()
@@ -78,36 +249,32 @@ func ValidateValue(res ResourceRead, property string, expectedValue interface{},
 		)
 	}
 
-	var foundValue interface{}
-	var err error
-	switch f := actual.(type) {
-	case func() (bool, error):
-		foundValue, err = f()
-	case func() (string, error):
-		foundValue, err = f()
-	case func() (int, error):
-		foundValue, err = f()
-	case func() ([]string, error):
-		foundValue, err = f()
-	case func() (interface{}, error):
-		foundValue, err = f()
-	default:
-		err = fmt.Errorf("Unknown method signature: %t", f)
-	}
+	foundValue, err := readValue(factKey(typeS, id, property), actual)
 
 	expectedValue = sanitizeExpectedValue(expectedValue)
+	// Whole numbers of the gossfile are ints, a float value equals them as a float
+	if e, ok := expectedValue.(int); ok {
+		if _, ok := foundValue.(float64); ok {
+			expectedValue = float64(e)
+		}
+	}
 	var gomegaMatcher types.GomegaMatcher
 	var success bool
 	if err == nil {
-		gomegaMatcher, err = matcherToGomegaMatcher(expectedValue)
+		if gomegaMatcher, err = matcherToGomegaMatcher(expectedValue); err != nil {
+			err = util.NewCodedError(util.ErrCodeMatcherInvalid, err)
+		}
 	}
 	if err == nil {
-		success, err = gomegaMatcher.Match(foundValue)
+		// Matchers only fail to match when the value isn't of their type
+		if success, err = gomegaMatcher.Match(foundValue); err != nil {
+			err = util.NewCodedError(util.ErrCodeMatcherInvalid, err)
+		}
 	}
 	if err != nil {
 		return TestResult{
 			Successful:   false,
-			Result:       FAIL,
+			Result:       ERROR,
 			ResourceType: typeS,
 			TestType:     Values,
 			ResourceId:   id,
@@ -123,6 +290,9 @@ func ValidateValue(res ResourceRead, property string, expectedValue interface{},
 	var result int
 	if !success {
 		failMessage = gomegaMatcher.FailureMessage(foundValue)
+		if diff := failureDiff(expectedValue, foundValue); diff != "" {
+			failMessage = diff
+		}
 		result = FAIL
 	}
 
@@ -285,12 +455,12 @@ func ValidateContains(res ResourceRead, property string, expectedValues []string
 		}
 	}
 	if err == nil {
-		fh, err = method()
+		fh, err = readContents(factKey(typeS, id, property), method)
 	}
 	if err != nil {
 		return TestResult{
 			Successful:   false,
-			Result:       FAIL,
+			Result:       ERROR,
 			ResourceType: typeS,
 			TestType:     Contains,
 			ResourceId:   id,
@@ -335,7 +505,7 @@ func ValidateContains(res ResourceRead, property string, expectedValues []string
 	if err := scanner.Err(); err != nil {
 		return TestResult{
 			Successful:   false,
-			Result:       FAIL,
+			Result:       ERROR,
 			ResourceType: typeS,
 			TestType:     Contains,
 			ResourceId:   id,
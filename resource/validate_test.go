@@ -3,6 +3,7 @@ package resource
 import (
 	"fmt"
 	"io"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -18,6 +19,12 @@ func (f *FakeResource) GetTitle() string { return "title" }
 
 func (f *FakeResource) GetMeta() meta { return meta{"foo": "bar"} }
 
+func (f *FakeResource) GetTags() []string { return nil }
+
+func (f *FakeResource) GetRequires() []string { return nil }
+
+func (f *FakeResource) GetSeverity() string { return "" }
+
 var stringTests = []struct {
 	in, in2 interface{}
 	want    bool
@@ -41,6 +48,20 @@ func TestValidateValue(t *testing.T) {
 	}
 }
 
+func TestValidateValueFloat(t *testing.T) {
+	offset := func() (float64, error) { return 0.5, nil }
+	if got := ValidateValue(&FakeResource{""}, "offset", map[string]interface{}{"lt": 1}, offset, false); !got.Successful {
+		t.Errorf("float64 value: got %v %v, want success", got.Result, got.Err)
+	}
+	if got := ValidateValue(&FakeResource{""}, "offset", map[string]interface{}{"lt": 0.7}, offset, false); !got.Successful {
+		t.Errorf("float64 value: got %v %v, want 0.5 to be lt 0.7", got.Result, got.Err)
+	}
+	zero := func() (float64, error) { return 0, nil }
+	if got := ValidateValue(&FakeResource{""}, "loss", 0, zero, false); !got.Successful {
+		t.Errorf("float64 value: got %v %v, want 0 to equal 0.0", got.Result, got.Err)
+	}
+}
+
 func TestValidateValueErr(t *testing.T) {
 	for _, c := range stringTests {
 		inFunc := func() (interface{}, error) {
@@ -139,3 +160,21 @@ func TestValidateContainsSkip(t *testing.T) {
 		}
 	}
 }
+
+// The expected numbers of every resource are sanitized, JSON decodes them
+// all as float64 and only whole ones are ints
+func TestSanitizeExpectedValue(t *testing.T) {
+	tests := []struct {
+		in, want interface{}
+	}{
+		{float64(3), 3},
+		{1.5, 1.5},
+		{map[interface{}]interface{}{"lt": float64(10)}, map[string]interface{}{"lt": 10}},
+		{map[interface{}]interface{}{"lt": 0.5}, map[string]interface{}{"lt": 0.5}},
+	}
+	for _, tt := range tests {
+		if got := sanitizeExpectedValue(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sanitizeExpectedValue(%#v): got %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package resource
+
+import (
+	"time"
+
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+type WebSocket struct {
+	Title         string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Meta          meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Tags          []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires      []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Severity      string   `json:"severity,omitempty" yaml:"severity,omitempty"`
+	URL           string   `json:"-" yaml:"-"`
+	Connected     matcher  `json:"connected" yaml:"connected"`
+	Status        matcher  `json:"status,omitempty" yaml:"status,omitempty"`
+	Subprotocol   matcher  `json:"subprotocol,omitempty" yaml:"subprotocol,omitempty"`
+	Reply         matcher  `json:"reply,omitempty" yaml:"reply,omitempty"`
+	Latency       matcher  `json:"latency,omitempty" yaml:"latency,omitempty"`
+	Send          string   `json:"send,omitempty" yaml:"send,omitempty"`
+	Protocols     []string `json:"protocols,omitempty" yaml:"protocols,omitempty"`
+	Origin        string   `json:"origin,omitempty" yaml:"origin,omitempty"`
+	RequestHeader []string `json:"request-headers,omitempty" yaml:"request-headers,omitempty"`
+	AllowInsecure bool     `json:"allow-insecure,omitempty" yaml:"allow-insecure,omitempty"`
+	CAFile        string   `json:"ca-file,omitempty" yaml:"ca-file,omitempty"`
+	ClientCert    string   `json:"client-cert,omitempty" yaml:"client-cert,omitempty"`
+	ClientKey     string   `json:"client-key,omitempty" yaml:"client-key,omitempty"`
+	Timeout       int      `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	IPVersion     matcher  `json:"ip-version,omitempty" yaml:"ip-version,omitempty"`
+	Netns         string   `json:"netns,omitempty" yaml:"netns,omitempty"`
+	Skip          bool     `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+func (w *WebSocket) ID() string      { return w.URL }
+func (w *WebSocket) SetID(id string) { w.URL = id }
+
+func (w *WebSocket) GetTitle() string      { return w.Title }
+func (w *WebSocket) GetMeta() meta         { return w.Meta }
+func (w *WebSocket) GetTags() []string     { return w.Tags }
+func (w *WebSocket) GetRequires() []string { return w.Requires }
+func (w *WebSocket) GetSeverity() string   { return w.Severity }
+
+func (w *WebSocket) Validate(sys *system.System) []TestResult {
+	skip := w.Skip
+	if w.Timeout == 0 {
+		w.Timeout = 5000
+	}
+
+	version, err := ipVersion(w.IPVersion)
+	sysWS := sys.NewWebSocket(w.URL, sys, util.Config{
+		AllowInsecure: w.AllowInsecure, CAFile: w.CAFile, ClientCert: w.ClientCert, ClientKey: w.ClientKey,
+		RequestHeader: w.RequestHeader, Timeout: time.Duration(w.Timeout) * time.Millisecond,
+		IPVersion: version, Netns: w.Netns})
+	sysWS.SetHandshake(w.Protocols, w.Origin)
+	sysWS.SetMessage(w.Send, w.Reply != nil)
+	connected := sysWS.Connected
+	if err != nil {
+		connected = func() (bool, error) { return false, err }
+	}
+
+	var results []TestResult
+	results = append(results, ValidateValue(w, "connected", w.Connected, connected, skip))
+	// The status of a refused upgrade is still checked
+	if w.Status != nil {
+		results = append(results, ValidateValue(w, "status", w.Status, sysWS.Status, skip || results[0].Err != nil))
+	}
+	if shouldSkip(results) {
+		skip = true
+	}
+	if w.Subprotocol != nil {
+		results = append(results, ValidateValue(w, "subprotocol", w.Subprotocol, sysWS.Subprotocol, skip))
+	}
+	if w.Reply != nil {
+		results = append(results, ValidateValue(w, "reply", w.Reply, sysWS.Reply, skip))
+	}
+	if w.Latency != nil {
+		results = append(results, ValidateValue(w, "latency", w.Latency, sysWS.Latency, skip))
+	}
+	return results
+}
+
+func NewWebSocket(sysWS system.WebSocket, config util.Config) (*WebSocket, error) {
+	connected, err := sysWS.Connected()
+	w := &WebSocket{
+		URL:       sysWS.URL(),
+		Connected: connected,
+		Timeout:   config.TimeOutMilliSeconds(),
+	}
+	return w, err
+}
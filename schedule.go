@@ -0,0 +1,225 @@
+package goss
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// splayRand is seeded per process, the hosts of a fleet would otherwise
+// all be delayed the same
+var splayRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+// WatchSchedule is an entry of --schedule-file, the resources with one of
+// Tags are validated by --watch on the Cron schedule rather than every
+// --interval
+type WatchSchedule struct {
+	Tags []string `json:"tags" yaml:"tags"`
+	// Cron is a five field crontab schedule in local time, or one of @hourly,
+	// @daily, @weekly, @monthly, @yearly and @every <duration>
+	Cron string `json:"cron" yaml:"cron"`
+	// Splay is the maximum random delay of every run, so the hosts of a fleet
+	// don't all validate at the same time
+	Splay string `json:"splay,omitempty" yaml:"splay,omitempty"`
+
+	cron  *cronSchedule
+	every time.Duration
+	splay time.Duration
+	// skipTags are those of the other schedules, for the schedule of the
+	// resources no schedule has a tag of
+	skipTags []string
+}
+
+// loadSchedules reads the schedules of file, followed by that of the other
+// resources every interval
+func loadSchedules(file string, interval time.Duration) ([]*WatchSchedule, error) {
+	var schedules []*WatchSchedule
+	if file != "" {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("schedule file error: %v", err)
+		}
+		if err := unmarshalYAML(data, &schedules); err != nil {
+			return nil, err
+		}
+	}
+	var tags []string
+	for i, s := range schedules {
+		if len(s.Tags) == 0 {
+			return nil, fmt.Errorf("schedule %d: tags are required", i+1)
+		}
+		var err error
+		if every := strings.TrimPrefix(s.Cron, "@every "); every != s.Cron {
+			if s.every, err = time.ParseDuration(strings.TrimSpace(every)); err != nil || s.every <= 0 {
+				return nil, fmt.Errorf("schedule %d: invalid cron %q", i+1, s.Cron)
+			}
+		} else if s.cron, err = parseCron(s.Cron); err != nil {
+			return nil, fmt.Errorf("schedule %d: %v", i+1, err)
+		}
+		if s.Splay != "" {
+			if s.splay, err = time.ParseDuration(s.Splay); err != nil || s.splay < 0 {
+				return nil, fmt.Errorf("schedule %d: invalid splay %q", i+1, s.Splay)
+			}
+		}
+		tags = append(tags, s.Tags...)
+	}
+	return append(schedules, &WatchSchedule{every: interval, skipTags: tags}), nil
+}
+
+// name is how the output of --watch refers to the schedule
+func (s *WatchSchedule) name() string {
+	if len(s.Tags) == 0 {
+		return "untagged"
+	}
+	return strings.Join(s.Tags, ",")
+}
+
+// selection is the part of gossConfig validated on the schedule
+func (s *WatchSchedule) selection(gossConfig GossConfig) GossConfig {
+	if len(s.Tags) == 0 && len(s.skipTags) == 0 {
+		return gossConfig
+	}
+	return selectTags(gossConfig, s.Tags, s.skipTags)
+}
+
+// next is when the schedule runs next after a run that ended at t, delayed by
+// up to its splay
+func (s *WatchSchedule) next(t time.Time) time.Time {
+	var next time.Time
+	if s.cron != nil {
+		next = s.cron.next(t)
+	} else {
+		next = t.Add(s.every)
+	}
+	if s.splay > 0 {
+		next = next.Add(time.Duration(splayRand.Int63n(int64(s.splay))))
+	}
+	return next
+}
+
+// cronSchedule holds the minutes, hours, days of the month, months and days
+// of the week, Sunday being 0, a crontab schedule matches as bits
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// Like cron, when both days are restricted either matches
+	anyDOM, anyDOW bool
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+func parseCron(spec string) (*cronSchedule, error) {
+	expanded := spec
+	if d, ok := cronDescriptors[strings.TrimSpace(spec)]; ok {
+		expanded = d
+	}
+	fields := strings.Fields(expanded)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron %q: expected 5 fields, found %d", spec, len(fields))
+	}
+	c := &cronSchedule{anyDOM: fields[2] == "*", anyDOW: fields[4] == "*"}
+	bounds := []struct {
+		bits     *uint64
+		min, max int
+	}{{&c.minute, 0, 59}, {&c.hour, 0, 23}, {&c.dom, 1, 31}, {&c.month, 1, 12}, {&c.dow, 0, 7}}
+	for i, b := range bounds {
+		bits, err := parseCronField(fields[i], b.min, b.max)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron %q: %v", spec, err)
+		}
+		*b.bits = bits
+	}
+	// 7 is also Sunday
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	if c.next(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)).IsZero() {
+		return nil, fmt.Errorf("invalid cron %q: it never runs", spec)
+	}
+	return c, nil
+}
+
+// parseCronField parses a comma separated list of *, n and n-m, each with an
+// optional /step, to bits
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		rng, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(item[i+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", item)
+			}
+			rng = item[:i]
+		}
+		low, high := min, max
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if low, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("invalid value %q", item)
+			}
+			high = low
+			if len(bounds) == 2 {
+				if high, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("invalid value %q", item)
+				}
+			} else if step > 1 {
+				high = max
+			}
+		}
+		if low < min || high > max || low > high {
+			return 0, fmt.Errorf("%q is out of the range %d-%d", item, min, max)
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (c *cronSchedule) matchesDay(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.anyDOM && c.anyDOW:
+		return true
+	case c.anyDOM:
+		return dow
+	case c.anyDOW:
+		return dom
+	}
+	return dom || dow
+}
+
+// next is the first minute after t the schedule matches, zero when there's
+// none within five years, as for February 30
+func (c *cronSchedule) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
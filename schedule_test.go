@@ -0,0 +1,118 @@
+package goss
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/resource"
+)
+
+func TestCronNext(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2021, 3, 3, 10, 17, 30, 0, time.UTC)
+	for spec, want := range map[string]string{
+		"*/15 * * * *":    "2021-03-03T10:30:00Z",
+		"@hourly":         "2021-03-03T11:00:00Z",
+		"@daily":          "2021-03-04T00:00:00Z",
+		"30 2 * * 1-5":    "2021-03-04T02:30:00Z",
+		"0 9 * * 7":       "2021-03-07T09:00:00Z",
+		"0 0 1,15 * *":    "2021-03-15T00:00:00Z",
+		"0 0 13 * 5":      "2021-03-05T00:00:00Z",
+		"0 0 29 2 *":      "2024-02-29T00:00:00Z",
+		"5-10/5 10 * * *": "2021-03-04T10:05:00Z",
+	} {
+		c, err := parseCron(spec)
+		if err != nil {
+			t.Errorf("%s: %v", spec, err)
+			continue
+		}
+		if got := c.next(from).Format(time.RFC3339); got != want {
+			t.Errorf("%s: got %s, want %s", spec, got, want)
+		}
+	}
+
+	for _, spec := range []string{"* * * *", "60 * * * *", "0 0 30 2 *", "*/0 * * * *", "a * * * *", "5-1 * * * *"} {
+		if _, err := parseCron(spec); err == nil {
+			t.Errorf("%s: got no error", spec)
+		}
+	}
+}
+
+func TestLoadSchedules(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "schedules.yaml")
+	write := func(content string) {
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`- tags: [baseline]
+  cron: "@hourly"
+  splay: 10m
+- tags: [storage, backup]
+  cron: "@every 24h"
+`)
+	schedules, err := loadSchedules(file, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schedules) != 3 || schedules[0].name() != "baseline" || schedules[1].name() != "storage,backup" || schedules[2].name() != "untagged" {
+		t.Fatalf("got %d schedules", len(schedules))
+	}
+
+	now := time.Date(2021, 3, 3, 10, 17, 30, 0, time.UTC)
+	for i := 0; i < 20; i++ {
+		if next := schedules[0].next(now); next.Before(time.Date(2021, 3, 3, 11, 0, 0, 0, time.UTC)) || !next.Before(time.Date(2021, 3, 3, 11, 10, 0, 0, time.UTC)) {
+			t.Errorf("baseline: got %s, want 11:00 splayed by up to 10m", next)
+		}
+	}
+	if next := schedules[1].next(now); !next.Equal(now.Add(24 * time.Hour)) {
+		t.Errorf("storage: got %s", next)
+	}
+	if next := schedules[2].next(now); !next.Equal(now.Add(time.Minute)) {
+		t.Errorf("untagged: got %s", next)
+	}
+
+	g, err := ReadJSONData([]byte(`{"command": {
+		"quick": {"exec": "true", "exit-status": 0, "tags": ["baseline"]},
+		"slow": {"exec": "true", "exit-status": 0, "tags": ["backup"]},
+		"both": {"exec": "true", "exit-status": 0, "tags": ["baseline", "storage"]},
+		"other": {"exec": "true", "exit-status": 0, "tags": ["smoke"]},
+		"none": {"exec": "true", "exit-status": 0}
+	}}`), true)
+	checkErr(t, err, "reading gossfile failed")
+	ids := func(s *WatchSchedule) string {
+		var ids []string
+		selected := s.selection(g)
+		for _, r := range selected.Resources() {
+			ids = append(ids, r.(resource.ResourceRead).ID())
+		}
+		sort.Strings(ids)
+		return strings.Join(ids, ",")
+	}
+	for i, want := range []string{"both,quick", "both,slow", "none,other"} {
+		if got := ids(schedules[i]); got != want {
+			t.Errorf("%s: got %s, want %s", schedules[i].name(), got, want)
+		}
+	}
+
+	if schedules, err := loadSchedules("", time.Minute); err != nil || len(schedules) != 1 || ids(schedules[0]) != "both,none,other,quick,slow" {
+		t.Errorf("without a schedule file: got %d schedules, %v", len(schedules), err)
+	}
+
+	for content, want := range map[string]string{
+		"- cron: \"@daily\"\n":                          "schedule 1: tags are required",
+		"- tags: [a]\n  cron: \"0 0 * *\"\n":            `schedule 1: invalid cron "0 0 * *": expected 5 fields, found 4`,
+		"- tags: [a]\n  cron: \"@every x\"\n":           `schedule 1: invalid cron "@every x"`,
+		"- tags: [a]\n  cron: \"@daily\"\n  splay: x\n": `schedule 1: invalid splay "x"`,
+	} {
+		write(content)
+		if _, err := loadSchedules(file, time.Minute); err == nil || err.Error() != want {
+			t.Errorf("%q: got %v, want %s", content, err, want)
+		}
+	}
+}
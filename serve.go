@@ -2,54 +2,292 @@ package goss
 
 import (
 	"bytes"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/aelsabbahy/goss/outputs"
+	"github.com/aelsabbahy/goss/resource"
 	"github.com/aelsabbahy/goss/system"
 	"github.com/aelsabbahy/goss/util"
 	"github.com/fatih/color"
 	"github.com/patrickmn/go-cache"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 func Serve(c *util.Config) error {
-	endpoint := c.Endpoint
-	health, err := newHealthHandler(c)
+	mux, grpcHealth, err := newServeMux(c)
 	if err != nil {
 		return err
 	}
-	http.Handle(endpoint, health)
-	log.Printf("Starting to listen on: %s", c.ListenAddress)
-	return http.ListenAndServe(c.ListenAddress, nil)
+	tlsConfig, err := serveTLSConfig(c)
+	if err != nil {
+		return err
+	}
+	if c.BasicAuth != "" && !strings.Contains(c.BasicAuth, ":") {
+		return fmt.Errorf("basic-auth must be user:password")
+	}
+	servers := []*http.Server{{
+		Addr:      c.ListenAddress,
+		Handler:   authHandler{next: mux, basicAuth: c.BasicAuth, bearerToken: c.BearerToken},
+		TLSConfig: tlsConfig,
+	}}
+	if c.GRPCListenAddress != "" {
+		var handler http.Handler = authHandler{next: grpcHealth, basicAuth: c.BasicAuth, bearerToken: c.BearerToken}
+		if tlsConfig == nil {
+			// gRPC is HTTP/2, without TLS it's negotiated with the prior knowledge of h2c
+			handler = h2c.NewHandler(handler, &http2.Server{})
+		}
+		servers = append(servers, &http.Server{Addr: c.GRPCListenAddress, Handler: handler, TLSConfig: tlsConfig})
+	}
+
+	errs := make(chan error, len(servers))
+	for _, server := range servers {
+		log.Printf("Starting to listen on: %s", server.Addr)
+		go func(server *http.Server) {
+			if tlsConfig != nil {
+				errs <- server.ListenAndServeTLS(c.TLSCert, c.TLSKey)
+			} else {
+				errs <- server.ListenAndServe()
+			}
+		}(server)
+	}
+	return <-errs
+}
+
+// newServeMux routes the health endpoints, and the metrics of --endpoint, and
+// is the gRPC health service of the endpoints
+func newServeMux(c *util.Config) (*http.ServeMux, grpcHealthHandler, error) {
+	endpoints, err := serveEndpoints(c)
+	if err != nil {
+		return nil, grpcHealthHandler{}, err
+	}
+	mux := http.NewServeMux()
+	grpcHealth := grpcHealthHandler{services: map[string]*healthHandler{}}
+	for _, e := range endpoints {
+		health, err := newHealthHandler(e.config)
+		if err != nil {
+			return nil, grpcHealthHandler{}, fmt.Errorf("endpoint %s: %v", e.path, err)
+		}
+		mux.Handle(e.path, health)
+		grpcHealth.services[strings.TrimPrefix(e.path, "/")] = health
+		if e.path == c.Endpoint {
+			grpcHealth.services[""] = health
+			if c.MetricsEndpoint != "" {
+				mux.Handle(c.MetricsEndpoint, metricsHandler{health})
+			}
+		}
+	}
+	return mux, grpcHealth, nil
+}
+
+// ServeEndpoint is an endpoint of --serve-config, serving the results of its
+// own gossfile cached for its own duration
+type ServeEndpoint struct {
+	Path     string `json:"path" yaml:"path"`
+	Gossfile string `json:"gossfile" yaml:"gossfile"`
+	// Cache is a duration such as 60s, --cache when empty
+	Cache string `json:"cache,omitempty" yaml:"cache,omitempty"`
+}
+
+type serveEndpoint struct {
+	path   string
+	config *util.Config
+}
+
+// serveEndpoints are the --endpoint of the --gossfile and the endpoints of
+// --serve-endpoint and --serve-config, which replace --endpoint when one of
+// them has its path
+func serveEndpoints(c *util.Config) ([]serveEndpoint, error) {
+	var endpoints []ServeEndpoint
+	if c.ServeConfig != "" {
+		data, err := ioutil.ReadFile(c.ServeConfig)
+		if err != nil {
+			return nil, fmt.Errorf("serve config error: %v", err)
+		}
+		if err := unmarshalYAML(data, &endpoints); err != nil {
+			return nil, fmt.Errorf("serve config error: %v", err)
+		}
+	}
+	for _, spec := range c.ServeEndpoints {
+		e, err := parseServeEndpoint(spec)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, e)
+	}
+
+	var result []serveEndpoint
+	paths := map[string]bool{}
+	for _, e := range endpoints {
+		if !strings.HasPrefix(e.Path, "/") {
+			return nil, fmt.Errorf("endpoint path must start with /, got: %q", e.Path)
+		}
+		if e.Gossfile == "" {
+			return nil, fmt.Errorf("endpoint %s: gossfile is required", e.Path)
+		}
+		if paths[e.Path] || e.Path == c.MetricsEndpoint {
+			return nil, fmt.Errorf("endpoint %s is served more than once", e.Path)
+		}
+		paths[e.Path] = true
+		config := *c
+		config.Spec = e.Gossfile
+		if e.Cache != "" {
+			cache, err := time.ParseDuration(e.Cache)
+			if err != nil {
+				return nil, fmt.Errorf("endpoint %s: invalid cache: %v", e.Path, err)
+			}
+			config.Cache = cache
+		}
+		result = append(result, serveEndpoint{path: e.Path, config: &config})
+	}
+	if !paths[c.Endpoint] {
+		result = append([]serveEndpoint{{path: c.Endpoint, config: c}}, result...)
+	}
+	return result, nil
+}
+
+// parseServeEndpoint parses a path=gossfile[@cache] spec of --serve-endpoint
+func parseServeEndpoint(spec string) (ServeEndpoint, error) {
+	i := strings.Index(spec, "=")
+	if i < 0 {
+		return ServeEndpoint{}, fmt.Errorf("serve-endpoint must be path=gossfile[@cache], got: %s", spec)
+	}
+	e := ServeEndpoint{Path: spec[:i], Gossfile: spec[i+1:]}
+	if j := strings.LastIndex(e.Gossfile, "@"); j >= 0 {
+		e.Gossfile, e.Cache = e.Gossfile[:j], e.Gossfile[j+1:]
+	}
+	return e, nil
+}
+
+// serveTLSConfig is the TLS configuration of the endpoints, nil to serve
+// them over plain HTTP
+func serveTLSConfig(c *util.Config) (*tls.Config, error) {
+	if c.TLSCert == "" && c.TLSKey == "" {
+		if c.TLSClientCA != "" {
+			return nil, fmt.Errorf("tls-client-ca needs tls-cert and tls-key")
+		}
+		return nil, nil
+	}
+	if c.TLSCert == "" || c.TLSKey == "" {
+		return nil, fmt.Errorf("tls-cert and tls-key must be provided together")
+	}
+	if _, err := tls.LoadX509KeyPair(c.TLSCert, c.TLSKey); err != nil {
+		return nil, fmt.Errorf("could not load tls-cert: %v", err)
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.TLSClientCA != "" {
+		pem, err := ioutil.ReadFile(c.TLSClientCA)
+		if err != nil {
+			return nil, fmt.Errorf("could not read tls-client-ca: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in tls-client-ca: %s", c.TLSClientCA)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// authHandler only passes requests with the basic auth user:password or the
+// bearer token on to next, either one when both are set and every request
+// when neither is
+type authHandler struct {
+	next        http.Handler
+	basicAuth   string
+	bearerToken string
+}
+
+func (a authHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if a.authorized(r) {
+		a.next.ServeHTTP(w, r)
+		return
+	}
+	log.Printf("%v: unauthorized request", r.RemoteAddr)
+	if a.basicAuth != "" {
+		w.Header().Set("WWW-Authenticate", `Basic realm="goss"`)
+	} else {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="goss"`)
+	}
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
+
+func (a authHandler) authorized(r *http.Request) bool {
+	if a.basicAuth == "" && a.bearerToken == "" {
+		return true
+	}
+	if user, password, ok := r.BasicAuth(); ok && a.basicAuth != "" {
+		if secretEqual(user+":"+password, a.basicAuth) {
+			return true
+		}
+	}
+	auth := r.Header.Get("Authorization")
+	if a.bearerToken != "" && strings.HasPrefix(auth, "Bearer ") {
+		return secretEqual(strings.TrimPrefix(auth, "Bearer "), a.bearerToken)
+	}
+	return false
+}
+
+// secretEqual compares in constant time, so the time taken doesn't tell how
+// much of a secret was guessed
+func secretEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
 }
 
 func newHealthHandler(c *util.Config) (*healthHandler, error) {
 	color.NoColor = true
 	cache := cache.New(c.Cache, 30*time.Second)
 
-	cfg, err := getGossConfig(c.Vars, c.VarsInline, c.Spec)
+	cfg, err := loadGossConfig(c)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := getOutputer(c.NoColor, c.OutputFormat, c.Lang)
+	if err != nil {
+		return nil, err
+	}
+	outputConfig, err := newOutputConfig(c)
 	if err != nil {
 		return nil, err
 	}
 
-	output, err := getOutputer(c.NoColor, c.OutputFormat)
+	sys, err := newSystem(c)
+	if err != nil {
+		return nil, err
+	}
+	concurrency, err := newConcurrency(c)
 	if err != nil {
 		return nil, err
 	}
 
 	health := &healthHandler{
-		c:             c,
-		gossConfig:    *cfg,
-		sys:           system.New(c.PackageManager),
-		outputer:      output,
-		cache:         cache,
-		gossMu:        &sync.Mutex{},
-		maxConcurrent: c.MaxConcurrent,
-	}
-	if c.OutputFormat == "json" {
+		c:            c,
+		gossConfig:   *cfg,
+		sys:          sys,
+		outputer:     output,
+		outputConfig: outputConfig,
+		cache:        cache,
+		gossMu:       &sync.Mutex{},
+		concurrency:  concurrency,
+		tags:         resourceTags(*cfg),
+	}
+	switch c.OutputFormat {
+	case "json":
 		health.contentType = "application/json"
+	case "html":
+		health.contentType = "text/html; charset=utf-8"
 	}
 	return health, nil
 }
@@ -57,24 +295,70 @@ func newHealthHandler(c *util.Config) (*healthHandler, error) {
 type res struct {
 	exitCode int
 	b        bytes.Buffer
+	// metrics are the results of the run as Prometheus metrics
+	metrics []byte
+	runID   string
+	// results, startTime and outputConfig are those of the run, to output the
+	// results a query selects
+	results      [][]resource.TestResult
+	startTime    time.Time
+	outputConfig util.OutputConfig
 }
 type healthHandler struct {
-	c             *util.Config
-	gossConfig    GossConfig
-	sys           *system.System
-	outputer      outputs.Outputer
-	cache         *cache.Cache
-	gossMu        *sync.Mutex
-	contentType   string
-	maxConcurrent int
+	c            *util.Config
+	gossConfig   GossConfig
+	sys          *system.System
+	outputer     outputs.Outputer
+	outputConfig util.OutputConfig
+	cache        *cache.Cache
+	gossMu       *sync.Mutex
+	contentType  string
+	concurrency  Concurrency
+	// tags are those of the resources by resourceKey, for the tag queries
+	tags map[string][]string
 }
 
 func (h healthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	outputConfig := util.OutputConfig{
-		FormatOptions: h.c.FormatOptions,
+	log.Printf("%v: requesting health probe", r.RemoteAddr)
+	query, err := parseResultsQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
+	resp := h.results(r)
+	if !query.all() {
+		var total int
+		resp, total = h.queryResults(resp, query)
+		w.Header().Set("X-Goss-Total-Tests", strconv.Itoa(total))
+	}
+	if h.contentType != "" {
+		w.Header().Set("Content-Type", h.contentType)
+	}
+	w.Header().Set("X-Goss-Run-Id", resp.runID)
+	if resp.exitCode == 0 {
+		resp.b.WriteTo(w)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		resp.b.WriteTo(w)
+	}
+}
 
-	log.Printf("%v: requesting health probe", r.RemoteAddr)
+// queryResults are the results of resp query selects, and their count. The
+// status is that of all of them, whatever the page.
+func (h healthHandler) queryResults(resp res, query resultsQuery) (res, int) {
+	filtered, page := query.filter(resp.results, h.tags)
+	var b bytes.Buffer
+	exitCode := h.outputer.Output(ioutil.Discard, resultsChan(filtered), resp.startTime, resp.outputConfig)
+	h.outputer.Output(&b, resultsChan(page), resp.startTime, resp.outputConfig)
+	total := 0
+	for _, group := range filtered {
+		total += len(group)
+	}
+	return res{exitCode: exitCode, b: b, runID: resp.runID}, total
+}
+
+// results are those of the cached run, or of a new one when it's stale
+func (h healthHandler) results(r *http.Request) res {
 	var resp res
 	tmp, found := h.cache.Get("res")
 	if found {
@@ -86,23 +370,56 @@ func (h healthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		if found {
 			resp = tmp.(res)
 		} else {
-			h.sys = system.New(h.c.PackageManager)
-			log.Printf("%v: Stale cache, running tests", r.RemoteAddr)
-			iStartTime := time.Now()
-			out := validate(h.sys, h.gossConfig, h.maxConcurrent)
-			var b bytes.Buffer
-			exitCode := h.outputer.Output(&b, out, iStartTime, outputConfig)
-			resp = res{exitCode: exitCode, b: b}
+			policy, unprivileged, resolver := h.sys.CommandPolicy, h.sys.Unprivileged, h.sys.Resolver
+			h.sys = systemFor(h.c)
+			h.sys.CommandPolicy, h.sys.Unprivileged, h.sys.Resolver = policy, unprivileged, resolver
+			iStartTime, runID := time.Now(), newRunID()
+			log.Printf("%v: Stale cache, running tests, run %s", r.RemoteAddr, runID)
+			windows, err := loadMaintenance(h.c.MaintenanceFile)
+			if err != nil {
+				// Without the windows failures still page, rather than hiding them
+				log.Printf("%v: ignoring maintenance windows: %v", r.RemoteAddr, err)
+			}
+			timing := &util.Timing{Load: time.Since(iStartTime)}
+			outputConfig := h.outputConfig
+			outputConfig.Timing, outputConfig.RunID = timing, runID
+			out := timeValidation(validate(h.sys, h.gossConfig, h.concurrency, runDeadline(h.c.MaxRunDuration)), timing)
+			out = MaintenanceResults(out, windows, iStartTime)
+			out = outputs.RedactResults(out, h.c.Redact)
+			out = outputs.TruncateResults(out, h.c.MaxOutputBytes, nil)
+			if h.c.SortResults {
+				out = outputs.SortResults(out)
+			}
+			out = outputs.OrderResults(out, h.c.FormatOptions)
+			postRun := NewPostRunExec(h.c.PostRunExec)
+			out = postRun.Results(out, iStartTime, outputConfig)
+			var results [][]resource.TestResult
+			for resultGroup := range out {
+				results = append(results, resultGroup)
+			}
+			if err := postRun.Err(); err != nil {
+				// The results are still served, the hook is only told about them
+				log.Printf("%v: run %s: %v", r.RemoteAddr, runID, err)
+			}
+			var b, metrics bytes.Buffer
+			exitCode := h.outputer.Output(&b, resultsChan(results), iStartTime, outputConfig)
+			outputs.Prometheus{}.Output(&metrics, resultsChan(results), iStartTime, runOutputConfig(outputConfig))
+			resp = res{exitCode: exitCode, b: b, metrics: metrics.Bytes(), runID: runID, results: results, startTime: iStartTime, outputConfig: outputConfig}
 			h.cache.Set("res", resp, cache.DefaultExpiration)
 		}
 	}
-	if h.contentType != "" {
-		w.Header().Set("Content-Type", h.contentType)
-	}
-	if resp.exitCode == 0 {
-		resp.b.WriteTo(w)
-	} else {
-		w.WriteHeader(http.StatusServiceUnavailable)
-		resp.b.WriteTo(w)
-	}
+	return resp
+}
+
+// metricsHandler serves the results of the health endpoint as Prometheus
+// metrics, a failed run is still a successful scrape
+type metricsHandler struct {
+	health *healthHandler
+}
+
+func (m metricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%v: requesting metrics", r.RemoteAddr)
+	resp := m.health.results(r)
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(resp.metrics)
 }
@@ -0,0 +1,123 @@
+package goss
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// The codes of grpc-status and the statuses of grpc.health.v1 used by the
+// health service
+const (
+	grpcOK            = 0
+	grpcInvalidArg    = 3
+	grpcNotFound      = 5
+	grpcUnimplemented = 12
+
+	grpcServing    = 1
+	grpcNotServing = 2
+)
+
+// grpcHealthHandler serves the Check method of the standard grpc.health.v1
+// Health service. The empty service is --endpoint and the others the paths of
+// the endpoints without their slash, such as readyz, SERVING when their run
+// passes. It implements just enough of gRPC and protobuf for its two messages.
+type grpcHealthHandler struct {
+	services map[string]*healthHandler
+}
+
+func (g grpcHealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		http.Error(w, "goss only serves the grpc.health.v1.Health service", http.StatusUnsupportedMediaType)
+		return
+	}
+	w.Header().Set("Content-Type", "application/grpc")
+	if r.URL.Path != "/grpc.health.v1.Health/Check" {
+		grpcError(w, grpcUnimplemented, "goss only implements grpc.health.v1.Health/Check")
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		grpcError(w, grpcInvalidArg, err.Error())
+		return
+	}
+	service, err := grpcHealthService(body)
+	if err != nil {
+		grpcError(w, grpcInvalidArg, err.Error())
+		return
+	}
+	health, ok := g.services[service]
+	if !ok {
+		grpcError(w, grpcNotFound, "unknown service "+service)
+		return
+	}
+	log.Printf("%v: requesting grpc health of %q", r.RemoteAddr, service)
+	status := byte(grpcServing)
+	if resp := health.results(r); resp.exitCode != 0 {
+		status = grpcNotServing
+	}
+	// HealthCheckResponse with its status as field 1, after the uncompressed
+	// flag and length of the message
+	msg := []byte{0x08, status}
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	w.Write(append(frame, msg...))
+	// Flushing streams the response without a Content-Length, like gRPC does
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", fmt.Sprint(grpcOK))
+}
+
+// grpcError fails the call with a response of only headers, which carry
+// the status rather than the trailers
+func grpcError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Grpc-Status", fmt.Sprint(code))
+	w.Header().Set("Grpc-Message", message)
+	w.WriteHeader(http.StatusOK)
+}
+
+// grpcHealthService is the service field of the HealthCheckRequest of a
+// request body
+func grpcHealthService(body []byte) (string, error) {
+	if len(body) < 5 {
+		return "", fmt.Errorf("invalid request")
+	}
+	if body[0] != 0 {
+		return "", fmt.Errorf("compressed requests aren't supported")
+	}
+	msg := body[5:]
+	if int(binary.BigEndian.Uint32(body[1:5])) != len(msg) {
+		return "", fmt.Errorf("invalid request length")
+	}
+	var service string
+	for len(msg) > 0 {
+		key, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return "", fmt.Errorf("invalid request")
+		}
+		msg = msg[n:]
+		switch key & 7 {
+		case 0:
+			if _, n = binary.Uvarint(msg); n <= 0 {
+				return "", fmt.Errorf("invalid request")
+			}
+			msg = msg[n:]
+		case 2:
+			length, n := binary.Uvarint(msg)
+			if n <= 0 || length > uint64(len(msg)-n) {
+				return "", fmt.Errorf("invalid request")
+			}
+			if key>>3 == 1 {
+				service = string(msg[n : n+int(length)])
+			}
+			msg = msg[n+int(length):]
+		default:
+			return "", fmt.Errorf("invalid request")
+		}
+	}
+	return service, nil
+}
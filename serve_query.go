@@ -0,0 +1,154 @@
+package goss
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aelsabbahy/goss/resource"
+)
+
+// resultsQuery selects the results a health endpoint serves, from the query
+// parameters of the request
+type resultsQuery struct {
+	statuses []string
+	types    []string
+	tags     []string
+	offset   int
+	// limit is the number of tests to serve after offset, -1 for all of them
+	limit int
+}
+
+var queryStatuses = map[string]bool{"passed": true, "failed": true, "skipped": true, "timeout": true, "error": true}
+
+// parseResultsQuery reads status, type and tag, repeated or comma separated,
+// and limit and offset from q
+func parseResultsQuery(q url.Values) (resultsQuery, error) {
+	query := resultsQuery{
+		statuses: queryList(q["status"]),
+		types:    queryList(q["type"]),
+		tags:     queryList(q["tag"]),
+		limit:    -1,
+	}
+	for i, s := range query.statuses {
+		query.statuses[i] = strings.ToLower(s)
+		if !queryStatuses[query.statuses[i]] {
+			return query, fmt.Errorf("unknown status %q, must be passed, failed, skipped, timeout or error", s)
+		}
+	}
+	// ResourceType is the Go type name, KernelParam for kernel-param
+	for i, typ := range query.types {
+		query.types[i] = strings.ToLower(strings.Replace(typ, "-", "", -1))
+	}
+	for name, n := range map[string]*int{"limit": &query.limit, "offset": &query.offset} {
+		v := q.Get(name)
+		if v == "" {
+			continue
+		}
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			return query, fmt.Errorf("%s must be a number of tests, got %q", name, v)
+		}
+		*n = i
+	}
+	return query, nil
+}
+
+func queryList(values []string) []string {
+	var list []string
+	for _, v := range values {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				list = append(list, s)
+			}
+		}
+	}
+	return list
+}
+
+// all is whether q selects every result
+func (q resultsQuery) all() bool {
+	return len(q.statuses) == 0 && len(q.types) == 0 && len(q.tags) == 0 && q.offset == 0 && q.limit < 0
+}
+
+// filter is results with the tests q selects, before and after limit and
+// offset are applied, tags are those of the resources by resourceKey
+func (q resultsQuery) filter(results [][]resource.TestResult, tags map[string][]string) (filtered, page [][]resource.TestResult) {
+	skip, left := q.offset, q.limit
+	for _, group := range results {
+		var selected, paged []resource.TestResult
+		for _, t := range group {
+			if !q.matches(t, tags) {
+				continue
+			}
+			selected = append(selected, t)
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if left != 0 {
+				paged = append(paged, t)
+				left--
+			}
+		}
+		if len(selected) > 0 {
+			filtered = append(filtered, selected)
+		}
+		if len(paged) > 0 {
+			page = append(page, paged)
+		}
+	}
+	return filtered, page
+}
+
+func (q resultsQuery) matches(t resource.TestResult, tags map[string][]string) bool {
+	if len(q.statuses) > 0 && !q.matchesStatus(queryStatus(t)) {
+		return false
+	}
+	if len(q.types) > 0 && !hasTag([]string{strings.ToLower(t.ResourceType)}, q.types) {
+		return false
+	}
+	return len(q.tags) == 0 || hasTag(tags[resourceKey(t.ResourceType, t.ResourceId)], q.tags)
+}
+
+// matchesStatus is whether q selects status, tests that errored or timed out
+// are also failed
+func (q resultsQuery) matchesStatus(status string) bool {
+	for _, s := range q.statuses {
+		if s == status || (s == "failed" && (status == "timeout" || status == "error")) {
+			return true
+		}
+	}
+	return false
+}
+
+func queryStatus(t resource.TestResult) string {
+	switch t.Result {
+	case resource.SUCCESS:
+		return "passed"
+	case resource.SKIP:
+		return "skipped"
+	case resource.TIMEOUT:
+		return "timeout"
+	case resource.ERROR:
+		return "error"
+	}
+	return "failed"
+}
+
+func resourceKey(resourceType, id string) string {
+	return resourceType + "/" + id
+}
+
+// resourceTags are the tags of the resources of gossConfig by resourceKey
+func resourceTags(gossConfig GossConfig) map[string][]string {
+	tags := make(map[string][]string)
+	for _, r := range gossConfig.Resources() {
+		res := r.(resource.ResourceRead)
+		resourceType := strings.Split(reflect.TypeOf(res).String(), ".")[1]
+		tags[resourceKey(resourceType, res.ID())] = res.GetTags()
+	}
+	return tags
+}
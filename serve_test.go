@@ -2,16 +2,30 @@ package goss
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
 	"log"
+	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/aelsabbahy/goss/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 func TestServe(t *testing.T) {
@@ -69,6 +83,9 @@ func TestServe(t *testing.T) {
 			if tc.expectedContentType != "" {
 				assert.Equal(t, []string{tc.expectedContentType}, rr.HeaderMap["Content-Type"])
 			}
+			runID := rr.Header().Get("X-Goss-Run-Id")
+			assert.Len(t, runID, 36)
+			assert.Contains(t, logOutput.String(), "run "+runID)
 		})
 	}
 }
@@ -114,3 +131,286 @@ func TestServeCache(t *testing.T) {
 		logOutput.Reset()
 	})
 }
+
+func TestServeEndpoints(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	serveConfig := filepath.Join(t.TempDir(), "serve.yaml")
+	require.NoError(t, ioutil.WriteFile(serveConfig, []byte(`
+- path: /readyz
+  gossfile: testdata/failing.goss.yaml
+  cache: 60s
+`), 0644))
+	config, err := util.NewConfig(
+		util.WithSpecFile(filepath.Join("testdata", "passing.goss.yaml")),
+		util.WithOutputFormat("json"),
+		util.WithServeConfig(serveConfig),
+		util.WithServeEndpoints("/livez=testdata/passing.goss.yaml@1s"),
+	)
+	require.NoError(t, err)
+
+	endpoints, err := serveEndpoints(config)
+	require.NoError(t, err)
+	require.Len(t, endpoints, 3)
+	assert.Equal(t, "/healthz", endpoints[0].path)
+	assert.Equal(t, 60*time.Second, endpoints[1].config.Cache)
+	assert.Equal(t, time.Second, endpoints[2].config.Cache)
+
+	mux, _, err := newServeMux(config)
+	require.NoError(t, err)
+	for path, status := range map[string]int{
+		"/healthz": http.StatusOK,
+		"/readyz":  http.StatusServiceUnavailable,
+		"/livez":   http.StatusOK,
+	} {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, status, rr.Code, path)
+	}
+
+	// An endpoint with the path of --endpoint replaces it
+	config.ServeEndpoints = []string{"/healthz=testdata/failing.goss.yaml"}
+	endpoints, err = serveEndpoints(config)
+	require.NoError(t, err)
+	assert.Len(t, endpoints, 2)
+	assert.Equal(t, filepath.Join("testdata", "failing.goss.yaml"), endpoints[1].config.Spec)
+
+	for _, spec := range []string{"/readyz=testdata/passing.goss.yaml", "readyz", "/x=", "/x=goss.yaml@soon", "/metrics=goss.yaml"} {
+		config.ServeEndpoints = []string{spec}
+		_, err := serveEndpoints(config)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestServeGRPCHealth(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	config, err := util.NewConfig(
+		util.WithSpecFile(filepath.Join("testdata", "passing.goss.yaml")),
+		util.WithOutputFormat("json"),
+		util.WithServeEndpoints("/readyz=testdata/failing.goss.yaml"),
+	)
+	require.NoError(t, err)
+	_, grpcHealth, err := newServeMux(config)
+	require.NoError(t, err)
+	ts := httptest.NewUnstartedServer(h2c.NewHandler(grpcHealth, &http2.Server{}))
+	ts.Start()
+	defer ts.Close()
+
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	check := func(service string) (status string, serving byte) {
+		// HealthCheckRequest with the service as field 1
+		msg := append([]byte{0x0a, byte(len(service))}, service...)
+		body := append([]byte{0, 0, 0, 0, byte(len(msg))}, msg...)
+		req, err := http.NewRequest("POST", ts.URL+"/grpc.health.v1.Health/Check", bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/grpc")
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		out, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		if len(out) == 7 {
+			serving = out[6]
+		}
+		if status = resp.Header.Get("Grpc-Status"); status == "" {
+			status = resp.Trailer.Get("Grpc-Status")
+		}
+		return status, serving
+	}
+
+	status, serving := check("")
+	assert.Equal(t, "0", status)
+	assert.Equal(t, byte(grpcServing), serving)
+	status, serving = check("readyz")
+	assert.Equal(t, "0", status)
+	assert.Equal(t, byte(grpcNotServing), serving)
+	status, _ = check("missing")
+	assert.Equal(t, "5", status)
+}
+
+func TestServeMetrics(t *testing.T) {
+	var logOutput bytes.Buffer
+	log.SetOutput(&logOutput)
+	config, err := util.NewConfig(util.WithSpecFile(filepath.Join("testdata", "failing.goss.yaml")), util.WithOutputFormat("json"))
+	require.NoError(t, err)
+
+	hh, err := newHealthHandler(config)
+	require.NoError(t, err)
+	req, err := http.NewRequest("GET", config.MetricsEndpoint, nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	metricsHandler{hh}.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `goss_tests{status="failed"} 2`)
+	assert.Contains(t, rr.Body.String(), "goss_run_timestamp_seconds ")
+
+	// The health endpoint reports the same cached run
+	rr = httptest.NewRecorder()
+	hh.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, 1, strings.Count(logOutput.String(), "Stale cache"))
+}
+
+func TestServeQuery(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	dir := t.TempDir()
+	gossfile := filepath.Join(dir, "goss.yaml")
+	require.NoError(t, ioutil.WriteFile(gossfile, []byte(`command:
+  echo hello:
+    exit-status: 0
+    stdout: [hello]
+    tags: [web]
+  exit 1:
+    exit-status: 0
+file:
+  `+dir+`:
+    exists: true
+    filetype: directory
+    tags: [web]
+`), 0644))
+	config, err := util.NewConfig(util.WithSpecFile(gossfile), util.WithOutputFormat("json"), util.WithSortResults())
+	require.NoError(t, err)
+	hh, err := newHealthHandler(config)
+	require.NoError(t, err)
+
+	tests := []struct {
+		query  string
+		status int
+		tests  []string
+		total  string
+	}{
+		{"", http.StatusServiceUnavailable, []string{"echo hello", "echo hello", "exit 1", dir, dir}, ""},
+		{"?status=failed", http.StatusServiceUnavailable, []string{"exit 1"}, "1"},
+		{"?tag=web", http.StatusOK, []string{"echo hello", "echo hello", dir, dir}, "4"},
+		{"?type=file,kernel-param", http.StatusOK, []string{dir, dir}, "2"},
+		{"?type=command&type=File&limit=2&offset=2", http.StatusServiceUnavailable, []string{"exit 1", dir}, "5"},
+		{"?type=command&limit=0", http.StatusServiceUnavailable, nil, "3"},
+		{"?status=passed&offset=10", http.StatusOK, nil, "4"},
+	}
+	for _, tc := range tests {
+		req, err := http.NewRequest("GET", config.Endpoint+tc.query, nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		hh.ServeHTTP(rr, req)
+		assert.Equal(t, tc.status, rr.Code, tc.query)
+		assert.Equal(t, tc.total, rr.Header().Get("X-Goss-Total-Tests"), tc.query)
+		var out struct {
+			Results []struct {
+				ResourceID string `json:"resource-id"`
+			} `json:"results"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out), tc.query)
+		var ids []string
+		for _, r := range out.Results {
+			ids = append(ids, r.ResourceID)
+		}
+		assert.Equal(t, tc.tests, ids, tc.query)
+	}
+
+	for _, query := range []string{"?status=broken", "?limit=-1", "?offset=x"} {
+		req, err := http.NewRequest("GET", config.Endpoint+query, nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		hh.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+}
+
+func TestServeAuth(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	tests := []struct {
+		name      string
+		handler   authHandler
+		setup     func(r *http.Request)
+		wantCode  int
+		challenge string
+	}{
+		{"no auth", authHandler{next: ok}, func(r *http.Request) {}, http.StatusOK, ""},
+		{"basic", authHandler{next: ok, basicAuth: "goss:secret"}, func(r *http.Request) { r.SetBasicAuth("goss", "secret") }, http.StatusOK, ""},
+		{"wrong password", authHandler{next: ok, basicAuth: "goss:secret"}, func(r *http.Request) { r.SetBasicAuth("goss", "guess") }, http.StatusUnauthorized, "Basic"},
+		{"bearer", authHandler{next: ok, bearerToken: "token"}, func(r *http.Request) { r.Header.Set("Authorization", "Bearer token") }, http.StatusOK, ""},
+		{"missing token", authHandler{next: ok, bearerToken: "token"}, func(r *http.Request) {}, http.StatusUnauthorized, "Bearer"},
+		{"bearer with both", authHandler{next: ok, basicAuth: "goss:secret", bearerToken: "token"}, func(r *http.Request) { r.Header.Set("Authorization", "Bearer token") }, http.StatusOK, ""},
+	}
+	log.SetOutput(&bytes.Buffer{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/healthz", nil)
+			tt.setup(req)
+			rr := httptest.NewRecorder()
+			tt.handler.ServeHTTP(rr, req)
+			assert.Equal(t, tt.wantCode, rr.Code)
+			if tt.challenge != "" {
+				assert.True(t, strings.HasPrefix(rr.Header().Get("WWW-Authenticate"), tt.challenge))
+			}
+		})
+	}
+}
+
+func TestServeTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	for _, opts := range [][]util.ConfigOption{
+		{util.WithTLS(certFile, "")},
+		{util.WithTLSClientCA(certFile)},
+		{util.WithTLS(certFile, certFile)},
+	} {
+		config, err := util.NewConfig(opts...)
+		require.NoError(t, err)
+		_, err = serveTLSConfig(config)
+		assert.Error(t, err)
+	}
+
+	config, err := util.NewConfig(util.WithTLS(certFile, keyFile), util.WithTLSClientCA(certFile))
+	require.NoError(t, err)
+	tlsConfig, err := serveTLSConfig(config)
+	require.NoError(t, err)
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	require.NoError(t, err)
+	tlsConfig.Certificates = []tls.Certificate{cert}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = tlsConfig
+	srv.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	parsed, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	roots.AddCert(parsed)
+	client := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs}}}
+	}
+	if _, err := client().Get(srv.URL); err == nil {
+		t.Error("a client without a certificate must be refused")
+	}
+	resp, err := client(cert).Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,111 @@
+package goss
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+)
+
+// SeverityExitCodes are the exit codes of runs by the severity of the tests
+// that didn't pass
+type SeverityExitCodes map[string]int
+
+func newSeverityExitCodes(c *util.Config) (SeverityExitCodes, error) {
+	return parseSeverityExitCodes(c.SeverityExitCode)
+}
+
+// parseSeverityExitCodes parses the severity=code specs of
+// --severity-exit-code, such as warn=3, over the defaults: fail=1, warn=0 and
+// info=0
+func parseSeverityExitCodes(specs []string) (SeverityExitCodes, error) {
+	codes := SeverityExitCodes{resource.SeverityFail: 1, resource.SeverityWarn: 0, resource.SeverityInfo: 0}
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("severity-exit-code must be <severity>=<code>, got: %s", spec)
+		}
+		severity := strings.TrimSpace(parts[0])
+		if !util.IsValueInList(severity, resource.Severities) {
+			return nil, fmt.Errorf("severity-exit-code %s: unknown severity %q, expected one of: %s", spec, severity, strings.Join(resource.Severities, ", "))
+		}
+		code, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || code < 0 || code > 255 {
+			return nil, fmt.Errorf("severity-exit-code %s: the code must be a number between 0 and 255", spec)
+		}
+		codes[severity] = code
+	}
+	return codes, nil
+}
+
+// checkSeverities reports the first of resources with an unknown severity
+func checkSeverities(resources []resource.Resource) error {
+	keys := resourceTypeKeys()
+	for _, r := range resources {
+		if _, err := resource.Severity(r.(resource.ResourceRead)); err != nil {
+			return fmt.Errorf("%s: %v", requiresRef(keys, r), err)
+		}
+	}
+	return nil
+}
+
+// SeverityTally records the severities of the tests of a run that didn't
+// pass, tests that only warn for another reason, such as quarantined ones,
+// aren't recorded
+type SeverityTally struct {
+	codes  SeverityExitCodes
+	failed map[string]bool
+}
+
+// NewSeverityTally tallies the results of a run to map it to the exit code of
+// codes
+func NewSeverityTally(codes SeverityExitCodes) *SeverityTally {
+	return &SeverityTally{codes: codes, failed: make(map[string]bool)}
+}
+
+// Results records the severities of the tests of in that didn't pass, in is
+// passed through unchanged
+func (t *SeverityTally) Results(in <-chan []resource.TestResult) <-chan []resource.TestResult {
+	out := make(chan []resource.TestResult)
+	go func() {
+		defer close(out)
+		for resultGroup := range in {
+			for _, r := range resultGroup {
+				if r.Result == resource.SUCCESS || r.Result == resource.SKIP {
+					continue
+				}
+				if r.Quarantined() || r.Flaky() || r.Maintenance != "" || r.Baseline {
+					continue
+				}
+				severity := r.Severity
+				if severity == "" {
+					severity = resource.SeverityFail
+				}
+				t.failed[severity] = true
+			}
+			out <- resultGroup
+		}
+	}()
+	return out
+}
+
+// ExitCode is the exit code of the run the outputer exited with code for: the
+// code of the most severe severity whose tests didn't pass and whose code
+// isn't 0. Errors and time outs of fail tests, and codes other than 1 and 0,
+// are kept as they are.
+func (t *SeverityTally) ExitCode(code int) int {
+	if code > 1 {
+		return code
+	}
+	for _, severity := range resource.Severities {
+		if t.failed[severity] && t.codes[severity] != 0 {
+			return t.codes[severity]
+		}
+	}
+	if t.failed[resource.SeverityFail] {
+		return 0
+	}
+	return code
+}
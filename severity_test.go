@@ -0,0 +1,82 @@
+package goss
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSeverityExitCodes(t *testing.T) {
+	codes, err := parseSeverityExitCodes([]string{"warn=3", " info = 4 "})
+	require.NoError(t, err)
+	assert.Equal(t, SeverityExitCodes{"fail": 1, "warn": 3, "info": 4}, codes)
+
+	for _, bad := range []string{"warn", "critical=2", "warn=-1", "warn=256", "warn=x"} {
+		_, err := parseSeverityExitCodes([]string{bad})
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestSeverityTally(t *testing.T) {
+	run := func(specs []string, code int, results ...resource.TestResult) int {
+		codes, err := parseSeverityExitCodes(specs)
+		require.NoError(t, err)
+		in := make(chan []resource.TestResult, 1)
+		in <- results
+		close(in)
+		tally := NewSeverityTally(codes)
+		for range tally.Results(in) {
+		}
+		return tally.ExitCode(code)
+	}
+	failed := resource.TestResult{Result: resource.FAIL, Severity: "fail"}
+	warned := resource.TestResult{Result: resource.FAIL, Severity: "warn"}
+	info := resource.TestResult{Result: resource.ERROR, Severity: "info"}
+	passed := resource.TestResult{Result: resource.SUCCESS, Severity: "warn"}
+
+	assert.Equal(t, 1, run(nil, 1, failed, warned))
+	assert.Equal(t, 0, run(nil, 0, warned, info, passed))
+	assert.Equal(t, 3, run([]string{"warn=3"}, 0, warned, info))
+	assert.Equal(t, 4, run([]string{"warn=3", "info=4"}, 0, info, passed))
+	assert.Equal(t, 5, run([]string{"fail=5", "warn=3"}, 1, failed, warned))
+	assert.Equal(t, 3, run([]string{"fail=0", "warn=3"}, 1, failed, warned))
+	assert.Equal(t, 0, run([]string{"fail=0"}, 1, failed))
+	assert.Equal(t, 2, run([]string{"fail=5"}, 2, resource.TestResult{Result: resource.ERROR, Severity: "fail"}))
+	assert.Equal(t, 1, run([]string{"warn=3"}, 1), "exit codes other than those of failures are kept")
+
+	quarantined := resource.TestResult{Result: resource.FAIL, Severity: "warn", Meta: map[string]interface{}{"quarantined": true}}
+	assert.Equal(t, 0, run([]string{"warn=3"}, 0, quarantined))
+}
+
+func TestValidateSeverity(t *testing.T) {
+	g, err := ReadJSONData([]byte(`{"command": {
+		"failing": {"exec": "false", "exit-status": 0},
+		"warning": {"exec": "false", "exit-status": 0, "severity": "warn"},
+		"informational": {"exec": "true", "exit-status": 0, "severity": "info"}
+	}}`), true)
+	checkErr(t, err, "reading gossfile failed")
+	assert.NoError(t, checkSeverities(g.Resources()))
+
+	results := map[string]resource.TestResult{}
+	for rg := range validate(system.New(""), g, Concurrency{Max: 10}, time.Time{}) {
+		for _, r := range rg {
+			if r.Property == "exit-status" {
+				results[r.ResourceId] = r
+			}
+		}
+	}
+	assert.Equal(t, "fail", results["failing"].Severity)
+	assert.False(t, results["failing"].Warning())
+	assert.Equal(t, "warn", results["warning"].Severity)
+	assert.True(t, results["warning"].Warning())
+	assert.Equal(t, "info", results["informational"].Severity)
+	assert.False(t, results["informational"].Warning(), "passed")
+
+	g, err = ReadJSONData([]byte(`{"command": {"true": {"exit-status": 0, "severity": "critical"}}}`), true)
+	checkErr(t, err, "reading gossfile failed")
+	assert.EqualError(t, checkSeverities(g.Resources()), `command:true: unknown severity "critical", expected one of: fail, warn, info`)
+}
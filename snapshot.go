@@ -0,0 +1,194 @@
+package goss
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+)
+
+// diffMaxLines bounds the lines of contents Diff compares line by line, the
+// values of longer ones are shown whole
+const diffMaxLines = 2000
+
+// DiffReport is the drift Diff found
+type DiffReport struct {
+	Changes []resource.FactChange `json:"changes"`
+}
+
+// Snapshot writes the values every test of the gossfile reads from the
+// system, such as the contents of files and the output of commands, to w. A
+// snapshot is the same as a recording of validate --record, it can be
+// replayed too.
+func Snapshot(c *util.Config, w io.Writer) error {
+	facts, err := takeSnapshot(c)
+	if err != nil {
+		return err
+	}
+	return facts.Write(w)
+}
+
+// takeSnapshot validates the gossfile of c recording the facts, the results
+// themselves don't matter
+func takeSnapshot(c *util.Config) (*resource.Facts, error) {
+	gossConfig, err := loadGossConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	sys, err := newSystem(c)
+	if err != nil {
+		return nil, err
+	}
+	concurrency, err := newConcurrency(c)
+	if err != nil {
+		return nil, err
+	}
+
+	facts := resource.NewFacts()
+	resource.UseFacts(facts)
+	defer resource.UseFacts(nil)
+	for range validate(sys, *gossConfig, concurrency, runDeadline(c.MaxRunDuration)) {
+	}
+	return facts, nil
+}
+
+// Diff reports the facts that differ between the snapshots before and after,
+// or the live system when after is "", as text or json. The exit code is 1
+// when there's drift.
+func Diff(c *util.Config, before, after string) (int, error) {
+	var w io.Writer = os.Stdout
+	if c.OutputWriter != nil {
+		w = c.OutputWriter
+	}
+	if c.OutputFormat != "" && c.OutputFormat != "text" && c.OutputFormat != "json" {
+		return 1, fmt.Errorf("unknown diff format %q, expected text or json", c.OutputFormat)
+	}
+
+	beforeFacts, err := loadSnapshot(before)
+	if err != nil {
+		return 1, err
+	}
+	var afterFacts *resource.Facts
+	if after == "" {
+		afterFacts, err = takeSnapshot(c)
+	} else {
+		afterFacts, err = loadSnapshot(after)
+	}
+	if err != nil {
+		return 1, err
+	}
+
+	report := DiffReport{Changes: resource.DiffFacts(beforeFacts, afterFacts)}
+	if report.Changes == nil {
+		report.Changes = []resource.FactChange{}
+	}
+	if c.OutputFormat == "json" {
+		b, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			return 1, err
+		}
+		fmt.Fprintln(w, string(b))
+	} else {
+		printDiff(w, report)
+	}
+	if len(report.Changes) > 0 {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func loadSnapshot(file string) (*resource.Facts, error) {
+	fh, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot file error: %v", err)
+	}
+	defer fh.Close()
+	facts, err := resource.LoadFacts(fh)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot %s: %v", file, err)
+	}
+	return facts, nil
+}
+
+// printDiff prints each change as the key of the fact prefixed with + when
+// it was added, - when it was removed and ~ when it changed, followed by its
+// values. Contents are compared line by line.
+func printDiff(w io.Writer, report DiffReport) {
+	var added, removed, changed int
+	for _, ch := range report.Changes {
+		switch {
+		case ch.Before == "":
+			added++
+			fmt.Fprintf(w, "+ %s\n    + %s\n", ch.Key, ch.After)
+		case ch.After == "":
+			removed++
+			fmt.Fprintf(w, "- %s\n    - %s\n", ch.Key, ch.Before)
+		default:
+			changed++
+			fmt.Fprintf(w, "~ %s\n", ch.Key)
+			for _, line := range diffValues(ch.Before, ch.After) {
+				fmt.Fprintf(w, "    %s\n", line)
+			}
+		}
+	}
+	fmt.Fprintf(w, "Changed: %d, Added: %d, Removed: %d\n", changed, added, removed)
+}
+
+// diffValues are the lines of the change from before to after. Multi-line
+// strings, such as contents, show the lines removed and added so a one-line
+// edit of a large file stays readable.
+func diffValues(before, after string) []string {
+	var b, a string
+	if json.Unmarshal([]byte(before), &b) != nil || json.Unmarshal([]byte(after), &a) != nil ||
+		(!strings.Contains(b, "\n") && !strings.Contains(a, "\n")) {
+		return []string{"- " + before, "+ " + after}
+	}
+	bLines, aLines := strings.Split(b, "\n"), strings.Split(a, "\n")
+	if len(bLines) > diffMaxLines || len(aLines) > diffMaxLines {
+		return []string{"- " + before, "+ " + after}
+	}
+	return diffLines(bLines, aLines)
+}
+
+// diffLines are the lines removed from before and added in after, in order,
+// by their longest common subsequence
+func diffLines(before, after []string) []string {
+	// lcs[i][j] is the length of the longest common subsequence of
+	// before[i:] and after[j:]
+	lcs := make([][]int, len(before)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i] == after[j]:
+			i++
+			j++
+		case j == len(after) || (i < len(before) && lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "- "+before[i])
+			i++
+		default:
+			lines = append(lines, "+ "+after[j])
+			j++
+		}
+	}
+	return lines
+}
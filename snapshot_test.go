@@ -0,0 +1,55 @@
+package goss
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aelsabbahy/goss/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotDiff(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.conf")
+	require.NoError(t, ioutil.WriteFile(file, []byte("port: 8080\nworkers: 4\nlog: info\n"), 0644))
+	spec := filepath.Join(dir, "goss.yaml")
+	require.NoError(t, ioutil.WriteFile(spec, []byte("file:\n  "+file+":\n    exists: true\n    mode: \"0644\"\n    contains: [port]\n"), 0644))
+	c, err := util.NewConfig(util.WithSpecFile(spec))
+	require.NoError(t, err)
+
+	before := filepath.Join(dir, "before.json")
+	fh, err := os.Create(before)
+	require.NoError(t, err)
+	require.NoError(t, Snapshot(c, fh))
+	require.NoError(t, fh.Close())
+
+	var out bytes.Buffer
+	c.OutputWriter, c.OutputFormat = &out, "text"
+	code, err := Diff(c, before, "")
+	require.NoError(t, err)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "Changed: 0, Added: 0, Removed: 0\n", out.String())
+
+	require.NoError(t, ioutil.WriteFile(file, []byte("port: 9090\nworkers: 4\nlog: info\n"), 0644))
+	require.NoError(t, os.Chmod(file, 0600))
+	out.Reset()
+	code, err = Diff(c, before, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "~ File: "+file+": contains\n    - port: 8080\n    + port: 9090\n"+
+		"~ File: "+file+": mode\n    - \"0644\"\n    + \"0600\"\n"+
+		"Changed: 2, Added: 0, Removed: 0\n", out.String())
+
+	_, err = Diff(c, filepath.Join(dir, "missing.json"), "")
+	assert.Error(t, err)
+}
+
+func TestDiffLines(t *testing.T) {
+	assert.Equal(t, []string{"- b", "+ B", "+ d"}, diffLines([]string{"a", "b", "c"}, []string{"a", "B", "c", "d"}))
+	assert.Nil(t, diffLines([]string{"a"}, []string{"a"}))
+	assert.Equal(t, []string{`- "a"`, `+ "b"`}, diffValues(`"a"`, `"b"`))
+}
@@ -8,8 +8,10 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 
 	"gopkg.in/yaml.v2"
 
@@ -21,6 +23,9 @@ const (
 	UNSET = iota
 	JSON
 	YAML
+	// HCL and CUE gossfiles are read as the JSON they convert to
+	HCL
+	CUE
 )
 
 var outStoreFormat = UNSET
@@ -34,11 +39,25 @@ func getStoreFormatFromFileName(f string) (int, error) {
 		return JSON, nil
 	case ".yaml", ".yml":
 		return YAML, nil
+	case ".hcl":
+		return HCL, nil
+	case ".cue":
+		return CUE, nil
 	default:
 		return 0, fmt.Errorf("unknown file extension: %v", ext)
 	}
 }
 
+// getAddStoreFormat is the format resources are added to fileName in, CUE
+// gossfiles aren't rewritten since their definitions would be lost
+func getAddStoreFormat(fileName string) (int, error) {
+	format, err := getStoreFormatFromFileName(fileName)
+	if err == nil && format == CUE {
+		return 0, fmt.Errorf("resources can't be added to the CUE gossfile %s, its definitions would be lost", fileName)
+	}
+	return format, err
+}
+
 func getStoreFormatFromData(data []byte) (int, error) {
 	var v interface{}
 	if err := unmarshalJSON(data, &v); err == nil {
@@ -53,7 +72,7 @@ func getStoreFormatFromData(data []byte) (int, error) {
 
 // ReadJSON Reads json file returning GossConfig
 func ReadJSON(filePath string) (GossConfig, error) {
-	file, err := ioutil.ReadFile(filePath)
+	file, err := readGossFile(filePath)
 	if err != nil {
 		return GossConfig{}, fmt.Errorf("file error: %v", err)
 	}
@@ -74,10 +93,18 @@ func (t *TmplVars) Env() map[string]string {
 	return env
 }
 
-func loadVars(varsFile string, varsInline string) (map[string]interface{}, error) {
-	vars, err := varsFromFile(varsFile)
-	if err != nil {
-		return nil, fmt.Errorf("Error: loading vars file '%s'\n%w", varsFile, err)
+// loadVars merges the vars of varsSources, a later source overwriting the
+// vars of an earlier one, and then varsInline
+func loadVars(varsSources []string, varsInline string) (map[string]interface{}, error) {
+	vars := make(map[string]interface{})
+	for _, source := range varsSources {
+		sourceVars, err := varsFromSource(source)
+		if err != nil {
+			return nil, fmt.Errorf("Error: loading vars '%s'\n%w", source, err)
+		}
+		for k, v := range sourceVars {
+			vars[k] = v
+		}
 	}
 
 	varsExtra, err := varsFromString(varsInline)
@@ -97,7 +124,7 @@ func varsFromFile(varsFile string) (map[string]interface{}, error) {
 	if varsFile == "" {
 		return vars, nil
 	}
-	data, err := ioutil.ReadFile(varsFile)
+	data, err := readGossFile(varsFile)
 	if err != nil {
 		return vars, err
 	}
@@ -130,6 +157,12 @@ func varsFromString(varsString string) (map[string]interface{}, error) {
 
 // ReadJSONData Reads json byte array returning GossConfig
 func ReadJSONData(data []byte, detectFormat bool) (GossConfig, error) {
+	return readGossData(data, outStoreFormat, detectFormat)
+}
+
+// readGossData reads the gossfile data in format, or the one detected from
+// data when detectFormat is true
+func readGossData(data []byte, format int, detectFormat bool) (GossConfig, error) {
 	var err error
 	if currentTemplateFilter != nil {
 		data, err = currentTemplateFilter(data)
@@ -142,7 +175,6 @@ func ReadJSONData(data []byte, detectFormat bool) (GossConfig, error) {
 		}
 	}
 
-	format := outStoreFormat
 	if detectFormat == true {
 		format, err = getStoreFormatFromData(data)
 		if err != nil {
@@ -150,11 +182,21 @@ func ReadJSONData(data []byte, detectFormat bool) (GossConfig, error) {
 		}
 	}
 
+	if data, err = convertToJSON(data, format); err != nil {
+		return GossConfig{}, err
+	}
+	if format == HCL || format == CUE {
+		format = JSON
+	}
+
 	gossConfig := NewGossConfig()
 	// Horrible, but will do for now
 	if err := unmarshal(data, gossConfig, format); err != nil {
 		return *gossConfig, err
 	}
+	if format == JSON {
+		gossConfig.order = jsonSpecOrder(data)
+	}
 
 	return *gossConfig, nil
 }
@@ -163,7 +205,7 @@ func ReadJSONData(data []byte, detectFormat bool) (GossConfig, error) {
 func RenderJSON(c *util.Config) (string, error) {
 	var err error
 	debug = c.Debug
-	currentTemplateFilter, err = NewTemplateFilter(c.Vars, c.VarsInline)
+	currentTemplateFilter, err = configTemplateFilter(c)
 	if err != nil {
 		return "", err
 	}
@@ -196,6 +238,14 @@ func mergeJSONData(gossConfig GossConfig, depth int, path string) (GossConfig, e
 	if depth >= 50 {
 		return GossConfig{}, fmt.Errorf("max depth of 50 reached, possibly due to dependency loop in goss file")
 	}
+	// The expected files of a gossfile are next to it, the includes resolve
+	// theirs as they're merged
+	typeKeys := resourceTypeKeys()
+	for _, r := range gossConfig.Resources() {
+		if err := resource.ResolveExpectedFiles(r, path); err != nil {
+			return GossConfig{}, fmt.Errorf("%s: %v", requiresRef(typeKeys, r), err)
+		}
+	}
 	// Our return gossConfig
 	ret := *NewGossConfig()
 	ret = mergeGoss(ret, gossConfig)
@@ -207,38 +257,113 @@ func mergeJSONData(gossConfig GossConfig, depth int, path string) (GossConfig, e
 	}
 	sort.Strings(keys)
 
-	// Merge gossfiles in sorted order
+	// Read the gossfiles concurrently, they're merged in sorted order
+	var includes []*include
+	presetIncluded := false
 	for _, k := range keys {
 		g := gossConfig.Gossfiles[k]
-		var fpath string
-		if strings.HasPrefix(g.ID(), "/") {
-			fpath = g.ID()
-		} else {
-			fpath = filepath.Join(path, g.ID())
+		if len(g.Vars) > 0 && !isPreset(g.ID()) {
+			return ret, fmt.Errorf("gossfile %s: vars are only those of presets, other gossfiles are rendered with --vars", g.ID())
 		}
-		matches, err := filepath.Glob(fpath)
+		presetIncluded = presetIncluded || isPreset(g.ID())
+		matches, err := includePaths(path, g.ID())
 		if err != nil {
-			return ret, fmt.Errorf("error in expanding glob pattern: %q", err)
-		}
-		if matches == nil {
-			return ret, fmt.Errorf("no matched files were found: %q", fpath)
+			return ret, err
 		}
 		for _, match := range matches {
-			fdir := filepath.Dir(match)
-			j, err := ReadJSON(match)
-			if err != nil {
-				return GossConfig{}, fmt.Errorf("could not read json data in %s: %s", match, err)
-			}
-			j, err = mergeJSONData(j, depth, fdir)
-			if err != nil {
-				return ret, fmt.Errorf("could not write json data: %s", err)
-			}
-			ret = mergeGoss(ret, j)
+			includes = append(includes, &include{path: match, sha256: g.Sha256, tags: g.Tags, vars: g.Vars})
+		}
+	}
+	var wg sync.WaitGroup
+	for _, inc := range includes {
+		wg.Add(1)
+		go func(inc *include) {
+			defer wg.Done()
+			inc.read(depth)
+		}(inc)
+	}
+	wg.Wait()
+
+	for _, inc := range includes {
+		if inc.err != nil {
+			return GossConfig{}, inc.err
 		}
+		addTags(inc.gossConfig, inc.tags)
+		ret = mergeGoss(ret, inc.gossConfig)
+	}
+	if presetIncluded {
+		// The resources of the gossfile replace those of its presets
+		ret = mergeGoss(ret, gossConfig)
 	}
 	return ret, nil
 }
 
+// include is a gossfile included from another one, its resources get the
+// tags of the gossfile entry, and a preset is rendered with its vars
+type include struct {
+	path       string
+	sha256     string
+	tags       []string
+	vars       map[string]interface{}
+	gossConfig GossConfig
+	err        error
+}
+
+// includeReaders limits how many gossfiles are read at once
+var includeReaders = make(chan struct{}, runtime.NumCPU())
+
+// read reads the gossfile and the ones it includes
+func (inc *include) read(depth int) {
+	if isPreset(inc.path) {
+		inc.readPreset(depth)
+		return
+	}
+	includeReaders <- struct{}{}
+	data, err := readInclude(inc.path, inc.sha256)
+	<-includeReaders
+	var j GossConfig
+	if err == nil {
+		// The format of an included gossfile is that of its extension, a
+		// remote one without is detected
+		if format, ferr := getStoreFormatFromFileName(inc.path); ferr == nil {
+			j, err = readGossData(data, format, false)
+		} else {
+			j, err = ReadJSONData(data, isRemoteInclude(inc.path))
+		}
+	}
+	if err != nil {
+		inc.err = fmt.Errorf("could not read json data in %s: %s", redactURL(inc.path), err)
+		return
+	}
+	j, err = mergeJSONData(j, depth, includeDir(inc.path))
+	if err != nil {
+		inc.err = fmt.Errorf("could not write json data: %s", err)
+		return
+	}
+	inc.gossConfig = j
+}
+
+// readPreset reads the gossfile of a preset, which is rendered with the vars
+// of the include rather than --vars and includes nothing
+func (inc *include) readPreset(depth int) {
+	data, err := readPreset(inc.path, inc.vars)
+	if err != nil {
+		inc.err = err
+		return
+	}
+	j, err := readGossData(data, YAML, false)
+	if err != nil {
+		inc.err = fmt.Errorf("could not read json data in %s: %s", inc.path, err)
+		return
+	}
+	j, err = mergeJSONData(j, depth, presetScheme)
+	if err != nil {
+		inc.err = fmt.Errorf("could not write json data: %s", err)
+		return
+	}
+	inc.gossConfig = j
+}
+
 func WriteJSON(filePath string, gossConfig GossConfig) error {
 	jsonData, err := marshal(gossConfig)
 	if err != nil {
@@ -282,11 +407,53 @@ func marshal(gossConfig interface{}) ([]byte, error) {
 		return marshalJSON(gossConfig)
 	case YAML:
 		return marshalYAML(gossConfig)
+	case HCL:
+		return marshalHCL(gossConfig)
+	case CUE:
+		// JSON is CUE
+		return marshalJSON(gossConfig)
 	default:
 		return nil, fmt.Errorf("StoreFormat unset")
 	}
 }
 
+// convertToJSON converts HCL and CUE gossfiles to JSON, it's data for the
+// other formats
+func convertToJSON(data []byte, format int) ([]byte, error) {
+	switch format {
+	case HCL:
+		return hclToJSON(data)
+	case CUE:
+		return cueToJSON(data)
+	}
+	return data, nil
+}
+
+// cueToJSON exports the CUE gossfile data to JSON with the cue command, which
+// checks the constraints of its definitions
+func cueToJSON(data []byte) ([]byte, error) {
+	fh, err := ioutil.TempFile("", "goss-*.cue")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(fh.Name())
+	_, err = fh.Write(data)
+	if cerr := fh.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	cmd := util.NewCommand("cue", "export", "--out", "json", fh.Name())
+	if err := cmd.Run(); err != nil {
+		if stderr := strings.TrimSpace(cmd.Stderr.String()); stderr != "" {
+			return nil, fmt.Errorf("cue export: %s", stderr)
+		}
+		return nil, fmt.Errorf("cue export: %v", err)
+	}
+	return cmd.Stdout.Bytes(), nil
+}
+
 func unmarshal(data []byte, v interface{}, storeFormat int) error {
 	switch storeFormat {
 	case JSON:
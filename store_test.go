@@ -1,11 +1,16 @@
 package goss
 
 import (
+	"fmt"
 	"io/ioutil"
 	"log"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/aelsabbahy/goss/resource"
 )
 
 func Test_varsFromString(t *testing.T) {
@@ -209,7 +214,7 @@ func Test_loadVars(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := loadVars(tt.args.varsFile, tt.args.varsInline)
+			got, err := loadVars([]string{tt.args.varsFile}, tt.args.varsInline)
 
 			assert.Equal(t, tt.want, got, "map contents")
 			assert.Equal(t, tt.wantErr, err != nil, "has error")
@@ -217,6 +222,43 @@ func Test_loadVars(t *testing.T) {
 	}
 }
 
+func Test_mergeJSONData(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, content string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("goss.yaml", "gossfile:\n  \"inc/*.yaml\": {}\nfile:\n  /etc/passwd:\n    exists: true\n")
+	if err := os.Mkdir(filepath.Join(dir, "inc"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		write(fmt.Sprintf("inc/%d.yaml", i), fmt.Sprintf("command:\n  \"echo %d\":\n    exit-status: 0\n", i))
+	}
+
+	outStoreFormat = YAML
+	currentTemplateFilter = nil
+	j, err := ReadJSON(filepath.Join(dir, "goss.yaml"))
+	assert.NoError(t, err)
+	gossConfig, err := mergeJSONData(j, 0, dir)
+	assert.NoError(t, err)
+
+	var ids []string
+	for _, r := range gossConfig.Resources() {
+		ids = append(ids, r.(resource.ResourceRead).ID())
+	}
+	assert.Equal(t, []string{"/etc/passwd", "echo 0", "echo 1", "echo 2", "echo 3", "echo 4"}, ids)
+
+	write("inc/5.yaml", "command:\n  \"echo 5\":\n    bogus: 0\n")
+	_, err = mergeJSONData(j, 0, dir)
+	assert.Error(t, err)
+}
+
 func fileMaker(content string) (string, func()) {
 	bytes := []byte(content)
 
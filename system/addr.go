@@ -1,6 +1,7 @@
 package system
 
 import (
+	"context"
 	"net"
 	"strings"
 	"time"
@@ -18,15 +19,26 @@ type DefAddr struct {
 	address      string
 	LocalAddress string
 	Timeout      int
+	// IPVersion is the address family of the connection, 4, 6 or any
+	IPVersion string
+	// Netns is the network namespace the connection is made from
+	Netns    string
+	resolver *Resolver
 }
 
 func NewDefAddr(address string, system *System, config util.Config) Addr {
 	addr := normalizeAddress(address)
-	return &DefAddr{
+	a := &DefAddr{
 		address:      addr,
 		LocalAddress: config.LocalAddress,
 		Timeout:      config.TimeOutMilliSeconds(),
+		IPVersion:    ipVersion(config.IPVersion, system),
+		Netns:        netnsName(config.Netns, system),
 	}
+	if system != nil {
+		a.resolver = system.Resolver
+	}
+	return a
 }
 
 func (a *DefAddr) ID() string {
@@ -39,15 +51,16 @@ func (a *DefAddr) Exists() (bool, error) { return a.Reachable() }
 
 func (a *DefAddr) Reachable() (bool, error) {
 	network, address := splitAddress(a.address)
+	network = ipNetwork(network, a.IPVersion)
 
 	var localAddr net.Addr
-	if network == "udp" {
+	if strings.HasPrefix(network, "udp") {
 		localAddr = &net.UDPAddr{IP: net.ParseIP(a.LocalAddress)}
 	} else {
 		localAddr = &net.TCPAddr{IP: net.ParseIP(a.LocalAddress)}
 	}
-	d := net.Dialer{LocalAddr: localAddr, Timeout: time.Duration(a.Timeout) * time.Millisecond}
-	conn, err := d.Dial(network, address)
+	d := &net.Dialer{LocalAddr: localAddr, Timeout: time.Duration(a.Timeout) * time.Millisecond}
+	conn, err := a.resolver.dialNetns(context.Background(), d, a.Netns, network, address)
 	if err != nil {
 		return false, nil
 	}
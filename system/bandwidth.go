@@ -0,0 +1,234 @@
+package system
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// Bandwidth is the throughput of a bounded transfer, with the iperf3 client
+// to an iperf3 server of an iperf3://host[:port] target, or downloading an
+// http or https URL
+type Bandwidth interface {
+	Target() string
+	Exists() (bool, error)
+	SetTransfer(BandwidthTransfer)
+	Throughput() (float64, error)
+	Loss() (float64, error)
+}
+
+// BandwidthTransfer bounds a transfer, by its duration for iperf3 and by
+// MaxBytes or its duration, whichever comes first, for http
+type BandwidthTransfer struct {
+	// Duration is in seconds
+	Duration int
+	MaxBytes int64
+	// UDP, Bitrate and Reverse are the -u, -b and -R of iperf3
+	UDP     bool
+	Bitrate string
+	Reverse bool
+}
+
+// Defaults of a transfer that doesn't set its bounds
+const (
+	defaultBandwidthDuration = 3
+	defaultBandwidthMaxBytes = 10 << 20
+)
+
+type DefBandwidth struct {
+	target     string
+	transfer   BandwidthTransfer
+	timeout    int
+	loaded     bool
+	err        error
+	throughput float64
+	loss       float64
+	hasLoss    bool
+}
+
+func NewDefBandwidth(target string, system *System, config util.Config) Bandwidth {
+	return &DefBandwidth{target: target, timeout: config.TimeOutMilliSeconds()}
+}
+
+func (b *DefBandwidth) Target() string {
+	return b.target
+}
+
+func (b *DefBandwidth) Exists() (bool, error) {
+	if err := b.setup(); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+// SetTransfer sets the bounds and the iperf3 options of the transfer
+func (b *DefBandwidth) SetTransfer(transfer BandwidthTransfer) {
+	b.transfer = transfer
+}
+
+func (b *DefBandwidth) setup() error {
+	if b.loaded {
+		return b.err
+	}
+	b.loaded = true
+
+	if b.transfer.Duration <= 0 {
+		b.transfer.Duration = defaultBandwidthDuration
+	}
+	if b.transfer.MaxBytes <= 0 {
+		b.transfer.MaxBytes = defaultBandwidthMaxBytes
+	}
+	// The transfer is bounded by its duration, the timeout also covers
+	// connecting and iperf3's handshake
+	timeout := b.timeout
+	if timeout <= 0 {
+		timeout = (b.transfer.Duration + 10) * 1000
+	}
+
+	switch {
+	case strings.HasPrefix(b.target, "iperf3://"):
+		b.err = b.iperf3(timeout)
+	case strings.HasPrefix(b.target, "http://"), strings.HasPrefix(b.target, "https://"):
+		b.err = b.download(timeout)
+	default:
+		b.err = fmt.Errorf("bandwidth target must be iperf3://host[:port] or an http(s) URL, got: %s", b.target)
+	}
+	return b.err
+}
+
+// iperf3 runs the iperf3 client against the server of the target
+func (b *DefBandwidth) iperf3(timeout int) error {
+	host, port := strings.TrimPrefix(b.target, "iperf3://"), "5201"
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		host, port = h, p
+	}
+	args := []string{"-c", host, "-p", port, "-J", "-t", strconv.Itoa(b.transfer.Duration)}
+	if b.transfer.UDP {
+		args = append(args, "-u")
+	}
+	if b.transfer.Bitrate != "" {
+		args = append(args, "-b", b.transfer.Bitrate)
+	}
+	if b.transfer.Reverse {
+		args = append(args, "-R")
+	}
+	cmd := util.NewCommand("iperf3", args...)
+	err := runCommand(cmd, timeout)
+	// iperf3 reports its errors in the JSON it prints, exiting 1
+	if cmd.Stdout.Len() == 0 {
+		if err == nil {
+			err = fmt.Errorf("iperf3 printed no results")
+		}
+		if msg := strings.TrimSpace(cmd.Stderr.String()); msg != "" {
+			err = fmt.Errorf("iperf3: %s", msg)
+		}
+		return err
+	}
+	b.throughput, b.loss, b.hasLoss, err = parseIperf3(cmd.Stdout.Bytes())
+	return err
+}
+
+// parseIperf3 is the throughput in Mbit/s received of the -J output of an
+// iperf3 client, and the percentage of datagrams lost for udp
+func parseIperf3(out []byte) (throughput, loss float64, hasLoss bool, err error) {
+	type sum struct {
+		BitsPerSecond float64  `json:"bits_per_second"`
+		LostPercent   *float64 `json:"lost_percent"`
+	}
+	var result struct {
+		Error string `json:"error"`
+		End   struct {
+			// Sum is the udp summary, sum_received the tcp one and
+			// the udp one of newer versions
+			Sum         *sum `json:"sum"`
+			SumReceived *sum `json:"sum_received"`
+		} `json:"end"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return 0, 0, false, fmt.Errorf("iperf3: could not parse the results: %v", err)
+	}
+	if result.Error != "" {
+		return 0, 0, false, fmt.Errorf("iperf3: %s", result.Error)
+	}
+	sums := []*sum{result.End.SumReceived, result.End.Sum}
+	var received *sum
+	for _, s := range sums {
+		if s == nil {
+			continue
+		}
+		if received == nil {
+			received = s
+		}
+		if s.LostPercent != nil && !hasLoss {
+			loss, hasLoss = *s.LostPercent, true
+		}
+	}
+	if received == nil {
+		return 0, 0, false, fmt.Errorf("iperf3: no summary in the results")
+	}
+	return received.BitsPerSecond / 1e6, loss, hasLoss, nil
+}
+
+// download reads the body of the target up to the bounds of the transfer
+func (b *DefBandwidth) download(timeout int) error {
+	client := &http.Client{Timeout: time.Duration(timeout) * time.Millisecond}
+	resp, err := client.Get(b.target)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("bandwidth %s: %s", b.target, resp.Status)
+	}
+
+	start := time.Now()
+	deadline := start.Add(time.Duration(b.transfer.Duration) * time.Second)
+	var n int64
+	buf := make([]byte, 32<<10)
+	for n < b.transfer.MaxBytes && time.Now().Before(deadline) {
+		chunk := buf
+		if left := b.transfer.MaxBytes - n; left < int64(len(chunk)) {
+			chunk = chunk[:left]
+		}
+		read, err := resp.Body.Read(chunk)
+		n += int64(read)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	elapsed := time.Since(start).Seconds()
+	if n == 0 || elapsed == 0 {
+		return fmt.Errorf("bandwidth %s: the response has no body", b.target)
+	}
+	b.throughput = float64(n) * 8 / elapsed / 1e6
+	return nil
+}
+
+// Throughput is the rate of the transfer in Mbit/s
+func (b *DefBandwidth) Throughput() (float64, error) {
+	if err := b.setup(); err != nil {
+		return 0, err
+	}
+	return b.throughput, nil
+}
+
+// Loss is the percentage of the datagrams of an iperf3 udp transfer lost
+func (b *DefBandwidth) Loss() (float64, error) {
+	if err := b.setup(); err != nil {
+		return 0, err
+	}
+	if !b.hasLoss {
+		return 0, fmt.Errorf("loss is only measured by udp iperf3 transfers")
+	}
+	return b.loss, nil
+}
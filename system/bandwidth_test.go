@@ -0,0 +1,57 @@
+package system
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+func TestParseIperf3(t *testing.T) {
+	tests := []struct {
+		name       string
+		out        string
+		throughput float64
+		loss       float64
+		hasLoss    bool
+		err        bool
+	}{
+		{"tcp", `{"end": {"sum_sent": {"bits_per_second": 950000000}, "sum_received": {"bits_per_second": 940000000}}}`, 940, 0, false, false},
+		{"udp", `{"end": {"sum": {"bits_per_second": 100000000, "lost_percent": 0.25}}}`, 100, 0.25, true, false},
+		{"udp sum_received", `{"end": {"sum_received": {"bits_per_second": 99000000, "lost_percent": 1.5}}}`, 99, 1.5, true, false},
+		{"error", `{"start": {}, "error": "unable to connect to server: Connection refused"}`, 0, 0, false, true},
+		{"no summary", `{"end": {}}`, 0, 0, false, true},
+	}
+	for _, tt := range tests {
+		throughput, loss, hasLoss, err := parseIperf3([]byte(tt.out))
+		if (err != nil) != tt.err {
+			t.Errorf("%s: got error %v", tt.name, err)
+			continue
+		}
+		if throughput != tt.throughput || loss != tt.loss || hasLoss != tt.hasLoss {
+			t.Errorf("%s: got %v %v %v, want %v %v %v", tt.name, throughput, loss, hasLoss, tt.throughput, tt.loss, tt.hasLoss)
+		}
+	}
+}
+
+func TestBandwidthDownload(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 1<<20)))
+	}))
+	defer ts.Close()
+
+	b := NewDefBandwidth(ts.URL, nil, util.Config{})
+	b.SetTransfer(BandwidthTransfer{MaxBytes: 64 << 10})
+	if throughput, err := b.Throughput(); err != nil || throughput <= 0 {
+		t.Errorf("got %v, %v", throughput, err)
+	}
+	if _, err := b.Loss(); err == nil {
+		t.Error("loss of a download must be an error")
+	}
+
+	if _, err := NewDefBandwidth("ftp://example.com", nil, util.Config{}).Throughput(); err == nil {
+		t.Error("an ftp target must be an error")
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
+	"strings"
 	"time"
 
 	"github.com/aelsabbahy/goss/util"
@@ -16,23 +18,44 @@ type Command interface {
 	ExitStatus() (int, error)
 	Stdout() (io.Reader, error)
 	Stderr() (io.Reader, error)
+	Output() (io.Reader, error)
 }
 
 type DefCommand struct {
 	command    string
 	exitStatus int
-	stdout     io.Reader
-	stderr     io.Reader
+	stdout     []byte
+	stderr     []byte
+	output     []byte
 	loaded     bool
 	Timeout    int
 	err        error
+	policy     *CommandPolicy
+	shell      string
+	dir        string
+	env        []string
+	stdin      string
+	credential *Credential
+	sandbox    bool
 }
 
 func NewDefCommand(command string, system *System, config util.Config) Command {
-	return &DefCommand{
+	c := &DefCommand{
 		command: command,
 		Timeout: config.TimeOutMilliSeconds(),
+		shell:   config.Shell,
+		dir:     config.Dir,
+		env:     config.Env,
+		stdin:   config.Stdin,
+		sandbox: config.Sandbox,
 	}
+	if system != nil {
+		c.policy = system.CommandPolicy
+		if config.DropPrivileges {
+			c.credential = system.Unprivileged
+		}
+	}
+	return c
 }
 
 func (c *DefCommand) setup() error {
@@ -41,16 +64,50 @@ func (c *DefCommand) setup() error {
 	}
 	c.loaded = true
 
-	cmd := util.NewCommand("sh", "-c", c.command)
-	err := runCommand(cmd, c.Timeout)
+	shell := c.shell
+	if shell == "" {
+		shell = defaultShell
+	}
+	if err := c.policy.Check(shell, c.dir, c.env, c.command); err != nil {
+		c.err = err
+		return c.err
+	}
+
+	argv, err := shellArgv(shell, c.command)
+	if err != nil {
+		c.err = err
+		return c.err
+	}
+	if c.sandbox {
+		if argv, err = sandboxArgv(argv); err != nil {
+			c.err = err
+			return c.err
+		}
+	}
+	cmd := util.NewCommand(argv[0], argv[1:]...)
+	cmd.Cmd.Dir = c.dir
+	if len(c.env) > 0 {
+		cmd.Cmd.Env = append(os.Environ(), c.env...)
+	}
+	if c.stdin != "" {
+		cmd.Cmd.Stdin = strings.NewReader(c.stdin)
+	}
+	if c.credential != nil {
+		if err := SetCredential(cmd.Cmd, c.credential); err != nil {
+			c.err = err
+			return c.err
+		}
+	}
+	err = runCommand(cmd, c.Timeout)
 
 	// We don't care about ExitError since it's covered by status
 	if _, ok := err.(*exec.ExitError); !ok {
 		c.err = err
 	}
 	c.exitStatus = cmd.Status
-	c.stdout = bytes.NewReader(cmd.Stdout.Bytes())
-	c.stderr = bytes.NewReader(cmd.Stderr.Bytes())
+	c.stdout = decodeCommandOutput(cmd.Stdout.Bytes())
+	c.stderr = decodeCommandOutput(cmd.Stderr.Bytes())
+	c.output = decodeCommandOutput(cmd.Combined.Bytes())
 
 	return c.err
 }
@@ -65,16 +122,25 @@ func (c *DefCommand) ExitStatus() (int, error) {
 	return c.exitStatus, err
 }
 
+// Stdout, Stderr and Output return a new reader on every call so the output
+// can be checked by more than one attribute
 func (c *DefCommand) Stdout() (io.Reader, error) {
 	err := c.setup()
 
-	return c.stdout, err
+	return bytes.NewReader(c.stdout), err
 }
 
 func (c *DefCommand) Stderr() (io.Reader, error) {
 	err := c.setup()
 
-	return c.stderr, err
+	return bytes.NewReader(c.stderr), err
+}
+
+// Output is stdout and stderr interleaved in the order they were written
+func (c *DefCommand) Output() (io.Reader, error) {
+	err := c.setup()
+
+	return bytes.NewReader(c.output), err
 }
 
 // Stub out
@@ -82,6 +148,118 @@ func (c *DefCommand) Exists() (bool, error) {
 	return false, nil
 }
 
+// Shells are the values accepted by the shell attribute of command resources
+var Shells = []string{"sh", "bash", "powershell", "pwsh", "cmd", "none"}
+
+// shellArgv returns the argv that runs command with shell, the none shell
+// splits command into words and runs it directly
+func shellArgv(shell string, command string) ([]string, error) {
+	switch shell {
+	case "sh", "bash":
+		return []string{shell, "-c", command}, nil
+	case "powershell", "pwsh":
+		ps := shell
+		if ps == "powershell" && !HasCommand(ps) && HasCommand("pwsh") {
+			ps = "pwsh"
+		}
+		return []string{ps, "-NoProfile", "-NonInteractive", "-Command", powershellScript(command)}, nil
+	case "cmd":
+		return []string{"cmd", "/C", command}, nil
+	case "none":
+		argv, err := splitArgv(command)
+		if err != nil {
+			return nil, err
+		}
+		if len(argv) == 0 {
+			return nil, fmt.Errorf("empty command")
+		}
+		return argv, nil
+	}
+	return nil, fmt.Errorf("unsupported shell: %q, valid options: %s", shell, strings.Join(Shells, ", "))
+}
+
+// powershellScript wraps command so PowerShell writes UTF-8 and exits with the
+// exit code of the last native command, or 1 when the last statement failed,
+// rather than reporting 0 or 1 regardless of what command did
+func powershellScript(command string) string {
+	return "[Console]::OutputEncoding = [System.Text.Encoding]::UTF8; $global:LASTEXITCODE = 0; & {\n" +
+		command +
+		"\n}; if (-not $?) { if ($LASTEXITCODE) { exit $LASTEXITCODE }; exit 1 }; exit $LASTEXITCODE"
+}
+
+// splitArgv splits command into words the way a POSIX shell would, honoring
+// quotes and backslash escapes but performing no expansion
+func splitArgv(command string) ([]string, error) {
+	var argv []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	src := []rune(command)
+
+	for i := 0; i < len(src); i++ {
+		r := src[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			if i+1 == len(src) {
+				return nil, fmt.Errorf("unterminated escape in command: %q", command)
+			}
+			i++
+			// Inside double quotes a backslash only escapes characters that are special there
+			if quote == '"' && !strings.ContainsRune("$`\"\\\n", src[i]) {
+				word.WriteRune(r)
+			}
+			if src[i] != '\n' || quote == '"' {
+				word.WriteRune(src[i])
+			}
+			inWord = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				argv = append(argv, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in command: %q", command)
+	}
+	if inWord {
+		argv = append(argv, word.String())
+	}
+	return argv, nil
+}
+
+// sandboxArgv wraps argv so it's run by "goss sandbox-exec", which enters the
+// sandbox before executing it
+func sandboxArgv(argv []string) ([]string, error) {
+	if err := SandboxAvailable(); err != nil {
+		return nil, err
+	}
+	goss, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("sandbox: %v", err)
+	}
+	return append([]string{goss, "sandbox-exec", "--"}, argv...), nil
+}
+
 func runCommand(cmd *util.Command, timeout int) error {
 	c1 := make(chan bool, 1)
 	e1 := make(chan error, 1)
@@ -99,6 +277,7 @@ func runCommand(cmd *util.Command, timeout int) error {
 	case err := <-e1:
 		return err
 	case <-time.After(timeoutD):
+		cmd.Kill()
 		return fmt.Errorf("Command execution timed out (%s)", timeoutD)
 	}
 }
@@ -0,0 +1,593 @@
+package system
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// CommandPolicy restricts the executables command resources may invoke and the
+// shells used to invoke them. Entries containing a / are matched against the
+// full path of the executable, other entries against its base name, both
+// support filepath.Match wildcards.
+//
+// Deny always wins, when Allow is empty everything not denied is permitted
+type CommandPolicy struct {
+	Shells []string `json:"shells,omitempty" yaml:"shells,omitempty"`
+	Allow  []string `json:"allow,omitempty" yaml:"allow,omitempty"`
+	Deny   []string `json:"deny,omitempty" yaml:"deny,omitempty"`
+}
+
+// policyBuiltins are shell builtins that can't run other programs, they are
+// permitted by an allow list unless explicitly denied
+var policyBuiltins = map[string]bool{
+	"echo": true, "printf": true, "test": true, "[": true, "true": true, "false": true, ":": true,
+	"cd": true, "exit": true, "return": true, "export": true, "read": true, "set": true,
+	"shift": true, "unset": true, "wait": true, "break": true, "continue": true,
+}
+
+// policyKeywords are reserved words that may be followed by a command
+var policyKeywords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "fi": true, "do": true, "done": true,
+	"while": true, "until": true, "!": true, "{": true, "}": true, "time": true, "esac": true,
+}
+
+// policyArgKeywords are reserved words whose arguments aren't commands
+var policyArgKeywords = map[string]bool{
+	"for": true, "case": true, "select": true, "[[": true,
+}
+
+// policyWrappers run the command of their arguments, which is checked in
+// their place: the builtins builtin, command and exec, and env
+var policyWrappers = map[string]bool{
+	"builtin": true, "command": true, "exec": true, "env": true,
+}
+
+// policyOpaque run commands that can't be determined without running them,
+// such as those of a string, a file or an alias
+var policyOpaque = map[string]bool{
+	"eval": true, "xargs": true, "trap": true, ".": true, "source": true, "alias": true,
+}
+
+// policyDeclarations are builtins whose arguments may assign variables
+var policyDeclarations = map[string]bool{
+	"export": true, "declare": true, "typeset": true, "readonly": true, "local": true,
+}
+
+// policyShells are shells, the script of their -c is checked, any other
+// script they'd run can't be
+var policyShells = map[string]bool{
+	"sh": true, "bash": true, "dash": true, "ash": true, "ksh": true, "mksh": true, "zsh": true,
+}
+
+var shellAssignment = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*\+?=`)
+
+// pathAssignment sets or appends to PATH, changing where the executables of
+// the commands are looked up
+var pathAssignment = regexp.MustCompile(`^PATH\+?=`)
+
+// LoadCommandPolicy reads a yaml or json policy file, an empty path results in no policy
+func LoadCommandPolicy(path string) (*CommandPolicy, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read command policy: %v", err)
+	}
+	var p CommandPolicy
+	if err := yaml.UnmarshalStrict(data, &p); err != nil {
+		return nil, fmt.Errorf("could not parse command policy %s: %v", path, err)
+	}
+	for _, pattern := range append(append(append([]string{}, p.Shells...), p.Allow...), p.Deny...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern in command policy %s: %q", path, pattern)
+		}
+	}
+	return &p, nil
+}
+
+// Check returns an error when running command with shell isn't permitted, a nil
+// policy permits everything. Commands run without a shell, the none shell, are
+// checked by their first word and aren't subject to Shells.
+//
+// Commands are inspected without being run, constructs that make the executable
+// impossible to determine such as a variable in command position or here documents
+// are rejected, as is overriding PATH in env. Relative paths are resolved from dir
+func (p *CommandPolicy) Check(shell string, dir string, env []string, command string) error {
+	if p == nil {
+		return nil
+	}
+	for _, e := range env {
+		if strings.HasPrefix(e, "PATH=") {
+			return fmt.Errorf("command policy: overriding PATH is not allowed")
+		}
+	}
+	matchAny := func(patterns []string, name string, followLinks bool) bool {
+		if strings.Contains(name, "/") && !filepath.IsAbs(name) && dir != "" {
+			name = filepath.Join(dir, name)
+		}
+		return p.matchAny(patterns, name, followLinks)
+	}
+
+	var names []string
+	switch shell {
+	case "none":
+		argv, err := splitArgv(command)
+		if err != nil {
+			return fmt.Errorf("command policy: %v", err)
+		}
+		if names, err = argvNames(argv); err != nil {
+			return err
+		}
+	default:
+		if len(p.Shells) > 0 && !matchAny(p.Shells, shell, false) {
+			return fmt.Errorf("command policy: shell %q is not allowed", shell)
+		}
+		if matchAny(p.Deny, shell, true) {
+			return fmt.Errorf("command policy: shell %q is denied", shell)
+		}
+		if shell != "sh" && shell != "bash" {
+			if len(p.Allow) > 0 || len(p.Deny) > 0 {
+				return fmt.Errorf("command policy: commands run by %s can't be inspected for allowed executables", shell)
+			}
+			return nil
+		}
+		var err error
+		names, err = commandNames(command)
+		if err != nil {
+			return fmt.Errorf("command policy: %v", err)
+		}
+	}
+	return p.permit(names, matchAny)
+}
+
+// CheckArgv returns an error when running argv without a shell isn't
+// permitted, like Check with the none shell, a nil policy permits everything
+func (p *CommandPolicy) CheckArgv(argv []string) error {
+	if p == nil {
+		return nil
+	}
+	names, err := argvNames(argv)
+	if err != nil {
+		return err
+	}
+	return p.permit(names, p.matchAny)
+}
+
+// argvNames are the executables running argv runs, it and those it wraps
+func argvNames(argv []string) ([]string, error) {
+	var names []string
+	st := newPolicyState()
+	for _, w := range argv {
+		if err := st.addWord(w, false, &names); err != nil {
+			return nil, fmt.Errorf("command policy: %v", err)
+		}
+	}
+	if err := st.endCommand(); err != nil {
+		return nil, fmt.Errorf("command policy: %v", err)
+	}
+	return names, nil
+}
+
+// permit returns an error unless the policy permits every executable of names
+func (p *CommandPolicy) permit(names []string, matchAny func(patterns []string, name string, followLinks bool) bool) error {
+	for _, name := range names {
+		if matchAny(p.Deny, name, true) {
+			return fmt.Errorf("command policy: executable %q is denied", name)
+		}
+		if len(p.Allow) > 0 && !policyBuiltins[name] && !matchAny(p.Allow, name, false) {
+			return fmt.Errorf("command policy: executable %q is not allowed", name)
+		}
+	}
+	return nil
+}
+
+// matchAny reports whether name matches any of patterns, when followLinks is set
+// the target of a symlinked executable is considered as well
+func (p *CommandPolicy) matchAny(patterns []string, name string, followLinks bool) bool {
+	candidates := []string{filepath.Base(name)}
+	path := name
+	if !strings.Contains(name, "/") {
+		path, _ = exec.LookPath(name)
+	}
+	if path != "" {
+		path = filepath.Clean(path)
+		candidates = append(candidates, path)
+		if abs, err := filepath.Abs(path); err == nil {
+			candidates = append(candidates, abs)
+		}
+		if followLinks {
+			if resolved, err := filepath.EvalSymlinks(path); err == nil {
+				candidates = append(candidates, resolved, filepath.Base(resolved))
+			}
+		}
+	}
+
+	for _, pattern := range patterns {
+		for _, c := range candidates {
+			// Base name patterns only apply to base names and path patterns only to paths
+			if strings.Contains(pattern, "/") != strings.Contains(c, "/") {
+				continue
+			}
+			if ok, _ := filepath.Match(pattern, c); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// policyFrame is the state of the scanner saved when entering a command
+// substitution or subshell
+type policyFrame struct {
+	kind     rune
+	state    policyState
+	inDouble bool
+}
+
+type policyState struct {
+	word     *strings.Builder
+	inWord   bool
+	dynamic  bool
+	atStart  bool
+	skipRest bool
+	redirect bool
+	// funcName is set after function, whose next word names the function
+	funcName bool
+	// wrapper is the wrapper whose command is the next word
+	wrapper string
+	// skipNext skips the argument of an option of a wrapper or shell
+	skipNext bool
+	// shell is the shell of the command, until the script of its -c, which
+	// is the next word when script is set
+	shell  string
+	script bool
+	// declaration is set after a builtin of policyDeclarations, whose
+	// arguments are checked for assignments of PATH
+	declaration bool
+}
+
+func newPolicyState() *policyState {
+	return &policyState{atStart: true, word: new(strings.Builder)}
+}
+
+// addWord adds w, a word of the command, to names when it's the executable
+func (st *policyState) addWord(w string, dynamic bool, names *[]string) error {
+	switch {
+	case st.redirect:
+		st.redirect = false
+	case st.skipNext:
+		st.skipNext = false
+	case st.script:
+		if dynamic {
+			return fmt.Errorf("cannot determine the commands of %s -c %q", st.shell, w)
+		}
+		nested, err := commandNames(w)
+		if err != nil {
+			return fmt.Errorf("%s -c: %v", st.shell, err)
+		}
+		*names = append(*names, nested...)
+		// The remaining arguments are those of the script
+		st.shell, st.script, st.skipRest = "", false, true
+	case st.declaration:
+		switch {
+		case pathAssignment.MatchString(w):
+			return fmt.Errorf("overriding PATH is not allowed")
+		case dynamic && !shellAssignment.MatchString(w):
+			return fmt.Errorf("cannot determine the variables of %q", w)
+		}
+	case st.shell != "":
+		switch {
+		case w == "-o" || w == "+o":
+			st.skipNext = true
+		case strings.HasPrefix(w, "-") && strings.ContainsRune(w, 'c') && !strings.HasPrefix(w, "--"):
+			st.script = true
+		case strings.HasPrefix(w, "-") || strings.HasPrefix(w, "+"):
+		default:
+			return fmt.Errorf("cannot determine the commands of the script %q %s runs", w, st.shell)
+		}
+	case !st.atStart || st.skipRest:
+	case st.funcName:
+		st.funcName = false
+	case !dynamic && policyKeywords[w]:
+	case !dynamic && w == "function":
+		st.funcName = true
+	case !dynamic && policyArgKeywords[w]:
+		st.skipRest = true
+	case pathAssignment.MatchString(w):
+		return fmt.Errorf("overriding PATH is not allowed")
+	case shellAssignment.MatchString(w):
+	case dynamic:
+		return fmt.Errorf("cannot determine the executable of %q", w)
+	case st.wrapper != "" && strings.HasPrefix(w, "-"):
+		switch {
+		case st.wrapper == "env" && (w == "-S" || strings.HasPrefix(w, "--split-string")):
+			return fmt.Errorf("cannot determine the executable of env %s", w)
+		case st.wrapper == "exec" && w == "-a", st.wrapper == "env" && (w == "-u" || w == "-C"):
+			st.skipNext = true
+		}
+	default:
+		base := filepath.Base(w)
+		switch {
+		case policyOpaque[base]:
+			return fmt.Errorf("cannot determine the executables %s runs", w)
+		case policyWrappers[base]:
+			// builtin, command and exec are builtins, env is an executable as well
+			if base == "env" {
+				*names = append(*names, w)
+			}
+			st.wrapper = base
+		case policyShells[base]:
+			*names = append(*names, w)
+			st.shell, st.wrapper, st.atStart = w, "", false
+		default:
+			*names = append(*names, w)
+			st.wrapper, st.atStart = "", false
+			st.declaration = policyDeclarations[base]
+		}
+	}
+	return nil
+}
+
+// endCommand resets the state for the next command, a shell that would run
+// the commands of its input is an error
+func (st *policyState) endCommand() error {
+	if st.shell != "" {
+		return fmt.Errorf("cannot determine the commands %s reads from its input", st.shell)
+	}
+	st.atStart, st.skipRest, st.redirect = true, false, false
+	st.funcName, st.wrapper, st.skipNext, st.script = false, "", false, false
+	st.declaration = false
+	return nil
+}
+
+// commandNames returns the names of the commands a shell script would run,
+// including those in pipelines, lists, subshells, command substitutions,
+// function bodies and the wrappers of policyWrappers and policyShells
+func commandNames(script string) ([]string, error) {
+	var names []string
+	var stack []policyFrame
+	src := []rune(script)
+	inDouble := false
+	st := newPolicyState()
+
+	endWord := func() error {
+		if !st.inWord {
+			return nil
+		}
+		w := st.word.String()
+		dynamic := st.dynamic
+		st.word.Reset()
+		st.inWord, st.dynamic = false, false
+		return st.addWord(w, dynamic, &names)
+	}
+	separator := func() error {
+		if err := endWord(); err != nil {
+			return err
+		}
+		return st.endCommand()
+	}
+	push := func(kind rune) {
+		stack = append(stack, policyFrame{kind: kind, state: *st, inDouble: inDouble})
+		st = newPolicyState()
+		inDouble = false
+	}
+	pop := func() error {
+		if err := separator(); err != nil {
+			return err
+		}
+		f := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		saved := f.state
+		st = &saved
+		inDouble = f.inDouble
+		if f.kind == '(' {
+			return st.endCommand()
+		}
+		st.inWord, st.dynamic = true, true
+		return nil
+	}
+	top := func() rune {
+		if len(stack) == 0 {
+			return 0
+		}
+		return stack[len(stack)-1].kind
+	}
+	// scanTo returns the index of the close rune balancing src[start]
+	scanTo := func(start int, open, close rune) (int, error) {
+		depth := 0
+		for j := start; j < len(src); j++ {
+			switch src[j] {
+			case open:
+				depth++
+			case close:
+				depth--
+				if depth == 0 {
+					body := string(src[start : j+1])
+					if strings.Contains(body, "$(") || strings.Contains(body, "`") {
+						return 0, fmt.Errorf("command substitution inside an expansion is not supported")
+					}
+					return j, nil
+				}
+			}
+		}
+		return 0, fmt.Errorf("unterminated expansion")
+	}
+
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		next := rune(0)
+		if i+1 < len(src) {
+			next = src[i+1]
+		}
+
+		switch {
+		case c == '\\':
+			i++
+			if next != '\n' && next != 0 {
+				st.word.WriteRune(next)
+				st.inWord = true
+			}
+			continue
+		case c == '\'' && !inDouble:
+			end := i + 1
+			for end < len(src) && src[end] != '\'' {
+				end++
+			}
+			if end == len(src) {
+				return nil, fmt.Errorf("unterminated quote")
+			}
+			st.word.WriteString(string(src[i+1 : end]))
+			i = end
+			st.inWord = true
+			continue
+		case c == '"':
+			inDouble = !inDouble
+			st.inWord = true
+			continue
+		case c == '$' && next == '(' && i+2 < len(src) && src[i+2] == '(':
+			end, err := scanTo(i+1, '(', ')')
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			st.inWord, st.dynamic = true, true
+			continue
+		case c == '$' && next == '(':
+			push('$')
+			i++
+			continue
+		case c == '$' && next == '{':
+			end, err := scanTo(i+1, '{', '}')
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			st.inWord, st.dynamic = true, true
+			continue
+		case c == '$' && next != 0 && strings.ContainsRune("_@*#?$!-0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ", next):
+			st.word.WriteRune(c)
+			st.inWord, st.dynamic = true, true
+			continue
+		case c == '`':
+			if top() == '`' && !inDouble {
+				if err := pop(); err != nil {
+					return nil, err
+				}
+			} else {
+				push('`')
+			}
+			continue
+		case inDouble:
+			st.word.WriteRune(c)
+			continue
+		}
+
+		switch c {
+		case ' ', '\t':
+			if err := endWord(); err != nil {
+				return nil, err
+			}
+		case '#':
+			if st.inWord {
+				st.word.WriteRune(c)
+				continue
+			}
+			for i+1 < len(src) && src[i+1] != '\n' {
+				i++
+			}
+		case '\n', ';', '|':
+			if err := separator(); err != nil {
+				return nil, err
+			}
+		case '&':
+			if err := endWord(); err != nil {
+				return nil, err
+			}
+			if next == '>' {
+				// &> and &>> redirect both stdout and stderr
+				for i+1 < len(src) && src[i+1] == '>' {
+					i++
+				}
+				st.redirect = true
+				continue
+			}
+			if err := separator(); err != nil {
+				return nil, err
+			}
+		case '(':
+			if err := endWord(); err != nil {
+				return nil, err
+			}
+			push('(')
+		case ')':
+			switch top() {
+			case '(', '$':
+				if err := pop(); err != nil {
+					return nil, err
+				}
+			default:
+				// The end of a case pattern
+				if err := separator(); err != nil {
+					return nil, err
+				}
+			}
+		case '<', '>':
+			if next == '(' {
+				// Process substitution
+				if err := endWord(); err != nil {
+					return nil, err
+				}
+				push('(')
+				i++
+				continue
+			}
+			if c == '<' && next == '<' && (i+2 >= len(src) || src[i+2] != '<') {
+				return nil, fmt.Errorf("here-documents are not supported")
+			}
+			if st.inWord && !st.dynamic && isDigits(st.word.String()) {
+				// A file descriptor, e.g. 2>&1
+				st.word.Reset()
+				st.inWord = false
+			} else if err := endWord(); err != nil {
+				return nil, err
+			}
+			for i+1 < len(src) && strings.ContainsRune("<>&|", src[i+1]) {
+				i++
+			}
+			st.redirect = true
+		default:
+			st.word.WriteRune(c)
+			st.inWord = true
+		}
+	}
+
+	if inDouble {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("unterminated command substitution or subshell")
+	}
+	if err := separator(); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
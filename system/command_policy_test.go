@@ -0,0 +1,153 @@
+package system
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommandNames(t *testing.T) {
+	tables := []struct {
+		in   string
+		want []string
+	}{
+		{"echo hello", []string{"echo"}},
+		{"FOO=bar /usr/bin/env -i A=1 ls -l", []string{"/usr/bin/env", "ls"}},
+		{"command -p rm x; exec -a name rm y", []string{"rm", "rm"}},
+		{"function f { rm -rf /tmp/x; }; f", []string{"rm", "f"}},
+		{"f() { rm x; }", []string{"f", "rm"}},
+		{"sh -c 'rm x' name arg; bash -ec \"id -u\"", []string{"sh", "rm", "bash", "id"}},
+		{"cat /etc/passwd | grep root && wc -l < /etc/group; id", []string{"cat", "grep", "wc", "id"}},
+		{"ls 2>&1 >/dev/null &", []string{"ls"}},
+		{"echo \"$(hostname) `uname -r`\"", []string{"echo", "hostname", "uname"}},
+		{"VERSION=$(cat /etc/version) test -n \"$VERSION\"", []string{"cat", "test"}},
+		{"(cd /tmp && pwd) || exit 1", []string{"cd", "pwd", "exit"}},
+		{"if grep -q x 'a; rm -rf /'; then echo yes; fi", []string{"grep", "echo"}},
+		{"for f in a b; do stat $f; done", []string{"stat"}},
+		{"diff <(sort a) <(sort b) # compare", []string{"diff", "sort", "sort"}},
+		{"echo $((1 + 2)) ${HOME}", []string{"echo"}},
+		{"echo x$(id -u)y", []string{"echo", "id"}},
+	}
+
+	for _, table := range tables {
+		got, err := commandNames(table.in)
+		if err != nil {
+			t.Errorf("commandNames (%s) returned an error: %v", table.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, table.want) {
+			t.Errorf("commandNames (%s) was incorrect, got: %v, want: %v.", table.in, got, table.want)
+		}
+	}
+
+	for _, in := range []string{"$CMD -rf /", "cat <<EOF\nrm\nEOF", "echo 'unterminated", "echo $(ls", "echo ${X:-$(rm)}",
+		"eval rm x", "ls | xargs rm", "echo rm x | sh", "sh script.sh", "sh -c \"$CMD\"", "env -S 'rm x'"} {
+		if _, err := commandNames(in); err == nil {
+			t.Errorf("commandNames (%s) should have returned an error", in)
+		}
+	}
+}
+
+func TestCommandPolicyCheck(t *testing.T) {
+	p := &CommandPolicy{
+		Shells: []string{"sh"},
+		Allow:  []string{"cat", "grep", "/usr/local/bin/*"},
+		Deny:   []string{"rm"},
+	}
+
+	tables := []struct {
+		shell   string
+		command string
+		ok      bool
+	}{
+		{"sh", "cat /etc/passwd | grep root", true},
+		{"sh", "echo $(cat /etc/hostname)", true},
+		{"sh", "/usr/local/bin/check --all", true},
+		{"sh", "curl http://example.com", false},
+		{"sh", "cat x; rm -rf /", false},
+		{"sh", "echo $(rm -rf /)", false},
+		{"bash", "cat /etc/passwd", false},
+		{"none", "cat '/etc/pass wd'", true},
+		{"none", "rm -rf /", false},
+		{"powershell", "Get-Process", false},
+	}
+
+	for _, table := range tables {
+		err := p.Check(table.shell, "", nil, table.command)
+		if (err == nil) != table.ok {
+			t.Errorf("Check (%s, %s) was incorrect, got: %v, want ok: %v.", table.shell, table.command, err, table.ok)
+		}
+	}
+
+	// Wrappers, functions and nested shells don't hide the commands they run
+	for _, command := range []string{
+		"function cat { rm -rf /tmp/x; }; cat",
+		"command rm x",
+		"env rm x",
+		"eval rm",
+		"exec rm",
+		"ls | xargs rm",
+		"sh -c 'rm -rf /tmp/x'",
+		"function f { rm -rf /tmp/x; }; f",
+	} {
+		for _, policy := range []*CommandPolicy{{Allow: []string{"cat", "ls", "env", "sh"}}, {Deny: []string{"rm"}}} {
+			if err := policy.Check("sh", "", nil, command); err == nil {
+				t.Errorf("Check (%s) with %+v should have returned an error", command, *policy)
+			}
+		}
+	}
+	// Builtins, traps, sourced files and aliases don't hide them either
+	for _, command := range []string{
+		"builtin eval 'rm x'",
+		"trap 'rm x' EXIT; true",
+		". ./x.sh",
+		"source ./x.sh",
+		"alias x=rm; x",
+	} {
+		if err := (&CommandPolicy{Deny: []string{"rm"}}).Check("sh", "", nil, command); err == nil {
+			t.Errorf("Check (%s) with a deny list should have returned an error", command)
+		}
+	}
+
+	// Nor does overriding PATH, inline or through a declaration
+	for _, command := range []string{
+		"PATH=/tmp cat x",
+		"PATH=/tmp; cat x",
+		"PATH+=:/tmp; cat x",
+		"env PATH=/tmp cat x",
+		"export PATH=/tmp; cat x",
+		"declare -x PATH=/tmp; cat x",
+		"typeset PATH=/tmp; cat x",
+		"readonly PATH=/tmp; cat x",
+		"export $VAR; cat x",
+	} {
+		if err := (&CommandPolicy{Allow: []string{"cat", "env", "declare", "typeset", "readonly"}}).Check("sh", "", nil, command); err == nil {
+			t.Errorf("Check (%s) with an allow list should have returned an error", command)
+		}
+	}
+	if err := (&CommandPolicy{Allow: []string{"cat"}}).Check("sh", "", nil, "export LANG=C HOME=$HOME; cat x"); err != nil {
+		t.Errorf("Check (export LANG=C HOME=$HOME) returned an error: %v", err)
+	}
+	if err := (&CommandPolicy{Allow: []string{"cat"}}).Check("none", "", nil, "PATH=/tmp cat x"); err == nil {
+		t.Error("Check (none, PATH=/tmp cat x) should have returned an error")
+	}
+
+	if err := (&CommandPolicy{Deny: []string{"rm"}}).Check("none", "", nil, "env rm x"); err == nil {
+		t.Error("Check (none, env rm x) should have returned an error")
+	}
+	if err := (&CommandPolicy{Allow: []string{"cat"}}).Check("sh", "", nil, "command cat x"); err != nil {
+		t.Errorf("Check (command cat x) returned an error: %v", err)
+	}
+
+	if err := p.Check("sh", "", []string{"PATH=/tmp"}, "cat x"); err == nil {
+		t.Error("Check should reject overriding PATH")
+	}
+	deny := &CommandPolicy{Deny: []string{"/opt/bad/*"}}
+	if err := deny.Check("sh", "/opt/bad", nil, "./tool"); err == nil {
+		t.Error("Check should resolve relative paths from dir")
+	}
+
+	var nilPolicy *CommandPolicy
+	if err := nilPolicy.Check("sh", "", nil, "rm -rf /"); err != nil {
+		t.Errorf("nil policy should permit everything, got: %v", err)
+	}
+}
@@ -0,0 +1,9 @@
+// +build linux darwin !windows
+
+package system
+
+const defaultShell = "sh"
+
+func decodeCommandOutput(b []byte) []byte {
+	return b
+}
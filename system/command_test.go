@@ -0,0 +1,52 @@
+package system
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitArgv(t *testing.T) {
+	tables := []struct {
+		in   string
+		want []string
+	}{
+		{"echo hello world", []string{"echo", "hello", "world"}},
+		{"  ls   -l\t/tmp\n", []string{"ls", "-l", "/tmp"}},
+		{`printf '%s\n' "a b" c\ d`, []string{"printf", `%s\n`, "a b", "c d"}},
+		{`echo "x\"y" "\$HOME" "\w" ''`, []string{"echo", `x"y`, "$HOME", `\w`, ""}},
+		{`echo $HOME *`, []string{"echo", "$HOME", "*"}},
+	}
+
+	for _, table := range tables {
+		got, err := splitArgv(table.in)
+		if err != nil {
+			t.Errorf("splitArgv (%s) returned an error: %v", table.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, table.want) {
+			t.Errorf("splitArgv (%s) was incorrect, got: %q, want: %q.", table.in, got, table.want)
+		}
+	}
+
+	for _, in := range []string{`echo "unterminated`, `echo 'unterminated`, `echo \`} {
+		if _, err := splitArgv(in); err == nil {
+			t.Errorf("splitArgv (%s) should have returned an error", in)
+		}
+	}
+}
+
+func TestShellArgv(t *testing.T) {
+	got, err := shellArgv("bash", "echo $HOME")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"bash", "-c", "echo $HOME"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("shellArgv was incorrect, got: %q, want: %q.", got, want)
+	}
+	if _, err := shellArgv("zsh", "echo"); err == nil {
+		t.Error("shellArgv should reject unsupported shells")
+	}
+	if _, err := shellArgv("none", "   "); err == nil {
+		t.Error("shellArgv should reject an empty command")
+	}
+}
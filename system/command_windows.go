@@ -0,0 +1,16 @@
+// +build windows
+
+package system
+
+import (
+	"github.com/aelsabbahy/goss/util"
+)
+
+// defaultShell is PowerShell, sh usually doesn't exist on Windows
+const defaultShell = "powershell"
+
+// decodeCommandOutput converts UTF-16 output, common for Windows tools, to UTF-8
+func decodeCommandOutput(b []byte) []byte {
+	out, _ := util.DecodeText(b, "auto")
+	return out
+}
@@ -0,0 +1,76 @@
+package system
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+var (
+	dockerEnv    = "/.dockerenv"
+	containerEnv = "/run/.containerenv"
+	procInit     = "/proc/1"
+)
+
+// cgroupRuntimes are the container runtimes by the cgroup they put init in
+var cgroupRuntimes = []struct{ cgroup, runtime string }{
+	{"/kubepods", "kubernetes"},
+	{"/libpod", "podman"},
+	{"/docker", "docker"},
+	{"/lxc", "lxc"},
+	{"/containerd", "containerd"},
+}
+
+// DetectContainer attempts to detect the container runtime goss is running
+// in, such as "docker", "podman", "kubernetes" or "lxc". It returns an empty
+// string when goss doesn't run in a container.
+func DetectContainer() string {
+	if _, err := os.Stat(containerEnv); err == nil {
+		return "podman"
+	}
+	// Set for init by lxc, systemd-nspawn and podman among others
+	if c := os.Getenv("container"); c != "" {
+		return c
+	}
+	if env, err := ioutil.ReadFile(procInit + "/environ"); err == nil {
+		for _, v := range bytes.Split(env, []byte{0}) {
+			if c := bytes.TrimPrefix(v, []byte("container=")); len(c) < len(v) && len(c) > 0 {
+				return string(c)
+			}
+		}
+	}
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return "kubernetes"
+	}
+	if _, err := os.Stat(dockerEnv); err == nil {
+		return "docker"
+	}
+	if f, err := os.Open(procInit + "/cgroup"); err == nil {
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			for _, c := range cgroupRuntimes {
+				if strings.Contains(scanner.Text(), c.cgroup) {
+					return c.runtime
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// hasInit reports whether init is a service manager, containers often run
+// their application or a minimal init such as tini instead
+func hasInit() bool {
+	comm, err := ioutil.ReadFile(procInit + "/comm")
+	if err != nil {
+		return true
+	}
+	switch strings.TrimSpace(string(comm)) {
+	case "systemd", "init", "upstart", "openrc-init":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+// +build no_container slim
+
+package system
+
+import (
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+type disabledContainer struct {
+	name string
+}
+
+func NewDefContainer(name string, system *System, config util.Config) Container {
+	return &disabledContainer{name: name}
+}
+
+func (c *disabledContainer) Name() string               { return c.name }
+func (c *disabledContainer) Exists() (bool, error)      { return false, errNotBuiltIn("container") }
+func (c *disabledContainer) Running() (bool, error)     { return false, errNotBuiltIn("container") }
+func (c *disabledContainer) Health() (string, error)    { return "", errNotBuiltIn("container") }
+func (c *disabledContainer) Image() (string, error)     { return "", errNotBuiltIn("container") }
+func (c *disabledContainer) RestartCount() (int, error) { return 0, errNotBuiltIn("container") }
+func (c *disabledContainer) Ports() ([]string, error)   { return nil, errNotBuiltIn("container") }
+func (c *disabledContainer) Mounts() ([]string, error)  { return nil, errNotBuiltIn("container") }
+func (c *disabledContainer) SetSocket(string)           {}
+
+func RunningContainers(socket string, timeout time.Duration) ([]string, error) {
+	return nil, errNotBuiltIn("container")
+}
@@ -0,0 +1,277 @@
+// +build !no_container,!slim
+
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+type DefContainer struct {
+	name    string
+	socket  string
+	timeout time.Duration
+	loaded  bool
+	err     error
+	inspect *containerInspect
+}
+
+// containerSockets are the API sockets tried in order when neither the
+// resource nor DOCKER_HOST sets one, the docker one is also served by
+// podman-docker
+var containerSockets = []string{
+	"/var/run/docker.sock",
+	"/run/podman/podman.sock",
+}
+
+// containerInspect is the part of GET /containers/{name}/json goss uses, it's
+// the same for the Docker API and the Docker compatible API of Podman
+type containerInspect struct {
+	RestartCount int
+	State        struct {
+		Running bool
+		Health  *struct {
+			Status string
+		}
+	}
+	Config struct {
+		Image string
+	}
+	Mounts []struct {
+		Source      string
+		Destination string
+	}
+	NetworkSettings struct {
+		Ports map[string][]struct {
+			HostIp   string
+			HostPort string
+		}
+	}
+}
+
+func NewDefContainer(name string, system *System, config util.Config) Container {
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	return &DefContainer{name: name, timeout: timeout}
+}
+
+func (c *DefContainer) Name() string {
+	return c.name
+}
+
+// SetSocket sets the path of the engine's API socket, a unix:// URL as in
+// DOCKER_HOST is accepted as well
+func (c *DefContainer) SetSocket(socket string) {
+	c.socket = strings.TrimPrefix(socket, "unix://")
+}
+
+// engineSocket finds the API socket, the rootless podman one of the user is
+// tried last
+func (c *DefContainer) engineSocket() (string, error) {
+	if c.socket != "" {
+		return c.socket, nil
+	}
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		if !strings.HasPrefix(host, "unix://") {
+			return "", fmt.Errorf("DOCKER_HOST %q is not supported, only unix:// sockets are", host)
+		}
+		return strings.TrimPrefix(host, "unix://"), nil
+	}
+	sockets := containerSockets
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		sockets = append(sockets[:len(sockets):len(sockets)], filepath.Join(dir, "podman", "podman.sock"))
+	}
+	for _, s := range sockets {
+		if _, err := os.Stat(s); err == nil {
+			return s, nil
+		}
+	}
+	return "", util.NewCodedError(util.ErrCodeBackendNotFound, fmt.Errorf("no docker or podman socket found, tried %s", strings.Join(sockets, ", ")))
+}
+
+func engineClient(socket string, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+			DisableKeepAlives: true,
+		},
+		Timeout: timeout,
+	}
+}
+
+// RunningContainers are the names of the running containers of the engine
+// of socket, found as for the container resource when it's empty
+func RunningContainers(socket string, timeout time.Duration) ([]string, error) {
+	c := &DefContainer{socket: strings.TrimPrefix(socket, "unix://")}
+	socket, err := c.engineSocket()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := engineClient(socket, timeout).Get("http://engine/containers/json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("listing containers: %s", resp.Status)
+	}
+	var containers []struct {
+		Names []string
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&containers); err != nil {
+		return nil, fmt.Errorf("listing containers: %v", err)
+	}
+	var names []string
+	for _, c := range containers {
+		if len(c.Names) > 0 {
+			names = append(names, strings.TrimPrefix(c.Names[0], "/"))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (c *DefContainer) setup() error {
+	if c.loaded {
+		return c.err
+	}
+	c.loaded = true
+
+	socket, err := c.engineSocket()
+	if err != nil {
+		c.err = err
+		return c.err
+	}
+	client := engineClient(socket, c.timeout)
+	// The host is ignored, the connection is always to the socket
+	resp, err := client.Get("http://engine/containers/" + url.PathEscape(c.name) + "/json")
+	if err != nil {
+		c.err = err
+		return c.err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil
+	default:
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		c.err = fmt.Errorf("inspecting container %s: %s: %s", c.name, resp.Status, apiErr.Message)
+		return c.err
+	}
+	var inspect containerInspect
+	if err := json.NewDecoder(resp.Body).Decode(&inspect); err != nil {
+		c.err = fmt.Errorf("inspecting container %s: %v", c.name, err)
+		return c.err
+	}
+	c.inspect = &inspect
+	return nil
+}
+
+func (c *DefContainer) Exists() (bool, error) {
+	if err := c.setup(); err != nil {
+		return false, err
+	}
+	return c.inspect != nil, nil
+}
+
+// get returns the inspect output, it's an error for containers that don't exist
+func (c *DefContainer) get() (*containerInspect, error) {
+	if err := c.setup(); err != nil {
+		return nil, err
+	}
+	if c.inspect == nil {
+		return nil, fmt.Errorf("container %s doesn't exist", c.name)
+	}
+	return c.inspect, nil
+}
+
+func (c *DefContainer) Running() (bool, error) {
+	i, err := c.get()
+	if err != nil {
+		return false, err
+	}
+	return i.State.Running, nil
+}
+
+// Health is the status of the container's health check, healthy, unhealthy
+// or starting, and none when the image doesn't define one
+func (c *DefContainer) Health() (string, error) {
+	i, err := c.get()
+	if err != nil {
+		return "", err
+	}
+	if i.State.Health == nil || i.State.Health.Status == "" {
+		return "none", nil
+	}
+	return i.State.Health.Status, nil
+}
+
+// Image is the image the container was created from as given, such as
+// nginx:1.25
+func (c *DefContainer) Image() (string, error) {
+	i, err := c.get()
+	if err != nil {
+		return "", err
+	}
+	return i.Config.Image, nil
+}
+
+func (c *DefContainer) RestartCount() (int, error) {
+	i, err := c.get()
+	if err != nil {
+		return 0, err
+	}
+	return i.RestartCount, nil
+}
+
+// Ports are the published ports in the format of docker ps, such as
+// 0.0.0.0:8080->80/tcp
+func (c *DefContainer) Ports() ([]string, error) {
+	i, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	ports := []string{}
+	for port, bindings := range i.NetworkSettings.Ports {
+		for _, b := range bindings {
+			ports = append(ports, net.JoinHostPort(b.HostIp, b.HostPort)+"->"+port)
+		}
+	}
+	sort.Strings(ports)
+	return ports, nil
+}
+
+// Mounts are the volumes and bind mounts as source:destination, the source
+// of a named volume is where the engine stores it
+func (c *DefContainer) Mounts() ([]string, error) {
+	i, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	mounts := []string{}
+	for _, m := range i.Mounts {
+		mounts = append(mounts, m.Source+":"+m.Destination)
+	}
+	sort.Strings(mounts)
+	return mounts, nil
+}
@@ -0,0 +1,93 @@
+// +build !no_container,!slim
+
+package system
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+func TestDefContainer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goss-engine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "docker.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skip(err)
+	}
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/containers/web/json":
+			fmt.Fprint(w, `{"RestartCount": 2, "State": {"Running": true, "Health": {"Status": "healthy"}},
+				"Config": {"Image": "nginx:1.25"},
+				"Mounts": [{"Source": "/srv/www", "Destination": "/usr/share/nginx/html"}],
+				"NetworkSettings": {"Ports": {"80/tcp": [{"HostIp": "0.0.0.0", "HostPort": "8080"}, {"HostIp": "::", "HostPort": "8080"}], "443/tcp": null}}}`)
+		case "/containers/db/json":
+			fmt.Fprint(w, `{"State": {"Running": false}}`)
+		case "/containers/json":
+			fmt.Fprint(w, `[{"Names": ["/web"]}, {"Names": ["/cache", "/web/cache"]}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "No such container"}`)
+		}
+	})}
+	go srv.Serve(l)
+	defer srv.Close()
+
+	if names, err := RunningContainers("unix://"+socket, time.Second); err != nil || !reflect.DeepEqual(names, []string{"cache", "web"}) {
+		t.Errorf("RunningContainers was incorrect, got: %v, %v, want: [cache web].", names, err)
+	}
+
+	c := NewDefContainer("web", nil, util.Config{})
+	c.SetSocket("unix://" + socket)
+	if exists, err := c.Exists(); err != nil || !exists {
+		t.Fatalf("Exists of web was incorrect, got: %v, %v, want: true.", exists, err)
+	}
+	if running, _ := c.Running(); !running {
+		t.Errorf("Running of web was incorrect, got: false, want: true.")
+	}
+	if health, _ := c.Health(); health != "healthy" {
+		t.Errorf("Health of web was incorrect, got: %q, want: healthy.", health)
+	}
+	if image, _ := c.Image(); image != "nginx:1.25" {
+		t.Errorf("Image of web was incorrect, got: %q, want: nginx:1.25.", image)
+	}
+	if count, _ := c.RestartCount(); count != 2 {
+		t.Errorf("RestartCount of web was incorrect, got: %d, want: 2.", count)
+	}
+	wantPorts := []string{"0.0.0.0:8080->80/tcp", "[::]:8080->80/tcp"}
+	if ports, _ := c.Ports(); !reflect.DeepEqual(ports, wantPorts) {
+		t.Errorf("Ports of web were incorrect, got: %v, want: %v.", ports, wantPorts)
+	}
+	wantMounts := []string{"/srv/www:/usr/share/nginx/html"}
+	if mounts, _ := c.Mounts(); !reflect.DeepEqual(mounts, wantMounts) {
+		t.Errorf("Mounts of web were incorrect, got: %v, want: %v.", mounts, wantMounts)
+	}
+
+	c = NewDefContainer("db", nil, util.Config{})
+	c.SetSocket(socket)
+	if health, _ := c.Health(); health != "none" {
+		t.Errorf("Health without a health check was incorrect, got: %q, want: none.", health)
+	}
+
+	c = NewDefContainer("missing", nil, util.Config{})
+	c.SetSocket(socket)
+	if exists, err := c.Exists(); err != nil || exists {
+		t.Errorf("Exists of a missing container was incorrect, got: %v, %v, want: false.", exists, err)
+	}
+	if _, err := c.Running(); err == nil {
+		t.Errorf("Running of a missing container should be an error")
+	}
+}
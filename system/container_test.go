@@ -0,0 +1,60 @@
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectContainer(t *testing.T) {
+	root, err := ioutil.TempDir("", "goss-container")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	defer func(d, c, p string) { dockerEnv, containerEnv, procInit = d, c, p }(dockerEnv, containerEnv, procInit)
+	dockerEnv = filepath.Join(root, ".dockerenv")
+	containerEnv = filepath.Join(root, ".containerenv")
+	procInit = root
+	for _, e := range []string{"container", "KUBERNETES_SERVICE_HOST"} {
+		if v, ok := os.LookupEnv(e); ok {
+			defer os.Setenv(e, v)
+			os.Unsetenv(e)
+		}
+	}
+
+	write := func(name, content string) {
+		if err := ioutil.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("cgroup", "12:pids:/\n0::/init.scope\n")
+	if got := DetectContainer(); got != "" {
+		t.Errorf("DetectContainer on a host was incorrect, got: %q, want: \"\".", got)
+	}
+	write("cgroup", "12:pids:/kubepods/besteffort/pod1/abc\n")
+	if got := DetectContainer(); got != "kubernetes" {
+		t.Errorf("DetectContainer by cgroup was incorrect, got: %q, want: kubernetes.", got)
+	}
+	write(".dockerenv", "")
+	if got := DetectContainer(); got != "docker" {
+		t.Errorf("DetectContainer with %s was incorrect, got: %q, want: docker.", dockerEnv, got)
+	}
+	write("environ", "PATH=/bin\x00container=lxc\x00")
+	if got := DetectContainer(); got != "lxc" {
+		t.Errorf("DetectContainer by the environment of init was incorrect, got: %q, want: lxc.", got)
+	}
+	write(".containerenv", "")
+	if got := DetectContainer(); got != "podman" {
+		t.Errorf("DetectContainer with %s was incorrect, got: %q, want: podman.", containerEnv, got)
+	}
+
+	for comm, want := range map[string]bool{"systemd\n": true, "init\n": true, "tini\n": false, "nginx\n": false} {
+		write("comm", comm)
+		if got := hasInit(); got != want {
+			t.Errorf("hasInit (%q) was incorrect, got: %v, want: %v.", comm, got, want)
+		}
+	}
+}
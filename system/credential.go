@@ -0,0 +1,22 @@
+package system
+
+import (
+	"github.com/opencontainers/runc/libcontainer/user"
+)
+
+// Credential is a user and group processes can run as
+type Credential struct {
+	User string
+	Uid  int
+	Gid  int
+}
+
+// LookupCredential returns the credential of the user name, with the primary
+// group of the user
+func LookupCredential(name string) (*Credential, error) {
+	u, err := user.LookupUser(name)
+	if err != nil {
+		return nil, err
+	}
+	return &Credential{User: u.Name, Uid: u.Uid, Gid: u.Gid}, nil
+}
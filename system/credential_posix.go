@@ -0,0 +1,21 @@
+// +build linux darwin !windows
+
+package system
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// SetCredential makes cmd run as cred without supplementary groups
+func SetCredential(cmd *exec.Cmd, cred *Credential) error {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Credential = &syscall.Credential{
+		Uid:    uint32(cred.Uid),
+		Gid:    uint32(cred.Gid),
+		Groups: []uint32{},
+	}
+	return nil
+}
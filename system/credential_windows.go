@@ -0,0 +1,14 @@
+// +build windows
+
+package system
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// SetCredential isn't supported, Windows has no equivalent of running a
+// process as another user without their password
+func SetCredential(cmd *exec.Cmd, cred *Credential) error {
+	return fmt.Errorf("running checks as %s is not supported on windows", cred.User)
+}
@@ -0,0 +1,221 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// CryptoPolicy is the system wide cryptography configuration, the only name
+// is "system"
+type CryptoPolicy interface {
+	Name() string
+	Exists() (bool, error)
+	Policy() (string, error)
+	FIPS() (bool, error)
+	OpenSSLMinProtocol() (string, error)
+	OpenSSLCipherString() (string, error)
+}
+
+type DefCryptoPolicy struct {
+	name          string
+	loaded        bool
+	err           error
+	systemDefault map[string]string
+}
+
+var (
+	cryptoPolicyState = "/etc/crypto-policies/state/current"
+	fipsEnabled       = "/proc/sys/crypto/fips_enabled"
+	opensslConfigs    = []string{"/etc/pki/tls/openssl.cnf", "/etc/ssl/openssl.cnf", "/usr/lib/ssl/openssl.cnf"}
+)
+
+func NewDefCryptoPolicy(name string, system *System, config util.Config) CryptoPolicy {
+	return &DefCryptoPolicy{name: name}
+}
+
+func (c *DefCryptoPolicy) Name() string {
+	return c.name
+}
+
+func (c *DefCryptoPolicy) Exists() (bool, error) {
+	return c.name == "system", nil
+}
+
+func (c *DefCryptoPolicy) checkName() error {
+	if c.name != "system" {
+		return fmt.Errorf("unknown crypto policy %q, the only one is system", c.name)
+	}
+	return nil
+}
+
+// Policy is the policy set with update-crypto-policies
+func (c *DefCryptoPolicy) Policy() (string, error) {
+	if err := c.checkName(); err != nil {
+		return "", err
+	}
+	data, err := ioutil.ReadFile(cryptoPolicyState)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("system crypto policies aren't in use, %s doesn't exist", cryptoPolicyState)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// FIPS reports whether the kernel is running in FIPS mode
+func (c *DefCryptoPolicy) FIPS() (bool, error) {
+	if err := c.checkName(); err != nil {
+		return false, err
+	}
+	data, err := ioutil.ReadFile(fipsEnabled)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(data)) == "1", nil
+}
+
+func (c *DefCryptoPolicy) setup() error {
+	if c.loaded {
+		return c.err
+	}
+	c.loaded = true
+	if c.err = c.checkName(); c.err != nil {
+		return c.err
+	}
+
+	// OpenSSL uses the same environment variable
+	path := os.Getenv("OPENSSL_CONF")
+	if path == "" {
+		for _, p := range opensslConfigs {
+			if _, err := os.Stat(p); err == nil {
+				path = p
+				break
+			}
+		}
+	}
+	if path == "" {
+		c.err = fmt.Errorf("no openssl configuration found in %s", strings.Join(opensslConfigs, ", "))
+		return c.err
+	}
+	c.systemDefault, c.err = opensslSystemDefault(path)
+	return c.err
+}
+
+// OpenSSLMinProtocol is the lowest TLS version OpenSSL allows by default, empty
+// when the configuration doesn't restrict it
+func (c *DefCryptoPolicy) OpenSSLMinProtocol() (string, error) {
+	if err := c.setup(); err != nil {
+		return "", err
+	}
+	if v, ok := c.systemDefault["TLS.MinProtocol"]; ok {
+		return v, nil
+	}
+	return c.systemDefault["MinProtocol"], nil
+}
+
+// OpenSSLCipherString is the default cipher list of OpenSSL for TLS 1.2 and
+// lower, empty when the configuration doesn't set it
+func (c *DefCryptoPolicy) OpenSSLCipherString() (string, error) {
+	if err := c.setup(); err != nil {
+		return "", err
+	}
+	return c.systemDefault["CipherString"], nil
+}
+
+// opensslSystemDefault returns the settings OpenSSL applies to every TLS
+// connection, found by following openssl_conf, ssl_conf and system_default
+func opensslSystemDefault(path string) (map[string]string, error) {
+	sections := make(map[string]map[string]string)
+	if err := parseOpenSSLConfig(path, "default", sections, 0); err != nil {
+		return nil, err
+	}
+	settings := map[string]string{}
+	name := sections["default"]["openssl_conf"]
+	for _, key := range []string{"ssl_conf", "system_default"} {
+		if name == "" {
+			return settings, nil
+		}
+		name = sections[name][key]
+	}
+	if s, ok := sections[name]; ok {
+		settings = s
+	}
+	return settings, nil
+}
+
+// parseOpenSSLConfig adds the settings of the config at path to sections,
+// starting in section. .include directives are followed, a section started in
+// an included file ends with it
+func parseOpenSSLConfig(path string, section string, sections map[string]map[string]string, depth int) error {
+	if depth > 10 {
+		return fmt.Errorf("%s: too many nested includes", path)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if fi.IsDir() {
+		matches, _ := filepath.Glob(filepath.Join(path, "*.cnf"))
+		more, _ := filepath.Glob(filepath.Join(path, "*.conf"))
+		matches = append(matches, more...)
+		sort.Strings(matches)
+		for _, m := range matches {
+			if err := parseOpenSSLConfig(m, section, sections, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i != -1 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "["):
+			section = strings.TrimSpace(strings.Trim(line, "[]"))
+		case strings.HasPrefix(line, ".include"):
+			inc := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(line, ".include")), "="))
+			if !filepath.IsAbs(inc) {
+				inc = filepath.Join(filepath.Dir(path), inc)
+			}
+			if err := parseOpenSSLConfig(inc, section, sections, depth+1); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "."):
+			// Other directives such as .pragma don't affect settings
+		default:
+			i := strings.IndexByte(line, '=')
+			if i == -1 {
+				continue
+			}
+			key, value := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+			value = strings.Trim(value, `"'`)
+			if sections[section] == nil {
+				sections[section] = make(map[string]string)
+			}
+			sections[section][key] = value
+		}
+	}
+	return scanner.Err()
+}
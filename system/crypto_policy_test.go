@@ -0,0 +1,62 @@
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOpenSSLSystemDefault(t *testing.T) {
+	root, err := ioutil.TempDir("", "goss-openssl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	for _, d := range []string{"policies", "dir.d"} {
+		if err := os.Mkdir(filepath.Join(root, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		// The layout of RHEL, the settings come from the crypto policy
+		"rhel.cnf": "HOME = .\nopenssl_conf = default_modules\n\n[ default_modules ]\nssl_conf = ssl_module\n\n" +
+			"[ ssl_module ]\nsystem_default = crypto_policy\n\n[ crypto_policy ]\n.include = policies/opensslcnf.config\n",
+		"policies/opensslcnf.config": "CipherString = @SECLEVEL=2:kEECDH:kRSA # policy\nTLS.MinProtocol = TLSv1.2\nDTLS.MinProtocol = DTLSv1.2\n",
+		// The layout of Debian
+		"debian.cnf": "openssl_conf = default_conf\n\n[default_conf]\nssl_conf = ssl_sect\n\n[ssl_sect]\n" +
+			"system_default = system_default_sect\n\n[system_default_sect]\nMinProtocol = TLSv1.2\nCipherString = \"DEFAULT@SECLEVEL=2\"\n",
+		"none.cnf": "[ req ]\ndefault_bits = 2048\n",
+		// Directories include their .cnf and .conf files
+		"dir.cnf":          "openssl_conf = init\n[init]\nssl_conf = ssl\n[ssl]\nsystem_default = tls\n[tls]\n.include dir.d\n",
+		"dir.d/10-min.cnf": "MinProtocol = TLSv1.3\n",
+		"dir.d/readme.txt": "MinProtocol = SSLv3\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tables := []struct {
+		config string
+		want   map[string]string
+	}{
+		{"rhel.cnf", map[string]string{"CipherString": "@SECLEVEL=2:kEECDH:kRSA", "TLS.MinProtocol": "TLSv1.2", "DTLS.MinProtocol": "DTLSv1.2"}},
+		{"debian.cnf", map[string]string{"MinProtocol": "TLSv1.2", "CipherString": "DEFAULT@SECLEVEL=2"}},
+		{"none.cnf", map[string]string{}},
+		{"dir.cnf", map[string]string{"MinProtocol": "TLSv1.3"}},
+	}
+
+	for _, table := range tables {
+		got, err := opensslSystemDefault(filepath.Join(root, table.config))
+		if err != nil {
+			t.Errorf("opensslSystemDefault (%s) failed: %v", table.config, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, table.want) {
+			t.Errorf("opensslSystemDefault (%s) was incorrect, got: %v, want: %v.", table.config, got, table.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+type Dir interface {
+	Path() string
+	Exists() (bool, error)
+	Entries() ([]string, error)
+}
+
+type DefDir struct {
+	path     string
+	realPath string
+	loaded   bool
+	err      error
+}
+
+func NewDefDir(path string, system *System, config util.Config) Dir {
+	var err error
+	if !strings.HasPrefix(path, "~") {
+		path, err = filepath.Abs(path)
+	}
+	return &DefDir{path: path, err: err}
+}
+
+func (d *DefDir) setup() error {
+	if d.loaded || d.err != nil {
+		return d.err
+	}
+	d.loaded = true
+	d.realPath, d.err = realPath(d.path)
+	return d.err
+}
+
+func (d *DefDir) Path() string {
+	return d.path
+}
+
+// Exists reports whether path is a directory, or a symlink to one
+func (d *DefDir) Exists() (bool, error) {
+	if err := d.setup(); err != nil {
+		return false, err
+	}
+	fi, err := os.Stat(d.realPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return fi.IsDir(), nil
+}
+
+// Entries returns the sorted names of the entries in the directory, including
+// hidden ones
+func (d *DefDir) Entries() ([]string, error) {
+	if err := d.setup(); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(d.realPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("%s: not a directory", d.path)
+	}
+	names, err := f.Readdirnames(-1)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -1,8 +1,13 @@
 package system
 
 import (
+	"bytes"
+	"crypto/tls"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net"
+	"net/http"
 	"regexp"
 	"sort"
 	"strconv"
@@ -20,6 +25,8 @@ type DNS interface {
 	Exists() (bool, error)
 	Server() string
 	Qtype() string
+	Records() ([]interface{}, error)
+	DNSSEC() (bool, error)
 }
 
 type DefDNS struct {
@@ -31,6 +38,17 @@ type DefDNS struct {
 	err        error
 	server     string
 	qtype      string
+	records    []interface{}
+	recLoaded  bool
+	recErr     error
+	dnssec     bool
+	secLoaded  bool
+	secErr     error
+	resolver   *Resolver
+	// ipVersion is the address family of the addresses of host lookups
+	ipVersion string
+	// netns is the network namespace the queries are sent from
+	netns string
 }
 
 func NewDefDNS(host string, system *System, config util.Config) DNS {
@@ -45,12 +63,20 @@ func NewDefDNS(host string, system *System, config util.Config) DNS {
 		h = host
 	}
 
-	return &DefDNS{
-		host:    h,
-		Timeout: config.TimeOutMilliSeconds(),
-		server:  config.Server,
-		qtype:   t,
+	d := &DefDNS{
+		host:      h,
+		Timeout:   config.TimeOutMilliSeconds(),
+		server:    config.Server,
+		qtype:     t,
+		ipVersion: ipVersion(config.IPVersion, system),
+		netns:     netnsName(config.Netns, system),
 	}
+	// The server of the check overrides the resolver of the run
+	if system != nil && system.Resolver != nil && d.server == "" {
+		d.resolver = system.Resolver
+		d.server = system.Resolver.Server
+	}
+	return d
 }
 
 func (d *DefDNS) Host() string {
@@ -71,8 +97,20 @@ func (d *DefDNS) setup() error {
 	}
 	d.loaded = true
 
+	if addrs, ok := d.resolver.static(d.host, d.qtype); ok {
+		d.addrs = append([]string{}, d.filter(addrs)...)
+		d.resolvable = len(d.addrs) > 0
+		sort.Strings(d.addrs)
+		return nil
+	}
+	server, err := d.nsServer()
+	if err != nil {
+		d.err = err
+		return d.err
+	}
 	for i := 0; i < 3; i++ {
-		addrs, err := DNSlookup(d.host, d.server, d.qtype, d.Timeout)
+		addrs, err := dnsLookup(d.host, server, d.qtype, d.Timeout, d.netns)
+		addrs = d.filter(addrs)
 		if err != nil || len(addrs) == 0 {
 			d.resolvable = false
 			d.addrs = []string{}
@@ -92,6 +130,23 @@ func (d *DefDNS) setup() error {
 	return d.err
 }
 
+// nsServer is the server of the queries, the nameserver of the network
+// namespace when they're sent from one and the check doesn't set a server
+func (d *DefDNS) nsServer() (string, error) {
+	if d.server != "" || d.netns == "" {
+		return d.server, nil
+	}
+	return netnsServer(d.netns)
+}
+
+// filter is the addresses of the ip version of host lookups
+func (d *DefDNS) filter(addrs []string) []string {
+	if d.qtype != "" || addrs == nil {
+		return addrs
+	}
+	return filterIPVersion(addrs, d.ipVersion)
+}
+
 func (d *DefDNS) Addrs() ([]string, error) {
 	err := d.setup()
 
@@ -104,12 +159,56 @@ func (d *DefDNS) Resolvable() (bool, error) {
 	return d.resolvable, err
 }
 
+// Records returns the structured fields of every record of the query type
+func (d *DefDNS) Records() ([]interface{}, error) {
+	if d.recLoaded {
+		return d.records, d.recErr
+	}
+	d.recLoaded = true
+	server, err := d.nsServer()
+	if err != nil {
+		d.recErr = err
+		return nil, err
+	}
+	d.recErr = inNetns(d.netns, func() (err error) {
+		d.records, err = DNSRecords(d.host, server, d.qtype, d.Timeout)
+		return err
+	})
+
+	return d.records, d.recErr
+}
+
+// DNSSEC returns whether the answer to the query is DNSSEC validated
+func (d *DefDNS) DNSSEC() (bool, error) {
+	if d.secLoaded {
+		return d.dnssec, d.secErr
+	}
+	d.secLoaded = true
+	server, err := d.nsServer()
+	if err != nil {
+		d.secErr = err
+		return false, err
+	}
+	d.secErr = inNetns(d.netns, func() (err error) {
+		d.dnssec, err = DNSSECValidate(d.host, server, d.qtype, d.Timeout)
+		return err
+	})
+
+	return d.dnssec, d.secErr
+}
+
 // Stub out
 func (d *DefDNS) Exists() (bool, error) {
 	return false, nil
 }
 
 func DNSlookup(host string, server string, qtype string, timeout int) ([]string, error) {
+	return dnsLookup(host, server, qtype, timeout, "")
+}
+
+// dnsLookup is DNSlookup from the network namespace netns, which needs a
+// server as the system resolver doesn't lookup from it
+func dnsLookup(host string, server string, qtype string, timeout int, netns string) ([]string, error) {
 	c1 := make(chan []string, 1)
 	e1 := make(chan error, 1)
 	timeoutD := time.Duration(timeout) * time.Millisecond
@@ -117,35 +216,43 @@ func DNSlookup(host string, server string, qtype string, timeout int) ([]string,
 	var addrs []string
 	var err error
 	go func() {
-		if server != "" {
-			c := new(dns.Client)
-			c.Timeout = timeoutD
-			m := new(dns.Msg)
-
-			switch qtype {
-			case "A":
-				addrs, err = LookupA(host, server, c, m)
-			case "AAAA":
-				addrs, err = LookupAAAA(host, server, c, m)
-			case "PTR":
-				addrs, err = LookupPTR(host, server, c, m)
-			case "CNAME":
-				addrs, err = LookupCNAME(host, server, c, m)
-			case "MX":
-				addrs, err = LookupMX(host, server, c, m)
-			case "NS":
-				addrs, err = LookupNS(host, server, c, m)
-			case "SRV":
-				addrs, err = LookupSRV(host, server, c, m)
-			case "TXT":
-				addrs, err = LookupTXT(host, server, c, m)
-			case "CAA":
-				addrs, err = LookupCAA(host, server, c, m)
-			default:
-				addrs, err = LookupHost(host, server, c, m)
+		nsErr := inNetns(netns, func() error {
+			if server != "" {
+				c := new(dns.Client)
+				c.Timeout = timeoutD
+				m := new(dns.Msg)
+
+				switch qtype {
+				case "A":
+					addrs, err = LookupA(host, server, c, m)
+				case "AAAA":
+					addrs, err = LookupAAAA(host, server, c, m)
+				case "PTR":
+					addrs, err = LookupPTR(host, server, c, m)
+				case "CNAME":
+					addrs, err = LookupCNAME(host, server, c, m)
+				case "MX":
+					addrs, err = LookupMX(host, server, c, m)
+				case "NS":
+					addrs, err = LookupNS(host, server, c, m)
+				case "SRV":
+					addrs, err = LookupSRV(host, server, c, m)
+				case "TXT":
+					addrs, err = LookupTXT(host, server, c, m)
+				case "CAA":
+					addrs, err = LookupCAA(host, server, c, m)
+				case "SOA":
+					addrs, err = LookupSOA(host, server, c, m)
+				default:
+					addrs, err = LookupHost(host, server, c, m)
+				}
+			} else {
+				addrs, err = net.LookupHost(host)
 			}
-		} else {
-			addrs, err = net.LookupHost(host)
+			return nil
+		})
+		if nsErr != nil {
+			err = nsErr
 		}
 		if err != nil {
 			e1 <- err
@@ -174,7 +281,7 @@ func LookupHost(host string, server string, c *dns.Client, m *dns.Msg) (addrs []
 // A record lookup
 func LookupA(host string, server string, c *dns.Client, m *dns.Msg) (addrs []string, err error) {
 	m.SetQuestion(dns.Fqdn(host), dns.TypeA)
-	r, _, err := c.Exchange(m, parseServerString(server))
+	r, err := exchange(c, m, server)
 	if err != nil {
 		return nil, err
 	}
@@ -190,18 +297,83 @@ func LookupA(host string, server string, c *dns.Client, m *dns.Msg) (addrs []str
 
 // parseServerString - Check if the DNS Server in server config has a port, if not ensure 53 is prefixed.
 func parseServerString(server string) string {
+	return serverWithPort(server, "53")
+}
+
+func serverWithPort(server string, port string) string {
 	srvhost, srvport, err := net.SplitHostPort(server)
 	if err != nil {
-		srvport = "53"
-		srvhost = server
+		srvport = port
+		srvhost = strings.Trim(server, "[]")
 	}
 	return net.JoinHostPort(srvhost, srvport)
 }
 
+// exchange sends m to server, which is a host[:port] for plain DNS,
+// tcp://host[:port] for DNS over TCP, tls://host[:port] for DNS over TLS or
+// an https:// URL for DNS over HTTPS
+func exchange(c *dns.Client, m *dns.Msg, server string) (*dns.Msg, error) {
+	switch {
+	case strings.HasPrefix(server, "https://"):
+		return exchangeHTTPS(c, m, server)
+	case strings.HasPrefix(server, "tls://"):
+		addr := serverWithPort(strings.TrimPrefix(server, "tls://"), "853")
+		host, _, _ := net.SplitHostPort(addr)
+		tc := &dns.Client{Net: "tcp-tls", Timeout: c.Timeout, TLSConfig: &tls.Config{ServerName: host}}
+		r, _, err := tc.Exchange(m, addr)
+		return r, err
+	case strings.HasPrefix(server, "tcp://"):
+		tc := &dns.Client{Net: "tcp", Timeout: c.Timeout}
+		r, _, err := tc.Exchange(m, parseServerString(strings.TrimPrefix(server, "tcp://")))
+		return r, err
+	case strings.HasPrefix(server, "udp://"):
+		server = strings.TrimPrefix(server, "udp://")
+	}
+	r, _, err := c.Exchange(m, parseServerString(server))
+	return r, err
+}
+
+// exchangeHTTPS sends m to a DNS over HTTPS endpoint as described in RFC 8484
+func exchangeHTTPS(c *dns.Client, m *dns.Msg, url string) (*dns.Msg, error) {
+	q := m.Copy()
+	// A zero ID makes responses cache friendly, RFC 8484 section 4.1
+	q.Id = 0
+	body, err := q.Pack()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+
+	client := &http.Client{Timeout: c.Timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DNS over HTTPS query to %s failed: %s", url, resp.Status)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, dns.MaxMsgSize))
+	if err != nil {
+		return nil, err
+	}
+	r := new(dns.Msg)
+	if err := r.Unpack(data); err != nil {
+		return nil, err
+	}
+	r.Id = m.Id
+	return r, nil
+}
+
 // AAAA (IPv6) record lookup
 func LookupAAAA(host string, server string, c *dns.Client, m *dns.Msg) (addrs []string, err error) {
 	m.SetQuestion(dns.Fqdn(host), dns.TypeAAAA)
-	r, _, err := c.Exchange(m, parseServerString(server))
+	r, err := exchange(c, m, server)
 	if err != nil {
 		return nil, err
 	}
@@ -218,7 +390,7 @@ func LookupAAAA(host string, server string, c *dns.Client, m *dns.Msg) (addrs []
 // CNAME record lookup
 func LookupCNAME(host string, server string, c *dns.Client, m *dns.Msg) (addrs []string, err error) {
 	m.SetQuestion(dns.Fqdn(host), dns.TypeCNAME)
-	r, _, err := c.Exchange(m, parseServerString(server))
+	r, err := exchange(c, m, server)
 	if err != nil {
 		return nil, err
 	}
@@ -235,7 +407,7 @@ func LookupCNAME(host string, server string, c *dns.Client, m *dns.Msg) (addrs [
 // MX record lookup
 func LookupMX(host string, server string, c *dns.Client, m *dns.Msg) (addrs []string, err error) {
 	m.SetQuestion(dns.Fqdn(host), dns.TypeMX)
-	r, _, err := c.Exchange(m, parseServerString(server))
+	r, err := exchange(c, m, server)
 	if err != nil {
 		return nil, err
 	}
@@ -253,7 +425,7 @@ func LookupMX(host string, server string, c *dns.Client, m *dns.Msg) (addrs []st
 // NS record lookup
 func LookupNS(host string, server string, c *dns.Client, m *dns.Msg) (addrs []string, err error) {
 	m.SetQuestion(dns.Fqdn(host), dns.TypeNS)
-	r, _, err := c.Exchange(m, parseServerString(server))
+	r, err := exchange(c, m, server)
 	if err != nil {
 		return nil, err
 	}
@@ -270,7 +442,7 @@ func LookupNS(host string, server string, c *dns.Client, m *dns.Msg) (addrs []st
 // SRV record lookup
 func LookupSRV(host string, server string, c *dns.Client, m *dns.Msg) (addrs []string, err error) {
 	m.SetQuestion(dns.Fqdn(host), dns.TypeSRV)
-	r, _, err := c.Exchange(m, parseServerString(server))
+	r, err := exchange(c, m, server)
 	if err != nil {
 		return nil, err
 	}
@@ -291,7 +463,7 @@ func LookupSRV(host string, server string, c *dns.Client, m *dns.Msg) (addrs []s
 // TXT record lookup
 func LookupTXT(host string, server string, c *dns.Client, m *dns.Msg) (addrs []string, err error) {
 	m.SetQuestion(dns.Fqdn(host), dns.TypeTXT)
-	r, _, err := c.Exchange(m, parseServerString(server))
+	r, err := exchange(c, m, server)
 	if err != nil {
 		return nil, err
 	}
@@ -315,7 +487,7 @@ func LookupPTR(addr string, server string, c *dns.Client, m *dns.Msg) (name []st
 
 	m.SetQuestion(reverse, dns.TypePTR)
 
-	r, _, err := c.Exchange(m, parseServerString(server))
+	r, err := exchange(c, m, server)
 	if err != nil {
 		return nil, err
 	}
@@ -330,7 +502,7 @@ func LookupPTR(addr string, server string, c *dns.Client, m *dns.Msg) (name []st
 // CAA record lookup
 func LookupCAA(host string, server string, c *dns.Client, m *dns.Msg) (addrs []string, err error) {
 	m.SetQuestion(dns.Fqdn(host), dns.TypeCAA)
-	r, _, err := c.Exchange(m, parseServerString(server))
+	r, err := exchange(c, m, server)
 	if err != nil {
 		return nil, err
 	}
@@ -345,3 +517,228 @@ func LookupCAA(host string, server string, c *dns.Client, m *dns.Msg) (addrs []s
 
 	return
 }
+
+// SOA record lookup
+func LookupSOA(host string, server string, c *dns.Client, m *dns.Msg) (addrs []string, err error) {
+	m.SetQuestion(dns.Fqdn(host), dns.TypeSOA)
+	r, err := exchange(c, m, server)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ans := range r.Answer {
+		if t, ok := ans.(*dns.SOA); ok {
+			soarec := strings.Join([]string{t.Ns, t.Mbox, strconv.Itoa(int(t.Serial)), strconv.Itoa(int(t.Refresh)),
+				strconv.Itoa(int(t.Retry)), strconv.Itoa(int(t.Expire)), strconv.Itoa(int(t.Minttl))}, " ")
+			addrs = append(addrs, soarec)
+		}
+	}
+
+	return
+}
+
+var recordTypes = map[string]uint16{
+	"MX":  dns.TypeMX,
+	"SRV": dns.TypeSRV,
+	"CAA": dns.TypeCAA,
+	"SOA": dns.TypeSOA,
+	"TXT": dns.TypeTXT,
+}
+
+// defaultServer returns server, or the first nameserver in resolv.conf when it's empty
+func defaultServer(server string) (string, error) {
+	if server != "" {
+		return server, nil
+	}
+	return resolvConfServer("/etc/resolv.conf")
+}
+
+// DNSRecords looks up the records of qtype returning the fields of each record
+// as a map, when server is empty the first nameserver in resolv.conf is used
+func DNSRecords(host string, server string, qtype string, timeout int) ([]interface{}, error) {
+	rrType, ok := recordTypes[qtype]
+	if !ok {
+		return nil, fmt.Errorf("records are only supported for MX, SRV, CAA, SOA and TXT queries, got: %q", qtype)
+	}
+	server, err := defaultServer(server)
+	if err != nil {
+		return nil, err
+	}
+
+	c := new(dns.Client)
+	c.Timeout = time.Duration(timeout) * time.Millisecond
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(host), rrType)
+	r, err := exchange(c, m, server)
+	if err != nil {
+		return nil, err
+	}
+
+	records := []interface{}{}
+	for _, ans := range r.Answer {
+		if ans.Header().Rrtype != rrType {
+			continue
+		}
+		records = append(records, recordToMap(ans))
+	}
+
+	return records, nil
+}
+
+// recordToMap converts the fields of a record into a map usable by matchers
+func recordToMap(rr dns.RR) map[string]interface{} {
+	rec := map[string]interface{}{
+		"ttl": int(rr.Header().Ttl),
+	}
+	switch t := rr.(type) {
+	case *dns.MX:
+		rec["preference"] = int(t.Preference)
+		rec["mx"] = t.Mx
+	case *dns.SRV:
+		rec["priority"] = int(t.Priority)
+		rec["weight"] = int(t.Weight)
+		rec["port"] = int(t.Port)
+		rec["target"] = t.Target
+	case *dns.CAA:
+		rec["flag"] = int(t.Flag)
+		rec["tag"] = t.Tag
+		rec["value"] = t.Value
+	case *dns.SOA:
+		rec["ns"] = t.Ns
+		rec["mbox"] = t.Mbox
+		rec["serial"] = int(t.Serial)
+		rec["refresh"] = int(t.Refresh)
+		rec["retry"] = int(t.Retry)
+		rec["expire"] = int(t.Expire)
+		rec["minttl"] = int(t.Minttl)
+	case *dns.TXT:
+		rec["txt"] = strings.Join(t.Txt, "")
+	}
+	return rec
+}
+
+// DNSSECValidate queries host with the DNSSEC OK bit set and reports whether
+// the answer is validated. The resolver must set the authenticated data flag
+// and every RRset of qtype in the answer must carry a current RRSIG that
+// verifies against a DNSKEY of its signer, when server is empty the first
+// nameserver in resolv.conf is used
+func DNSSECValidate(host string, server string, qtype string, timeout int) (bool, error) {
+	rrType := dns.TypeA
+	if qtype != "" {
+		t, ok := dns.StringToType[qtype]
+		if !ok {
+			return false, fmt.Errorf("unknown query type: %q", qtype)
+		}
+		rrType = t
+	}
+	name := dns.Fqdn(host)
+	if rrType == dns.TypePTR {
+		reverse, err := dns.ReverseAddr(host)
+		if err != nil {
+			return false, err
+		}
+		name = reverse
+	}
+	server, err := defaultServer(server)
+	if err != nil {
+		return false, err
+	}
+
+	c := &dns.Client{Timeout: time.Duration(timeout) * time.Millisecond}
+	r, err := dnssecQuery(c, server, name, rrType)
+	if err != nil {
+		return false, err
+	}
+	if !r.AuthenticatedData {
+		return false, nil
+	}
+
+	keys := make(map[string][]*dns.DNSKEY)
+	for _, ans := range r.Answer {
+		sig, ok := ans.(*dns.RRSIG)
+		if !ok || sig.TypeCovered != rrType {
+			continue
+		}
+		signer := strings.ToLower(sig.SignerName)
+		if _, ok := keys[signer]; ok {
+			continue
+		}
+		kr, err := dnssecQuery(c, server, signer, dns.TypeDNSKEY)
+		if err != nil {
+			return false, err
+		}
+		for _, k := range kr.Answer {
+			if key, ok := k.(*dns.DNSKEY); ok {
+				keys[signer] = append(keys[signer], key)
+			}
+		}
+	}
+
+	return verifyRRSIGs(r.Answer, rrType, keys, time.Now()), nil
+}
+
+// dnssecQuery sends a query with the DNSSEC OK bit set, retrying over TCP when
+// a plain DNS answer is truncated
+func dnssecQuery(c *dns.Client, server string, name string, rrType uint16) (*dns.Msg, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(name, rrType)
+	m.SetEdns0(4096, true)
+	r, err := exchange(c, m, server)
+	if err != nil {
+		return nil, err
+	}
+	if r.Truncated && !strings.Contains(server, "://") {
+		r, err = exchange(c, m, "tcp://"+server)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if r.Rcode != dns.RcodeSuccess {
+		return nil, fmt.Errorf("DNSSEC query for %s %s failed: %s", dns.TypeToString[rrType], name, dns.RcodeToString[r.Rcode])
+	}
+	return r, nil
+}
+
+// verifyRRSIGs reports whether every RRset of rrType in answer has an RRSIG
+// valid at now that verifies with one of keys, keyed by lower case signer name
+func verifyRRSIGs(answer []dns.RR, rrType uint16, keys map[string][]*dns.DNSKEY, now time.Time) bool {
+	rrsets := make(map[string][]dns.RR)
+	var sigs []*dns.RRSIG
+	for _, ans := range answer {
+		if sig, ok := ans.(*dns.RRSIG); ok {
+			if sig.TypeCovered == rrType {
+				sigs = append(sigs, sig)
+			}
+			continue
+		}
+		if ans.Header().Rrtype == rrType {
+			owner := strings.ToLower(ans.Header().Name)
+			rrsets[owner] = append(rrsets[owner], ans)
+		}
+	}
+	if len(rrsets) == 0 {
+		return false
+	}
+
+	for owner, rrset := range rrsets {
+		verified := false
+		for _, sig := range sigs {
+			if strings.ToLower(sig.Hdr.Name) != owner || !sig.ValidityPeriod(now) {
+				continue
+			}
+			for _, key := range keys[strings.ToLower(sig.SignerName)] {
+				if key.KeyTag() == sig.KeyTag && sig.Verify(key, rrset) == nil {
+					verified = true
+					break
+				}
+			}
+			if verified {
+				break
+			}
+		}
+		if !verified {
+			return false
+		}
+	}
+	return true
+}
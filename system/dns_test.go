@@ -1,7 +1,16 @@
 package system
 
 import (
+	"crypto"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/miekg/dns"
 )
 
 func TestParseServerString(t *testing.T) {
@@ -14,6 +23,7 @@ func TestParseServerString(t *testing.T) {
 		{"127.0.0.1:53", "127.0.0.1:53"},
 		{"127.0.0.1:8600", "127.0.0.1:8600"},
 		{"1.1.1.1:53", "1.1.1.1:53"},
+		{"[::1]", "[::1]:53"},
 	}
 
 	for _, table := range tables {
@@ -23,3 +33,105 @@ func TestParseServerString(t *testing.T) {
 		}
 	}
 }
+
+func TestRecordToMap(t *testing.T) {
+	rr, err := dns.NewRR("_https._tcp.dnstest.io. 300 IN SRV 10 5 443 a.dnstest.io.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"ttl": 300, "priority": 10, "weight": 5, "port": 443, "target": "a.dnstest.io."}
+	if got := recordToMap(rr); !reflect.DeepEqual(got, want) {
+		t.Errorf("recordToMap was incorrect, got: %v, want: %v.", got, want)
+	}
+
+	rr, err = dns.NewRR("dnstest.io. 60 IN SOA ns1.dnstest.io. admin.dnstest.io. 2020010101 7200 3600 1209600 300")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = map[string]interface{}{"ttl": 60, "ns": "ns1.dnstest.io.", "mbox": "admin.dnstest.io.", "serial": 2020010101,
+		"refresh": 7200, "retry": 3600, "expire": 1209600, "minttl": 300}
+	if got := recordToMap(rr); !reflect.DeepEqual(got, want) {
+		t.Errorf("recordToMap was incorrect, got: %v, want: %v.", got, want)
+	}
+}
+
+func TestExchangeHTTPS(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/dns-message" {
+			http.Error(w, "bad content type", http.StatusUnsupportedMediaType)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		q := new(dns.Msg)
+		if err := q.Unpack(body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp := new(dns.Msg)
+		resp.SetReply(q)
+		resp.Answer = append(resp.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: q.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+			A:   net.ParseIP("192.0.2.1"),
+		})
+		out, _ := resp.Pack()
+		w.Header().Set("Content-Type", "application/dns-message")
+		w.Write(out)
+	}))
+	defer ts.Close()
+
+	c := &dns.Client{Timeout: time.Second}
+	m := new(dns.Msg)
+	m.SetQuestion("dnstest.io.", dns.TypeA)
+	r, err := exchangeHTTPS(c, m, ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Id != m.Id {
+		t.Errorf("exchangeHTTPS should restore the query id, got: %d, want: %d.", r.Id, m.Id)
+	}
+	if len(r.Answer) != 1 || r.Answer[0].(*dns.A).A.String() != "192.0.2.1" {
+		t.Errorf("exchangeHTTPS was incorrect, got: %v", r.Answer)
+	}
+}
+
+func TestVerifyRRSIGs(t *testing.T) {
+	key := &dns.DNSKEY{
+		Hdr:       dns.RR_Header{Name: "dnstest.io.", Rrtype: dns.TypeDNSKEY, Class: dns.ClassINET, Ttl: 3600},
+		Flags:     257,
+		Protocol:  3,
+		Algorithm: dns.ECDSAP256SHA256,
+	}
+	priv, err := key.Generate(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, _ := dns.NewRR("www.dnstest.io. 300 IN A 192.0.2.1")
+	now := time.Now()
+	sig := &dns.RRSIG{
+		Hdr:        dns.RR_Header{Name: "www.dnstest.io.", Rrtype: dns.TypeRRSIG, Class: dns.ClassINET, Ttl: 300},
+		KeyTag:     key.KeyTag(),
+		SignerName: "dnstest.io.",
+		Algorithm:  key.Algorithm,
+		Inception:  uint32(now.Add(-time.Hour).Unix()),
+		Expiration: uint32(now.Add(time.Hour).Unix()),
+	}
+	if err := sig.Sign(priv.(crypto.Signer), []dns.RR{a}); err != nil {
+		t.Fatal(err)
+	}
+	keys := map[string][]*dns.DNSKEY{"dnstest.io.": {key}}
+
+	if !verifyRRSIGs([]dns.RR{a, sig}, dns.TypeA, keys, now) {
+		t.Error("verifyRRSIGs should verify a correctly signed RRset")
+	}
+	if verifyRRSIGs([]dns.RR{a}, dns.TypeA, keys, now) {
+		t.Error("verifyRRSIGs should fail an unsigned RRset")
+	}
+	if verifyRRSIGs([]dns.RR{a, sig}, dns.TypeA, keys, now.Add(2*time.Hour)) {
+		t.Error("verifyRRSIGs should fail an expired signature")
+	}
+	forged, _ := dns.NewRR("www.dnstest.io. 300 IN A 192.0.2.66")
+	if verifyRRSIGs([]dns.RR{forged, sig}, dns.TypeA, keys, now) {
+		t.Error("verifyRRSIGs should fail a tampered RRset")
+	}
+}
@@ -0,0 +1,154 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// Entropy is the state of the kernel random number generator, the only name
+// is "system"
+type Entropy interface {
+	Name() string
+	Exists() (bool, error)
+	Available() (int, error)
+	PoolSize() (int, error)
+	HWRNG() (bool, error)
+	HWRNGSource() (string, error)
+	Jitterentropy() (bool, error)
+	Daemons() ([]string, error)
+}
+
+type DefEntropy struct {
+	name   string
+	system *System
+}
+
+var (
+	randomDir   = "/proc/sys/kernel/random"
+	hwrngDevice = "/dev/hwrng"
+	hwrngDir    = "/sys/class/misc/hw_random"
+	procCrypto  = "/proc/crypto"
+)
+
+// entropyDaemons feed the kernel random number generator from other sources
+var entropyDaemons = []string{"rngd", "jitterentropy-rngd", "haveged"}
+
+func NewDefEntropy(name string, system *System, config util.Config) Entropy {
+	return &DefEntropy{name: name, system: system}
+}
+
+func (e *DefEntropy) Name() string {
+	return e.name
+}
+
+func (e *DefEntropy) Exists() (bool, error) {
+	return e.name == "system", nil
+}
+
+func (e *DefEntropy) checkName() error {
+	if e.name != "system" {
+		return fmt.Errorf("unknown entropy %q, the only one is system", e.name)
+	}
+	return nil
+}
+
+// Available is the entropy the kernel estimates it has in bits
+func (e *DefEntropy) Available() (int, error) {
+	if err := e.checkName(); err != nil {
+		return 0, err
+	}
+	return readRandomInt("entropy_avail")
+}
+
+func (e *DefEntropy) PoolSize() (int, error) {
+	if err := e.checkName(); err != nil {
+		return 0, err
+	}
+	return readRandomInt("poolsize")
+}
+
+func readRandomInt(name string) (int, error) {
+	data, err := ioutil.ReadFile(randomDir + "/" + name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// HWRNG reports whether a hardware random number generator is available
+func (e *DefEntropy) HWRNG() (bool, error) {
+	if err := e.checkName(); err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(hwrngDevice); err != nil {
+		return false, nil
+	}
+	source, err := e.HWRNGSource()
+	return err == nil && source != "none", nil
+}
+
+// HWRNGSource is the driver of the hardware random number generator in use,
+// "none" without one
+func (e *DefEntropy) HWRNGSource() (string, error) {
+	if err := e.checkName(); err != nil {
+		return "", err
+	}
+	data, err := ioutil.ReadFile(hwrngDir + "/rng_current")
+	if os.IsNotExist(err) {
+		return "none", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Jitterentropy reports whether the kernel has the CPU jitter random number
+// generator
+func (e *DefEntropy) Jitterentropy() (bool, error) {
+	if err := e.checkName(); err != nil {
+		return false, err
+	}
+	f, err := os.Open(procCrypto)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 3 && fields[0] == "driver" && fields[2] == "jitterentropy_rng" {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// Daemons are the running entropy daemons, such as rngd
+func (e *DefEntropy) Daemons() ([]string, error) {
+	if err := e.checkName(); err != nil {
+		return nil, err
+	}
+	pmap, err := e.system.ProcMap()
+	if err != nil {
+		return nil, err
+	}
+	daemons := []string{}
+	for _, d := range entropyDaemons {
+		// Processes are known by the name the kernel keeps, cut to 15 characters
+		comm := d
+		if len(comm) > 15 {
+			comm = comm[:15]
+		}
+		if len(pmap[comm]) > 0 {
+			daemons = append(daemons, d)
+		}
+	}
+	return daemons, nil
+}
@@ -0,0 +1,76 @@
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+func TestEntropy(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	defer func(random, device, hwrng, crypto string) {
+		randomDir, hwrngDevice, hwrngDir, procCrypto = random, device, hwrng, crypto
+	}(randomDir, hwrngDevice, hwrngDir, procCrypto)
+	randomDir = filepath.Dir(write("random/entropy_avail", "256\n"))
+	write("random/poolsize", "4096\n")
+	hwrngDevice = write("hwrng", "")
+	hwrngDir = filepath.Dir(write("hw_random/rng_current", "tpm-rng-0\n"))
+	procCrypto = write("crypto", "name         : jitterentropy_rng\ndriver       : jitterentropy_rng\nmodule       : kernel\n")
+
+	sys := &System{}
+	sys.procOnce.Do(func() {
+		sys.procMap = map[string][]Proc{"rngd": {psProc{pid: 10, executable: "rngd"}}, "jitterentropy-r": {psProc{pid: 11}}}
+	})
+	e := NewDefEntropy("system", sys, util.Config{})
+	if v, err := e.Available(); err != nil || v != 256 {
+		t.Errorf("available: got %d, %v", v, err)
+	}
+	if v, err := e.PoolSize(); err != nil || v != 4096 {
+		t.Errorf("pool size: got %d, %v", v, err)
+	}
+	if v, err := e.HWRNG(); err != nil || !v {
+		t.Errorf("hwrng: got %v, %v", v, err)
+	}
+	if v, err := e.HWRNGSource(); err != nil || v != "tpm-rng-0" {
+		t.Errorf("hwrng source: got %q, %v", v, err)
+	}
+	if v, err := e.Jitterentropy(); err != nil || !v {
+		t.Errorf("jitterentropy: got %v, %v", v, err)
+	}
+	// jitterentropy-rngd is known by its name cut to 15 characters
+	if v, err := e.Daemons(); err != nil || !reflect.DeepEqual(v, []string{"rngd", "jitterentropy-rngd"}) {
+		t.Errorf("daemons: got %v, %v", v, err)
+	}
+
+	// Without a hardware random number generator
+	os.Remove(hwrngDevice)
+	os.RemoveAll(hwrngDir)
+	write("crypto", "driver       : sha256-generic\n")
+	if v, err := e.HWRNG(); err != nil || v {
+		t.Errorf("hwrng without a device: got %v, %v", v, err)
+	}
+	if v, err := e.HWRNGSource(); err != nil || v != "none" {
+		t.Errorf("hwrng source without a device: got %q, %v", v, err)
+	}
+	if v, err := e.Jitterentropy(); err != nil || v {
+		t.Errorf("jitterentropy without the driver: got %v, %v", v, err)
+	}
+
+	if _, err := NewDefEntropy("other", sys, util.Config{}).Available(); err == nil {
+		t.Error("entropy other than system: got no error")
+	}
+}
@@ -3,12 +3,16 @@ package system
 import (
 	"crypto/md5"
 	"crypto/sha256"
+	"crypto/sha512"
 	"fmt"
+	"hash"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aelsabbahy/goss/util"
 	"github.com/opencontainers/runc/libcontainer/user"
@@ -20,18 +24,24 @@ type File interface {
 	Contains() (io.Reader, error)
 	Mode() (string, error)
 	Size() (int, error)
+	Blocks() (int, error)
+	Allocated() (int, error)
 	Filetype() (string, error)
 	Owner() (string, error)
 	Group() (string, error)
 	LinkedTo() (string, error)
+	Attributes() ([]string, error)
+	Walk(exclude []string) ([]File, error)
 	Md5() (string, error)
 	Sha256() (string, error)
+	Sha512() (string, error)
 }
 
 type DefFile struct {
 	path     string
 	realPath string
 	fi       os.FileInfo
+	follow   bool
 	loaded   bool
 	err      error
 }
@@ -41,7 +51,7 @@ func NewDefFile(path string, system *System, config util.Config) File {
 	if !strings.HasPrefix(path, "~") {
 		path, err = filepath.Abs(path)
 	}
-	return &DefFile{path: path, err: err}
+	return &DefFile{path: path, follow: config.FollowSymlinks, err: err}
 }
 
 func (f *DefFile) setup() error {
@@ -60,12 +70,20 @@ func (f *DefFile) Path() string {
 	return f.path
 }
 
+// stat describes the file, or the target of a symlink when following symlinks
+func (f *DefFile) stat() (os.FileInfo, error) {
+	if f.follow {
+		return os.Stat(f.realPath)
+	}
+	return os.Lstat(f.realPath)
+}
+
 func (f *DefFile) Exists() (bool, error) {
 	if err := f.setup(); err != nil {
 		return false, err
 	}
 
-	_, err := os.Lstat(f.realPath)
+	_, err := f.stat()
 	if os.IsNotExist(err) {
 		return false, nil
 	}
@@ -89,7 +107,7 @@ func (f *DefFile) Size() (int, error) {
 		return 0, err
 	}
 
-	fi, err := os.Lstat(f.realPath)
+	fi, err := f.stat()
 	if err != nil {
 		return 0, err
 	}
@@ -103,7 +121,7 @@ func (f *DefFile) Filetype() (string, error) {
 		return "", err
 	}
 
-	fi, err := os.Lstat(f.realPath)
+	fi, err := f.stat()
 	if err != nil {
 		return "", err
 	}
@@ -129,11 +147,23 @@ func (f *DefFile) Filetype() (string, error) {
 	return "file", nil
 }
 
+// LinkedTo is the target of a symlink, or the path it finally resolves to, after
+// following every symlink, when following symlinks
 func (f *DefFile) LinkedTo() (string, error) {
 	if err := f.setup(); err != nil {
 		return "", err
 	}
 
+	if f.follow {
+		fi, err := os.Lstat(f.realPath)
+		if err != nil {
+			return "", err
+		}
+		if fi.Mode()&os.ModeSymlink == 0 {
+			return "", fmt.Errorf("%s: not a symlink", f.path)
+		}
+		return filepath.EvalSymlinks(f.realPath)
+	}
 	dst, err := os.Readlink(f.realPath)
 	if err != nil {
 		return "", err
@@ -141,6 +171,79 @@ func (f *DefFile) LinkedTo() (string, error) {
 	return dst, nil
 }
 
+// Attributes are the inode attributes set with chattr, such as immutable and append-only
+func (f *DefFile) Attributes() ([]string, error) {
+	if err := f.setup(); err != nil {
+		return nil, err
+	}
+
+	return fileAttributes(f.realPath)
+}
+
+// Walk returns the file and every entry under it without following symlinks,
+// other than the file itself when following symlinks.
+// Exclude patterns are matched against the path relative to the file, or the
+// base name for patterns without a /, and leave out matching entries and
+// everything under them. A pattern ending in / only matches directories and
+// leaves out just the directory, not its contents, the file itself is "."
+func (f *DefFile) Walk(exclude []string) ([]File, error) {
+	if err := f.setup(); err != nil {
+		return nil, err
+	}
+	for _, pattern := range exclude {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern: %q", pattern)
+		}
+	}
+
+	root := f.realPath
+	if f.follow {
+		var err error
+		if root, err = filepath.EvalSymlinks(root); err != nil {
+			return nil, err
+		}
+	}
+	var files []File
+	err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		excludeDir := false
+		for _, pattern := range exclude {
+			dirOnly := strings.HasSuffix(pattern, "/")
+			pattern = strings.TrimSuffix(pattern, "/")
+			if dirOnly && !fi.IsDir() {
+				continue
+			}
+			name := rel
+			if !strings.Contains(pattern, "/") {
+				name = filepath.Base(rel)
+			}
+			if ok, _ := filepath.Match(pattern, name); !ok {
+				continue
+			}
+			if dirOnly {
+				excludeDir = true
+				continue
+			}
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !excludeDir {
+			files = append(files, &DefFile{path: path, realPath: path, loaded: true})
+		}
+		return nil
+	})
+	return files, err
+}
+
 func realPath(path string) (string, error) {
 	if !strings.HasPrefix(path, "~") {
 		return path, nil
@@ -167,7 +270,19 @@ func realPath(path string) (string, error) {
 }
 
 func (f *DefFile) Md5() (string, error) {
+	return f.checksum(md5.New())
+}
+
+func (f *DefFile) Sha256() (string, error) {
+	return f.checksum(sha256.New())
+}
 
+func (f *DefFile) Sha512() (string, error) {
+	return f.checksum(sha512.New())
+}
+
+// checksum streams the file through h so large files aren't read into memory
+func (f *DefFile) checksum(h hash.Hash) (string, error) {
 	if err := f.setup(); err != nil {
 		return "", err
 	}
@@ -178,28 +293,38 @@ func (f *DefFile) Md5() (string, error) {
 	}
 	defer fh.Close()
 
-	hash := md5.New()
-	if _, err := io.Copy(hash, fh); err != nil {
+	if _, err := io.Copy(h, fh); err != nil {
 		return "", err
 	}
 
-	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
 }
 
-func (f *DefFile) Sha256() (string, error) {
-
-	if err := f.setup(); err != nil {
-		return "", err
-	}
-
-	fh, err := os.Open(f.realPath)
-	if err != nil {
-		return "", err
+// SourceSha256 returns the sha256 checksum of a local file or an http(s) URL
+func SourceSha256(source string, timeout time.Duration) (string, error) {
+	var r io.Reader
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		client := &http.Client{Timeout: timeout}
+		resp, err := client.Get(source)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("could not fetch %s: %s", source, resp.Status)
+		}
+		r = resp.Body
+	} else {
+		fh, err := os.Open(source)
+		if err != nil {
+			return "", err
+		}
+		defer fh.Close()
+		r = fh
 	}
-	defer fh.Close()
 
 	hash := sha256.New()
-	if _, err := io.Copy(hash, fh); err != nil {
+	if _, err := io.Copy(hash, r); err != nil {
 		return "", err
 	}
 
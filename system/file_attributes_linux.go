@@ -0,0 +1,85 @@
+// +build linux
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"syscall"
+	"unsafe"
+)
+
+// fileAttributeFlags maps the inode flags reported by lsattr to attribute names
+var fileAttributeFlags = map[uint32]string{
+	0x00000001: "secure-delete",
+	0x00000002: "undelete",
+	0x00000004: "compress",
+	0x00000008: "sync",
+	0x00000010: "immutable",
+	0x00000020: "append-only",
+	0x00000040: "nodump",
+	0x00000080: "noatime",
+	0x00000400: "nocompress",
+	0x00000800: "encrypted",
+	0x00001000: "indexed",
+	0x00004000: "journal-data",
+	0x00008000: "notail",
+	0x00010000: "dirsync",
+	0x00020000: "topdir",
+	0x00080000: "extents",
+	0x00100000: "verity",
+	0x00800000: "nocow",
+	0x02000000: "dax",
+	0x10000000: "inline-data",
+	0x20000000: "project",
+	0x40000000: "casefold",
+}
+
+// fsIocGetflags is FS_IOC_GETFLAGS, _IOR('f', 1, long), the direction bits differ
+// between architectures
+func fsIocGetflags() uintptr {
+	size := unsafe.Sizeof(uintptr(0))
+	switch runtime.GOARCH {
+	case "mips", "mipsle", "mips64", "mips64le", "ppc64", "ppc64le", "sparc64":
+		return 2<<29 | size<<16 | 'f'<<8 | 1
+	}
+	return 2<<30 | size<<16 | 'f'<<8 | 1
+}
+
+// fileAttributes returns the sorted names of the file's inode attributes, only
+// files and directories are opened, like lsattr does, so devices and fifos
+// aren't affected
+func fileAttributes(path string) ([]string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.Mode().IsRegular() && !fi.IsDir() {
+		return nil, fmt.Errorf("%s: attributes are only supported for files and directories", path)
+	}
+	fd, err := syscall.Open(path, syscall.O_RDONLY|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+	defer syscall.Close(fd)
+
+	// The kernel reads and writes an int despite the ioctl being defined with a long
+	var flags uint32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), fsIocGetflags(), uintptr(unsafe.Pointer(&flags))); errno != 0 {
+		if errno == syscall.ENOTTY || errno == syscall.EOPNOTSUPP || errno == syscall.EINVAL {
+			return nil, fmt.Errorf("%s: the file system doesn't support attributes", path)
+		}
+		return nil, &os.PathError{Op: "get attributes", Path: path, Err: errno}
+	}
+
+	attrs := []string{}
+	for flag, name := range fileAttributeFlags {
+		if flags&flag != 0 {
+			attrs = append(attrs, name)
+		}
+	}
+	sort.Strings(attrs)
+	return attrs, nil
+}
@@ -0,0 +1,44 @@
+// +build linux
+
+package system
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestFileAttributes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	attrs, err := fileAttributes(path)
+	if err != nil {
+		t.Skip(err)
+	}
+	if contains(attrs, "nodump") {
+		t.Fatalf("new file has nodump: %v", attrs)
+	}
+	if out, err := exec.Command("chattr", "+d", path).CombinedOutput(); err != nil {
+		t.Skipf("chattr: %v: %s", err, out)
+	}
+	if attrs, err = fileAttributes(path); err != nil || !contains(attrs, "nodump") {
+		t.Errorf("after chattr +d: got %v, %v, want nodump", attrs, err)
+	}
+
+	fifo := filepath.Join(dir, "fifo")
+	if err := syscall.Mkfifo(fifo, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fileAttributes(fifo); err == nil || !strings.Contains(err.Error(), "only supported for files and directories") {
+		t.Errorf("fifo: got %v", err)
+	}
+	if _, err := fileAttributes(filepath.Join(dir, "missing")); err == nil {
+		t.Error("missing file: got no error")
+	}
+}
@@ -0,0 +1,12 @@
+// +build !linux
+
+package system
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func fileAttributes(path string) ([]string, error) {
+	return nil, fmt.Errorf("file attributes are not supported on %s", runtime.GOOS)
+}
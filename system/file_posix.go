@@ -51,12 +51,30 @@ func (f *DefFile) Group() (string, error) {
 	return getGroupForGid(gid)
 }
 
+// Blocks is the number of 512 byte blocks allocated to the file
+func (f *DefFile) Blocks() (int, error) {
+	blocks, err := f.getFileInfo(func(fi os.FileInfo) string {
+		return fmt.Sprint(fi.Sys().(*syscall.Stat_t).Blocks)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(blocks)
+}
+
+// Allocated is the disk space used by the file in bytes, smaller than Size for
+// sparse or compressed files
+func (f *DefFile) Allocated() (int, error) {
+	blocks, err := f.Blocks()
+	return blocks * 512, err
+}
+
 func (f *DefFile) getFileInfo(selectorFunc func(os.FileInfo) string) (string, error) {
 	if err := f.setup(); err != nil {
 		return "", err
 	}
 
-	fi, err := os.Lstat(f.realPath)
+	fi, err := f.stat()
 	if err != nil {
 		return "", err
 	}
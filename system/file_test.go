@@ -0,0 +1,58 @@
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+func TestWalk(t *testing.T) {
+	root, err := ioutil.TempDir("", "goss-walk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	for _, d := range []string{"sub", "cache/deep"} {
+		if err := os.MkdirAll(filepath.Join(root, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{"a.pem", "a.bak", "sub/b.pem", "cache/deep/c"} {
+		if err := ioutil.WriteFile(filepath.Join(root, f), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tables := []struct {
+		exclude []string
+		want    []string
+	}{
+		{nil, []string{".", "a.bak", "a.pem", "cache", "cache/deep", "cache/deep/c", "sub", "sub/b.pem"}},
+		{[]string{"*.bak", "cache"}, []string{".", "a.pem", "sub", "sub/b.pem"}},
+		{[]string{"*/"}, []string{"a.bak", "a.pem", "cache/deep/c", "sub/b.pem"}},
+		{[]string{"sub/*.pem", "deep"}, []string{".", "a.bak", "a.pem", "cache", "sub"}},
+	}
+
+	for _, table := range tables {
+		files, err := NewDefFile(root, nil, util.Config{}).Walk(table.exclude)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, f := range files {
+			rel, _ := filepath.Rel(root, f.Path())
+			got = append(got, filepath.ToSlash(rel))
+		}
+		if !reflect.DeepEqual(got, table.want) {
+			t.Errorf("Walk (%v) was incorrect, got: %v, want: %v.", table.exclude, got, table.want)
+		}
+	}
+
+	if _, err := NewDefFile(root, nil, util.Config{}).Walk([]string{"["}); err == nil {
+		t.Errorf("Walk accepted an invalid pattern")
+	}
+}
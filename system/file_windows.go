@@ -13,3 +13,11 @@ func (f *DefFile) Owner() (string, error) {
 func (f *DefFile) Group() (string, error) {
 	return "-1", nil // not applicable on Windows
 }
+
+func (f *DefFile) Blocks() (int, error) {
+	return -1, nil // not applicable on Windows
+}
+
+func (f *DefFile) Allocated() (int, error) {
+	return -1, nil // not applicable on Windows
+}
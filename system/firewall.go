@@ -0,0 +1,425 @@
+package system
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// Firewall is whether the firewall has a rule matching some criteria, the
+// rules are read from iptables and nftables rather than matching the text
+// of iptables -S
+type Firewall interface {
+	Name() string
+	Exists() (bool, error)
+	SetRule(FirewallRule)
+}
+
+// FirewallRule is the criteria a rule has to match, empty fields match any
+// rule
+type FirewallRule struct {
+	// Backend is iptables or nftables, both are read when it's empty
+	Backend string
+	// Family is ipv4 or ipv6
+	Family   string
+	Table    string
+	Chain    string
+	Protocol string
+	DPort    int
+	// Action is the verdict or target of the rule, such as accept, drop or
+	// the chain it jumps to
+	Action string
+}
+
+// firewallRule is a rule read from a backend, family is ip, ip6 or, for
+// nftables, inet
+type firewallRule struct {
+	family   string
+	table    string
+	chain    string
+	protocol string
+	dports   [][2]int
+	action   string
+}
+
+type DefFirewall struct {
+	name    string
+	rule    FirewallRule
+	timeout int
+	loaded  bool
+	err     error
+	rules   []firewallRule
+}
+
+// firewallBackend reads the rules of a backend, the commands are the
+// alternatives to read them with, the first one installed is used
+type firewallBackend struct {
+	commands [][]string
+	parse    func(out string, family string) ([]firewallRule, error)
+	family   string
+}
+
+var firewallBackends = map[string][]firewallBackend{
+	// iptables-legacy-save is only installed next to the nftables based
+	// iptables, whose rules the nftables backend reads
+	"iptables": {
+		{commands: [][]string{{"iptables-legacy-save"}, {"iptables-save"}}, parse: parseIptablesSave, family: "ip"},
+		{commands: [][]string{{"ip6tables-legacy-save"}, {"ip6tables-save"}}, parse: parseIptablesSave, family: "ip6"},
+	},
+	"nftables": {
+		{commands: [][]string{{"nft", "--json", "list", "ruleset"}}, parse: parseNftRuleset},
+	},
+}
+
+func NewDefFirewall(name string, system *System, config util.Config) Firewall {
+	timeout := config.TimeOutMilliSeconds()
+	if timeout == 0 {
+		timeout = 10000
+	}
+	return &DefFirewall{name: name, timeout: timeout}
+}
+
+func (f *DefFirewall) Name() string {
+	return f.name
+}
+
+// SetRule sets the criteria of the rule
+func (f *DefFirewall) SetRule(rule FirewallRule) {
+	f.rule = rule
+}
+
+func (f *DefFirewall) setup() error {
+	if f.loaded {
+		return f.err
+	}
+	f.loaded = true
+
+	var backends []string
+	switch f.rule.Backend {
+	case "":
+		backends = []string{"iptables", "nftables"}
+	case "iptables", "nftables":
+		backends = []string{f.rule.Backend}
+	default:
+		f.err = fmt.Errorf("unknown firewall backend %q, must be iptables or nftables", f.rule.Backend)
+		return f.err
+	}
+	switch f.rule.Family {
+	case "", "ipv4", "ipv6":
+	default:
+		f.err = fmt.Errorf("unknown firewall family %q, must be ipv4 or ipv6", f.rule.Family)
+		return f.err
+	}
+
+	found := false
+	for _, name := range backends {
+		for _, b := range firewallBackends[name] {
+			args := firstInstalled(b.commands)
+			if args == nil {
+				continue
+			}
+			found = true
+			cmd := util.NewCommand(args[0], args[1:]...)
+			if err := runCommand(cmd, f.timeout); err != nil {
+				if msg := strings.TrimSpace(cmd.Stderr.String()); msg != "" {
+					err = fmt.Errorf("%s: %s", args[0], msg)
+				}
+				f.err = err
+				return f.err
+			}
+			rules, err := b.parse(cmd.Stdout.String(), b.family)
+			if err != nil {
+				f.err = fmt.Errorf("%s: %v", args[0], err)
+				return f.err
+			}
+			f.rules = append(f.rules, rules...)
+		}
+	}
+	if !found {
+		f.err = util.NewCodedError(util.ErrCodeCommandNotFound, fmt.Errorf("none of the firewall tools of %s are installed", strings.Join(backends, ", ")))
+	}
+	return f.err
+}
+
+func firstInstalled(commands [][]string) []string {
+	for _, args := range commands {
+		if HasCommand(args[0]) {
+			return args
+		}
+	}
+	return nil
+}
+
+// Exists reports whether a rule matches the criteria
+func (f *DefFirewall) Exists() (bool, error) {
+	if err := f.setup(); err != nil {
+		return false, err
+	}
+	for _, r := range f.rules {
+		if f.rule.matches(r) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c FirewallRule) matches(r firewallRule) bool {
+	switch c.Family {
+	case "ipv4":
+		if r.family != "ip" && r.family != "inet" {
+			return false
+		}
+	case "ipv6":
+		if r.family != "ip6" && r.family != "inet" {
+			return false
+		}
+	}
+	for _, f := range [][2]string{{c.Table, r.table}, {c.Chain, r.chain}, {c.Protocol, r.protocol}, {c.Action, r.action}} {
+		if f[0] != "" && !strings.EqualFold(f[0], f[1]) {
+			return false
+		}
+	}
+	if c.DPort != 0 {
+		for _, p := range r.dports {
+			if c.DPort >= p[0] && c.DPort <= p[1] {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// parseIptablesSave reads the rules of the output of iptables-save, negated
+// protocols are prefixed with ! so they don't match, negated ports are left
+// out
+func parseIptablesSave(out string, family string) ([]firewallRule, error) {
+	var rules []firewallRule
+	table := ""
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "*"):
+			table = line[1:]
+			continue
+		case !strings.HasPrefix(line, "-A "):
+			continue
+		}
+		args := splitIptablesArgs(line)
+		r := firewallRule{family: family, table: table, chain: args[1]}
+		negated := false
+		for i := 2; i < len(args); i++ {
+			if args[i] == "!" {
+				negated = true
+				continue
+			}
+			opt := args[i]
+			if i+1 >= len(args) {
+				break
+			}
+			value := args[i+1]
+			switch opt {
+			case "-p", "--protocol":
+				if negated {
+					value = "!" + value
+				}
+				r.protocol = value
+				i++
+			case "--dport", "--dports", "--destination-port", "--destination-ports":
+				if !negated {
+					dports, err := parsePorts(value)
+					if err != nil {
+						return nil, err
+					}
+					r.dports = dports
+				}
+				i++
+			case "-j", "--jump", "-g", "--goto":
+				r.action = strings.ToLower(value)
+				i++
+			}
+			negated = false
+		}
+		rules = append(rules, r)
+	}
+	return rules, scanner.Err()
+}
+
+// splitIptablesArgs splits a line of iptables-save on spaces, except within
+// double quotes, such as in comments
+func splitIptablesArgs(line string) []string {
+	var args []string
+	var arg strings.Builder
+	quoted, escaped, started := false, false, false
+	for _, c := range line {
+		switch {
+		case escaped:
+			arg.WriteRune(c)
+			escaped = false
+		case c == '\\' && quoted:
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+			started = true
+		case c == ' ' && !quoted:
+			if started {
+				args = append(args, arg.String())
+				arg.Reset()
+				started = false
+			}
+		default:
+			arg.WriteRune(c)
+			started = true
+		}
+	}
+	if started {
+		args = append(args, arg.String())
+	}
+	return args
+}
+
+// parsePorts reads a list of ports and port ranges, such as 22,1000:2000
+func parsePorts(s string) ([][2]int, error) {
+	var ports [][2]int
+	for _, p := range strings.Split(s, ",") {
+		bounds := strings.SplitN(p, ":", 2)
+		low, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q", p)
+		}
+		high := low
+		if len(bounds) == 2 {
+			if high, err = strconv.Atoi(bounds[1]); err != nil {
+				return nil, fmt.Errorf("invalid port %q", p)
+			}
+		}
+		ports = append(ports, [2]int{low, high})
+	}
+	return ports, nil
+}
+
+// nftVerdicts are the statements ending the evaluation of a rule, the action
+// of jump and goto is their target chain
+var nftVerdicts = map[string]bool{
+	"accept": true, "drop": true, "reject": true, "return": true, "queue": true,
+	"jump": true, "goto": true, "masquerade": true, "snat": true, "dnat": true, "redirect": true,
+}
+
+// parseNftRuleset reads the rules of the output of nft --json list ruleset
+func parseNftRuleset(out string, _ string) ([]firewallRule, error) {
+	var ruleset struct {
+		Nftables []struct {
+			Rule *struct {
+				Family string                       `json:"family"`
+				Table  string                       `json:"table"`
+				Chain  string                       `json:"chain"`
+				Expr   []map[string]json.RawMessage `json:"expr"`
+			} `json:"rule"`
+		} `json:"nftables"`
+	}
+	if err := json.Unmarshal([]byte(out), &ruleset); err != nil {
+		return nil, err
+	}
+	var rules []firewallRule
+	for _, o := range ruleset.Nftables {
+		if o.Rule == nil {
+			continue
+		}
+		r := firewallRule{family: o.Rule.Family, table: o.Rule.Table, chain: o.Rule.Chain}
+		logs := false
+		for _, expr := range o.Rule.Expr {
+			for key, value := range expr {
+				switch {
+				case key == "match":
+					nftMatch(&r, value)
+				case key == "log":
+					logs = true
+				case nftVerdicts[key]:
+					r.action = key
+					var jump struct {
+						Target string `json:"target"`
+					}
+					if (key == "jump" || key == "goto") && json.Unmarshal(value, &jump) == nil {
+						r.action = jump.Target
+					}
+				}
+			}
+		}
+		if r.action == "" && logs {
+			r.action = "log"
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// nftMatch sets the protocol or destination ports of r that a match
+// expression checks, such as tcp dport 22 or meta l4proto udp
+func nftMatch(r *firewallRule, value json.RawMessage) {
+	var m struct {
+		Op   string `json:"op"`
+		Left struct {
+			Payload *struct {
+				Protocol string `json:"protocol"`
+				Field    string `json:"field"`
+			} `json:"payload"`
+			Meta *struct {
+				Key string `json:"key"`
+			} `json:"meta"`
+		} `json:"left"`
+		Right json.RawMessage `json:"right"`
+	}
+	if json.Unmarshal(value, &m) != nil {
+		return
+	}
+	negated := m.Op == "!="
+	switch {
+	case m.Left.Payload != nil && m.Left.Payload.Field == "dport":
+		if m.Left.Payload.Protocol != "th" && r.protocol == "" {
+			r.protocol = m.Left.Payload.Protocol
+		}
+		if !negated {
+			r.dports = nftPorts(m.Right)
+		}
+	case m.Left.Meta != nil && m.Left.Meta.Key == "l4proto",
+		m.Left.Payload != nil && (m.Left.Payload.Field == "protocol" || m.Left.Payload.Field == "nexthdr"):
+		var protocol string
+		if json.Unmarshal(m.Right, &protocol) == nil {
+			if negated {
+				protocol = "!" + protocol
+			}
+			r.protocol = protocol
+		}
+	}
+}
+
+// nftPorts reads a port, a range of ports or a set of both
+func nftPorts(value json.RawMessage) [][2]int {
+	var port int
+	if json.Unmarshal(value, &port) == nil {
+		return [][2]int{{port, port}}
+	}
+	var r struct {
+		Range [2]int            `json:"range"`
+		Set   []json.RawMessage `json:"set"`
+	}
+	if json.Unmarshal(value, &r) != nil {
+		return nil
+	}
+	if r.Set == nil {
+		if r.Range == [2]int{} {
+			return nil
+		}
+		return [][2]int{r.Range}
+	}
+	var ports [][2]int
+	for _, v := range r.Set {
+		ports = append(ports, nftPorts(v)...)
+	}
+	return ports
+}
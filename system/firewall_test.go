@@ -0,0 +1,97 @@
+package system
+
+import (
+	"testing"
+)
+
+const iptablesSave = `# Generated by iptables-save v1.8.7
+*filter
+:INPUT DROP [0:0]
+:FORWARD DROP [0:0]
+-A INPUT -i lo -j ACCEPT
+-A INPUT -p tcp -m tcp --dport 22 -m comment --comment "allow ssh" -j ACCEPT
+-A INPUT -p udp -m multiport --dports 53,1000:2000 -j ACCEPT
+-A INPUT ! -p icmp -j LOGDROP
+-A INPUT -p tcp -m tcp ! --dport 80 -j REJECT --reject-with icmp-port-unreachable
+COMMIT
+*nat
+-A POSTROUTING -o eth0 -j MASQUERADE
+COMMIT
+`
+
+const nftRuleset = `{"nftables": [
+  {"metainfo": {"version": "1.0.2", "json_schema_version": 1}},
+  {"table": {"family": "inet", "name": "filter", "handle": 1}},
+  {"chain": {"family": "inet", "table": "filter", "name": "input", "handle": 1, "type": "filter", "hook": "input", "prio": 0, "policy": "drop"}},
+  {"rule": {"family": "inet", "table": "filter", "chain": "input", "handle": 4, "expr": [
+    {"match": {"op": "==", "left": {"payload": {"protocol": "tcp", "field": "dport"}}, "right": 22}},
+    {"counter": {"packets": 0, "bytes": 0}},
+    {"accept": null}]}},
+  {"rule": {"family": "inet", "table": "filter", "chain": "input", "handle": 5, "expr": [
+    {"match": {"op": "==", "left": {"meta": {"key": "l4proto"}}, "right": "udp"}},
+    {"match": {"op": "==", "left": {"payload": {"protocol": "th", "field": "dport"}}, "right": {"set": [53, {"range": [1000, 2000]}]}}},
+    {"jump": {"target": "udp_in"}}]}},
+  {"rule": {"family": "ip6", "table": "filter", "chain": "input", "handle": 6, "expr": [
+    {"match": {"op": "!=", "left": {"payload": {"protocol": "tcp", "field": "dport"}}, "right": 80}},
+    {"log": {"prefix": "dropped "}},
+    {"drop": null}]}}
+]}`
+
+func TestFirewallRules(t *testing.T) {
+	ipt, err := parseIptablesSave(iptablesSave, "ip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nft, err := parseNftRuleset(nftRuleset, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		rules []firewallRule
+		rule  FirewallRule
+		want  bool
+	}{
+		{ipt, FirewallRule{Table: "filter", Chain: "INPUT", Protocol: "tcp", DPort: 22, Action: "accept"}, true},
+		{ipt, FirewallRule{Chain: "input", Protocol: "tcp", DPort: 22}, true},
+		{ipt, FirewallRule{Protocol: "tcp", DPort: 22, Action: "drop"}, false},
+		{ipt, FirewallRule{Protocol: "udp", DPort: 1500, Action: "accept"}, true},
+		{ipt, FirewallRule{Protocol: "udp", DPort: 2001}, false},
+		{ipt, FirewallRule{Protocol: "icmp", Action: "logdrop"}, false},
+		{ipt, FirewallRule{Action: "logdrop"}, true},
+		{ipt, FirewallRule{DPort: 80, Action: "reject"}, false},
+		{ipt, FirewallRule{Table: "nat", Chain: "POSTROUTING", Action: "masquerade"}, true},
+		{ipt, FirewallRule{Family: "ipv6", Action: "masquerade"}, false},
+		{nft, FirewallRule{Table: "filter", Chain: "input", Protocol: "tcp", DPort: 22, Action: "accept"}, true},
+		{nft, FirewallRule{Family: "ipv6", Protocol: "tcp", DPort: 22}, true},
+		{nft, FirewallRule{Protocol: "udp", DPort: 1000, Action: "udp_in"}, true},
+		{nft, FirewallRule{Protocol: "udp", DPort: 54}, false},
+		{nft, FirewallRule{Family: "ipv6", Protocol: "tcp", DPort: 80}, false},
+		{nft, FirewallRule{Family: "ipv4", Action: "drop"}, false},
+		{nft, FirewallRule{Family: "ipv6", Protocol: "tcp", Action: "drop"}, true},
+	}
+	for _, tt := range tests {
+		got := false
+		for _, r := range tt.rules {
+			if tt.rule.matches(r) {
+				got = true
+			}
+		}
+		if got != tt.want {
+			t.Errorf("%+v: got %v, want %v", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestSplitIptablesArgs(t *testing.T) {
+	got := splitIptablesArgs(`-A INPUT -m comment --comment "allow \"web\" in" -j ACCEPT`)
+	want := []string{"-A", "INPUT", "-m", "comment", "--comment", `allow "web" in`, "-j", "ACCEPT"}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+package system
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+	"golang.org/x/net/http2"
+)
+
+// GRPC is a gRPC server, checked with the Check method of the standard
+// grpc.health.v1 Health service and the list of services of server reflection
+type GRPC interface {
+	Target() string
+	Exists() (bool, error)
+	Status(service string) (string, error)
+	Services() ([]string, error)
+	Latency() (int, error)
+	SetTLS(enabled bool, serverName string)
+}
+
+// The statuses of grpc.health.v1, SERVICE_UNKNOWN is also what a server that
+// doesn't know the service fails the call with
+var grpcHealthStatuses = map[uint64]string{
+	0: "UNKNOWN",
+	1: "SERVING",
+	2: "NOT_SERVING",
+	3: "SERVICE_UNKNOWN",
+}
+
+// The codes of grpc-status the checks tell apart
+const (
+	grpcNotFound      = 5
+	grpcUnimplemented = 12
+)
+
+type DefGRPC struct {
+	target string
+	// TLS connects with TLS rather than the h2c of plaintext servers
+	TLS           bool
+	AllowInsecure bool
+	CAFile        string
+	ClientCert    string
+	ClientKey     string
+	// ServerName is the name TLS verifies the certificate for and the
+	// :authority of the calls, the host of the target when empty
+	ServerName string
+	Timeout    int
+	// IPVersion is the address family of the connection, 4, 6 or any
+	IPVersion string
+	// Netns is the network namespace the calls are made from
+	Netns     string
+	resolver  *Resolver
+	client    *http.Client
+	clientErr error
+	statuses  map[string]string
+	errs      map[string]error
+	latency   time.Duration
+}
+
+func NewDefGRPC(target string, system *System, config util.Config) GRPC {
+	g := &DefGRPC{
+		target:        target,
+		AllowInsecure: config.AllowInsecure,
+		CAFile:        config.CAFile,
+		ClientCert:    config.ClientCert,
+		ClientKey:     config.ClientKey,
+		Timeout:       config.TimeOutMilliSeconds(),
+		IPVersion:     ipVersion(config.IPVersion, system),
+		Netns:         netnsName(config.Netns, system),
+		statuses:      make(map[string]string),
+		errs:          make(map[string]error),
+	}
+	if system != nil {
+		g.resolver = system.Resolver
+	}
+	return g
+}
+
+func (g *DefGRPC) Target() string {
+	return g.target
+}
+
+// Exists is whether the server answers the health check of the server
+func (g *DefGRPC) Exists() (bool, error) {
+	if _, err := g.Status(""); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetTLS sets whether the server is connected to with TLS and the name its
+// certificate is verified for
+func (g *DefGRPC) SetTLS(enabled bool, serverName string) {
+	g.TLS, g.ServerName = enabled, serverName
+}
+
+func (g *DefGRPC) setup() error {
+	if g.client != nil || g.clientErr != nil {
+		return g.clientErr
+	}
+	if _, _, err := net.SplitHostPort(g.target); err != nil {
+		g.clientErr = fmt.Errorf("grpc target must be host:port, got: %s", g.target)
+		return g.clientErr
+	}
+	tlsConfig, err := newTLSConfig(g.AllowInsecure, g.CAFile, g.ClientCert, g.ClientKey)
+	if err != nil {
+		g.clientErr = err
+		return g.clientErr
+	}
+	tlsConfig.NextProtos = []string{"h2"}
+	timeout := time.Duration(g.Timeout) * time.Millisecond
+	dialer := &net.Dialer{}
+	tr := &http2.Transport{
+		AllowHTTP: !g.TLS,
+		DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			conn, err := g.resolver.dialNetns(ctx, dialer, g.Netns, ipNetwork(network, g.IPVersion), g.target)
+			if err != nil || !g.TLS {
+				return conn, err
+			}
+			if cfg.ServerName == "" {
+				cfg.ServerName, _, _ = net.SplitHostPort(g.target)
+			}
+			tlsConn := tls.Client(conn, cfg)
+			tlsConn.SetDeadline(time.Now().Add(timeout))
+			if err := tlsConn.Handshake(); err != nil {
+				conn.Close()
+				return nil, err
+			}
+			tlsConn.SetDeadline(time.Time{})
+			return tlsConn, nil
+		},
+		TLSClientConfig: tlsConfig,
+	}
+	g.client = &http.Client{Transport: tr, Timeout: timeout}
+	return nil
+}
+
+// call makes the unary or server streaming call of method with the message
+// msg, the messages are those of the response
+func (g *DefGRPC) call(method string, msg []byte) ([][]byte, error) {
+	if err := g.setup(); err != nil {
+		return nil, err
+	}
+	scheme, host := "http", g.target
+	if g.TLS {
+		scheme = "https"
+	}
+	if g.ServerName != "" {
+		_, port, _ := net.SplitHostPort(g.target)
+		host = net.JoinHostPort(g.ServerName, port)
+	}
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	req, err := http.NewRequest(http.MethodPost, scheme+"://"+host+method, bytes.NewReader(append(frame, msg...)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("grpc %s: %s", method, resp.Status)
+	}
+	// Trailers-only responses carry the status in the headers
+	status, message := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status, message = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+	if code, _ := strconv.Atoi(status); status != "0" {
+		return nil, &grpcStatusError{code: code, message: message, method: method}
+	}
+	var messages [][]byte
+	for len(body) >= 5 {
+		if body[0] != 0 {
+			return nil, fmt.Errorf("grpc %s: compressed responses aren't supported", method)
+		}
+		n := int(binary.BigEndian.Uint32(body[1:5]))
+		if n > len(body)-5 {
+			return nil, fmt.Errorf("grpc %s: truncated response", method)
+		}
+		messages = append(messages, body[5:5+n])
+		body = body[5+n:]
+	}
+	return messages, nil
+}
+
+type grpcStatusError struct {
+	code    int
+	message string
+	method  string
+}
+
+func (e *grpcStatusError) Error() string {
+	return fmt.Sprintf("grpc %s: status %d: %s", e.method, e.code, e.message)
+}
+
+func grpcCode(err error) int {
+	var e *grpcStatusError
+	if errors.As(err, &e) {
+		return e.code
+	}
+	return -1
+}
+
+// Status is the grpc.health.v1 status of service, the empty service is the
+// health of the whole server
+func (g *DefGRPC) Status(service string) (string, error) {
+	if status, ok := g.statuses[service]; ok {
+		return status, g.errs[service]
+	}
+	status, err := g.check(service)
+	g.statuses[service], g.errs[service] = status, err
+	return status, err
+}
+
+func (g *DefGRPC) check(service string) (string, error) {
+	// HealthCheckRequest with the service as field 1
+	msg := append(protoKey(1, 2), protoBytes([]byte(service))...)
+	start := time.Now()
+	messages, err := g.call("/grpc.health.v1.Health/Check", msg)
+	if service == "" {
+		g.latency = time.Since(start)
+	}
+	switch grpcCode(err) {
+	case -1:
+	case grpcNotFound:
+		return "SERVICE_UNKNOWN", nil
+	case grpcUnimplemented:
+		return "", fmt.Errorf("%s doesn't implement the grpc.health.v1 Health service", g.target)
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(messages) != 1 {
+		return "", fmt.Errorf("grpc health check of %s: expected one response, got %d", g.target, len(messages))
+	}
+	var status uint64
+	err = protoEach(messages[0], func(field int, v uint64, _ []byte) {
+		if field == 1 {
+			status = v
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+	if s, ok := grpcHealthStatuses[status]; ok {
+		return s, nil
+	}
+	return strconv.FormatUint(status, 10), nil
+}
+
+// Services are the services server reflection lists, sorted, with v1 or the
+// v1alpha of older servers
+func (g *DefGRPC) Services() ([]string, error) {
+	// ServerReflectionRequest with an empty list_services, field 7
+	msg := append(protoKey(7, 2), protoBytes(nil)...)
+	messages, err := g.call("/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", msg)
+	if grpcCode(err) == grpcUnimplemented {
+		messages, err = g.call("/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo", msg)
+	}
+	if grpcCode(err) == grpcUnimplemented {
+		return nil, fmt.Errorf("%s doesn't implement server reflection", g.target)
+	}
+	if err != nil {
+		return nil, err
+	}
+	services := []string{}
+	for _, m := range messages {
+		var reflectionErr error
+		// list_services_response is field 6, its services field 1 and
+		// their name field 1, error_response is field 7
+		err := protoEach(m, func(field int, _ uint64, data []byte) {
+			switch field {
+			case 6:
+				protoEach(data, func(field int, _ uint64, data []byte) {
+					if field == 1 {
+						protoEach(data, func(field int, _ uint64, data []byte) {
+							if field == 1 {
+								services = append(services, string(data))
+							}
+						})
+					}
+				})
+			case 7:
+				protoEach(data, func(field int, _ uint64, data []byte) {
+					if field == 2 {
+						reflectionErr = fmt.Errorf("grpc reflection of %s: %s", g.target, data)
+					}
+				})
+			}
+		})
+		if err == nil {
+			err = reflectionErr
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(services)
+	return services, nil
+}
+
+// Latency is the time in milliseconds the health check of the server took
+func (g *DefGRPC) Latency() (int, error) {
+	if _, err := g.Status(""); err != nil {
+		return 0, err
+	}
+	return int(g.latency / time.Millisecond), nil
+}
+
+// protoKey is the key of a protobuf field of wire type wire
+func protoKey(field, wire int) []byte {
+	return protoVarint(uint64(field<<3 | wire))
+}
+
+// protoBytes is a length delimited protobuf value
+func protoBytes(b []byte) []byte {
+	return append(protoVarint(uint64(len(b))), b...)
+}
+
+func protoVarint(v uint64) []byte {
+	b := make([]byte, binary.MaxVarintLen64)
+	return b[:binary.PutUvarint(b, v)]
+}
+
+// protoEach calls f with the fields of the protobuf message msg, the value of
+// varints or the bytes of length delimited fields, the others are skipped
+func protoEach(msg []byte, f func(field int, v uint64, data []byte)) error {
+	for len(msg) > 0 {
+		key, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return fmt.Errorf("invalid protobuf message")
+		}
+		msg = msg[n:]
+		field := int(key >> 3)
+		switch key & 7 {
+		case 0:
+			v, n := binary.Uvarint(msg)
+			if n <= 0 {
+				return fmt.Errorf("invalid protobuf message")
+			}
+			f(field, v, nil)
+			msg = msg[n:]
+		case 1:
+			if len(msg) < 8 {
+				return fmt.Errorf("invalid protobuf message")
+			}
+			msg = msg[8:]
+		case 2:
+			length, n := binary.Uvarint(msg)
+			if n <= 0 || length > uint64(len(msg)-n) {
+				return fmt.Errorf("invalid protobuf message")
+			}
+			f(field, 0, msg[n:n+int(length)])
+			msg = msg[n+int(length):]
+		case 5:
+			if len(msg) < 4 {
+				return fmt.Errorf("invalid protobuf message")
+			}
+			msg = msg[4:]
+		default:
+			return fmt.Errorf("invalid protobuf message")
+		}
+	}
+	return nil
+}
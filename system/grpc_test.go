@@ -0,0 +1,106 @@
+package system
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// grpcTestServer serves grpc.health.v1 with the statuses of services and
+// the v1alpha server reflection listing them
+func grpcTestServer(services map[string]uint64) http.Handler {
+	respond := func(w http.ResponseWriter, msgs ...[]byte) {
+		w.Header().Set("Content-Type", "application/grpc")
+		for _, msg := range msgs {
+			frame := make([]byte, 5)
+			binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+			w.Write(append(frame, msg...))
+		}
+		w.(http.Flusher).Flush()
+		w.Header().Set(http.TrailerPrefix+"Grpc-Status", "0")
+	}
+	fail := func(w http.ResponseWriter, code string) {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", code)
+		w.WriteHeader(http.StatusOK)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/grpc.health.v1.Health/Check":
+			var service string
+			protoEach(body[5:], func(field int, _ uint64, data []byte) { service = string(data) })
+			status, ok := services[service]
+			if !ok {
+				fail(w, "5")
+				return
+			}
+			respond(w, append(protoKey(1, 0), protoVarint(status)...))
+		case "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo":
+			var list []byte
+			for name := range services {
+				if name != "" {
+					service := append(protoKey(1, 2), protoBytes([]byte(name))...)
+					list = append(list, append(protoKey(1, 2), protoBytes(service)...)...)
+				}
+			}
+			respond(w, append(protoKey(6, 2), protoBytes(list)...))
+		default:
+			fail(w, "12")
+		}
+	})
+}
+
+func TestGRPC(t *testing.T) {
+	services := map[string]uint64{"": 1, "orders.v1.Orders": 2, "users.v1.Users": 1}
+	server := httptest.NewServer(h2c.NewHandler(grpcTestServer(services), &http2.Server{}))
+	defer server.Close()
+	target := strings.TrimPrefix(server.URL, "http://")
+
+	g := NewDefGRPC(target, nil, util.Config{Timeout: 5 * time.Second})
+	tests := map[string]string{"": "SERVING", "orders.v1.Orders": "NOT_SERVING", "missing": "SERVICE_UNKNOWN"}
+	for service, want := range tests {
+		if status, err := g.Status(service); err != nil || status != want {
+			t.Errorf("status of %q: got %q, %v, want %s", service, status, err, want)
+		}
+	}
+	if got, err := g.Services(); err != nil || !reflect.DeepEqual(got, []string{"orders.v1.Orders", "users.v1.Users"}) {
+		t.Errorf("services: got %v, %v", got, err)
+	}
+	if _, err := g.Latency(); err != nil {
+		t.Errorf("latency: %v", err)
+	}
+
+	// Plaintext h2c against a TLS server fails
+	tlsServer := httptest.NewUnstartedServer(grpcTestServer(services))
+	tlsServer.EnableHTTP2 = true
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+	tlsTarget := strings.TrimPrefix(tlsServer.URL, "https://")
+	if _, err := NewDefGRPC(tlsTarget, nil, util.Config{Timeout: time.Second}).Status(""); err == nil {
+		t.Error("h2c against a TLS server: got no error")
+	}
+	g = NewDefGRPC(tlsTarget, nil, util.Config{AllowInsecure: true, Timeout: 5 * time.Second})
+	g.SetTLS(true, "")
+	if status, err := g.Status(""); err != nil || status != "SERVING" {
+		t.Errorf("status over TLS: got %q, %v", status, err)
+	}
+	g = NewDefGRPC(tlsTarget, nil, util.Config{Timeout: 5 * time.Second})
+	g.SetTLS(true, "")
+	if _, err := g.Status(""); err == nil {
+		t.Error("TLS with an untrusted certificate: got no error")
+	}
+
+	if _, err := NewDefGRPC("localhost", nil, util.Config{}).Status(""); err == nil {
+		t.Error("target without a port: got no error")
+	}
+}
@@ -1,9 +1,19 @@
 package system
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"strings"
 	"time"
@@ -16,22 +26,69 @@ type HTTP interface {
 	Status() (int, error)
 	Headers() (io.Reader, error)
 	Body() (io.Reader, error)
+	Latency() (int, error)
 	Exists() (bool, error)
 	SetAllowInsecure(bool)
 	SetNoFollowRedirects(bool)
 }
 
+// HTTPEncoding is an HTTP endpoint that reports the encoding and length of
+// the body as it's sent
+type HTTPEncoding interface {
+	ContentEncoding() (string, error)
+	ContentLength() (int, error)
+}
+
+// HTTPEcho is an HTTP endpoint that reports the request headers a backend
+// echoed, to check how it handles those of a load balancer
+type HTTPEcho interface {
+	EchoedHeader(name string) (string, error)
+}
+
+// HTTPCertFingerprints is an HTTP endpoint that reports the SPKI fingerprints
+// of the certificates it served
+type HTTPCertFingerprints interface {
+	CertFingerprints() ([]string, error)
+}
+
 type DefHTTP struct {
 	http              string
 	allowInsecure     bool
 	noFollowRedirects bool
 	resp              *http.Response
+	latency           time.Duration
 	RequestHeader     http.Header
 	Timeout           int
 	loaded            bool
 	err               error
 	Username          string
 	Password          string
+	CAFile            string
+	ClientCert        string
+	ClientKey         string
+	// CertPins are the SPKI fingerprints, sha256/<base64>, one of the
+	// certificates served must have, the connection is refused otherwise
+	CertPins []string
+	// AcceptEncoding is the Accept-Encoding header, gzip when neither it nor
+	// the request headers set it
+	AcceptEncoding string
+	// Resolve are the host:port:addr entries connections to host:port are
+	// made to addr with, as curl --resolve
+	Resolve []string
+	// IPVersion is the address family of the connection, 4, 6 or any
+	IPVersion string
+	// Netns is the network namespace the requests are made from
+	Netns string
+	// ProxyProtocol is the version of the PROXY protocol header, v1 or v2,
+	// sent before the requests as a load balancer would, none when empty
+	ProxyProtocol string
+	// ProxySource is the client ip or ip:port the PROXY protocol header
+	// claims, the address of the connection when empty
+	ProxySource string
+	servedPins  []string
+	resolver    *Resolver
+	// body is the decoded body once EchoedHeader read it
+	body []byte
 }
 
 func NewDefHTTP(httpStr string, system *System, config util.Config) HTTP {
@@ -40,7 +97,7 @@ func NewDefHTTP(httpStr string, system *System, config util.Config) HTTP {
 		str := strings.SplitN(r, ": ", 2)
 		headers.Add(str[0], str[1])
 	}
-	return &DefHTTP{
+	h := &DefHTTP{
 		http:              httpStr,
 		allowInsecure:     config.AllowInsecure,
 		noFollowRedirects: config.NoFollowRedirects,
@@ -48,7 +105,21 @@ func NewDefHTTP(httpStr string, system *System, config util.Config) HTTP {
 		Timeout:           config.TimeOutMilliSeconds(),
 		Username:          config.Username,
 		Password:          config.Password,
+		CAFile:            config.CAFile,
+		ClientCert:        config.ClientCert,
+		ClientKey:         config.ClientKey,
+		CertPins:          config.CertPins,
+		Resolve:           config.Resolve,
+		AcceptEncoding:    config.AcceptEncoding,
+		IPVersion:         ipVersion(config.IPVersion, system),
+		Netns:             netnsName(config.Netns, system),
+		ProxyProtocol:     config.ProxyProtocol,
+		ProxySource:       config.ProxySource,
 	}
+	if system != nil {
+		h.resolver = system.Resolver
+	}
+	return h
 }
 
 func HeaderToArray(header http.Header) (res []string) {
@@ -66,9 +137,46 @@ func (u *DefHTTP) setup() error {
 	}
 	u.loaded = true
 
+	tlsConfig, err := u.tlsConfig()
+	if err != nil {
+		u.err = err
+		return u.err
+	}
+	// Bodies are decompressed by Body rather than the transport, which would
+	// hide the Content-Encoding and Content-Length of the response
 	tr := &http.Transport{
-		TLSClientConfig:   &tls.Config{InsecureSkipVerify: u.allowInsecure},
-		DisableKeepAlives: true,
+		TLSClientConfig:    tlsConfig,
+		DisableKeepAlives:  true,
+		DisableCompression: true,
+	}
+	if u.ProxyProtocol != "" && u.ProxyProtocol != "v1" && u.ProxyProtocol != "v2" {
+		u.err = fmt.Errorf("proxy-protocol must be v1 or v2, got: %s", u.ProxyProtocol)
+		return u.err
+	}
+	if len(u.Resolve) > 0 || u.resolver != nil || u.IPVersion == "4" || u.IPVersion == "6" || u.Netns != "" || u.ProxyProtocol != "" {
+		resolve, err := parseResolve(u.Resolve)
+		if err != nil {
+			u.err = err
+			return u.err
+		}
+		dialer := &net.Dialer{}
+		tr.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			network = ipNetwork(network, u.IPVersion)
+			if to, ok := resolve[addr]; ok {
+				addr = to
+			}
+			conn, err := u.resolver.dialNetns(ctx, dialer, u.Netns, network, addr)
+			if err != nil || u.ProxyProtocol == "" {
+				return conn, err
+			}
+			// Keep-alives are disabled, so every request, redirects
+			// included, gets a connection and a header of its own
+			if err := sendProxyHeader(conn, u.ProxyProtocol, u.ProxySource); err != nil {
+				conn.Close()
+				return nil, err
+			}
+			return conn, nil
+		}
 	}
 	client := &http.Client{
 		Transport: tr,
@@ -86,6 +194,11 @@ func (u *DefHTTP) setup() error {
 		return u.err
 	}
 	req.Header = u.RequestHeader.Clone()
+	if u.AcceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", u.AcceptEncoding)
+	} else if req.Header.Get("Accept-Encoding") == "" && req.Header.Get("Range") == "" {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
 
 	if host := req.Header.Get("Host"); host != "" {
 		req.Host = host
@@ -94,11 +207,111 @@ func (u *DefHTTP) setup() error {
 	if u.Username != "" || u.Password != "" {
 		req.SetBasicAuth(u.Username, u.Password)
 	}
+	startTime := time.Now()
 	u.resp, u.err = client.Do(req)
+	u.latency = time.Since(startTime)
+	if u.err != nil {
+		util.Log().Debug("http request", "url", req.URL.Redacted(), "duration", u.latency, "error", u.err)
+	} else {
+		util.Log().Debug("http request", "url", req.URL.Redacted(), "status", u.resp.StatusCode, "duration", u.latency)
+	}
 
 	return u.err
 }
 
+// parseResolve maps the host:port of the entries of resolve to the address
+// they connect to, the URL is unchanged, so are SNI and the Host header
+func parseResolve(resolve []string) (map[string]string, error) {
+	m := make(map[string]string)
+	for _, r := range resolve {
+		parts := strings.SplitN(r, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("resolve must be host:port:addr, got: %s", r)
+		}
+		ip := net.ParseIP(strings.Trim(parts[2], "[]"))
+		if ip == nil {
+			return nil, fmt.Errorf("resolve must be host:port:addr, got: %s", r)
+		}
+		m[net.JoinHostPort(parts[0], parts[1])] = net.JoinHostPort(ip.String(), parts[1])
+	}
+	return m, nil
+}
+
+// tlsConfig builds the TLS configuration, loading the CA bundle and client
+// certificate when they are provided
+func (u *DefHTTP) tlsConfig() (*tls.Config, error) {
+	tlsConfig, err := newTLSConfig(u.allowInsecure, u.CAFile, u.ClientCert, u.ClientKey)
+	if err != nil || len(u.CertPins) == 0 {
+		return tlsConfig, err
+	}
+	for _, pin := range u.CertPins {
+		if !strings.HasPrefix(pin, "sha256/") {
+			return nil, fmt.Errorf("expected-cert-fingerprints must be sha256/<base64>, got: %s", pin)
+		}
+	}
+	// Runs after the chain is verified, or on its own with allow-insecure
+	tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		u.servedPins = make([]string, 0, len(rawCerts))
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return err
+			}
+			u.servedPins = append(u.servedPins, spkiFingerprint(cert))
+		}
+		for _, pin := range u.servedPins {
+			for _, want := range u.CertPins {
+				if pin == want {
+					return nil
+				}
+			}
+		}
+		return errCertPin
+	}
+	return tlsConfig, nil
+}
+
+var errCertPin = errors.New("none of the certificates served matches expected-cert-fingerprints")
+
+// spkiFingerprint is the sha256 of the public key of cert, as pinned by
+// HPKP or curl --pinnedpubkey, it stays the same when the certificate is
+// renewed with the same key
+func spkiFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// newTLSConfig is the client TLS config verifying the server with the
+// certificates of caFile, or the system pool when it's empty
+func newTLSConfig(allowInsecure bool, caFile, clientCert, clientKey string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: allowInsecure}
+
+	if caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read ca-file: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in ca-file: %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if clientCert != "" || clientKey != "" {
+		if clientCert == "" || clientKey == "" {
+			return nil, fmt.Errorf("client-cert and client-key must be provided together")
+		}
+		cert, err := tls.LoadX509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
 func (u *DefHTTP) Exists() (bool, error) {
 	if _, err := u.Status(); err != nil {
 		return false, err
@@ -138,10 +351,116 @@ func (u *DefHTTP) Headers() (io.Reader, error) {
 	return strings.NewReader(headerString), nil
 }
 
+// Body is the body of the response, decompressed when it's gzip or deflate
+// encoded
 func (u *DefHTTP) Body() (io.Reader, error) {
 	if err := u.setup(); err != nil {
 		return nil, err
 	}
+	if u.body != nil {
+		return bytes.NewReader(u.body), nil
+	}
+
+	switch enc := strings.ToLower(u.resp.Header.Get("Content-Encoding")); enc {
+	case "", "identity":
+		return u.resp.Body, nil
+	case "gzip", "x-gzip":
+		r, err := gzip.NewReader(u.resp.Body)
+		if err != nil {
+			u.resp.Body.Close()
+			return nil, fmt.Errorf("could not decompress the gzip body: %v", err)
+		}
+		return decodedBody{r, u.resp.Body}, nil
+	case "deflate":
+		r, err := zlib.NewReader(u.resp.Body)
+		if err != nil {
+			u.resp.Body.Close()
+			return nil, fmt.Errorf("could not decompress the deflate body: %v", err)
+		}
+		return decodedBody{r, u.resp.Body}, nil
+	default:
+		u.resp.Body.Close()
+		return nil, fmt.Errorf("can't decompress %s bodies, leave %s out of accept-encoding to match the body", enc, enc)
+	}
+}
+
+// EchoedHeader is the value of the request header name the backend echoed,
+// in a response header of the same name or, as echo servers print the request
+// headers they got, in a "name: value" line of the body
+func (u *DefHTTP) EchoedHeader(name string) (string, error) {
+	if err := u.setup(); err != nil {
+		return "", err
+	}
+	if v := u.resp.Header.Get(name); v != "" {
+		return v, nil
+	}
+	if u.body == nil {
+		body, err := u.Body()
+		if err != nil {
+			return "", err
+		}
+		b, err := ioutil.ReadAll(io.LimitReader(body, maxEchoBytes))
+		if err != nil {
+			return "", err
+		}
+		u.body = b
+	}
+	for _, line := range strings.Split(string(u.body), "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) == 2 && strings.EqualFold(strings.TrimSpace(parts[0]), name) {
+			return strings.TrimSpace(parts[1]), nil
+		}
+	}
+	return "", fmt.Errorf("the response doesn't echo %s", name)
+}
+
+// maxEchoBytes is how much of the body EchoedHeader reads, echo servers only
+// print the request
+const maxEchoBytes = 1 << 20
+
+// decodedBody closes the body it decompresses
+type decodedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// ContentEncoding is the Content-Encoding of the response, empty when the body
+// isn't compressed
+func (u *DefHTTP) ContentEncoding() (string, error) {
+	if err := u.setup(); err != nil {
+		return "", err
+	}
+
+	return u.resp.Header.Get("Content-Encoding"), nil
+}
+
+// ContentLength is the Content-Length of the response, the size of the body
+// as it's sent, so before it's decompressed, -1 when it isn't known
+func (u *DefHTTP) ContentLength() (int, error) {
+	if err := u.setup(); err != nil {
+		return 0, err
+	}
+
+	return int(u.resp.ContentLength), nil
+}
+
+// CertFingerprints are the SPKI fingerprints of the certificates served on the
+// last connection, of the leaf first, it's empty without TLS
+func (u *DefHTTP) CertFingerprints() ([]string, error) {
+	if err := u.setup(); err != nil && !errors.Is(err, errCertPin) {
+		return nil, err
+	}
+	if u.servedPins == nil {
+		return []string{}, nil
+	}
+	return u.servedPins, nil
+}
+
+// Latency is the time in milliseconds it took to receive the response headers
+func (u *DefHTTP) Latency() (int, error) {
+	if err := u.setup(); err != nil {
+		return 0, err
+	}
 
-	return u.resp.Body, nil
+	return int(u.latency / time.Millisecond), nil
 }
@@ -0,0 +1,312 @@
+package system
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+func TestHTTPCertPins(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	pin := spkiFingerprint(server.Certificate())
+
+	tests := []struct {
+		pins    []string
+		wantErr bool
+	}{
+		{[]string{"sha256/AAAA", pin}, false},
+		{[]string{"sha256/AAAA"}, true},
+	}
+	for _, tt := range tests {
+		h := NewDefHTTP(server.URL, nil, util.Config{AllowInsecure: true, CertPins: tt.pins, Timeout: 5 * time.Second}).(*DefHTTP)
+		if _, err := h.Status(); (err != nil) != tt.wantErr {
+			t.Errorf("pins %v: status error %v, want error %v", tt.pins, err, tt.wantErr)
+		}
+		served, err := h.CertFingerprints()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(served) != 1 || served[0] != pin {
+			t.Errorf("pins %v: served %v, want [%s]", tt.pins, served, pin)
+		}
+	}
+
+	h := NewDefHTTP(server.URL, nil, util.Config{AllowInsecure: true, CertPins: []string{"AAAA"}, Timeout: 5 * time.Second})
+	if _, err := h.Status(); err == nil {
+		t.Error("pin without sha256/ prefix: got no error")
+	}
+}
+
+func TestHTTPResolve(t *testing.T) {
+	var host string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { host = r.Host }))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	// The certificate of the test server is valid for example.com, so it's
+	// verified with the SNI of the URL
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := ioutil.WriteFile(caFile, ca, 0644); err != nil {
+		t.Fatal(err)
+	}
+	url := "https://example.com:" + port + "/"
+	h := NewDefHTTP(url, nil, util.Config{CAFile: caFile, Resolve: []string{"example.com:" + port + ":127.0.0.1"}, Timeout: 5 * time.Second})
+	if status, err := h.Status(); err != nil || status != 200 {
+		t.Fatalf("status: got %d, %v", status, err)
+	}
+	if host != "example.com:"+port {
+		t.Errorf("Host: got %q, want example.com:%s", host, port)
+	}
+
+	for _, r := range []string{"example.com:443", "example.com:443:not-an-ip", ":443:127.0.0.1"} {
+		if _, err := parseResolve([]string{r}); err == nil {
+			t.Errorf("resolve %q: got no error", r)
+		}
+	}
+}
+
+func TestHTTPClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, block *pem.Block) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// A self signed client certificate the server trusts
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "goss"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := write("client.pem", &pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyFile := write("client-key.pem", &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	var peer string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peer = r.TLS.PeerCertificates[0].Subject.CommonName
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	// The handshakes failing on purpose aren't logged
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+	caFile := write("ca.pem", &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	emptyFile := write("empty.pem", &pem.Block{Type: "EMPTY"})
+
+	status := func(config util.Config) (int, error) {
+		config.Timeout = 5 * time.Second
+		return NewDefHTTP(server.URL, nil, config).Status()
+	}
+	if got, err := status(util.Config{CAFile: caFile, ClientCert: certFile, ClientKey: keyFile}); err != nil || got != 200 {
+		t.Fatalf("mTLS: got %d, %v", got, err)
+	}
+	if peer != "goss" {
+		t.Errorf("server saw client certificate %q, want goss", peer)
+	}
+
+	tests := []struct {
+		name    string
+		config  util.Config
+		wantErr string
+	}{
+		{"no client certificate", util.Config{CAFile: caFile}, ""},
+		{"system pool", util.Config{ClientCert: certFile, ClientKey: keyFile}, "certificate"},
+		{"other ca", util.Config{CAFile: certFile, ClientCert: certFile, ClientKey: keyFile}, "certificate"},
+		{"no certificates in ca-file", util.Config{CAFile: emptyFile}, "no certificates found in ca-file"},
+		{"missing ca-file", util.Config{CAFile: filepath.Join(dir, "missing.pem")}, "could not read ca-file"},
+		{"cert without key", util.Config{CAFile: caFile, ClientCert: certFile}, "client-cert and client-key must be provided together"},
+		{"key as cert", util.Config{CAFile: caFile, ClientCert: keyFile, ClientKey: keyFile}, "could not load client certificate"},
+	}
+	for _, tt := range tests {
+		_, err := status(tt.config)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: got error %v, want one containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestHTTPEncoding(t *testing.T) {
+	var gzipped bytes.Buffer
+	zw := gzip.NewWriter(&gzipped)
+	zw.Write([]byte("hello world"))
+	zw.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Accept-Encoding") {
+		case "gzip":
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write(gzipped.Bytes())
+		case "br":
+			w.Header().Set("Content-Encoding", "br")
+			w.Write([]byte{0x0b})
+		default:
+			w.Write([]byte("hello world"))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		acceptEncoding string
+		encoding       string
+		length         int
+		body           string
+	}{
+		{"", "gzip", gzipped.Len(), "hello world"},
+		{"identity", "", 11, "hello world"},
+		{"br", "br", 1, ""},
+	}
+	for _, tt := range tests {
+		h := NewDefHTTP(server.URL, nil, util.Config{AcceptEncoding: tt.acceptEncoding, Timeout: 5 * time.Second}).(*DefHTTP)
+		if enc, err := h.ContentEncoding(); err != nil || enc != tt.encoding {
+			t.Errorf("accept-encoding %q: content-encoding got %q, %v, want %q", tt.acceptEncoding, enc, err, tt.encoding)
+		}
+		if length, err := h.ContentLength(); err != nil || length != tt.length {
+			t.Errorf("accept-encoding %q: content-length got %d, %v, want %d", tt.acceptEncoding, length, err, tt.length)
+		}
+		body, err := h.Body()
+		if tt.body == "" {
+			if err == nil {
+				t.Errorf("accept-encoding %q: body got no error", tt.acceptEncoding)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b, _ := ioutil.ReadAll(body); string(b) != tt.body {
+			t.Errorf("accept-encoding %q: body got %q, want %q", tt.acceptEncoding, b, tt.body)
+		}
+	}
+}
+
+// proxyListener reads the PROXY protocol header of the connections it accepts
+type proxyListener struct {
+	net.Listener
+	headers chan []byte
+}
+
+func (l proxyListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(conn)
+	var header []byte
+	if sig, _ := r.Peek(len(proxyV2Signature)); bytes.Equal(sig, proxyV2Signature) {
+		header = make([]byte, 16)
+		io.ReadFull(r, header)
+		rest := make([]byte, binary.BigEndian.Uint16(header[14:]))
+		io.ReadFull(r, rest)
+		header = append(header, rest...)
+	} else {
+		header, _ = r.ReadBytes('\n')
+	}
+	l.headers <- header
+	return bufferedConn{conn, r}, nil
+}
+
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c bufferedConn) Read(b []byte) (int, error) { return c.r.Read(b) }
+
+func TestHTTPProxyProtocol(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pl := proxyListener{l, make(chan []byte, 10)}
+	go http.Serve(pl, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Real-IP", "203.0.113.7")
+		fmt.Fprintf(w, "GET / HTTP/1.1\nX-Forwarded-For: %s\n", r.Header.Get("X-Forwarded-For"))
+	}))
+	defer l.Close()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+
+	h := NewDefHTTP("http://"+l.Addr().String(), nil, util.Config{
+		ProxyProtocol: "v1", ProxySource: "203.0.113.7:4242",
+		RequestHeader: []string{"X-Forwarded-For: 198.51.100.9"}, Timeout: 5 * time.Second}).(*DefHTTP)
+	if v, err := h.EchoedHeader("X-Real-IP"); err != nil || v != "203.0.113.7" {
+		t.Errorf("x-real-ip: got %q, %v", v, err)
+	}
+	if v, err := h.EchoedHeader("X-Forwarded-For"); err != nil || v != "198.51.100.9" {
+		t.Errorf("x-forwarded-for: got %q, %v", v, err)
+	}
+	if _, err := h.EchoedHeader("Forwarded"); err == nil {
+		t.Error("forwarded isn't echoed: got no error")
+	}
+	body, _ := h.Body()
+	if b, _ := ioutil.ReadAll(body); !strings.Contains(string(b), "X-Forwarded-For") {
+		t.Errorf("body after the echoed headers: got %q", b)
+	}
+	if got, want := string(<-pl.headers), "PROXY TCP4 203.0.113.7 127.0.0.1 4242 "+port+"\r\n"; got != want {
+		t.Errorf("v1 header: got %q, want %q", got, want)
+	}
+
+	h = NewDefHTTP("http://"+l.Addr().String(), nil, util.Config{ProxyProtocol: "v2", ProxySource: "203.0.113.7", Timeout: 5 * time.Second}).(*DefHTTP)
+	if status, err := h.Status(); err != nil || status != 200 {
+		t.Fatalf("v2 status: got %d, %v", status, err)
+	}
+	header := <-pl.headers
+	p, _ := strconv.Atoi(port)
+	want := append(append([]byte{}, proxyV2Signature...), 0x21, 0x11, 0, 12, 203, 0, 113, 7, 127, 0, 0, 1)
+	if len(header) != len(want)+4 || !bytes.Equal(header[:len(want)], want) || int(binary.BigEndian.Uint16(header[len(want)+2:])) != p {
+		t.Errorf("v2 header: got %x", header)
+	}
+
+	for _, c := range []util.Config{{ProxyProtocol: "v3"}, {ProxyProtocol: "v1", ProxySource: "2001:db8::1"}, {ProxyProtocol: "v1", ProxySource: "not-an-ip"}} {
+		c.Timeout = 5 * time.Second
+		if _, err := NewDefHTTP("http://"+l.Addr().String(), nil, c).Status(); err == nil {
+			t.Errorf("%s %s: got no error", c.ProxyProtocol, c.ProxySource)
+		}
+	}
+}
@@ -1,7 +1,12 @@
 package system
 
 import (
+	"fmt"
+	"io/ioutil"
 	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/aelsabbahy/goss/util"
 )
@@ -10,9 +15,16 @@ type Interface interface {
 	Name() string
 	Exists() (bool, error)
 	Addrs() ([]string, error)
+	IPv6Addrs() ([]string, error)
 	MTU() (int, error)
+	State() (string, error)
+	Speed() (int, error)
+	Duplex() (string, error)
 }
 
+// sysClassNet is where the kernel describes the state of the links
+var sysClassNet = "/sys/class/net"
+
 type DefInterface struct {
 	name   string
 	loaded bool
@@ -77,6 +89,22 @@ func (i *DefInterface) Addrs() ([]string, error) {
 	return ret, nil
 }
 
+// IPv6Addrs are the IPv6 addresses of Addrs, link-local ones included
+func (i *DefInterface) IPv6Addrs() ([]string, error) {
+	addrs, err := i.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	var ret []string
+	for _, addr := range addrs {
+		ip, _, err := net.ParseCIDR(addr)
+		if err == nil && ip.To4() == nil {
+			ret = append(ret, addr)
+		}
+	}
+	return ret, nil
+}
+
 func (i *DefInterface) MTU() (int, error) {
 	if err := i.setup(); err != nil {
 		return 0, err
@@ -84,3 +112,49 @@ func (i *DefInterface) MTU() (int, error) {
 
 	return i.iface.MTU, nil
 }
+
+// State is the operational state of the link, such as up, down or dormant.
+// Links whose driver doesn't track it, such as loopback, are unknown.
+func (i *DefInterface) State() (string, error) {
+	if err := i.setup(); err != nil {
+		return "", err
+	}
+	return i.readSys("operstate")
+}
+
+// Speed is the negotiated speed of the link in Mb/s, only physical links
+// that are up report one
+func (i *DefInterface) Speed() (int, error) {
+	if err := i.setup(); err != nil {
+		return 0, err
+	}
+	s, err := i.readSys("speed")
+	if err != nil {
+		return 0, fmt.Errorf("%s doesn't report a speed, it's down or not a physical link", i.name)
+	}
+	speed, err := strconv.Atoi(s)
+	if err != nil || speed < 0 {
+		return 0, fmt.Errorf("%s doesn't report a speed, it's down or not a physical link", i.name)
+	}
+	return speed, nil
+}
+
+// Duplex is full or half, or unknown while the link is down
+func (i *DefInterface) Duplex() (string, error) {
+	if err := i.setup(); err != nil {
+		return "", err
+	}
+	duplex, err := i.readSys("duplex")
+	if err != nil {
+		return "", fmt.Errorf("%s doesn't report a duplex, it's not a physical link", i.name)
+	}
+	return duplex, nil
+}
+
+func (i *DefInterface) readSys(attr string) (string, error) {
+	b, err := ioutil.ReadFile(filepath.Join(sysClassNet, i.name, attr))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
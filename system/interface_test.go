@@ -0,0 +1,59 @@
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+func TestInterfaceLink(t *testing.T) {
+	root, err := ioutil.TempDir("", "goss-interface")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	defer func(s string) { sysClassNet = s }(sysClassNet)
+	sysClassNet = root
+
+	i := NewDefInterface("lo", nil, util.Config{})
+	if exists, _ := i.Exists(); !exists {
+		t.Skip("no loopback interface")
+	}
+	if err := os.Mkdir(filepath.Join(root, "lo"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for attr, value := range map[string]string{"operstate": "up\n", "speed": "1000\n", "duplex": "full\n"} {
+		if err := ioutil.WriteFile(filepath.Join(root, "lo", attr), []byte(value), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if state, err := i.State(); err != nil || state != "up" {
+		t.Errorf("state was incorrect, got: %q, %v, want: up", state, err)
+	}
+	if speed, err := i.Speed(); err != nil || speed != 1000 {
+		t.Errorf("speed was incorrect, got: %d, %v, want: 1000", speed, err)
+	}
+	if duplex, err := i.Duplex(); err != nil || duplex != "full" {
+		t.Errorf("duplex was incorrect, got: %q, %v, want: full", duplex, err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(root, "lo", "speed"), []byte("-1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.Speed(); err == nil {
+		t.Errorf("a link without a speed should be an error")
+	}
+
+	addrs, err := i.IPv6Addrs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range addrs {
+		if addr == "127.0.0.1/8" {
+			t.Errorf("ipv6 addrs had an ipv4 address, got: %v", addrs)
+		}
+	}
+}
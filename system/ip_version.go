@@ -0,0 +1,63 @@
+package system
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ValidateIPVersion checks the address family of network checks, 4, 6 or
+// any, empty for the default of the run
+func ValidateIPVersion(version string) error {
+	switch version {
+	case "", "4", "6", "any":
+		return nil
+	}
+	return fmt.Errorf("ip-version must be 4, 6 or any, got: %s", version)
+}
+
+// ipVersion is version, or the default of the run of system when it's empty
+func ipVersion(version string, system *System) string {
+	if version == "" && system != nil {
+		return system.IPVersion
+	}
+	return version
+}
+
+// ipNetwork is network restricted to the addresses of version, such as tcp4
+// for tcp over IPv4, any leaves it to network
+func ipNetwork(network, version string) string {
+	switch version {
+	case "4", "6":
+		return strings.TrimRight(network, "46") + version
+	}
+	return network
+}
+
+// networkAllows reports whether network, such as tcp or udp6, connects to ip
+func networkAllows(network, ip string) bool {
+	addr := net.ParseIP(ip)
+	switch {
+	case addr == nil:
+		return true
+	case strings.HasSuffix(network, "4"):
+		return addr.To4() != nil
+	case strings.HasSuffix(network, "6"):
+		return addr.To4() == nil
+	}
+	return true
+}
+
+// filterIPVersion is the addresses of addrs of version
+func filterIPVersion(addrs []string, version string) []string {
+	if version != "4" && version != "6" {
+		return addrs
+	}
+	filtered := []string{}
+	for _, a := range addrs {
+		if networkAllows("ip"+version, a) {
+			filtered = append(filtered, a)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,80 @@
+package system
+
+import (
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+func TestPortKeys(t *testing.T) {
+	tests := []struct {
+		port, version string
+		want          []string
+	}{
+		{"tcp:80", "", []string{"tcp:80"}},
+		{"tcp6:80", "", []string{"tcp6:80"}},
+		{"tcp6:80", "4", []string{"tcp:80"}},
+		{"udp:53", "6", []string{"udp6:53"}},
+		{"tcp:80", "any", []string{"tcp:80", "tcp6:80"}},
+	}
+	for _, tt := range tests {
+		if got := portKeys(tt.port, tt.version); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("portKeys(%s, %s): got %v, want %v", tt.port, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestIPVersion(t *testing.T) {
+	l, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+
+	hostsFile := filepath.Join(t.TempDir(), "hosts")
+	if err := ioutil.WriteFile(hostsFile, []byte("::1 dual.goss.test\n127.0.0.1 dual.goss.test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	resolver, err := NewResolver("", hostsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		version   string
+		reachable bool
+		addrs     []string
+	}{
+		{"4", true, []string{"127.0.0.1"}},
+		{"6", false, []string{"::1"}},
+		{"any", true, []string{"127.0.0.1", "::1"}},
+	} {
+		sys := &System{Resolver: resolver, IPVersion: tt.version}
+		config := util.Config{Timeout: time.Second}
+		if reachable, _ := NewDefAddr("tcp://dual.goss.test:"+port, sys, config).Reachable(); reachable != tt.reachable {
+			t.Errorf("ip-version %s: reachable got %v, want %v", tt.version, reachable, tt.reachable)
+		}
+		if addrs, _ := NewDefDNS("dual.goss.test", sys, config).Addrs(); !reflect.DeepEqual(addrs, tt.addrs) {
+			t.Errorf("ip-version %s: addrs got %v, want %v", tt.version, addrs, tt.addrs)
+		}
+	}
+
+	if err := ValidateIPVersion("5"); err == nil {
+		t.Error("ip-version 5 must be invalid")
+	}
+}
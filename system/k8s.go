@@ -0,0 +1,433 @@
+// +build !no_k8s,!slim
+
+package system
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+type DefK8s struct {
+	name       string
+	kubeconfig string
+	context    string
+	timeout    time.Duration
+	loaded     bool
+	err        error
+	object     *k8sObject
+}
+
+var (
+	k8sServiceAccount = "/var/run/secrets/kubernetes.io/serviceaccount"
+	k8sAPIPaths       = map[string]string{
+		"pod":         "api/v1",
+		"deployment":  "apis/apps/v1",
+		"daemonset":   "apis/apps/v1",
+		"statefulset": "apis/apps/v1",
+	}
+)
+
+// k8sObject holds the fields of the kinds goss supports it uses
+type k8sObject struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Generation int64 `json:"generation"`
+	} `json:"metadata"`
+	Spec struct {
+		Replicas *int `json:"replicas"`
+	} `json:"spec"`
+	Status struct {
+		ObservedGeneration     int64 `json:"observedGeneration"`
+		Replicas               int   `json:"replicas"`
+		ReadyReplicas          int   `json:"readyReplicas"`
+		UpdatedReplicas        int   `json:"updatedReplicas"`
+		AvailableReplicas      int   `json:"availableReplicas"`
+		DesiredNumberScheduled int   `json:"desiredNumberScheduled"`
+		NumberReady            int   `json:"numberReady"`
+		UpdatedNumberScheduled int   `json:"updatedNumberScheduled"`
+		NumberAvailable        int   `json:"numberAvailable"`
+		Conditions             []struct {
+			Type   string `json:"type"`
+			Status string `json:"status"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+func NewDefK8s(name string, system *System, config util.Config) K8s {
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	return &DefK8s{name: name, timeout: timeout}
+}
+
+func (k *DefK8s) Name() string {
+	return k.name
+}
+
+// SetKubeconfig sets the kubeconfig file, by default it's the in-cluster
+// service account, then KUBECONFIG and ~/.kube/config
+func (k *DefK8s) SetKubeconfig(path string) {
+	k.kubeconfig = path
+}
+
+// SetContext sets the context of the kubeconfig, by default the current one
+func (k *DefK8s) SetContext(context string) {
+	k.context = context
+}
+
+func (k *DefK8s) parseName() (namespace, kind, name string, err error) {
+	parts := strings.Split(k.name, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+		return "", "", "", fmt.Errorf("invalid k8s name %q, must be namespace/kind/name", k.name)
+	}
+	kind = strings.ToLower(parts[1])
+	if _, ok := k8sAPIPaths[kind]; !ok {
+		return "", "", "", fmt.Errorf("unsupported k8s kind %q, must be pod, deployment, daemonset or statefulset", parts[1])
+	}
+	return parts[0], kind, parts[2], nil
+}
+
+func (k *DefK8s) setup() error {
+	if k.loaded {
+		return k.err
+	}
+	k.loaded = true
+
+	namespace, kind, name, err := k.parseName()
+	if err != nil {
+		k.err = err
+		return k.err
+	}
+	cluster, err := k.cluster()
+	if err != nil {
+		k.err = err
+		return k.err
+	}
+	u := strings.TrimSuffix(cluster.server, "/") + "/" + k8sAPIPaths[kind] +
+		"/namespaces/" + url.PathEscape(namespace) + "/" + kind + "s/" + url.PathEscape(name)
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		k.err = err
+		return k.err
+	}
+	req.Header.Set("Accept", "application/json")
+	if cluster.token != "" {
+		req.Header.Set("Authorization", "Bearer "+cluster.token)
+	}
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: cluster.tlsConfig, DisableKeepAlives: true},
+		Timeout:   k.timeout,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		k.err = err
+		return k.err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil
+	default:
+		var status struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&status)
+		k.err = fmt.Errorf("getting %s: %s: %s", k.name, resp.Status, status.Message)
+		return k.err
+	}
+	var object k8sObject
+	if err := json.NewDecoder(resp.Body).Decode(&object); err != nil {
+		k.err = fmt.Errorf("getting %s: %v", k.name, err)
+		return k.err
+	}
+	k.object = &object
+	return nil
+}
+
+func (k *DefK8s) Exists() (bool, error) {
+	if err := k.setup(); err != nil {
+		return false, err
+	}
+	return k.object != nil, nil
+}
+
+func (k *DefK8s) get() (*k8sObject, error) {
+	if err := k.setup(); err != nil {
+		return nil, err
+	}
+	if k.object == nil {
+		return nil, fmt.Errorf("%s doesn't exist", k.name)
+	}
+	return k.object, nil
+}
+
+// Ready reports whether a pod has the Ready condition, or whether all the
+// replicas of a controller are updated to its latest spec, ready and
+// available
+func (k *DefK8s) Ready() (bool, error) {
+	o, err := k.get()
+	if err != nil {
+		return false, err
+	}
+	s := o.Status
+	switch o.Kind {
+	case "Pod":
+		for _, c := range s.Conditions {
+			if c.Type == "Ready" {
+				return c.Status == "True", nil
+			}
+		}
+		return false, nil
+	case "DaemonSet":
+		return s.ObservedGeneration >= o.Metadata.Generation &&
+			s.UpdatedNumberScheduled == s.DesiredNumberScheduled &&
+			s.NumberReady == s.DesiredNumberScheduled &&
+			s.NumberAvailable == s.DesiredNumberScheduled, nil
+	}
+	replicas := o.replicas()
+	ready := s.ObservedGeneration >= o.Metadata.Generation &&
+		s.UpdatedReplicas == replicas &&
+		s.ReadyReplicas == replicas &&
+		s.Replicas == replicas
+	// Statefulsets only report available replicas since Kubernetes 1.22
+	if o.Kind == "Deployment" {
+		ready = ready && s.AvailableReplicas == replicas
+	}
+	return ready, nil
+}
+
+// Replicas is the desired number of replicas, the number of nodes the pods of
+// a daemonset should run on
+func (k *DefK8s) Replicas() (int, error) {
+	o, err := k.get()
+	if err != nil {
+		return 0, err
+	}
+	switch o.Kind {
+	case "Pod":
+		return 0, fmt.Errorf("replicas is not supported for pods")
+	case "DaemonSet":
+		return o.Status.DesiredNumberScheduled, nil
+	}
+	return o.replicas(), nil
+}
+
+func (k *DefK8s) ReadyReplicas() (int, error) {
+	o, err := k.get()
+	if err != nil {
+		return 0, err
+	}
+	switch o.Kind {
+	case "Pod":
+		return 0, fmt.Errorf("ready-replicas is not supported for pods")
+	case "DaemonSet":
+		return o.Status.NumberReady, nil
+	}
+	return o.Status.ReadyReplicas, nil
+}
+
+// replicas is spec.replicas, which defaults to 1
+func (o *k8sObject) replicas() int {
+	if o.Spec.Replicas == nil {
+		return 1
+	}
+	return *o.Spec.Replicas
+}
+
+// k8sCluster is how to reach and authenticate to the API server
+type k8sCluster struct {
+	server    string
+	token     string
+	tlsConfig *tls.Config
+}
+
+// kubeconfig is the part of the kubeconfig format goss supports, exec and
+// auth provider plugins aren't
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster string `yaml:"cluster"`
+			User    string `yaml:"user"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string      `yaml:"token"`
+			TokenFile             string      `yaml:"tokenFile"`
+			ClientCertificate     string      `yaml:"client-certificate"`
+			ClientCertificateData string      `yaml:"client-certificate-data"`
+			ClientKey             string      `yaml:"client-key"`
+			ClientKeyData         string      `yaml:"client-key-data"`
+			Exec                  interface{} `yaml:"exec"`
+			AuthProvider          interface{} `yaml:"auth-provider"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+}
+
+func (k *DefK8s) cluster() (*k8sCluster, error) {
+	kubeconfig := k.kubeconfig
+	if kubeconfig == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host != "" && port != "" && pathExists(filepath.Join(k8sServiceAccount, "token")) {
+			return inClusterK8s(host, port)
+		}
+		kubeconfig = strings.Split(os.Getenv("KUBECONFIG"), string(os.PathListSeparator))[0]
+	}
+	if kubeconfig == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		kubeconfig = filepath.Join(home, ".kube", "config")
+	}
+	return kubeconfigK8s(kubeconfig, k.context)
+}
+
+// inClusterK8s uses the service account of the pod goss runs in
+func inClusterK8s(host, port string) (*k8sCluster, error) {
+	token, err := ioutil.ReadFile(filepath.Join(k8sServiceAccount, "token"))
+	if err != nil {
+		return nil, err
+	}
+	ca, err := ioutil.ReadFile(filepath.Join(k8sServiceAccount, "ca.crt"))
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificates found in the service account ca.crt")
+	}
+	return &k8sCluster{
+		server:    "https://" + net.JoinHostPort(host, port),
+		token:     strings.TrimSpace(string(token)),
+		tlsConfig: &tls.Config{RootCAs: pool},
+	}, nil
+}
+
+func kubeconfigK8s(path, context string) (*k8sCluster, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("kubeconfig error: %v", err)
+	}
+	var kc kubeconfig
+	if err := yaml.Unmarshal(data, &kc); err != nil {
+		return nil, fmt.Errorf("kubeconfig %s: %v", path, err)
+	}
+	if context == "" {
+		context = kc.CurrentContext
+	}
+	// Relative paths in a kubeconfig are relative to its directory
+	abs := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(filepath.Dir(path), p)
+	}
+
+	ctxFound := false
+	var clusterName, userName string
+	for _, c := range kc.Contexts {
+		if c.Name == context {
+			ctxFound, clusterName, userName = true, c.Context.Cluster, c.Context.User
+		}
+	}
+	if !ctxFound {
+		return nil, fmt.Errorf("kubeconfig %s: context %q not found", path, context)
+	}
+
+	cluster := &k8sCluster{tlsConfig: &tls.Config{}}
+	for _, c := range kc.Clusters {
+		if c.Name != clusterName {
+			continue
+		}
+		cluster.server = c.Cluster.Server
+		cluster.tlsConfig.InsecureSkipVerify = c.Cluster.InsecureSkipTLSVerify
+		ca, err := fileOrData(abs(c.Cluster.CertificateAuthority), c.Cluster.CertificateAuthorityData)
+		if err != nil {
+			return nil, fmt.Errorf("kubeconfig %s: certificate-authority: %v", path, err)
+		}
+		if ca != nil {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("kubeconfig %s: no certificates found in the certificate authority of %s", path, clusterName)
+			}
+			cluster.tlsConfig.RootCAs = pool
+		}
+	}
+	if cluster.server == "" {
+		return nil, fmt.Errorf("kubeconfig %s: cluster %q not found", path, clusterName)
+	}
+
+	for _, u := range kc.Users {
+		if u.Name != userName {
+			continue
+		}
+		if u.User.Exec != nil || u.User.AuthProvider != nil {
+			return nil, fmt.Errorf("kubeconfig %s: user %s: exec and auth-provider credentials are not supported", path, userName)
+		}
+		cluster.token = u.User.Token
+		if u.User.TokenFile != "" {
+			token, err := ioutil.ReadFile(abs(u.User.TokenFile))
+			if err != nil {
+				return nil, fmt.Errorf("kubeconfig %s: tokenFile: %v", path, err)
+			}
+			cluster.token = strings.TrimSpace(string(token))
+		}
+		cert, err := fileOrData(abs(u.User.ClientCertificate), u.User.ClientCertificateData)
+		if err != nil {
+			return nil, fmt.Errorf("kubeconfig %s: client-certificate: %v", path, err)
+		}
+		key, err := fileOrData(abs(u.User.ClientKey), u.User.ClientKeyData)
+		if err != nil {
+			return nil, fmt.Errorf("kubeconfig %s: client-key: %v", path, err)
+		}
+		if cert != nil || key != nil {
+			pair, err := tls.X509KeyPair(cert, key)
+			if err != nil {
+				return nil, fmt.Errorf("kubeconfig %s: user %s: %v", path, userName, err)
+			}
+			cluster.tlsConfig.Certificates = []tls.Certificate{pair}
+		}
+	}
+	return cluster, nil
+}
+
+// fileOrData reads the PEM of a kubeconfig field that's a file or base64 data
+func fileOrData(file, data string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	if file != "" {
+		return ioutil.ReadFile(file)
+	}
+	return nil, nil
+}
@@ -0,0 +1,23 @@
+// +build no_k8s slim
+
+package system
+
+import (
+	"github.com/aelsabbahy/goss/util"
+)
+
+type disabledK8s struct {
+	name string
+}
+
+func NewDefK8s(name string, system *System, config util.Config) K8s {
+	return &disabledK8s{name: name}
+}
+
+func (k *disabledK8s) Name() string                { return k.name }
+func (k *disabledK8s) Exists() (bool, error)       { return false, errNotBuiltIn("k8s") }
+func (k *disabledK8s) Ready() (bool, error)        { return false, errNotBuiltIn("k8s") }
+func (k *disabledK8s) Replicas() (int, error)      { return 0, errNotBuiltIn("k8s") }
+func (k *disabledK8s) ReadyReplicas() (int, error) { return 0, errNotBuiltIn("k8s") }
+func (k *disabledK8s) SetKubeconfig(string)        {}
+func (k *disabledK8s) SetContext(string)           {}
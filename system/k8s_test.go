@@ -0,0 +1,118 @@
+// +build !no_k8s,!slim
+
+package system
+
+import (
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+func TestDefK8s(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message": "Unauthorized"}`)
+			return
+		}
+		switch r.URL.Path {
+		case "/apis/apps/v1/namespaces/default/deployments/web":
+			fmt.Fprint(w, `{"kind": "Deployment", "metadata": {"generation": 3}, "spec": {"replicas": 3},
+				"status": {"observedGeneration": 3, "replicas": 3, "updatedReplicas": 3, "readyReplicas": 3, "availableReplicas": 3}}`)
+		case "/apis/apps/v1/namespaces/kube-system/daemonsets/kube-proxy":
+			fmt.Fprint(w, `{"kind": "DaemonSet", "metadata": {"generation": 1},
+				"status": {"observedGeneration": 1, "desiredNumberScheduled": 4, "updatedNumberScheduled": 4, "numberReady": 3, "numberAvailable": 3}}`)
+		case "/api/v1/namespaces/default/pods/db-0":
+			fmt.Fprint(w, `{"kind": "Pod", "status": {"conditions": [{"type": "Initialized", "status": "True"}, {"type": "Ready", "status": "True"}]}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "not found"}`)
+		}
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "goss-k8s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	kubeconfig := filepath.Join(dir, "config")
+	if err := ioutil.WriteFile(filepath.Join(dir, "token"), []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(kubeconfig, []byte(fmt.Sprintf(`
+current-context: test
+clusters:
+- name: test
+  cluster:
+    server: %s
+    certificate-authority-data: %s
+contexts:
+- name: test
+  context: {cluster: test, user: test}
+- name: other
+  context: {cluster: missing, user: test}
+users:
+- name: test
+  user:
+    tokenFile: token
+`, srv.URL, base64.StdEncoding.EncodeToString(ca))), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newK8s := func(name string) K8s {
+		k := NewDefK8s(name, nil, util.Config{})
+		k.SetKubeconfig(kubeconfig)
+		return k
+	}
+
+	k := newK8s("default/deployment/web")
+	if ready, err := k.Ready(); err != nil || !ready {
+		t.Errorf("Ready of a rolled out deployment was incorrect, got: %v, %v, want: true.", ready, err)
+	}
+	if replicas, _ := k.Replicas(); replicas != 3 {
+		t.Errorf("Replicas of the deployment was incorrect, got: %d, want: 3.", replicas)
+	}
+
+	k = newK8s("kube-system/DaemonSet/kube-proxy")
+	if ready, err := k.Ready(); err != nil || ready {
+		t.Errorf("Ready of a daemonset with a pod not ready was incorrect, got: %v, %v, want: false.", ready, err)
+	}
+	if replicas, _ := k.Replicas(); replicas != 4 {
+		t.Errorf("Replicas of the daemonset was incorrect, got: %d, want: 4.", replicas)
+	}
+	if ready, _ := k.ReadyReplicas(); ready != 3 {
+		t.Errorf("ReadyReplicas of the daemonset was incorrect, got: %d, want: 3.", ready)
+	}
+
+	k = newK8s("default/pod/db-0")
+	if ready, err := k.Ready(); err != nil || !ready {
+		t.Errorf("Ready of a ready pod was incorrect, got: %v, %v, want: true.", ready, err)
+	}
+	if _, err := k.Replicas(); err == nil {
+		t.Errorf("Replicas of a pod should be an error")
+	}
+
+	if exists, err := newK8s("default/pod/missing").Exists(); err != nil || exists {
+		t.Errorf("Exists of a missing pod was incorrect, got: %v, %v, want: false.", exists, err)
+	}
+	for _, name := range []string{"default/web", "default/service/web"} {
+		if _, err := newK8s(name).Exists(); err == nil {
+			t.Errorf("Exists of %q should be an error", name)
+		}
+	}
+	k = newK8s("default/deployment/web")
+	k.SetContext("other")
+	if _, err := k.Exists(); err == nil {
+		t.Errorf("Exists with a context of a missing cluster should be an error")
+	}
+}
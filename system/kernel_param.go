@@ -1,6 +1,9 @@
 package system
 
 import (
+	"os"
+	"strings"
+
 	"github.com/achanda/go-sysctl"
 	"github.com/aelsabbahy/goss/util"
 )
@@ -9,6 +12,7 @@ type KernelParam interface {
 	Key() string
 	Exists() (bool, error)
 	Value() (string, error)
+	HostOnly() bool
 }
 
 type DefKernelParam struct {
@@ -40,3 +44,19 @@ func (k *DefKernelParam) Exists() (bool, error) {
 func (k *DefKernelParam) Value() (string, error) {
 	return sysctl.Get(k.key)
 }
+
+// namespacedKernelParams can be set for each container rather than the host
+var namespacedKernelParams = []string{
+	"kernel.domainname", "kernel.hostname", "kernel.msgmax", "kernel.msgmnb", "kernel.msgmni",
+	"kernel.sem", "kernel.shmall", "kernel.shmmax", "kernel.shmmni", "kernel.shm_rmid_forced",
+}
+
+// HostOnly reports whether the parameter is a setting of the host that
+// containers share, read only parameters such as kernel.ostype aren't settings
+func (k *DefKernelParam) HostOnly() bool {
+	if strings.HasPrefix(k.key, "net.") || strings.HasPrefix(k.key, "fs.mqueue.") || contains(namespacedKernelParams, k.key) {
+		return false
+	}
+	fi, err := os.Stat("/proc/sys/" + strings.Replace(k.key, ".", "/", -1))
+	return err == nil && fi.Mode().Perm()&0222 != 0
+}
@@ -0,0 +1,368 @@
+// +build !no_kv,!slim
+
+package system
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+type DefKV struct {
+	address       string
+	command       string
+	key           string
+	tls           bool
+	allowInsecure bool
+	caFile        string
+	clientCert    string
+	clientKey     string
+	username      string
+	password      string
+	timeout       int
+	loaded        bool
+	reachable     bool
+	err           error
+	response      string
+	info          map[string]string
+	latency       time.Duration
+}
+
+// kvProtocol runs command on conn, after authenticating when a password is
+// set, returning the response and, for info, its fields
+type kvProtocol func(conn *bufio.ReadWriter, kv *DefKV, db int) (string, map[string]string, error)
+
+var kvProtocols = map[string]kvProtocol{
+	"redis":     redisCommand,
+	"memcached": memcachedCommand,
+}
+
+func NewDefKV(address string, system *System, config util.Config) KV {
+	timeout := config.TimeOutMilliSeconds()
+	if timeout == 0 {
+		timeout = 5000
+	}
+	return &DefKV{
+		address:       address,
+		command:       "ping",
+		allowInsecure: config.AllowInsecure,
+		caFile:        config.CAFile,
+		clientCert:    config.ClientCert,
+		clientKey:     config.ClientKey,
+		username:      config.Username,
+		password:      config.Password,
+		timeout:       timeout,
+	}
+}
+
+func (k *DefKV) Address() string {
+	return k.address
+}
+
+// SetCommand sets what's run once connected: ping, info, or get of key
+func (k *DefKV) SetCommand(command, key string) {
+	if command != "" {
+		k.command = command
+	}
+	k.key = key
+}
+
+// SetTLS connects with TLS, verifying the server with the CA file of the
+// config unless insecure connections are allowed
+func (k *DefKV) SetTLS(enabled bool) {
+	k.tls = enabled
+}
+
+func (k *DefKV) setup() error {
+	if k.loaded {
+		return k.err
+	}
+	k.loaded = true
+
+	u, err := url.Parse(k.address)
+	if err != nil || u.Host == "" {
+		k.err = fmt.Errorf("kv address must be a URL such as redis://localhost:6379 or memcached://localhost:11211")
+		return k.err
+	}
+	protocol, ok := kvProtocols[u.Scheme]
+	if !ok {
+		k.err = fmt.Errorf("unknown kv scheme %q, must be redis or memcached", u.Scheme)
+		return k.err
+	}
+	db := 0
+	if p := strings.Trim(u.Path, "/"); p != "" {
+		if db, err = strconv.Atoi(p); err != nil || u.Scheme != "redis" {
+			k.err = fmt.Errorf("kv address %s: the path can only be the number of a redis database", k.address)
+			return k.err
+		}
+	}
+	if k.command != "ping" && k.command != "info" && k.command != "get" {
+		k.err = fmt.Errorf("unknown kv command %q, must be ping, info or get", k.command)
+		return k.err
+	}
+	if k.command == "get" && k.key == "" {
+		k.err = fmt.Errorf("kv %s: key is required to get", k.address)
+		return k.err
+	}
+
+	timeout := time.Duration(k.timeout) * time.Millisecond
+	startTime := time.Now()
+	var conn net.Conn
+	if k.tls {
+		tlsConfig, err := newTLSConfig(k.allowInsecure, k.caFile, k.clientCert, k.clientKey)
+		if err != nil {
+			k.err = err
+			return k.err
+		}
+		tlsConfig.ServerName = u.Hostname()
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", u.Host, tlsConfig)
+		if err != nil {
+			k.err = err
+			return k.err
+		}
+	} else {
+		if conn, err = net.DialTimeout("tcp", u.Host, timeout); err != nil {
+			k.err = err
+			return k.err
+		}
+	}
+	defer conn.Close()
+	k.reachable = true
+	if err := conn.SetDeadline(startTime.Add(timeout)); err != nil {
+		k.err = err
+		return k.err
+	}
+
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+	k.response, k.info, k.err = protocol(rw, k, db)
+	k.latency = time.Since(startTime)
+	return k.err
+}
+
+// Exists reports whether the server accepted the connection
+func (k *DefKV) Exists() (bool, error) { return k.Reachable() }
+
+// Reachable reports whether the server accepted the connection, even when
+// running the command failed
+func (k *DefKV) Reachable() (bool, error) {
+	if err := k.setup(); err != nil && !k.reachable {
+		if _, ok := err.(net.Error); ok {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Response is the reply to the command, PONG to a redis ping, the version
+// to a memcached one, the value of the key to get, and all of info
+func (k *DefKV) Response() (string, error) {
+	if err := k.setup(); err != nil {
+		return "", err
+	}
+	return k.response, nil
+}
+
+// Info is the value of field in the reply to info, INFO for redis and stats
+// for memcached
+func (k *DefKV) Info(field string) (string, error) {
+	if err := k.setup(); err != nil {
+		return "", err
+	}
+	if k.command != "info" {
+		return "", fmt.Errorf("kv %s: info fields need the info command", k.address)
+	}
+	v, ok := k.info[field]
+	if !ok {
+		return "", fmt.Errorf("info has no field %q", field)
+	}
+	return v, nil
+}
+
+// Latency is the time in milliseconds from connecting until the response
+// was received
+func (k *DefKV) Latency() (int, error) {
+	if err := k.setup(); err != nil {
+		return 0, err
+	}
+	return int(k.latency / time.Millisecond), nil
+}
+
+func redisCommand(rw *bufio.ReadWriter, k *DefKV, db int) (string, map[string]string, error) {
+	if k.password != "" {
+		args := []string{"AUTH", k.password}
+		if k.username != "" {
+			args = []string{"AUTH", k.username, k.password}
+		}
+		if _, err := redisCall(rw, args...); err != nil {
+			return "", nil, fmt.Errorf("redis auth: %v", err)
+		}
+	}
+	if db != 0 {
+		if _, err := redisCall(rw, "SELECT", strconv.Itoa(db)); err != nil {
+			return "", nil, fmt.Errorf("redis select %d: %v", db, err)
+		}
+	}
+	switch k.command {
+	case "info":
+		reply, err := redisCall(rw, "INFO")
+		if err != nil {
+			return "", nil, err
+		}
+		info := make(map[string]string)
+		for _, line := range strings.Split(reply, "\n") {
+			line = strings.TrimSuffix(line, "\r")
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if kv := strings.SplitN(line, ":", 2); len(kv) == 2 {
+				info[kv[0]] = kv[1]
+			}
+		}
+		return reply, info, nil
+	case "get":
+		reply, err := redisCall(rw, "GET", k.key)
+		return reply, nil, err
+	default:
+		reply, err := redisCall(rw, "PING")
+		return reply, nil, err
+	}
+}
+
+// redisCall sends args as a RESP array and reads a simple, bulk or integer
+// reply
+func redisCall(rw *bufio.ReadWriter, args ...string) (string, error) {
+	fmt.Fprintf(rw, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(rw, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if err := rw.Flush(); err != nil {
+		return "", err
+	}
+	line, err := readKVLine(rw)
+	if err != nil {
+		return "", err
+	}
+	if line == "" {
+		return "", fmt.Errorf("empty redis reply")
+	}
+	switch line[0] {
+	case '+', ':':
+		return line[1:], nil
+	case '-':
+		return "", fmt.Errorf("%s", line[1:])
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return "", fmt.Errorf("invalid redis reply %q", line)
+		}
+		if n < 0 {
+			return "", fmt.Errorf("key %q doesn't exist", args[len(args)-1])
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(rw, buf); err != nil {
+			return "", err
+		}
+		return string(buf[:n]), nil
+	default:
+		return "", fmt.Errorf("unexpected redis reply %q", line)
+	}
+}
+
+func memcachedCommand(rw *bufio.ReadWriter, k *DefKV, db int) (string, map[string]string, error) {
+	if k.password != "" {
+		// The ASCII protocol authenticates with a set of "username password"
+		creds := k.username + " " + k.password
+		fmt.Fprintf(rw, "set auth 0 0 %d\r\n%s\r\n", len(creds), creds)
+		line, err := memcachedCall(rw)
+		if err != nil {
+			return "", nil, fmt.Errorf("memcached auth: %v", err)
+		}
+		if line != "STORED" {
+			return "", nil, fmt.Errorf("memcached auth: %s", line)
+		}
+	}
+	switch k.command {
+	case "info":
+		fmt.Fprint(rw, "stats\r\n")
+		var lines []string
+		info := make(map[string]string)
+		for {
+			line, err := memcachedCall(rw)
+			if err != nil {
+				return "", nil, err
+			}
+			if line == "END" {
+				return strings.Join(lines, "\n"), info, nil
+			}
+			lines = append(lines, line)
+			if f := strings.SplitN(line, " ", 3); len(f) == 3 && f[0] == "STAT" {
+				info[f[1]] = f[2]
+			}
+		}
+	case "get":
+		fmt.Fprintf(rw, "get %s\r\n", k.key)
+		line, err := memcachedCall(rw)
+		if err != nil {
+			return "", nil, err
+		}
+		if line == "END" {
+			return "", nil, fmt.Errorf("key %q doesn't exist", k.key)
+		}
+		f := strings.Fields(line)
+		if len(f) < 4 || f[0] != "VALUE" {
+			return "", nil, fmt.Errorf("unexpected memcached reply %q", line)
+		}
+		n, err := strconv.Atoi(f[3])
+		if err != nil {
+			return "", nil, fmt.Errorf("unexpected memcached reply %q", line)
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(rw, buf); err != nil {
+			return "", nil, err
+		}
+		if end, err := readKVLine(rw); err != nil || end != "END" {
+			return "", nil, fmt.Errorf("unexpected memcached reply after the value")
+		}
+		return string(buf[:n]), nil, nil
+	default:
+		fmt.Fprint(rw, "version\r\n")
+		line, err := memcachedCall(rw)
+		if err != nil {
+			return "", nil, err
+		}
+		return strings.TrimPrefix(line, "VERSION "), nil, nil
+	}
+}
+
+// memcachedCall flushes the pending command and reads a line of the reply,
+// error replies are returned as errors
+func memcachedCall(rw *bufio.ReadWriter) (string, error) {
+	if err := rw.Flush(); err != nil {
+		return "", err
+	}
+	line, err := readKVLine(rw)
+	if err != nil {
+		return "", err
+	}
+	if line == "ERROR" || strings.HasPrefix(line, "CLIENT_ERROR") || strings.HasPrefix(line, "SERVER_ERROR") {
+		return "", fmt.Errorf("%s", line)
+	}
+	return line, nil
+}
+
+func readKVLine(rw *bufio.ReadWriter) (string, error) {
+	line, err := rw.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), nil
+}
@@ -0,0 +1,24 @@
+// +build no_kv slim
+
+package system
+
+import (
+	"github.com/aelsabbahy/goss/util"
+)
+
+type disabledKV struct {
+	address string
+}
+
+func NewDefKV(address string, system *System, config util.Config) KV {
+	return &disabledKV{address: address}
+}
+
+func (k *disabledKV) Address() string                   { return k.address }
+func (k *disabledKV) Exists() (bool, error)             { return false, errNotBuiltIn("kv") }
+func (k *disabledKV) SetCommand(command, key string)    {}
+func (k *disabledKV) SetTLS(bool)                       {}
+func (k *disabledKV) Reachable() (bool, error)          { return false, errNotBuiltIn("kv") }
+func (k *disabledKV) Response() (string, error)         { return "", errNotBuiltIn("kv") }
+func (k *disabledKV) Info(field string) (string, error) { return "", errNotBuiltIn("kv") }
+func (k *disabledKV) Latency() (int, error)             { return 0, errNotBuiltIn("kv") }
@@ -0,0 +1,176 @@
+// +build !no_kv,!slim
+
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// serveKV answers the commands of every connection to a local listener with
+// reply, commands are the redis arguments or the memcached line
+func serveKV(t *testing.T, redis bool, reply func(cmd []string) string) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					line = strings.TrimRight(line, "\r\n")
+					var cmd []string
+					if redis {
+						n, _ := strconv.Atoi(strings.TrimPrefix(line, "*"))
+						for i := 0; i < n; i++ {
+							r.ReadString('\n')
+							arg, _ := r.ReadString('\n')
+							cmd = append(cmd, strings.TrimRight(arg, "\r\n"))
+						}
+					} else {
+						cmd = strings.Fields(line)
+						if len(cmd) > 0 && cmd[0] == "set" {
+							data, _ := r.ReadString('\n')
+							cmd = append(cmd, strings.TrimRight(data, "\r\n"))
+						}
+					}
+					fmt.Fprint(conn, reply(cmd))
+				}
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestDefKVRedis(t *testing.T) {
+	addr := serveKV(t, true, func(cmd []string) string {
+		switch strings.Join(cmd, " ") {
+		case "AUTH goss secret":
+			return "+OK\r\n"
+		case "SELECT 2":
+			return "+OK\r\n"
+		case "PING":
+			return "+PONG\r\n"
+		case "INFO":
+			info := "# Replication\r\nrole:master\r\nconnected_slaves:0\r\n"
+			return fmt.Sprintf("$%d\r\n%s\r\n", len(info), info)
+		case "GET greeting":
+			return "$5\r\nhello\r\n"
+		case "GET missing":
+			return "$-1\r\n"
+		}
+		if cmd[0] == "AUTH" {
+			return "-WRONGPASS invalid username-password pair\r\n"
+		}
+		return "-ERR unknown command\r\n"
+	})
+	config := util.Config{Username: "goss", Password: "secret", Timeout: time.Second}
+
+	kv := NewDefKV("redis://"+addr+"/2", nil, config)
+	if got, err := kv.Response(); err != nil || got != "PONG" {
+		t.Errorf("ping: got %q, %v", got, err)
+	}
+	if got, err := kv.Latency(); err != nil || got < 0 {
+		t.Errorf("latency: got %d, %v", got, err)
+	}
+
+	kv = NewDefKV("redis://"+addr, nil, config)
+	kv.SetCommand("info", "")
+	if got, err := kv.Info("role"); err != nil || got != "master" {
+		t.Errorf("info role: got %q, %v", got, err)
+	}
+	if _, err := kv.Info("nope"); err == nil {
+		t.Error("info nope: want an error")
+	}
+
+	kv = NewDefKV("redis://"+addr, nil, config)
+	kv.SetCommand("get", "greeting")
+	if got, err := kv.Response(); err != nil || got != "hello" {
+		t.Errorf("get: got %q, %v", got, err)
+	}
+	kv = NewDefKV("redis://"+addr, nil, config)
+	kv.SetCommand("get", "missing")
+	if _, err := kv.Response(); err == nil || !strings.Contains(err.Error(), "doesn't exist") {
+		t.Errorf("get missing: got %v", err)
+	}
+
+	kv = NewDefKV("redis://"+addr, nil, util.Config{Password: "wrong", Timeout: time.Second})
+	_, err := kv.Response()
+	if err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Errorf("wrong password: got %v", err)
+	}
+	if reachable, err := kv.Reachable(); !reachable || err != nil {
+		t.Errorf("wrong password: got reachable %v, %v, want reachable", reachable, err)
+	}
+
+	l, _ := net.Listen("tcp", "127.0.0.1:0")
+	closed := l.Addr().String()
+	l.Close()
+	if reachable, err := NewDefKV("redis://"+closed, nil, config).Reachable(); reachable || err != nil {
+		t.Errorf("closed port: got reachable %v, %v", reachable, err)
+	}
+}
+
+func TestDefKVMemcached(t *testing.T) {
+	addr := serveKV(t, false, func(cmd []string) string {
+		switch strings.Join(cmd, " ") {
+		case "set auth 0 0 11 goss secret":
+			return "STORED\r\n"
+		case "version":
+			return "VERSION 1.6.9\r\n"
+		case "stats":
+			return "STAT pid 1\r\nSTAT curr_connections 2\r\nEND\r\n"
+		case "get greeting":
+			return "VALUE greeting 0 5\r\nhello\r\nEND\r\n"
+		case "get missing":
+			return "END\r\n"
+		}
+		return "CLIENT_ERROR authentication failure\r\n"
+	})
+	config := util.Config{Username: "goss", Password: "secret", Timeout: time.Second}
+
+	kv := NewDefKV("memcached://"+addr, nil, config)
+	if got, err := kv.Response(); err != nil || got != "1.6.9" {
+		t.Errorf("ping: got %q, %v", got, err)
+	}
+	kv = NewDefKV("memcached://"+addr, nil, config)
+	kv.SetCommand("info", "")
+	if got, err := kv.Info("curr_connections"); err != nil || got != "2" {
+		t.Errorf("stats: got %q, %v", got, err)
+	}
+	kv = NewDefKV("memcached://"+addr, nil, config)
+	kv.SetCommand("get", "greeting")
+	if got, err := kv.Response(); err != nil || got != "hello" {
+		t.Errorf("get: got %q, %v", got, err)
+	}
+	kv = NewDefKV("memcached://"+addr, nil, config)
+	kv.SetCommand("get", "missing")
+	if _, err := kv.Response(); err == nil {
+		t.Error("get missing: want an error")
+	}
+	kv = NewDefKV("memcached://"+addr, nil, util.Config{Password: "wrong", Timeout: time.Second})
+	if _, err := kv.Response(); err == nil || !strings.Contains(err.Error(), "authentication failure") {
+		t.Errorf("wrong password: got %v", err)
+	}
+	if _, err := NewDefKV("memcached://"+addr+"/1", nil, config).Response(); err == nil {
+		t.Error("memcached database: want an error")
+	}
+}
@@ -0,0 +1,146 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// MAC is the state of the mandatory access control systems, SELinux and
+// AppArmor, the only name is "system"
+type MAC interface {
+	Name() string
+	Exists() (bool, error)
+	SELinux() (string, error)
+	SELinuxPolicy() (string, error)
+	AppArmor() (bool, error)
+	AppArmorProfiles() (map[string]string, error)
+}
+
+type DefMAC struct {
+	name string
+}
+
+var (
+	selinuxDir       = "/sys/fs/selinux"
+	selinuxConfig    = "/etc/selinux/config"
+	apparmorEnabled  = "/sys/module/apparmor/parameters/enabled"
+	apparmorProfiles = "/sys/kernel/security/apparmor/profiles"
+)
+
+func NewDefMAC(name string, system *System, config util.Config) MAC {
+	return &DefMAC{name: name}
+}
+
+func (m *DefMAC) Name() string {
+	return m.name
+}
+
+func (m *DefMAC) Exists() (bool, error) {
+	return m.name == "system", nil
+}
+
+func (m *DefMAC) check() error {
+	if m.name != "system" {
+		return fmt.Errorf("unknown mac %q, the only one is system", m.name)
+	}
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("mac is not supported on %s", runtime.GOOS)
+	}
+	return nil
+}
+
+// SELinux is the mode SELinux runs in, enforcing, permissive or disabled
+func (m *DefMAC) SELinux() (string, error) {
+	if err := m.check(); err != nil {
+		return "", err
+	}
+	data, err := ioutil.ReadFile(filepath.Join(selinuxDir, "enforce"))
+	if os.IsNotExist(err) {
+		return "disabled", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	switch strings.TrimSpace(string(data)) {
+	case "1":
+		return "enforcing", nil
+	case "0":
+		return "permissive", nil
+	}
+	return "", fmt.Errorf("unexpected SELinux enforce value %q", strings.TrimSpace(string(data)))
+}
+
+// SELinuxPolicy is the type of the SELinux policy configured, such as
+// targeted, empty when SELinux isn't configured
+func (m *DefMAC) SELinuxPolicy() (string, error) {
+	if err := m.check(); err != nil {
+		return "", err
+	}
+	f, err := os.Open(selinuxConfig)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	policy := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "SELINUXTYPE=") {
+			policy = strings.Trim(strings.TrimPrefix(line, "SELINUXTYPE="), `"'`)
+		}
+	}
+	return policy, scanner.Err()
+}
+
+// AppArmor reports whether AppArmor is enabled in the kernel
+func (m *DefMAC) AppArmor() (bool, error) {
+	if err := m.check(); err != nil {
+		return false, err
+	}
+	data, err := ioutil.ReadFile(apparmorEnabled)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(data)) == "Y", nil
+}
+
+// AppArmorProfiles maps the loaded AppArmor profiles to their mode, such as
+// enforce or complain
+func (m *DefMAC) AppArmorProfiles() (map[string]string, error) {
+	if err := m.check(); err != nil {
+		return nil, err
+	}
+	profiles := make(map[string]string)
+	f, err := os.Open(apparmorProfiles)
+	if os.IsNotExist(err) {
+		return profiles, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Lines are "name (mode)", names can contain spaces
+		line := scanner.Text()
+		i := strings.LastIndex(line, " (")
+		if i < 0 || !strings.HasSuffix(line, ")") {
+			continue
+		}
+		profiles[line[:i]] = line[i+2 : len(line)-1]
+	}
+	return profiles, scanner.Err()
+}
@@ -0,0 +1,69 @@
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+func TestMAC(t *testing.T) {
+	root, err := ioutil.TempDir("", "goss-mac")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	defer func(d, c, e, p string) { selinuxDir, selinuxConfig, apparmorEnabled, apparmorProfiles = d, c, e, p }(selinuxDir, selinuxConfig, apparmorEnabled, apparmorProfiles)
+	selinuxDir = filepath.Join(root, "selinux")
+	selinuxConfig = filepath.Join(root, "config")
+	apparmorEnabled = filepath.Join(root, "enabled")
+	apparmorProfiles = filepath.Join(root, "profiles")
+
+	mac := NewDefMAC("system", nil, util.Config{})
+	if mode, err := mac.SELinux(); mode != "disabled" || err != nil {
+		t.Errorf("SELinux without selinuxfs was incorrect, got: %q, %v, want: disabled.", mode, err)
+	}
+	if aa, err := mac.AppArmor(); aa || err != nil {
+		t.Errorf("AppArmor without the module was incorrect, got: %v, %v, want: false.", aa, err)
+	}
+
+	files := map[string]string{
+		"selinux/enforce": "0\n",
+		"config":          "# comment\nSELINUX=permissive\nSELINUXTYPE=targeted\n",
+		"enabled":         "Y\n",
+		"profiles":        "/usr/sbin/cupsd (enforce)\nlibreoffice oopslash (complain)\ndocker-default (enforce)\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if mode, _ := mac.SELinux(); mode != "permissive" {
+		t.Errorf("SELinux was incorrect, got: %q, want: permissive.", mode)
+	}
+	if policy, _ := mac.SELinuxPolicy(); policy != "targeted" {
+		t.Errorf("SELinuxPolicy was incorrect, got: %q, want: targeted.", policy)
+	}
+	if aa, _ := mac.AppArmor(); !aa {
+		t.Errorf("AppArmor was incorrect, got: false, want: true.")
+	}
+	want := map[string]string{
+		"/usr/sbin/cupsd":      "enforce",
+		"libreoffice oopslash": "complain",
+		"docker-default":       "enforce",
+	}
+	if profiles, err := mac.AppArmorProfiles(); !reflect.DeepEqual(profiles, want) || err != nil {
+		t.Errorf("AppArmorProfiles was incorrect, got: %v, %v, want: %v.", profiles, err, want)
+	}
+
+	if _, err := NewDefMAC("other", nil, util.Config{}).SELinux(); err == nil {
+		t.Errorf("SELinux of an unknown name should fail")
+	}
+}
@@ -2,6 +2,7 @@ package system
 
 import (
 	"fmt"
+	"math"
 	"strings"
 
 	"github.com/aelsabbahy/goss/util"
@@ -15,6 +16,7 @@ type Mount interface {
 	Source() (string, error)
 	Filesystem() (string, error)
 	Usage() (int, error)
+	InodeUsage() (int, error)
 }
 
 type DefMount struct {
@@ -23,6 +25,7 @@ type DefMount struct {
 	exists     bool
 	mountInfo  *mount.Info
 	usage      int
+	inodeUsage int
 	err        error
 }
 
@@ -47,12 +50,13 @@ func (m *DefMount) setup() error {
 	m.mountInfo = mountInfo
 	m.exists = true
 
-	usage, err := getUsage(m.mountPoint)
+	usage, inodeUsage, err := getUsage(m.mountPoint)
 	if err != nil {
 		m.err = err
 		return m.err
 	}
 	m.usage = usage
+	m.inodeUsage = inodeUsage
 
 	return nil
 }
@@ -105,6 +109,15 @@ func (m *DefMount) Usage() (int, error) {
 	return m.usage, nil
 }
 
+// InodeUsage is the percentage of inodes in use
+func (m *DefMount) InodeUsage() (int, error) {
+	if err := m.setup(); err != nil {
+		return -1, err
+	}
+
+	return m.inodeUsage, nil
+}
+
 func getMount(mountpoint string) (*mount.Info, error) {
 	entries, err := mount.GetMounts()
 	if err != nil {
@@ -119,3 +132,11 @@ func getMount(mountpoint string) (*mount.Info, error) {
 	}
 	return nil, fmt.Errorf("Mountpoint not found")
 }
+
+func percentUsed(free, total uint64) int {
+	if total == 0 {
+		return 0
+	}
+	percentageFree := float64(free) / float64(total)
+	return int(math.Round((1 - percentageFree) * 100))
+}
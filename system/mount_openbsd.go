@@ -0,0 +1,19 @@
+// +build openbsd
+
+package system
+
+import (
+	"syscall"
+)
+
+// getUsage returns the percentage of blocks and inodes in use
+func getUsage(mountpoint string) (int, int, error) {
+	statfsOut := &syscall.Statfs_t{}
+	err := syscall.Statfs(mountpoint, statfsOut)
+	if err != nil {
+		return -1, -1, err
+	}
+
+	return percentUsed(statfsOut.F_bfree, statfsOut.F_blocks),
+		percentUsed(statfsOut.F_ffree, statfsOut.F_files), nil
+}
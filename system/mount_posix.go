@@ -1,21 +1,21 @@
-// +build linux darwin !windows
+//go:build linux || darwin || (!windows && !openbsd && !solaris)
+// +build linux darwin !windows,!openbsd,!solaris
 
 package system
 
 import (
-	"math"
 	"syscall"
 )
 
-func getUsage(mountpoint string) (int, error) {
+// getUsage returns the percentage of blocks and inodes in use, file systems
+// without a fixed number of them, such as btrfs for inodes, report 0
+func getUsage(mountpoint string) (int, int, error) {
 	statfsOut := &syscall.Statfs_t{}
 	err := syscall.Statfs(mountpoint, statfsOut)
 	if err != nil {
-		return -1, err
+		return -1, -1, err
 	}
 
-	percentageFree := float64(statfsOut.Bfree) / float64(statfsOut.Blocks)
-	usage := math.Round((1 - percentageFree) * 100)
-
-	return int(usage), nil
+	return percentUsed(uint64(statfsOut.Bfree), uint64(statfsOut.Blocks)),
+		percentUsed(uint64(statfsOut.Ffree), uint64(statfsOut.Files)), nil
 }
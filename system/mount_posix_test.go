@@ -0,0 +1,48 @@
+//go:build linux || darwin || (!windows && !openbsd && !solaris)
+// +build linux darwin !windows,!openbsd,!solaris
+
+package system
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestPercentUsed(t *testing.T) {
+	tests := []struct {
+		free, total uint64
+		want        int
+	}{
+		{0, 100, 100},
+		{100, 100, 0},
+		{25, 100, 75},
+		{1, 3, 67},
+		// btrfs reports no inodes
+		{0, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := percentUsed(tt.free, tt.total); got != tt.want {
+			t.Errorf("percentUsed(%d, %d): got %d, want %d", tt.free, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestGetUsage(t *testing.T) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs("/", &st); err != nil {
+		t.Skip(err)
+	}
+	usage, inodeUsage, err := getUsage("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := percentUsed(uint64(st.Bfree), uint64(st.Blocks)); usage != want {
+		t.Errorf("usage: got %d, want %d", usage, want)
+	}
+	if want := percentUsed(uint64(st.Ffree), uint64(st.Files)); inodeUsage != want {
+		t.Errorf("inode usage: got %d, want %d", inodeUsage, want)
+	}
+	if _, _, err := getUsage("/does/not/exist"); err == nil {
+		t.Error("missing mount point: got no error")
+	}
+}
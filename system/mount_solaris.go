@@ -0,0 +1,9 @@
+// +build solaris
+
+package system
+
+import "errors"
+
+func getUsage(mountpoint string) (int, int, error) {
+	return 0, 0, errors.New("Not implemented")
+}
@@ -4,6 +4,6 @@ package system
 
 import "errors"
 
-func getUsage(mountpoint string) (int, error) {
-	return 0, errors.New("Not implemented")
+func getUsage(mountpoint string) (int, int, error) {
+	return 0, 0, errors.New("Not implemented")
 }
@@ -0,0 +1,59 @@
+package system
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// netnsDir is where ip netns creates the named network namespaces, and
+// netnsEtcDir where it keeps the files it bind mounts over /etc in them
+var (
+	netnsDir    = "/var/run/netns"
+	netnsEtcDir = "/etc/netns"
+)
+
+// netnsName is name, or the network namespace of the run of system when it's
+// empty
+func netnsName(name string, system *System) string {
+	if name == "" && system != nil {
+		return system.Netns
+	}
+	return name
+}
+
+// netnsPath is the file of the network namespace name, a name with a / is a
+// path such as /proc/<pid>/ns/net
+func netnsPath(name string) string {
+	if strings.Contains(name, "/") {
+		return name
+	}
+	return filepath.Join(netnsDir, name)
+}
+
+// netnsServer is the first nameserver of the resolv.conf of the network
+// namespace name, /etc/netns/<name>/resolv.conf like ip netns exec, or
+// otherwise /etc/resolv.conf
+func netnsServer(name string) (string, error) {
+	conf := filepath.Join(netnsEtcDir, name, "resolv.conf")
+	if _, err := os.Stat(conf); strings.Contains(name, "/") || err != nil {
+		conf = "/etc/resolv.conf"
+	}
+	return resolvConfServer(conf)
+}
+
+// resolvConfServer is the first nameserver of the resolv.conf formatted conf
+func resolvConfServer(conf string) (string, error) {
+	config, err := dns.ClientConfigFromFile(conf)
+	if err != nil {
+		return "", err
+	}
+	if len(config.Servers) == 0 {
+		return "", fmt.Errorf("no nameservers found in %s", conf)
+	}
+	return net.JoinHostPort(config.Servers[0], config.Port), nil
+}
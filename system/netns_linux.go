@@ -0,0 +1,66 @@
+//go:build (linux && amd64) || (linux && arm64)
+// +build linux,amd64 linux,arm64
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"syscall"
+)
+
+const cloneNewNet = 0x40000000
+
+// ValidateNetns checks the network namespace name exists, empty for the
+// namespace of goss
+func ValidateNetns(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, err := os.Stat(netnsPath(name)); err != nil {
+		return fmt.Errorf("netns %s: %v", name, err)
+	}
+	return nil
+}
+
+// inNetns runs f on a thread in the network namespace name, so the sockets f
+// opens are in it. Goroutines f starts aren't, so f can't resolve host names
+// or dial more than one address. An empty name runs f as is.
+func inNetns(name string, f func() error) error {
+	if name == "" {
+		return f()
+	}
+	target, err := os.Open(netnsPath(name))
+	if err != nil {
+		return fmt.Errorf("netns %s: %v", name, err)
+	}
+	defer target.Close()
+
+	runtime.LockOSThread()
+	origin, err := os.Open(fmt.Sprintf("/proc/self/task/%d/ns/net", syscall.Gettid()))
+	if err != nil {
+		runtime.UnlockOSThread()
+		return fmt.Errorf("netns %s: %v", name, err)
+	}
+	defer origin.Close()
+	if err := setns(target.Fd()); err != nil {
+		runtime.UnlockOSThread()
+		return fmt.Errorf("netns %s: %v", name, err)
+	}
+	ferr := f()
+	if err := setns(origin.Fd()); err != nil {
+		// The thread stays locked so it exits with the goroutine rather
+		// than running others in the namespace
+		return fmt.Errorf("netns %s: leaving: %v", name, err)
+	}
+	runtime.UnlockOSThread()
+	return ferr
+}
+
+func setns(fd uintptr) error {
+	if _, _, errno := syscall.RawSyscall(sysSetns, fd, cloneNewNet, 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
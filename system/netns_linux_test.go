@@ -0,0 +1,87 @@
+// +build linux,amd64 linux,arm64
+
+package system
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+	"github.com/miekg/dns"
+)
+
+func TestNetns(t *testing.T) {
+	name := fmt.Sprintf("goss-test-%d", os.Getpid())
+	if out, err := exec.Command("ip", "netns", "add", name).CombinedOutput(); err != nil {
+		t.Skipf("can't create a network namespace: %v %s", err, out)
+	}
+	defer exec.Command("ip", "netns", "del", name).Run()
+	if out, err := exec.Command("ip", "-n", name, "link", "set", "lo", "up").CombinedOutput(); err != nil {
+		t.Fatalf("%v %s", err, out)
+	}
+
+	// The listener and DNS server are only in the namespace
+	var l net.Listener
+	var pc net.PacketConn
+	err := inNetns(name, func() (err error) {
+		if l, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+			return err
+		}
+		pc, err = net.ListenPacket("udp", "127.0.0.1:0")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go http.Serve(l, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server := &dns.Server{PacketConn: pc, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, q *dns.Msg) {
+		resp := new(dns.Msg)
+		resp.SetReply(q)
+		if q.Question[0].Qtype == dns.TypeA {
+			resp.Answer = append(resp.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: q.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+				A:   net.ParseIP("127.0.0.1"),
+			})
+		}
+		w.WriteMsg(resp)
+	})}
+	go server.ActivateAndServe()
+	defer server.Shutdown()
+
+	config := util.Config{Timeout: time.Second}
+	sys := &System{Netns: name}
+	if reachable, _ := NewDefAddr("tcp://"+l.Addr().String(), sys, config).Reachable(); !reachable {
+		t.Error("addr in the network namespace isn't reachable")
+	}
+	if reachable, _ := NewDefAddr("tcp://"+l.Addr().String(), nil, config).Reachable(); reachable {
+		t.Error("addr in the network namespace is reachable outside of it")
+	}
+
+	resolver, err := NewResolver(pc.LocalAddr().String(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sys.Resolver = resolver
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	if reachable, _ := NewDefAddr("tcp://dynamic.goss.test:"+port, sys, config).Reachable(); !reachable {
+		t.Error("addr resolved through the DNS server in the network namespace isn't reachable")
+	}
+	if status, err := NewDefHTTP("http://dynamic.goss.test:"+port, sys, config).Status(); err != nil || status != 200 {
+		t.Errorf("http in the network namespace: got %d, %v", status, err)
+	}
+	addrs, err := NewDefDNS("A:dynamic.goss.test", sys, config).Addrs()
+	if err != nil || !reflect.DeepEqual(addrs, []string{"127.0.0.1"}) {
+		t.Errorf("dns in the network namespace: got %v, %v", addrs, err)
+	}
+
+	if err := ValidateNetns(name + "-missing"); err == nil {
+		t.Error("a missing network namespace must be an error")
+	}
+}
@@ -0,0 +1,21 @@
+// +build !linux !amd64,!arm64
+
+package system
+
+import "fmt"
+
+// ValidateNetns always returns an error for a network namespace, they're
+// only implemented on Linux amd64 and arm64
+func ValidateNetns(name string) error {
+	if name == "" {
+		return nil
+	}
+	return fmt.Errorf("netns %s: network namespaces are only supported on linux", name)
+}
+
+func inNetns(name string, f func() error) error {
+	if err := ValidateNetns(name); err != nil {
+		return err
+	}
+	return f()
+}
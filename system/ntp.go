@@ -0,0 +1,324 @@
+package system
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// NTP is the time synchronization of the clock as reported by chronyd, ntpd
+// or systemd-timesyncd, the only name is "system"
+type NTP interface {
+	Name() string
+	Exists() (bool, error)
+	SetDaemon(string)
+	Daemon() (string, error)
+	Synchronized() (bool, error)
+	Offset() (float64, error)
+	ReachableSources() (int, error)
+}
+
+type DefNTP struct {
+	name    string
+	daemon  string
+	timeout int
+	loaded  bool
+	err     error
+	status  ntpStatus
+}
+
+// ntpStatus is what a daemon reports, offset is the absolute offset of the
+// clock in milliseconds
+type ntpStatus struct {
+	daemon       string
+	synchronized bool
+	offset       float64
+	hasOffset    bool
+	reachable    int
+}
+
+// ntpDaemon reads the status of a daemon with its command line tool
+type ntpDaemon struct {
+	name    string
+	command string
+	read    func(timeout int) (ntpStatus, error)
+}
+
+// ntpDaemons are tried in order when the daemon isn't set, timedatectl is
+// last as it's installed with systemd whichever daemon runs
+var ntpDaemons = []ntpDaemon{
+	{name: "chronyd", command: "chronyc", read: readChrony},
+	{name: "ntpd", command: "ntpq", read: readNtpd},
+	{name: "systemd-timesyncd", command: "timedatectl", read: readTimesyncd},
+}
+
+func NewDefNTP(name string, system *System, config util.Config) NTP {
+	timeout := config.TimeOutMilliSeconds()
+	if timeout == 0 {
+		timeout = 5000
+	}
+	return &DefNTP{name: name, timeout: timeout}
+}
+
+func (n *DefNTP) Name() string {
+	return n.name
+}
+
+func (n *DefNTP) Exists() (bool, error) {
+	return n.name == "system", nil
+}
+
+// SetDaemon sets the daemon to read, chronyd, ntpd or systemd-timesyncd,
+// the first one answering is read when it's empty
+func (n *DefNTP) SetDaemon(daemon string) {
+	n.daemon = daemon
+}
+
+func (n *DefNTP) setup() error {
+	if n.loaded {
+		return n.err
+	}
+	n.loaded = true
+
+	if n.name != "system" {
+		n.err = fmt.Errorf("unknown ntp %q, the only one is system", n.name)
+		return n.err
+	}
+	var daemons, names []string
+	for _, d := range ntpDaemons {
+		names = append(names, d.name)
+	}
+	var errs []string
+	for _, d := range ntpDaemons {
+		if n.daemon != "" && d.name != n.daemon {
+			continue
+		}
+		daemons = append(daemons, d.name)
+		if !HasCommand(d.command) {
+			continue
+		}
+		status, err := d.read(n.timeout)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		status.daemon = d.name
+		n.status = status
+		return nil
+	}
+	switch {
+	case len(daemons) == 0:
+		n.err = fmt.Errorf("unknown ntp daemon %q, must be one of %s", n.daemon, strings.Join(names, ", "))
+	case len(errs) == 0:
+		n.err = util.NewCodedError(util.ErrCodeCommandNotFound, fmt.Errorf("none of the tools of %s are installed", strings.Join(daemons, ", ")))
+	default:
+		n.err = fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return n.err
+}
+
+// Daemon is the daemon the status is read from
+func (n *DefNTP) Daemon() (string, error) {
+	if err := n.setup(); err != nil {
+		return "", err
+	}
+	return n.status.daemon, nil
+}
+
+func (n *DefNTP) Synchronized() (bool, error) {
+	if err := n.setup(); err != nil {
+		return false, err
+	}
+	return n.status.synchronized, nil
+}
+
+// Offset is the absolute offset of the clock from the time of its sources in
+// milliseconds
+func (n *DefNTP) Offset() (float64, error) {
+	if err := n.setup(); err != nil {
+		return 0, err
+	}
+	if !n.status.hasOffset {
+		return 0, fmt.Errorf("%s doesn't report an offset, the clock isn't synchronized", n.status.daemon)
+	}
+	return n.status.offset, nil
+}
+
+// ReachableSources is the number of time sources that answered recently
+func (n *DefNTP) ReachableSources() (int, error) {
+	if err := n.setup(); err != nil {
+		return 0, err
+	}
+	return n.status.reachable, nil
+}
+
+func ntpCommand(timeout int, name string, args ...string) (string, error) {
+	cmd := util.NewCommand(name, args...)
+	if err := runCommand(cmd, timeout); err != nil {
+		if msg := strings.TrimSpace(cmd.Stderr.String() + cmd.Stdout.String()); msg != "" {
+			err = fmt.Errorf("%s: %s", name, msg)
+		}
+		return "", err
+	}
+	return cmd.Stdout.String(), nil
+}
+
+func readChrony(timeout int) (ntpStatus, error) {
+	tracking, err := ntpCommand(timeout, "chronyc", "-n", "-c", "tracking")
+	if err != nil {
+		return ntpStatus{}, err
+	}
+	status, err := parseChronyTracking(tracking)
+	if err != nil {
+		return ntpStatus{}, err
+	}
+	sources, err := ntpCommand(timeout, "chronyc", "-n", "-c", "sources")
+	if err != nil {
+		return ntpStatus{}, err
+	}
+	status.reachable = parseChronySources(sources)
+	return status, nil
+}
+
+// parseChronyTracking reads the csv output of chronyc tracking, the system
+// time is the 5th field in seconds and the leap status the 14th
+func parseChronyTracking(out string) (ntpStatus, error) {
+	f := strings.Split(strings.TrimSpace(out), ",")
+	if len(f) < 14 {
+		return ntpStatus{}, fmt.Errorf("unexpected chronyc tracking output %q", strings.TrimSpace(out))
+	}
+	offset, err := strconv.ParseFloat(f[4], 64)
+	if err != nil {
+		return ntpStatus{}, fmt.Errorf("unexpected chronyc system time %q", f[4])
+	}
+	return ntpStatus{
+		synchronized: f[13] != "Not synchronised",
+		offset:       math.Abs(offset) * 1000,
+		hasOffset:    true,
+	}, nil
+}
+
+// parseChronySources counts the sources in the csv output of chronyc sources
+// whose reach register, the 6th field in octal, isn't 0
+func parseChronySources(out string) int {
+	reachable := 0
+	for _, l := range strings.Split(out, "\n") {
+		f := strings.Split(l, ",")
+		if len(f) < 6 {
+			continue
+		}
+		if reach, err := strconv.ParseInt(f[5], 8, 64); err == nil && reach != 0 {
+			reachable++
+		}
+	}
+	return reachable
+}
+
+func readNtpd(timeout int) (ntpStatus, error) {
+	out, err := ntpCommand(timeout, "ntpq", "-pn")
+	if err != nil {
+		return ntpStatus{}, err
+	}
+	return parseNtpqPeers(out), nil
+}
+
+// parseNtpqPeers reads the peers of ntpq -pn, the clock is synchronized to the
+// peer marked * or, for PPS, o. The columns after the mark are remote, refid,
+// st, t, when, poll, reach, delay, offset and jitter.
+func parseNtpqPeers(out string) ntpStatus {
+	var status ntpStatus
+	for _, l := range strings.Split(out, "\n") {
+		if len(l) < 2 {
+			continue
+		}
+		f := strings.Fields(l[1:])
+		if len(f) < 10 {
+			continue
+		}
+		reach, err := strconv.ParseInt(f[6], 8, 64)
+		if err != nil {
+			// The header
+			continue
+		}
+		if reach != 0 {
+			status.reachable++
+		}
+		if l[0] == '*' || l[0] == 'o' {
+			if offset, err := strconv.ParseFloat(f[8], 64); err == nil {
+				status.synchronized = true
+				status.offset = math.Abs(offset)
+				status.hasOffset = true
+			}
+		}
+	}
+	return status
+}
+
+func readTimesyncd(timeout int) (ntpStatus, error) {
+	synced, err := ntpCommand(timeout, "timedatectl", "show", "--property=NTPSynchronized", "--value")
+	if err != nil {
+		return ntpStatus{}, err
+	}
+	out, err := ntpCommand(timeout, "timedatectl", "timesync-status")
+	if err != nil {
+		return ntpStatus{}, err
+	}
+	status := parseTimesyncStatus(out)
+	status.synchronized = strings.TrimSpace(synced) == "yes"
+	return status, nil
+}
+
+// parseTimesyncStatus reads timedatectl timesync-status, systemd-timesyncd
+// has one server that's reachable once it answered
+func parseTimesyncStatus(out string) ntpStatus {
+	var status ntpStatus
+	server := false
+	packets := 0
+	for _, l := range strings.Split(out, "\n") {
+		i := strings.Index(l, ":")
+		if i < 0 {
+			continue
+		}
+		value := strings.TrimSpace(l[i+1:])
+		switch strings.TrimSpace(l[:i]) {
+		case "Server":
+			server = value != "" && value != "null"
+		case "Packet count":
+			packets, _ = strconv.Atoi(value)
+		case "Offset":
+			if d, err := parseSystemdTimespan(value); err == nil {
+				status.offset = math.Abs(float64(d) / float64(time.Millisecond))
+				status.hasOffset = true
+			}
+		}
+	}
+	if server && packets > 0 {
+		status.reachable = 1
+	}
+	return status
+}
+
+// parseSystemdTimespan reads the time spans systemd prints, such as +1.234ms,
+// -52us or 1min 2.5s
+func parseSystemdTimespan(s string) (time.Duration, error) {
+	s = strings.TrimPrefix(s, "+")
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	var total time.Duration
+	for _, part := range strings.Fields(s) {
+		part = strings.Replace(part, "min", "m", 1)
+		d, err := time.ParseDuration(part)
+		if err != nil {
+			return 0, err
+		}
+		total += d
+	}
+	if negative {
+		total = -total
+	}
+	return total, nil
+}
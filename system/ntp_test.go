@@ -0,0 +1,83 @@
+package system
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseChrony(t *testing.T) {
+	status, err := parseChronyTracking("A9FEA97B,169.254.169.123,4,1697039923.123456789,-0.000012345,0.000001,0.000010,-3.2,0.001,0.050,0.000321,0.000123,64.3,Normal\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.synchronized || !status.hasOffset || status.offset < 0.0123 || status.offset > 0.0124 {
+		t.Errorf("tracking was incorrect, got: %+v", status)
+	}
+	status, err = parseChronyTracking("00000000,,0,0.000000000,0.000000000,0.000000000,0.000000000,0.000,0.000,0.000,1.000000000,1.000000000,0.0,Not synchronised\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.synchronized {
+		t.Errorf("an unsynchronised clock should not be synchronized")
+	}
+	if _, err := parseChronyTracking("506 Cannot talk to daemon"); err == nil {
+		t.Errorf("unexpected output should be an error")
+	}
+
+	sources := "^,*,169.254.169.123,3,4,377,12,-0.000001,-0.000002,0.000300\n^,?,10.0.0.1,0,6,0,-,0.0,0.0,0.0\n^,+,10.0.0.2,2,6,1,40,0.000010,0.000010,0.000400\n"
+	if got := parseChronySources(sources); got != 2 {
+		t.Errorf("reachable sources were incorrect, got: %d, want: 2", got)
+	}
+}
+
+func TestParseNtpqPeers(t *testing.T) {
+	out := `     remote           refid      st t when poll reach   delay   offset  jitter
+==============================================================================
+*10.0.0.1        .GPS.            1 u   33   64  377    0.345   -1.234   0.100
++10.0.0.2        10.0.0.1         2 u   12   64  377    0.412    0.522   0.080
+ 10.0.0.3        .INIT.          16 u    -   64    0    0.000    0.000   0.000
+`
+	status := parseNtpqPeers(out)
+	if !status.synchronized || status.offset != 1.234 || status.reachable != 2 {
+		t.Errorf("peers were incorrect, got: %+v", status)
+	}
+	status = parseNtpqPeers(" 10.0.0.3        .INIT.          16 u    -   64    0    0.000    0.000   0.000\n")
+	if status.synchronized || status.hasOffset {
+		t.Errorf("peers without a system peer should not be synchronized, got: %+v", status)
+	}
+}
+
+func TestParseTimesyncStatus(t *testing.T) {
+	out := `       Server: 185.125.190.56 (ntp.ubuntu.com)
+Poll interval: 34min 8s (min: 32s; max 34min 8s)
+         Leap: normal
+      Version: 4
+      Stratum: 2
+    Reference: 4FF3EC02
+    Precision: 1us (-25)
+Root distance: 1.006ms (max: 5s)
+       Offset: -2.678ms
+        Delay: 31.363ms
+       Jitter: 1.038ms
+ Packet count: 42
+    Frequency: -10.345ppm
+`
+	status := parseTimesyncStatus(out)
+	if !status.hasOffset || status.offset != 2.678 || status.reachable != 1 {
+		t.Errorf("timesync status was incorrect, got: %+v", status)
+	}
+
+	tests := map[string]time.Duration{
+		"+1.234ms":    1234 * time.Microsecond,
+		"-52us":       -52 * time.Microsecond,
+		"1min 2.5s":   62500 * time.Millisecond,
+		"+0":          0,
+		"-1min 500ms": -60500 * time.Millisecond,
+	}
+	for s, want := range tests {
+		got, err := parseSystemdTimespan(s)
+		if err != nil || got != want {
+			t.Errorf("%s: got %v, %v, want %v", s, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package system
+
+import (
+	"fmt"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// The subsystems below can be left out of the build, for a smaller binary,
+// with the build tag no_<subsystem> or, for all of them, slim. Their
+// resources still parse, but fail with an error.
+
+// K8s is a workload of a Kubernetes cluster, named namespace/kind/name, such
+// as kube-system/daemonset/kube-proxy, where kind is pod, deployment,
+// daemonset or statefulset
+type K8s interface {
+	Name() string
+	Exists() (bool, error)
+	Ready() (bool, error)
+	Replicas() (int, error)
+	ReadyReplicas() (int, error)
+	SetKubeconfig(string)
+	SetContext(string)
+}
+
+// SQL is the result of a query, run with the command line client of the
+// database so goss doesn't need to include the drivers
+type SQL interface {
+	Name() string
+	Exists() (bool, error)
+	SetQuery(driver, dsn, query string)
+	Rows() (int, error)
+	Value() (string, error)
+	Column(string) ([]string, error)
+}
+
+// KV is a Redis or Memcached server, address is redis://host:port/db or
+// memcached://host:port
+type KV interface {
+	Address() string
+	Exists() (bool, error)
+	SetCommand(command, key string)
+	SetTLS(bool)
+	Reachable() (bool, error)
+	Response() (string, error)
+	Info(field string) (string, error)
+	Latency() (int, error)
+}
+
+// Container is a container of the Docker or Podman engine, queried through
+// the engine's API socket
+type Container interface {
+	Name() string
+	Exists() (bool, error)
+	Running() (bool, error)
+	Health() (string, error)
+	Image() (string, error)
+	RestartCount() (int, error)
+	Ports() ([]string, error)
+	Mounts() ([]string, error)
+	SetSocket(string)
+}
+
+// errNotBuiltIn is the error of the resources of a subsystem left out of the
+// build
+func errNotBuiltIn(subsystem string) error {
+	return util.NewCodedError(util.ErrCodeNotBuiltIn, fmt.Errorf("goss was built without %s support, with the no_%s or slim build tag", subsystem, subsystem))
+}
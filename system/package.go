@@ -2,6 +2,8 @@ package system
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/aelsabbahy/goss/util"
 )
@@ -13,10 +15,12 @@ type Package interface {
 	Versions() ([]string, error)
 }
 
-var ErrNullPackage = errors.New("Could not detect Package type on this system, please use --package flag to explicity set it")
+var ErrNullPackage = util.NewCodedError(util.ErrCodePkgBackendNotFound,
+	errors.New("Could not detect Package type on this system, please use --package flag to explicity set it"))
 
 type NullPackage struct {
 	name string
+	err  error
 }
 
 func NewNullPackage(name string, system *System, config util.Config) Package {
@@ -28,9 +32,74 @@ func (p *NullPackage) Name() string { return p.name }
 func (p *NullPackage) Exists() (bool, error) { return p.Installed() }
 
 func (p *NullPackage) Installed() (bool, error) {
+	if p.err != nil {
+		return false, p.err
+	}
 	return false, ErrNullPackage
 }
 
 func (p *NullPackage) Versions() ([]string, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
 	return nil, ErrNullPackage
 }
+
+// ChannelPackage is a package installed from a channel, the channel a snap
+// tracks or the branch of a flatpak
+type ChannelPackage interface {
+	Channel() (string, error)
+}
+
+// ConfinedPackage is a package run in a sandbox, the confinement of a snap
+type ConfinedPackage interface {
+	Confinement() (string, error)
+}
+
+// SupportedPackageProviders is a list of the package managers chosen with the
+// package-manager attribute, next to the one of the system
+func SupportedPackageProviders() []string {
+	return []string{"flatpak", "gem", "npm", "pip", "snap"}
+}
+
+// NewPackageProvider is the package name of packageManager, one of
+// SupportedPackageProviders
+// packageCommands are the commands the system package managers are queried
+// with
+var packageCommands = map[string]string{
+	"apk":     "apk",
+	"dpkg":    "dpkg-query",
+	"pacman":  "pacman",
+	"pkg":     "pkg",
+	"pkg_add": "pkg_info",
+	"pkg5":    "pkg",
+	"rpm":     "rpm",
+}
+
+// PackageManagerMissing is the error of the system package manager
+// packageManager when its command isn't installed, such as rpm, the default,
+// in a distroless image, nil when it is. Providers report it themselves.
+func PackageManagerMissing(packageManager string) error {
+	cmd, ok := packageCommands[packageManager]
+	if !ok || HasCommand(cmd) {
+		return nil
+	}
+	return util.NewCodedError(util.ErrCodeBackendNotFound, fmt.Errorf("%s isn't installed", cmd))
+}
+
+func NewPackageProvider(packageManager, name string, system *System, config util.Config) Package {
+	switch packageManager {
+	case "flatpak":
+		return NewFlatpakPackage(name, system, config)
+	case "gem":
+		return NewGemPackage(name, system, config)
+	case "npm":
+		return NewNpmPackage(name, system, config)
+	case "pip":
+		return NewPipPackage(name, system, config)
+	case "snap":
+		return NewSnapPackage(name, system, config)
+	}
+	return &NullPackage{name: name, err: util.NewCodedError(util.ErrCodeConfigInvalid, fmt.Errorf("unknown package-manager %q, must be one of %s",
+		packageManager, strings.Join(SupportedPackageProviders(), ", ")))}
+}
@@ -0,0 +1,84 @@
+package system
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// BSDPackage is a package of pkg on FreeBSD or of pkg_add on OpenBSD
+type BSDPackage struct {
+	name      string
+	openbsd   bool
+	versions  []string
+	loaded    bool
+	installed bool
+}
+
+func NewFreeBSDPackage(name string, system *System, config util.Config) Package {
+	return &BSDPackage{name: name}
+}
+
+func NewOpenBSDPackage(name string, system *System, config util.Config) Package {
+	return &BSDPackage{name: name, openbsd: true}
+}
+
+func (p *BSDPackage) setup() {
+	if p.loaded {
+		return
+	}
+	p.loaded = true
+	if p.openbsd {
+		cmd := util.NewCommand("pkg_info", "-e", p.name+"-*")
+		if err := cmd.Run(); err != nil {
+			return
+		}
+		p.versions = parsePkgInfo(p.name, cmd.Stdout.String())
+	} else {
+		cmd := util.NewCommand("pkg", "query", "%v", p.name)
+		if err := cmd.Run(); err != nil {
+			return
+		}
+		p.versions = strings.Fields(cmd.Stdout.String())
+	}
+	p.installed = len(p.versions) > 0
+}
+
+// parsePkgInfo reads the versions of name out of the packages pkg_info -e
+// reports, such as inst:curl-8.4.0, the pattern name-* also matches the
+// packages whose name only starts with name, such as curl-impersonate
+func parsePkgInfo(name, out string) []string {
+	var versions []string
+	for _, l := range strings.Fields(out) {
+		l = strings.TrimPrefix(l, "inst:")
+		if !strings.HasPrefix(l, name+"-") {
+			continue
+		}
+		ver := l[len(name)+1:]
+		if ver != "" && ver[0] >= '0' && ver[0] <= '9' {
+			versions = append(versions, ver)
+		}
+	}
+	return versions
+}
+
+func (p *BSDPackage) Name() string {
+	return p.name
+}
+
+func (p *BSDPackage) Exists() (bool, error) { return p.Installed() }
+
+func (p *BSDPackage) Installed() (bool, error) {
+	p.setup()
+
+	return p.installed, nil
+}
+
+func (p *BSDPackage) Versions() ([]string, error) {
+	p.setup()
+	if len(p.versions) == 0 {
+		return p.versions, errors.New("Package version not found")
+	}
+	return p.versions, nil
+}
@@ -0,0 +1,97 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+type FlatpakPackage struct {
+	name      string
+	versions  []string
+	branch    string
+	loaded    bool
+	installed bool
+	err       error
+}
+
+func NewFlatpakPackage(name string, system *System, config util.Config) Package {
+	return &FlatpakPackage{name: name}
+}
+
+func (p *FlatpakPackage) setup() {
+	if p.loaded {
+		return
+	}
+	p.loaded = true
+	if !HasCommand("flatpak") {
+		p.err = util.NewCodedError(util.ErrCodePkgBackendNotFound, fmt.Errorf("flatpak isn't installed"))
+		return
+	}
+	// flatpak info exits 1 when the application or runtime isn't installed
+	cmd := util.NewCommand("flatpak", "info", p.name)
+	if err := cmd.Run(); err != nil {
+		return
+	}
+	info := parseFlatpakInfo(cmd.Stdout.String())
+	p.installed = true
+	p.branch = info["Branch"]
+	if v := info["Version"]; v != "" {
+		p.versions = []string{v}
+	}
+}
+
+// parseFlatpakInfo is the fields of the output of flatpak info, such as
+// "Branch: stable"
+func parseFlatpakInfo(out string) map[string]string {
+	info := make(map[string]string)
+	for _, l := range strings.Split(out, "\n") {
+		i := strings.Index(l, ":")
+		if i < 0 {
+			continue
+		}
+		key := strings.TrimSpace(l[:i])
+		if key == "" || strings.Contains(key, " ") {
+			continue
+		}
+		info[key] = strings.TrimSpace(l[i+1:])
+	}
+	return info
+}
+
+func (p *FlatpakPackage) Name() string {
+	return p.name
+}
+
+func (p *FlatpakPackage) Exists() (bool, error) { return p.Installed() }
+
+func (p *FlatpakPackage) Installed() (bool, error) {
+	p.setup()
+
+	return p.installed, p.err
+}
+
+func (p *FlatpakPackage) Versions() ([]string, error) {
+	p.setup()
+	if p.err != nil {
+		return nil, p.err
+	}
+	if len(p.versions) == 0 {
+		return p.versions, errors.New("Package version not found")
+	}
+	return p.versions, nil
+}
+
+// Channel is the branch of the application or runtime, such as stable
+func (p *FlatpakPackage) Channel() (string, error) {
+	p.setup()
+	if p.err != nil {
+		return "", p.err
+	}
+	if !p.installed {
+		return "", errors.New("Package not installed")
+	}
+	return p.branch, nil
+}
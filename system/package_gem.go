@@ -0,0 +1,82 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+type GemPackage struct {
+	name      string
+	versions  []string
+	loaded    bool
+	installed bool
+	err       error
+}
+
+func NewGemPackage(name string, system *System, config util.Config) Package {
+	return &GemPackage{name: name}
+}
+
+func (p *GemPackage) setup() {
+	if p.loaded {
+		return
+	}
+	p.loaded = true
+	if !HasCommand("gem") {
+		p.err = util.NewCodedError(util.ErrCodePkgBackendNotFound, fmt.Errorf("gem isn't installed"))
+		return
+	}
+	cmd := util.NewCommand("gem", "list", "--local", "--exact", p.name)
+	if err := cmd.Run(); err != nil {
+		p.err = fmt.Errorf("gem list %s: %v: %s", p.name, err, strings.TrimSpace(cmd.Stderr.String()))
+		return
+	}
+	p.versions = parseGemList(p.name, cmd.Stdout.String())
+	p.installed = len(p.versions) > 0
+}
+
+// parseGemList is the versions in the output of gem list, such as
+// "rake (13.0.6, default: 12.3.3)"
+func parseGemList(name, out string) []string {
+	var versions []string
+	for _, l := range strings.Split(out, "\n") {
+		l = strings.TrimSpace(l)
+		if !strings.HasPrefix(l, name+" (") || !strings.HasSuffix(l, ")") {
+			continue
+		}
+		for _, v := range strings.Split(l[len(name)+2:len(l)-1], ",") {
+			v = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(v), "default:"))
+			// Platform gems are listed as "1.13.10 x86_64-linux"
+			if f := strings.Fields(v); len(f) > 0 {
+				versions = append(versions, f[0])
+			}
+		}
+	}
+	return versions
+}
+
+func (p *GemPackage) Name() string {
+	return p.name
+}
+
+func (p *GemPackage) Exists() (bool, error) { return p.Installed() }
+
+func (p *GemPackage) Installed() (bool, error) {
+	p.setup()
+
+	return p.installed, p.err
+}
+
+func (p *GemPackage) Versions() ([]string, error) {
+	p.setup()
+	if p.err != nil {
+		return nil, p.err
+	}
+	if len(p.versions) == 0 {
+		return p.versions, errors.New("Package version not found")
+	}
+	return p.versions, nil
+}
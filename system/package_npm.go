@@ -0,0 +1,83 @@
+package system
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// NpmPackage is a package installed globally, with npm install -g
+type NpmPackage struct {
+	name      string
+	versions  []string
+	loaded    bool
+	installed bool
+	err       error
+}
+
+func NewNpmPackage(name string, system *System, config util.Config) Package {
+	return &NpmPackage{name: name}
+}
+
+func (p *NpmPackage) setup() {
+	if p.loaded {
+		return
+	}
+	p.loaded = true
+	if !HasCommand("npm") {
+		p.err = util.NewCodedError(util.ErrCodePkgBackendNotFound, fmt.Errorf("npm isn't installed"))
+		return
+	}
+	// npm ls exits 1 when the package isn't installed, the JSON is still
+	// printed
+	cmd := util.NewCommand("npm", "ls", "--global", "--depth=0", "--json", p.name)
+	cmd.Run()
+	versions, err := parseNpmLs(p.name, cmd.Stdout.Bytes())
+	if err != nil {
+		p.err = fmt.Errorf("npm ls %s: %v", p.name, err)
+		return
+	}
+	p.versions = versions
+	p.installed = len(p.versions) > 0
+}
+
+// parseNpmLs is the version in the output of npm ls --json
+func parseNpmLs(name string, out []byte) ([]string, error) {
+	var ls struct {
+		Dependencies map[string]struct {
+			Version string `json:"version"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal(out, &ls); err != nil {
+		return nil, err
+	}
+	if dep, ok := ls.Dependencies[name]; ok && dep.Version != "" {
+		return []string{dep.Version}, nil
+	}
+	return nil, nil
+}
+
+func (p *NpmPackage) Name() string {
+	return p.name
+}
+
+func (p *NpmPackage) Exists() (bool, error) { return p.Installed() }
+
+func (p *NpmPackage) Installed() (bool, error) {
+	p.setup()
+
+	return p.installed, p.err
+}
+
+func (p *NpmPackage) Versions() ([]string, error) {
+	p.setup()
+	if p.err != nil {
+		return nil, p.err
+	}
+	if len(p.versions) == 0 {
+		return p.versions, errors.New("Package version not found")
+	}
+	return p.versions, nil
+}
@@ -0,0 +1,76 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+type PipPackage struct {
+	name      string
+	versions  []string
+	loaded    bool
+	installed bool
+	err       error
+}
+
+func NewPipPackage(name string, system *System, config util.Config) Package {
+	return &PipPackage{name: name}
+}
+
+func (p *PipPackage) setup() {
+	if p.loaded {
+		return
+	}
+	p.loaded = true
+	pip := "pip3"
+	if !HasCommand(pip) {
+		pip = "pip"
+	}
+	if !HasCommand(pip) {
+		p.err = util.NewCodedError(util.ErrCodePkgBackendNotFound, fmt.Errorf("pip isn't installed"))
+		return
+	}
+	// pip show exits 1 when the package isn't installed
+	cmd := util.NewCommand(pip, "show", p.name)
+	if err := cmd.Run(); err != nil {
+		return
+	}
+	p.versions = parsePipShow(cmd.Stdout.String())
+	p.installed = len(p.versions) > 0
+}
+
+// parsePipShow is the version in the output of pip show
+func parsePipShow(out string) []string {
+	for _, l := range strings.Split(out, "\n") {
+		if strings.HasPrefix(l, "Version:") {
+			return []string{strings.TrimSpace(strings.TrimPrefix(l, "Version:"))}
+		}
+	}
+	return nil
+}
+
+func (p *PipPackage) Name() string {
+	return p.name
+}
+
+func (p *PipPackage) Exists() (bool, error) { return p.Installed() }
+
+func (p *PipPackage) Installed() (bool, error) {
+	p.setup()
+
+	return p.installed, p.err
+}
+
+func (p *PipPackage) Versions() ([]string, error) {
+	p.setup()
+	if p.err != nil {
+		return nil, p.err
+	}
+	if len(p.versions) == 0 {
+		return p.versions, errors.New("Package version not found")
+	}
+	return p.versions, nil
+}
@@ -0,0 +1,72 @@
+package system
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// Pkg5Package is a package of pkg(5), the Image Packaging System of Solaris
+// and illumos
+type Pkg5Package struct {
+	name      string
+	versions  []string
+	loaded    bool
+	installed bool
+}
+
+func NewPkg5Package(name string, system *System, config util.Config) Package {
+	return &Pkg5Package{name: name}
+}
+
+func (p *Pkg5Package) setup() {
+	if p.loaded {
+		return
+	}
+	p.loaded = true
+	cmd := util.NewCommand("pkg", "list", "-H", p.name)
+	if err := cmd.Run(); err != nil {
+		return
+	}
+	p.versions = parsePkgList(cmd.Stdout.String())
+	p.installed = len(p.versions) > 0
+}
+
+// parsePkgList reads the versions of pkg list -H, whose columns are the name,
+// the publisher in parentheses unless it's the preferred one, the version and
+// the IFO flags, such as web/curl 7.88.1-2022.0.0.0 i--
+func parsePkgList(out string) []string {
+	var versions []string
+	for _, l := range strings.Split(out, "\n") {
+		f := strings.Fields(l)
+		if len(f) > 2 && strings.HasPrefix(f[1], "(") {
+			f = append(f[:1], f[2:]...)
+		}
+		if len(f) < 3 {
+			continue
+		}
+		versions = append(versions, f[1])
+	}
+	return versions
+}
+
+func (p *Pkg5Package) Name() string {
+	return p.name
+}
+
+func (p *Pkg5Package) Exists() (bool, error) { return p.Installed() }
+
+func (p *Pkg5Package) Installed() (bool, error) {
+	p.setup()
+
+	return p.installed, nil
+}
+
+func (p *Pkg5Package) Versions() ([]string, error) {
+	p.setup()
+	if len(p.versions) == 0 {
+		return p.versions, errors.New("Package version not found")
+	}
+	return p.versions, nil
+}
@@ -0,0 +1,110 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+type SnapPackage struct {
+	name        string
+	versions    []string
+	channel     string
+	confinement string
+	loaded      bool
+	installed   bool
+	err         error
+}
+
+func NewSnapPackage(name string, system *System, config util.Config) Package {
+	return &SnapPackage{name: name}
+}
+
+func (p *SnapPackage) setup() {
+	if p.loaded {
+		return
+	}
+	p.loaded = true
+	if !HasCommand("snap") {
+		p.err = util.NewCodedError(util.ErrCodePkgBackendNotFound, fmt.Errorf("snap isn't installed"))
+		return
+	}
+	// snap list exits 1 when the snap isn't installed
+	cmd := util.NewCommand("snap", "list", p.name)
+	if err := cmd.Run(); err != nil {
+		return
+	}
+	p.installed, p.versions, p.channel, p.confinement = parseSnapList(p.name, cmd.Stdout.String())
+}
+
+// parseSnapList is the snap name in the output of snap list, the columns are
+// Name, Version, Rev, Tracking, Publisher and Notes
+func parseSnapList(name, out string) (installed bool, versions []string, channel, confinement string) {
+	for _, l := range strings.Split(out, "\n") {
+		f := strings.Fields(l)
+		if len(f) < 6 || f[0] != name {
+			continue
+		}
+		confinement = "strict"
+		for _, note := range strings.Split(f[5], ",") {
+			switch note {
+			case "classic", "devmode", "jailmode":
+				confinement = note
+			}
+		}
+		channel = f[3]
+		if channel == "-" {
+			// Snaps installed from a file don't track a channel
+			channel = ""
+		}
+		return true, []string{f[1]}, channel, confinement
+	}
+	return false, nil, "", ""
+}
+
+func (p *SnapPackage) Name() string {
+	return p.name
+}
+
+func (p *SnapPackage) Exists() (bool, error) { return p.Installed() }
+
+func (p *SnapPackage) Installed() (bool, error) {
+	p.setup()
+
+	return p.installed, p.err
+}
+
+func (p *SnapPackage) Versions() ([]string, error) {
+	p.setup()
+	if p.err != nil {
+		return nil, p.err
+	}
+	if len(p.versions) == 0 {
+		return p.versions, errors.New("Package version not found")
+	}
+	return p.versions, nil
+}
+
+func (p *SnapPackage) Channel() (string, error) {
+	p.setup()
+	if p.err != nil {
+		return "", p.err
+	}
+	if !p.installed {
+		return "", errors.New("Package not installed")
+	}
+	return p.channel, nil
+}
+
+func (p *SnapPackage) Confinement() (string, error) {
+	p.setup()
+	if p.err != nil {
+		return "", p.err
+	}
+	if !p.installed {
+		return "", errors.New("Package not installed")
+	}
+	return p.confinement, nil
+}
@@ -1,7 +1,10 @@
 package system
 
 import (
+	"reflect"
 	"testing"
+
+	"github.com/aelsabbahy/goss/util"
 )
 
 func TestIsSupportedPackageManager(t *testing.T) {
@@ -13,3 +16,88 @@ func TestIsSupportedPackageManager(t *testing.T) {
 		t.Fatal("rpm should be a valid package manager")
 	}
 }
+
+func TestParsePackageProviders(t *testing.T) {
+	pip := "Name: requests\nVersion: 2.31.0\nSummary: Python HTTP for Humans.\n"
+	if got := parsePipShow(pip); !reflect.DeepEqual(got, []string{"2.31.0"}) {
+		t.Errorf("pip show: got %v", got)
+	}
+
+	gem := "\n*** LOCAL GEMS ***\n\nrake (13.0.6, default: 12.3.3)\nnokogiri (1.13.10 x86_64-linux)\n"
+	if got := parseGemList("rake", gem); !reflect.DeepEqual(got, []string{"13.0.6", "12.3.3"}) {
+		t.Errorf("gem list rake: got %v", got)
+	}
+	if got := parseGemList("nokogiri", gem); !reflect.DeepEqual(got, []string{"1.13.10"}) {
+		t.Errorf("gem list nokogiri: got %v", got)
+	}
+
+	npm := `{"dependencies": {"typescript": {"version": "5.1.6", "overridden": false}}}`
+	if got, err := parseNpmLs("typescript", []byte(npm)); err != nil || !reflect.DeepEqual(got, []string{"5.1.6"}) {
+		t.Errorf("npm ls typescript: got %v, %v", got, err)
+	}
+	if got, err := parseNpmLs("missing", []byte("{}")); err != nil || got != nil {
+		t.Errorf("npm ls missing: got %v, %v", got, err)
+	}
+}
+
+func TestParseSnapList(t *testing.T) {
+	out := "Name    Version   Rev    Tracking       Publisher   Notes\n" +
+		"core20  20230801  2015   latest/stable  canonical✓  base\n" +
+		"code    1.83.1    143    latest/stable  vscode✓     classic\n" +
+		"hello   2.10      x1     -              -           -\n"
+	for _, tt := range []struct {
+		name, version, channel, confinement string
+	}{
+		{"core20", "20230801", "latest/stable", "strict"},
+		{"code", "1.83.1", "latest/stable", "classic"},
+		{"hello", "2.10", "", "strict"},
+	} {
+		installed, versions, channel, confinement := parseSnapList(tt.name, out)
+		if !installed || !reflect.DeepEqual(versions, []string{tt.version}) || channel != tt.channel || confinement != tt.confinement {
+			t.Errorf("%s: got %v %v %q %q", tt.name, installed, versions, channel, confinement)
+		}
+	}
+	if installed, _, _, _ := parseSnapList("missing", out); installed {
+		t.Error("missing: got installed")
+	}
+}
+
+func TestParseFlatpakInfo(t *testing.T) {
+	out := "\nFirefox - Fast, Private & Safe Web Browser\n\n" +
+		"          ID: org.mozilla.firefox\n" +
+		"         Ref: app/org.mozilla.firefox/x86_64/stable\n" +
+		"      Branch: stable\n" +
+		"     Version: 118.0.1\n" +
+		"     Runtime: org.freedesktop.Platform/x86_64/23.08\n"
+	info := parseFlatpakInfo(out)
+	if info["Branch"] != "stable" || info["Version"] != "118.0.1" || info["Runtime"] != "org.freedesktop.Platform/x86_64/23.08" {
+		t.Errorf("got %v", info)
+	}
+}
+
+func TestParsePkgInfo(t *testing.T) {
+	out := "inst:curl-8.4.0\ninst:curl-impersonate-0.5.4\ninst:vim-9.0.2-no_x11\n"
+	if got := parsePkgInfo("curl", out); !reflect.DeepEqual(got, []string{"8.4.0"}) {
+		t.Errorf("curl: got %v", got)
+	}
+	if got := parsePkgInfo("vim", out); !reflect.DeepEqual(got, []string{"9.0.2-no_x11"}) {
+		t.Errorf("vim: got %v", got)
+	}
+	if got := parsePkgInfo("cur", out); got != nil {
+		t.Errorf("cur: got %v", got)
+	}
+}
+
+func TestParsePkgList(t *testing.T) {
+	out := "web/curl                                          7.88.1-2022.0.0.0          i--\n" +
+		"library/security/openssl-3 (extra.omnios)          3.0.11-151046.0            i--\n"
+	if got := parsePkgList(out); !reflect.DeepEqual(got, []string{"7.88.1-2022.0.0.0", "3.0.11-151046.0"}) {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestNewPackageProvider(t *testing.T) {
+	if _, err := NewPackageProvider("cargo", "serde", nil, util.Config{}).Installed(); err == nil {
+		t.Fatal("cargo should not be a valid package manager")
+	}
+}
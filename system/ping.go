@@ -0,0 +1,377 @@
+package system
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// Ping is the ICMP echo of a host, sent with a raw socket when goss may open
+// one and otherwise with an unprivileged ICMP datagram socket
+type Ping interface {
+	Host() string
+	Exists() (bool, error)
+	SetProbes(count, interval int)
+	Reachable() (bool, error)
+	RTT() (float64, error)
+	Loss() (float64, error)
+	MTU() (int, error)
+}
+
+// Defaults of a ping that doesn't set its probes
+const (
+	defaultPingCount    = 3
+	defaultPingInterval = 200
+)
+
+type DefPing struct {
+	host string
+	// Timeout is how long to wait for the reply of each probe
+	Timeout int
+	// IPVersion is the address family of the host to ping, 4, 6 or any
+	IPVersion string
+	// Netns is the network namespace the probes are sent from
+	Netns    string
+	resolver *Resolver
+	count    int
+	interval int
+	loaded   bool
+	err      error
+	sent     int
+	received int
+	maxRTT   time.Duration
+	mtu      int
+	mtuErr   error
+	mtuDone  bool
+}
+
+// Bounds of the path MTU search, the minimum MTU of each family, and up to
+// jumbo frames
+const (
+	minMTU4 = 68
+	minMTU6 = 1280
+	maxMTU  = 9000
+)
+
+// errPacketTooBig is a probe that needs to be fragmented
+var errPacketTooBig = errors.New("packet too big")
+
+func NewDefPing(host string, system *System, config util.Config) Ping {
+	p := &DefPing{
+		host:      host,
+		Timeout:   config.TimeOutMilliSeconds(),
+		IPVersion: ipVersion(config.IPVersion, system),
+		Netns:     netnsName(config.Netns, system),
+	}
+	if system != nil {
+		p.resolver = system.Resolver
+	}
+	return p
+}
+
+func (p *DefPing) Host() string {
+	return p.host
+}
+
+func (p *DefPing) Exists() (bool, error) { return p.Reachable() }
+
+// SetProbes sets the number of echo requests and the milliseconds between them
+func (p *DefPing) SetProbes(count, interval int) {
+	p.count, p.interval = count, interval
+}
+
+func (p *DefPing) setup() error {
+	if p.loaded {
+		return p.err
+	}
+	p.loaded = true
+	p.err = p.ping()
+	return p.err
+}
+
+func (p *DefPing) timeout() time.Duration {
+	if p.Timeout <= 0 {
+		return time.Second
+	}
+	return time.Duration(p.Timeout) * time.Millisecond
+}
+
+// address is the address of the host that's pinged, the first one of its
+// address family
+func (p *DefPing) address() (net.IP, error) {
+	addrs, err := p.resolver.lookupHost(p.host, int(p.timeout()/time.Millisecond), p.Netns)
+	if err != nil {
+		return nil, err
+	}
+	addrs = filterIPVersion(addrs, p.IPVersion)
+	if len(addrs) == 0 {
+		return nil, &net.AddrError{Err: "no suitable address found", Addr: p.host}
+	}
+	return net.ParseIP(addrs[0]), nil
+}
+
+func (p *DefPing) ping() error {
+	if p.count <= 0 {
+		p.count = defaultPingCount
+	}
+	if p.interval <= 0 {
+		p.interval = defaultPingInterval
+	}
+	timeout := p.timeout()
+	ip, err := p.address()
+	if err != nil {
+		return err
+	}
+
+	var conn net.PacketConn
+	var privileged bool
+	err = inNetns(p.Netns, func() (err error) {
+		conn, privileged, err = listenICMP(ip.To4() == nil)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var dst net.Addr = &net.IPAddr{IP: ip}
+	if !privileged {
+		dst = &net.UDPAddr{IP: ip}
+	}
+	token, err := pingToken(16)
+	if err != nil {
+		return err
+	}
+	for seq := 1; seq <= p.count; seq++ {
+		if seq > 1 {
+			time.Sleep(time.Duration(p.interval) * time.Millisecond)
+		}
+		rtt, err := pingOnce(conn, dst, ip, seq, token, timeout)
+		if err != nil {
+			return err
+		}
+		p.sent++
+		if rtt > 0 {
+			p.received++
+			if rtt > p.maxRTT {
+				p.maxRTT = rtt
+			}
+		}
+	}
+	return nil
+}
+
+// pingToken is the payload of echo requests, the replies to other pings are
+// told apart by its random start, as the kernel sets the ID of unprivileged
+// echo requests
+func pingToken(size int) ([]byte, error) {
+	token := make([]byte, size)
+	_, err := rand.Read(token[:16])
+	return token, err
+}
+
+// MTU is the path MTU to the host, the largest packet in bytes including its
+// IP header that gets a reply without being fragmented. A size is tried twice
+// before it's considered too big, as a lost probe can't be told apart from a
+// dropped one.
+func (p *DefPing) MTU() (int, error) {
+	if p.mtuDone {
+		return p.mtu, p.mtuErr
+	}
+	p.mtuDone = true
+	p.mtu, p.mtuErr = p.pathMTU()
+	return p.mtu, p.mtuErr
+}
+
+func (p *DefPing) pathMTU() (int, error) {
+	ip, err := p.address()
+	if err != nil {
+		return 0, err
+	}
+	v6 := ip.To4() == nil
+	var conn net.PacketConn
+	var privileged bool
+	err = inNetns(p.Netns, func() (err error) {
+		conn, privileged, err = listenICMPNoFragment(v6)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var dst net.Addr = &net.IPAddr{IP: ip}
+	if !privileged {
+		dst = &net.UDPAddr{IP: ip}
+	}
+	lo, hi, header := minMTU4, maxMTU, 20+8
+	if v6 {
+		lo, header = minMTU6, 40+8
+	}
+	seq := 0
+	fits := func(size int) (bool, error) {
+		data, err := pingToken(size - header)
+		if err != nil {
+			return false, err
+		}
+		for attempt := 0; attempt < 2; attempt++ {
+			seq++
+			rtt, err := pingOnce(conn, dst, ip, seq, data, p.timeout())
+			if err == errPacketTooBig {
+				return false, nil
+			}
+			if err != nil || rtt > 0 {
+				return rtt > 0, err
+			}
+		}
+		return false, nil
+	}
+
+	if ok, err := fits(lo); err != nil || !ok {
+		if err == nil {
+			err = fmt.Errorf("ping %s: no replies", p.host)
+		}
+		return 0, err
+	}
+	// lo fits and hi+1 doesn't
+	hi++
+	for hi-lo > 1 {
+		mid := (lo + hi) / 2
+		ok, err := fits(mid)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo, nil
+}
+
+// listenICMP opens a raw ICMP socket, or an unprivileged ICMP datagram
+// socket when goss can't open raw sockets
+func listenICMP(v6 bool) (conn *icmp.PacketConn, privileged bool, err error) {
+	raw, dgram, address := "ip4:icmp", "udp4", "0.0.0.0"
+	if v6 {
+		raw, dgram, address = "ip6:ipv6-icmp", "udp6", "::"
+	}
+	if conn, err = icmp.ListenPacket(raw, address); err == nil {
+		return conn, true, nil
+	}
+	if conn, err = icmp.ListenPacket(dgram, address); err == nil {
+		return conn, false, nil
+	}
+	return nil, false, fmt.Errorf("ping needs root, CAP_NET_RAW or the group of goss in net.ipv4.ping_group_range: %v", err)
+}
+
+// pingOnce sends an echo request and waits for its reply, the round trip is
+// 0 when there was no reply before timeout
+func pingOnce(conn net.PacketConn, dst net.Addr, ip net.IP, seq int, data []byte, timeout time.Duration) (time.Duration, error) {
+	var request icmp.Type = ipv4.ICMPTypeEcho
+	reply, proto := icmp.Type(ipv4.ICMPTypeEchoReply), 1
+	if ip.To4() == nil {
+		request, reply, proto = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply, 58
+	}
+	id := int(data[0])<<8 | int(data[1])
+	msg := icmp.Message{Type: request, Body: &icmp.Echo{ID: id, Seq: seq, Data: data}}
+	b, err := msg.Marshal(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	if _, err := conn.WriteTo(b, dst); err != nil {
+		if errors.Is(err, syscall.EMSGSIZE) {
+			return 0, errPacketTooBig
+		}
+		return 0, err
+	}
+	if err := conn.SetReadDeadline(start.Add(timeout)); err != nil {
+		return 0, err
+	}
+	// Replies are as large as the request, the IPv4 header of raw sockets aside
+	buf := make([]byte, len(b)+60)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if e, ok := err.(net.Error); ok && e.Timeout() {
+				return 0, nil
+			}
+			return 0, err
+		}
+		m, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil {
+			continue
+		}
+		if packetTooBig(m, seq) {
+			return 0, errPacketTooBig
+		}
+		if m.Type != reply {
+			continue
+		}
+		if echo, ok := m.Body.(*icmp.Echo); ok && echo.Seq == seq && bytes.Equal(echo.Data, data) {
+			return time.Since(start), nil
+		}
+	}
+}
+
+// packetTooBig is whether m is the error of a router that the echo request
+// seq needs to be fragmented, which only raw sockets read
+func packetTooBig(m *icmp.Message, seq int) bool {
+	var data []byte
+	switch body := m.Body.(type) {
+	case *icmp.DstUnreach:
+		// The IPv4 header of the request is of variable length
+		if m.Type != ipv4.ICMPTypeDestinationUnreachable || m.Code != 4 || len(body.Data) < 1 {
+			return false
+		}
+		data = body.Data[int(body.Data[0]&0x0f)*4:]
+	case *icmp.PacketTooBig:
+		if len(body.Data) < 40 {
+			return false
+		}
+		data = body.Data[40:]
+	default:
+		return false
+	}
+	// The ICMP header of the request, with its sequence number last
+	return len(data) >= 8 && int(data[6])<<8|int(data[7]) == seq
+}
+
+// Reachable is whether any of the probes got a reply
+func (p *DefPing) Reachable() (bool, error) {
+	if err := p.setup(); err != nil {
+		return false, err
+	}
+	return p.received > 0, nil
+}
+
+// RTT is the longest round trip of the replies in milliseconds
+func (p *DefPing) RTT() (float64, error) {
+	if err := p.setup(); err != nil {
+		return 0, err
+	}
+	if p.received == 0 {
+		return 0, fmt.Errorf("ping %s: no replies", p.host)
+	}
+	return float64(p.maxRTT) / float64(time.Millisecond), nil
+}
+
+// Loss is the percentage of the probes that got no reply
+func (p *DefPing) Loss() (float64, error) {
+	if err := p.setup(); err != nil {
+		return 0, err
+	}
+	return float64(p.sent-p.received) * 100 / float64(p.sent), nil
+}
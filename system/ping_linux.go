@@ -0,0 +1,56 @@
+//go:build linux
+// +build linux
+
+package system
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+// listenICMPNoFragment is listenICMP with fragmentation disabled, so probes
+// larger than the path MTU are dropped or rejected. PROBE sets the DF bit
+// whatever the path MTU the kernel learnt.
+func listenICMPNoFragment(v6 bool) (conn net.PacketConn, privileged bool, err error) {
+	family, proto, level, opt, value := syscall.AF_INET, syscall.IPPROTO_ICMP, syscall.IPPROTO_IP, syscall.IP_MTU_DISCOVER, syscall.IP_PMTUDISC_PROBE
+	if v6 {
+		family, proto, level, opt, value = syscall.AF_INET6, syscall.IPPROTO_ICMPV6, syscall.IPPROTO_IPV6, syscall.IPV6_MTU_DISCOVER, syscall.IPV6_PMTUDISC_PROBE
+	}
+	setDF := func(fd uintptr) error {
+		return os.NewSyscallError("setsockopt", syscall.SetsockoptInt(int(fd), level, opt, value))
+	}
+	// As listenICMP, a raw socket or else an unprivileged datagram one
+	raw, address := "ip4:icmp", "0.0.0.0"
+	if v6 {
+		raw, address = "ip6:ipv6-icmp", "::"
+	}
+	if c, err := net.ListenPacket(raw, address); err == nil {
+		rc, err := c.(*net.IPConn).SyscallConn()
+		var dfErr error
+		if err == nil {
+			err = rc.Control(func(fd uintptr) { dfErr = setDF(fd) })
+		}
+		if err == nil {
+			err = dfErr
+		}
+		if err != nil {
+			c.Close()
+			return nil, false, err
+		}
+		return c, true, nil
+	}
+	fd, err := syscall.Socket(family, syscall.SOCK_DGRAM, proto)
+	if err != nil {
+		return nil, false, fmt.Errorf("ping needs root, CAP_NET_RAW or the group of goss in net.ipv4.ping_group_range: %v", err)
+	}
+	if err := setDF(uintptr(fd)); err != nil {
+		syscall.Close(fd)
+		return nil, false, err
+	}
+	f := os.NewFile(uintptr(fd), "icmp")
+	defer f.Close()
+	conn, err = net.FilePacketConn(f)
+	return conn, false, err
+}
@@ -0,0 +1,30 @@
+//go:build (linux && amd64) || (linux && arm64)
+// +build linux,amd64 linux,arm64
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+func TestPingMTU(t *testing.T) {
+	name := fmt.Sprintf("goss-test-mtu-%d", os.Getpid())
+	if out, err := exec.Command("ip", "netns", "add", name).CombinedOutput(); err != nil {
+		t.Skipf("can't create a network namespace: %v %s", err, out)
+	}
+	defer exec.Command("ip", "netns", "del", name).Run()
+	if out, err := exec.Command("ip", "-n", name, "link", "set", "lo", "mtu", "1400", "up").CombinedOutput(); err != nil {
+		t.Fatalf("%v %s", err, out)
+	}
+
+	p := NewDefPing("127.0.0.1", nil, util.Config{Timeout: 200 * time.Millisecond, Netns: name})
+	if mtu, err := p.MTU(); err != nil || mtu != 1400 {
+		t.Errorf("got mtu %d, %v, want 1400", mtu, err)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+// +build !linux
+
+package system
+
+import (
+	"fmt"
+	"net"
+)
+
+func listenICMPNoFragment(v6 bool) (net.PacketConn, bool, error) {
+	return nil, false, fmt.Errorf("path mtu is only supported on linux")
+}
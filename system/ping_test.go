@@ -0,0 +1,29 @@
+package system
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+func TestPing(t *testing.T) {
+	if conn, _, err := listenICMP(false); err != nil {
+		t.Skip(err)
+	} else {
+		conn.Close()
+	}
+
+	p := NewDefPing("127.0.0.1", nil, util.Config{Timeout: time.Second})
+	p.SetProbes(2, 10)
+	if reachable, err := p.Reachable(); err != nil || !reachable {
+		t.Fatalf("127.0.0.1 got reachable %v, %v", reachable, err)
+	}
+	if loss, err := p.Loss(); err != nil || loss != 0 {
+		t.Errorf("127.0.0.1 got loss %v, %v", loss, err)
+	}
+	if rtt, err := p.RTT(); err != nil || rtt <= 0 || rtt > 1000 {
+		t.Errorf("127.0.0.1 got rtt %v, %v", rtt, err)
+	}
+
+}
@@ -1,6 +1,10 @@
 package system
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -13,11 +17,17 @@ type Port interface {
 	Exists() (bool, error)
 	Listening() (bool, error)
 	IP() ([]string, error)
+	Process() ([]string, error)
+	User() ([]string, error)
 }
 
 type DefPort struct {
 	port     string
 	sysPorts map[string][]GOnetstat.Process
+	system   *System
+	// keys are the ports of the ip version looked up, such as tcp:80 and
+	// tcp6:80 for any
+	keys []string
 }
 
 func NewDefPort(port string, system *System, config util.Config) Port {
@@ -25,9 +35,36 @@ func NewDefPort(port string, system *System, config util.Config) Port {
 	return &DefPort{
 		port:     p,
 		sysPorts: system.Ports(),
+		system:   system,
+		keys:     portKeys(p, ipVersion(config.IPVersion, system)),
 	}
 }
 
+// portKeys are the ports of fullport of an ip version, the network of
+// fullport picks the family when version is empty
+func portKeys(fullport, version string) []string {
+	network, port := splitPort(fullport)
+	base := strings.TrimRight(network, "46")
+	switch version {
+	case "4":
+		return []string{base + ":" + port}
+	case "6":
+		return []string{base + "6:" + port}
+	case "any":
+		return []string{base + ":" + port, base + "6:" + port}
+	}
+	return []string{fullport}
+}
+
+// entries are the sockets of the port of every key
+func (p *DefPort) entries() []GOnetstat.Process {
+	var entries []GOnetstat.Process
+	for _, k := range p.keys {
+		entries = append(entries, p.sysPorts[k]...)
+	}
+	return entries
+}
+
 func splitPort(fullport string) (network, port string) {
 	split := strings.SplitN(fullport, ":", 2)
 	if len(split) == 2 {
@@ -49,15 +86,17 @@ func (p *DefPort) Port() string {
 func (p *DefPort) Exists() (bool, error) { return p.Listening() }
 
 func (p *DefPort) Listening() (bool, error) {
-	if _, ok := p.sysPorts[p.port]; ok {
-		return true, nil
+	for _, k := range p.keys {
+		if _, ok := p.sysPorts[k]; ok {
+			return true, nil
+		}
 	}
 	return false, nil
 }
 
 func (p *DefPort) IP() ([]string, error) {
 	var ips []string
-	for _, entry := range p.sysPorts[p.port] {
+	for _, entry := range p.entries() {
 		ips = append(ips, entry.Ip)
 	}
 	return ips, nil
@@ -105,3 +144,107 @@ func GetPorts(lookupPids bool) map[string][]GOnetstat.Process {
 	}
 	return ports
 }
+
+// Process returns the distinct names of the processes holding the port's sockets
+func (p *DefPort) Process() ([]string, error) {
+	if isBSD() {
+		return bsdOwners(p.entries(), func(e GOnetstat.Process) string { return e.Name }), nil
+	}
+	return p.owners(func(pid string) (string, error) {
+		comm, err := ioutil.ReadFile(filepath.Join("/proc", pid, "comm"))
+		return strings.TrimSpace(string(comm)), err
+	})
+}
+
+// User returns the distinct effective users of the processes holding the port's sockets
+func (p *DefPort) User() ([]string, error) {
+	if isBSD() {
+		return bsdOwners(p.entries(), func(e GOnetstat.Process) string { return e.User }), nil
+	}
+	return p.owners(processUser)
+}
+
+func (p *DefPort) owners(lookup func(pid string) (string, error)) ([]string, error) {
+	seen := make(map[string]bool)
+	owners := []string{}
+	var pids []string
+	for _, k := range p.keys {
+		pids = append(pids, p.system.PortPids()[k]...)
+	}
+	for _, pid := range pids {
+		owner, err := lookup(pid)
+		if err != nil {
+			// The process exited since the sockets were listed
+			continue
+		}
+		if !seen[owner] {
+			seen[owner] = true
+			owners = append(owners, owner)
+		}
+	}
+	sort.Strings(owners)
+	return owners, nil
+}
+
+// GetPortPids maps listening ports to the pids of every process holding their
+// sockets, pids of processes that can't be inspected are omitted
+func GetPortPids() map[string][]string {
+	inodePorts := make(map[string]string)
+	for _, net := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		data, err := ioutil.ReadFile("/proc/net/" + net)
+		if err != nil {
+			continue
+		}
+		for port, inodes := range parseProcNet(net, string(data)) {
+			for _, inode := range inodes {
+				inodePorts[inode] = port
+			}
+		}
+	}
+
+	portPids := make(map[string][]string)
+	fds, _ := filepath.Glob("/proc/[0-9]*/fd/[0-9]*")
+	seen := make(map[string]bool)
+	for _, fd := range fds {
+		link, err := os.Readlink(fd)
+		if err != nil || !strings.HasPrefix(link, "socket:[") {
+			continue
+		}
+		port, ok := inodePorts[strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")]
+		if !ok {
+			continue
+		}
+		pid := strings.Split(fd, "/")[2]
+		if key := port + "/" + pid; !seen[key] {
+			seen[key] = true
+			portPids[port] = append(portPids[port], pid)
+		}
+	}
+	return portPids
+}
+
+// parseProcNet maps the ports of the listening sockets in a /proc/net file to their inodes
+func parseProcNet(net string, data string) map[string][]string {
+	ports := make(map[string][]string)
+	for i, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if i == 0 || len(fields) < 10 {
+			continue
+		}
+		// 0A is TCP_LISTEN, udp sockets have no listening state
+		if strings.HasPrefix(net, "tcp") && fields[3] != "0A" {
+			continue
+		}
+		local := strings.Split(fields[1], ":")
+		if len(local) != 2 {
+			continue
+		}
+		port, err := strconv.ParseInt(local[1], 16, 64)
+		if err != nil {
+			continue
+		}
+		key := net + ":" + strconv.FormatInt(port, 10)
+		ports[key] = append(ports[key], fields[9])
+	}
+	return ports
+}
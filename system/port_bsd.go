@@ -0,0 +1,145 @@
+package system
+
+import (
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aelsabbahy/GOnetstat"
+	"github.com/aelsabbahy/goss/util"
+)
+
+// isBSD reports whether the ports are read with sockstat or netstat, there's
+// no /proc/net on FreeBSD and OpenBSD
+func isBSD() bool {
+	return runtime.GOOS == "freebsd" || runtime.GOOS == "openbsd"
+}
+
+// GetBSDPorts lists the listening ports, in the same format as GetPorts, with
+// sockstat on FreeBSD, which knows the processes holding them, and netstat on
+// OpenBSD, which doesn't
+func GetBSDPorts() map[string][]GOnetstat.Process {
+	if runtime.GOOS == "openbsd" {
+		ports := make(map[string][]GOnetstat.Process)
+		for _, family := range []string{"inet", "inet6"} {
+			cmd := util.NewCommand("netstat", "-an", "-f", family)
+			if err := cmd.Run(); err != nil {
+				continue
+			}
+			for port, entries := range parseNetstat(family, cmd.Stdout.String()) {
+				ports[port] = append(ports[port], entries...)
+			}
+		}
+		return ports
+	}
+	cmd := util.NewCommand("sockstat", "-46l")
+	if err := cmd.Run(); err != nil {
+		return map[string][]GOnetstat.Process{}
+	}
+	return parseSockstat(cmd.Stdout.String())
+}
+
+// parseSockstat reads the listening sockets of sockstat -46l, whose columns
+// are user, command, pid, fd, proto, local address and foreign address.
+// Sockets of both families, tcp46, are tcp6 ports as dual-stack sockets are on
+// Linux.
+func parseSockstat(out string) map[string][]GOnetstat.Process {
+	ports := make(map[string][]GOnetstat.Process)
+	for _, l := range strings.Split(out, "\n") {
+		f := strings.Fields(l)
+		if len(f) < 7 || f[0] == "USER" {
+			continue
+		}
+		var net string
+		switch f[4] {
+		case "tcp4":
+			net = "tcp"
+		case "tcp6", "tcp46":
+			net = "tcp6"
+		case "udp4":
+			net = "udp"
+		case "udp6", "udp46":
+			net = "udp6"
+		default:
+			continue
+		}
+		ip, port, ok := splitBSDAddr(f[5], ":", net)
+		if !ok {
+			continue
+		}
+		entry := GOnetstat.Process{User: f[0], Name: f[1], Pid: f[2], State: "LISTEN", Ip: ip, Port: port}
+		key := net + ":" + strconv.FormatInt(port, 10)
+		ports[key] = append(ports[key], entry)
+	}
+	return ports
+}
+
+// parseNetstat reads the listening sockets of netstat -an -f family on
+// OpenBSD, which separates the port from the address with a dot, such as in
+// *.22 or ::1.25
+func parseNetstat(family, out string) map[string][]GOnetstat.Process {
+	ports := make(map[string][]GOnetstat.Process)
+	for _, l := range strings.Split(out, "\n") {
+		f := strings.Fields(l)
+		if len(f) < 5 {
+			continue
+		}
+		// IPv6 sockets are tcp6 or tcp depending on the release
+		var net string
+		switch proto := strings.TrimSuffix(f[0], "6"); {
+		case proto == "tcp" && len(f) >= 6 && f[5] == "LISTEN":
+			net = "tcp"
+		case proto == "udp" && f[4] == "*.*":
+			net = "udp"
+		default:
+			continue
+		}
+		if family == "inet6" {
+			net += "6"
+		}
+		ip, port, ok := splitBSDAddr(f[3], ".", net)
+		if !ok {
+			continue
+		}
+		key := net + ":" + strconv.FormatInt(port, 10)
+		ports[key] = append(ports[key], GOnetstat.Process{State: "LISTEN", Ip: ip, Port: port})
+	}
+	return ports
+}
+
+// splitBSDAddr splits the port off a local address, the wildcard address *
+// is 0.0.0.0 or ::, as on Linux
+func splitBSDAddr(addr, sep, net string) (string, int64, bool) {
+	i := strings.LastIndex(addr, sep)
+	if i < 0 {
+		return "", 0, false
+	}
+	port, err := strconv.ParseInt(addr[i+1:], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	ip := strings.Trim(addr[:i], "[]")
+	if ip == "*" {
+		ip = "0.0.0.0"
+		if strings.HasSuffix(net, "6") {
+			ip = "::"
+		}
+	}
+	return ip, port, true
+}
+
+// bsdOwners are the distinct names or users sockstat reports for the
+// sockets of the port
+func bsdOwners(entries []GOnetstat.Process, owner func(GOnetstat.Process) string) []string {
+	seen := make(map[string]bool)
+	owners := []string{}
+	for _, e := range entries {
+		if o := owner(e); o != "" && !seen[o] {
+			seen[o] = true
+			owners = append(owners, o)
+		}
+	}
+	sort.Strings(owners)
+	return owners
+}
@@ -0,0 +1,77 @@
+package system
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aelsabbahy/GOnetstat"
+)
+
+func TestParseProcNet(t *testing.T) {
+	data := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:01BB 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 21012 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:0CEA 00000000:0000 0A 00000000:00000000 00:00000000 00000000   999        0 21013 1 0000000000000000 100 0 0 10 0
+   2: 0F02000A:B2D4 5DB8D822:01BB 01 00000000:00000000 02:000007E3 00000000  1000        0 31337 2 0000000000000000 20 4 30 10 -1
+`
+	want := map[string][]string{
+		"tcp:443":  {"21012"},
+		"tcp:3306": {"21013"},
+	}
+	if got := parseProcNet("tcp", data); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseProcNet (tcp) was incorrect, got: %v, want: %v.", got, want)
+	}
+
+	want = map[string][]string{
+		"udp:443":   {"21012"},
+		"udp:3306":  {"21013"},
+		"udp:45780": {"31337"},
+	}
+	if got := parseProcNet("udp", data); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseProcNet (udp) was incorrect, got: %v, want: %v.", got, want)
+	}
+}
+
+func TestParseSockstat(t *testing.T) {
+	out := `USER     COMMAND    PID   FD  PROTO  LOCAL ADDRESS         FOREIGN ADDRESS
+root     sshd        812  4   tcp6   *:22                  *:*
+root     sshd        812  5   tcp4   *:22                  *:*
+www      nginx      1020  6   tcp46  *:443                 *:*
+unbound  unbound     640  3   udp4   127.0.0.1:53          *:*
+root     ntpd        701  21  udp6   fe80::1%lo0:123       *:*
+`
+	ports := parseSockstat(out)
+	if len(ports) != 5 {
+		t.Fatalf("got %v", ports)
+	}
+	if e := ports["tcp:22"]; len(e) != 1 || e[0].Ip != "0.0.0.0" || e[0].Name != "sshd" || e[0].Pid != "812" {
+		t.Errorf("tcp:22 was incorrect, got: %+v", e)
+	}
+	if e := ports["tcp6:443"]; len(e) != 1 || e[0].Ip != "::" || e[0].User != "www" {
+		t.Errorf("tcp6:443 was incorrect, got: %+v", e)
+	}
+	if e := ports["udp6:123"]; len(e) != 1 || e[0].Ip != "fe80::1%lo0" {
+		t.Errorf("udp6:123 was incorrect, got: %+v", e)
+	}
+	if got := bsdOwners(ports["tcp:22"], func(e GOnetstat.Process) string { return e.User }); !reflect.DeepEqual(got, []string{"root"}) {
+		t.Errorf("owners were incorrect, got: %v", got)
+	}
+}
+
+func TestParseNetstat(t *testing.T) {
+	out := `Active Internet connections (including servers)
+Proto   Recv-Q Send-Q  Local Address          Foreign Address        (state)
+tcp          0      0  10.0.0.5.22            10.0.0.9.51234         ESTABLISHED
+tcp          0      0  *.22                   *.*                    LISTEN
+tcp          0      0  127.0.0.1.25           *.*                    LISTEN
+udp          0      0  *.514                  *.*
+udp          0      0  10.0.0.5.32768         10.0.0.1.53
+`
+	ports := parseNetstat("inet", out)
+	if len(ports) != 3 || ports["tcp:22"][0].Ip != "0.0.0.0" || ports["tcp:25"][0].Ip != "127.0.0.1" || len(ports["udp:514"]) != 1 {
+		t.Errorf("inet was incorrect, got: %+v", ports)
+	}
+	ports = parseNetstat("inet6", "tcp6         0      0  ::1.25                 *.*                    LISTEN\ntcp          0      0  *.80                   *.*                    LISTEN\n")
+	if e := ports["tcp6:80"]; len(e) != 1 || e[0].Ip != "::" || ports["tcp6:25"][0].Ip != "::1" {
+		t.Errorf("inet6 was incorrect, got: %+v", ports)
+	}
+}
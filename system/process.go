@@ -1,7 +1,17 @@
 package system
 
 import (
-	"github.com/aelsabbahy/go-ps"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/aelsabbahy/goss/util"
 )
 
@@ -10,11 +20,25 @@ type Process interface {
 	Exists() (bool, error)
 	Running() (bool, error)
 	Pids() ([]int, error)
+	Count() (int, error)
+	User() ([]string, error)
+	Args() (io.Reader, error)
+	RSS() (int, error)
+	VSZ() (int, error)
+	Env(name string) ([]string, error)
+	Cwd() ([]string, error)
+}
+
+// Proc is a running process, as listed by GetProcs
+type Proc interface {
+	Pid() int
+	PPid() int
+	Executable() string
 }
 
 type DefProcess struct {
 	executable string
-	procMap    map[string][]ps.Process
+	procMap    map[string][]Proc
 	err        error
 }
 
@@ -54,15 +78,193 @@ func (p *DefProcess) Running() (bool, error) {
 	return false, nil
 }
 
-func GetProcs() (map[string][]ps.Process, error) {
-	pmap := make(map[string][]ps.Process)
-	processes, err := ps.Processes()
+func (p *DefProcess) Count() (int, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+	return len(p.procMap[p.executable]), nil
+}
+
+// User returns the distinct effective users the instances run as
+func (p *DefProcess) User() ([]string, error) {
+	if err := p.procfs(); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	users := []string{}
+	for _, proc := range p.procMap[p.executable] {
+		u, err := processUser(strconv.Itoa(proc.Pid()))
+		if err != nil || seen[u] {
+			// The process may have exited since it was listed
+			continue
+		}
+		seen[u] = true
+		users = append(users, u)
+	}
+	sort.Strings(users)
+	return users, nil
+}
+
+// Args returns the command line of every instance, one per line
+func (p *DefProcess) Args() (io.Reader, error) {
+	if err := p.procfs(); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	for _, proc := range p.procMap[p.executable] {
+		cmdline, err := ioutil.ReadFile(filepath.Join("/proc", strconv.Itoa(proc.Pid()), "cmdline"))
+		if err != nil {
+			continue
+		}
+		args := strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00")
+		fmt.Fprintln(&out, strings.Join(args, " "))
+	}
+	return &out, nil
+}
+
+// RSS is the resident set size in KiB of the largest instance
+func (p *DefProcess) RSS() (int, error) {
+	return p.maxStatus("VmRSS:")
+}
+
+// VSZ is the virtual memory size in KiB of the largest instance
+func (p *DefProcess) VSZ() (int, error) {
+	return p.maxStatus("VmSize:")
+}
+
+// Env returns the distinct values of the environment variable name of the
+// instances, an instance without it has an empty value
+func (p *DefProcess) Env(name string) ([]string, error) {
+	return p.distinct(func(pid string) (string, error) {
+		environ, err := ioutil.ReadFile(filepath.Join("/proc", pid, "environ"))
+		if err != nil {
+			return "", err
+		}
+		for _, v := range strings.Split(string(environ), "\x00") {
+			if strings.HasPrefix(v, name+"=") {
+				return strings.TrimPrefix(v, name+"="), nil
+			}
+		}
+		return "", nil
+	})
+}
+
+// Cwd returns the distinct working directories of the instances
+func (p *DefProcess) Cwd() ([]string, error) {
+	return p.distinct(func(pid string) (string, error) {
+		return os.Readlink(filepath.Join("/proc", pid, "cwd"))
+	})
+}
+
+// distinct returns the sorted distinct values read of every instance
+func (p *DefProcess) distinct(read func(pid string) (string, error)) ([]string, error) {
+	if err := p.procfs(); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	values := []string{}
+	for _, proc := range p.procMap[p.executable] {
+		v, err := read(strconv.Itoa(proc.Pid()))
+		if err != nil || seen[v] {
+			// The process may have exited since it was listed, or belong to
+			// another user
+			continue
+		}
+		seen[v] = true
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	return values, nil
+}
+
+func (p *DefProcess) maxStatus(field string) (int, error) {
+	if err := p.procfs(); err != nil {
+		return 0, err
+	}
+	max := 0
+	for _, proc := range p.procMap[p.executable] {
+		v, err := processStatusKiB(strconv.Itoa(proc.Pid()), field)
+		if err != nil {
+			continue
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return max, nil
+}
+
+// procfs returns an error when the process details beyond the pids can't be read
+func (p *DefProcess) procfs() error {
+	if p.err != nil {
+		return p.err
+	}
+	if _, err := os.Stat("/proc/self/status"); err != nil {
+		return fmt.Errorf("process details require /proc: %v", err)
+	}
+	return nil
+}
+
+func processStatusKiB(pid string, field string) (int, error) {
+	status, err := ioutil.ReadFile(filepath.Join("/proc", pid, "status"))
 	if err != nil {
-		return pmap, err
+		return 0, err
 	}
-	for _, p := range processes {
-		pmap[p.Executable()] = append(pmap[p.Executable()], p)
+	for _, line := range strings.Split(string(status), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == field {
+			return strconv.Atoi(fields[1])
+		}
 	}
+	// Kernel threads have no memory fields
+	return 0, nil
+}
 
-	return pmap, nil
+func processUser(pid string) (string, error) {
+	status, err := ioutil.ReadFile(filepath.Join("/proc", pid, "status"))
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(status), "\n") {
+		fields := strings.Fields(line)
+		// Uid: real effective saved filesystem
+		if len(fields) < 3 || fields[0] != "Uid:" {
+			continue
+		}
+		if u, err := user.LookupId(fields[2]); err == nil {
+			return u.Username, nil
+		}
+		return fields[2], nil
+	}
+	return "", fmt.Errorf("no Uid in %s status", pid)
+}
+
+// psProc is a process of the output of ps -o pid=,ppid=,comm=
+type psProc struct {
+	pid        int
+	ppid       int
+	executable string
+}
+
+func (p psProc) Pid() int           { return p.pid }
+func (p psProc) PPid() int          { return p.ppid }
+func (p psProc) Executable() string { return p.executable }
+
+func parsePs(out string) map[string][]Proc {
+	pmap := make(map[string][]Proc)
+	for _, l := range strings.Split(out, "\n") {
+		f := strings.Fields(l)
+		if len(f) < 3 {
+			continue
+		}
+		pid, err := strconv.Atoi(f[0])
+		if err != nil {
+			continue
+		}
+		ppid, _ := strconv.Atoi(f[1])
+		// The command name can have spaces, such as for kernel threads
+		p := psProc{pid: pid, ppid: ppid, executable: strings.Join(f[2:], " ")}
+		pmap[p.executable] = append(pmap[p.executable], p)
+	}
+	return pmap
 }
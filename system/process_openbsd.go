@@ -0,0 +1,19 @@
+// +build openbsd
+
+package system
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// GetProcs lists the processes with ps, go-ps doesn't read them on OpenBSD
+func GetProcs() (map[string][]Proc, error) {
+	cmd := util.NewCommand("ps", "-axo", "pid=,ppid=,comm=")
+	if err := cmd.Run(); err != nil {
+		return map[string][]Proc{}, fmt.Errorf("ps: %v: %s", err, strings.TrimSpace(cmd.Stderr.String()))
+	}
+	return parsePs(cmd.Stdout.String()), nil
+}
@@ -0,0 +1,21 @@
+// +build !openbsd
+
+package system
+
+import (
+	// This needs a better name
+	"github.com/aelsabbahy/go-ps"
+)
+
+func GetProcs() (map[string][]Proc, error) {
+	pmap := make(map[string][]Proc)
+	processes, err := ps.Processes()
+	if err != nil {
+		return pmap, err
+	}
+	for _, p := range processes {
+		pmap[p.Executable()] = append(pmap[p.Executable()], p)
+	}
+
+	return pmap, nil
+}
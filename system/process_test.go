@@ -0,0 +1,99 @@
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/user"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessEnvCwd(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process details are read from /proc")
+	}
+	t.Setenv("GOSS_PROCESS_TEST", "on")
+	p := &DefProcess{
+		executable: "goss",
+		procMap:    map[string][]Proc{"goss": {psProc{pid: os.Getpid(), executable: "goss"}}},
+	}
+
+	env, err := p.Env("GOSS_PROCESS_TEST")
+	require.NoError(t, err)
+	// environ is the environment the process started with
+	assert.Equal(t, []string{""}, env)
+	env, err = p.Env("PATH")
+	require.NoError(t, err)
+	assert.Equal(t, []string{os.Getenv("PATH")}, env)
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	cwd, err := p.Cwd()
+	require.NoError(t, err)
+	assert.Equal(t, []string{wd}, cwd)
+
+	p.procMap = map[string][]Proc{}
+	cwd, err = p.Cwd()
+	require.NoError(t, err)
+	assert.Empty(t, cwd)
+}
+
+func TestProcessDetails(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process details are read from /proc")
+	}
+	sleep := exec.Command("sleep", "60")
+	require.NoError(t, sleep.Start())
+	defer sleep.Wait()
+	defer sleep.Process.Kill()
+	p := &DefProcess{
+		executable: "sleep",
+		procMap: map[string][]Proc{"sleep": {
+			psProc{pid: os.Getpid(), executable: "sleep"},
+			psProc{pid: sleep.Process.Pid, executable: "sleep"},
+		}},
+	}
+
+	count, err := p.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Both run as the user of the test, listed once
+	u, err := user.Current()
+	require.NoError(t, err)
+	users, err := p.User()
+	require.NoError(t, err)
+	assert.Equal(t, []string{u.Username}, users)
+
+	args, err := p.Args()
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(args)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Equal(t, []string{strings.Join(os.Args, " "), "sleep 60"}, lines)
+
+	// The largest instance is the test binary
+	rss, err := p.RSS()
+	require.NoError(t, err)
+	vsz, err := p.VSZ()
+	require.NoError(t, err)
+	assert.True(t, rss > 1024, "rss %d KiB", rss)
+	assert.True(t, vsz >= rss, "vsz %d KiB, rss %d KiB", vsz, rss)
+
+	// Instances that exited since they were listed are left out
+	require.NoError(t, sleep.Process.Kill())
+	sleep.Wait()
+	users, err = p.User()
+	require.NoError(t, err)
+	assert.Equal(t, []string{u.Username}, users)
+	args, err = p.Args()
+	require.NoError(t, err)
+	data, err = ioutil.ReadAll(args)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join(os.Args, " ")+"\n", string(data))
+}
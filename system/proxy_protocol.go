@@ -0,0 +1,95 @@
+package system
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// proxyV2Signature starts the binary header of version 2 of the PROXY protocol
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// proxySourceAddr is the client address a PROXY protocol header claims, an
+// ip or ip:port, the address and port of the connection when they're left out
+func proxySourceAddr(source string, local *net.TCPAddr) (*net.TCPAddr, error) {
+	if source == "" {
+		return local, nil
+	}
+	host, port := source, ""
+	if h, p, err := net.SplitHostPort(source); err == nil {
+		host, port = h, p
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, fmt.Errorf("proxy-source must be an ip or ip:port, got: %s", source)
+	}
+	addr := &net.TCPAddr{IP: ip, Port: local.Port}
+	if port != "" {
+		p, err := strconv.Atoi(port)
+		if err != nil || p < 0 || p > 65535 {
+			return nil, fmt.Errorf("proxy-source must be an ip or ip:port, got: %s", source)
+		}
+		addr.Port = p
+	}
+	return addr, nil
+}
+
+// proxyHeader is the PROXY protocol header of version v1 or v2 a load
+// balancer sends before proxying the connection of src to dst
+func proxyHeader(version string, src, dst *net.TCPAddr) ([]byte, error) {
+	v4 := dst.IP.To4() != nil
+	if (src.IP.To4() != nil) != v4 {
+		return nil, fmt.Errorf("proxy-source %s isn't of the address family of %s", src.IP, dst.IP)
+	}
+	switch version {
+	case "v1":
+		proto := "TCP4"
+		if !v4 {
+			proto = "TCP6"
+		}
+		return []byte(fmt.Sprintf("PROXY %s %s %s %d %d\r\n", proto, src.IP, dst.IP, src.Port, dst.Port)), nil
+	case "v2":
+		var b bytes.Buffer
+		b.Write(proxyV2Signature)
+		// Version 2, PROXY command
+		b.WriteByte(0x21)
+		srcIP, dstIP := src.IP.To16(), dst.IP.To16()
+		if v4 {
+			// TCP over IPv4
+			b.WriteByte(0x11)
+			srcIP, dstIP = src.IP.To4(), dst.IP.To4()
+		} else {
+			// TCP over IPv6
+			b.WriteByte(0x21)
+		}
+		binary.Write(&b, binary.BigEndian, uint16(2*len(srcIP)+4))
+		b.Write(srcIP)
+		b.Write(dstIP)
+		binary.Write(&b, binary.BigEndian, uint16(src.Port))
+		binary.Write(&b, binary.BigEndian, uint16(dst.Port))
+		return b.Bytes(), nil
+	}
+	return nil, fmt.Errorf("proxy-protocol must be v1 or v2, got: %s", version)
+}
+
+// sendProxyHeader writes the PROXY protocol header of conn, as a load
+// balancer in front of the server would
+func sendProxyHeader(conn net.Conn, version, source string) error {
+	local, ok := conn.LocalAddr().(*net.TCPAddr)
+	remote, ok2 := conn.RemoteAddr().(*net.TCPAddr)
+	if !ok || !ok2 {
+		return fmt.Errorf("proxy-protocol needs a TCP connection")
+	}
+	src, err := proxySourceAddr(source, local)
+	if err != nil {
+		return err
+	}
+	header, err := proxyHeader(version, src, remote)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Write(header)
+	return err
+}
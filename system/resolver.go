@@ -0,0 +1,166 @@
+package system
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// Resolver resolves the hosts of the addr, dns and http checks through a DNS
+// server and hosts file of the run rather than the system resolver, for
+// build environments where the system DNS isn't production's
+type Resolver struct {
+	// Server is the DNS server hosts missing from the hosts file are
+	// resolved through, the system resolver when it's empty
+	Server string
+	// HostsFile is the /etc/hosts formatted file of the static hosts
+	HostsFile string
+	hosts     map[string][]string
+}
+
+// NewResolver is the resolver of server and the /etc/hosts formatted
+// hostsFile, nil when both are empty
+func NewResolver(server, hostsFile string) (*Resolver, error) {
+	if server == "" && hostsFile == "" {
+		return nil, nil
+	}
+	r := &Resolver{Server: server, HostsFile: hostsFile, hosts: make(map[string][]string)}
+	if hostsFile == "" {
+		return r, nil
+	}
+	f, err := os.Open(hostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("hosts file: %v", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		ip := net.ParseIP(fields[0])
+		if ip == nil || len(fields) < 2 {
+			return nil, fmt.Errorf("hosts file %s:%d: must be an address followed by host names, got: %s", hostsFile, n, scanner.Text())
+		}
+		for _, name := range fields[1:] {
+			name = strings.ToLower(strings.TrimSuffix(name, "."))
+			r.hosts[name] = append(r.hosts[name], ip.String())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("hosts file: %v", err)
+	}
+	return r, nil
+}
+
+// static is the addresses of host in the hosts file answering a qtype
+// lookup, only A, AAAA and host lookups are answered
+func (r *Resolver) static(host, qtype string) ([]string, bool) {
+	if r == nil {
+		return nil, false
+	}
+	addrs, ok := r.hosts[strings.ToLower(strings.TrimSuffix(host, "."))]
+	if !ok {
+		return nil, false
+	}
+	switch qtype {
+	case "":
+		return addrs, true
+	case "A", "AAAA":
+		var res []string
+		for _, a := range addrs {
+			if (net.ParseIP(a).To4() != nil) == (qtype == "A") {
+				res = append(res, a)
+			}
+		}
+		return res, true
+	}
+	return nil, false
+}
+
+// LookupHost is the addresses of host in the hosts file, or otherwise
+// resolved through the server
+func (r *Resolver) LookupHost(host string, timeout int) ([]string, error) {
+	return r.lookupHost(host, timeout, "")
+}
+
+// lookupHost is LookupHost from the network namespace netns, through its
+// nameserver when the resolver doesn't have a server
+func (r *Resolver) lookupHost(host string, timeout int, netns string) ([]string, error) {
+	if ip := net.ParseIP(strings.Trim(host, "[]")); ip != nil {
+		return []string{ip.String()}, nil
+	}
+	if addrs, ok := r.static(host, ""); ok {
+		return addrs, nil
+	}
+	var server string
+	if r != nil {
+		server = r.Server
+	}
+	if server == "" && netns != "" {
+		var err error
+		if server, err = netnsServer(netns); err != nil {
+			return nil, err
+		}
+	}
+	addrs, err := dnsLookup(host, server, "", timeout, netns)
+	if err == nil && len(addrs) == 0 {
+		err = &net.DNSError{Err: "no such host", Name: host, Server: server, IsNotFound: true}
+	}
+	return addrs, err
+}
+
+// DialContext connects to address with dialer after resolving its host with
+// the resolver, trying each of its addresses in turn
+func (r *Resolver) DialContext(ctx context.Context, dialer *net.Dialer, network, address string) (net.Conn, error) {
+	return r.dialNetns(ctx, dialer, "", network, address)
+}
+
+// dialNetns is DialContext from the network namespace netns, the host is
+// resolved first so each dial opens a single socket in the namespace
+func (r *Resolver) dialNetns(ctx context.Context, dialer *net.Dialer, netns, network, address string) (net.Conn, error) {
+	if r == nil && netns == "" {
+		return dialer.DialContext(ctx, network, address)
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	timeout := 5 * time.Second
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	if dialer.Timeout > 0 && dialer.Timeout < timeout {
+		timeout = dialer.Timeout
+	}
+	addrs, err := r.lookupHost(host, int(timeout/time.Millisecond), netns)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range addrs {
+		if !networkAllows(network, a) {
+			continue
+		}
+		var conn net.Conn
+		err = inNetns(netns, func() (err error) {
+			conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(a, port))
+			return err
+		})
+		if err == nil {
+			return conn, nil
+		}
+	}
+	if err == nil {
+		err = &net.AddrError{Err: "no suitable address found", Addr: host}
+	}
+	return nil, err
+}
@@ -0,0 +1,88 @@
+package system
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+	"github.com/miekg/dns"
+)
+
+func TestResolver(t *testing.T) {
+	// The DNS server answers 127.0.0.1 for every A query
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &dns.Server{PacketConn: pc, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, q *dns.Msg) {
+		resp := new(dns.Msg)
+		resp.SetReply(q)
+		if q.Question[0].Qtype == dns.TypeA {
+			resp.Answer = append(resp.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: q.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+				A:   net.ParseIP("127.0.0.1"),
+			})
+		}
+		w.WriteMsg(resp)
+	})}
+	go server.ActivateAndServe()
+	defer server.Shutdown()
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+	u, _ := url.Parse(target.URL)
+	port := u.Port()
+
+	hostsFile := filepath.Join(t.TempDir(), "hosts")
+	hosts := "# static hosts\n127.0.0.1 static.goss.test\n::1 static.goss.test alias.goss.test\n"
+	if err := ioutil.WriteFile(hostsFile, []byte(hosts), 0644); err != nil {
+		t.Fatal(err)
+	}
+	resolver, err := NewResolver(pc.LocalAddr().String(), hostsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sys := &System{Resolver: resolver}
+	config := util.Config{Timeout: 5 * time.Second}
+
+	h := NewDefHTTP("http://dynamic.goss.test:"+port, sys, config)
+	if status, err := h.Status(); err != nil || status != 200 {
+		t.Errorf("http through the dns server: got %d, %v", status, err)
+	}
+	a := NewDefAddr("tcp://static.goss.test:"+port, sys, config)
+	if reachable, _ := a.Reachable(); !reachable {
+		t.Error("addr through the hosts file isn't reachable")
+	}
+
+	lookups := []struct {
+		host  string
+		addrs []string
+	}{
+		{"static.goss.test", []string{"127.0.0.1", "::1"}},
+		{"A:static.goss.test", []string{"127.0.0.1"}},
+		{"AAAA:alias.goss.test", []string{"::1"}},
+		{"dynamic.goss.test", []string{"127.0.0.1"}},
+	}
+	for _, l := range lookups {
+		addrs, err := NewDefDNS(l.host, sys, config).Addrs()
+		if err != nil || !reflect.DeepEqual(addrs, l.addrs) {
+			t.Errorf("dns %s: got %v, %v, want %v", l.host, addrs, err, l.addrs)
+		}
+	}
+
+	if _, err := NewResolver("", filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("a missing hosts file must be an error")
+	}
+	if err := ioutil.WriteFile(hostsFile, []byte("static.goss.test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewResolver("", hostsFile); err == nil {
+		t.Error("a hosts file line without an address must be an error")
+	}
+}
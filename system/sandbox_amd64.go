@@ -0,0 +1,52 @@
+// +build linux,amd64
+
+package system
+
+import "syscall"
+
+const auditArch = 0xc000003e
+
+// Syscalls missing from the syscall package on amd64
+const (
+	sysOpenByHandleAt  = 304
+	sysSetns           = 308
+	sysProcessVMWritev = 311
+	sysFinitModule     = 313
+	sysBPF             = 321
+	sysPidfdSendSignal = 424
+	sysIOUringSetup    = 425
+	sysFchmodat2       = 452
+	sysSetxattrat      = 463
+	sysRemovexattrat   = 466
+)
+
+// sandboxDeniedSyscalls change the system, load code into the kernel or
+// bypass the restrictions the sandbox sets up. io_uring is denied because
+// the operations it submits aren't seen by seccomp.
+var sandboxDeniedSyscalls = []uintptr{
+	syscall.SYS_PTRACE, sysProcessVMWritev,
+	syscall.SYS_MOUNT, syscall.SYS_UMOUNT2, syscall.SYS_PIVOT_ROOT, syscall.SYS_CHROOT,
+	sysSetns, syscall.SYS_UNSHARE, sysOpenByHandleAt,
+	syscall.SYS_SWAPON, syscall.SYS_SWAPOFF, syscall.SYS_REBOOT, syscall.SYS_KEXEC_LOAD,
+	syscall.SYS_INIT_MODULE, syscall.SYS_DELETE_MODULE, sysFinitModule,
+	sysBPF, syscall.SYS_PERF_EVENT_OPEN, syscall.SYS_ACCT,
+	syscall.SYS_KEYCTL, syscall.SYS_ADD_KEY, syscall.SYS_REQUEST_KEY,
+	syscall.SYS_SETTIMEOFDAY, syscall.SYS_CLOCK_SETTIME, syscall.SYS_ADJTIMEX,
+	syscall.SYS_SETHOSTNAME, syscall.SYS_SETDOMAINNAME,
+	syscall.SYS_IOPL, syscall.SYS_IOPERM,
+	sysIOUringSetup,
+	// Landlock's first ABI doesn't restrict changing the metadata of files
+	syscall.SYS_CHMOD, syscall.SYS_FCHMOD, syscall.SYS_FCHMODAT, sysFchmodat2,
+	syscall.SYS_CHOWN, syscall.SYS_FCHOWN, syscall.SYS_LCHOWN, syscall.SYS_FCHOWNAT,
+	syscall.SYS_TRUNCATE,
+	syscall.SYS_SETXATTR, syscall.SYS_LSETXATTR, syscall.SYS_FSETXATTR, sysSetxattrat,
+	syscall.SYS_REMOVEXATTR, syscall.SYS_LREMOVEXATTR, syscall.SYS_FREMOVEXATTR, sysRemovexattrat,
+	syscall.SYS_UTIME, syscall.SYS_UTIMES, syscall.SYS_FUTIMESAT, syscall.SYS_UTIMENSAT,
+}
+
+// sandboxSignalSyscalls send signals, they're denied unless the signal, the
+// argument at the index, is 0, which only checks the process exists
+var sandboxSignalSyscalls = map[uintptr]uint32{
+	syscall.SYS_KILL: 1, syscall.SYS_TKILL: 1, syscall.SYS_TGKILL: 2,
+	syscall.SYS_RT_SIGQUEUEINFO: 1, syscall.SYS_RT_TGSIGQUEUEINFO: 2, sysPidfdSendSignal: 1,
+}
@@ -0,0 +1,232 @@
+//go:build (linux && amd64) || (linux && arm64)
+// +build linux,amd64 linux,arm64
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	prSetNoNewPrivs   = 38
+	prSetSeccomp      = 22
+	seccompModeFilter = 2
+
+	seccompRetKillProcess = 0x80000000
+	seccompRetErrno       = 0x00050000
+	seccompRetAllow       = 0x7fff0000
+
+	bpfLdWAbs = 0x20
+	bpfJeqK   = 0x15
+	bpfJgeK   = 0x35
+	bpfRetK   = 0x06
+	bpfAndK   = 0x54
+
+	// seccompArgs is the offset of the arguments in struct seccomp_data,
+	// each 8 bytes with the low 32 bits first on little endian arches
+	seccompArgs = 16
+
+	// Syscalls with this bit set use the x32 ABI on amd64, with the same audit arch
+	x32SyscallBit = 0x40000000
+
+	sysLandlockCreateRuleset     = 444
+	sysLandlockAddRule           = 445
+	sysLandlockRestrictSelf      = 446
+	landlockRulePathBeneath      = 1
+	landlockCreateRulesetVersion = 1
+
+	landlockAccessFSWriteFile = 1 << 1
+	// Every access right of the first landlock ABI that changes the file system
+	landlockAccessFSWrite = landlockAccessFSWriteFile | 1<<4 | 1<<5 | 1<<6 | 1<<7 | 1<<8 | 1<<9 | 1<<10 | 1<<11 | 1<<12
+)
+
+// sandboxSockets are the socket families commands may create, each with the
+// socket types it may have: unix datagram sockets so syslog works, but not
+// unix stream sockets, which reach daemons such as docker and D-Bus. IP,
+// packet and netlink sockets, and any other family, are denied.
+var sandboxSockets = []struct {
+	family uint32
+	types  []uint32
+}{
+	{syscall.AF_UNIX, []uint32{syscall.SOCK_DGRAM}},
+}
+
+// EnterSandbox restricts the calling thread and what it executes: the file
+// system becomes read only except for /dev/null, including the modes,
+// owners, extended attributes and times of files, only the sockets of
+// sandboxSockets can be created, signals can't be sent except signal 0,
+// input can't be pushed to terminals and syscalls that change the system
+// fail with EPERM. The caller must exec from the same thread, it can't be
+// undone.
+func EnterSandbox() error {
+	runtime.LockOSThread()
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prSetNoNewPrivs, 1, 0); errno != 0 {
+		return fmt.Errorf("sandbox: setting no_new_privs: %v", errno)
+	}
+	if err := restrictFileSystem(); err != nil {
+		return err
+	}
+	return installSeccompFilter()
+}
+
+// SandboxExec enters the sandbox and replaces the goss process with argv
+func SandboxExec(argv []string) error {
+	if len(argv) == 0 {
+		return fmt.Errorf("sandbox: no command given")
+	}
+	path, err := exec.LookPath(argv[0])
+	if err != nil {
+		return err
+	}
+	if err := EnterSandbox(); err != nil {
+		return err
+	}
+	return syscall.Exec(path, argv, os.Environ())
+}
+
+// SandboxAvailable returns why commands can't be sandboxed, or nil when they can
+func SandboxAvailable() error {
+	_, _, errno := syscall.RawSyscall(sysLandlockCreateRuleset, 0, 0, landlockCreateRulesetVersion)
+	if errno == syscall.ENOSYS || errno == syscall.EOPNOTSUPP {
+		return errNoLandlock
+	}
+	if errno != 0 {
+		return fmt.Errorf("sandbox: checking landlock: %v", errno)
+	}
+	return nil
+}
+
+var errNoLandlock = fmt.Errorf("sandbox: landlock isn't available, it needs Linux 5.13 or later with landlock enabled")
+
+func restrictFileSystem() error {
+	attr := uint64(landlockAccessFSWrite)
+	fd, _, errno := syscall.RawSyscall(sysLandlockCreateRuleset, uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr), 0)
+	if errno == syscall.ENOSYS || errno == syscall.EOPNOTSUPP {
+		return errNoLandlock
+	}
+	if errno != 0 {
+		return fmt.Errorf("sandbox: creating landlock ruleset: %v", errno)
+	}
+	defer syscall.Close(int(fd))
+
+	// Commands commonly redirect to /dev/null
+	null, err := syscall.Open("/dev/null", syscall.O_RDONLY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("sandbox: %v", err)
+	}
+	defer syscall.Close(null)
+	// struct landlock_path_beneath_attr is packed, a u64 followed by an s32
+	var rule [12]byte
+	*(*uint64)(unsafe.Pointer(&rule[0])) = landlockAccessFSWriteFile
+	*(*int32)(unsafe.Pointer(&rule[8])) = int32(null)
+	if _, _, errno := syscall.RawSyscall6(sysLandlockAddRule, fd, landlockRulePathBeneath, uintptr(unsafe.Pointer(&rule[0])), 0, 0, 0); errno != 0 {
+		return fmt.Errorf("sandbox: adding landlock rule: %v", errno)
+	}
+	if _, _, errno := syscall.RawSyscall(sysLandlockRestrictSelf, fd, 0, 0); errno != 0 {
+		return fmt.Errorf("sandbox: enforcing landlock ruleset: %v", errno)
+	}
+	return nil
+}
+
+func installSeccompFilter() error {
+	prog := seccompFilter()
+	fprog := syscall.SockFprog{Len: uint16(len(prog)), Filter: &prog[0]}
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prSetSeccomp, seccompModeFilter, uintptr(unsafe.Pointer(&fprog))); errno != 0 {
+		return fmt.Errorf("sandbox: installing seccomp filter: %v", errno)
+	}
+	return nil
+}
+
+// seccompFilter returns a BPF program over struct seccomp_data, which holds the
+// syscall number at offset 0, the audit arch at 4 and the arguments from 16
+func seccompFilter() []syscall.SockFilter {
+	stmt := func(code uint16, k uint32) syscall.SockFilter {
+		return syscall.SockFilter{Code: code, K: k}
+	}
+	// Jumps to deny are resolved once the position of deny is known, when
+	// the comparison is true or, for the signals, false
+	var prog []syscall.SockFilter
+	var toDeny, toDenyFalse []int
+	jumpToDeny := func(code uint16, k uint32) {
+		toDeny = append(toDeny, len(prog))
+		prog = append(prog, stmt(code, k))
+	}
+
+	prog = append(prog,
+		stmt(bpfLdWAbs, 4),
+		syscall.SockFilter{Code: bpfJeqK, Jt: 1, K: auditArch},
+		stmt(bpfRetK, seccompRetKillProcess),
+		stmt(bpfLdWAbs, 0),
+	)
+	jumpToDeny(bpfJgeK, x32SyscallBit)
+	for _, nr := range sandboxDeniedSyscalls {
+		jumpToDeny(bpfJeqK, uint32(nr))
+	}
+	// Signals are allowed when they're 0, each check skips to the next one
+	// for other syscalls, with the syscall number still loaded
+	for _, nr := range sortedSyscalls(sandboxSignalSyscalls) {
+		prog = append(prog,
+			syscall.SockFilter{Code: bpfJeqK, Jf: 3, K: uint32(nr)},
+			stmt(bpfLdWAbs, seccompArgs+8*sandboxSignalSyscalls[nr]),
+		)
+		toDenyFalse = append(toDenyFalse, len(prog))
+		prog = append(prog,
+			stmt(bpfJeqK, 0),
+			stmt(bpfRetK, seccompRetAllow),
+		)
+	}
+	// TIOCSTI pushes input to a terminal, which its shell would run
+	prog = append(prog,
+		syscall.SockFilter{Code: bpfJeqK, Jf: 3, K: syscall.SYS_IOCTL},
+		stmt(bpfLdWAbs, seccompArgs+8),
+	)
+	jumpToDeny(bpfJeqK, syscall.TIOCSTI)
+	prog = append(prog, stmt(bpfRetK, seccompRetAllow))
+	// Only socket is left to check, anything else is allowed. Each family
+	// of sandboxSockets allows its types, without the flags of the type
+	// argument, and denies the others.
+	prog = append(prog,
+		syscall.SockFilter{Code: bpfJeqK, Jt: 1, K: syscall.SYS_SOCKET},
+		stmt(bpfRetK, seccompRetAllow),
+		stmt(bpfLdWAbs, seccompArgs),
+	)
+	for _, s := range sandboxSockets {
+		prog = append(prog,
+			syscall.SockFilter{Code: bpfJeqK, Jf: uint8(3 + 2*len(s.types)), K: s.family},
+			stmt(bpfLdWAbs, seccompArgs+8),
+			stmt(bpfAndK, ^uint32(syscall.SOCK_NONBLOCK|syscall.SOCK_CLOEXEC)),
+		)
+		for _, typ := range s.types {
+			prog = append(prog,
+				syscall.SockFilter{Code: bpfJeqK, Jf: 1, K: typ},
+				stmt(bpfRetK, seccompRetAllow),
+			)
+		}
+		prog = append(prog, stmt(bpfRetK, seccompRetErrno|uint32(syscall.EPERM)))
+	}
+
+	deny := len(prog)
+	prog = append(prog, stmt(bpfRetK, seccompRetErrno|uint32(syscall.EPERM)))
+	for _, i := range toDeny {
+		prog[i].Jt = uint8(deny - i - 1)
+	}
+	for _, i := range toDenyFalse {
+		prog[i].Jf = uint8(deny - i - 1)
+	}
+	return prog
+}
+
+func sortedSyscalls(m map[uintptr]uint32) []uintptr {
+	nrs := make([]uintptr, 0, len(m))
+	for nr := range m {
+		nrs = append(nrs, nr)
+	}
+	sort.Slice(nrs, func(i, j int) bool { return nrs[i] < nrs[j] })
+	return nrs
+}
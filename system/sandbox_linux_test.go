@@ -0,0 +1,210 @@
+//go:build (linux && amd64) || (linux && arm64)
+// +build linux,amd64 linux,arm64
+
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestSeccompFilter(t *testing.T) {
+	prog := seccompFilter()
+	if len(prog) > 4096 {
+		t.Fatalf("filter has %d instructions, the kernel accepts 4096", len(prog))
+	}
+	last := prog[len(prog)-1]
+	if last.Code != bpfRetK || last.K != seccompRetErrno|uint32(syscall.EPERM) {
+		t.Fatalf("filter should end with deny, got %+v", last)
+	}
+
+	// run follows the filter for a syscall number and its arguments on the
+	// native arch, returning the action
+	run := func(nr uint32, args ...uint32) uint32 {
+		data := map[uint32]uint32{0: nr, 4: auditArch}
+		for i, arg := range args {
+			data[seccompArgs+8*uint32(i)] = arg
+		}
+		var acc uint32
+		for pc := 0; pc < len(prog); pc++ {
+			ins := prog[pc]
+			switch ins.Code {
+			case bpfLdWAbs:
+				acc = data[ins.K]
+			case bpfJeqK:
+				if acc == ins.K {
+					pc += int(ins.Jt)
+				} else {
+					pc += int(ins.Jf)
+				}
+			case bpfJgeK:
+				if acc >= ins.K {
+					pc += int(ins.Jt)
+				} else {
+					pc += int(ins.Jf)
+				}
+			case bpfAndK:
+				acc &= ins.K
+			case bpfRetK:
+				return ins.K
+			default:
+				t.Fatalf("unexpected instruction %+v", ins)
+			}
+		}
+		t.Fatalf("filter ran past its end for syscall %d", nr)
+		return 0
+	}
+
+	deny := seccompRetErrno | uint32(syscall.EPERM)
+	tests := []struct {
+		nr   uint32
+		args []uint32
+		want uint32
+	}{
+		{syscall.SYS_READ, nil, seccompRetAllow},
+		{syscall.SYS_OPENAT, nil, seccompRetAllow},
+		{syscall.SYS_SOCKET, []uint32{syscall.AF_UNIX, syscall.SOCK_DGRAM}, seccompRetAllow},
+		{syscall.SYS_SOCKET, []uint32{syscall.AF_UNIX, syscall.SOCK_DGRAM | syscall.SOCK_CLOEXEC}, seccompRetAllow},
+		{syscall.SYS_SOCKET, []uint32{syscall.AF_UNIX, syscall.SOCK_STREAM}, deny},
+		{syscall.SYS_SOCKET, []uint32{syscall.AF_UNIX, syscall.SOCK_SEQPACKET | syscall.SOCK_NONBLOCK}, deny},
+		{syscall.SYS_SOCKET, []uint32{syscall.AF_NETLINK, syscall.SOCK_DGRAM}, deny},
+		{syscall.SYS_SOCKET, []uint32{syscall.AF_NETLINK, syscall.SOCK_RAW}, deny},
+		{syscall.SYS_SOCKET, []uint32{syscall.AF_ALG, syscall.SOCK_SEQPACKET}, deny},
+		{syscall.SYS_SOCKETPAIR, []uint32{syscall.AF_UNIX, syscall.SOCK_STREAM}, seccompRetAllow},
+		{syscall.SYS_IOCTL, []uint32{0, syscall.TCGETS}, seccompRetAllow},
+		{syscall.SYS_IOCTL, []uint32{0, syscall.TIOCSTI}, deny},
+		{syscall.SYS_SOCKET, []uint32{syscall.AF_INET}, deny},
+		{syscall.SYS_SOCKET, []uint32{syscall.AF_INET6}, deny},
+		{syscall.SYS_SOCKET, []uint32{syscall.AF_PACKET}, deny},
+		{syscall.SYS_MOUNT, nil, deny},
+		{syscall.SYS_PTRACE, nil, deny},
+		{sysIOUringSetup, nil, deny},
+		{syscall.SYS_FCHMODAT, nil, deny},
+		{syscall.SYS_FCHOWNAT, nil, deny},
+		{syscall.SYS_TRUNCATE, nil, deny},
+		{syscall.SYS_SETXATTR, nil, deny},
+		{syscall.SYS_UTIMENSAT, nil, deny},
+		{syscall.SYS_KILL, []uint32{1, 0}, seccompRetAllow},
+		{syscall.SYS_KILL, []uint32{1, uint32(syscall.SIGTERM)}, deny},
+		{syscall.SYS_TGKILL, []uint32{1, 1, 0}, seccompRetAllow},
+		{syscall.SYS_TGKILL, []uint32{1, 1, uint32(syscall.SIGKILL)}, deny},
+		{sysPidfdSendSignal, []uint32{3, uint32(syscall.SIGKILL)}, deny},
+		{x32SyscallBit | syscall.SYS_READ, nil, deny},
+	}
+	for _, tc := range tests {
+		if got := run(tc.nr, tc.args...); got != tc.want {
+			t.Errorf("syscall %d%v: got %#x, want %#x", tc.nr, tc.args, got, tc.want)
+		}
+	}
+}
+
+// atFDCWD resolves paths relative to the working directory in *at syscalls
+const atFDCWD = -0x64
+
+func TestSandboxDeniesChanges(t *testing.T) {
+	if err := SandboxAvailable(); err != nil {
+		t.Skip(err)
+	}
+	dir, err := ioutil.TempDir("", "goss-sandbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(file, []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sleep := exec.Command("sleep", "60")
+	if err := sleep.Start(); err != nil {
+		t.Skip(err)
+	}
+	defer sleep.Wait()
+	defer sleep.Process.Kill()
+	pid := sleep.Process.Pid
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"chmod", func() error { return syscall.Chmod(file, 0777) }},
+		{"fchmodat", func() error { return syscall.Fchmodat(atFDCWD, file, 0777, 0) }},
+		{"chown", func() error { return syscall.Chown(file, 1, 1) }},
+		{"fchownat", func() error { return syscall.Fchownat(atFDCWD, file, 1, 1, 0) }},
+		{"truncate", func() error { return syscall.Truncate(file, 0) }},
+		{"setxattr", func() error { return syscall.Setxattr(file, "user.goss", []byte("x"), 0) }},
+		{"utimensat", func() error {
+			return syscall.UtimesNano(file, []syscall.Timespec{{Sec: 1}, {Sec: 1}})
+		}},
+		{"kill", func() error { return syscall.Kill(pid, syscall.SIGKILL) }},
+		{"netlink socket", func() error { return createSocket(syscall.AF_NETLINK, syscall.SOCK_RAW, syscall.NETLINK_ROUTE) }},
+		{"unix stream socket", func() error { return createSocket(syscall.AF_UNIX, syscall.SOCK_STREAM, 0) }},
+		{"unix seqpacket socket", func() error { return createSocket(syscall.AF_UNIX, syscall.SOCK_SEQPACKET|syscall.SOCK_CLOEXEC, 0) }},
+		{"inet socket", func() error { return createSocket(syscall.AF_INET, syscall.SOCK_STREAM, 0) }},
+		{"write", func() error { return ioutil.WriteFile(file, nil, 0644) }},
+	}
+
+	// The sandbox only applies to the thread entering it, the goroutine
+	// exits locked so its thread is thrown away
+	type result struct {
+		name string
+		err  error
+	}
+	results := make(chan result)
+	go func() {
+		defer close(results)
+		if err := EnterSandbox(); err != nil {
+			results <- result{"EnterSandbox", err}
+			return
+		}
+		results <- result{"EnterSandbox", nil}
+		for _, tc := range tests {
+			results <- result{tc.name, tc.call()}
+		}
+		results <- result{"kill 0", syscall.Kill(pid, 0)}
+		results <- result{"unix datagram socket", createSocket(syscall.AF_UNIX, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, 0)}
+	}()
+	if r := <-results; r.err != nil {
+		t.Fatal(r.err)
+	}
+	for r := range results {
+		switch {
+		case r.name == "kill 0" || r.name == "unix datagram socket":
+			if r.err != nil {
+				t.Errorf("%s: %v", r.name, r.err)
+			}
+		case r.err != syscall.EPERM && r.err != syscall.EACCES && !os.IsPermission(r.err):
+			t.Errorf("%s: got %v, want it denied", r.name, r.err)
+		}
+	}
+
+	after, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Mode() != before.Mode() || after.Size() != before.Size() || !after.ModTime().Equal(before.ModTime()) {
+		t.Errorf("file changed in the sandbox: %v %d %v", after.Mode(), after.Size(), after.ModTime())
+	}
+	if st := after.Sys().(*syscall.Stat_t); st.Uid != before.Sys().(*syscall.Stat_t).Uid {
+		t.Errorf("file owner changed in the sandbox: %d", st.Uid)
+	}
+	if err := sleep.Process.Signal(syscall.Signal(0)); err != nil {
+		t.Errorf("process killed in the sandbox: %v", err)
+	}
+}
+
+// createSocket creates a socket and closes it
+func createSocket(family, typ, proto int) error {
+	fd, err := syscall.Socket(family, typ, proto)
+	if err == nil {
+		syscall.Close(fd)
+	}
+	return err
+}
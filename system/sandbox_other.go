@@ -0,0 +1,23 @@
+// +build !linux !amd64,!arm64
+
+package system
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// SandboxAvailable always returns an error, sandboxing is only implemented on
+// Linux amd64 and arm64
+func SandboxAvailable() error {
+	return EnterSandbox()
+}
+
+func SandboxExec(argv []string) error {
+	return EnterSandbox()
+}
+
+// EnterSandbox is only implemented on Linux amd64 and arm64
+func EnterSandbox() error {
+	return fmt.Errorf("sandbox: not supported on %s/%s", runtime.GOOS, runtime.GOARCH)
+}
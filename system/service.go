@@ -9,6 +9,29 @@ type Service interface {
 	Running() (bool, error)
 }
 
+// ServiceProperties is implemented by services of service managers that
+// describe units with properties, such as systemd
+type ServiceProperties interface {
+	Property(name string) (string, error)
+}
+
+// ServiceFailures is implemented by services of service managers that track
+// services that stopped unexpectedly, Restarts is how often the manager
+// restarted the service since it was started
+type ServiceFailures interface {
+	Masked() (bool, error)
+	Failed() (bool, error)
+	Restarts() (int, error)
+}
+
+// ServiceDependencies is implemented by services of service managers that
+// start units in the order of their dependencies, such as systemd. Verified
+// is whether the manager finds no errors in the unit, such as cycles.
+type ServiceDependencies interface {
+	Dependencies(kind string) ([]string, error)
+	Verified() (bool, error)
+}
+
 func invalidService(s string) bool {
 	if strings.ContainsRune(s, '/') {
 		return true
@@ -0,0 +1,73 @@
+package system
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// ServiceBSD is a service of the rc.d scripts of FreeBSD or OpenBSD, it's
+// enabled in rc.conf with service on FreeBSD and rcctl on OpenBSD
+type ServiceBSD struct {
+	service string
+	openbsd bool
+}
+
+func NewServiceFreeBSD(service string, system *System, config util.Config) Service {
+	return &ServiceBSD{service: service}
+}
+
+func NewServiceOpenBSD(service string, system *System, config util.Config) Service {
+	return &ServiceBSD{service: service, openbsd: true}
+}
+
+func (s *ServiceBSD) Service() string {
+	return s.service
+}
+
+func (s *ServiceBSD) Exists() (bool, error) {
+	if invalidService(s.service) {
+		return false, nil
+	}
+	for _, f := range []string{"/etc/rc.d/%s", "/usr/local/etc/rc.d/%s"} {
+		if _, err := os.Stat(fmt.Sprintf(f, s.service)); err == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *ServiceBSD) Enabled() (bool, error) {
+	if invalidService(s.service) {
+		return false, nil
+	}
+	if s.openbsd {
+		return s.succeeds("rcctl", "get", s.service, "status")
+	}
+	return s.succeeds("service", s.service, "enabled")
+}
+
+// Running uses onestatus on FreeBSD, status fails for services that aren't
+// enabled even when they run
+func (s *ServiceBSD) Running() (bool, error) {
+	if invalidService(s.service) {
+		return false, nil
+	}
+	if s.openbsd {
+		return s.succeeds("rcctl", "check", s.service)
+	}
+	return s.succeeds("service", s.service, "onestatus")
+}
+
+func (s *ServiceBSD) succeeds(name string, args ...string) (bool, error) {
+	if exists, err := s.Exists(); !exists || err != nil {
+		return false, err
+	}
+	cmd := util.NewCommand(name, args...)
+	cmd.Run()
+	if cmd.Status == 0 {
+		return true, cmd.Err
+	}
+	return false, nil
+}
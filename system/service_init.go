@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/aelsabbahy/goss/util"
 )
@@ -73,3 +74,28 @@ func alpineInitServiceEnabled(service string, level string) (bool, error) {
 	}
 	return false, err
 }
+
+// Failed reports whether the service died, LSB init scripts exit with status
+// 1 or 2 when its pid or lock file is left behind and openrc reports it
+// crashed
+func (s *ServiceInit) Failed() (bool, error) {
+	if invalidService(s.service) {
+		return false, nil
+	}
+	cmd := util.NewCommand("service", s.service, "status")
+	cmd.Run()
+	if s.alpine {
+		return strings.Contains(cmd.Stdout.String()+cmd.Stderr.String(), "crashed"), nil
+	}
+	return cmd.Status == 1 || cmd.Status == 2, nil
+}
+
+// Masked fails, init can't mask services
+func (s *ServiceInit) Masked() (bool, error) {
+	return false, fmt.Errorf("masked is only supported with systemd")
+}
+
+// Restarts fails, init doesn't restart services that died
+func (s *ServiceInit) Restarts() (int, error) {
+	return 0, fmt.Errorf("n-restarts is only supported with systemd")
+}
@@ -0,0 +1,59 @@
+package system
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// ServiceProc finds services by their processes, it's used in containers
+// without a service manager where nothing is started at boot
+type ServiceProc struct {
+	service string
+	system  *System
+}
+
+func NewServiceProc(service string, system *System, config util.Config) Service {
+	return &ServiceProc{service: service, system: system}
+}
+
+func (s *ServiceProc) Service() string {
+	return s.service
+}
+
+func (s *ServiceProc) Exists() (bool, error) {
+	if invalidService(s.service) {
+		return false, nil
+	}
+	if running, err := s.Running(); running || err != nil {
+		return running, err
+	}
+	for _, f := range []string{"/etc/init.d/%s", "/etc/systemd/system/%s.service", "/lib/systemd/system/%s.service", "/usr/lib/systemd/system/%s.service"} {
+		if _, err := os.Stat(fmt.Sprintf(f, s.service)); err == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Enabled is always false as nothing is started at boot
+func (s *ServiceProc) Enabled() (bool, error) {
+	return false, nil
+}
+
+// Running reports whether a process named like the service runs
+func (s *ServiceProc) Running() (bool, error) {
+	if invalidService(s.service) {
+		return false, nil
+	}
+	pmap, err := s.system.ProcMap()
+	if err != nil {
+		return false, err
+	}
+	comm := s.service
+	if len(comm) > 15 {
+		comm = comm[:15]
+	}
+	return len(pmap[comm]) > 0, nil
+}
@@ -0,0 +1,135 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// serviceRoot is prefixed to the paths ServiceProcfs reads
+var serviceRoot = ""
+
+// runitServices are where runit and busybox runsvdir look for the services
+// they supervise, a service is enabled when it's linked there
+var runitServices = []string{"/etc/service", "/var/service", "/etc/runit/runsvdir/default"}
+
+// ServiceProcfs reads services from /proc and the init configuration in /etc
+// without running a service manager, it's used on busybox based images where
+// neither systemctl nor service exist. Scripts of busybox init and openrc,
+// runit services and inittab entries are understood.
+type ServiceProcfs struct {
+	ServiceProc
+}
+
+func NewServiceProcfs(service string, system *System, config util.Config) Service {
+	return &ServiceProcfs{ServiceProc{service: service, system: system}}
+}
+
+func (s *ServiceProcfs) Exists() (bool, error) {
+	if invalidService(s.service) {
+		return false, nil
+	}
+	if running, err := s.Running(); running || err != nil {
+		return running, err
+	}
+	if enabled, err := s.Enabled(); enabled || err != nil {
+		return enabled, err
+	}
+	for _, f := range []string{"/etc/init.d/%s", "/etc/sv/%s"} {
+		if _, err := os.Stat(serviceRoot + fmt.Sprintf(f, s.service)); err == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Enabled reports whether init starts the service, a start script in
+// /etc/init.d or an rc directory, an openrc runlevel, a runit service or a
+// process of /etc/inittab
+func (s *ServiceProcfs) Enabled() (bool, error) {
+	if invalidService(s.service) {
+		return false, nil
+	}
+	patterns := []string{
+		// busybox and buildroot run the S scripts of /etc/init.d in order
+		"/etc/init.d/S[0-9][0-9]%s",
+		"/etc/rc[2345].d/S[0-9][0-9]%s",
+		"/etc/runlevels/*/%s",
+	}
+	for _, d := range runitServices {
+		patterns = append(patterns, d+"/%s")
+	}
+	for _, p := range patterns {
+		matches, err := filepath.Glob(serviceRoot + fmt.Sprintf(p, s.service))
+		if err != nil {
+			return false, err
+		}
+		if matches != nil {
+			return true, nil
+		}
+	}
+	return inittabHasService(serviceRoot+"/etc/inittab", s.service)
+}
+
+// Running reports whether the runit supervisor or the pid file of the
+// service says it runs, otherwise whether a process named like it does
+func (s *ServiceProcfs) Running() (bool, error) {
+	if invalidService(s.service) {
+		return false, nil
+	}
+	for _, d := range runitServices {
+		if stat, err := ioutil.ReadFile(serviceRoot + path.Join(d, s.service, "supervise/stat")); err == nil {
+			return strings.TrimSpace(string(stat)) == "run", nil
+		}
+	}
+	for _, f := range []string{"/run/%s.pid", "/var/run/%s.pid", "/run/%s/%s.pid", "/var/run/%s/%s.pid"} {
+		pidFile := strings.Replace(f, "%s", s.service, -1)
+		b, err := ioutil.ReadFile(serviceRoot + pidFile)
+		if err != nil {
+			continue
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(fmt.Sprintf("%s/proc/%d", serviceRoot, pid)); err == nil {
+			return true, nil
+		}
+	}
+	return s.ServiceProc.Running()
+}
+
+// inittabHasService reports whether an entry of inittab, id:runlevels:action:process,
+// runs the service's binary
+func inittabHasService(inittab, service string) (bool, error) {
+	f, err := os.Open(inittab)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.SplitN(line, ":", 4)
+		if len(fields) < 4 || fields[2] == "off" {
+			continue
+		}
+		process := strings.Fields(strings.TrimPrefix(fields[3], "-"))
+		if len(process) > 0 && path.Base(process[0]) == service {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
@@ -0,0 +1,63 @@
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+func TestServiceProcfs(t *testing.T) {
+	root, err := ioutil.TempDir("", "goss-service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	defer func(r string) { serviceRoot = r }(serviceRoot)
+	serviceRoot = root
+
+	write := func(name, content string) {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("etc/init.d/S50sshd", "")
+	write("etc/init.d/ntpd", "")
+	write("etc/inittab", "::sysinit:/etc/init.d/rcS\n# ::respawn:/sbin/crond\nttyS0::respawn:-/sbin/getty -L ttyS0 115200\n")
+	write("etc/service/dropbear/supervise/stat", "run\n")
+	write("etc/service/udhcpc/supervise/stat", "down\n")
+	write("run/sshd.pid", "4242\n")
+	write("proc/4242/stat", "")
+	write("var/run/ntpd.pid", "4343\n")
+
+	sys := &System{procMap: map[string][]Proc{}}
+	sys.procOnce.Do(func() {})
+
+	tests := []struct {
+		service                  string
+		exists, enabled, running bool
+	}{
+		{"sshd", true, true, true},
+		{"ntpd", true, false, false},
+		{"getty", true, true, false},
+		{"crond", false, false, false},
+		{"dropbear", true, true, true},
+		{"udhcpc", true, true, false},
+		{"rcS", true, true, false},
+	}
+	for _, tt := range tests {
+		s := NewServiceProcfs(tt.service, sys, util.Config{})
+		exists, _ := s.Exists()
+		enabled, _ := s.Enabled()
+		running, _ := s.Running()
+		if exists != tt.exists || enabled != tt.enabled || running != tt.running {
+			t.Errorf("%s: got exists %v, enabled %v, running %v, want %v, %v, %v", tt.service, exists, enabled, running, tt.exists, tt.enabled, tt.running)
+		}
+	}
+}
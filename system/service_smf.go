@@ -0,0 +1,104 @@
+package system
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// ServiceSMF is a service of the Service Management Facility of Solaris and
+// illumos, the name is an FMRI or an abbreviation of one svcs accepts, such
+// as ssh or network/ssh:default
+type ServiceSMF struct {
+	service string
+	loaded  bool
+	state   string
+}
+
+func NewServiceSMF(service string, system *System, config util.Config) Service {
+	return &ServiceSMF{service: service}
+}
+
+func (s *ServiceSMF) Service() string {
+	return s.service
+}
+
+// setup reads the state of the instance, online, offline, degraded,
+// maintenance, disabled, uninitialized or legacy_run for rc scripts. It's
+// empty when svcs doesn't know the service or it matches several instances.
+func (s *ServiceSMF) setup() {
+	if s.loaded {
+		return
+	}
+	s.loaded = true
+	if invalidSMFService(s.service) {
+		return
+	}
+	cmd := util.NewCommand("svcs", "-H", "-o", "state", s.service)
+	if err := cmd.Run(); err != nil {
+		return
+	}
+	if states := strings.Fields(cmd.Stdout.String()); len(states) == 1 {
+		s.state = states[0]
+	}
+}
+
+// invalidSMFService rejects names svcs would read as options, FMRIs have
+// slashes unlike the names of other services
+func invalidSMFService(s string) bool {
+	return s == "" || strings.HasPrefix(s, "-")
+}
+
+func (s *ServiceSMF) Exists() (bool, error) {
+	s.setup()
+	return s.state != "", nil
+}
+
+// Enabled reports whether the service isn't disabled, a service in
+// maintenance is still enabled
+func (s *ServiceSMF) Enabled() (bool, error) {
+	s.setup()
+	return s.state != "" && s.state != "disabled", nil
+}
+
+// Running reports whether the service is online, degraded services run with
+// reduced capacity
+func (s *ServiceSMF) Running() (bool, error) {
+	s.setup()
+	switch s.state {
+	case "online", "degraded", "legacy_run":
+		return true, nil
+	}
+	return false, nil
+}
+
+// Failed reports whether the service is in maintenance, where the restarter
+// puts it when it failed and until svcadm clear
+func (s *ServiceSMF) Failed() (bool, error) {
+	s.setup()
+	return s.state == "maintenance", nil
+}
+
+// Masked fails, SMF can't mask services
+func (s *ServiceSMF) Masked() (bool, error) {
+	return false, fmt.Errorf("masked is only supported with systemd")
+}
+
+// Restarts fails, svcs doesn't report how often a service was restarted
+func (s *ServiceSMF) Restarts() (int, error) {
+	return 0, fmt.Errorf("n-restarts is only supported with systemd")
+}
+
+// Property is a property of the service as svcprop reports it, such as
+// start/exec or general/enabled
+func (s *ServiceSMF) Property(name string) (string, error) {
+	if invalidSMFService(s.service) {
+		return "", fmt.Errorf("invalid service %q", s.service)
+	}
+	cmd := util.NewCommand("svcprop", "-p", name, s.service)
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("svcprop -p %s %s: %v: %s", name, s.service, err, strings.TrimSpace(cmd.Stderr.String()))
+	}
+	return strings.TrimSpace(cmd.Stdout.String()), nil
+}
@@ -0,0 +1,64 @@
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+func TestServiceSMF(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goss-svcs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	svcs := "#!/bin/sh\ncase \"$4\" in\n" +
+		"ssh) echo online ;;\n" +
+		"network/nfs/server) echo maintenance ;;\n" +
+		"sendmail) echo disabled ;;\n" +
+		"svc:/application/rc) echo legacy_run ;;\n" +
+		"network) printf 'online\\nonline\\n' ;;\n" +
+		"*) echo \"svcs: Pattern '$4' doesn't match any instances\" >&2; exit 1 ;;\nesac\n"
+	svcprop := "#!/bin/sh\n[ \"$1 $2 $3\" = \"-p start/exec ssh\" ] || exit 1\necho '/lib/svc/method/sshd\\ start'\n"
+	for name, script := range map[string]string{"svcs": svcs, "svcprop": svcprop} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	tests := []struct {
+		service                          string
+		exists, enabled, running, failed bool
+	}{
+		{"ssh", true, true, true, false},
+		{"network/nfs/server", true, true, false, true},
+		{"sendmail", true, false, false, false},
+		{"svc:/application/rc", true, true, true, false},
+		{"network", false, false, false, false},
+		{"missing", false, false, false, false},
+		{"-a", false, false, false, false},
+	}
+	for _, tt := range tests {
+		s := NewServiceSMF(tt.service, nil, util.Config{})
+		exists, _ := s.Exists()
+		enabled, _ := s.Enabled()
+		running, _ := s.Running()
+		failed, _ := s.(ServiceFailures).Failed()
+		if exists != tt.exists || enabled != tt.enabled || running != tt.running || failed != tt.failed {
+			t.Errorf("%s: got exists %v, enabled %v, running %v, failed %v", tt.service, exists, enabled, running, failed)
+		}
+	}
+
+	p := NewServiceSMF("ssh", nil, util.Config{}).(ServiceProperties)
+	if got, err := p.Property("start/exec"); err != nil || got != `/lib/svc/method/sshd\ start` {
+		t.Errorf("start/exec was incorrect, got: %q, %v", got, err)
+	}
+	if _, err := p.Property("general/nosuch"); err == nil {
+		t.Errorf("Property accepted an unknown property")
+	}
+}
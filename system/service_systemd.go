@@ -2,14 +2,16 @@ package system
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/aelsabbahy/goss/util"
 )
 
 type ServiceSystemd struct {
-	service string
-	legacy  bool
+	service    string
+	legacy     bool
+	properties map[string]string
 }
 
 func NewServiceSystemd(service string, system *System, config util.Config) Service {
@@ -85,3 +87,128 @@ func (s *ServiceSystemd) Running() (bool, error) {
 	}
 	return false, nil
 }
+
+// Property is a property of the unit as systemctl show reports it, such as
+// Restart or MemoryMax
+func (s *ServiceSystemd) Property(name string) (string, error) {
+	if invalidService(s.service) {
+		return "", fmt.Errorf("invalid service %q", s.service)
+	}
+	if s.properties == nil {
+		cmd := util.NewCommand("systemctl", "show", "--no-pager", s.service)
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("systemctl show %s: %v: %s", s.service, err, strings.TrimSpace(cmd.Stderr.String()))
+		}
+		properties := make(map[string]string)
+		for _, line := range strings.Split(cmd.Stdout.String(), "\n") {
+			if i := strings.IndexByte(line, '='); i > 0 {
+				properties[line[:i]] = line[i+1:]
+			}
+		}
+		s.properties = properties
+	}
+	v, ok := s.properties[name]
+	if !ok {
+		return "", fmt.Errorf("unknown property %q of %s", name, s.service)
+	}
+	return v, nil
+}
+
+// Masked reports whether the unit is linked to /dev/null, so it can't be
+// started
+func (s *ServiceSystemd) Masked() (bool, error) {
+	state, err := s.Property("LoadState")
+	if err != nil {
+		return false, err
+	}
+	return state == "masked", nil
+}
+
+// Failed reports whether the unit stopped unexpectedly, it's failed until
+// it's started again or reset with systemctl reset-failed
+func (s *ServiceSystemd) Failed() (bool, error) {
+	state, err := s.Property("ActiveState")
+	if err != nil {
+		return false, err
+	}
+	return state == "failed", nil
+}
+
+// Restarts is NRestarts, how often systemd restarted the unit since it was
+// last started by hand, it's reset by systemctl restart
+func (s *ServiceSystemd) Restarts() (int, error) {
+	n, err := s.Property("NRestarts")
+	if err != nil {
+		return 0, fmt.Errorf("%v, systemd before 235 doesn't count restarts", err)
+	}
+	return strconv.Atoi(n)
+}
+
+// systemdDependencies are the unit properties of the kinds of dependencies
+var systemdDependencies = map[string]string{
+	"wants":       "Wants",
+	"requires":    "Requires",
+	"requisite":   "Requisite",
+	"binds-to":    "BindsTo",
+	"part-of":     "PartOf",
+	"conflicts":   "Conflicts",
+	"after":       "After",
+	"before":      "Before",
+	"wanted-by":   "WantedBy",
+	"required-by": "RequiredBy",
+}
+
+// Dependencies are the units of a kind of dependency of the unit, such as
+// the units it wants or is started after, with those of its drop-ins
+func (s *ServiceSystemd) Dependencies(kind string) ([]string, error) {
+	property, ok := systemdDependencies[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown dependency %q", kind)
+	}
+	v, err := s.Property(property)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(v), nil
+}
+
+// Verified reports whether systemd-analyze verify finds no errors in the
+// unit and no ordering cycles it's part of, which systemd only breaks at
+// boot by not starting one of the units
+func (s *ServiceSystemd) Verified() (bool, error) {
+	if invalidService(s.service) {
+		return false, fmt.Errorf("invalid service %q", s.service)
+	}
+	cmd := util.NewCommand("systemd-analyze", "verify", s.service+".service")
+	cmd.Run()
+	if cmd.Err != nil && cmd.Status == 0 {
+		return false, fmt.Errorf("systemd-analyze verify %s: %v", s.service, cmd.Err)
+	}
+	if cmd.Status != 0 || strings.Contains(cmd.Stderr.String(), "ordering cycle") {
+		util.Log().Debug("unit not verified", "service", s.service, "output", strings.TrimSpace(cmd.Stderr.String()))
+		return false, nil
+	}
+	return true, nil
+}
+
+// EnabledSystemdServices are the names of the services systemd starts at
+// boot, without the template units it can't check
+func EnabledSystemdServices() ([]string, error) {
+	cmd := util.NewCommand("systemctl", "list-unit-files", "--type=service", "--state=enabled", "--no-legend", "--no-pager")
+	cmd.Run()
+	if stderr := strings.TrimSpace(cmd.Stderr.String()); cmd.Status != 0 && stderr != "" {
+		return nil, fmt.Errorf("listing systemd services: %s", stderr)
+	}
+	if cmd.Err != nil && cmd.Status == 0 {
+		return nil, fmt.Errorf("listing systemd services: %v", cmd.Err)
+	}
+	var services []string
+	for _, line := range strings.Split(cmd.Stdout.String(), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || !strings.HasSuffix(fields[0], ".service") || strings.HasSuffix(fields[0], "@.service") {
+			continue
+		}
+		services = append(services, strings.TrimSuffix(fields[0], ".service"))
+	}
+	return services, nil
+}
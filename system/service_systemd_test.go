@@ -0,0 +1,165 @@
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+func TestServiceSystemdProperty(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goss-systemctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	script := "#!/bin/sh\n[ \"$1 $2 $3\" = \"show --no-pager sshd\" ] || exit 1\n" +
+		"printf 'Restart=always\\nNRestarts=0\\nEnvironment=A=1 B=2\\nUser=\\n'\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "systemctl"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	s := NewServiceSystemd("sshd", nil, util.Config{}).(ServiceProperties)
+	tables := []struct {
+		name string
+		want string
+	}{
+		{"Restart", "always"},
+		{"NRestarts", "0"},
+		{"Environment", "A=1 B=2"},
+		{"User", ""},
+	}
+	for _, table := range tables {
+		got, err := s.Property(table.name)
+		if err != nil {
+			t.Errorf("Property (%s) failed: %v", table.name, err)
+			continue
+		}
+		if got != table.want {
+			t.Errorf("Property (%s) was incorrect, got: %q, want: %q.", table.name, got, table.want)
+		}
+	}
+	if _, err := s.Property("NoSuchProperty"); err == nil {
+		t.Errorf("Property accepted an unknown property")
+	}
+}
+
+func TestServiceSystemdFailures(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goss-systemctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	script := "#!/bin/sh\ncase \"$3\" in\n" +
+		"web) printf 'LoadState=loaded\\nActiveState=active\\nNRestarts=7\\n' ;;\n" +
+		"cron) printf 'LoadState=masked\\nActiveState=failed\\nNRestarts=0\\n' ;;\n" +
+		"old) printf 'LoadState=loaded\\nActiveState=inactive\\n' ;;\n" +
+		"*) exit 1 ;;\nesac\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "systemctl"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	tests := []struct {
+		service        string
+		masked, failed bool
+		restarts       int
+		restartsErr    bool
+	}{
+		{service: "web", restarts: 7},
+		{service: "cron", masked: true, failed: true},
+		{service: "old", restartsErr: true},
+	}
+	for _, tt := range tests {
+		s := NewServiceSystemd(tt.service, nil, util.Config{}).(ServiceFailures)
+		if got, err := s.Masked(); err != nil || got != tt.masked {
+			t.Errorf("%s: masked: got %v, %v", tt.service, got, err)
+		}
+		if got, err := s.Failed(); err != nil || got != tt.failed {
+			t.Errorf("%s: failed: got %v, %v", tt.service, got, err)
+		}
+		got, err := s.Restarts()
+		if tt.restartsErr {
+			if err == nil {
+				t.Errorf("%s: n-restarts: want an error without NRestarts", tt.service)
+			}
+		} else if err != nil || got != tt.restarts {
+			t.Errorf("%s: n-restarts: got %d, %v", tt.service, got, err)
+		}
+	}
+}
+
+func TestServiceSystemdDependencies(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goss-systemctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	systemctl := "#!/bin/sh\nprintf 'Wants=network-online.target\\nAfter=network-online.target basic.target\\nRequires=\\n'\n"
+	analyze := "#!/bin/sh\ncase \"$2\" in\n" +
+		"web.service) ;;\n" +
+		"cycle.service) echo 'cycle.target: Found ordering cycle on web.service/start' >&2 ;;\n" +
+		"*) echo 'Unit broken.service not found.' >&2; exit 1 ;;\nesac\n"
+	for name, script := range map[string]string{"systemctl": systemctl, "systemd-analyze": analyze} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	s := NewServiceSystemd("web", nil, util.Config{}).(ServiceDependencies)
+	tables := []struct {
+		kind string
+		want []string
+	}{
+		{"wants", []string{"network-online.target"}},
+		{"after", []string{"network-online.target", "basic.target"}},
+		{"requires", []string{}},
+	}
+	for _, table := range tables {
+		got, err := s.Dependencies(table.kind)
+		if err != nil || len(got) != len(table.want) || len(got) > 0 && !reflect.DeepEqual(got, table.want) {
+			t.Errorf("Dependencies (%s) was incorrect, got: %v, %v, want: %v.", table.kind, got, err, table.want)
+		}
+	}
+	if _, err := s.Dependencies("needs"); err == nil {
+		t.Errorf("Dependencies accepted an unknown kind")
+	}
+
+	for service, want := range map[string]bool{"web": true, "cycle": false, "broken": false} {
+		got, err := NewServiceSystemd(service, nil, util.Config{}).(ServiceDependencies).Verified()
+		if err != nil || got != want {
+			t.Errorf("%s: verified: got %v, %v, want: %v", service, got, err, want)
+		}
+	}
+}
+
+func TestEnabledSystemdServices(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goss-systemctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	script := "#!/bin/sh\n[ \"$1 $2 $3\" = \"list-unit-files --type=service --state=enabled\" ] || exit 1\n" +
+		"printf 'cron.service enabled enabled\\ngetty@.service enabled enabled\\nsshd.service enabled disabled\\n'\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "systemctl"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	services, err := EnabledSystemdServices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"cron", "sshd"}; !reflect.DeepEqual(services, want) {
+		t.Errorf("EnabledSystemdServices was incorrect, got: %v, want: %v.", services, want)
+	}
+}
@@ -0,0 +1,279 @@
+package system
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aelsabbahy/goss/util"
+	"github.com/opencontainers/runc/libcontainer/user"
+)
+
+// ShellProfile is the startup configuration of login shells, "default" for
+// the system wide configuration or a user name to include the user's files
+type ShellProfile interface {
+	User() string
+	Exists() (bool, error)
+	Umask() (string, error)
+	Files() ([]string, error)
+	Contains() (io.Reader, error)
+}
+
+type DefShellProfile struct {
+	user   string
+	loaded bool
+	err    error
+	umask  int
+	files  []string
+}
+
+// loginDefs configures the umask that login starts shells with
+var loginDefs = "/etc/login.defs"
+
+func NewDefShellProfile(name string, system *System, config util.Config) ShellProfile {
+	return &DefShellProfile{user: name}
+}
+
+func (p *DefShellProfile) User() string {
+	return p.user
+}
+
+func (p *DefShellProfile) Exists() (bool, error) {
+	if p.user == "default" {
+		return true, nil
+	}
+	if _, err := user.LookupUser(p.user); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+func (p *DefShellProfile) setup() error {
+	if p.loaded {
+		return p.err
+	}
+	p.loaded = true
+
+	shell := "bash"
+	home := ""
+	var u user.User
+	if p.user != "default" {
+		var err error
+		if u, err = user.LookupUser(p.user); err != nil {
+			p.err = err
+			return p.err
+		}
+		shell, home = filepath.Base(u.Shell), u.Home
+	}
+	p.files = profileFiles(shell, home)
+
+	defs, err := readLoginDefs(loginDefs)
+	if err != nil {
+		p.err = err
+		return p.err
+	}
+	p.umask = 022
+	if v, ok := defs["UMASK"]; ok {
+		if p.umask, err = parseOctalUmask(v); err != nil {
+			p.err = fmt.Errorf("%s: invalid UMASK %q", loginDefs, v)
+			return p.err
+		}
+	}
+	// pam_umask gives users with a group of their own the owner bits as group bits
+	if strings.EqualFold(defs["USERGROUPS_ENAB"], "yes") && p.user != "default" && u.Uid != 0 {
+		if g, err := user.LookupGid(u.Gid); err == nil && g.Name == u.Name {
+			p.umask = p.umask&^070 | (p.umask&0700)>>3
+		}
+	}
+
+	for _, f := range p.files {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			p.err = err
+			return p.err
+		}
+		if p.umask, err = scriptUmask(data, p.umask); err != nil {
+			p.err = fmt.Errorf("%s: %v", f, err)
+			return p.err
+		}
+	}
+	return nil
+}
+
+// Umask is the umask login shells end up with, starting with UMASK from
+// login.defs and applying every umask command in the startup files in the order
+// the shell reads them. The files aren't run, so conditions are ignored and the
+// last umask command wins
+func (p *DefShellProfile) Umask() (string, error) {
+	if err := p.setup(); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%04o", p.umask), nil
+}
+
+// Files are the startup files a login shell reads, in order
+func (p *DefShellProfile) Files() ([]string, error) {
+	if err := p.setup(); err != nil {
+		return nil, err
+	}
+	return p.files, nil
+}
+
+// Contains is the content of Files one after another
+func (p *DefShellProfile) Contains() (io.Reader, error) {
+	if err := p.setup(); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	for _, f := range p.files {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+	return &buf, nil
+}
+
+// profileFiles returns the existing startup files of a login shell with home,
+// bash reads the first of its login files and, as distributions set it up, the
+// bashrc files. Other shells read the POSIX profile files
+func profileFiles(shell, home string) []string {
+	candidates := []string{"/etc/profile"}
+	if d, err := filepath.Glob("/etc/profile.d/*.sh"); err == nil {
+		sort.Strings(d)
+		candidates = append(candidates, d...)
+	}
+	if shell == "bash" {
+		candidates = append(candidates, "/etc/bash.bashrc", "/etc/bashrc")
+	}
+	if home != "" {
+		if shell == "bash" {
+			for _, f := range []string{".bash_profile", ".bash_login", ".profile"} {
+				if isFile(filepath.Join(home, f)) {
+					candidates = append(candidates, filepath.Join(home, f))
+					break
+				}
+			}
+			candidates = append(candidates, filepath.Join(home, ".bashrc"))
+		} else {
+			candidates = append(candidates, filepath.Join(home, ".profile"))
+		}
+	}
+
+	files := []string{}
+	for _, f := range candidates {
+		if isFile(f) && !contains(files, f) {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+func isFile(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.Mode().IsRegular()
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+func readLoginDefs(path string) (map[string]string, error) {
+	defs := make(map[string]string)
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return defs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && !strings.HasPrefix(fields[0], "#") {
+			defs[fields[0]] = fields[1]
+		}
+	}
+	return defs, scanner.Err()
+}
+
+var umaskCommand = regexp.MustCompile(`(?:^|[;&|(]|\bthen|\belse|\bdo)\s*umask\s+(?:-S\s+)?([0-7]{1,4}|[ugoa]*[=+-][rwx]*(?:,[ugoa]*[=+-][rwx]*)*)(?:[\s;&|)]|$)`)
+
+// scriptUmask applies the umask commands in script to umask
+func scriptUmask(script []byte, umask int) (int, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(script))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, m := range umaskCommand.FindAllStringSubmatch(line, -1) {
+			var err error
+			if umask, err = applyUmask(umask, m[1]); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return umask, scanner.Err()
+}
+
+func parseOctalUmask(s string) (int, error) {
+	v, err := strconv.ParseInt(s, 8, 0)
+	if err != nil || v < 0 || v > 0777 {
+		return 0, fmt.Errorf("invalid umask %q", s)
+	}
+	return int(v), nil
+}
+
+// applyUmask returns the umask after running umask with arg, an octal or
+// symbolic mode such as u=rwx,g=rx,o=
+func applyUmask(umask int, arg string) (int, error) {
+	if arg != "" && arg[0] >= '0' && arg[0] <= '7' {
+		return parseOctalUmask(arg)
+	}
+	// Symbolic modes describe the permissions that are allowed
+	perms := ^umask & 0777
+	for _, clause := range strings.Split(arg, ",") {
+		i := strings.IndexAny(clause, "=+-")
+		if i == -1 {
+			return 0, fmt.Errorf("invalid umask %q", arg)
+		}
+		who, op, what := clause[:i], clause[i], clause[i+1:]
+		if who == "" || strings.Contains(who, "a") {
+			who = "ugo"
+		}
+		bits := 0
+		for _, r := range what {
+			bits |= map[rune]int{'r': 4, 'w': 2, 'x': 1}[r]
+		}
+		for _, w := range who {
+			shift := map[rune]uint{'u': 6, 'g': 3, 'o': 0}[w]
+			switch op {
+			case '=':
+				perms = perms&^(7<<shift) | bits<<shift
+			case '+':
+				perms |= bits << shift
+			case '-':
+				perms &^= bits << shift
+			}
+		}
+	}
+	return ^perms & 0777, nil
+}
@@ -0,0 +1,38 @@
+package system
+
+import "testing"
+
+func TestScriptUmask(t *testing.T) {
+	tables := []struct {
+		script string
+		want   int
+	}{
+		{"", 022},
+		{"umask 027\n", 027},
+		{"# umask 077\n  umask 0077 # strict\n", 077},
+		{"if [ $UID -gt 199 ]; then umask 002; else umask 027; fi\n", 027},
+		{"[ -n \"$PS1\" ] && umask 007\n", 007},
+		{"umask u=rwx,g=rx,o=\n", 027},
+		{"umask -S g-w,o-rwx\n", 027},
+		{"umask a+r\n", 022},
+		{"umask\nalias umaskit='x'\n", 022},
+	}
+
+	for _, table := range tables {
+		got, err := scriptUmask([]byte(table.script), 022)
+		if err != nil {
+			t.Errorf("scriptUmask (%q) failed: %v", table.script, err)
+			continue
+		}
+		if got != table.want {
+			t.Errorf("scriptUmask (%q) was incorrect, got: %04o, want: %04o.", table.script, got, table.want)
+		}
+	}
+
+	if _, err := scriptUmask([]byte("umask 0999\n"), 022); err != nil {
+		t.Errorf("scriptUmask treated a non-octal argument as a umask: %v", err)
+	}
+	if _, err := applyUmask(022, "7777"); err == nil {
+		t.Errorf("applyUmask accepted an out of range umask")
+	}
+}
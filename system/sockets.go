@@ -0,0 +1,233 @@
+package system
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// Sockets are the TCP sockets of a port, a process or the host counted by
+// state. The name is all, a local port such as 80, tcp:80 or tcp6:80, a
+// remote port such as remote:5432 or the sockets of the processes of an
+// executable such as process:nginx.
+type Sockets interface {
+	Name() string
+	Exists() (bool, error)
+	Established() (int, error)
+	SynSent() (int, error)
+	SynRecv() (int, error)
+	FinWait() (int, error)
+	TimeWait() (int, error)
+	CloseWait() (int, error)
+	LastAck() (int, error)
+	Listen() (int, error)
+	Total() (int, error)
+}
+
+type DefSockets struct {
+	name   string
+	system *System
+	loaded bool
+	err    error
+	counts map[string]int
+	total  int
+}
+
+// socketStates are the names of the states of /proc/net/tcp, fin-wait
+// counts both FIN_WAIT states
+var socketStates = map[string]string{
+	"01": "ESTABLISHED",
+	"02": "SYN_SENT",
+	"03": "SYN_RECV",
+	"04": "FIN_WAIT",
+	"05": "FIN_WAIT",
+	"06": "TIME_WAIT",
+	"07": "CLOSE",
+	"08": "CLOSE_WAIT",
+	"09": "LAST_ACK",
+	"0A": "LISTEN",
+	"0B": "CLOSING",
+}
+
+// socket is a line of /proc/net/tcp or tcp6
+type socket struct {
+	net        string
+	localPort  int64
+	remotePort int64
+	state      string
+	inode      string
+}
+
+func NewDefSockets(name string, system *System, config util.Config) Sockets {
+	return &DefSockets{name: name, system: system}
+}
+
+func (s *DefSockets) Name() string {
+	return s.name
+}
+
+// Exists is whether the name is valid and, for a process, whether it runs
+func (s *DefSockets) Exists() (bool, error) {
+	if err := s.setup(); err != nil {
+		return false, err
+	}
+	if strings.HasPrefix(s.name, "process:") {
+		procs, err := s.system.ProcMap()
+		return len(procs[strings.TrimPrefix(s.name, "process:")]) > 0, err
+	}
+	return true, nil
+}
+
+func (s *DefSockets) setup() error {
+	if s.loaded {
+		return s.err
+	}
+	s.loaded = true
+
+	match, err := s.matcher()
+	if err != nil {
+		s.err = err
+		return s.err
+	}
+	sockets, err := readSockets()
+	if err != nil {
+		s.err = err
+		return s.err
+	}
+	s.counts = make(map[string]int)
+	for _, sock := range sockets {
+		if match(sock) {
+			s.counts[sock.state]++
+			s.total++
+		}
+	}
+	return nil
+}
+
+// matcher is whether a socket is one of the name
+func (s *DefSockets) matcher() (func(socket) bool, error) {
+	name := s.name
+	switch {
+	case name == "all":
+		return func(socket) bool { return true }, nil
+	case strings.HasPrefix(name, "process:"):
+		procs, err := s.system.ProcMap()
+		if err != nil {
+			return nil, err
+		}
+		inodes := make(map[string]bool)
+		for _, p := range procs[strings.TrimPrefix(name, "process:")] {
+			for _, inode := range socketInodes(strconv.Itoa(p.Pid())) {
+				inodes[inode] = true
+			}
+		}
+		return func(sock socket) bool { return inodes[sock.inode] }, nil
+	case strings.HasPrefix(name, "remote:"):
+		port, err := strconv.ParseInt(strings.TrimPrefix(name, "remote:"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("sockets must be all, a port, remote:<port> or process:<name>, got: %s", name)
+		}
+		return func(sock socket) bool { return sock.remotePort == port }, nil
+	}
+	network, p := "", name
+	if strings.HasPrefix(name, "tcp:") || strings.HasPrefix(name, "tcp6:") {
+		network, p = splitPort(name)
+	}
+	port, err := strconv.ParseInt(p, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("sockets must be all, a port, remote:<port> or process:<name>, got: %s", name)
+	}
+	return func(sock socket) bool {
+		return sock.localPort == port && (network == "" || sock.net == network)
+	}, nil
+}
+
+func (s *DefSockets) count(state string) (int, error) {
+	if err := s.setup(); err != nil {
+		return 0, err
+	}
+	return s.counts[state], nil
+}
+
+func (s *DefSockets) Established() (int, error) { return s.count("ESTABLISHED") }
+func (s *DefSockets) SynSent() (int, error)     { return s.count("SYN_SENT") }
+func (s *DefSockets) SynRecv() (int, error)     { return s.count("SYN_RECV") }
+func (s *DefSockets) FinWait() (int, error)     { return s.count("FIN_WAIT") }
+func (s *DefSockets) TimeWait() (int, error)    { return s.count("TIME_WAIT") }
+func (s *DefSockets) CloseWait() (int, error)   { return s.count("CLOSE_WAIT") }
+func (s *DefSockets) LastAck() (int, error)     { return s.count("LAST_ACK") }
+func (s *DefSockets) Listen() (int, error)      { return s.count("LISTEN") }
+
+func (s *DefSockets) Total() (int, error) {
+	if err := s.setup(); err != nil {
+		return 0, err
+	}
+	return s.total, nil
+}
+
+// readSockets lists the TCP sockets of /proc/net, tcp6 is missing when IPv6
+// is disabled
+func readSockets() ([]socket, error) {
+	var sockets []socket
+	for _, net := range []string{"tcp", "tcp6"} {
+		data, err := ioutil.ReadFile("/proc/net/" + net)
+		if os.IsNotExist(err) && net == "tcp6" {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sockets = append(sockets, parseSockets(net, string(data))...)
+	}
+	return sockets, nil
+}
+
+// parseSockets parses the sockets of a /proc/net/tcp formatted file
+func parseSockets(net string, data string) []socket {
+	var sockets []socket
+	for i, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if i == 0 || len(fields) < 10 {
+			continue
+		}
+		local, remote := strings.Split(fields[1], ":"), strings.Split(fields[2], ":")
+		if len(local) != 2 || len(remote) != 2 {
+			continue
+		}
+		localPort, err := strconv.ParseInt(local[1], 16, 64)
+		if err != nil {
+			continue
+		}
+		remotePort, err := strconv.ParseInt(remote[1], 16, 64)
+		if err != nil {
+			continue
+		}
+		sockets = append(sockets, socket{
+			net:        net,
+			localPort:  localPort,
+			remotePort: remotePort,
+			state:      socketStates[fields[3]],
+			inode:      fields[9],
+		})
+	}
+	return sockets
+}
+
+// socketInodes are the inodes of the sockets open by pid
+func socketInodes(pid string) []string {
+	var inodes []string
+	fds, _ := filepath.Glob(filepath.Join("/proc", pid, "fd", "[0-9]*"))
+	for _, fd := range fds {
+		link, err := os.Readlink(fd)
+		if err != nil || !strings.HasPrefix(link, "socket:[") {
+			continue
+		}
+		inodes = append(inodes, strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]"))
+	}
+	return inodes
+}
@@ -0,0 +1,90 @@
+package system
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+func TestParseSockets(t *testing.T) {
+	data := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:0050 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1001 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:0050 0100007F:D431 01 00000000:00000000 00:00000000 00000000     0        0 1002 1 0000000000000000 20 4 30 10 -1
+   2: 0100007F:D431 0100007F:1538 06 00000000:00000000 03:00000F3A 00000000     0        0 0 3 0000000000000000
+`
+	sockets := parseSockets("tcp", data)
+	want := []socket{
+		{"tcp", 80, 0, "LISTEN", "1001"},
+		{"tcp", 80, 54321, "ESTABLISHED", "1002"},
+		{"tcp", 54321, 5432, "TIME_WAIT", "0"},
+	}
+	if len(sockets) != len(want) {
+		t.Fatalf("got %v, want %v", sockets, want)
+	}
+	for i := range want {
+		if sockets[i] != want[i] {
+			t.Errorf("socket %d: got %v, want %v", i, sockets[i], want[i])
+		}
+	}
+}
+
+func TestSockets(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("sockets are read from /proc/net")
+	}
+	l, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	conn, err := net.Dial("tcp4", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	accepted, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer accepted.Close()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	comm, err := ioutil.ReadFile(filepath.Join("/proc", strconv.Itoa(os.Getpid()), "comm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sys := New("")
+	tests := []struct {
+		name                string
+		established, listen int
+	}{
+		{port, 1, 1},
+		{"tcp:" + port, 1, 1},
+		{"tcp6:" + port, 0, 0},
+		{"remote:" + port, 1, 0},
+		{"process:" + strings.TrimSpace(string(comm)), 2, 1},
+	}
+	for _, tt := range tests {
+		s := NewDefSockets(tt.name, sys, util.Config{})
+		established, err := s.Established()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		listen, _ := s.Listen()
+		// Other processes of the same name may hold sockets too
+		if established < tt.established || listen < tt.listen || (tt.established == 0 && established != 0) {
+			t.Errorf("%s: got established %d, listen %d, want %d, %d", tt.name, established, listen, tt.established, tt.listen)
+		}
+	}
+
+	if _, err := NewDefSockets("tcp:http", sys, util.Config{}).Total(); err == nil {
+		t.Error("a port that isn't a number must be an error")
+	}
+}
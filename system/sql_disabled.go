@@ -0,0 +1,22 @@
+// +build no_sql slim
+
+package system
+
+import (
+	"github.com/aelsabbahy/goss/util"
+)
+
+type disabledSQL struct {
+	name string
+}
+
+func NewDefSQL(name string, system *System, config util.Config) SQL {
+	return &disabledSQL{name: name}
+}
+
+func (s *disabledSQL) Name() string                       { return s.name }
+func (s *disabledSQL) Exists() (bool, error)              { return false, errNotBuiltIn("sql") }
+func (s *disabledSQL) SetQuery(driver, dsn, query string) {}
+func (s *disabledSQL) Rows() (int, error)                 { return 0, errNotBuiltIn("sql") }
+func (s *disabledSQL) Value() (string, error)             { return "", errNotBuiltIn("sql") }
+func (s *disabledSQL) Column(string) ([]string, error)    { return nil, errNotBuiltIn("sql") }
@@ -0,0 +1,97 @@
+// +build !windows,!no_sql,!slim
+
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+func TestParseSQLOutput(t *testing.T) {
+	columns, rows := parseSQLOutput("id\tname\n1\tal\\tice\n2\tNULL\n", true)
+	if !reflect.DeepEqual(columns, []string{"id", "name"}) {
+		t.Errorf("columns were incorrect, got: %v", columns)
+	}
+	if want := [][]string{{"1", "al\tice"}, {"2", "NULL"}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows were incorrect, got: %q, want: %q.", rows, want)
+	}
+	if columns, rows := parseSQLOutput("", false); columns != nil || rows != nil {
+		t.Errorf("empty output should be no rows, got: %v, %v", columns, rows)
+	}
+}
+
+func TestMysqlCommand(t *testing.T) {
+	args, env, err := mysqlCommand("mysql://goss:secret@db:3307/app", "select 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"mysql", "--batch", "--execute=select 1", "--host=db", "--port=3307", "--user=goss", "--database=app"}
+	if !reflect.DeepEqual(args, want) || !reflect.DeepEqual(env, []string{"MYSQL_PWD=secret"}) {
+		t.Errorf("mysql command was incorrect, got: %v %v", args, env)
+	}
+	if _, _, err := mysqlCommand("goss@db/app", "select 1"); err == nil {
+		t.Errorf("a dsn that isn't a mysql:// URL should be an error")
+	}
+}
+
+func TestDefSQL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goss-sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// A fake sqlite3 answering with the output of the real one
+	script := "#!/bin/sh\n" +
+		"for q; do :; done\n" +
+		"case \"$q\" in\n" +
+		"'select count(*) as n from users') printf 'n\\n3\\n' ;;\n" +
+		"'select name from users') printf 'name\\nalice\\nbob\\ncarol\\n' ;;\n" +
+		"'select name from users where 0') ;;\n" +
+		"*) echo 'Error: no such table: nope' >&2; exit 1 ;;\n" +
+		"esac\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "sqlite3"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	query := func(q string) SQL {
+		s := NewDefSQL("users", nil, util.Config{Timeout: 5 * time.Second})
+		s.SetQuery("sqlite", "/var/lib/app.db", q)
+		return s
+	}
+	if v, err := query("select count(*) as n from users").Value(); err != nil || v != "3" {
+		t.Errorf("Value was incorrect, got: %q, %v, want: 3.", v, err)
+	}
+	s := query("select name from users")
+	if n, _ := s.Rows(); n != 3 {
+		t.Errorf("Rows was incorrect, got: %d, want: 3.", n)
+	}
+	if names, _ := s.Column("name"); !reflect.DeepEqual(names, []string{"alice", "bob", "carol"}) {
+		t.Errorf("Column was incorrect, got: %v", names)
+	}
+	if _, err := s.Column("email"); err == nil {
+		t.Errorf("Column of a column the query didn't return should be an error")
+	}
+	s = query("select name from users where 0")
+	if n, _ := s.Rows(); n != 0 {
+		t.Errorf("Rows of an empty result was incorrect, got: %d, want: 0.", n)
+	}
+	if _, err := s.Value(); err == nil {
+		t.Errorf("Value of an empty result should be an error")
+	}
+	if _, err := query("select * from nope").Rows(); err == nil || err.Error() != "sqlite3: Error: no such table: nope" {
+		t.Errorf("a failing query should be an error with the client's message, got: %v", err)
+	}
+	s = NewDefSQL("users", nil, util.Config{})
+	s.SetQuery("oracle", "", "select 1")
+	if _, err := s.Rows(); err == nil {
+		t.Errorf("an unknown driver should be an error")
+	}
+}
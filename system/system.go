@@ -2,15 +2,15 @@ package system
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"runtime"
 	"strconv"
 	"sync"
 
 	"github.com/aelsabbahy/GOnetstat"
-	// This needs a better name
-	"github.com/aelsabbahy/go-ps"
 
 	util2 "github.com/aelsabbahy/goss/util"
 )
@@ -20,35 +20,88 @@ type Resource interface {
 }
 
 type System struct {
-	NewPackage     func(string, *System, util2.Config) Package
-	NewFile        func(string, *System, util2.Config) File
-	NewAddr        func(string, *System, util2.Config) Addr
-	NewPort        func(string, *System, util2.Config) Port
-	NewService     func(string, *System, util2.Config) Service
-	NewUser        func(string, *System, util2.Config) User
-	NewGroup       func(string, *System, util2.Config) Group
-	NewCommand     func(string, *System, util2.Config) Command
-	NewDNS         func(string, *System, util2.Config) DNS
-	NewProcess     func(string, *System, util2.Config) Process
-	NewGossfile    func(string, *System, util2.Config) Gossfile
-	NewKernelParam func(string, *System, util2.Config) KernelParam
-	NewMount       func(string, *System, util2.Config) Mount
-	NewInterface   func(string, *System, util2.Config) Interface
-	NewHTTP        func(string, *System, util2.Config) HTTP
-	ports          map[string][]GOnetstat.Process
-	portsOnce      sync.Once
-	procMap        map[string][]ps.Process
-	procOnce       sync.Once
+	NewPackage      func(string, *System, util2.Config) Package
+	NewFile         func(string, *System, util2.Config) File
+	NewAddr         func(string, *System, util2.Config) Addr
+	NewPort         func(string, *System, util2.Config) Port
+	NewService      func(string, *System, util2.Config) Service
+	NewUser         func(string, *System, util2.Config) User
+	NewGroup        func(string, *System, util2.Config) Group
+	NewCommand      func(string, *System, util2.Config) Command
+	NewDNS          func(string, *System, util2.Config) DNS
+	NewProcess      func(string, *System, util2.Config) Process
+	NewGossfile     func(string, *System, util2.Config) Gossfile
+	NewKernelParam  func(string, *System, util2.Config) KernelParam
+	NewMount        func(string, *System, util2.Config) Mount
+	NewInterface    func(string, *System, util2.Config) Interface
+	NewHTTP         func(string, *System, util2.Config) HTTP
+	NewDir          func(string, *System, util2.Config) Dir
+	NewShellProfile func(string, *System, util2.Config) ShellProfile
+	NewCryptoPolicy func(string, *System, util2.Config) CryptoPolicy
+	NewTrustedBoot  func(string, *System, util2.Config) TrustedBoot
+	NewEntropy      func(string, *System, util2.Config) Entropy
+	NewMAC          func(string, *System, util2.Config) MAC
+	NewContainer    func(string, *System, util2.Config) Container
+	NewK8s          func(string, *System, util2.Config) K8s
+	NewSQL          func(string, *System, util2.Config) SQL
+	NewKV           func(string, *System, util2.Config) KV
+	NewFirewall     func(string, *System, util2.Config) Firewall
+	NewNTP          func(string, *System, util2.Config) NTP
+	NewSockets      func(string, *System, util2.Config) Sockets
+	NewBandwidth    func(string, *System, util2.Config) Bandwidth
+	NewPing         func(string, *System, util2.Config) Ping
+	NewGRPC         func(string, *System, util2.Config) GRPC
+	NewWebSocket    func(string, *System, util2.Config) WebSocket
+	CommandPolicy   *CommandPolicy
+	// Unprivileged is who checks that don't need root run as, nil to run
+	// everything as the current user
+	Unprivileged *Credential
+	// Resolver resolves the hosts of the network checks, nil for the system
+	// resolver
+	Resolver *Resolver
+	// IPVersion is the address family of the network checks that don't set
+	// one, 4, 6 or any
+	IPVersion string
+	// Netns is the network namespace of the network checks that don't set
+	// one, empty for the namespace of goss
+	Netns string
+	// Container is the container runtime goss runs in, empty outside of one
+	Container string
+	// PackageManager is the package manager of NewPackage: dpkg, apk, pacman
+	// or rpm
+	PackageManager string
+	// serviceManagerMissing is why the host has no service manager, nil
+	// when it has one
+	serviceManagerMissing error
+	ports                 map[string][]GOnetstat.Process
+	portsOnce             sync.Once
+	portPids              map[string][]string
+	portPidsOnce          sync.Once
+	procMap               map[string][]Proc
+	procOnce              sync.Once
 }
 
 func (s *System) Ports() map[string][]GOnetstat.Process {
 	s.portsOnce.Do(func() {
+		if isBSD() {
+			s.ports = GetBSDPorts()
+			return
+		}
 		s.ports = GetPorts(false)
 	})
 	return s.ports
 }
 
-func (s *System) ProcMap() (map[string][]ps.Process, error) {
+// PortPids maps the listening ports, in the same format as Ports, to the pids
+// of the processes holding their sockets
+func (s *System) PortPids() map[string][]string {
+	s.portPidsOnce.Do(func() {
+		s.portPids = GetPortPids()
+	})
+	return s.portPids
+}
+
+func (s *System) ProcMap() (map[string][]Proc, error) {
 	var err error
 
 	s.procOnce.Do(func() {
@@ -60,21 +113,39 @@ func (s *System) ProcMap() (map[string][]ps.Process, error) {
 
 func New(packageManager string) *System {
 	sys := &System{
-		NewFile:        NewDefFile,
-		NewAddr:        NewDefAddr,
-		NewPort:        NewDefPort,
-		NewUser:        NewDefUser,
-		NewGroup:       NewDefGroup,
-		NewCommand:     NewDefCommand,
-		NewDNS:         NewDefDNS,
-		NewProcess:     NewDefProcess,
-		NewGossfile:    NewDefGossfile,
-		NewKernelParam: NewDefKernelParam,
-		NewMount:       NewDefMount,
-		NewInterface:   NewDefInterface,
-		NewHTTP:        NewDefHTTP,
+		NewFile:         NewDefFile,
+		NewAddr:         NewDefAddr,
+		NewPort:         NewDefPort,
+		NewUser:         NewDefUser,
+		NewGroup:        NewDefGroup,
+		NewCommand:      NewDefCommand,
+		NewDNS:          NewDefDNS,
+		NewProcess:      NewDefProcess,
+		NewGossfile:     NewDefGossfile,
+		NewKernelParam:  NewDefKernelParam,
+		NewMount:        NewDefMount,
+		NewInterface:    NewDefInterface,
+		NewHTTP:         NewDefHTTP,
+		NewDir:          NewDefDir,
+		NewShellProfile: NewDefShellProfile,
+		NewCryptoPolicy: NewDefCryptoPolicy,
+		NewTrustedBoot:  NewDefTrustedBoot,
+		NewEntropy:      NewDefEntropy,
+		NewMAC:          NewDefMAC,
+		NewContainer:    NewDefContainer,
+		NewK8s:          NewDefK8s,
+		NewSQL:          NewDefSQL,
+		NewKV:           NewDefKV,
+		NewFirewall:     NewDefFirewall,
+		NewNTP:          NewDefNTP,
+		NewSockets:      NewDefSockets,
+		NewBandwidth:    NewDefBandwidth,
+		NewPing:         NewDefPing,
+		NewGRPC:         NewDefGRPC,
+		NewWebSocket:    NewDefWebSocket,
 	}
 
+	sys.Container = DetectContainer()
 	sys.detectService()
 	sys.detectPackage(packageManager)
 
@@ -83,16 +154,28 @@ func New(packageManager string) *System {
 
 // detectPackage adds the correct package creation function to a System struct
 func (sys *System) detectPackage(p string) {
-	if p != "dpkg" && p != "apk" && p != "pacman" && p != "rpm" {
+	if !IsSupportedPackageManager(p) {
 		p = DetectPackageManager()
 	}
 	switch p {
+	case "dpkg", "apk", "pacman", "pkg", "pkg_add", "pkg5":
+	default:
+		p = "rpm"
+	}
+	sys.PackageManager = p
+	switch p {
 	case "dpkg":
 		sys.NewPackage = NewDebPackage
 	case "apk":
 		sys.NewPackage = NewAlpinePackage
 	case "pacman":
 		sys.NewPackage = NewPacmanPackage
+	case "pkg":
+		sys.NewPackage = NewFreeBSDPackage
+	case "pkg_add":
+		sys.NewPackage = NewOpenBSDPackage
+	case "pkg5":
+		sys.NewPackage = NewPkg5Package
 	default:
 		sys.NewPackage = NewRpmPackage
 	}
@@ -100,6 +183,13 @@ func (sys *System) detectPackage(p string) {
 
 // detectService adds the correct service creation function to a System struct
 func (sys *System) detectService() {
+	// Querying systemctl in a container without a service manager only fails
+	if sys.Container != "" && !hasInit() {
+		sys.NewService = NewServiceProc
+		sys.serviceManagerMissing = util2.NewCodedError(util2.ErrCodeBackendNotFound,
+			fmt.Errorf("no service manager found, init of the %s container isn't one", sys.Container))
+		return
+	}
 	switch DetectService() {
 	case "upstart":
 		sys.NewService = NewServiceUpstart
@@ -109,14 +199,39 @@ func (sys *System) detectService() {
 		sys.NewService = NewServiceSystemdLegacy
 	case "alpineinit":
 		sys.NewService = NewAlpineServiceInit
+	case "procfs":
+		sys.NewService = NewServiceProcfs
+		sys.serviceManagerMissing = util2.NewCodedError(util2.ErrCodeBackendNotFound,
+			fmt.Errorf("no service manager found, neither systemctl nor service is installed"))
+	case "freebsd":
+		sys.NewService = NewServiceFreeBSD
+	case "openbsd":
+		sys.NewService = NewServiceOpenBSD
+	case "smf":
+		sys.NewService = NewServiceSMF
 	default:
 		sys.NewService = NewServiceInit
 	}
 }
 
+// ServiceManagerMissing is the error of the host having no service manager,
+// such as a container whose init is its application, nil when it has one or
+// --procfs was chosen. Services are then read from init and the processes.
+func (sys *System) ServiceManagerMissing() error {
+	return sys.serviceManagerMissing
+}
+
+// UseProcfs reads services from /proc and /etc instead of running a service
+// manager, services are then read like ports, processes and mounts without
+// running other programs
+func (sys *System) UseProcfs() {
+	sys.NewService = NewServiceProcfs
+	sys.serviceManagerMissing = nil
+}
+
 // SupportedPackageManagers is a list of package managers we support
 func SupportedPackageManagers() []string {
-	return []string{"apk", "dpkg", "pacman", "rpm"}
+	return []string{"apk", "dpkg", "pacman", "pkg", "pkg5", "pkg_add", "rpm"}
 }
 
 // IsSupportedPackageManager determines if p is a supported package manager
@@ -131,10 +246,19 @@ func IsSupportedPackageManager(p string) bool {
 }
 
 // DetectPackageManager attempts to detect whether or not the system is using
-// "dpkg", "rpm", "apk", or "pacman" package managers. It first attempts to
+// "dpkg", "rpm", "apk", or "pacman" package managers, or "pkg", "pkg_add" and
+// "pkg5" on FreeBSD, OpenBSD and Solaris or illumos. It first attempts to
 // detect the distro. If that fails, it falls back to finding package manager
 // executables. If that fails, it returns the empty string.
 func DetectPackageManager() string {
+	switch runtime.GOOS {
+	case "freebsd":
+		return "pkg"
+	case "openbsd":
+		return "pkg_add"
+	case "solaris", "illumos":
+		return "pkg5"
+	}
 	switch DetectDistro() {
 	case "ubuntu":
 		return "dpkg"
@@ -156,16 +280,27 @@ func DetectPackageManager() string {
 }
 
 // DetectService attempts to detect what kind of service management the system
-// is using, "systemd", "upstart", "alpineinit", or "init". It looks for systemctl
-// command to detect systemd, and falls back on DetectDistro otherwise. If it can't
+// is using, "systemd", "upstart", "alpineinit", "init" or "procfs", the rc.d of
+// "freebsd" and "openbsd", or "smf" on Solaris and illumos. It looks for systemctl
+// command to detect systemd, returns "procfs" when there's no service command either,
+// as on busybox based images, and falls back on DetectDistro otherwise. If it can't
 // decide, it returns "init".
 func DetectService() string {
+	switch runtime.GOOS {
+	case "freebsd", "openbsd":
+		return runtime.GOOS
+	case "solaris", "illumos":
+		return "smf"
+	}
 	if HasCommand("systemctl") {
 		if isLegacySystemd() {
 			return "systemdlegacy"
 		}
 		return "systemd"
 	}
+	if !HasCommand("service") {
+		return "procfs"
+	}
 	// Centos Docker container doesn't run systemd, so we detect it or use init.
 	switch DetectDistro() {
 	case "ubuntu":
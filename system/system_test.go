@@ -28,7 +28,7 @@ func TestPackageManager(t *testing.T) {
 	t.Parallel()
 	testOutputs(
 		DetectPackageManager,
-		[]string{"dpkg", "rpm", "apk", "pacman", ""},
+		[]string{"dpkg", "rpm", "apk", "pacman", "pkg", "pkg_add", "pkg5", ""},
 		t,
 	)
 }
@@ -37,7 +37,7 @@ func TestDetectService(t *testing.T) {
 	t.Parallel()
 	testOutputs(
 		DetectService,
-		[]string{"systemd", "init", "alpineinit", "upstart", ""},
+		[]string{"systemd", "systemdlegacy", "init", "alpineinit", "upstart", "procfs", "freebsd", "openbsd", "smf", ""},
 		t,
 	)
 }
@@ -0,0 +1,186 @@
+package system
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// TrustedBoot is the Secure Boot and TPM state of the machine, the only name
+// is "system"
+type TrustedBoot interface {
+	Name() string
+	Exists() (bool, error)
+	UEFI() (bool, error)
+	SecureBoot() (bool, error)
+	SetupMode() (bool, error)
+	TPM() (bool, error)
+	TPMVersion() (string, error)
+	PCRBanks() ([]string, error)
+	MeasuredBoot() (bool, error)
+}
+
+type DefTrustedBoot struct {
+	name string
+}
+
+var (
+	efiDir         = "/sys/firmware/efi"
+	tpmDir         = "/sys/class/tpm/tpm0"
+	tpmResourceMgr = "/dev/tpmrm0"
+	tpmEventLog    = "/sys/kernel/security/tpm0/binary_bios_measurements"
+)
+
+// efiGlobalVariable is the vendor GUID of the variables defined by the UEFI spec
+const efiGlobalVariable = "8be4df61-93ca-11d2-aa0d-00e098032b8c"
+
+func NewDefTrustedBoot(name string, system *System, config util.Config) TrustedBoot {
+	return &DefTrustedBoot{name: name}
+}
+
+func (t *DefTrustedBoot) Name() string {
+	return t.name
+}
+
+func (t *DefTrustedBoot) Exists() (bool, error) {
+	return t.name == "system", nil
+}
+
+func (t *DefTrustedBoot) check() error {
+	if t.name != "system" {
+		return fmt.Errorf("unknown trusted boot %q, the only one is system", t.name)
+	}
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("trusted boot is not supported on %s", runtime.GOOS)
+	}
+	return nil
+}
+
+// UEFI reports whether the machine booted with UEFI rather than a legacy BIOS
+func (t *DefTrustedBoot) UEFI() (bool, error) {
+	if err := t.check(); err != nil {
+		return false, err
+	}
+	return pathExists(efiDir), nil
+}
+
+// SecureBoot reports whether the firmware enforces Secure Boot, it's false
+// without UEFI
+func (t *DefTrustedBoot) SecureBoot() (bool, error) {
+	if err := t.check(); err != nil {
+		return false, err
+	}
+	return efiBoolVariable("SecureBoot")
+}
+
+// SetupMode reports whether the firmware has no platform key enrolled, in
+// which case Secure Boot keys can be changed without authentication
+func (t *DefTrustedBoot) SetupMode() (bool, error) {
+	if err := t.check(); err != nil {
+		return false, err
+	}
+	return efiBoolVariable("SetupMode")
+}
+
+func (t *DefTrustedBoot) TPM() (bool, error) {
+	if err := t.check(); err != nil {
+		return false, err
+	}
+	return pathExists(tpmDir), nil
+}
+
+// TPMVersion is the version of the TPM spec implemented, 1.2 or 2.0, empty
+// without a TPM
+func (t *DefTrustedBoot) TPMVersion() (string, error) {
+	if err := t.check(); err != nil {
+		return "", err
+	}
+	if !pathExists(tpmDir) {
+		return "", nil
+	}
+	// Available since Linux 5.6
+	if data, err := ioutil.ReadFile(filepath.Join(tpmDir, "tpm_version_major")); err == nil {
+		switch strings.TrimSpace(string(data)) {
+		case "1":
+			return "1.2", nil
+		case "2":
+			return "2.0", nil
+		}
+		return "", fmt.Errorf("unknown TPM major version %q", strings.TrimSpace(string(data)))
+	}
+	// Older kernels only show caps for TPM 1.2 and a resource manager for 2.0
+	for _, caps := range []string{filepath.Join(tpmDir, "caps"), filepath.Join(tpmDir, "device", "caps")} {
+		if data, err := ioutil.ReadFile(caps); err == nil && strings.Contains(string(data), "TCG version: 1.2") {
+			return "1.2", nil
+		}
+	}
+	if pathExists(tpmResourceMgr) {
+		return "2.0", nil
+	}
+	return "", fmt.Errorf("unable to determine the version of the TPM in %s", tpmDir)
+}
+
+// PCRBanks are the hash algorithms the TPM has PCRs for, such as sha256
+func (t *DefTrustedBoot) PCRBanks() ([]string, error) {
+	if err := t.check(); err != nil {
+		return nil, err
+	}
+	// Available since Linux 5.12
+	dirs, err := filepath.Glob(filepath.Join(tpmDir, "pcr-*"))
+	if err != nil {
+		return nil, err
+	}
+	banks := []string{}
+	for _, d := range dirs {
+		banks = append(banks, strings.TrimPrefix(filepath.Base(d), "pcr-"))
+	}
+	sort.Strings(banks)
+	return banks, nil
+}
+
+// MeasuredBoot reports whether the firmware left an event log of the boot
+// measurements it extended into the PCRs
+func (t *DefTrustedBoot) MeasuredBoot() (bool, error) {
+	if err := t.check(); err != nil {
+		return false, err
+	}
+	return pathExists(tpmEventLog), nil
+}
+
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// efiBoolVariable reads a boolean UEFI global variable, efivarfs prefixes the
+// value with 4 bytes of attributes while the older sysfs interface doesn't
+func efiBoolVariable(name string) (bool, error) {
+	file := name + "-" + efiGlobalVariable
+	data, err := ioutil.ReadFile(filepath.Join(efiDir, "efivars", file))
+	if err == nil {
+		if len(data) != 5 {
+			return false, fmt.Errorf("unexpected size of UEFI variable %s: %d", name, len(data))
+		}
+		return data[4] == 1, nil
+	}
+	if !os.IsNotExist(err) {
+		return false, err
+	}
+	data, err = ioutil.ReadFile(filepath.Join(efiDir, "vars", file, "data"))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if len(data) != 1 {
+		return false, fmt.Errorf("unexpected size of UEFI variable %s: %d", name, len(data))
+	}
+	return data[0] == 1, nil
+}
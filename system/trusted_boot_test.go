@@ -0,0 +1,78 @@
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+func TestTrustedBoot(t *testing.T) {
+	root, err := ioutil.TempDir("", "goss-trusted-boot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	defer func(e, d, r, l string) { efiDir, tpmDir, tpmResourceMgr, tpmEventLog = e, d, r, l }(efiDir, tpmDir, tpmResourceMgr, tpmEventLog)
+	efiDir = filepath.Join(root, "efi")
+	tpmDir = filepath.Join(root, "tpm0")
+	tpmResourceMgr = filepath.Join(root, "tpmrm0")
+	tpmEventLog = filepath.Join(root, "binary_bios_measurements")
+
+	boot := NewDefTrustedBoot("system", nil, util.Config{})
+	if uefi, _ := boot.UEFI(); uefi {
+		t.Errorf("UEFI without %s", efiDir)
+	}
+	if sb, err := boot.SecureBoot(); sb || err != nil {
+		t.Errorf("SecureBoot without UEFI was incorrect, got: %v, %v, want: false.", sb, err)
+	}
+	if v, err := boot.TPMVersion(); v != "" || err != nil {
+		t.Errorf("TPMVersion without a TPM was incorrect, got: %q, %v, want: \"\".", v, err)
+	}
+
+	files := map[string][]byte{
+		"efi/efivars/SecureBoot-" + efiGlobalVariable: {0x06, 0, 0, 0, 1},
+		"efi/efivars/SetupMode-" + efiGlobalVariable:  {0x06, 0, 0, 0, 0},
+		"tpm0/tpm_version_major":                      []byte("2\n"),
+		"tpm0/pcr-sha256/0":                           []byte("0x00\n"),
+		"tpm0/pcr-sha1/0":                             []byte("0x00\n"),
+		"binary_bios_measurements":                    nil,
+	}
+	for name, data := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, f := range map[string]func() (bool, error){"UEFI": boot.UEFI, "SecureBoot": boot.SecureBoot, "TPM": boot.TPM, "MeasuredBoot": boot.MeasuredBoot} {
+		if got, err := f(); !got || err != nil {
+			t.Errorf("%s was incorrect, got: %v, %v, want: true.", name, got, err)
+		}
+	}
+	if got, err := boot.SetupMode(); got || err != nil {
+		t.Errorf("SetupMode was incorrect, got: %v, %v, want: false.", got, err)
+	}
+	if got, err := boot.TPMVersion(); got != "2.0" || err != nil {
+		t.Errorf("TPMVersion was incorrect, got: %q, %v, want: 2.0.", got, err)
+	}
+	if got, err := boot.PCRBanks(); !reflect.DeepEqual(got, []string{"sha1", "sha256"}) || err != nil {
+		t.Errorf("PCRBanks was incorrect, got: %v, %v, want: [sha1 sha256].", got, err)
+	}
+
+	// Kernels before 5.6 only tell TPM 2.0 apart by its resource manager
+	os.Remove(filepath.Join(tpmDir, "tpm_version_major"))
+	if got, err := boot.TPMVersion(); err == nil {
+		t.Errorf("TPMVersion without a version was incorrect, got: %q, want an error.", got)
+	}
+	ioutil.WriteFile(tpmResourceMgr, nil, 0600)
+	if got, err := boot.TPMVersion(); got != "2.0" || err != nil {
+		t.Errorf("TPMVersion with a resource manager was incorrect, got: %q, %v, want: 2.0.", got, err)
+	}
+}
@@ -3,6 +3,7 @@ package system
 import (
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/aelsabbahy/goss/util"
 	"github.com/opencontainers/runc/libcontainer/user"
@@ -16,6 +17,14 @@ type User interface {
 	Groups() ([]string, error)
 	Home() (string, error)
 	Shell() (string, error)
+	PasswordLocked() (bool, error)
+	PasswordExpired() (bool, error)
+	PasswordEmpty() (bool, error)
+	PasswordAge() (int, error)
+	MinDays() (int, error)
+	MaxDays() (int, error)
+	WarnDays() (int, error)
+	InactiveDays() (int, error)
 }
 
 type DefUser struct {
@@ -94,6 +103,84 @@ func (u *DefUser) Groups() ([]string, error) {
 	return groupList, nil
 }
 
+// PasswordLocked reports whether the password can't be used to log in
+func (u *DefUser) PasswordLocked() (bool, error) {
+	e, err := lookupShadow(u.username)
+	if err != nil {
+		return false, err
+	}
+	return e.locked(), nil
+}
+
+// PasswordExpired reports whether the password has to be changed
+func (u *DefUser) PasswordExpired() (bool, error) {
+	e, err := lookupShadow(u.username)
+	if err != nil {
+		return false, err
+	}
+	return e.expired(time.Now()), nil
+}
+
+// PasswordEmpty reports whether the user can log in without a password
+func (u *DefUser) PasswordEmpty() (bool, error) {
+	e, err := lookupShadow(u.username)
+	if err != nil {
+		return false, err
+	}
+	return e.hash == "", nil
+}
+
+// PasswordAge is the number of days since the password was last changed
+func (u *DefUser) PasswordAge() (int, error) {
+	e, err := lookupShadow(u.username)
+	if err != nil {
+		return 0, err
+	}
+	lastChange, err := e.days("last password change", e.lastChange)
+	if err != nil {
+		return 0, err
+	}
+	return epochDays(time.Now()) - lastChange, nil
+}
+
+// MinDays is the number of days before the password can be changed again
+func (u *DefUser) MinDays() (int, error) {
+	e, err := lookupShadow(u.username)
+	if err != nil {
+		return 0, err
+	}
+	return e.days("min-days", e.min)
+}
+
+// MaxDays is the number of days after which the password has to be changed
+func (u *DefUser) MaxDays() (int, error) {
+	e, err := lookupShadow(u.username)
+	if err != nil {
+		return 0, err
+	}
+	return e.days("max-days", e.max)
+}
+
+// WarnDays is the number of days before the password expires the user is
+// warned
+func (u *DefUser) WarnDays() (int, error) {
+	e, err := lookupShadow(u.username)
+	if err != nil {
+		return 0, err
+	}
+	return e.days("warn-days", e.warn)
+}
+
+// InactiveDays is the number of days after the password expired the account
+// is disabled
+func (u *DefUser) InactiveDays() (int, error) {
+	e, err := lookupShadow(u.username)
+	if err != nil {
+		return 0, err
+	}
+	return e.days("inactive-days", e.inactive)
+}
+
 func lookupUserGroups(userS user.User) ([]user.Group, error) {
 	// Get operating system-specific group reader-closer.
 	group, err := user.GetGroup()
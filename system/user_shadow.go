@@ -0,0 +1,128 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// shadowFile is where the password hashes and aging of local users are
+var shadowFile = "/etc/shadow"
+
+// masterPasswdFile is the shadow file of FreeBSD and OpenBSD
+var masterPasswdFile = "/etc/master.passwd"
+
+// shadowEntry is the line of a user in the shadow file, days are counted
+// since the epoch and are -1 when the field is empty. changeBy is the day the
+// password has to be changed by on BSD, which has no aging otherwise.
+type shadowEntry struct {
+	hash       string
+	lastChange int
+	min        int
+	max        int
+	warn       int
+	inactive   int
+	changeBy   int
+}
+
+func lookupShadow(username string) (shadowEntry, error) {
+	if isBSD() {
+		return lookupMasterPasswd(username)
+	}
+	f, err := os.Open(shadowFile)
+	if err != nil {
+		return shadowEntry{}, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 7 || fields[0] != username {
+			continue
+		}
+		e := shadowEntry{hash: fields[1], changeBy: -1}
+		for i, days := range []*int{&e.lastChange, &e.min, &e.max, &e.warn, &e.inactive} {
+			if *days, err = shadowDays(fields[i+2]); err != nil {
+				return shadowEntry{}, fmt.Errorf("%s: %s: %v", shadowFile, username, err)
+			}
+		}
+		return e, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return shadowEntry{}, err
+	}
+	return shadowEntry{}, fmt.Errorf("user %s not found in %s", username, shadowFile)
+}
+
+// lookupMasterPasswd reads the user of master.passwd, whose fields are name,
+// password, uid, gid, class, change, expire, gecos, home and shell. change is
+// when the password has to be changed by in seconds since the epoch, 0 when it
+// doesn't.
+func lookupMasterPasswd(username string) (shadowEntry, error) {
+	f, err := os.Open(masterPasswdFile)
+	if err != nil {
+		return shadowEntry{}, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 10 || fields[0] != username {
+			continue
+		}
+		e := shadowEntry{hash: fields[1], lastChange: -1, min: -1, max: -1, warn: -1, inactive: -1, changeBy: -1}
+		change, err := strconv.ParseInt(fields[5], 10, 64)
+		if err != nil && fields[5] != "" {
+			return shadowEntry{}, fmt.Errorf("%s: %s: %v", masterPasswdFile, username, err)
+		}
+		if change > 0 {
+			e.changeBy = epochDays(time.Unix(change, 0))
+		}
+		return e, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return shadowEntry{}, err
+	}
+	return shadowEntry{}, fmt.Errorf("user %s not found in %s", username, masterPasswdFile)
+}
+
+func shadowDays(field string) (int, error) {
+	if field == "" {
+		return -1, nil
+	}
+	return strconv.Atoi(field)
+}
+
+func epochDays(t time.Time) int {
+	return int(t.Unix() / (24 * 60 * 60))
+}
+
+// locked reports whether the password can't be used to log in, passwd -l
+// prefixes the hash with !, pw lock on FreeBSD with *LOCKED* and accounts
+// without a password have *
+func (e shadowEntry) locked() bool {
+	return strings.HasPrefix(e.hash, "!") || strings.HasPrefix(e.hash, "*")
+}
+
+// expired reports whether the password has to be changed, because it was
+// forced with a last change of 0, it's older than max days or, on BSD, its
+// change by day passed
+func (e shadowEntry) expired(now time.Time) bool {
+	if e.changeBy >= 0 {
+		return epochDays(now) >= e.changeBy
+	}
+	if e.lastChange == 0 {
+		return true
+	}
+	return e.lastChange > 0 && e.max >= 0 && epochDays(now) > e.lastChange+e.max
+}
+
+func (e shadowEntry) days(name string, days int) (int, error) {
+	if days < 0 {
+		return 0, fmt.Errorf("%s isn't set", name)
+	}
+	return days, nil
+}
@@ -0,0 +1,95 @@
+package system
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+func TestDefUserShadow(t *testing.T) {
+	today := epochDays(time.Now())
+	shadow := "root:*:19000:0:99999:7:::\n" +
+		"alice:$6$salt$hash:" + strconv.Itoa(today-10) + ":1:90:7:30::\n" +
+		"bob:!$6$salt$hash:" + strconv.Itoa(today-100) + ":0:90:7:::\n" +
+		"carol::0::::::\n"
+	shadowFile = filepath.Join(t.TempDir(), "shadow")
+	defer func() { shadowFile = "/etc/shadow" }()
+	if err := ioutil.WriteFile(shadowFile, []byte(shadow), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		user                   string
+		locked, expired, empty bool
+	}{
+		{"root", true, false, false},
+		{"alice", false, false, false},
+		{"bob", true, true, false},
+		{"carol", false, true, true},
+	}
+	for _, tt := range tests {
+		u := NewDefUser(tt.user, nil, util.Config{})
+		if got, err := u.PasswordLocked(); err != nil || got != tt.locked {
+			t.Errorf("%s: locked: got %v, %v", tt.user, got, err)
+		}
+		if got, err := u.PasswordExpired(); err != nil || got != tt.expired {
+			t.Errorf("%s: expired: got %v, %v", tt.user, got, err)
+		}
+		if got, err := u.PasswordEmpty(); err != nil || got != tt.empty {
+			t.Errorf("%s: empty: got %v, %v", tt.user, got, err)
+		}
+	}
+
+	alice := NewDefUser("alice", nil, util.Config{})
+	for name, method := range map[string]func() (int, error){
+		"age": alice.PasswordAge, "min": alice.MinDays, "max": alice.MaxDays, "warn": alice.WarnDays, "inactive": alice.InactiveDays,
+	} {
+		want := map[string]int{"age": 10, "min": 1, "max": 90, "warn": 7, "inactive": 30}[name]
+		if got, err := method(); err != nil || got != want {
+			t.Errorf("alice: %s: got %d, %v, want %d", name, got, err, want)
+		}
+	}
+	if _, err := NewDefUser("carol", nil, util.Config{}).MaxDays(); err == nil {
+		t.Error("carol: max-days: want an error for an empty field")
+	}
+	if _, err := NewDefUser("dave", nil, util.Config{}).PasswordLocked(); err == nil {
+		t.Error("dave: want an error for a user not in the shadow file")
+	}
+}
+
+func TestMasterPasswd(t *testing.T) {
+	masterPasswd := "# $FreeBSD$\n" +
+		"root:$6$salt$hash:0:0::0:0:Charlie &:/root:/bin/sh\n" +
+		"alice:*LOCKED*$6$salt$hash:1001:1001::0:0:Alice:/home/alice:/bin/sh\n" +
+		"bob:$6$salt$hash:1002:1002::" + strconv.FormatInt(time.Now().Add(-48*time.Hour).Unix(), 10) + ":0:Bob:/home/bob:/bin/sh\n"
+	masterPasswdFile = filepath.Join(t.TempDir(), "master.passwd")
+	defer func() { masterPasswdFile = "/etc/master.passwd" }()
+	if err := ioutil.WriteFile(masterPasswdFile, []byte(masterPasswd), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		user            string
+		locked, expired bool
+	}{
+		{"root", false, false},
+		{"alice", true, false},
+		{"bob", false, true},
+	}
+	for _, tt := range tests {
+		e, err := lookupMasterPasswd(tt.user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.locked() != tt.locked || e.expired(time.Now()) != tt.expired {
+			t.Errorf("%s: got locked %v, expired %v", tt.user, e.locked(), e.expired(time.Now()))
+		}
+		if _, err := e.days("max-days", e.max); err == nil {
+			t.Errorf("%s: max-days: want an error, BSD has no password aging", tt.user)
+		}
+	}
+}
@@ -0,0 +1,409 @@
+package system
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// WebSocket is a WebSocket endpoint, checked with the upgrade handshake and
+// optionally a message sent once connected and the reply to it
+type WebSocket interface {
+	URL() string
+	Exists() (bool, error)
+	Connected() (bool, error)
+	Status() (int, error)
+	Subprotocol() (string, error)
+	Reply() (string, error)
+	Latency() (int, error)
+	SetMessage(send string, awaitReply bool)
+	SetHandshake(protocols []string, origin string)
+}
+
+// The GUID the Sec-WebSocket-Accept of the handshake is derived with
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// The opcodes of WebSocket frames
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xa
+)
+
+// Replies larger than this are an error rather than read in full
+const maxWebSocketReply = 1 << 20
+
+type DefWebSocket struct {
+	url           string
+	allowInsecure bool
+	CAFile        string
+	ClientCert    string
+	ClientKey     string
+	// Protocols are the subprotocols the handshake offers, the server picks
+	// one of them
+	Protocols []string
+	// Origin is the Origin header of the handshake, the http or https
+	// origin of the URL when empty, as a page served by it would send
+	Origin        string
+	RequestHeader http.Header
+	Timeout       int
+	// IPVersion is the address family of the connection, 4, 6 or any
+	IPVersion string
+	// Netns is the network namespace the connection is made from
+	Netns      string
+	send       string
+	awaitReply bool
+	resolver   *Resolver
+	loaded     bool
+	// err is an invalid configuration, connErr why the handshake didn't
+	// succeed and replyErr why no reply was read
+	err         error
+	connErr     error
+	replyErr    error
+	connected   bool
+	status      int
+	subprotocol string
+	reply       string
+	latency     time.Duration
+}
+
+func NewDefWebSocket(wsURL string, system *System, config util.Config) WebSocket {
+	headers := http.Header{}
+	for _, r := range config.RequestHeader {
+		str := strings.SplitN(r, ": ", 2)
+		if len(str) == 2 {
+			headers.Add(str[0], str[1])
+		}
+	}
+	w := &DefWebSocket{
+		url:           wsURL,
+		allowInsecure: config.AllowInsecure,
+		CAFile:        config.CAFile,
+		ClientCert:    config.ClientCert,
+		ClientKey:     config.ClientKey,
+		RequestHeader: headers,
+		Timeout:       config.TimeOutMilliSeconds(),
+		IPVersion:     ipVersion(config.IPVersion, system),
+		Netns:         netnsName(config.Netns, system),
+	}
+	if system != nil {
+		w.resolver = system.Resolver
+	}
+	return w
+}
+
+func (w *DefWebSocket) URL() string {
+	return w.url
+}
+
+// Exists is whether the upgrade handshake succeeded
+func (w *DefWebSocket) Exists() (bool, error) {
+	return w.Connected()
+}
+
+// SetMessage sets the text message sent once connected, none when empty, and
+// whether a reply is read, the first message the server sends after it
+func (w *DefWebSocket) SetMessage(send string, awaitReply bool) {
+	w.send, w.awaitReply = send, awaitReply
+}
+
+// SetHandshake sets the subprotocols offered and the Origin of the handshake
+func (w *DefWebSocket) SetHandshake(protocols []string, origin string) {
+	w.Protocols, w.Origin = protocols, origin
+}
+
+func (w *DefWebSocket) setup() error {
+	if w.loaded {
+		return w.err
+	}
+	w.loaded = true
+
+	u, err := url.Parse(w.url)
+	if err != nil {
+		w.err = err
+		return w.err
+	}
+	var addr string
+	switch u.Scheme {
+	case "ws":
+		addr = net.JoinHostPort(u.Hostname(), "80")
+	case "wss":
+		addr = net.JoinHostPort(u.Hostname(), "443")
+	default:
+		w.err = fmt.Errorf("websocket url must start with ws:// or wss://, got: %s", w.url)
+		return w.err
+	}
+	if u.Port() != "" {
+		addr = u.Host
+	}
+	var tlsConfig *tls.Config
+	if u.Scheme == "wss" {
+		if tlsConfig, err = newTLSConfig(w.allowInsecure, w.CAFile, w.ClientCert, w.ClientKey); err != nil {
+			w.err = err
+			return w.err
+		}
+		tlsConfig.ServerName = u.Hostname()
+	}
+	w.connErr = w.exchange(u, addr, tlsConfig)
+	return nil
+}
+
+// exchange connects to addr, upgrades the connection and, once connected,
+// sends the message and reads the reply
+func (w *DefWebSocket) exchange(u *url.URL, addr string, tlsConfig *tls.Config) error {
+	deadline := time.Now().Add(time.Duration(w.Timeout) * time.Millisecond)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	start := time.Now()
+	conn, err := w.resolver.dialNetns(ctx, &net.Dialer{}, w.Netns, ipNetwork("tcp", w.IPVersion), addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(deadline)
+	if tlsConfig != nil {
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			return err
+		}
+		conn = tlsConn
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        u,
+		Host:       u.Host,
+		Header:     w.RequestHeader.Clone(),
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+	}
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if len(w.Protocols) > 0 {
+		req.Header.Set("Sec-WebSocket-Protocol", strings.Join(w.Protocols, ", "))
+	}
+	origin := w.Origin
+	if origin == "" {
+		origin = "http://" + u.Host
+		if u.Scheme == "wss" {
+			origin = "https://" + u.Host
+		}
+	}
+	req.Header.Set("Origin", origin)
+	if err := req.Write(conn); err != nil {
+		return err
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return err
+	}
+	w.status = resp.StatusCode
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return fmt.Errorf("websocket handshake of %s: %s", w.url, resp.Status)
+	}
+	accept := sha1.Sum([]byte(key + websocketGUID))
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") ||
+		resp.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(accept[:]) {
+		return fmt.Errorf("websocket handshake of %s: the server didn't accept the upgrade", w.url)
+	}
+	w.subprotocol = resp.Header.Get("Sec-WebSocket-Protocol")
+	if w.subprotocol != "" && !contains(w.Protocols, w.subprotocol) {
+		return fmt.Errorf("websocket handshake of %s: the server chose subprotocol %s, which wasn't offered", w.url, w.subprotocol)
+	}
+	w.latency = time.Since(start)
+	w.connected = true
+
+	if w.send != "" {
+		if err := writeWebSocketFrame(conn, wsText, []byte(w.send)); err != nil {
+			w.replyErr = err
+			return nil
+		}
+	}
+	if w.awaitReply {
+		w.reply, w.replyErr = readWebSocketMessage(br, conn)
+	}
+	// Close status 1000, the exchange is over
+	writeWebSocketFrame(conn, wsClose, []byte{0x03, 0xe8})
+	return nil
+}
+
+// writeWebSocketFrame writes a frame of op with payload, masked as client
+// frames must be
+func writeWebSocketFrame(w io.Writer, op byte, payload []byte) error {
+	frame := []byte{0x80 | op}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, 0x80|byte(n))
+	case n <= 0xffff:
+		frame = append(frame, 0x80|126, byte(n>>8), byte(n))
+	default:
+		length := make([]byte, 8)
+		binary.BigEndian.PutUint64(length, uint64(n))
+		frame = append(append(frame, 0x80|127), length...)
+	}
+	mask := make([]byte, 4)
+	if _, err := rand.Read(mask); err != nil {
+		return err
+	}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := w.Write(frame)
+	return err
+}
+
+// readWebSocketMessage reads the next text or binary message, answering
+// pings on the way
+func readWebSocketMessage(r io.Reader, w io.Writer) (string, error) {
+	var message []byte
+	for {
+		header := make([]byte, 2)
+		if _, err := io.ReadFull(r, header); err != nil {
+			return "", err
+		}
+		fin, op := header[0]&0x80 != 0, header[0]&0x0f
+		length := uint64(header[1] & 0x7f)
+		switch length {
+		case 126:
+			b := make([]byte, 2)
+			if _, err := io.ReadFull(r, b); err != nil {
+				return "", err
+			}
+			length = uint64(binary.BigEndian.Uint16(b))
+		case 127:
+			b := make([]byte, 8)
+			if _, err := io.ReadFull(r, b); err != nil {
+				return "", err
+			}
+			length = binary.BigEndian.Uint64(b)
+		}
+		var mask []byte
+		if header[1]&0x80 != 0 {
+			mask = make([]byte, 4)
+			if _, err := io.ReadFull(r, mask); err != nil {
+				return "", err
+			}
+		}
+		if length > maxWebSocketReply || uint64(len(message))+length > maxWebSocketReply {
+			return "", fmt.Errorf("websocket reply is larger than %d bytes", maxWebSocketReply)
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return "", err
+		}
+		for i := range mask {
+			for j := i; j < len(payload); j += 4 {
+				payload[j] ^= mask[i]
+			}
+		}
+		switch op {
+		case wsText, wsBinary, wsContinuation:
+			message = append(message, payload...)
+			if fin {
+				return string(message), nil
+			}
+		case wsPing:
+			if err := writeWebSocketFrame(w, wsPong, payload); err != nil {
+				return "", err
+			}
+		case wsPong:
+		case wsClose:
+			if len(payload) >= 2 {
+				return "", fmt.Errorf("websocket closed by the server before replying: %d %s",
+					binary.BigEndian.Uint16(payload), payload[2:])
+			}
+			return "", errors.New("websocket closed by the server before replying")
+		default:
+			return "", fmt.Errorf("websocket frame with unknown opcode %d", op)
+		}
+	}
+}
+
+// Connected is whether the upgrade handshake succeeded, false when the
+// connection failed or the server refused the upgrade
+func (w *DefWebSocket) Connected() (bool, error) {
+	if err := w.setup(); err != nil {
+		return false, err
+	}
+	return w.connected, nil
+}
+
+// Status is the HTTP status of the response to the handshake, 101 when the
+// server switched protocols
+func (w *DefWebSocket) Status() (int, error) {
+	if err := w.setup(); err != nil {
+		return 0, err
+	}
+	if w.status == 0 {
+		return 0, w.connErr
+	}
+	return w.status, nil
+}
+
+// Subprotocol is the subprotocol the server chose, empty when it chose none
+func (w *DefWebSocket) Subprotocol() (string, error) {
+	if err := w.setup(); err != nil {
+		return "", err
+	}
+	if !w.connected {
+		return "", w.connErr
+	}
+	return w.subprotocol, nil
+}
+
+// Reply is the first message the server sent after the message of
+// SetMessage, or after connecting when there's none
+func (w *DefWebSocket) Reply() (string, error) {
+	if err := w.setup(); err != nil {
+		return "", err
+	}
+	if !w.connected {
+		return "", w.connErr
+	}
+	if !w.awaitReply {
+		return "", errors.New("websocket reply wasn't read")
+	}
+	return w.reply, w.replyErr
+}
+
+// Latency is the time in milliseconds from connecting until the handshake
+// succeeded
+func (w *DefWebSocket) Latency() (int, error) {
+	if err := w.setup(); err != nil {
+		return 0, err
+	}
+	if !w.connected {
+		return 0, w.connErr
+	}
+	return int(w.latency / time.Millisecond), nil
+}
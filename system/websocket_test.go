@@ -0,0 +1,85 @@
+package system
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+	"golang.org/x/net/websocket"
+)
+
+func TestWebSocket(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/echo", websocket.Server{
+		Handshake: func(config *websocket.Config, req *http.Request) error {
+			for _, p := range config.Protocol {
+				if p == "chat" {
+					config.Protocol = []string{p}
+					return nil
+				}
+			}
+			config.Protocol = nil
+			return nil
+		},
+		Handler: func(ws *websocket.Conn) {
+			var msg string
+			if err := websocket.Message.Receive(ws, &msg); err == nil {
+				websocket.Message.Send(ws, "echo: "+msg+" "+ws.Request().Header.Get("X-Token"))
+			}
+		},
+	})
+	mux.Handle("/greet", websocket.Handler(func(ws *websocket.Conn) {
+		websocket.Message.Send(ws, []byte("hello"))
+	}))
+	mux.HandleFunc("/private", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	base := "ws" + strings.TrimPrefix(server.URL, "http")
+	config := util.Config{Timeout: 5 * time.Second, RequestHeader: []string{"X-Token: secret"}}
+
+	w := NewDefWebSocket(base+"/echo", nil, config)
+	w.SetHandshake([]string{"v2", "chat"}, server.URL)
+	w.SetMessage("ping", true)
+	if connected, err := w.Connected(); err != nil || !connected {
+		t.Fatalf("connected: got %v, %v", connected, err)
+	}
+	if status, err := w.Status(); err != nil || status != 101 {
+		t.Errorf("status: got %d, %v", status, err)
+	}
+	if p, err := w.Subprotocol(); err != nil || p != "chat" {
+		t.Errorf("subprotocol: got %q, %v", p, err)
+	}
+	if reply, err := w.Reply(); err != nil || reply != "echo: ping secret" {
+		t.Errorf("reply: got %q, %v", reply, err)
+	}
+	if _, err := w.Latency(); err != nil {
+		t.Errorf("latency: %v", err)
+	}
+
+	// A binary message sent on connecting, with the default origin
+	w = NewDefWebSocket(base+"/greet", nil, config)
+	w.SetMessage("", true)
+	if reply, err := w.Reply(); err != nil || reply != "hello" {
+		t.Errorf("greeting: got %q, %v", reply, err)
+	}
+
+	w = NewDefWebSocket(base+"/private", nil, config)
+	if connected, err := w.Connected(); err != nil || connected {
+		t.Errorf("refused upgrade: got connected %v, %v", connected, err)
+	}
+	if status, err := w.Status(); err != nil || status != 401 {
+		t.Errorf("refused upgrade: got status %d, %v", status, err)
+	}
+	if _, err := w.Reply(); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("refused upgrade: got reply error %v", err)
+	}
+
+	if _, err := NewDefWebSocket(server.URL+"/echo", nil, config).Connected(); err == nil {
+		t.Error("http:// url: got no error")
+	}
+}
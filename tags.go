@@ -0,0 +1,98 @@
+package goss
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+)
+
+// loadGossConfig reads the gossfile of c, keeping the resources its --tags
+// and --skip-tags select. The requires of its resources have to resolve and
+// their severities be known.
+func loadGossConfig(c *util.Config) (*GossConfig, error) {
+	gossConfig, err := getGossConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	// Checked before the selection, a requirement left out of the run is
+	// only ignored
+	if _, _, err := resolveRequires(gossConfig.Resources(), false); err != nil {
+		return nil, err
+	}
+	if err := checkSeverities(gossConfig.Resources()); err != nil {
+		return nil, err
+	}
+	if len(c.Tags) == 0 && len(c.SkipTags) == 0 {
+		return gossConfig, nil
+	}
+	selected := selectTags(*gossConfig, c.Tags, c.SkipTags)
+	if len(selected.Resources()) == 0 {
+		var selection []string
+		if len(c.Tags) > 0 {
+			selection = append(selection, "tags: "+strings.Join(c.Tags, ","))
+		}
+		if len(c.SkipTags) > 0 {
+			selection = append(selection, "skip-tags: "+strings.Join(c.SkipTags, ","))
+		}
+		return nil, fmt.Errorf("found 0 tests selected by %s, source: %v", strings.Join(selection, ", "), c.Spec)
+	}
+	return &selected, nil
+}
+
+// selectTags is gossConfig with the resources that have one of tags, all of
+// them when tags is empty, and none of skipTags
+func selectTags(gossConfig GossConfig, tags, skipTags []string) GossConfig {
+	v := reflect.ValueOf(&gossConfig).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if f.Kind() != reflect.Map || !f.CanSet() {
+			continue
+		}
+		selected := reflect.MakeMap(f.Type())
+		for _, k := range f.MapKeys() {
+			r, ok := f.MapIndex(k).Interface().(resource.ResourceRead)
+			if !ok {
+				selected.SetMapIndex(k, f.MapIndex(k))
+				continue
+			}
+			if (len(tags) == 0 || hasTag(r.GetTags(), tags)) && !hasTag(r.GetTags(), skipTags) {
+				selected.SetMapIndex(k, f.MapIndex(k))
+			}
+		}
+		f.Set(selected)
+	}
+	return gossConfig
+}
+
+func hasTag(resourceTags, tags []string) bool {
+	for _, t := range resourceTags {
+		for _, tag := range tags {
+			if t == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addTags adds tags to the resources of gossConfig, those of the gossfile
+// entry that included them
+func addTags(gossConfig GossConfig, tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+	for _, r := range gossConfig.Resources() {
+		f := reflect.ValueOf(r).Elem().FieldByName("Tags")
+		if !f.IsValid() {
+			continue
+		}
+		for _, tag := range tags {
+			if !hasTag(f.Interface().([]string), []string{tag}) {
+				f.Set(reflect.Append(f, reflect.ValueOf(tag)))
+			}
+		}
+	}
+}
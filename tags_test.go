@@ -0,0 +1,64 @@
+package goss
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadGossConfigTags(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, content string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("goss.yaml", `gossfile:
+  cis.yaml:
+    tags: [compliance]
+file:
+  /etc/passwd:
+    exists: true
+    tags: [security]
+command:
+  "echo 1":
+    exit-status: 0
+    tags: [smoke, network]
+  "echo 2":
+    exit-status: 0
+`)
+	write("cis.yaml", `command:
+  "echo 3":
+    exit-status: 0
+    tags: [security]
+`)
+	outStoreFormat = YAML
+	currentTemplateFilter = nil
+
+	ids := func(tags, skipTags []string) []string {
+		gossConfig, err := loadGossConfig(&util.Config{Spec: filepath.Join(dir, "goss.yaml"), Tags: tags, SkipTags: skipTags})
+		if err != nil {
+			return []string{err.Error()}
+		}
+		var ids []string
+		for _, r := range gossConfig.Resources() {
+			ids = append(ids, r.(resource.ResourceRead).ID())
+		}
+		return ids
+	}
+	assert.ElementsMatch(t, []string{"echo 1", "echo 2", "echo 3", "/etc/passwd"}, ids(nil, nil))
+	assert.ElementsMatch(t, []string{"echo 3", "/etc/passwd"}, ids([]string{"security"}, nil))
+	assert.ElementsMatch(t, []string{"echo 1", "/etc/passwd"}, ids([]string{"security", "smoke"}, []string{"compliance"}))
+	assert.ElementsMatch(t, []string{"echo 3"}, ids([]string{"compliance"}, nil))
+	assert.ElementsMatch(t, []string{"echo 2"}, ids(nil, []string{"security", "network"}))
+	assert.Contains(t, ids([]string{"nope"}, nil)[0], "found 0 tests selected by tags: nope")
+}
@@ -5,11 +5,15 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"regexp"
 	"strings"
 	"text/template"
 
 	"github.com/Masterminds/sprig"
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
 )
 
 // TemplateFilter is the type of the Goss Template Filter which include custom variables and functions.
@@ -17,15 +21,46 @@ type TemplateFilter func([]byte) ([]byte, error)
 
 // NewTemplateFilter creates a new Template Filter based in the file and inline variables.
 func NewTemplateFilter(varsFile string, varsInline string) (func([]byte) ([]byte, error), error) {
-	vars, err := loadVars(varsFile, varsInline)
+	var sources []string
+	if varsFile != "" {
+		sources = append(sources, varsFile)
+	}
+	return newTemplateFilter(sources, varsInline, nil)
+}
+
+// configTemplateFilter is the template filter of the vars and template
+// functions of c
+func configTemplateFilter(c *util.Config) (func([]byte) ([]byte, error), error) {
+	funcs, err := templateFuncs(c)
+	if err != nil {
+		return nil, err
+	}
+	return newTemplateFilter(varsSources(c), c.VarsInline, funcs)
+}
+
+// newTemplateFilter renders with the vars of varsSources and varsInline, and
+// funcs over the sprig and goss functions
+func newTemplateFilter(varsSources []string, varsInline string, funcs template.FuncMap) (func([]byte) ([]byte, error), error) {
+	vars, err := loadVars(varsSources, varsInline)
 	if err != nil {
-		return nil, fmt.Errorf("failed while loading vars file %q: %v", varsFile, err)
+		return nil, fmt.Errorf("failed while loading vars: %v", err)
 	}
+	return varsTemplateFilter(vars, funcs), nil
+}
 
+// varsTemplateFilter renders with vars and funcs over the sprig and goss
+// functions
+func varsTemplateFilter(vars map[string]interface{}, funcs template.FuncMap) func([]byte) ([]byte, error) {
 	tVars := &TmplVars{Vars: vars}
 
+	sprigFuncs := sprig.TxtFuncMap()
 	f := func(data []byte) ([]byte, error) {
-		t := template.New("test").Funcs(sprig.TxtFuncMap()).Funcs(funcMap)
+		// Gossfiles without actions render as themselves, large ones are
+		// often generated without any
+		if !bytes.Contains(data, []byte("{{")) {
+			return data, nil
+		}
+		t := template.New("test").Funcs(sprigFuncs).Funcs(funcMap).Funcs(funcs)
 
 		tmpl, err := t.Parse(string(data))
 		if err != nil {
@@ -43,7 +78,7 @@ func NewTemplateFilter(varsFile string, varsInline string) (func([]byte) ([]byte
 		return doc.Bytes(), nil
 	}
 
-	return f, nil
+	return f
 }
 
 func mkSlice(args ...interface{}) []interface{} {
@@ -77,11 +112,74 @@ func regexMatch(re, s string) (bool, error) {
 	return compiled.MatchString(s), nil
 }
 
+// templateFuncs are the template functions of c, its programs and then its
+// Go functions
+func templateFuncs(c *util.Config) (template.FuncMap, error) {
+	funcs := template.FuncMap{}
+	var policy *system.CommandPolicy
+	if len(c.TemplatePrograms) > 0 {
+		var err error
+		if policy, err = system.LoadCommandPolicy(c.CommandPolicy); err != nil {
+			return nil, err
+		}
+	}
+	for _, spec := range c.TemplatePrograms {
+		i := strings.Index(spec, "=")
+		if i < 1 || len(strings.Fields(spec[i+1:])) == 0 {
+			return nil, fmt.Errorf("template func %q must be name=program [args...]", spec)
+		}
+		funcs[spec[:i]] = programFunc(strings.Fields(spec[i+1:]), policy)
+	}
+	for name, fn := range c.TemplateFuncs {
+		funcs[name] = fn
+	}
+	for name, fn := range funcs {
+		if err := checkTemplateFunc(name, fn); err != nil {
+			return nil, err
+		}
+	}
+	return funcs, nil
+}
+
+// checkTemplateFunc errors when text/template would panic on fn
+func checkTemplateFunc(name string, fn interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("template func %s: %v", name, r)
+		}
+	}()
+	template.New("test").Funcs(template.FuncMap{name: fn})
+	return nil
+}
+
+// programFunc runs argv with the arguments of the call appended, when policy
+// permits it, the result is its output without the trailing newline
+func programFunc(argv []string, policy *system.CommandPolicy) func(args ...interface{}) (string, error) {
+	return func(args ...interface{}) (string, error) {
+		cmdArgs := append([]string{}, argv[1:]...)
+		for _, arg := range args {
+			cmdArgs = append(cmdArgs, fmt.Sprint(arg))
+		}
+		if err := policy.CheckArgv(append([]string{argv[0]}, cmdArgs...)); err != nil {
+			return "", err
+		}
+		cmd := exec.Command(argv[0], cmdArgs...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("%s: %v: %s", argv[0], err, strings.TrimSpace(stderr.String()))
+		}
+		return strings.TrimRight(string(out), "\r\n"), nil
+	}
+}
+
 var funcMap = template.FuncMap{
 	"mkSlice":    mkSlice,
 	"readFile":   readFile,
 	"getEnv":     getEnv,
 	"regexMatch": regexMatch,
+	"secretRef":  resource.SecretRef,
 	"toUpper":    strings.ToUpper,
 	"toLower":    strings.ToLower,
 }
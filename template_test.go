@@ -0,0 +1,71 @@
+package goss
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aelsabbahy/goss/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "lookup")
+	require.NoError(t, ioutil.WriteFile(script, []byte("#!/bin/sh\n"+
+		"test \"$2\" = missing && { echo \"no $2\" >&2; exit 1; }\n"+
+		"echo \"$1-$2\"\n"), 0755))
+
+	c, err := util.NewConfig(
+		util.WithTemplatePrograms("hostgroup="+script+" group"),
+		util.WithTemplateFunc("shout", func(s string) string { return strings.ToUpper(s) + "!" }),
+		// Over the goss function
+		util.WithTemplateFunc("toUpper", strings.ToLower),
+	)
+	require.NoError(t, err)
+	filter, err := configTemplateFilter(c)
+	require.NoError(t, err)
+	out, err := filter([]byte(`{{hostgroup "web1"}} {{hostgroup 2}} {{"hi" | shout}} {{toUpper "A"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "group-web1 group-2 HI! a", string(out))
+
+	_, err = filter([]byte(`{{hostgroup "missing"}}`))
+	assert.Contains(t, err.Error(), script+": exit status 1: no missing")
+
+	for _, option := range []util.ConfigOption{
+		util.WithTemplatePrograms("hostgroup"),
+		util.WithTemplatePrograms("=" + script),
+		util.WithTemplateFunc("not-a-name", strings.ToUpper),
+		util.WithTemplateFunc("value", "not a func"),
+	} {
+		c, err := util.NewConfig(option)
+		require.NoError(t, err)
+		_, err = configTemplateFilter(c)
+		assert.Error(t, err)
+	}
+}
+
+func TestTemplateFuncsCommandPolicy(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "lookup")
+	require.NoError(t, ioutil.WriteFile(script, []byte("#!/bin/sh\necho \"$1\"\n"), 0755))
+	policy := filepath.Join(dir, "policy.yaml")
+	require.NoError(t, ioutil.WriteFile(policy, []byte("deny: [lookup]\n"), 0644))
+
+	c, err := util.NewConfig(
+		util.WithTemplatePrograms("direct="+script, "wrapped=env", "echo=echo"),
+		util.WithCommandPolicy(policy),
+	)
+	require.NoError(t, err)
+	filter, err := configTemplateFilter(c)
+	require.NoError(t, err)
+	_, err = filter([]byte(`{{direct "web1"}}`))
+	assert.Contains(t, err.Error(), `command policy: executable "`+script+`" is denied`)
+	// The program of a wrapper is in the arguments of the call
+	_, err = filter([]byte(`{{wrapped "` + script + `"}}`))
+	assert.Contains(t, err.Error(), `command policy: executable "`+script+`" is denied`)
+	out, err := filter([]byte(`{{echo "web1"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "web1", string(out))
+}
@@ -0,0 +1,250 @@
+package goss
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/system"
+	"github.com/aelsabbahy/goss/util"
+)
+
+// workerResult carries a TestResult between processes, errors don't survive
+// being marshalled so they're sent as their message and error code
+type workerResult struct {
+	resource.TestResult
+	Err     string `json:"err"`
+	ErrCode string `json:"err-code,omitempty"`
+}
+
+// splitUnprivileged moves the resources that don't need root, http, grpc,
+// websocket and dns, out of gossConfig. Entering a network namespace needs root, so the ones
+// checked from one, netns or their own, stay. So do the ones with requires, or
+// that others require, as requirements are only waited for within a run.
+func splitUnprivileged(gossConfig GossConfig, netns string) (privileged, unprivileged GossConfig) {
+	privileged = gossConfig
+	unprivileged = *NewGossConfig()
+	if netns != "" {
+		return privileged, unprivileged
+	}
+	privileged.HTTPs, privileged.GRPCs, privileged.DNS = make(resource.HTTPMap), make(resource.GRPCMap), make(resource.DNSMap)
+	privileged.WebSockets = make(resource.WebSocketMap)
+	refs := requiredRefs(gossConfig)
+	requires := func(key, id string, own []string) bool {
+		return len(own) > 0 || refs[key+":"+id] || refs[id]
+	}
+	for id, h := range gossConfig.HTTPs {
+		if h.Netns != "" || requires("http", id, h.Requires) {
+			privileged.HTTPs[id] = h
+		} else {
+			unprivileged.HTTPs[id] = h
+		}
+	}
+	for id, g := range gossConfig.GRPCs {
+		if g.Netns != "" || requires("grpc", id, g.Requires) {
+			privileged.GRPCs[id] = g
+		} else {
+			unprivileged.GRPCs[id] = g
+		}
+	}
+	for id, w := range gossConfig.WebSockets {
+		if w.Netns != "" || requires("websocket", id, w.Requires) {
+			privileged.WebSockets[id] = w
+		} else {
+			unprivileged.WebSockets[id] = w
+		}
+	}
+	for id, d := range gossConfig.DNS {
+		if d.Netns != "" || requires("dns", id, d.Requires) {
+			privileged.DNS[id] = d
+		} else {
+			unprivileged.DNS[id] = d
+		}
+	}
+	return privileged, unprivileged
+}
+
+// validateUnprivileged validates gossConfig in a goss worker process running
+// as the unprivileged user of sys, so the network and parsing code of the
+// checks doesn't run as root. The worker resolves hosts like sys.
+func validateUnprivileged(sys *system.System, gossConfig GossConfig, concurrency Concurrency, deadline time.Time) <-chan []resource.TestResult {
+	out := make(chan []resource.TestResult)
+	go func() {
+		defer close(out)
+		received := make(map[string]bool)
+		if err := runWorker(sys, gossConfig, concurrency, deadline, out, received); err != nil {
+			// The worker may have died part way, what it validated is kept
+			for _, r := range missingResources(gossConfig, received) {
+				out <- []resource.TestResult{workerFailure(r, err)}
+			}
+		}
+	}()
+	return out
+}
+
+// missingResources are the resources of gossConfig without results in received
+func missingResources(gossConfig GossConfig, received map[string]bool) []resource.Resource {
+	var missing []resource.Resource
+	for _, r := range gossConfig.Resources() {
+		if !received[workerKey(strings.Split(reflect.TypeOf(r).String(), ".")[1], r.(resource.ResourceRead).ID())] {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
+
+// workerKey identifies the resource of a result of the worker
+func workerKey(resourceType, id string) string {
+	return resourceType + ":" + id
+}
+
+func runWorker(sys *system.System, gossConfig GossConfig, concurrency Concurrency, deadline time.Time, out chan<- []resource.TestResult, received map[string]bool) error {
+	if len(gossConfig.Resources()) == 0 {
+		return nil
+	}
+	spec, err := json.Marshal(gossConfig)
+	if err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	args := []string{"--ip-version", sys.IPVersion}
+	if sys.Resolver != nil {
+		args = append(args, "--dns-server", sys.Resolver.Server, "--hosts-file", sys.Resolver.HostsFile)
+	}
+	args = append(args, "unprivileged-worker", "--max-concurrent", strconv.Itoa(concurrency.Max))
+	for _, spec := range concurrency.specs() {
+		args = append(args, "--max-concurrent-type", spec)
+	}
+	if !deadline.IsZero() {
+		// The worker marks what it didn't finish as timed out itself
+		args = append(args, "--max-run-duration", time.Until(deadline).String())
+	}
+	cmd := exec.Command(exe, args...)
+	cred := sys.Unprivileged
+	if err := system.SetCredential(cmd, cred); err != nil {
+		return err
+	}
+	cmd.Stdin = strings.NewReader(string(spec))
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting unprivileged worker as %s: %v", cred.User, err)
+	}
+	if err := forwardWorkerResults(stdout, out, received); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return err
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("unprivileged worker as %s: %v: %s", cred.User, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// forwardWorkerResults sends the result groups the worker writes to r to
+// out, marking the resources they're for in received
+func forwardWorkerResults(r io.Reader, out chan<- []resource.TestResult, received map[string]bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		var group []workerResult
+		if err := json.Unmarshal(scanner.Bytes(), &group); err != nil {
+			return fmt.Errorf("unprivileged worker: %v", err)
+		}
+		results := make([]resource.TestResult, len(group))
+		for i, r := range group {
+			results[i] = r.TestResult
+			if r.Err != "" {
+				results[i].Err = errors.New(r.Err)
+			}
+			if r.ErrCode != "" {
+				results[i].Err = util.NewCodedError(r.ErrCode, results[i].Err)
+			}
+			received[workerKey(r.ResourceType, r.ResourceId)] = true
+		}
+		out <- results
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("unprivileged worker: %v", err)
+	}
+	return nil
+}
+
+func workerFailure(r resource.Resource, err error) resource.TestResult {
+	res := r.(resource.ResourceRead)
+	return resource.TestResult{
+		Successful:   false,
+		Result:       resource.ERROR,
+		ResourceType: strings.Split(reflect.TypeOf(r).String(), ".")[1],
+		ResourceId:   res.ID(),
+		Title:        res.GetTitle(),
+		Meta:         res.GetMeta(),
+		Property:     "unprivileged",
+		Err:          util.NewCodedError(util.ErrCodeUnprivileged, err),
+	}
+}
+
+// UnprivilegedWorker validates the gossfile read as json from in and writes
+// the results to w, it's what validate runs as the unprivileged user
+func UnprivilegedWorker(in io.Reader, w io.Writer, maxConcurrent int, maxConcurrentType []string, maxRunDuration time.Duration, sys *system.System) error {
+	concurrency, err := parseConcurrency(maxConcurrent, maxConcurrentType)
+	if err != nil {
+		return err
+	}
+	var gossConfig GossConfig
+	if err := json.NewDecoder(in).Decode(&gossConfig); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	for group := range validate(sys, gossConfig, concurrency, runDeadline(maxRunDuration)) {
+		results := make([]workerResult, len(group))
+		for i, r := range group {
+			results[i] = workerResult{TestResult: r}
+			if r.Err != nil {
+				results[i].Err = r.Err.Error()
+				results[i].ErrCode = util.ErrorCode(r.Err)
+			}
+		}
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeResults forwards the results of every channel in cs to one channel
+func mergeResults(cs ...<-chan []resource.TestResult) <-chan []resource.TestResult {
+	out := make(chan []resource.TestResult)
+	done := make(chan struct{})
+	for _, c := range cs {
+		go func(c <-chan []resource.TestResult) {
+			for r := range c {
+				out <- r
+			}
+			done <- struct{}{}
+		}(c)
+	}
+	go func() {
+		for range cs {
+			<-done
+		}
+		close(out)
+	}()
+	return out
+}
@@ -0,0 +1,82 @@
+package goss
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/system"
+)
+
+func TestUnprivilegedWorker(t *testing.T) {
+	spec := `{"dns": {"localhost": {"resolvable": true, "server": "127.0.0.1:1", "timeout": 100}}, "http": {"http://127.0.0.1:1/": {"status": 200, "timeout": 100}}}`
+	var out bytes.Buffer
+	if err := UnprivilegedWorker(strings.NewReader(spec), &out, 1, nil, 0, system.New("")); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("UnprivilegedWorker wrote %d result groups, want: 2.\n%s", len(lines), out.String())
+	}
+	if !strings.Contains(out.String(), `"err":"Get`) {
+		t.Errorf("UnprivilegedWorker didn't keep the error message of the http check:\n%s", out.String())
+	}
+}
+
+func TestUnprivilegedWorkerDiedPartWay(t *testing.T) {
+	spec := `{"dns": {"localhost": {"resolvable": true, "server": "127.0.0.1:1", "timeout": 100}}, "http": {"http://127.0.0.1:1/": {"status": 200, "timeout": 100}}}`
+	g, err := ReadJSONData([]byte(spec), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The worker only got to write the results of the dns check
+	var out bytes.Buffer
+	if err := UnprivilegedWorker(strings.NewReader(`{"dns": {"localhost": {"resolvable": true, "server": "127.0.0.1:1", "timeout": 100}}}`), &out, 1, nil, 0, system.New("")); err != nil {
+		t.Fatal(err)
+	}
+	results := make(chan []resource.TestResult, 1)
+	received := make(map[string]bool)
+	if err := forwardWorkerResults(&out, results, received); err != nil {
+		t.Fatal(err)
+	}
+	if group := <-results; len(group) == 0 || group[0].ResourceType != "DNS" {
+		t.Errorf("forwardWorkerResults didn't forward the dns results: %v", group)
+	}
+	missing := missingResources(g, received)
+	if len(missing) != 1 || missing[0].(resource.ResourceRead).ID() != "http://127.0.0.1:1/" {
+		t.Errorf("missingResources = %v, want: the http check", missing)
+	}
+}
+
+func TestSplitUnprivileged(t *testing.T) {
+	g, err := ReadJSONData([]byte(`{"file": {"/etc/passwd": {"exists": true}}, "http": {"http://localhost/": {"status": 200}}, "dns": {"localhost": {"resolvable": true}}, "grpc": {"localhost:50051": {"status": "SERVING"}}, "websocket": {"ws://localhost/ws": {"connected": true}}}`), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privileged, unprivileged := splitUnprivileged(g, "")
+	if len(privileged.Files) != 1 || len(privileged.HTTPs) != 0 || len(privileged.DNS) != 0 || len(privileged.GRPCs) != 0 || len(privileged.WebSockets) != 0 {
+		t.Errorf("splitUnprivileged kept the wrong resources as privileged: %v", privileged.Resources())
+	}
+	if len(unprivileged.Files) != 0 || len(unprivileged.HTTPs) != 1 || len(unprivileged.DNS) != 1 || len(unprivileged.GRPCs) != 1 || len(unprivileged.WebSockets) != 1 {
+		t.Errorf("splitUnprivileged moved the wrong resources to unprivileged: %v", unprivileged.Resources())
+	}
+	if len(g.HTTPs) != 1 {
+		t.Errorf("splitUnprivileged changed the gossfile it split")
+	}
+
+	g.DNS["localhost"].Netns = "blue"
+	if privileged, unprivileged := splitUnprivileged(g, ""); len(privileged.DNS) != 1 || len(unprivileged.HTTPs) != 1 {
+		t.Errorf("splitUnprivileged moved the dns check of a network namespace to unprivileged: %v", unprivileged.Resources())
+	}
+	if _, unprivileged := splitUnprivileged(g, "blue"); len(unprivileged.Resources()) != 0 {
+		t.Errorf("splitUnprivileged moved checks of the network namespace of the run to unprivileged: %v", unprivileged.Resources())
+	}
+
+	g.DNS["localhost"].Netns = ""
+	g.HTTPs["http://localhost/"].Requires = []string{"file:/etc/passwd"}
+	g.Files["/etc/passwd"].Requires = []string{"grpc:localhost:50051"}
+	if privileged, unprivileged := splitUnprivileged(g, ""); len(privileged.HTTPs) != 1 || len(privileged.GRPCs) != 1 || len(unprivileged.DNS) != 1 {
+		t.Errorf("splitUnprivileged moved checks with requires, or required, to unprivileged: %v", unprivileged.Resources())
+	}
+}
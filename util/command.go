@@ -3,16 +3,37 @@ package util
 import (
 	"bytes"
 	//"fmt"
+	"io"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 )
 
 type Command struct {
 	name           string
 	Cmd            *exec.Cmd
 	Stdout, Stderr bytes.Buffer
-	Err            error
-	Status         int
+	// Combined is stdout and stderr interleaved in the order they were received
+	Combined bytes.Buffer
+	Err      error
+	Status   int
+	// mu guards Cmd.Process between Run and Kill
+	mu sync.Mutex
+}
+
+// lockedWriter serializes writes from the stdout and stderr copying goroutines
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
 }
 
 func NewCommand(name string, arg ...string) *Command {
@@ -20,19 +41,34 @@ func NewCommand(name string, arg ...string) *Command {
 	command := new(Command)
 	command.name = name
 	command.Cmd = exec.Command(name, arg...)
+	setProcessGroup(command.Cmd)
 	return command
 }
 
 func (c *Command) Run() error {
-	c.Cmd.Stdout = &c.Stdout
-	c.Cmd.Stderr = &c.Stderr
+	combined := &lockedWriter{w: &c.Combined}
+	c.Cmd.Stdout = io.MultiWriter(&c.Stdout, combined)
+	c.Cmd.Stderr = io.MultiWriter(&c.Stderr, combined)
 
-	if _, err := exec.LookPath(c.name); err != nil {
+	name := c.name
+	if c.Cmd.Dir != "" && strings.Contains(name, "/") && !filepath.IsAbs(name) {
+		// Relative paths are relative to the command's working directory
+		name = filepath.Join(c.Cmd.Dir, name)
+	}
+	if _, err := exec.LookPath(name); err != nil {
 		c.Err = err
 		return c.Err
 	}
 
-	if err := c.Cmd.Start(); err != nil {
+	startTime := time.Now()
+	defer func() {
+		// Not the arguments, they may hold secrets
+		Log().Debug("command run", "program", c.name, "exit-status", c.Status, "duration", time.Since(startTime))
+	}()
+	c.mu.Lock()
+	err := c.Cmd.Start()
+	c.mu.Unlock()
+	if err != nil {
 		c.Err = err
 		//log.Fatalf("Cmd.Start: %v")
 	}
@@ -50,3 +86,13 @@ func (c *Command) Run() error {
 	}
 	return c.Err
 }
+
+// Kill kills the process and the processes it started, if it has been started
+func (c *Command) Kill() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Cmd.Process == nil {
+		return nil
+	}
+	return killProcessGroup(c.Cmd)
+}
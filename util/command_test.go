@@ -0,0 +1,29 @@
+package util
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestCommandCombined(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip(err)
+	}
+	// The sleeps order the writes to the two pipes
+	c := NewCommand("sh", "-c", "echo out1; sleep 0.05; echo err1 >&2; sleep 0.05; echo out2; exit 3")
+	if err := c.Run(); err == nil {
+		t.Fatal("expected the exit status as an error")
+	}
+	if c.Status != 3 {
+		t.Errorf("status: got %d, want 3", c.Status)
+	}
+	if got, want := c.Stdout.String(), "out1\nout2\n"; got != want {
+		t.Errorf("stdout: got %q, want %q", got, want)
+	}
+	if got, want := c.Stderr.String(), "err1\n"; got != want {
+		t.Errorf("stderr: got %q, want %q", got, want)
+	}
+	if got, want := c.Combined.String(), "out1\nerr1\nout2\n"; got != want {
+		t.Errorf("combined: got %q, want %q", got, want)
+	}
+}
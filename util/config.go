@@ -21,31 +21,94 @@ type ConfigOption func(c *Config) error
 // NewConfig can be used to create this which will default to what the CLI assumes
 // and allow manipulation via ConfigOption functions
 type Config struct {
+	AcceptEncoding    string
 	AllowInsecure     bool
 	AnnounceToCLI     bool
+	AuditLog          string
+	Baseline          string
+	BasicAuth         string
+	BearerToken       string
+	CAFile            string
 	Cache             time.Duration
+	CertPins          []string
+	ClientCert        string
+	ClientKey         string
+	CommandPolicy     string
+	DNSServer         string
 	Debug             bool
+	Discover          []string
+	Dir               string
+	DropPrivileges    bool
 	Endpoint          string
+	Env               []string
+	Fixtures          string
+	FollowSymlinks    bool
 	FormatOptions     []string
+	GRPCListenAddress string
+	HostsFile         string
+	IPVersion         string
 	IgnoreList        []string
+	Lang              string
 	ListenAddress     string
 	LocalAddress      string
+	MaintenanceFile   string
 	MaxConcurrent     int
+	MaxConcurrentType []string
+	MaxOutputBytes    int
+	MaxRunDuration    time.Duration
+	MetricsEndpoint   string
+	MetricsTextfile   string
+	Netns             string
 	NoColor           *bool
 	NoFollowRedirects bool
+	NotifyPreset      string
+	NotifyTemplate    string
+	NotifyURL         string
+	OutputDetailsFile string
 	OutputFormat      string
 	OutputWriter      io.Writer
 	PackageManager    string
 	Password          string
+	PostRunExec       string
+	Preflight         bool
+	Procfs            bool
+	ProxyProtocol     string
+	ProxySource       string
+	Pushgateway       string
+	Record            string
+	Redact            bool
+	Replay            string
 	RequestHeader     []string
+	Resolve           []string
 	RetryTimeout      time.Duration
+	Sandbox           bool
+	ScoreThreshold    float64
+	ServeConfig       string
+	ServeEndpoints    []string
 	Server            string
+	SeverityExitCode  []string
+	Shell             string
+	SkipTags          []string
 	Sleep             time.Duration
+	SortResults       bool
 	Spec              string
+	Stdin             string
+	TLSCert           string
+	TLSClientCA       string
+	TLSKey            string
+	Tags              []string
+	TemplateFuncs     map[string]interface{}
+	TemplatePrograms  []string
 	Timeout           time.Duration
+	UnprivilegedUser  string
 	Username          string
 	Vars              string
 	VarsInline        string
+	VarsSources       []string
+	Version           string
+	WatchInterval     time.Duration
+	WatchSchedule     string
+	WatchStatusFile   string
 }
 
 // TimeOutMilliSeconds is the timeout as milliseconds
@@ -56,30 +119,93 @@ func (c *Config) TimeOutMilliSeconds() int {
 // NewConfig creates a default configuration modeled on the defaults the CLI sets, modified using opts
 func NewConfig(opts ...ConfigOption) (rc *Config, err error) {
 	rc = &Config{
+		AcceptEncoding:    "",
 		AllowInsecure:     false,
 		AnnounceToCLI:     false,
+		AuditLog:          "",
+		Baseline:          "",
+		BasicAuth:         "",
+		BearerToken:       "",
+		CAFile:            "",
 		Cache:             5 * time.Second,
+		CertPins:          nil,
+		ClientCert:        "",
+		ClientKey:         "",
+		CommandPolicy:     "",
+		DNSServer:         "",
 		Debug:             false,
+		Discover:          nil,
+		Dir:               "",
+		DropPrivileges:    false,
 		Endpoint:          "/healthz",
+		Env:               nil,
+		Fixtures:          "",
+		FollowSymlinks:    false,
 		FormatOptions:     []string{},
+		GRPCListenAddress: "",
+		HostsFile:         "",
+		IPVersion:         "",
 		IgnoreList:        []string{},
+		Lang:              "",
 		ListenAddress:     ":8080",
 		LocalAddress:      "",
+		MaintenanceFile:   "",
 		MaxConcurrent:     50,
+		MaxConcurrentType: nil,
+		MaxOutputBytes:    0,
+		MaxRunDuration:    0,
+		MetricsEndpoint:   "/metrics",
+		MetricsTextfile:   "",
+		Netns:             "",
 		NoColor:           nil,
 		NoFollowRedirects: false,
+		NotifyPreset:      "",
+		NotifyTemplate:    "",
+		NotifyURL:         "",
+		OutputDetailsFile: "",
 		OutputFormat:      "structured", // most appropriate for package usage
 		PackageManager:    "",
+		PostRunExec:       "",
+		Preflight:         false,
+		Procfs:            false,
+		ProxyProtocol:     "",
+		ProxySource:       "",
+		Pushgateway:       "",
 		Password:          "",
+		Record:            "",
+		Redact:            false,
+		Replay:            "",
 		RequestHeader:     nil,
+		Resolve:           nil,
 		RetryTimeout:      0,
+		Sandbox:           false,
+		ScoreThreshold:    100,
+		ServeConfig:       "",
+		ServeEndpoints:    nil,
 		Server:            "",
+		SeverityExitCode:  nil,
+		Shell:             "",
+		SkipTags:          nil,
 		Sleep:             time.Second,
+		SortResults:       false,
 		Spec:              "",
+		Stdin:             "",
+		TLSCert:           "",
+		TLSClientCA:       "",
+		TLSKey:            "",
+		Tags:              nil,
+		TemplateFuncs:     nil,
+		TemplatePrograms:  nil,
 		Timeout:           0,
+		UnprivilegedUser:  "",
 		Username:          "",
 		Vars:              "",
 		VarsInline:        "",
+		VarsSources:       nil,
+		Version:           "",
+		WatchInterval:     30 * time.Second,
+		WatchSchedule:     "",
+		WatchStatusFile:   "",
 	}
 
 	// NewConfig() is likely to be used when embedding goss or using as a package
@@ -113,17 +239,34 @@ func WithOutputFormat(f string) ConfigOption {
 	}
 }
 
-// WithFormatOptions sets options used by the output format plugins, valid options are output.WithFormatOptions
+// WithFormatOptions sets options used by the output format plugins, valid options are output.WithFormatOptions,
+// an option can also be a comma separated list of options
 func WithFormatOptions(opts ...string) ConfigOption {
 	return func(c *Config) error {
 		for _, o := range opts {
-			c.FormatOptions = append(c.FormatOptions, o)
+			c.FormatOptions = append(c.FormatOptions, strings.Split(o, ",")...)
 		}
 
 		return nil
 	}
 }
 
+// WithLang sets the language human readable output is written in, defaults to the system locale
+func WithLang(lang string) ConfigOption {
+	return func(c *Config) error {
+		c.Lang = lang
+		return nil
+	}
+}
+
+// WithCommandPolicy restricts the executables command resources may run to those permitted by the policy file f
+func WithCommandPolicy(f string) ConfigOption {
+	return func(c *Config) error {
+		c.CommandPolicy = f
+		return nil
+	}
+}
+
 // WithResultWriter sets the writer to write output format to when validating
 func WithResultWriter(w io.Writer) ConfigOption {
 	return func(c *Config) error {
@@ -156,6 +299,85 @@ func WithCache(d time.Duration) ConfigOption {
 	}
 }
 
+// WithTLS serves the health endpoint over TLS with the PEM certificate and
+// key files
+func WithTLS(cert, key string) ConfigOption {
+	return func(c *Config) error {
+		c.TLSCert, c.TLSKey = cert, key
+		return nil
+	}
+}
+
+// WithTLSClientCA requires clients of the health endpoint to present a
+// certificate signed by a CA of the PEM file
+func WithTLSClientCA(file string) ConfigOption {
+	return func(c *Config) error {
+		c.TLSClientCA = file
+		return nil
+	}
+}
+
+// WithBasicAuth requires the user:password of userPassword to request the
+// health endpoint
+func WithBasicAuth(userPassword string) ConfigOption {
+	return func(c *Config) error {
+		c.BasicAuth = userPassword
+		return nil
+	}
+}
+
+// WithBearerToken requires the bearer token to request the health endpoint
+func WithBearerToken(token string) ConfigOption {
+	return func(c *Config) error {
+		c.BearerToken = token
+		return nil
+	}
+}
+
+// WithGRPCListenAddress also serves the grpc.health.v1.Health service of the
+// health endpoints on address
+func WithGRPCListenAddress(address string) ConfigOption {
+	return func(c *Config) error {
+		c.GRPCListenAddress = address
+		return nil
+	}
+}
+
+// WithServeEndpoints serves more endpoints, each a path=gossfile[@cache]
+// spec such as /readyz=deep.yaml@60s
+func WithServeEndpoints(specs ...string) ConfigOption {
+	return func(c *Config) error {
+		c.ServeEndpoints = append(c.ServeEndpoints, specs...)
+		return nil
+	}
+}
+
+// WithServeConfig serves the endpoints of the YAML/JSON file f
+func WithServeConfig(f string) ConfigOption {
+	return func(c *Config) error {
+		c.ServeConfig = f
+		return nil
+	}
+}
+
+// WithWatch sets the interval between the runs of Watch, and the file the
+// status of the latest run is written to, none when it's empty
+func WithWatch(interval time.Duration, statusFile string) ConfigOption {
+	return func(c *Config) error {
+		c.WatchInterval, c.WatchStatusFile = interval, statusFile
+		return nil
+	}
+}
+
+// WithWatchSchedule sets the file of the schedules Watch validates the
+// resources with their tags on
+func WithWatchSchedule(file string) ConfigOption {
+	return func(c *Config) error {
+		c.WatchSchedule = file
+		return nil
+	}
+}
+
 // WithMaxConcurrency is the maximum concurrent test that can be run
 func WithMaxConcurrency(mc int) ConfigOption {
 	return func(c *Config) error {
@@ -164,6 +386,188 @@ func WithMaxConcurrency(mc int) ConfigOption {
 	}
 }
 
+// WithMaxConcurrentType gives resource types a pool of workers of their own,
+// each spec is type=limit such as http=5
+func WithMaxConcurrentType(specs ...string) ConfigOption {
+	return func(c *Config) error {
+		c.MaxConcurrentType = append(c.MaxConcurrentType, specs...)
+		return nil
+	}
+}
+
+// WithTags only validates the resources that have one of tags
+func WithTags(tags ...string) ConfigOption {
+	return func(c *Config) error {
+		c.Tags = append(c.Tags, tags...)
+		return nil
+	}
+}
+
+// WithSkipTags doesn't validate the resources that have one of tags
+func WithSkipTags(tags ...string) ConfigOption {
+	return func(c *Config) error {
+		c.SkipTags = append(c.SkipTags, tags...)
+		return nil
+	}
+}
+
+// WithTemplateFunc makes fn available to the templates of the gossfile and
+// vars as name, it follows the rules of text/template functions
+func WithTemplateFunc(name string, fn interface{}) ConfigOption {
+	return func(c *Config) error {
+		if c.TemplateFuncs == nil {
+			c.TemplateFuncs = make(map[string]interface{})
+		}
+		c.TemplateFuncs[name] = fn
+		return nil
+	}
+}
+
+// WithTemplatePrograms are template functions that run a program, as
+// name=program [args...], with the arguments of the call appended
+func WithTemplatePrograms(specs ...string) ConfigOption {
+	return func(c *Config) error {
+		c.TemplatePrograms = append(c.TemplatePrograms, specs...)
+		return nil
+	}
+}
+
+// WithMaxOutputBytes truncates the human readable parts of each result to n bytes, 0 disables truncation
+func WithMaxOutputBytes(n int) ConfigOption {
+	return func(c *Config) error {
+		c.MaxOutputBytes = n
+		return nil
+	}
+}
+
+// WithOutputDetailsFile writes the full details of every truncated result to f
+func WithOutputDetailsFile(f string) ConfigOption {
+	return func(c *Config) error {
+		c.OutputDetailsFile = f
+		return nil
+	}
+}
+
+// WithAuditLog appends the results of each run to the hash chained NDJSON log f
+func WithAuditLog(f string) ConfigOption {
+	return func(c *Config) error {
+		c.AuditLog = f
+		return nil
+	}
+}
+
+// WithMetricsTextfile writes the metrics of each run to f for the textfile
+// collector of node_exporter
+func WithMetricsTextfile(f string) ConfigOption {
+	return func(c *Config) error {
+		c.MetricsTextfile = f
+		return nil
+	}
+}
+
+// WithPushgateway pushes the metrics of each run to the Pushgateway at u
+func WithPushgateway(u string) ConfigOption {
+	return func(c *Config) error {
+		c.Pushgateway = u
+		return nil
+	}
+}
+
+// WithNotifyURL posts a summary of the runs that fail to the webhook at u, the
+// payload is that of the preset, json, slack or teams, or the template of
+// templateFile when it's set
+func WithNotifyURL(u, preset, templateFile string) ConfigOption {
+	return func(c *Config) error {
+		c.NotifyURL = u
+		c.NotifyPreset = preset
+		c.NotifyTemplate = templateFile
+		return nil
+	}
+}
+
+// WithPostRunExec runs program after each run with the results as json on
+// its stdin
+func WithPostRunExec(program string) ConfigOption {
+	return func(c *Config) error {
+		c.PostRunExec = program
+		return nil
+	}
+}
+
+// WithBaseline only fails on tests that passed in the json or structured results of f
+func WithBaseline(f string) ConfigOption {
+	return func(c *Config) error {
+		c.Baseline = f
+		return nil
+	}
+}
+
+// WithSeverityExitCode sets the exit codes of runs whose most severe tests
+// that didn't pass are of a severity, severity=code specs such as warn=3
+func WithSeverityExitCode(specs ...string) ConfigOption {
+	return func(c *Config) error {
+		c.SeverityExitCode = append(c.SeverityExitCode, specs...)
+		return nil
+	}
+}
+
+// WithMaintenanceFile reports the failures of tests in the maintenance windows of f as warnings
+func WithMaintenanceFile(f string) ConfigOption {
+	return func(c *Config) error {
+		c.MaintenanceFile = f
+		return nil
+	}
+}
+
+// WithFixtures sets the fixtures file goss test runs the gossfile against
+func WithFixtures(f string) ConfigOption {
+	return func(c *Config) error {
+		c.Fixtures = f
+		return nil
+	}
+}
+
+// WithScoreThreshold sets the lowest score in percent the score format passes with
+func WithScoreThreshold(t float64) ConfigOption {
+	return func(c *Config) error {
+		c.ScoreThreshold = t
+		return nil
+	}
+}
+
+// WithRedact replaces hostnames, IP addresses and home directory paths in the output with placeholders
+func WithRedact() ConfigOption {
+	return func(c *Config) error {
+		c.Redact = true
+		return nil
+	}
+}
+
+// WithSortResults reports the results sorted by resource type, id and property
+// rather than in the order of the gossfile
+func WithSortResults() ConfigOption {
+	return func(c *Config) error {
+		c.SortResults = true
+		return nil
+	}
+}
+
+// WithRecord writes the values the tests read from the system to f
+func WithRecord(f string) ConfigOption {
+	return func(c *Config) error {
+		c.Record = f
+		return nil
+	}
+}
+
+// WithReplay evaluates the tests against the values recorded in f instead of the system
+func WithReplay(f string) ConfigOption {
+	return func(c *Config) error {
+		c.Replay = f
+		return nil
+	}
+}
+
 // WithNoColor disables colored output
 func WithNoColor() ConfigOption {
 	return func(c *Config) error {
@@ -189,6 +593,47 @@ func WithPackageManager(p string) ConfigOption {
 	}
 }
 
+// WithResolver resolves the hosts of the addr, dns and http checks through
+// the DNS server and the /etc/hosts formatted hostsFile, either can be empty
+func WithResolver(server, hostsFile string) ConfigOption {
+	return func(c *Config) error {
+		c.DNSServer = server
+		c.HostsFile = hostsFile
+
+		return nil
+	}
+}
+
+// WithNetns is the network namespace of the network checks that don't set
+// one
+func WithNetns(name string) ConfigOption {
+	return func(c *Config) error {
+		c.Netns = name
+
+		return nil
+	}
+}
+
+// WithIPVersion is the address family of the network checks that don't set
+// one, 4, 6 or any
+func WithIPVersion(version string) ConfigOption {
+	return func(c *Config) error {
+		c.IPVersion = version
+
+		return nil
+	}
+}
+
+// WithProcfs reads services from /proc and /etc instead of running a service
+// manager
+func WithProcfs() ConfigOption {
+	return func(c *Config) error {
+		c.Procfs = true
+
+		return nil
+	}
+}
+
 // WithDebug enables debug output
 func WithDebug() ConfigOption {
 	return func(c *Config) error {
@@ -197,6 +642,15 @@ func WithDebug() ConfigOption {
 	}
 }
 
+// WithDiscover makes autoadd discover resources of kinds: containers,
+// services, ports or all
+func WithDiscover(kinds ...string) ConfigOption {
+	return func(c *Config) error {
+		c.Discover = kinds
+		return nil
+	}
+}
+
 // WithVarsFile is a json or yaml file containing variables to pass to the validator
 func WithVarsFile(file string) ConfigOption {
 	return func(c *Config) error {
@@ -205,6 +659,15 @@ func WithVarsFile(file string) ConfigOption {
 	}
 }
 
+// WithVarsSources are further vars sources, merged over the vars file in order:
+// json or yaml files, env:PREFIX, vault:path or ssm:/path
+func WithVarsSources(sources ...string) ConfigOption {
+	return func(c *Config) error {
+		c.VarsSources = sources
+		return nil
+	}
+}
+
 // WithVarsData uses v as variables to pass to the Validator
 func WithVarsData(v interface{}) ConfigOption {
 	return func(c *Config) error {
@@ -234,6 +697,22 @@ func WithVarsString(v string) ConfigOption {
 
 type OutputConfig struct {
 	FormatOptions []string
+	// Gossfile is the path of the gossfile the results are of, the github
+	// format annotates it
+	Gossfile string
+	// Redact leaves the hostname out of the junit properties
+	Redact bool
+	// RunID identifies the run in every output, so its results can be
+	// correlated with its logs, notifications and metrics
+	RunID string
+	// ScoreThreshold is the lowest score in percent of the score format that passes
+	ScoreThreshold float64
+	// Timing is how long the phases of the run took, for the summary
+	Timing *Timing
+	// Vars is the vars file of the gossfile and Version the version of goss,
+	// for the junit properties
+	Vars    string
+	Version string
 }
 
 type format string
@@ -243,9 +722,16 @@ const (
 	YAML format = "yaml"
 )
 
+// ignoredValue decodes nothing, so only the keys of sections are decoded to
+// validate them
+type ignoredValue struct{}
+
+func (ignoredValue) UnmarshalJSON([]byte) error                  { return nil }
+func (ignoredValue) UnmarshalYAML(func(interface{}) error) error { return nil }
+
 func ValidateSections(unmarshal func(interface{}) error, i interface{}, whitelist map[string]bool) error {
-	// Get generic input
-	var toValidate map[string]map[string]interface{}
+	// Get the attributes of the input, not their values
+	var toValidate map[string]map[string]ignoredValue
 	if err := unmarshal(&toValidate); err != nil {
 		return err
 	}
@@ -0,0 +1,190 @@
+package util
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Defaults are the flag values of goss config files. A top level value applies
+// to every command with the flag, one under the name of a command only to it
+// and wins over the top level one.
+type Defaults struct {
+	flags    map[string][]string
+	commands map[string]map[string][]string
+}
+
+// WorkingDirDefaults is the config file of the working directory, it's read
+// after the one of the user unless a config file is given
+const WorkingDirDefaults = ".goss.yaml"
+
+// programFlags run programs, send the results elsewhere or loosen the
+// restrictions of commands, so anyone who can write to a directory goss is
+// run from could take over goss with them
+var programFlags = map[string]bool{
+	"post-run-exec":     true,
+	"template-func":     true,
+	"notify-url":        true,
+	"pushgateway":       true,
+	"command-policy":    true,
+	"unprivileged-user": true,
+	"goss-binary":       true,
+}
+
+// pathFlags choose the files goss reads or writes, which could be ones the
+// writer of the config file of the working directory planted, or those it
+// wants overwritten
+var pathFlags = map[string]bool{
+	"gossfile":            true,
+	"vars":                true,
+	"hosts-file":          true,
+	"baseline":            true,
+	"maintenance-file":    true,
+	"schedule-file":       true,
+	"serve-config":        true,
+	"replay":              true,
+	"fixtures":            true,
+	"value-file":          true,
+	"ca-file":             true,
+	"client-cert":         true,
+	"client-key":          true,
+	"tls-cert":            true,
+	"tls-key":             true,
+	"tls-client-ca":       true,
+	"audit-log":           true,
+	"record":              true,
+	"output-details-file": true,
+	"prometheus-textfile": true,
+	"status-file":         true,
+	"output":              true,
+}
+
+// DefaultsFiles are the config files of the user and of the working
+// directory, in the order they're applied
+func DefaultsFiles() []string {
+	var files []string
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if home, err := os.UserHomeDir(); dir == "" && err == nil {
+		dir = filepath.Join(home, ".config")
+	}
+	if dir != "" {
+		files = append(files, filepath.Join(dir, "goss", "config.yaml"))
+	}
+	return append(files, WorkingDirDefaults)
+}
+
+// LoadDefaults reads the config files that exist of files, a later file wins
+// over an earlier one. flags are the flag names of each command, by command
+// name and "" for the global ones, a value of another flag is an error. The
+// file untrusted, when not "", can't set flags that run programs or choose
+// files.
+func LoadDefaults(files []string, flags map[string][]string, untrusted string) (*Defaults, error) {
+	known := map[string]map[string]bool{}
+	all := map[string]bool{}
+	for command, names := range flags {
+		known[command] = map[string]bool{}
+		for _, name := range names {
+			known[command][name], all[name] = true, true
+		}
+	}
+
+	d := &Defaults{flags: map[string][]string{}, commands: map[string]map[string][]string{}}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("config file error: %v", err)
+		}
+		var values map[string]interface{}
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("config file %s: %v", file, err)
+		}
+		for key, value := range values {
+			section, isSection := value.(map[interface{}]interface{})
+			if !isSection {
+				if !all[key] {
+					return nil, fmt.Errorf("config file %s: unknown flag %s", file, key)
+				}
+				if file == untrusted && (programFlags[key] || pathFlags[key]) {
+					return nil, errUntrustedFlag(file, key)
+				}
+				if d.flags[key], err = flagValues(value); err != nil {
+					return nil, fmt.Errorf("config file %s: %s: %v", file, key, err)
+				}
+				continue
+			}
+			if _, ok := known[key]; !ok || key == "" {
+				return nil, fmt.Errorf("config file %s: unknown command %s", file, key)
+			}
+			if d.commands[key] == nil {
+				d.commands[key] = map[string][]string{}
+			}
+			for k, v := range section {
+				name := fmt.Sprint(k)
+				if !known[key][name] && !known[""][name] {
+					return nil, fmt.Errorf("config file %s: %s: unknown flag %s", file, key, name)
+				}
+				if file == untrusted && (programFlags[name] || pathFlags[name]) {
+					return nil, errUntrustedFlag(file, key+": "+name)
+				}
+				if d.commands[key][name], err = flagValues(v); err != nil {
+					return nil, fmt.Errorf("config file %s: %s: %s: %v", file, key, name, err)
+				}
+			}
+		}
+	}
+	return d, nil
+}
+
+func errUntrustedFlag(file, flag string) error {
+	return fmt.Errorf("config file %s: %s can only be set by the config file of the user or one given with --config", file, flag)
+}
+
+// flagValues are the values a flag is set to for value, each element of a
+// list for flags that may be specified multiple times
+func flagValues(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, e := range v {
+			if _, ok := e.(map[interface{}]interface{}); ok {
+				return nil, fmt.Errorf("expected a value or a list of values")
+			}
+			values = append(values, fmt.Sprint(e))
+		}
+		return values, nil
+	case nil:
+		return nil, fmt.Errorf("expected a value or a list of values")
+	}
+	return []string{fmt.Sprint(value)}, nil
+}
+
+// Values are the flag values of command, sorted by flag name
+func (d *Defaults) Values(command string) []FlagValue {
+	merged := map[string][]string{}
+	for name, v := range d.flags {
+		merged[name] = v
+	}
+	for name, v := range d.commands[command] {
+		merged[name] = v
+	}
+	values := make([]FlagValue, 0, len(merged))
+	for name, v := range merged {
+		values = append(values, FlagValue{Name: name, Values: v})
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Name < values[j].Name })
+	return values
+}
+
+// FlagValue is the value of a flag of a config file, several when it may be
+// specified multiple times
+type FlagValue struct {
+	Name   string
+	Values []string
+}
@@ -0,0 +1,73 @@
+package util
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadDefaults(t *testing.T) {
+	dir := t.TempDir()
+	user, local := filepath.Join(dir, "config.yaml"), filepath.Join(dir, ".goss.yaml")
+	write := func(file, content string) {
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	flags := map[string][]string{
+		"":         {"vars", "package", "gossfile"},
+		"validate": {"format", "f", "retry-timeout", "tags", "post-run-exec", "audit-log", "baseline"},
+		"lint":     {"format"},
+	}
+	write(user, "vars: vars.yaml\npackage: rpm\nformat: json\nvalidate:\n  retry-timeout: 10s\n")
+	write(local, "package: apk\nvalidate:\n  format: documentation\n  tags: [web, db]\n")
+
+	d, err := LoadDefaults([]string{user, local, filepath.Join(dir, "missing.yaml")}, flags, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FlagValue{
+		{Name: "format", Values: []string{"documentation"}},
+		{Name: "package", Values: []string{"apk"}},
+		{Name: "retry-timeout", Values: []string{"10s"}},
+		{Name: "tags", Values: []string{"web", "db"}},
+		{Name: "vars", Values: []string{"vars.yaml"}},
+	}
+	if got := d.Values("validate"); !reflect.DeepEqual(got, want) {
+		t.Errorf("validate: got %v, want %v", got, want)
+	}
+	want = []FlagValue{
+		{Name: "format", Values: []string{"json"}},
+		{Name: "package", Values: []string{"apk"}},
+		{Name: "vars", Values: []string{"vars.yaml"}},
+	}
+	if got := d.Values("lint"); !reflect.DeepEqual(got, want) {
+		t.Errorf("lint: got %v, want %v", got, want)
+	}
+
+	invalid := map[string]string{
+		"fromat: json\n":                   "config file " + local + ": unknown flag fromat",
+		"valdiate:\n  format: json\n":      "config file " + local + ": unknown command valdiate",
+		"lint:\n  tags: [a]\n":             "config file " + local + ": lint: unknown flag tags",
+		"validate:\n  format:\n":           "config file " + local + ": validate: format: expected a value or a list of values",
+		"post-run-exec: sh\n":              "config file " + local + ": post-run-exec can only be set by the config file of the user or one given with --config",
+		"validate:\n  post-run-exec: sh\n": "config file " + local + ": validate: post-run-exec can only be set by the config file of the user or one given with --config",
+		"gossfile: /tmp/goss.yaml\n":       "config file " + local + ": gossfile can only be set by the config file of the user or one given with --config",
+		"vars: /tmp/vars.yaml\n":           "config file " + local + ": vars can only be set by the config file of the user or one given with --config",
+		"validate:\n  audit-log: /x\n":     "config file " + local + ": validate: audit-log can only be set by the config file of the user or one given with --config",
+		"validate:\n  baseline: /x\n":      "config file " + local + ": validate: baseline can only be set by the config file of the user or one given with --config",
+	}
+	for content, msg := range invalid {
+		write(local, content)
+		if _, err := LoadDefaults([]string{local}, flags, local); err == nil || err.Error() != msg {
+			t.Errorf("%q: got %v, want %s", content, err, msg)
+		}
+	}
+
+	// The same file is trusted when it's given with --config
+	write(local, "gossfile: goss.yaml\nvalidate:\n  post-run-exec: sh\n  audit-log: audit.ndjson\n")
+	if _, err := LoadDefaults([]string{local}, flags, ""); err != nil {
+		t.Errorf("trusted config file: %v", err)
+	}
+}
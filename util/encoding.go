@@ -0,0 +1,157 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// Encodings are the text encodings supported by DecodeText and NewDecodingReader
+var Encodings = []string{"auto", "utf-8", "utf-16le", "utf-16be", "latin-1"}
+
+// detectSize is how much of the input auto encoding detection looks at
+const detectSize = 512
+
+// DecodeText converts b from encoding to UTF-8, see NewDecodingReader
+func DecodeText(b []byte, encoding string) ([]byte, error) {
+	r, err := NewDecodingReader(bytes.NewReader(b), encoding)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+// NewDecodingReader returns a reader converting r from encoding to UTF-8. auto
+// detects a byte order mark or BOM-less UTF-16LE, the default output of many
+// Windows tools, and otherwise assumes UTF-8. A byte order mark is dropped
+func NewDecodingReader(r io.Reader, encoding string) (io.Reader, error) {
+	br := bufio.NewReaderSize(r, detectSize)
+	enc := strings.ToLower(encoding)
+	if enc == "auto" {
+		head, _ := br.Peek(detectSize)
+		enc = detectEncoding(head)
+	}
+
+	switch enc {
+	case "", "utf-8", "utf8":
+		return br, discardBOM(br, bomUTF8)
+	case "utf-16le", "utf16le", "utf-16", "utf16":
+		return &decodingReader{src: br, order: binary.LittleEndian}, discardBOM(br, bomUTF16LE)
+	case "utf-16be", "utf16be":
+		return &decodingReader{src: br, order: binary.BigEndian}, discardBOM(br, bomUTF16BE)
+	case "latin-1", "latin1", "iso-8859-1":
+		return &decodingReader{src: br}, nil
+	}
+	return nil, fmt.Errorf("unsupported encoding: %q, valid options: %s", encoding, strings.Join(Encodings, ", "))
+}
+
+func detectEncoding(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, bomUTF8):
+		return "utf-8"
+	case bytes.HasPrefix(head, bomUTF16LE):
+		return "utf-16le"
+	case bytes.HasPrefix(head, bomUTF16BE):
+		return "utf-16be"
+	case looksUTF16LE(head):
+		return "utf-16le"
+	}
+	return "utf-8"
+}
+
+func discardBOM(br *bufio.Reader, bom []byte) error {
+	head, _ := br.Peek(len(bom))
+	if bytes.Equal(head, bom) {
+		_, err := br.Discard(len(bom))
+		return err
+	}
+	return nil
+}
+
+// looksUTF16LE reports whether b is likely mostly ASCII text encoded as UTF-16LE,
+// where every high byte is zero
+func looksUTF16LE(b []byte) bool {
+	pairs := len(b) / 2
+	if pairs == 0 {
+		return false
+	}
+	var evenZeros, oddZeros int
+	for i := 0; i+1 < len(b); i += 2 {
+		if b[i] == 0 {
+			evenZeros++
+		}
+		if b[i+1] == 0 {
+			oddZeros++
+		}
+	}
+	return oddZeros*2 > pairs && evenZeros*10 < oddZeros
+}
+
+// decodingReader converts UTF-16 in order, or latin-1 when order is nil, to UTF-8
+type decodingReader struct {
+	src     io.Reader
+	order   binary.ByteOrder
+	buf     [4096]byte
+	pending []byte
+	out     bytes.Buffer
+	err     error
+}
+
+func (d *decodingReader) Read(p []byte) (int, error) {
+	for d.out.Len() == 0 && d.err == nil {
+		n, err := d.src.Read(d.buf[:])
+		d.pending = append(d.pending, d.buf[:n]...)
+		d.err = err
+		d.decode(err != nil)
+	}
+	if d.out.Len() > 0 {
+		return d.out.Read(p)
+	}
+	return 0, d.err
+}
+
+// decode converts the pending input, keeping incomplete characters for the
+// next read unless final
+func (d *decodingReader) decode(final bool) {
+	if d.order == nil {
+		for _, c := range d.pending {
+			d.out.WriteRune(rune(c))
+		}
+		d.pending = d.pending[:0]
+		return
+	}
+
+	n := len(d.pending) &^ 1
+	if !final && n >= 2 {
+		// Don't split a surrogate pair
+		if last := d.order.Uint16(d.pending[n-2:]); last >= 0xD800 && last < 0xDC00 {
+			n -= 2
+		}
+	}
+	units := make([]uint16, 0, n/2)
+	for i := 0; i < n; i += 2 {
+		units = append(units, d.order.Uint16(d.pending[i:]))
+	}
+	for _, r := range utf16.Decode(units) {
+		d.out.WriteRune(r)
+	}
+
+	rest := d.pending[n:]
+	if final && len(rest) > 0 {
+		d.out.WriteRune(utf8.RuneError)
+		rest = nil
+	}
+	d.pending = append(d.pending[:0], rest...)
+}
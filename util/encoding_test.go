@@ -0,0 +1,68 @@
+package util
+
+import (
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+func TestDecodeText(t *testing.T) {
+	tables := []struct {
+		in       []byte
+		encoding string
+		want     string
+	}{
+		{[]byte("héllo"), "utf-8", "héllo"},
+		{[]byte("\xEF\xBB\xBFhello"), "utf-8", "hello"},
+		{[]byte("h\x00\xe9\x00"), "utf-16le", "hé"},
+		{[]byte("\x00h\x00\xe9"), "utf-16be", "hé"},
+		{[]byte("h\xe9"), "latin-1", "hé"},
+		{[]byte("\xFF\xFEo\x00k\x00"), "auto", "ok"},
+		{[]byte("\xFE\xFF\x00o\x00k"), "auto", "ok"},
+		{[]byte("o\x00k\x00\r\x00\n\x00"), "auto", "ok\r\n"},
+		{[]byte("plain ascii"), "auto", "plain ascii"},
+		{[]byte("=\xd8\x00\xde"), "utf-16le", "😀"},
+	}
+
+	for _, table := range tables {
+		got, err := DecodeText(table.in, table.encoding)
+		if err != nil {
+			t.Errorf("DecodeText (%q, %s) returned an error: %v", table.in, table.encoding, err)
+			continue
+		}
+		if string(got) != table.want {
+			t.Errorf("DecodeText (%q, %s) was incorrect, got: %q, want: %q.", table.in, table.encoding, got, table.want)
+		}
+	}
+
+	if _, err := DecodeText([]byte("x"), "ebcdic"); err == nil {
+		t.Error("DecodeText should reject unsupported encodings")
+	}
+}
+
+// oneByteReader returns a byte per read to exercise characters split across reads
+type oneByteReader struct{ b []byte }
+
+func (o *oneByteReader) Read(p []byte) (int, error) {
+	if len(o.b) == 0 {
+		return 0, io.EOF
+	}
+	p[0] = o.b[0]
+	o.b = o.b[1:]
+	return 1, nil
+}
+
+func TestNewDecodingReader(t *testing.T) {
+	in := []byte("\xFF\xFEh\x00\xe9\x00=\xd8\x00\xde")
+	r, err := NewDecodingReader(&oneByteReader{in}, "auto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "hé😀"; string(got) != want {
+		t.Errorf("NewDecodingReader was incorrect, got: %q, want: %q.", got, want)
+	}
+}
@@ -0,0 +1,94 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// Error codes classify the errors of tests, they're stable so automation can
+// branch on them rather than on the messages
+const (
+	ErrCodeUnknown            = "GOSS-E-UNKNOWN"
+	ErrCodePkgBackendNotFound = "GOSS-E-PKG-BACKEND-NOT-FOUND"
+	ErrCodeBackendNotFound    = "GOSS-E-BACKEND-NOT-FOUND"
+	ErrCodeCommandNotFound    = "GOSS-E-COMMAND-NOT-FOUND"
+	ErrCodePermissionDenied   = "GOSS-E-PERMISSION-DENIED"
+	ErrCodeNotFound           = "GOSS-E-NOT-FOUND"
+	ErrCodeTimeout            = "GOSS-E-TIMEOUT"
+	ErrCodeConnectionRefused  = "GOSS-E-CONNECTION-REFUSED"
+	ErrCodeMatcherInvalid     = "GOSS-E-MATCHER-INVALID"
+	ErrCodeConfigInvalid      = "GOSS-E-CONFIG-INVALID"
+	ErrCodeFactNotRecorded    = "GOSS-E-FACT-NOT-RECORDED"
+	ErrCodeUnprivileged       = "GOSS-E-UNPRIVILEGED-WORKER"
+	ErrCodePanic              = "GOSS-E-PANIC"
+	ErrCodeNotBuiltIn         = "GOSS-E-NOT-BUILT-IN"
+)
+
+// CodedError is an error with one of the error codes, it's marshalled to
+// JSON as its code and message
+type CodedError struct {
+	Code string
+	Err  error
+}
+
+// NewCodedError is err with the error code code
+func NewCodedError(code string, err error) error {
+	return &CodedError{Code: code, Err: err}
+}
+
+func (e *CodedError) Error() string { return e.Err.Error() }
+
+func (e *CodedError) Unwrap() error { return e.Err }
+
+func (e *CodedError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}{e.Code, e.Err.Error()})
+}
+
+// ErrorCode is the error code of err, the one it was given or otherwise the
+// one of the kind of error it wraps, such as a permission or a timeout error
+func ErrorCode(err error) string {
+	var coded *CodedError
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &coded):
+		return coded.Code
+	case errors.Is(err, exec.ErrNotFound):
+		return ErrCodeCommandNotFound
+	case errors.Is(err, os.ErrPermission):
+		return ErrCodePermissionDenied
+	case errors.Is(err, os.ErrNotExist):
+		return ErrCodeNotFound
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrCodeTimeout
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrCodeConnectionRefused
+	}
+	return ErrCodeUnknown
+}
+
+// CodeError is err with its error code, so it's marshalled with it
+func CodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*CodedError); ok {
+		return err
+	}
+	return &CodedError{Code: ErrorCode(err), Err: err}
+}
+
+// ReplaceErrorMessage is an error with the message msg and the error code of
+// err, such as err redacted
+func ReplaceErrorMessage(err error, msg string) error {
+	return &CodedError{Code: ErrorCode(err), Err: errors.New(msg)}
+}
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestErrorCode(t *testing.T) {
+	_, notFound := os.Open("/goss/does/not/exist")
+	_, noCommand := exec.LookPath("goss-does-not-exist")
+	tables := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{errors.New("boom"), ErrCodeUnknown},
+		{NewCodedError(ErrCodeMatcherInvalid, errors.New("bad")), ErrCodeMatcherInvalid},
+		{fmt.Errorf("reading: %w", NewCodedError(ErrCodePkgBackendNotFound, errors.New("no rpm"))), ErrCodePkgBackendNotFound},
+		{notFound, ErrCodeNotFound},
+		{noCommand, ErrCodeCommandNotFound},
+		{&os.PathError{Op: "open", Path: "/etc/shadow", Err: os.ErrPermission}, ErrCodePermissionDenied},
+		{fmt.Errorf("query: %w", context.DeadlineExceeded), ErrCodeTimeout},
+	}
+	for _, tt := range tables {
+		if got := ErrorCode(tt.err); got != tt.want {
+			t.Errorf("ErrorCode(%v): got %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestCodedErrorJSON(t *testing.T) {
+	b, err := json.Marshal(struct {
+		Err error `json:"err"`
+	}{CodeError(&os.PathError{Op: "open", Path: "/etc/shadow", Err: os.ErrPermission})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"err":{"code":"GOSS-E-PERMISSION-DENIED","message":"open /etc/shadow: permission denied"}}`
+	if string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+
+	redacted := ReplaceErrorMessage(NewCodedError(ErrCodeTimeout, errors.New("dial 10.0.0.1")), "dial <ip-1>")
+	if ErrorCode(redacted) != ErrCodeTimeout || redacted.Error() != "dial <ip-1>" {
+		t.Errorf("replaced message: got %q, %q", ErrorCode(redacted), redacted)
+	}
+}
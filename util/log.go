@@ -0,0 +1,171 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogLevels are the levels of --log-level, the least verbose first
+var LogLevels = []string{"error", "warn", "info", "debug"}
+
+// LogFormats are the formats of --log-format: text, as the log package
+// writes, or a json object a line for log aggregators
+var LogFormats = []string{"text", "json"}
+
+const (
+	logError = iota
+	logWarn
+	logInfo
+	logDebug
+)
+
+// Logger writes the logs of goss, separate from the results, such as the
+// lifecycle of each check at the debug level
+type Logger struct {
+	mu    sync.Mutex
+	w     io.Writer
+	json  bool
+	level int
+}
+
+var logger = &Logger{w: os.Stderr, level: logInfo}
+
+// NewLogger writes the logs of level and above to w in format
+func NewLogger(w io.Writer, format, level string) (*Logger, error) {
+	l := &Logger{w: w}
+	switch format {
+	case "", "text":
+	case "json":
+		l.json = true
+	default:
+		return nil, fmt.Errorf("unknown log format %q, expected one of: %s", format, strings.Join(LogFormats, ", "))
+	}
+	if level == "" {
+		level = "info"
+	}
+	l.level = -1
+	for i, name := range LogLevels {
+		if name == level {
+			l.level = i
+		}
+	}
+	if l.level < 0 {
+		return nil, fmt.Errorf("unknown log level %q, expected one of: %s", level, strings.Join(LogLevels, ", "))
+	}
+	return l, nil
+}
+
+// SetupLogging makes the logger of format and level the one of goss
+func SetupLogging(w io.Writer, format, level string) error {
+	l, err := NewLogger(w, format, level)
+	if err != nil {
+		return err
+	}
+	SetLogger(l)
+	return nil
+}
+
+// SetLogger makes l the logger of goss, the messages of the log package are
+// its info logs
+func SetLogger(l *Logger) {
+	logger = l
+	switch {
+	case l.json:
+		log.SetFlags(0)
+		log.SetOutput(stdLogWriter{l})
+	case l.level >= logInfo:
+		log.SetFlags(log.LstdFlags)
+		log.SetOutput(l.w)
+	default:
+		log.SetOutput(ioutil.Discard)
+	}
+}
+
+// Log is the logger of goss
+func Log() *Logger {
+	return logger
+}
+
+// stdLogWriter logs the lines of the log package at the info level
+type stdLogWriter struct{ l *Logger }
+
+func (w stdLogWriter) Write(p []byte) (int, error) {
+	w.l.log(logInfo, strings.TrimSuffix(string(p), "\n"), nil)
+	return len(p), nil
+}
+
+// DebugEnabled reports whether debug logs are written, so what only they
+// report isn't computed for nothing
+func (l *Logger) DebugEnabled() bool {
+	return l.level >= logDebug
+}
+
+// Debug logs msg with fields, pairs of a key and its value
+func (l *Logger) Debug(msg string, fields ...interface{}) { l.log(logDebug, msg, fields) }
+
+// Info logs msg with fields, pairs of a key and its value
+func (l *Logger) Info(msg string, fields ...interface{}) { l.log(logInfo, msg, fields) }
+
+// Warn logs msg with fields, pairs of a key and its value
+func (l *Logger) Warn(msg string, fields ...interface{}) { l.log(logWarn, msg, fields) }
+
+// Error logs msg with fields, pairs of a key and its value
+func (l *Logger) Error(msg string, fields ...interface{}) { l.log(logError, msg, fields) }
+
+func (l *Logger) log(level int, msg string, fields []interface{}) {
+	if level > l.level {
+		return
+	}
+	now := time.Now()
+	var line []byte
+	if l.json {
+		entry := map[string]interface{}{
+			"time":  now.Format(time.RFC3339Nano),
+			"level": LogLevels[level],
+			"msg":   msg,
+		}
+		for i := 0; i+1 < len(fields); i += 2 {
+			entry[fmt.Sprint(fields[i])] = logValue(fields[i+1], true)
+		}
+		line, _ = json.Marshal(entry)
+	} else {
+		var b strings.Builder
+		b.WriteString(now.Format("2006/01/02 15:04:05 "))
+		b.WriteString(strings.ToUpper(LogLevels[level]))
+		b.WriteString(" ")
+		b.WriteString(msg)
+		for i := 0; i+1 < len(fields); i += 2 {
+			fmt.Fprintf(&b, " %v=%v", fields[i], logValue(fields[i+1], false))
+		}
+		line = []byte(b.String())
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.w.Write(append(line, '\n'))
+}
+
+// logValue is v as it's logged, durations are in seconds in json and errors
+// their message, text quotes the strings with spaces
+func logValue(v interface{}, json bool) interface{} {
+	switch x := v.(type) {
+	case time.Duration:
+		if json {
+			return x.Seconds()
+		}
+		return x.String()
+	case error:
+		return logValue(x.Error(), json)
+	case string:
+		if !json && strings.ContainsAny(x, " \t\n\"=") {
+			return fmt.Sprintf("%q", x)
+		}
+	}
+	return v
+}
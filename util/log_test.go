@@ -0,0 +1,44 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := NewLogger(&buf, "json", "debug")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Debug("check finished", "resource", "file:/etc/passwd", "duration", 1500*time.Millisecond, "error", errors.New("boom"))
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("not a json line: %q: %v", buf.String(), err)
+	}
+	want := map[string]interface{}{"level": "debug", "msg": "check finished", "resource": "file:/etc/passwd", "duration": 1.5, "error": "boom"}
+	for k, v := range want {
+		if entry[k] != v {
+			t.Errorf("%s: got %v, want %v", k, entry[k], v)
+		}
+	}
+
+	buf.Reset()
+	l, _ = NewLogger(&buf, "text", "info")
+	l.Debug("not logged")
+	l.Warn("check timed out", "resource", "command:sleep 10", "duration", 2*time.Second)
+	if got := buf.String(); !strings.HasSuffix(got, ` WARN check timed out resource="command:sleep 10" duration=2s`+"\n") {
+		t.Errorf("got %q", got)
+	}
+
+	if _, err := NewLogger(&buf, "xml", "info"); err == nil {
+		t.Error("unknown format should error")
+	}
+	if _, err := NewLogger(&buf, "text", "trace"); err == nil {
+		t.Error("unknown level should error")
+	}
+}
@@ -0,0 +1,32 @@
+package util
+
+import "time"
+
+// Timing is how long the phases of a run took, measured with the monotonic
+// clock. Output is the rest of the run, so it's only known once it ends.
+type Timing struct {
+	// Load is reading and decoding the gossfile and the gossfiles it
+	// includes, Render rendering their templates
+	Load     time.Duration
+	Render   time.Duration
+	Validate time.Duration
+}
+
+// Phase is how long a phase of a run took
+type Phase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Phases are the load, render, validate and output phases of a run that took
+// total, none without a timing
+func (t *Timing) Phases(total time.Duration) []Phase {
+	if t == nil {
+		return nil
+	}
+	output := total - t.Load - t.Render - t.Validate
+	if output < 0 {
+		output = 0
+	}
+	return []Phase{{"load", t.Load}, {"render", t.Render}, {"validate", t.Validate}, {"output", output}}
+}
@@ -6,11 +6,15 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
-	"sync"
+	runtimedebug "runtime/debug"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/google/uuid"
 
 	"github.com/aelsabbahy/goss/outputs"
 	"github.com/aelsabbahy/goss/resource"
@@ -18,16 +22,27 @@ import (
 	"github.com/aelsabbahy/goss/util"
 )
 
-func getGossConfig(vars string, varsInline string, specFile string) (cfg *GossConfig, err error) {
+// getGossConfig reads the gossfile of c, rendered with its vars and template
+// functions
+func getGossConfig(c *util.Config) (cfg *GossConfig, err error) {
+	specFile := c.Spec
 	// handle stdin
 	var fh *os.File
 	var path, source string
 	var gossConfig GossConfig
 
-	currentTemplateFilter, err = NewTemplateFilter(vars, varsInline)
+	var rendering time.Duration
+	start := time.Now()
+	filter, err := configTemplateFilter(c)
 	if err != nil {
 		return nil, err
 	}
+	rendering += time.Since(start)
+	currentTemplateFilter = func(data []byte) ([]byte, error) {
+		start := time.Now()
+		defer func() { rendering += time.Since(start) }()
+		return filter(data)
+	}
 
 	if specFile == "-" {
 		source = "STDIN"
@@ -67,32 +82,117 @@ func getGossConfig(vars string, varsInline string, specFile string) (cfg *GossCo
 	if len(gossConfig.Resources()) == 0 {
 		return nil, fmt.Errorf("found 0 tests, source: %v", source)
 	}
+	gossConfig.rendering = rendering
 
 	return &gossConfig, nil
 }
 
-func getOutputer(c *bool, format string) (outputs.Outputer, error) {
+func getOutputer(c *bool, format string, lang string) (outputs.Outputer, error) {
 	if c != nil && *c {
 		color.NoColor = true
 	}
 	if c != nil && !*c {
 		color.NoColor = false
 	}
+	if err := outputs.SetLanguage(lang); err != nil {
+		return nil, err
+	}
 
 	return outputs.GetOutputer(format)
 }
 
+func newOutputConfig(c *util.Config) (util.OutputConfig, error) {
+	if c.ScoreThreshold < 0 || c.ScoreThreshold > 100 {
+		return util.OutputConfig{}, fmt.Errorf("score threshold must be between 0 and 100, got %g", c.ScoreThreshold)
+	}
+	return util.OutputConfig{
+		FormatOptions:  c.FormatOptions,
+		Gossfile:       c.Spec,
+		Redact:         c.Redact,
+		ScoreThreshold: c.ScoreThreshold,
+		Vars:           strings.Join(varsSources(c), ","),
+		Version:        c.Version,
+	}, nil
+}
+
 // ValidateResults performs validation and provides programmatic access to validation results
 // no retries or outputs are supported
 func ValidateResults(c *util.Config) (results <-chan []resource.TestResult, err error) {
-	gossConfig, err := getGossConfig(c.Vars, c.VarsInline, c.Spec)
+	gossConfig, err := loadGossConfig(c)
 	if err != nil {
 		return nil, err
 	}
+	return ValidateGossConfig(c, *gossConfig)
+}
 
-	sys := system.New(c.PackageManager)
+// LoadGossConfig loads the gossfile of c with its vars and includes, keeping
+// the resources its tags select, as validate does
+func LoadGossConfig(c *util.Config) (*GossConfig, error) {
+	return loadGossConfig(c)
+}
+
+// ValidateGossConfig validates the resources of gossConfig, such as one of
+// LoadGossConfig, with the system of c as ValidateResults does, so a gossfile
+// loaded once is validated many times
+func ValidateGossConfig(c *util.Config, gossConfig GossConfig) (results <-chan []resource.TestResult, err error) {
+	sys, err := newSystem(c)
+	if err != nil {
+		return nil, err
+	}
+	concurrency, err := newConcurrency(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return validate(sys, gossConfig, concurrency, runDeadline(c.MaxRunDuration)), nil
+}
+
+// newSystem creates a System for the package manager, command policy and
+// resolver in c
+func newSystem(c *util.Config) (*system.System, error) {
+	policy, err := system.LoadCommandPolicy(c.CommandPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver, err := system.NewResolver(c.DNSServer, c.HostsFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := system.ValidateIPVersion(c.IPVersion); err != nil {
+		return nil, err
+	}
+	if err := system.ValidateNetns(c.Netns); err != nil {
+		return nil, err
+	}
+
+	sys := systemFor(c)
+	sys.CommandPolicy = policy
+	sys.Resolver = resolver
+	if c.UnprivilegedUser != "" {
+		if sys.Unprivileged, err = system.LookupCredential(c.UnprivilegedUser); err != nil {
+			return nil, fmt.Errorf("unprivileged user: %v", err)
+		}
+	}
+
+	return sys, nil
+}
 
-	return validate(sys, *gossConfig, c.MaxConcurrent), nil
+// newRunID identifies a run, in its outputs, notifications, logs and metrics
+func newRunID() string {
+	return uuid.New().String()
+}
+
+// systemFor is the system of the package manager, service mode, ip version
+// and network namespace of c
+func systemFor(c *util.Config) *system.System {
+	sys := system.New(c.PackageManager)
+	if c.Procfs {
+		sys.UseProcfs()
+	}
+	sys.IPVersion = c.IPVersion
+	sys.Netns = c.Netns
+	return sys
 }
 
 // Validate performs validation, writes formatted output to stdout by default
@@ -100,17 +200,22 @@ func ValidateResults(c *util.Config) (results <-chan []resource.TestResult, err
 // by the typical CLI invocation and will produce output to StdOut.  Use
 // ValidateResults for programmatic access
 func Validate(c *util.Config, startTime time.Time) (code int, err error) {
-	outputConfig := util.OutputConfig{
-		FormatOptions: c.FormatOptions,
+	loadStart := time.Now()
+	outputConfig, err := newOutputConfig(c)
+	if err != nil {
+		return 1, err
 	}
 
-	gossConfig, err := getGossConfig(c.Vars, c.VarsInline, c.Spec)
+	gossConfig, err := loadGossConfig(c)
 	if err != nil {
 		return 1, err
 	}
 
-	sys := system.New(c.PackageManager)
-	outputer, err := getOutputer(c.NoColor, c.OutputFormat)
+	sys, err := newSystem(c)
+	if err != nil {
+		return 1, err
+	}
+	outputer, err := getOutputer(c.NoColor, c.OutputFormat, c.Lang)
 	if err != nil {
 		return 1, err
 	}
@@ -121,13 +226,106 @@ func Validate(c *util.Config, startTime time.Time) (code int, err error) {
 		ofh = c.OutputWriter
 	}
 
+	if c.Preflight {
+		// Stderr keeps structured output formats parsable
+		if problems := resource.Preflight(sys, gossConfig.Resources()); len(problems) > 0 {
+			color.New(color.FgYellow).Fprintf(os.Stderr, "Preflight: these checks will be unreliable with the privileges goss runs with:\n")
+			for _, p := range problems {
+				color.New(color.FgYellow).Fprintf(os.Stderr, "  %s\n", p)
+			}
+			fmt.Fprintln(os.Stderr)
+		}
+	}
+
+	var details io.Writer
+	if c.MaxOutputBytes > 0 && c.OutputDetailsFile != "" {
+		dfh, err := os.Create(c.OutputDetailsFile)
+		if err != nil {
+			return 1, err
+		}
+		defer dfh.Close()
+		details = dfh
+	}
+
+	baseline, err := loadBaseline(c.Baseline)
+	if err != nil {
+		return 1, err
+	}
+
+	concurrency, err := newConcurrency(c)
+	if err != nil {
+		return 1, err
+	}
+
+	severityCodes, err := newSeverityExitCodes(c)
+	if err != nil {
+		return 1, err
+	}
+
+	notifier, err := NewNotifier(c.NotifyURL, c.NotifyPreset, c.NotifyTemplate, c.Spec)
+	if err != nil {
+		return 1, err
+	}
+
+	recording, err := useFacts(c.Record, c.Replay, c.UnprivilegedUser)
+	if err != nil {
+		return 1, err
+	}
+	defer resource.UseFacts(nil)
+
 	sleep := c.Sleep
 	retryTimeout := c.RetryTimeout
 	i := 1
 	for {
-		iStartTime := time.Now()
-		out := validate(sys, *gossConfig, c.MaxConcurrent)
-		exitCode := outputer.Output(ofh, out, iStartTime, outputConfig)
+		// The first attempt includes loading the gossfile
+		iStartTime, timing := time.Now(), &util.Timing{}
+		if i == 1 {
+			iStartTime, timing.Render = loadStart, gossConfig.rendering
+		}
+		windows, err := loadMaintenance(c.MaintenanceFile)
+		if err != nil {
+			return 1, err
+		}
+		audit := NewAuditLog(c.AuditLog, c.Spec)
+		metrics := NewMetricsSink(c.MetricsTextfile, c.Pushgateway)
+		postRun := NewPostRunExec(c.PostRunExec)
+		severities := NewSeverityTally(severityCodes)
+		timing.Load = time.Since(iStartTime) - timing.Render
+		outputConfig.Timing, outputConfig.RunID = timing, newRunID()
+		util.Log().Debug("run started", "run-id", outputConfig.RunID, "attempt", i, "resources", len(gossConfig.Resources()))
+		out := timeValidation(validate(sys, *gossConfig, concurrency, runDeadline(c.MaxRunDuration)), timing)
+		out = MaintenanceResults(out, windows, iStartTime)
+		out = BaselineResults(out, baseline, c.Redact)
+		out = outputs.RedactResults(out, c.Redact)
+		out = outputs.TruncateResults(out, c.MaxOutputBytes, details)
+		if c.SortResults {
+			out = outputs.SortResults(out)
+		}
+		out = outputs.OrderResults(out, c.FormatOptions)
+		out = audit.Results(out, iStartTime, outputConfig)
+		out = metrics.Results(out, iStartTime, outputConfig)
+		out = notifier.Results(out, iStartTime, outputConfig)
+		out = postRun.Results(out, iStartTime, outputConfig)
+		out = severities.Results(out)
+		exitCode := severities.ExitCode(outputer.Output(ofh, out, iStartTime, outputConfig))
+		util.Log().Debug("run finished", "run-id", outputConfig.RunID, "exit-code", exitCode, "duration", time.Since(iStartTime))
+		if err := audit.Err(); err != nil {
+			return 1, err
+		}
+		if err := metrics.Err(); err != nil {
+			return 1, err
+		}
+		if err := notifier.Err(); err != nil {
+			return 1, err
+		}
+		if err := postRun.Err(); err != nil {
+			return 1, err
+		}
+		if recording != nil {
+			if err := writeFacts(c.Record, recording); err != nil {
+				return 1, err
+			}
+		}
 		if retryTimeout == 0 || exitCode == 0 {
 			return exitCode, nil
 		}
@@ -137,44 +335,265 @@ func Validate(c *util.Config, startTime time.Time) (code int, err error) {
 		}
 		color.Red("Retrying in %s (elapsed/timeout time: %.3fs/%s)\n\n\n", sleep, elapsed.Seconds(), retryTimeout)
 		// Reset cache
-		sys = system.New(c.PackageManager)
+		policy, unprivileged, resolver := sys.CommandPolicy, sys.Unprivileged, sys.Resolver
+		sys = systemFor(c)
+		sys.CommandPolicy, sys.Unprivileged, sys.Resolver = policy, unprivileged, resolver
 		time.Sleep(sleep)
 		i++
 		fmt.Printf("Attempt #%d:\n", i)
 	}
 }
 
-func validate(sys *system.System, gossConfig GossConfig, maxConcurrent int) <-chan []resource.TestResult {
-	out := make(chan []resource.TestResult)
-	in := make(chan resource.Resource)
+// validate validates the resources of gossConfig, the ones that haven't finished
+// at deadline are reported as timed out. A zero deadline waits for all of them.
+func validate(sys *system.System, gossConfig GossConfig, concurrency Concurrency, deadline time.Time) <-chan []resource.TestResult {
+	if sys.Unprivileged != nil {
+		privileged, unprivileged := splitUnprivileged(gossConfig, sys.Netns)
+		return orderResults(mergeResults(
+			validateResources(sys, privileged, concurrency, deadline),
+			validateUnprivileged(sys, unprivileged, concurrency, deadline),
+		), gossConfig.Resources())
+	}
+	return validateResources(sys, gossConfig, concurrency, deadline)
+}
 
+// timeValidation sets the validate duration of timing, from now until the
+// last results of in
+func timeValidation(in <-chan []resource.TestResult, timing *util.Timing) <-chan []resource.TestResult {
+	start := time.Now()
+	out := make(chan []resource.TestResult)
 	go func() {
-		for _, t := range gossConfig.Resources() {
-			in <- t
+		defer close(out)
+		end := start
+		for results := range in {
+			end = time.Now()
+			out <- results
 		}
-		close(in)
+		timing.Validate = end.Sub(start)
 	}()
+	return out
+}
+
+// runDeadline is when a run started now has to finish by, zero without a
+// maximum duration
+func runDeadline(maxRunDuration time.Duration) time.Time {
+	if maxRunDuration <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(maxRunDuration)
+}
+
+type validated struct {
+	index   int
+	results []resource.TestResult
+}
+
+func validateResources(sys *system.System, gossConfig GossConfig, concurrency Concurrency, deadline time.Time) <-chan []resource.TestResult {
+	startTime := time.Now()
+	resources := gossConfig.Resources()
+	out := make(chan []resource.TestResult)
+	stop := make(chan struct{})
+	// Buffered so workers still validating at the deadline don't block forever
+	finished := make(chan validated, len(resources))
 
 	workerCount := runtime.NumCPU() * 5
-	if workerCount > maxConcurrent {
-		workerCount = maxConcurrent
-	}
-	var wg sync.WaitGroup
-	for i := 0; i < workerCount; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for f := range in {
-				out <- f.Validate(sys)
-			}
+	if workerCount > concurrency.Max {
+		workerCount = concurrency.Max
+	}
+	// Requirements left out of the run are ignored, an unresolved one or a
+	// cycle was already reported by loadGossConfig
+	reqs, order, err := resolveRequires(resources, true)
+	if err != nil {
+		reqs, order = make([][]int, len(resources)), nil
+	}
+	rank := make([]int, len(resources))
+	for i := range rank {
+		rank[i] = i
+	}
+	for k, i := range order {
+		rank[i] = k
+	}
+	// ready[i] is closed once resource i is validated, and ok[i] set before
+	// to whether its requirers can be validated
+	ready := make([]chan struct{}, len(resources))
+	for i := range ready {
+		ready[i] = make(chan struct{})
+	}
+	ok := make([]bool, len(resources))
 
-		}()
+	// Each pool is fed on its own, so a pool whose workers are all busy
+	// doesn't hold up the resources of the others. They're fed requirements
+	// first, so a worker waiting on one never waits on a resource that isn't
+	// being validated.
+	indexes, workers := concurrency.pools(resources, workerCount)
+	for key := range indexes {
+		pool := indexes[key]
+		sort.SliceStable(pool, func(a, b int) bool { return rank[pool[a]] < rank[pool[b]] })
+		in := make(chan int)
+		go func(indexes []int) {
+			defer close(in)
+			for _, i := range indexes {
+				select {
+				case in <- i:
+				case <-stop:
+					return
+				}
+			}
+		}(indexes[key])
+		for w := 0; w < workers[key]; w++ {
+			go func() {
+				for i := range in {
+					results, passed, stopped := validateRequiring(sys, resources, i, reqs[i], ready, ok, stop)
+					if stopped {
+						return
+					}
+					ok[i] = passed
+					close(ready[i])
+					resource.SetSeverity(results, resources[i].(resource.ResourceRead))
+					resource.SetTags(results, resources[i].(resource.ResourceRead))
+					finished <- validated{index: i, results: results}
+				}
+			}()
+		}
 	}
 
 	go func() {
-		wg.Wait()
-		close(out)
+		defer close(out)
+		var expired <-chan time.Time
+		if !deadline.IsZero() {
+			timer := time.NewTimer(time.Until(deadline))
+			defer timer.Stop()
+			expired = timer.C
+		}
+		// Results are sent in the order of the resources, whatever order
+		// they finish in, so consecutive reports can be diffed
+		done := make([]bool, len(resources))
+		results := make([][]resource.TestResult, len(resources))
+		next := 0
+		for n := 0; n < len(resources); n++ {
+			select {
+			case v := <-finished:
+				done[v.index] = true
+				results[v.index] = v.results
+				for ; next < len(resources) && done[next]; next++ {
+					out <- results[next]
+					results[next] = nil
+				}
+			case <-expired:
+				close(stop)
+				timedOut(resources, next, done, results, finished, out, startTime)
+				return
+			}
+		}
 	}()
 
 	return out
 }
+
+// validateRequiring validates resources[i] once its requirements are, or skips
+// it when one of them didn't pass, passed is whether the resources requiring it
+// can be validated. stopped is when the run was stopped before.
+func validateRequiring(sys *system.System, resources []resource.Resource, i int, reqs []int, ready []chan struct{}, ok []bool, stop <-chan struct{}) (results []resource.TestResult, passed, stopped bool) {
+	for _, j := range reqs {
+		select {
+		case <-ready[j]:
+		case <-stop:
+			return nil, false, true
+		}
+		if ok[j] {
+			continue
+		}
+		reason := fmt.Sprintf("requires %s, which didn't pass", requiresRef(resourceTypeKeys(), resources[j]))
+		util.Log().Debug("check skipped", "resource", logRef(resources[i]), "reason", reason)
+		res := resources[i].(resource.ResourceRead)
+		return []resource.TestResult{resource.SkippedResult(res, "requires", reason, time.Now())}, false, false
+	}
+	results = validateResource(sys, resources[i])
+	return results, resource.Passed(results), false
+}
+
+// validateResource validates r, a panic of its backend or matchers errors r
+// rather than ending the run. A resource whose tests didn't all pass is
+// validated again up to the retries of its meta.
+func validateResource(sys *system.System, r resource.Resource) (results []resource.TestResult) {
+	startTime := time.Now()
+	res := r.(resource.ResourceRead)
+	logger := util.Log()
+	defer func() {
+		if p := recover(); p != nil {
+			logger.Error("check panicked", "resource", logRef(r), "panic", fmt.Sprint(p), "stack", string(runtimedebug.Stack()))
+			results = []resource.TestResult{resource.PanicResult(res, p, startTime)}
+		}
+	}()
+	if logger.DebugEnabled() {
+		ref := logRef(r)
+		logger.Debug("check started", "resource", ref)
+		defer func() {
+			logger.Debug("check finished", "resource", ref, "passed", resource.Passed(results), "tests", len(results), "duration", time.Since(startTime))
+		}()
+	}
+	policy, err := resource.Retries(res)
+	if err != nil {
+		return []resource.TestResult{resource.ConfigErrorResult(res, "retries", err, startTime)}
+	}
+	// Resolved on every run, so rotated secrets are picked up
+	r, err = resource.ResolveSecrets(r)
+	if err != nil {
+		return []resource.TestResult{resource.ConfigErrorResult(res, "secret", err, startTime)}
+	}
+	results = r.Validate(sys)
+	interval := policy.Interval
+	attempts := 1
+	for ; attempts <= policy.Retries && !resource.Passed(results); attempts++ {
+		logger.Debug("check retried", "resource", logRef(r), "attempt", attempts+1, "interval", interval)
+		time.Sleep(interval)
+		interval = time.Duration(float64(interval) * policy.Backoff)
+		results = r.Validate(sys)
+	}
+	if attempts > 1 {
+		for i := range results {
+			results[i].Attempts = attempts
+		}
+	}
+	return results
+}
+
+// logRef is how the logs name r, such as service:nginx, the type is left out
+// when it isn't one of the gossfile
+func logRef(r resource.Resource) string {
+	t := reflect.TypeOf(r)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	id := r.(resource.ResourceRead).ID()
+	if key, ok := resourceTypeKeys()[t.Name()]; ok {
+		return key + ":" + id
+	}
+	return id
+}
+
+// timedOut sends the results of the resources from next on that finished in
+// time, in order with a timed out result for every other one
+func timedOut(resources []resource.Resource, next int, done []bool, results [][]resource.TestResult, finished <-chan validated, out chan<- []resource.TestResult, startTime time.Time) {
+	for drained := false; !drained; {
+		select {
+		case v := <-finished:
+			done[v.index] = true
+			results[v.index] = v.results
+		default:
+			drained = true
+		}
+	}
+	for i := next; i < len(resources); i++ {
+		if done[i] {
+			out <- results[i]
+		} else {
+			util.Log().Warn("check timed out", "resource", logRef(resources[i]), "duration", time.Since(startTime))
+			res := resources[i].(resource.ResourceRead)
+			results := []resource.TestResult{resource.TimedOutResult(res, startTime)}
+			resource.SetSeverity(results, res)
+			resource.SetTags(results, res)
+			out <- results
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package goss
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aelsabbahy/goss/util"
+)
+
+// varsClient is the client vault and ssm vars sources are read with
+var varsClient = &http.Client{Timeout: 10 * time.Second}
+
+// ssmEndpoint is the URL of the SSM API of region
+var ssmEndpoint = func(region string) string {
+	return "https://ssm." + region + ".amazonaws.com/"
+}
+
+// varsSources are the vars sources of c, in the order they're merged
+func varsSources(c *util.Config) []string {
+	var sources []string
+	for _, s := range append([]string{c.Vars}, c.VarsSources...) {
+		if s != "" {
+			sources = append(sources, s)
+		}
+	}
+	return sources
+}
+
+// varsFromSource reads the vars of source: the variables of the environment
+// with a prefix as env:PREFIX, a secret of Vault as vault:path, the
+// parameters under a path of AWS SSM Parameter Store as ssm:/path, and
+// otherwise, or as file:path, a json/yaml file
+func varsFromSource(source string) (map[string]interface{}, error) {
+	kind, ref := parseVarsSource(source)
+	switch kind {
+	case "env":
+		return varsFromEnv(ref)
+	case "vault":
+		return varsFromVault(ref)
+	case "ssm":
+		return varsFromSSM(ref)
+	}
+	return varsFromFile(ref)
+}
+
+func parseVarsSource(source string) (kind, ref string) {
+	if i := strings.Index(source, ":"); i > 0 {
+		switch source[:i] {
+		case "env", "file", "vault", "ssm":
+			return source[:i], source[i+1:]
+		}
+	}
+	return "file", source
+}
+
+// varsFiles are the files of the vars sources of c
+func varsFiles(c *util.Config) []string {
+	var files []string
+	for _, source := range varsSources(c) {
+		if kind, ref := parseVarsSource(source); kind == "file" {
+			files = append(files, ref)
+		}
+	}
+	return files
+}
+
+// varsFromEnv are the environment variables whose name starts with prefix,
+// by the rest of their name
+func varsFromEnv(prefix string) (map[string]interface{}, error) {
+	if prefix == "" {
+		return nil, fmt.Errorf("env vars source needs a prefix, such as env:APP_")
+	}
+	vars := make(map[string]interface{})
+	for _, e := range os.Environ() {
+		sep := strings.Index(e, "=")
+		if name := e[:sep]; strings.HasPrefix(name, prefix) && name != prefix {
+			vars[strings.TrimPrefix(name, prefix)] = e[sep+1:]
+		}
+	}
+	return vars, nil
+}
+
+// varsFromVault reads the secret at path from the Vault of VAULT_ADDR with
+// VAULT_TOKEN, the data of the secret for a kv version 2 engine
+func varsFromVault(path string) (map[string]interface{}, error) {
+	addr, token := os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return nil, fmt.Errorf("VAULT_ADDR and VAULT_TOKEN must be set")
+	}
+	req, err := http.NewRequest("GET", strings.TrimRight(addr, "/")+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+	var secret struct {
+		Data   map[string]interface{} `json:"data"`
+		Errors []string               `json:"errors"`
+	}
+	if err := doVarsRequest(req, &secret); err != nil {
+		return nil, err
+	}
+	if len(secret.Errors) > 0 {
+		return nil, fmt.Errorf("vault: %s", strings.Join(secret.Errors, ", "))
+	}
+	if data, ok := secret.Data["data"].(map[string]interface{}); ok {
+		if _, ok := secret.Data["metadata"]; ok {
+			return data, nil
+		}
+	}
+	if secret.Data == nil {
+		return make(map[string]interface{}), nil
+	}
+	return secret.Data, nil
+}
+
+// varsFromSSM reads the parameters under path, decrypted, from the SSM
+// Parameter Store of AWS_REGION with the credentials of the environment.
+// Parameters of sub-paths are nested, /app/db/host under /app as db.host.
+func varsFromSSM(path string) (map[string]interface{}, error) {
+	region, accessKey, secretKey, err := awsCredentials()
+	if err != nil {
+		return nil, err
+	}
+	vars := make(map[string]interface{})
+	next := ""
+	for {
+		input := map[string]interface{}{"Path": path, "Recursive": true, "WithDecryption": true}
+		if next != "" {
+			input["NextToken"] = next
+		}
+		body, err := json.Marshal(input)
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequest("POST", ssmEndpoint(region), bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+		req.Header.Set("X-Amz-Target", "AmazonSSM.GetParametersByPath")
+		if token := os.Getenv("AWS_SESSION_TOKEN"); token != "" {
+			req.Header.Set("X-Amz-Security-Token", token)
+		}
+		signAWS(req, body, "ssm", region, accessKey, secretKey, time.Now())
+		var out struct {
+			Parameters []struct {
+				Name  string
+				Value string
+			}
+			NextToken string
+		}
+		if err := doVarsRequest(req, &out); err != nil {
+			return nil, err
+		}
+		for _, p := range out.Parameters {
+			name := strings.Trim(strings.TrimPrefix(p.Name, path), "/")
+			if err := setNested(vars, strings.Split(name, "/"), p.Value); err != nil {
+				return nil, fmt.Errorf("parameter %s: %v", p.Name, err)
+			}
+		}
+		if next = out.NextToken; next == "" {
+			return vars, nil
+		}
+	}
+}
+
+// awsCredentials are the region and the credentials of the environment
+func awsCredentials() (region, accessKey, secretKey string, err error) {
+	region = os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	accessKey, secretKey = os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if region == "" || accessKey == "" || secretKey == "" {
+		return "", "", "", fmt.Errorf("AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+	return region, accessKey, secretKey, nil
+}
+
+func setNested(vars map[string]interface{}, keys []string, value string) error {
+	for _, k := range keys[:len(keys)-1] {
+		child, ok := vars[k].(map[string]interface{})
+		if !ok {
+			if _, isValue := vars[k]; isValue {
+				return fmt.Errorf("%s is both a parameter and a path", k)
+			}
+			child = make(map[string]interface{})
+			vars[k] = child
+		}
+		vars = child
+	}
+	if _, isPath := vars[keys[len(keys)-1]].(map[string]interface{}); isPath {
+		return fmt.Errorf("%s is both a parameter and a path", keys[len(keys)-1])
+	}
+	vars[keys[len(keys)-1]] = value
+	return nil
+}
+
+func doVarsRequest(req *http.Request, v interface{}) error {
+	resp, err := varsClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, v)
+}
+
+// signAWS signs req, whose payload is body, with AWS Signature Version 4
+func signAWS(req *http.Request, body []byte, service, region, accessKey, secretKey string, t time.Time) {
+	amzDate := t.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	if req.Host != "" {
+		headers["host"] = req.Host
+	}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payload := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method, path, strings.Replace(req.URL.Query().Encode(), "+", "%20", -1),
+		canonicalHeaders.String(), signedHeaders, hex.EncodeToString(payload[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+
+	key := []byte("AWS4" + secretKey)
+	for _, s := range []string{date, region, service, "aws4_request"} {
+		key = hmacSHA256(key, s)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
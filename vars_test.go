@@ -0,0 +1,134 @@
+package goss
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setEnv(t *testing.T, env map[string]string) {
+	for k, v := range env {
+		old, ok := os.LookupEnv(k)
+		os.Setenv(k, v)
+		k := k
+		t.Cleanup(func() {
+			if ok {
+				os.Setenv(k, old)
+			} else {
+				os.Unsetenv(k)
+			}
+		})
+	}
+}
+
+func TestLoadVarsSources(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "vars.yaml")
+	if err := ioutil.WriteFile(file, []byte("a: file\nb: file\nc: file\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	setEnv(t, map[string]string{"GOSSTEST_b": "env", "GOSSTEST_c": "env"})
+
+	vars, err := loadVars([]string{file, "env:GOSSTEST_"}, `{c: inline}`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": "file", "b": "env", "c": "inline"}, vars)
+
+	vars, err = loadVars([]string{"env:GOSSTEST_", "file:" + file}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": "file", "b": "file", "c": "file"}, vars)
+
+	_, err = loadVars([]string{"env:"}, "")
+	assert.EqualError(t, err, "Error: loading vars 'env:'\nenv vars source needs a prefix, such as env:APP_")
+}
+
+func TestVarsFromVault(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/app":
+			w.Write([]byte(`{"data":{"data":{"password":"hunter2","port":5432},"metadata":{"version":3}}}`))
+		case "/v1/kv/app":
+			w.Write([]byte(`{"data":{"password":"hunter2"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer srv.Close()
+	setEnv(t, map[string]string{"VAULT_ADDR": srv.URL, "VAULT_TOKEN": "s.token"})
+
+	vars, err := varsFromSource("vault:secret/data/app")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"password": "hunter2", "port": float64(5432)}, vars)
+
+	vars, err = varsFromSource("vault:/kv/app")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"password": "hunter2"}, vars)
+
+	_, err = varsFromSource("vault:kv/missing")
+	assert.EqualError(t, err, `404 Not Found: {"errors":[]}`)
+
+	os.Setenv("VAULT_TOKEN", "wrong")
+	_, err = varsFromSource("vault:kv/app")
+	assert.EqualError(t, err, `403 Forbidden: {"errors":["permission denied"]}`)
+}
+
+func TestVarsFromSSM(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/eu-west-1/ssm/aws4_request") ||
+			r.Header.Get("X-Amz-Target") != "AmazonSSM.GetParametersByPath" || r.Header.Get("X-Amz-Security-Token") != "session" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var input struct {
+			Path           string
+			Recursive      bool
+			WithDecryption bool
+			NextToken      string
+		}
+		json.NewDecoder(r.Body).Decode(&input)
+		if input.Path != "/app/prod" || !input.Recursive || !input.WithDecryption {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if input.NextToken == "" {
+			w.Write([]byte(`{"Parameters":[{"Name":"/app/prod/version","Value":"1.2"}],"NextToken":"more"}`))
+			return
+		}
+		w.Write([]byte(`{"Parameters":[{"Name":"/app/prod/db/host","Value":"db1"},{"Name":"/app/prod/db/port","Value":"5432"}]}`))
+	}))
+	defer srv.Close()
+	endpoint := ssmEndpoint
+	ssmEndpoint = func(string) string { return srv.URL + "/" }
+	defer func() { ssmEndpoint = endpoint }()
+	setEnv(t, map[string]string{"AWS_REGION": "eu-west-1", "AWS_ACCESS_KEY_ID": "AKID", "AWS_SECRET_ACCESS_KEY": "secret", "AWS_SESSION_TOKEN": "session"})
+
+	vars, err := varsFromSource("ssm:/app/prod")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"version": "1.2",
+		"db":      map[string]interface{}{"host": "db1", "port": "5432"},
+	}, vars)
+}
+
+func TestSignAWS(t *testing.T) {
+	// The example of the AWS Signature Version 4 documentation
+	req, _ := http.NewRequest("GET", "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	signAWS(req, nil, "iam", "us-east-1", "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, "+
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7", req.Header.Get("Authorization"))
+}
@@ -0,0 +1,347 @@
+package goss
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/aelsabbahy/goss/outputs"
+	"github.com/aelsabbahy/goss/resource"
+	"github.com/aelsabbahy/goss/util"
+	"github.com/fatih/color"
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchSettle is how long to wait for more changes to the gossfile before
+// running it, editors write a file in several steps
+var watchSettle = 200 * time.Millisecond
+
+// WatchStatus is the content of --status-file, the outcome of the latest run
+type WatchStatus struct {
+	ExitCode int       `json:"exit-code"`
+	Time     time.Time `json:"time"`
+	Tests    int       `json:"tests"`
+	Failed   int       `json:"failed"`
+}
+
+// Watch validates the system every c.WatchInterval, and when the gossfile or
+// vars file change, until it's interrupted. The resources with a tag of a
+// schedule of c.WatchSchedule are validated on that schedule instead. The
+// first run of a schedule is output in full and later ones only print the
+// tests whose result changed, the exit code is that of the latest run.
+func Watch(c *util.Config) (int, error) {
+	outputConfig, err := newOutputConfig(c)
+	if err != nil {
+		return 1, err
+	}
+	gossConfig, err := loadGossConfig(c)
+	if err != nil {
+		return 1, err
+	}
+	sys, err := newSystem(c)
+	if err != nil {
+		return 1, err
+	}
+	outputer, err := getOutputer(c.NoColor, c.OutputFormat, c.Lang)
+	if err != nil {
+		return 1, err
+	}
+	baseline, err := loadBaseline(c.Baseline)
+	if err != nil {
+		return 1, err
+	}
+	concurrency, err := newConcurrency(c)
+	if err != nil {
+		return 1, err
+	}
+	schedules, err := loadSchedules(c.WatchSchedule, c.WatchInterval)
+	if err != nil {
+		return 1, err
+	}
+	var ofh io.Writer = os.Stdout
+	if c.OutputWriter != nil {
+		ofh = c.OutputWriter
+	}
+
+	changes, err := watchFiles(append([]string{c.Spec}, varsFiles(c)...), []string{c.WatchStatusFile, c.MetricsTextfile, c.AuditLog, c.OutputDetailsFile})
+	if err != nil {
+		return 1, err
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	// Runs never overlap, a schedule that comes due during the run of another
+	// waits for it, and runs once however many times it came due
+	previous := make(map[*WatchSchedule][]resource.TestResult)
+	latest := make(map[*WatchSchedule]int)
+	next := make(map[*WatchSchedule]time.Time, len(schedules))
+	exitCode := 0
+	for {
+		for _, s := range schedules {
+			if next[s].After(time.Now()) {
+				continue
+			}
+			selected := s.selection(*gossConfig)
+			if len(selected.Resources()) == 0 {
+				next[s] = s.next(time.Now())
+				continue
+			}
+			iStartTime := time.Now()
+			if c.WatchSchedule != "" {
+				fmt.Fprintf(ofh, "%s Running schedule %s\n", iStartTime.Format(time.RFC3339), s.name())
+			}
+			windows, err := loadMaintenance(c.MaintenanceFile)
+			if err != nil {
+				return 1, err
+			}
+			audit := NewAuditLog(c.AuditLog, c.Spec)
+			metrics := NewMetricsSink(c.MetricsTextfile, c.Pushgateway)
+			postRun := NewPostRunExec(c.PostRunExec)
+			timing := &util.Timing{Load: time.Since(iStartTime)}
+			outputConfig.Timing, outputConfig.RunID = timing, newRunID()
+			out := timeValidation(validate(sys, selected, concurrency, runDeadline(c.MaxRunDuration)), timing)
+			out = MaintenanceResults(out, windows, iStartTime)
+			out = BaselineResults(out, baseline, c.Redact)
+			out = outputs.RedactResults(out, c.Redact)
+			out = outputs.TruncateResults(out, c.MaxOutputBytes, nil)
+			if c.SortResults {
+				out = outputs.SortResults(out)
+			}
+			out = outputs.OrderResults(out, c.FormatOptions)
+			out = audit.Results(out, iStartTime, outputConfig)
+			out = metrics.Results(out, iStartTime, outputConfig)
+			out = postRun.Results(out, iStartTime, outputConfig)
+			var results [][]resource.TestResult
+			var current []resource.TestResult
+			for resultGroup := range out {
+				results = append(results, resultGroup)
+				current = append(current, resultGroup...)
+			}
+			if err := audit.Err(); err != nil {
+				return 1, err
+			}
+			if err := metrics.Err(); err != nil {
+				return 1, err
+			}
+			if err := postRun.Err(); err != nil {
+				return 1, err
+			}
+
+			if last, ok := previous[s]; !ok {
+				exitCode = outputer.Output(ofh, resultsChan(results), iStartTime, outputConfig)
+			} else {
+				exitCode = outputer.Output(ioutil.Discard, resultsChan(results), iStartTime, outputConfig)
+				for _, line := range watchTransitions(last, current) {
+					fmt.Fprintf(ofh, "%s %s\n", iStartTime.Format(time.RFC3339), line)
+				}
+			}
+			previous[s], latest[s] = current, exitCode
+			statusCode, statusResults := scheduleStatus(schedules, previous, latest)
+			if err := writeWatchStatus(c.WatchStatusFile, statusCode, statusResults, iStartTime); err != nil {
+				return 1, err
+			}
+			next[s] = s.next(time.Now())
+			// Reset cache
+			policy, unprivileged, resolver := sys.CommandPolicy, sys.Unprivileged, sys.Resolver
+			sys = systemFor(c)
+			sys.CommandPolicy, sys.Unprivileged, sys.Resolver = policy, unprivileged, resolver
+		}
+
+		due := next[schedules[0]]
+		for _, s := range schedules {
+			if next[s].Before(due) {
+				due = next[s]
+			}
+		}
+		timer := time.NewTimer(time.Until(due))
+		select {
+		case <-timer.C:
+		case <-changes:
+			settle(changes)
+			cfg, err := loadGossConfig(c)
+			if err != nil {
+				color.Red("Not reloading the gossfile: %v\n", err)
+			} else {
+				fmt.Fprintf(ofh, "%s Gossfile changed, running it\n", time.Now().Format(time.RFC3339))
+				gossConfig = cfg
+				next = make(map[*WatchSchedule]time.Time, len(schedules))
+			}
+		case <-signals:
+			return exitCode, nil
+		}
+		timer.Stop()
+	}
+}
+
+// scheduleStatus is the exit code, the highest of the latest runs of the
+// schedules, and the results of all of them
+func scheduleStatus(schedules []*WatchSchedule, previous map[*WatchSchedule][]resource.TestResult, latest map[*WatchSchedule]int) (int, []resource.TestResult) {
+	exitCode := 0
+	var results []resource.TestResult
+	for _, s := range schedules {
+		if latest[s] > exitCode {
+			exitCode = latest[s]
+		}
+		results = append(results, previous[s]...)
+	}
+	return exitCode, results
+}
+
+// watchFiles sends on the channel when the files are written, created or
+// replaced, or another YAML or JSON file of their directories that the
+// gossfile may include. Directories are watched as editors replace files, the
+// ignored files are those goss writes itself.
+func watchFiles(files, ignored []string) (<-chan struct{}, error) {
+	changes := make(chan struct{}, 1)
+	watched := map[string]bool{}
+	for _, f := range ignored {
+		if abs, err := filepath.Abs(f); f != "" && err == nil {
+			watched[abs] = false
+		}
+	}
+	var dirs []string
+	for _, f := range files {
+		if f == "" || f == "-" {
+			continue
+		}
+		abs, err := filepath.Abs(f)
+		if err != nil {
+			return nil, err
+		}
+		watched[abs] = true
+		dirs = append(dirs, filepath.Dir(abs))
+	}
+	if len(dirs) == 0 {
+		return changes, nil
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, err
+		}
+	}
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+					continue
+				}
+				ext := strings.ToLower(filepath.Ext(event.Name))
+				w, known := watched[event.Name]
+				if w || !known && (ext == ".yaml" || ext == ".yml" || ext == ".json") {
+					select {
+					case changes <- struct{}{}:
+					default:
+					}
+				}
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+	return changes, nil
+}
+
+// settle waits until the changes stop for watchSettle
+func settle(changes <-chan struct{}) {
+	for {
+		select {
+		case <-changes:
+		case <-time.After(watchSettle):
+			return
+		}
+	}
+}
+
+// watchTransitions describes the tests whose result differs from the previous
+// run, and the tests added or removed when the gossfile changed
+func watchTransitions(previous, current []resource.TestResult) []string {
+	key := func(r resource.TestResult) string {
+		return baselineKey(r.ResourceType, r.ResourceId, r.Property)
+	}
+	name := func(r resource.TestResult) string {
+		if r.Property == "" {
+			return fmt.Sprintf("%s: %s", r.ResourceType, r.ResourceId)
+		}
+		return fmt.Sprintf("%s: %s: %s", r.ResourceType, r.ResourceId, r.Property)
+	}
+	before := make(map[string]resource.TestResult, len(previous))
+	for _, r := range previous {
+		before[key(r)] = r
+	}
+	var lines []string
+	seen := make(map[string]bool, len(current))
+	for _, r := range current {
+		seen[key(r)] = true
+		p, ok := before[key(r)]
+		switch {
+		case !ok:
+			lines = append(lines, fmt.Sprintf("%s: new, %s", name(r), watchOutcome(r)))
+		case p.Result != r.Result:
+			lines = append(lines, fmt.Sprintf("%s: %s -> %s", name(r), watchOutcome(p), watchOutcome(r)))
+		}
+	}
+	for _, r := range previous {
+		if !seen[key(r)] {
+			lines = append(lines, fmt.Sprintf("%s: removed", name(r)))
+		}
+	}
+	return lines
+}
+
+func watchOutcome(r resource.TestResult) string {
+	switch r.Result {
+	case resource.SUCCESS:
+		return "passed"
+	case resource.SKIP:
+		return "skipped"
+	case resource.TIMEOUT:
+		return "timed out"
+	case resource.ERROR:
+		return fmt.Sprintf("error: %v", r.Err)
+	}
+	if r.Found != nil {
+		return fmt.Sprintf("failed, found: %s", strings.Join(r.Found, ", "))
+	}
+	return "failed"
+}
+
+// writeWatchStatus replaces the status file with the outcome of a run, it's
+// written to a temporary file first so readers never see it partly written
+func writeWatchStatus(file string, exitCode int, results []resource.TestResult, t time.Time) error {
+	if file == "" {
+		return nil
+	}
+	status := WatchStatus{ExitCode: exitCode, Time: t, Tests: len(results)}
+	for _, r := range results {
+		if r.Result != resource.SUCCESS && r.Result != resource.SKIP && !r.Warning() {
+			status.Failed++
+		}
+	}
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	if err := writeTextfile(file, append(data, '\n')); err != nil {
+		return fmt.Errorf("status file %s: %v", file, err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package goss
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aelsabbahy/goss/resource"
+)
+
+func TestWatchTransitions(t *testing.T) {
+	previous := []resource.TestResult{
+		{ResourceType: "File", ResourceId: "/etc/app", Property: "exists", Result: resource.SUCCESS},
+		{ResourceType: "Port", ResourceId: "tcp:80", Property: "listening", Result: resource.FAIL, Found: []string{"false"}},
+		{ResourceType: "Service", ResourceId: "app", Property: "running", Result: resource.SUCCESS},
+	}
+	current := []resource.TestResult{
+		{ResourceType: "File", ResourceId: "/etc/app", Property: "exists", Result: resource.SUCCESS},
+		{ResourceType: "Port", ResourceId: "tcp:80", Property: "listening", Result: resource.SUCCESS},
+		{ResourceType: "HTTP", ResourceId: "http://localhost", Property: "status", Result: resource.ERROR, Err: fmt.Errorf("connection refused")},
+	}
+	want := []string{
+		"Port: tcp:80: listening: failed, found: false -> passed",
+		"HTTP: http://localhost: status: new, error: connection refused",
+		"Service: app: running: removed",
+	}
+	if got := watchTransitions(previous, current); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := watchTransitions(current, current); len(got) != 0 {
+		t.Errorf("unchanged results: got %q", got)
+	}
+}
+
+func TestWatchStatus(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "status.json")
+	results := []resource.TestResult{{Result: resource.SUCCESS}, {Result: resource.FAIL}, {Result: resource.FAIL, Baseline: true}}
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := writeWatchStatus(file, 1, results, now); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var status WatchStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		t.Fatal(err)
+	}
+	if want := (WatchStatus{ExitCode: 1, Time: now, Tests: 3, Failed: 1}); status != want {
+		t.Errorf("got %+v, want %+v", status, want)
+	}
+}
+
+func TestWatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	gossfile := filepath.Join(dir, "goss.yaml")
+	if err := ioutil.WriteFile(gossfile, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	status := filepath.Join(dir, "status.json")
+	changes, err := watchFiles([]string{gossfile, ""}, []string{status})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0644)
+	writeWatchStatus(status, 0, nil, time.Now())
+	select {
+	case <-changes:
+		t.Fatal("writing the status file was reported as a change")
+	case <-time.After(100 * time.Millisecond):
+	}
+	ioutil.WriteFile(gossfile, []byte("file: {}"), 0644)
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported after writing the gossfile")
+	}
+}